@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"strings"
 )
 
@@ -14,6 +16,81 @@ type CryptoConfig struct {
 	FailOnError bool     `json:"fail_on_error,optional,default=true" yaml:"fail_on_error"`
 	Algorithm   string   `json:"algorithm,optional,default=AES-GCM" yaml:"algorithm"`
 	Debug       bool     `json:"debug,optional,default=false" yaml:"debug"`
+	// Services 多个命名加密服务，如 api、db、webhook 使用不同的密钥和算法
+	Services []CryptoServiceConfig `json:"services,optional" yaml:"services"`
+	// Signature 只校验签名不加密的路径，用于需要明文报文便于审查的合作方，匹配的路径不再加密
+	Signature *SignatureConfig `json:"signature,optional" yaml:"signature"`
+}
+
+// SignatureConfig 请求签名配置，签名为 HMAC-SHA256(method\npath\nquery\ntimestamp\nnonce\nhex(sha256(body)))
+// path 按 path.Clean 规范化，query 按参数名和值排序后URL编码，见 crypto.SignRequest
+type SignatureConfig struct {
+	Enable bool `json:"enable,optional" yaml:"enable"`
+	// 需要校验签名的路径，规则同 EnableURI，为空时不校验任何路径
+	URI []string `json:"uri,optional" yaml:"uri"`
+	// 签名密钥（支持密钥引用），按请求头中的密钥ID选择，请求未携带密钥ID时使用 default
+	Keys map[string]string `json:"keys" yaml:"keys"`
+	// 签名请求头，默认 X-Signature
+	Header string `json:"header,optional,default=X-Signature" yaml:"header"`
+	// 时间戳请求头（Unix秒），默认 X-Timestamp
+	TimestampHeader string `json:"timestamp_header,optional,default=X-Timestamp" yaml:"timestamp_header"`
+	// 密钥ID请求头，默认 X-Key-Id
+	KeyIDHeader string `json:"key_id_header,optional,default=X-Key-Id" yaml:"key_id_header"`
+	// nonce 请求头，有效期内同一 nonce 只能使用一次，默认 X-Nonce
+	NonceHeader string `json:"nonce_header,optional,default=X-Nonce" yaml:"nonce_header"`
+	// 允许的时间偏差（秒），默认300，nonce 保留两倍偏差时长
+	MaxSkew int64 `json:"max_skew,optional,default=300" yaml:"max_skew"`
+	// 是否对响应签名，签名写入同名响应头，签名方式同请求（使用请求的方法、路径、查询参数和 nonce，响应的时间戳和响应体）
+	SignResponse bool `json:"sign_response,optional" yaml:"sign_response"`
+}
+
+// ShouldSign 检查路径是否只校验签名，原始路径或按路由规则规范化后的路径任一匹配即需校验，
+// 避免 //a、/a/../b 等写法绕过校验后仍被路由到签名路径
+func (c *SignatureConfig) ShouldSign(p string) bool {
+	if c == nil || !c.Enable {
+		return false
+	}
+	cleaned := path.Clean("/" + p)
+	for _, uri := range c.URI {
+		if matchPath(p, uri) || matchPath(cleaned, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 验证签名配置
+func (c *SignatureConfig) Validate() error {
+	if c == nil || !c.Enable {
+		return nil
+	}
+	if len(c.Keys) == 0 {
+		return errors.New("signature keys are required")
+	}
+	for id, key := range c.Keys {
+		if key == "" {
+			return fmt.Errorf("signature key %s is empty", id)
+		}
+	}
+	if c.MaxSkew < 0 {
+		return fmt.Errorf("invalid signature max_skew: %d", c.MaxSkew)
+	}
+	return nil
+}
+
+// CryptoServiceConfig 命名加密服务配置
+type CryptoServiceConfig struct {
+	Name      string `json:"name,optional,default=default" yaml:"name"`
+	Algorithm string `json:"algorithm,optional,default=AES-GCM" yaml:"algorithm"` // AES-GCM、AES-CBC、X25519
+	// Key 密钥明文，或密钥引用：env:NAME、file:/path、kms://key-id（需注册对应的解析器）
+	Key   string `json:"key" yaml:"key"`
+	Debug bool   `json:"debug,optional" yaml:"debug"`
+	// Format 输出格式：envelope（默认，自定义信封）或 jwe（紧凑JWE，便于合作方使用标准库对接）
+	Format string `json:"format,optional" yaml:"format"`
+	// KeyID 写入JWE/JWS头部的kid
+	KeyID string `json:"key_id,optional" yaml:"key_id"`
+	// SigningKey JWS签名密钥（支持密钥引用），使用JWS时必须设置，至少32字节且不能与 Key 相同
+	SigningKey string `json:"signing_key,optional" yaml:"signing_key"`
 }
 
 // DefaultCryptoConfig 默认加密配置
@@ -57,10 +134,22 @@ func (c *CryptoConfig) LoadFromEnv() {
 
 // Validate 验证加密配置
 func (c *CryptoConfig) Validate() error {
+	if err := c.Signature.Validate(); err != nil {
+		return err
+	}
 	if !c.Enable {
 		return nil
 	}
 
+	if err := c.validateServices(); err != nil {
+		return err
+	}
+
+	// 仅配置了命名服务时可不设置默认密钥
+	if c.Key == "" && len(c.Services) > 0 {
+		return nil
+	}
+
 	if len(c.Key) != 32 {
 		return fmt.Errorf("crypto key must be exactly 32 bytes, got %d", len(c.Key))
 	}
@@ -75,12 +164,53 @@ func (c *CryptoConfig) Validate() error {
 	return fmt.Errorf("unsupported algorithm: %s", c.Algorithm)
 }
 
+// validateServices 验证命名服务配置，密钥可能为引用，长度在解析后校验
+func (c *CryptoConfig) validateServices() error {
+	names := make(map[string]bool, len(c.Services))
+	for _, svc := range c.Services {
+		name := svc.Name
+		if name == "" {
+			name = "default"
+		}
+		if names[name] {
+			return fmt.Errorf("duplicate crypto service: %s", name)
+		}
+		names[name] = true
+
+		if svc.Key == "" {
+			return fmt.Errorf("crypto service %s: key is required", name)
+		}
+
+		switch svc.Algorithm {
+		case "", "AES-GCM", "AES-CBC", "X25519":
+		default:
+			return fmt.Errorf("crypto service %s: unsupported algorithm: %s", name, svc.Algorithm)
+		}
+
+		switch svc.Format {
+		case "", "envelope":
+		case "jwe":
+			if svc.Algorithm == "X25519" {
+				return fmt.Errorf("crypto service %s: jwe format requires an AES key", name)
+			}
+		default:
+			return fmt.Errorf("crypto service %s: unsupported format: %s", name, svc.Format)
+		}
+	}
+	return nil
+}
+
 // ShouldEncrypt 检查路径是否需要加密
 func (c *CryptoConfig) ShouldEncrypt(path string) bool {
 	if !c.Enable {
 		return false
 	}
 
+	// 只校验签名的路径不加密
+	if c.Signature.ShouldSign(path) {
+		return false
+	}
+
 	// 如果EnableURI为空，加密所有路径
 	if len(c.EnableURI) == 0 {
 		return true
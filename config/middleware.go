@@ -12,8 +12,14 @@ type MiddlewareConfig struct {
 	EnableCORS     bool                   `json:"enable_cors,optional" yaml:"enable_cors"`
 	EnableLogging  bool                   `json:"enable_logging,optional" yaml:"enable_logging"`
 	EnableRecovery bool                   `json:"enable_recovery,optional" yaml:"enable_recovery"`
+	EnableTracing  bool                   `json:"enable_tracing,optional" yaml:"enable_tracing"`
+	EnableLocale   bool                   `json:"enable_locale,optional" yaml:"enable_locale"`
+	EnableCSRF     bool                   `json:"enable_csrf,optional" yaml:"enable_csrf"`
 	CORS           *CORSConfig            `json:"cors,optional,omitempty" yaml:"cors,omitempty"`
 	Logging        *LoggingConfig         `json:"logging,optional,omitempty" yaml:"logging,omitempty"`
+	Tracing        *TracingConfig         `json:"tracing,optional,omitempty" yaml:"tracing,omitempty"`
+	Locale         *LocaleConfig          `json:"locale,optional,omitempty" yaml:"locale,omitempty"`
+	CSRF           *CSRFConfig            `json:"csrf,optional,omitempty" yaml:"csrf,omitempty"`
 	Custom         map[string]interface{} `json:"custom,optional,omitempty" yaml:"custom,omitempty"`
 }
 
@@ -43,6 +49,35 @@ type LoggingConfig struct {
 	EnableMetrics bool   `json:"enable_metrics,optional" yaml:"enable_metrics"`
 }
 
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	SampleRatio  float64  `json:"sample_ratio,optional,default=1" yaml:"sample_ratio"` // 采样率 0-1，默认1，上游已采样的请求总是记录
+	ExcludePaths []string `json:"exclude_paths,optional" yaml:"exclude_paths"`         // 不追踪的路径前缀，如健康检查
+}
+
+// LocaleConfig 语言协商配置，按 查询参数 → Cookie → x-language → Accept-Language 的顺序确定语言
+type LocaleConfig struct {
+	Supported     []string `json:"supported,optional" yaml:"supported"`           // 支持的语言，如 en、zh、zh-TW，第一个为默认语言
+	QueryParam    string   `json:"query_param,optional" yaml:"query_param"`       // 查询参数名，默认 lang
+	CookieName    string   `json:"cookie_name,optional" yaml:"cookie_name"`       // Cookie名，默认 lang
+	PersistCookie bool     `json:"persist_cookie,optional" yaml:"persist_cookie"` // 通过查询参数切换语言时写入Cookie
+	CookieMaxAge  int      `json:"cookie_max_age,optional" yaml:"cookie_max_age"` // Cookie有效期（秒），默认一年
+}
+
+// CSRFConfig CSRF防护配置（双重提交Cookie），用于Cookie认证的管理后台
+// 安全方法（GET、HEAD、OPTIONS、TRACE）下发令牌Cookie，其他方法要求请求头或表单中的令牌与Cookie一致
+type CSRFConfig struct {
+	CookieName   string   `json:"cookie_name,optional" yaml:"cookie_name"`       // 令牌Cookie名，默认 csrf_token
+	HeaderName   string   `json:"header_name,optional" yaml:"header_name"`       // 令牌请求头，默认 X-CSRF-Token
+	FormField    string   `json:"form_field,optional" yaml:"form_field"`         // 表单字段名，为空时只接受请求头
+	CookiePath   string   `json:"cookie_path,optional" yaml:"cookie_path"`       // Cookie路径，默认 /
+	CookieDomain string   `json:"cookie_domain,optional" yaml:"cookie_domain"`   // Cookie域名
+	CookieMaxAge int      `json:"cookie_max_age,optional" yaml:"cookie_max_age"` // Cookie有效期（秒），默认12小时
+	SameSite     string   `json:"same_site,optional" yaml:"same_site"`           // lax（默认）、strict、none，none 需同时开启 Secure
+	Secure       bool     `json:"secure,optional" yaml:"secure"`                 // 仅HTTPS发送Cookie
+	ExemptPaths  []string `json:"exempt_paths,optional" yaml:"exempt_paths"`     // 不校验的路径前缀，按路径段匹配，如第三方回调 /webhook
+}
+
 // DefaultMiddlewareConfig 默认中间件配置
 func DefaultMiddlewareConfig() *MiddlewareConfig {
 	return &MiddlewareConfig{
@@ -81,6 +116,23 @@ func DefaultMiddlewareConfig() *MiddlewareConfig {
 			EnableTrace:   false,
 			EnableMetrics: false,
 		},
+		Tracing: &TracingConfig{
+			SampleRatio:  1,
+			ExcludePaths: []string{"/health", "/metrics"},
+		},
+		Locale: &LocaleConfig{
+			Supported:    []string{"en", "zh"},
+			QueryParam:   "lang",
+			CookieName:   "lang",
+			CookieMaxAge: 365 * 24 * 3600,
+		},
+		CSRF: &CSRFConfig{
+			CookieName:   "csrf_token",
+			HeaderName:   "X-CSRF-Token",
+			CookiePath:   "/",
+			CookieMaxAge: 12 * 3600,
+			SameSite:     "lax",
+		},
 		Custom: make(map[string]interface{}),
 	}
 }
@@ -99,6 +151,24 @@ func (m *MiddlewareConfig) LoadFromEnv() {
 		m.EnableRecovery = false
 	}
 
+	if enableTracing := os.Getenv("MIDDLEWARE_TRACING"); enableTracing == "true" {
+		m.EnableTracing = true
+	}
+
+	if enableLocale := os.Getenv("MIDDLEWARE_LOCALE"); enableLocale == "true" {
+		m.EnableLocale = true
+	}
+
+	if enableCSRF := os.Getenv("MIDDLEWARE_CSRF"); enableCSRF == "true" {
+		m.EnableCSRF = true
+	}
+
+	if ratio := os.Getenv("TRACING_SAMPLE_RATIO"); ratio != "" && m.Tracing != nil {
+		if r, err := strconv.ParseFloat(ratio, 64); err == nil {
+			m.Tracing.SampleRatio = r
+		}
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		m.Logging.Level = logLevel
 	}
@@ -126,5 +196,21 @@ func (m *MiddlewareConfig) Validate() error {
 		}
 	}
 
+	if m.Tracing != nil && (m.Tracing.SampleRatio < 0 || m.Tracing.SampleRatio > 1) {
+		return fmt.Errorf("invalid tracing sample ratio: %v", m.Tracing.SampleRatio)
+	}
+
+	if m.CSRF != nil {
+		switch m.CSRF.SameSite {
+		case "", "lax", "strict":
+		case "none":
+			if !m.CSRF.Secure {
+				return fmt.Errorf("csrf same_site none requires secure cookie")
+			}
+		default:
+			return fmt.Errorf("invalid csrf same_site: %s", m.CSRF.SameSite)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultProfileEnv 选择环境配置的环境变量
+	DefaultProfileEnv = "APP_ENV"
+	// profileExtendsKey 环境配置中声明继承的键，如 staging 继承 prod 后再覆盖少量配置
+	profileExtendsKey = "_extends"
+)
+
+// profileOptions 环境配置加载选项
+type profileOptions struct {
+	profile   string
+	envKey    string
+	expandEnv bool
+}
+
+// ProfileOption 环境配置加载选项
+type ProfileOption func(*profileOptions)
+
+// WithProfile 指定环境，优先于环境变量
+func WithProfile(name string) ProfileOption {
+	return func(o *profileOptions) {
+		o.profile = name
+	}
+}
+
+// WithProfileEnv 设置选择环境的环境变量，默认 APP_ENV
+func WithProfileEnv(key string) ProfileOption {
+	return func(o *profileOptions) {
+		o.envKey = key
+	}
+}
+
+// WithExpandEnv 读取配置文件时展开 ${VAR} 形式的环境变量，同 conf.UseEnv
+func WithExpandEnv() ProfileOption {
+	return func(o *profileOptions) {
+		o.expandEnv = true
+	}
+}
+
+// ActiveProfile 获取当前环境，未设置时为空
+func ActiveProfile() string {
+	return os.Getenv(DefaultProfileEnv)
+}
+
+// ProfileFile 获取环境配置文件路径，如 etc/app.yaml 的 prod 环境为 etc/app.prod.yaml
+func ProfileFile(file, profile string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + profile + ext
+}
+
+// LoadProfile 加载基础配置并合并环境配置，支持 .json、.yaml、.yml，合并后按 go-zero 规则解析（default、optional 等）并调用 Validate
+// 环境由 APP_ENV 选择，未设置时只加载基础配置；环境配置可通过 _extends 继承其他环境，按 基础 → 被继承环境 → 当前环境 的顺序合并
+// 合并规则：对象逐键合并；数组和标量整体替换；值为 null 时删除该键，恢复为默认值
+func LoadProfile(file string, v any, opts ...ProfileOption) error {
+	o := profileOptions{envKey: DefaultProfileEnv}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	profile := o.profile
+	if profile == "" {
+		profile = os.Getenv(o.envKey)
+	}
+
+	merged, err := readConfigMap(file, o.expandEnv)
+	if err != nil {
+		return err
+	}
+	delete(merged, profileExtendsKey)
+
+	if profile != "" {
+		overlays, err := profileChain(file, profile, o.expandEnv)
+		if err != nil {
+			return err
+		}
+		for _, overlay := range overlays {
+			mergeConfigMap(merged, overlay)
+		}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("marshal merged config: %w", err)
+	}
+	if err := conf.LoadFromJsonBytes(data, v); err != nil {
+		if profile != "" {
+			return fmt.Errorf("config %s (profile %s): %w", file, profile, err)
+		}
+		return fmt.Errorf("config %s: %w", file, err)
+	}
+	return nil
+}
+
+// MustLoadProfile 加载环境配置，失败时退出
+func MustLoadProfile(file string, v any, opts ...ProfileOption) {
+	if err := LoadProfile(file, v, opts...); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+}
+
+// profileChain 按继承关系返回需要依次合并的环境配置
+func profileChain(file, profile string, expandEnv bool) ([]map[string]any, error) {
+	var chain []map[string]any
+	seen := make(map[string]bool)
+	for profile != "" {
+		if strings.ContainsAny(profile, `/\`) {
+			return nil, fmt.Errorf("invalid profile name: %s", profile)
+		}
+		if seen[profile] {
+			return nil, fmt.Errorf("profile %s: circular %s", profile, profileExtendsKey)
+		}
+		seen[profile] = true
+
+		m, err := readConfigMap(ProfileFile(file, profile), expandEnv)
+		if err != nil {
+			return nil, err
+		}
+		parent, _ := m[profileExtendsKey].(string)
+		delete(m, profileExtendsKey)
+		chain = append([]map[string]any{m}, chain...)
+		profile = parent
+	}
+	return chain, nil
+}
+
+// readConfigMap 读取配置文件为 map
+func readConfigMap(file string, expandEnv bool) (map[string]any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if expandEnv {
+		content = []byte(os.ExpandEnv(string(content)))
+	}
+
+	m := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(content, &m)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &m)
+	default:
+		return nil, fmt.Errorf("unrecognized file type: %s", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", file, err)
+	}
+	if m == nil {
+		m = make(map[string]any)
+	}
+	return m, nil
+}
+
+// mergeConfigMap 将 overlay 合并到 base，键名不区分大小写（与 go-zero 一致）
+func mergeConfigMap(base, overlay map[string]any) {
+	for key, val := range overlay {
+		baseKey := key
+		for k := range base {
+			if strings.EqualFold(k, key) {
+				baseKey = k
+				break
+			}
+		}
+
+		if val == nil {
+			delete(base, baseKey)
+			continue
+		}
+		if src, ok := val.(map[string]any); ok {
+			if dst, ok := base[baseKey].(map[string]any); ok {
+				mergeConfigMap(dst, src)
+				continue
+			}
+		}
+		base[baseKey] = val
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// profileConfig 环境配置测试用配置
+type profileConfig struct {
+	Name  string
+	Port  int      `json:",default=8080"`
+	Mode  string   `json:",optional"`
+	Hosts []string `json:",optional"`
+	Redis struct {
+		Host string
+		DB   int `json:",default=0"`
+	}
+}
+
+// writeFiles 在临时目录写入配置文件
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestProfileFile(t *testing.T) {
+	tests := []struct {
+		file, profile, want string
+	}{
+		{"etc/app.yaml", "prod", "etc/app.prod.yaml"},
+		{"etc/app.json", "dev", "etc/app.dev.json"},
+		{"app", "dev", "app.dev"},
+	}
+	for _, tt := range tests {
+		if got := ProfileFile(tt.file, tt.profile); got != tt.want {
+			t.Fatalf("ProfileFile(%q, %q) = %q, want %q", tt.file, tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	const base = `
+Name: app
+Port: 9000
+Mode: dev
+Hosts: [a, b]
+Redis:
+  Host: localhost
+  DB: 1
+`
+	tests := []struct {
+		name    string
+		files   map[string]string
+		profile string
+		env     map[string]string
+		opts    []ProfileOption
+		check   func(c profileConfig) bool
+		wantErr string
+	}{
+		{
+			name:  "base only",
+			files: map[string]string{"app.prod.yaml": "Port: 1"},
+			check: func(c profileConfig) bool {
+				return c.Port == 9000 && c.Redis.Host == "localhost" && len(c.Hosts) == 2
+			},
+		},
+		{
+			name:    "nested merge",
+			files:   map[string]string{"app.prod.yaml": "Redis:\n  Host: redis\n"},
+			profile: "prod",
+			check: func(c profileConfig) bool {
+				return c.Redis.Host == "redis" && c.Redis.DB == 1 && c.Port == 9000
+			},
+		},
+		{
+			name:    "array replaced",
+			files:   map[string]string{"app.prod.yaml": "Hosts: [c]\n"},
+			profile: "prod",
+			check: func(c profileConfig) bool {
+				return len(c.Hosts) == 1 && c.Hosts[0] == "c"
+			},
+		},
+		{
+			name:    "null restores default",
+			files:   map[string]string{"app.prod.yaml": "Port: null\nMode: ~\n"},
+			profile: "prod",
+			check: func(c profileConfig) bool {
+				return c.Port == 8080 && c.Mode == ""
+			},
+		},
+		{
+			name:    "keys case insensitive",
+			files:   map[string]string{"app.prod.yaml": "port: 1\nredis:\n  host: redis\n"},
+			profile: "prod",
+			check: func(c profileConfig) bool {
+				return c.Port == 1 && c.Redis.Host == "redis" && c.Redis.DB == 1
+			},
+		},
+		{
+			name: "extends chain",
+			files: map[string]string{
+				"app.prod.yaml":    "Mode: prod\nPort: 80\nRedis:\n  Host: redis\n",
+				"app.staging.yaml": "_extends: prod\nPort: 81\n",
+			},
+			profile: "staging",
+			check: func(c profileConfig) bool {
+				return c.Mode == "prod" && c.Port == 81 && c.Redis.Host == "redis"
+			},
+		},
+		{
+			name:    "json profile",
+			files:   map[string]string{"app.prod.json": `{"Port": 2}`},
+			profile: "prod",
+			check: func(c profileConfig) bool {
+				return c.Port == 2
+			},
+		},
+		{
+			name:  "profile from env",
+			files: map[string]string{"app.prod.yaml": "Port: 3\n"},
+			env:   map[string]string{DefaultProfileEnv: "prod"},
+			check: func(c profileConfig) bool {
+				return c.Port == 3
+			},
+		},
+		{
+			name:  "custom env key",
+			files: map[string]string{"app.prod.yaml": "Port: 4\n"},
+			env:   map[string]string{"MY_ENV": "prod"},
+			opts:  []ProfileOption{WithProfileEnv("MY_ENV")},
+			check: func(c profileConfig) bool {
+				return c.Port == 4
+			},
+		},
+		{
+			name:  "expand env",
+			files: map[string]string{"app.prod.yaml": "Redis:\n  Host: ${PROFILE_TEST_HOST}\n"},
+			env:   map[string]string{"PROFILE_TEST_HOST": "from-env"},
+			opts:  []ProfileOption{WithProfile("prod"), WithExpandEnv()},
+			check: func(c profileConfig) bool {
+				return c.Redis.Host == "from-env"
+			},
+		},
+		{
+			name:    "missing profile",
+			files:   map[string]string{},
+			profile: "prod",
+			wantErr: "app.prod.yaml",
+		},
+		{
+			name: "circular extends",
+			files: map[string]string{
+				"app.a.yaml": "_extends: b\n",
+				"app.b.yaml": "_extends: a\n",
+			},
+			profile: "a",
+			wantErr: "circular",
+		},
+		{
+			name:    "invalid profile name",
+			files:   map[string]string{},
+			profile: "../prod",
+			wantErr: "invalid profile name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(DefaultProfileEnv, "")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			files := map[string]string{"app.yaml": base}
+			for k, v := range tt.files {
+				if strings.HasSuffix(k, ".json") {
+					// json 环境配置对应 json 基础配置
+					files["app.json"] = `{"Name": "app", "Redis": {"Host": "localhost"}}`
+				}
+				files[k] = v
+			}
+			dir := writeFiles(t, files)
+			file := filepath.Join(dir, "app.yaml")
+			if _, ok := files["app.json"]; ok {
+				file = filepath.Join(dir, "app.json")
+			}
+
+			opts := tt.opts
+			if tt.profile != "" {
+				opts = append(opts, WithProfile(tt.profile))
+			}
+			var c profileConfig
+			err := LoadProfile(file, &c, opts...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(c) {
+				t.Fatalf("config = %+v", c)
+			}
+		})
+	}
+}
@@ -15,17 +15,35 @@ type Encryptor interface {
 }
 
 // EncryptedData 加密数据结构
+// 后四个字段为可选字段，仅在使用临时密钥协商时出现，旧版两字段格式保持兼容
 type EncryptedData struct {
 	Encrypted bool   `json:"encrypted"`
 	Data      string `json:"data"`
 	Timestamp int64  `json:"timestamp,omitempty"`
+	// 临时公钥（X25519，base64）
+	EphemeralPublicKey string `json:"epk,omitempty"`
+	// 服务端密钥ID
+	KeyID string `json:"kid,omitempty"`
+	// 随机数（base64）
+	Nonce string `json:"nonce,omitempty"`
+	// 附加认证数据的sha256（base64）
+	AADHash string `json:"aad_hash,omitempty"`
+}
+
+// IsEnvelope 是否为临时密钥信封格式
+func (d *EncryptedData) IsEnvelope() bool {
+	return d != nil && d.EphemeralPublicKey != ""
 }
 
 // XCryptoService 加密服务
 type XCryptoService struct {
 	encryptor Encryptor
 	debug     bool
-	mu        sync.RWMutex // 保护并发访问
+	// JWE/JWS 相关配置，见 jose.go
+	format     string
+	keyID      string
+	signingKey []byte
+	mu         sync.RWMutex // 保护并发访问
 }
 
 // NewCryptoService 创建加密服务
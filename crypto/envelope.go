@@ -0,0 +1,274 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+const (
+	// EnvelopeAlgorithm 临时密钥信封算法
+	EnvelopeAlgorithm = "ECDH-X25519+AES-GCM"
+	// 密钥派生信息，请求和响应方向分别派生密钥
+	envelopeHKDFInfo = "golib-crypto-envelope"
+	// EnvelopeMaxSkew 请求信封时间戳允许的最大偏差
+	EnvelopeMaxSkew = 5 * time.Minute
+)
+
+// ErrEnvelopeExpired 请求信封时间戳超出允许的偏差
+var ErrEnvelopeExpired = errors.New("envelope timestamp out of range")
+
+// SessionKey 通过临时公钥协商出的会话密钥，请求和响应使用不同的密钥，
+// 服务端用于解密请求和加密响应，客户端用于加密请求和解密响应
+type SessionKey struct {
+	KeyID   string
+	sealKey []byte
+	openKey []byte
+	aad     []byte
+}
+
+// RegisterX25519Key 注册服务端X25519私钥（32字节原始私钥）
+func (m *Manager) RegisterX25519Key(keyID string, privateKey []byte) error {
+	priv, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("invalid x25519 private key: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keys == nil {
+		m.keys = make(map[string]*ecdh.PrivateKey)
+	}
+	m.keys[keyID] = priv
+	return nil
+}
+
+// PublicKey 获取已注册密钥的公钥（base64），用于下发给客户端
+func (m *Manager) PublicKey(keyID string) (string, error) {
+	priv, ok := m.getX25519Key(keyID)
+	if !ok {
+		return "", fmt.Errorf("key %s not registered", keyID)
+	}
+	return base64.StdEncoding.EncodeToString(priv.PublicKey().Bytes()), nil
+}
+
+// getX25519Key 获取服务端私钥
+func (m *Manager) getX25519Key(keyID string) (*ecdh.PrivateKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	priv, ok := m.keys[keyID]
+	return priv, ok
+}
+
+// OpenEnvelope 解密临时密钥信封，返回会话密钥供加密响应使用，信封时间戳与当前时间偏差超过 EnvelopeMaxSkew 时返回 ErrEnvelopeExpired
+func (m *Manager) OpenEnvelope(data *EncryptedData, aad []byte, target interface{}) (*SessionKey, error) {
+	if !data.IsEnvelope() {
+		return nil, fmt.Errorf("data is not an envelope")
+	}
+	// 时间戳参与认证，篡改后解密失败
+	if skew := time.Since(time.Unix(data.Timestamp, 0)); skew > EnvelopeMaxSkew || skew < -EnvelopeMaxSkew {
+		return nil, ErrEnvelopeExpired
+	}
+
+	priv, ok := m.getX25519Key(data.KeyID)
+	if !ok {
+		return nil, fmt.Errorf("key %s not registered", data.KeyID)
+	}
+
+	epk, err := base64.StdEncoding.DecodeString(data.EphemeralPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+
+	peer, err := ecdh.X25519().NewPublicKey(epk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral public key: %w", err)
+	}
+
+	reqKey, respKey, err := deriveSessionKey(priv, peer, epk, priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	session := &SessionKey{KeyID: data.KeyID, sealKey: respKey, openKey: reqKey, aad: aad}
+	if err = session.Open(data, target); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// SealEnvelope 客户端使用服务端公钥生成临时密钥并加密数据
+func SealEnvelope(serverPublicKey []byte, keyID string, data interface{}, aad []byte) (*EncryptedData, *SessionKey, error) {
+	peer, err := ecdh.X25519().NewPublicKey(serverPublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid server public key: %w", err)
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate ephemeral key failed: %w", err)
+	}
+
+	epk := ephemeral.PublicKey().Bytes()
+	reqKey, respKey, err := deriveSessionKey(ephemeral, peer, epk, serverPublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	session := &SessionKey{KeyID: keyID, sealKey: reqKey, openKey: respKey, aad: aad}
+	encrypted, err := session.Seal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted.EphemeralPublicKey = base64.StdEncoding.EncodeToString(epk)
+
+	return encrypted, session, nil
+}
+
+// Seal 使用本方向的会话密钥加密数据
+func (k *SessionKey) Seal(data interface{}) (*EncryptedData, error) {
+	jsonBytes, err := jsonx.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal failed: %w", err)
+	}
+
+	gcm, err := newGCM(k.sealKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	timestamp := getCurrentTimestamp()
+	return &EncryptedData{
+		Encrypted: true,
+		Data:      base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, jsonBytes, sealedAAD(k.aad, timestamp))),
+		Timestamp: timestamp,
+		KeyID:     k.KeyID,
+		Nonce:     base64.StdEncoding.EncodeToString(nonce),
+		AADHash:   hashAAD(k.aad),
+	}, nil
+}
+
+// Open 使用对方方向的会话密钥解密数据
+func (k *SessionKey) Open(data *EncryptedData, target interface{}) error {
+	if !data.Encrypted {
+		return fmt.Errorf("data is not encrypted")
+	}
+
+	// 先比较AAD哈希，便于给出明确的错误
+	if data.AADHash != "" && data.AADHash != hashAAD(k.aad) {
+		return fmt.Errorf("aad hash mismatch")
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(data.Nonce)
+	if err != nil {
+		return fmt.Errorf("invalid nonce: %w", err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(data.Data)
+	if err != nil {
+		return fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	gcm, err := newGCM(k.openKey)
+	if err != nil {
+		return err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return fmt.Errorf("invalid nonce size: %d", len(nonce))
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, sealedAAD(k.aad, data.Timestamp))
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+
+	if err = jsonx.Unmarshal(plaintext, target); err != nil {
+		return fmt.Errorf("json unmarshal failed: %w", err)
+	}
+
+	return nil
+}
+
+// deriveSessionKey ECDH协商后通过HKDF分别派生请求和响应方向的AES-256密钥
+func deriveSessionKey(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, epk, spk []byte) (reqKey, respKey []byte, err error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh failed: %w", err)
+	}
+
+	salt := bytes.Join([][]byte{epk, spk}, nil)
+	if reqKey, err = hkdf.Key(sha256.New, shared, salt, envelopeHKDFInfo+" req", 32); err != nil {
+		return nil, nil, fmt.Errorf("derive session key failed: %w", err)
+	}
+	if respKey, err = hkdf.Key(sha256.New, shared, salt, envelopeHKDFInfo+" resp", 32); err != nil {
+		return nil, nil, fmt.Errorf("derive session key failed: %w", err)
+	}
+
+	return reqKey, respKey, nil
+}
+
+// sealedAAD 加密时的附加认证数据，绑定信封时间戳
+func sealedAAD(aad []byte, timestamp int64) []byte {
+	return strconv.AppendInt(append(bytes.Clone(aad), ' '), timestamp, 10)
+}
+
+// newGCM 创建AES-GCM实例
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// hashAAD 计算附加认证数据的哈希
+func hashAAD(aad []byte) string {
+	if len(aad) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(aad)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// RegisterGlobalX25519Key 注册全局X25519私钥
+func RegisterGlobalX25519Key(keyID string, privateKey []byte) error {
+	return globalManager.RegisterX25519Key(keyID, privateKey)
+}
+
+// OpenRequestEnvelope 使用全局管理器解密请求信封
+func OpenRequestEnvelope(encryptedBytes []byte, aad []byte, target interface{}) (*SessionKey, error) {
+	var encryptedData EncryptedData
+	if err := jsonx.Unmarshal(encryptedBytes, &encryptedData); err != nil {
+		return nil, err
+	}
+
+	return globalManager.OpenEnvelope(&encryptedData, aad, target)
+}
+
+// IsEnvelopeFormat 检查是否为临时密钥信封格式
+func IsEnvelopeFormat(data []byte) bool {
+	var encryptedData EncryptedData
+	if err := jsonx.Unmarshal(data, &encryptedData); err != nil {
+		return false
+	}
+	return encryptedData.Encrypted && encryptedData.IsEnvelope()
+}
@@ -0,0 +1,128 @@
+package crypto
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+// newEnvelopeManager 创建注册了随机X25519私钥的管理器，返回服务端公钥
+func newEnvelopeManager(t *testing.T) (*Manager, []byte) {
+	t.Helper()
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager()
+	if err := m.RegisterX25519Key("k1", priv.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := m.PublicKey("k1")
+	if err != nil || pub != base64.StdEncoding.EncodeToString(priv.PublicKey().Bytes()) {
+		t.Fatalf("PublicKey = %q, %v", pub, err)
+	}
+	return m, priv.PublicKey().Bytes()
+}
+
+func TestEnvelopeKeyExchange(t *testing.T) {
+	m, pub := newEnvelopeManager(t)
+	aad := []byte("POST /pay")
+	in := josePayload{UserID: 7, Name: "alice"}
+
+	request, client, err := SealEnvelope(pub, "k1", in, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got josePayload
+	server, err := m.OpenEnvelope(request, aad, &got)
+	if err != nil || got != in {
+		t.Fatalf("OpenEnvelope = %+v, %v", got, err)
+	}
+
+	// 服务端用会话密钥加密响应，客户端解密
+	out := josePayload{UserID: 8, Name: "bob"}
+	response, err := server.Seal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = josePayload{}
+	if err := client.Open(response, &got); err != nil || got != out {
+		t.Fatalf("client Open = %+v, %v", got, err)
+	}
+
+	// 请求和响应使用不同的密钥，反射回对方的密文无法解密
+	if err := client.Open(request, &got); err == nil {
+		t.Fatal("client opened its own request")
+	}
+	if err := server.Open(response, &got); err == nil {
+		t.Fatal("server opened its own response")
+	}
+}
+
+func TestOpenEnvelopeRejects(t *testing.T) {
+	m, pub := newEnvelopeManager(t)
+	aad := []byte("POST /pay")
+
+	tests := []struct {
+		name   string
+		aad    []byte
+		modify func(d *EncryptedData)
+		want   error
+	}{
+		{"other path", []byte("POST /refund"), nil, nil},
+		{"unknown key", aad, func(d *EncryptedData) { d.KeyID = "k2" }, nil},
+		{"expired", aad, func(d *EncryptedData) { d.Timestamp -= int64(EnvelopeMaxSkew/time.Second) + 1 }, ErrEnvelopeExpired},
+		{"future", aad, func(d *EncryptedData) { d.Timestamp += int64(EnvelopeMaxSkew/time.Second) + 1 }, ErrEnvelopeExpired},
+		{"timestamp tampered", aad, func(d *EncryptedData) { d.Timestamp-- }, nil},
+		{"ciphertext tampered", aad, func(d *EncryptedData) { d.Data = base64.StdEncoding.EncodeToString([]byte("tampered")) }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := SealEnvelope(pub, "k1", josePayload{UserID: 7}, aad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.modify != nil {
+				tt.modify(data)
+			}
+			var got josePayload
+			_, err = m.OpenEnvelope(data, tt.aad, &got)
+			if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Fatalf("OpenEnvelope err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRequestEnvelope(t *testing.T) {
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterGlobalX25519Key("global-test", priv.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	aad := []byte("POST /pay")
+	in := josePayload{UserID: 7, Name: "alice"}
+	data, _, err := SealEnvelope(priv.PublicKey().Bytes(), "global-test", in, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := jsonx.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEnvelopeFormat(body) {
+		t.Fatal("IsEnvelopeFormat = false")
+	}
+
+	var got josePayload
+	if _, err := OpenRequestEnvelope(body, aad, &got); err != nil || got != in {
+		t.Fatalf("OpenRequestEnvelope = %+v, %v", got, err)
+	}
+}
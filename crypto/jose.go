@@ -0,0 +1,402 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+// 输出格式
+const (
+	// FormatEnvelope 自定义 EncryptedData 信封（默认）
+	FormatEnvelope = "envelope"
+	// FormatJWE 紧凑序列化JWE（alg=dir, enc=A256GCM），标准JOSE库可直接解密
+	FormatJWE = "jwe"
+)
+
+const (
+	jweAlgDir     = "dir"
+	jweEncA256GCM = "A256GCM"
+	jwsAlgHS256   = "HS256"
+)
+
+var (
+	// ErrInvalidJOSE JWE/JWS格式错误
+	ErrInvalidJOSE = errors.New("invalid jose compact serialization")
+	// ErrUnsupportedJOSEAlg 不支持的JWE/JWS算法
+	ErrUnsupportedJOSEAlg = errors.New("unsupported jose algorithm")
+	// ErrJWSSignature JWS签名校验失败
+	ErrJWSSignature = errors.New("jws signature verification failed")
+	// ErrNoSymmetricKey 加密器不支持导出对称密钥
+	ErrNoSymmetricKey = errors.New("encryptor does not expose a symmetric key")
+	// ErrNoSigningKey 未设置JWS签名密钥，或签名密钥与加密密钥相同
+	ErrNoSigningKey = errors.New("jws signing key not set or same as encryption key")
+)
+
+// HS256 签名密钥最小长度（RFC 7518 要求不短于哈希输出）
+const minSigningKeyLen = 32
+
+// joseHeader JOSE保护头
+type joseHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc,omitempty"`
+	Zip string `json:"zip,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// symmetricKeyer 可提供对称密钥的加密器，用于JWE/JWS
+type symmetricKeyer interface {
+	symmetricKey() []byte
+}
+
+// symmetricKey 实现 symmetricKeyer
+func (e *AESEncryptor) symmetricKey() []byte {
+	return e.key
+}
+
+// SetFormat 设置 EncryptPayload 的输出格式：envelope 或 jwe
+func (s *XCryptoService) SetFormat(format string) error {
+	switch format {
+	case "", FormatEnvelope:
+		format = FormatEnvelope
+	case FormatJWE:
+		if _, err := s.encryptionKey(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported crypto format: %s", format)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = format
+	return nil
+}
+
+// Format 当前输出格式
+func (s *XCryptoService) Format() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.format == "" {
+		return FormatEnvelope
+	}
+	return s.format
+}
+
+// SetKeyID 设置写入JWE/JWS头部的kid，便于对方选择密钥
+func (s *XCryptoService) SetKeyID(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyID = kid
+}
+
+// SetSigningKey 设置JWS签名密钥，至少32字节，不能与加密密钥相同；未设置时 SignJWS/VerifyJWS 返回 ErrNoSigningKey
+func (s *XCryptoService) SetSigningKey(key []byte) error {
+	if len(key) < minSigningKeyLen {
+		return fmt.Errorf("HS256 requires a signing key of at least %d bytes, got %d", minSigningKeyLen, len(key))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if keyer, ok := s.encryptor.(symmetricKeyer); ok && hmac.Equal(keyer.symmetricKey(), key) {
+		return ErrNoSigningKey
+	}
+	s.signingKey = bytes.Clone(key)
+	return nil
+}
+
+// EncryptPayload 按配置的格式加密：信封格式返回 EncryptedData 的JSON，JWE格式返回紧凑序列化字符串
+func (s *XCryptoService) EncryptPayload(data interface{}) ([]byte, error) {
+	s.mu.RLock()
+	format := s.format
+	s.mu.RUnlock()
+
+	if format == FormatJWE {
+		token, err := s.EncryptJWE(data)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(token), nil
+	}
+
+	encrypted, err := s.EncryptJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return jsonx.Marshal(encrypted)
+}
+
+// DecryptPayload 解密 EncryptPayload 的输出，自动识别信封和JWE格式
+func (s *XCryptoService) DecryptPayload(payload []byte, target interface{}) error {
+	if IsJWEFormat(payload) {
+		return s.DecryptJWE(string(bytes.TrimSpace(payload)), target)
+	}
+
+	var encrypted EncryptedData
+	if err := jsonx.Unmarshal(payload, &encrypted); err != nil {
+		return fmt.Errorf("json unmarshal failed: %w", err)
+	}
+	return s.DecryptJSON(&encrypted, target)
+}
+
+// EncryptJWE 将数据序列化为JSON后加密为紧凑JWE（alg=dir, enc=A256GCM）
+func (s *XCryptoService) EncryptJWE(data interface{}) (string, error) {
+	key, err := s.encryptionKey()
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := jsonx.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("json marshal failed: %w", err)
+	}
+
+	s.mu.RLock()
+	header := joseHeader{Alg: jweAlgDir, Enc: jweEncA256GCM, Kid: s.keyID}
+	s.mu.RUnlock()
+	protected, err := encodeJOSEHeader(header)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newA256GCM(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("generate iv failed: %w", err)
+	}
+
+	// JWE的附加认证数据为保护头的base64url编码
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		"", // dir模式无加密密钥
+		b64url(iv),
+		b64url(ciphertext),
+		b64url(tag),
+	}, "."), nil
+}
+
+// DecryptJWE 解密紧凑JWE并将JSON明文解析到target
+func (s *XCryptoService) DecryptJWE(token string, target interface{}) error {
+	key, err := s.encryptionKey()
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 {
+		return ErrInvalidJOSE
+	}
+
+	header, err := decodeJOSEHeader(parts[0])
+	if err != nil {
+		return err
+	}
+	if header.Alg != jweAlgDir || header.Enc != jweEncA256GCM || header.Zip != "" {
+		return fmt.Errorf("%w: alg=%s enc=%s zip=%s", ErrUnsupportedJOSEAlg, header.Alg, header.Enc, header.Zip)
+	}
+	if parts[1] != "" {
+		return fmt.Errorf("%w: unexpected encrypted key for dir", ErrInvalidJOSE)
+	}
+
+	iv, err1 := b64urlDecode(parts[2])
+	ciphertext, err2 := b64urlDecode(parts[3])
+	tag, err3 := b64urlDecode(parts[4])
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+
+	gcm, err := newA256GCM(key)
+	if err != nil {
+		return err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return ErrInvalidJOSE
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+
+	if err := jsonx.Unmarshal(plaintext, target); err != nil {
+		return fmt.Errorf("json unmarshal failed: %w", err)
+	}
+	return nil
+}
+
+// SignJWS 将数据序列化为JSON后生成紧凑JWS（HS256）
+func (s *XCryptoService) SignJWS(data interface{}) (string, error) {
+	key, err := s.jwsKey()
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := jsonx.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("json marshal failed: %w", err)
+	}
+
+	s.mu.RLock()
+	header := joseHeader{Alg: jwsAlgHS256, Kid: s.keyID}
+	s.mu.RUnlock()
+	protected, err := encodeJOSEHeader(header)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := protected + "." + b64url(payload)
+	return signingInput + "." + b64url(hs256(key, signingInput)), nil
+}
+
+// VerifyJWS 校验紧凑JWS签名并将JSON载荷解析到target
+func (s *XCryptoService) VerifyJWS(token string, target interface{}) error {
+	key, err := s.jwsKey()
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidJOSE
+	}
+
+	header, err := decodeJOSEHeader(parts[0])
+	if err != nil {
+		return err
+	}
+	// 只接受HS256，防止 alg=none 等降级攻击
+	if header.Alg != jwsAlgHS256 {
+		return fmt.Errorf("%w: alg=%s", ErrUnsupportedJOSEAlg, header.Alg)
+	}
+
+	signature, err := b64urlDecode(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	if !hmac.Equal(signature, hs256(key, parts[0]+"."+parts[1])) {
+		return ErrJWSSignature
+	}
+
+	payload, err := b64urlDecode(parts[1])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	if err := jsonx.Unmarshal(payload, target); err != nil {
+		return fmt.Errorf("json unmarshal failed: %w", err)
+	}
+	return nil
+}
+
+// IsJWEFormat 是否为紧凑JWE格式：5段均为base64url，保护头为含 alg 和 enc 的JSON对象，IV、密文和认证标签不为空
+func IsJWEFormat(data []byte) bool {
+	parts := strings.Split(string(bytes.TrimSpace(data)), ".")
+	if len(parts) != 5 || parts[2] == "" || parts[3] == "" || parts[4] == "" {
+		return false
+	}
+	header, err := decodeJOSEHeader(parts[0])
+	if err != nil || header.Alg == "" || header.Enc == "" {
+		return false
+	}
+	for _, part := range parts[1:] {
+		if _, err := b64urlDecode(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// encryptionKey 获取JWE使用的对称密钥
+func (s *XCryptoService) encryptionKey() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keyer, ok := s.encryptor.(symmetricKeyer)
+	if !ok {
+		return nil, ErrNoSymmetricKey
+	}
+	key := keyer.symmetricKey()
+	if len(key) != 32 {
+		return nil, fmt.Errorf("A256GCM requires a 32-byte key, got %d", len(key))
+	}
+	return key, nil
+}
+
+// jwsKey 获取JWS签名密钥，不与加密密钥共用，避免同一密钥同时用于AES-GCM和HMAC
+func (s *XCryptoService) jwsKey() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.signingKey) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	if keyer, ok := s.encryptor.(symmetricKeyer); ok && hmac.Equal(keyer.symmetricKey(), s.signingKey) {
+		return nil, ErrNoSigningKey
+	}
+	return s.signingKey, nil
+}
+
+func newA256GCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func hs256(key []byte, signingInput string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+func encodeJOSEHeader(h joseHeader) (string, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("marshal jose header failed: %w", err)
+	}
+	return b64url(data), nil
+}
+
+// decodeJOSEHeader 解析保护头，不支持任何扩展，含 crit 时拒绝（RFC 7515 4.1.11）
+func decodeJOSEHeader(s string) (joseHeader, error) {
+	var h joseHeader
+	data, err := b64urlDecode(s)
+	if err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	if _, ok := fields["crit"]; ok {
+		return h, fmt.Errorf("%w: crit header", ErrUnsupportedJOSEAlg)
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return h, fmt.Errorf("%w: %v", ErrInvalidJOSE, err)
+	}
+	return h, nil
+}
+
+func b64url(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func b64urlDecode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,189 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type josePayload struct {
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+// newJOSEService 创建使用AES-GCM密钥和独立签名密钥的加密服务
+func newJOSEService(t *testing.T) *XCryptoService {
+	t.Helper()
+	enc, err := NewAESGCMEncryptor(strings.Repeat("k", 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewCryptoService(enc, false)
+	if err := s.SetSigningKey([]byte(strings.Repeat("s", 32))); err != nil {
+		t.Fatal(err)
+	}
+	s.SetKeyID("k1")
+	return s
+}
+
+// joseHeaderSegment 编码保护头
+func joseHeaderSegment(t *testing.T, header string) string {
+	t.Helper()
+	return b64url([]byte(header))
+}
+
+func TestJWERoundTrip(t *testing.T) {
+	s := newJOSEService(t)
+	in := josePayload{UserID: 7, Name: "alice"}
+
+	token, err := s.EncryptJWE(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsJWEFormat([]byte(token)) {
+		t.Fatalf("IsJWEFormat(%q) = false", token)
+	}
+	var out josePayload
+	if err := s.DecryptJWE(token, &out); err != nil || out != in {
+		t.Fatalf("DecryptJWE = %+v, %v", out, err)
+	}
+
+	if err := s.SetFormat(FormatJWE); err != nil {
+		t.Fatal(err)
+	}
+	payload, err := s.EncryptPayload(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = josePayload{}
+	if err := s.DecryptPayload(payload, &out); err != nil || out != in {
+		t.Fatalf("DecryptPayload = %+v, %v", out, err)
+	}
+
+	parts := strings.Split(token, ".")
+	tests := []struct {
+		name   string
+		modify func(parts []string)
+		want   error
+	}{
+		{"ciphertext tampered", func(p []string) { p[3] = b64url([]byte("tampered")) }, nil},
+		{"header tampered", func(p []string) {
+			p[0] = joseHeaderSegment(t, `{"alg":"dir","enc":"A256GCM","kid":"k2"}`)
+		}, nil},
+		{"alg confusion", func(p []string) {
+			p[0] = joseHeaderSegment(t, `{"alg":"A256KW","enc":"A256GCM"}`)
+		}, ErrUnsupportedJOSEAlg},
+		{"compressed", func(p []string) {
+			p[0] = joseHeaderSegment(t, `{"alg":"dir","enc":"A256GCM","zip":"DEF"}`)
+		}, ErrUnsupportedJOSEAlg},
+		{"crit", func(p []string) {
+			p[0] = joseHeaderSegment(t, `{"alg":"dir","enc":"A256GCM","crit":["exp"],"exp":1}`)
+		}, ErrUnsupportedJOSEAlg},
+		{"encrypted key", func(p []string) { p[1] = b64url([]byte("key")) }, ErrInvalidJOSE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := append([]string(nil), parts...)
+			tt.modify(modified)
+			err := s.DecryptJWE(strings.Join(modified, "."), &out)
+			if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Fatalf("DecryptJWE err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWSRoundTrip(t *testing.T) {
+	s := newJOSEService(t)
+	in := josePayload{UserID: 7, Name: "alice"}
+
+	token, err := s.SignJWS(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out josePayload
+	if err := s.VerifyJWS(token, &out); err != nil || out != in {
+		t.Fatalf("VerifyJWS = %+v, %v", out, err)
+	}
+
+	parts := strings.Split(token, ".")
+	encKey := []byte(strings.Repeat("k", 32))
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"payload tampered", parts[0] + "." + b64url([]byte(`{"user_id":1}`)) + "." + parts[2], ErrJWSSignature},
+		{"alg none", joseHeaderSegment(t, `{"alg":"none"}`) + "." + parts[1] + ".", ErrUnsupportedJOSEAlg},
+		{"signed with encryption key", func() string {
+			input := parts[0] + "." + parts[1]
+			return input + "." + b64url(hs256(encKey, input))
+		}(), ErrJWSSignature},
+		{"crit", func() string {
+			input := joseHeaderSegment(t, `{"alg":"HS256","crit":["b64"],"b64":false}`) + "." + parts[1]
+			return input + "." + b64url(hs256([]byte(strings.Repeat("s", 32)), input))
+		}(), ErrUnsupportedJOSEAlg},
+		{"jwe as jws", strings.Join([]string{parts[0], "", parts[1], parts[2], ""}, "."), ErrInvalidJOSE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.VerifyJWS(tt.token, &out); !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyJWS err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWSSigningKey(t *testing.T) {
+	key := strings.Repeat("k", 32)
+	enc, err := NewAESGCMEncryptor(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewCryptoService(enc, false)
+
+	if _, err := s.SignJWS("x"); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("SignJWS without signing key err = %v", err)
+	}
+	if err := s.SetSigningKey([]byte(key)); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("SetSigningKey(encryption key) err = %v", err)
+	}
+	if err := s.SetSigningKey([]byte("short")); err == nil {
+		t.Fatal("short signing key accepted")
+	}
+}
+
+func TestIsJWEFormat(t *testing.T) {
+	s := newJOSEService(t)
+	token, err := s.EncryptJWE("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jws, err := s.SignJWS("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{"jwe", token, true},
+		{"jwe with whitespace", " " + token + "\n", true},
+		{"jws", jws, false},
+		{"envelope json", `{"data":"eyJ.a.b.c.d"}`, false},
+		{"header without enc", strings.Join(append([]string{joseHeaderSegment(t, `{"alg":"dir"}`)}, parts[1:]...), "."), false},
+		{"header not json", "eyJhbGciOi." + strings.Join(parts[1:], "."), false},
+		{"empty ciphertext", strings.Join([]string{parts[0], "", parts[2], "", parts[4]}, "."), false},
+		{"invalid base64", strings.Join([]string{parts[0], "", parts[2], "!!", parts[4]}, "."), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsJWEFormat([]byte(tt.data)); got != tt.want {
+				t.Fatalf("IsJWEFormat = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"crypto/ecdh"
 	"fmt"
 	"github.com/zeromicro/go-zero/core/jsonx"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -10,6 +11,7 @@ import (
 // Manager 加密管理器
 type Manager struct {
 	services map[string]*XCryptoService
+	keys     map[string]*ecdh.PrivateKey // 临时密钥协商使用的服务端私钥
 	mu       sync.RWMutex
 }
 
@@ -17,6 +19,7 @@ type Manager struct {
 func NewManager() *Manager {
 	return &Manager{
 		services: make(map[string]*XCryptoService),
+		keys:     make(map[string]*ecdh.PrivateKey),
 	}
 }
 
@@ -150,6 +153,9 @@ func QuickDecryptString(ciphertext string) (string, error) {
 
 // IsEncryptedFormat 检查是否为加密格式
 func IsEncryptedFormat(data []byte) bool {
+	if IsJWEFormat(data) {
+		return true
+	}
 	var encryptedData EncryptedData
 	if err := jsonx.Unmarshal(data, &encryptedData); err != nil {
 		logx.Errorf("jsonx.Unmarshal failed: %v", err)
@@ -165,6 +171,10 @@ func EncryptRequest(data interface{}) ([]byte, error) {
 		return nil, err
 	}
 
+	if service.Format() == FormatJWE {
+		return service.EncryptPayload(data)
+	}
+
 	encryptedData, err := service.EncryptJSON(data)
 	if err != nil {
 		return nil, err
@@ -177,6 +187,14 @@ func EncryptRequest(data interface{}) ([]byte, error) {
 
 // DecryptRequest 解密请求数据
 func DecryptRequest(encryptedBytes []byte, target interface{}) error {
+	if IsJWEFormat(encryptedBytes) {
+		service, err := GetGlobalService()
+		if err != nil {
+			return err
+		}
+		return service.DecryptPayload(encryptedBytes, target)
+	}
+
 	var encryptedData EncryptedData
 
 	if err := jsonx.Unmarshal(encryptedBytes, &encryptedData); err != nil {
@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NonceStore 请求 nonce 存储，用于拒绝有效期内重放的签名请求
+type NonceStore interface {
+	// Use 记录 nonce，有效期内首次使用返回 true，已使用过返回 false
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// 内存 nonce 存储清理过期 nonce 的间隔
+const nonceSweepInterval = time.Minute
+
+// memoryNonceStore 基于内存的 nonce 存储
+type memoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore 创建内存 nonce 存储，仅适用于单实例
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{nonces: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// Use 记录 nonce，每隔 nonceSweepInterval 清理一次已过期的 nonce
+func (m *memoryNonceStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if expireAt, ok := m.nonces[nonce]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	if now.Sub(m.lastSweep) >= nonceSweepInterval {
+		m.sweep(now)
+	}
+	m.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// sweep 清理已过期的 nonce，调用方需持有锁
+func (m *memoryNonceStore) sweep(now time.Time) {
+	for k, v := range m.nonces {
+		if now.After(v) {
+			delete(m.nonces, k)
+		}
+	}
+	m.lastSweep = now
+}
+
+// redisNonceStore 基于Redis的 nonce 存储
+type redisNonceStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisNonceStore 创建Redis nonce 存储，多实例部署时使用
+func NewRedisNonceStore(rdb redis.UniversalClient, prefix string) NonceStore {
+	if prefix == "" {
+		prefix = "crypto:nonce:"
+	}
+	return &redisNonceStore{rdb: rdb, prefix: prefix}
+}
+
+// Use 使用 SETNX 记录 nonce
+func (r *redisNonceStore) Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, r.prefix+nonce, 1, ttl).Result()
+}
@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// KeyResolver 密钥解析器，用于从KMS、密钥管理服务等获取密钥
+type KeyResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// KeyResolverFunc 函数适配器
+type KeyResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve 实现 KeyResolver 接口
+func (f KeyResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	keyResolvers = map[string]KeyResolver{
+		"env":  KeyResolverFunc(resolveEnvKey),
+		"file": KeyResolverFunc(resolveFileKey),
+	}
+	keyResolversMu sync.RWMutex
+)
+
+// RegisterKeyResolver 注册密钥解析器，如 RegisterKeyResolver("kms", kmsResolver)
+// 之后 kms://key-id 形式的密钥引用会交给该解析器处理
+func RegisterKeyResolver(scheme string, resolver KeyResolver) {
+	keyResolversMu.Lock()
+	defer keyResolversMu.Unlock()
+	keyResolvers[scheme] = resolver
+}
+
+// ResolveKey 解析密钥引用：env:NAME、file:/path、scheme://ref，其余视为明文密钥
+func ResolveKey(ctx context.Context, key string) (string, error) {
+	scheme, ref, ok := splitKeyRef(key)
+	if !ok {
+		return key, nil
+	}
+
+	keyResolversMu.RLock()
+	resolver, exists := keyResolvers[scheme]
+	keyResolversMu.RUnlock()
+	if !exists {
+		return "", fmt.Errorf("no key resolver registered for scheme %q", scheme)
+	}
+
+	resolved, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s key: %w", scheme, err)
+	}
+	return resolved, nil
+}
+
+// Setup 根据配置向全局管理器注册多个命名加密服务
+func Setup(cfg []config.CryptoServiceConfig) error {
+	return globalManager.Setup(cfg)
+}
+
+// MustSetup 根据配置注册多个命名加密服务，失败时退出
+// 替代启动时手动调用 RegisterGlobalAESGCM
+func MustSetup(cfg []config.CryptoServiceConfig) {
+	logx.Must(Setup(cfg))
+}
+
+// Setup 根据配置注册多个命名加密服务，任一服务失败时不注册任何服务
+func (m *Manager) Setup(cfg []config.CryptoServiceConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	type pending struct {
+		name      string
+		algorithm string
+		service   *XCryptoService
+		x25519    []byte
+	}
+
+	// 先全部解析校验，避免部分注册
+	items := make([]pending, 0, len(cfg))
+	for _, svc := range cfg {
+		name := svc.Name
+		if name == "" {
+			name = "default"
+		}
+		algorithm := svc.Algorithm
+		if algorithm == "" {
+			algorithm = "AES-GCM"
+		}
+
+		key, err := ResolveKey(ctx, svc.Key)
+		if err != nil {
+			return fmt.Errorf("crypto service %s: %w", name, err)
+		}
+
+		item := pending{name: name, algorithm: algorithm}
+		switch algorithm {
+		case "AES-GCM":
+			encryptor, err := NewAESGCMEncryptor(key)
+			if err != nil {
+				return fmt.Errorf("crypto service %s: %w", name, err)
+			}
+			item.service = NewCryptoService(encryptor, svc.Debug)
+		case "AES-CBC":
+			encryptor, err := NewAESCBCEncryptor(key)
+			if err != nil {
+				return fmt.Errorf("crypto service %s: %w", name, err)
+			}
+			item.service = NewCryptoService(encryptor, svc.Debug)
+		case "X25519":
+			// X25519私钥使用base64编码
+			item.x25519, err = base64.StdEncoding.DecodeString(key)
+			if err != nil {
+				return fmt.Errorf("crypto service %s: invalid base64 x25519 key: %w", name, err)
+			}
+		default:
+			return fmt.Errorf("crypto service %s: unsupported algorithm: %s", name, algorithm)
+		}
+		if item.service != nil {
+			if err := applyJOSEConfig(ctx, item.service, svc); err != nil {
+				return fmt.Errorf("crypto service %s: %w", name, err)
+			}
+		}
+		items = append(items, item)
+	}
+
+	for _, item := range items {
+		if item.service != nil {
+			m.RegisterService(item.name, item.service)
+		} else if err := m.RegisterX25519Key(item.name, item.x25519); err != nil {
+			return fmt.Errorf("crypto service %s: %w", item.name, err)
+		}
+		logx.Infof("Registered crypto service: %s (%s)", item.name, item.algorithm)
+	}
+	return nil
+}
+
+// applyJOSEConfig 应用输出格式、kid和JWS签名密钥配置
+func applyJOSEConfig(ctx context.Context, service *XCryptoService, svc config.CryptoServiceConfig) error {
+	if err := service.SetFormat(svc.Format); err != nil {
+		return err
+	}
+	service.SetKeyID(svc.KeyID)
+	if svc.SigningKey != "" {
+		signingKey, err := ResolveKey(ctx, svc.SigningKey)
+		if err != nil {
+			return fmt.Errorf("signing key: %w", err)
+		}
+		if err := service.SetSigningKey([]byte(signingKey)); err != nil {
+			return fmt.Errorf("signing key: %w", err)
+		}
+	}
+	return nil
+}
+
+// 拆分密钥引用，返回 scheme 和引用内容
+func splitKeyRef(key string) (string, string, bool) {
+	if scheme, ref, ok := strings.Cut(key, "://"); ok && isKeyScheme(scheme) {
+		return scheme, ref, true
+	}
+	if scheme, ref, ok := strings.Cut(key, ":"); ok && (scheme == "env" || scheme == "file") {
+		return scheme, ref, true
+	}
+	return "", "", false
+}
+
+// scheme 仅允许小写字母和数字，避免误判明文密钥
+func isKeyScheme(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// 从环境变量读取密钥
+func resolveEnvKey(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// 从文件读取密钥，去掉首尾空白（如K8s Secret挂载文件）
+func resolveFileKey(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrSignatureInvalid 请求签名校验失败
+	ErrSignatureInvalid = errors.New("request signature invalid")
+	// ErrSignatureExpired 请求时间戳超出允许的偏差
+	ErrSignatureExpired = errors.New("request signature expired")
+	// ErrSignatureReplayed 请求的 nonce 已被使用
+	ErrSignatureReplayed = errors.New("request signature replayed")
+)
+
+// SignatureInput 参与签名的请求内容
+type SignatureInput struct {
+	Method    string
+	Path      string     // 请求路径，签名前按 path.Clean 规范化
+	Query     url.Values // 查询参数，签名前按 CanonicalQuery 规范化
+	Timestamp int64      // Unix秒
+	Nonce     string     // 请求唯一标识，配合 NonceStore 防重放
+	Body      []byte
+}
+
+// SignRequest 计算请求签名，只保证完整性和来源，不加密报文
+// 签名为 hex(HMAC-SHA256(key, method\npath\ncanonical_query\ntimestamp\nnonce\nhex(sha256(body))))
+func SignRequest(key []byte, in SignatureInput) string {
+	bodyHash := sha256.Sum256(in.Body)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.ToUpper(in.Method) + "\n" + CanonicalPath(in.Path) + "\n" + CanonicalQuery(in.Query) + "\n" +
+		strconv.FormatInt(in.Timestamp, 10) + "\n" + in.Nonce + "\n"))
+	mac.Write([]byte(hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyRequest 校验请求签名和时间戳，maxSkew <=0 时不校验时间戳；nonce 是否重复由调用方通过 NonceStore 校验
+func VerifyRequest(key []byte, in SignatureInput, signature string, maxSkew time.Duration) error {
+	if maxSkew > 0 {
+		if skew := time.Since(time.Unix(in.Timestamp, 0)); skew > maxSkew || skew < -maxSkew {
+			return ErrSignatureExpired
+		}
+	}
+	expected := SignRequest(key, in)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// CanonicalPath 规范化请求路径，与路由匹配使用的路径一致，避免 //a、/a/../b 等写法绕过
+func CanonicalPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	return path.Clean(p)
+}
+
+// CanonicalQuery 规范化查询参数：按参数名排序，同名参数按值排序，键和值使用URL编码
+func CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		values := slices.Clone(query[k])
+		slices.Sort(values)
+		for _, v := range values {
+			if sb.Len() > 0 {
+				sb.WriteByte('&')
+			}
+			sb.WriteString(url.QueryEscape(k))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(v))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,110 @@
+package crypto
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSignRequest(t *testing.T) {
+	key := []byte("partner-secret")
+	now := time.Now().Unix()
+	in := SignatureInput{
+		Method:    "POST",
+		Path:      "/partner/pay",
+		Query:     url.Values{"b": {"2", "1"}, "a": {"x y"}},
+		Timestamp: now,
+		Nonce:     "nonce-0001",
+		Body:      []byte(`{"amount":100}`),
+	}
+	sig := SignRequest(key, in)
+
+	if err := VerifyRequest(key, in, sig, time.Minute); err != nil {
+		t.Fatalf("VerifyRequest = %v", err)
+	}
+
+	// 查询参数顺序和路径写法不影响签名
+	reordered := in
+	reordered.Query = url.Values{"a": {"x y"}, "b": {"1", "2"}}
+	reordered.Path = "//partner/./pay"
+	if err := VerifyRequest(key, reordered, sig, time.Minute); err != nil {
+		t.Errorf("reordered VerifyRequest = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*SignatureInput)
+		want   error
+	}{
+		{"query changed", func(in *SignatureInput) { in.Query = url.Values{"a": {"x y"}, "b": {"1", "3"}} }, ErrSignatureInvalid},
+		{"query added", func(in *SignatureInput) { in.Query = url.Values{"a": {"x y"}, "b": {"1", "2"}, "c": {""}} }, ErrSignatureInvalid},
+		{"path changed", func(in *SignatureInput) { in.Path = "/partner/refund" }, ErrSignatureInvalid},
+		{"method changed", func(in *SignatureInput) { in.Method = "PUT" }, ErrSignatureInvalid},
+		{"body changed", func(in *SignatureInput) { in.Body = []byte(`{"amount":999}`) }, ErrSignatureInvalid},
+		{"nonce changed", func(in *SignatureInput) { in.Nonce = "nonce-0002" }, ErrSignatureInvalid},
+		{"expired", func(in *SignatureInput) { in.Timestamp = now - 3600 }, ErrSignatureExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modified := in
+			tt.modify(&modified)
+			if err := VerifyRequest(key, modified, sig, time.Minute); err != tt.want {
+				t.Errorf("VerifyRequest = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := VerifyRequest([]byte("other"), in, sig, time.Minute); err != ErrSignatureInvalid {
+		t.Errorf("wrong key VerifyRequest = %v", err)
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	q := url.Values{"b": {"2", "1"}, "a": {"x y&z"}}
+	if got := CanonicalQuery(q); got != "a=x+y%26z&b=1&b=2" {
+		t.Errorf("CanonicalQuery = %q", got)
+	}
+	if got := CanonicalQuery(nil); got != "" {
+		t.Errorf("CanonicalQuery(nil) = %q", got)
+	}
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+	if ok, _ := store.Use(ctx, "n1", time.Minute); !ok {
+		t.Fatal("first use rejected")
+	}
+	if ok, _ := store.Use(ctx, "n1", time.Minute); ok {
+		t.Fatal("replay accepted")
+	}
+	if ok, _ := store.Use(ctx, "n2", -time.Second); !ok {
+		t.Fatal("first use rejected")
+	}
+	if ok, _ := store.Use(ctx, "n2", time.Minute); !ok {
+		t.Fatal("expired nonce rejected")
+	}
+}
+
+func TestMemoryNonceStoreSweep(t *testing.T) {
+	store := NewMemoryNonceStore().(*memoryNonceStore)
+	ctx := context.Background()
+	store.Use(ctx, "expired", -time.Second)
+	store.Use(ctx, "live", time.Hour)
+
+	// 未到清理间隔时不遍历
+	store.Use(ctx, "n1", time.Hour)
+	if len(store.nonces) != 3 {
+		t.Fatalf("nonces before sweep interval = %d, want 3", len(store.nonces))
+	}
+
+	store.lastSweep = time.Now().Add(-nonceSweepInterval)
+	store.Use(ctx, "n2", time.Hour)
+	if _, ok := store.nonces["expired"]; ok || len(store.nonces) != 3 {
+		t.Fatalf("nonces after sweep = %v", store.nonces)
+	}
+	if ok, _ := store.Use(ctx, "live", time.Hour); ok {
+		t.Fatal("live nonce dropped by sweep")
+	}
+}
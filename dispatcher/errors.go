@@ -0,0 +1,117 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// retryKind 任务错误的重试方式
+type retryKind int
+
+const (
+	// 不重试，直接归档
+	retrySkip retryKind = iota + 1
+	// 指定时间后重试
+	retryAfter
+	// 不可恢复的错误，不重试，直接归档并记录错误日志
+	retryFatal
+)
+
+// taskError 带重试语义的任务错误，由服务端中间件转换为asynq的重试行为
+type taskError struct {
+	err   error
+	kind  retryKind
+	delay time.Duration
+}
+
+// Error 实现 error 接口
+func (e *taskError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *taskError) Unwrap() error {
+	return e.err
+}
+
+// SkipRetry 标记错误不需要重试，任务直接归档，err 为空时返回空
+// 用于参数错误、数据不存在等重试也不会成功的情况
+func SkipRetry(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &taskError{err: err, kind: retrySkip}
+}
+
+// RetryAfter 标记错误在 d 之后重试（仍受最大重试次数限制），err 为空时返回空
+// 用于限流、依赖服务维护等已知恢复时间的情况
+func RetryAfter(d time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &taskError{err: err, kind: retryAfter, delay: d}
+}
+
+// Fatal 标记不可恢复的错误，任务不重试直接归档，并记录错误日志，err 为空时返回空
+// 用于数据损坏、程序缺陷等需要人工介入的情况
+func Fatal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &taskError{err: err, kind: retryFatal}
+}
+
+// IsSkipRetry 错误是否标记为不重试（SkipRetry 或 Fatal）
+func IsSkipRetry(err error) bool {
+	var te *taskError
+	return errors.As(err, &te) && (te.kind == retrySkip || te.kind == retryFatal)
+}
+
+// IsFatal 错误是否标记为不可恢复
+func IsFatal(err error) bool {
+	var te *taskError
+	return errors.As(err, &te) && te.kind == retryFatal
+}
+
+// RetryDelay 获取 RetryAfter 指定的重试等待时间
+func RetryDelay(err error) (time.Duration, bool) {
+	var te *taskError
+	if errors.As(err, &te) && te.kind == retryAfter {
+		return te.delay, true
+	}
+	return 0, false
+}
+
+// classifyErrors 服务端中间件，将 SkipRetry 和 Fatal 转换为 asynq.SkipRetry
+func classifyErrors(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		err := next.ProcessTask(ctx, task)
+		if err == nil || errors.Is(err, asynq.SkipRetry) {
+			return err
+		}
+
+		taskID, _ := asynq.GetTaskID(ctx)
+		switch {
+		case IsFatal(err):
+			logx.WithContext(ctx).Errorf("Task %s (%s) failed with fatal error, archived without retry: %v", taskID, task.Type(), err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		case IsSkipRetry(err):
+			logx.WithContext(ctx).Infof("Task %s (%s) failed, archived without retry: %v", taskID, task.Type(), err)
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+		return err
+	})
+}
+
+// retryDelay 重试等待时间，RetryAfter 指定的时间优先，否则使用asynq默认的指数退避
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if d, ok := RetryDelay(err); ok && d > 0 {
+		return d
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
@@ -0,0 +1,105 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+func TestTaskErrors(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		err       error
+		skip      bool
+		fatal     bool
+		delay     time.Duration
+		hasDelay  bool
+		wantIsNil bool
+	}{
+		{"plain", base, false, false, 0, false, false},
+		{"skip", SkipRetry(base), true, false, 0, false, false},
+		{"fatal", Fatal(base), true, true, 0, false, false},
+		{"retry after", RetryAfter(time.Minute, base), false, false, time.Minute, true, false},
+		{"wrapped skip", fmt.Errorf("handle: %w", SkipRetry(base)), true, false, 0, false, false},
+		{"wrapped retry after", fmt.Errorf("handle: %w", RetryAfter(time.Second, base)), false, false, time.Second, true, false},
+		{"nil skip", SkipRetry(nil), false, false, 0, false, true},
+		{"nil fatal", Fatal(nil), false, false, 0, false, true},
+		{"nil retry after", RetryAfter(time.Minute, nil), false, false, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.err == nil) != tt.wantIsNil {
+				t.Fatalf("err = %v", tt.err)
+			}
+			if tt.err != nil && !errors.Is(tt.err, base) {
+				t.Fatalf("%v does not unwrap to the original error", tt.err)
+			}
+			if got := IsSkipRetry(tt.err); got != tt.skip {
+				t.Fatalf("IsSkipRetry = %v, want %v", got, tt.skip)
+			}
+			if got := IsFatal(tt.err); got != tt.fatal {
+				t.Fatalf("IsFatal = %v, want %v", got, tt.fatal)
+			}
+			if d, ok := RetryDelay(tt.err); d != tt.delay || ok != tt.hasDelay {
+				t.Fatalf("RetryDelay = %v, %v, want %v, %v", d, ok, tt.delay, tt.hasDelay)
+			}
+		})
+	}
+}
+
+func TestClassifyErrors(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantSkip bool
+	}{
+		{"success", nil, false},
+		{"plain retried", base, false},
+		{"retry after retried", RetryAfter(time.Minute, base), false},
+		{"skip archived", SkipRetry(base), true},
+		{"fatal archived", Fatal(base), true},
+		{"asynq skip kept", fmt.Errorf("%w", asynq.SkipRetry), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := classifyErrors(asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+				return tt.err
+			}))
+			err := h.ProcessTask(context.Background(), asynq.NewTask("test", nil))
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("err = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want it to wrap %v", err, tt.err)
+			}
+			if got := errors.Is(err, asynq.SkipRetry); got != tt.wantSkip {
+				t.Fatalf("errors.Is(SkipRetry) = %v, want %v", got, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	task := asynq.NewTask("test", nil)
+	if got := retryDelay(1, RetryAfter(42*time.Second, errors.New("limited")), task); got != 42*time.Second {
+		t.Fatalf("retryDelay = %v, want 42s", got)
+	}
+	// 未指定时使用asynq默认退避，至少1秒
+	if got := retryDelay(1, errors.New("boom"), task); got < time.Second {
+		t.Fatalf("default retryDelay = %v", got)
+	}
+	if got := retryDelay(1, RetryAfter(0, errors.New("boom")), task); got < time.Second {
+		t.Fatalf("zero RetryAfter delay = %v", got)
+	}
+}
@@ -0,0 +1,226 @@
+package dispatcher
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 默认采集间隔
+const defaultMetricsInterval = 15 * time.Second
+
+var (
+	queueSizeDesc = prometheus.NewDesc(
+		"dispatcher_queue_size",
+		"队列中各状态的任务数",
+		[]string{"queue", "state"}, nil,
+	)
+	queueLatencyDesc = prometheus.NewDesc(
+		"dispatcher_queue_latency_seconds",
+		"队列中最早的待处理任务已等待的时间（秒）",
+		[]string{"queue"}, nil,
+	)
+	queuePausedDesc = prometheus.NewDesc(
+		"dispatcher_queue_paused",
+		"队列是否暂停（1为暂停）",
+		[]string{"queue"}, nil,
+	)
+	queueMemoryDesc = prometheus.NewDesc(
+		"dispatcher_queue_memory_bytes",
+		"队列占用的Redis内存（字节，估算值）",
+		[]string{"queue"}, nil,
+	)
+	processedTotalDesc = prometheus.NewDesc(
+		"dispatcher_tasks_processed_total",
+		"队列已处理的任务总数（含失败）",
+		[]string{"queue"}, nil,
+	)
+	failedTotalDesc = prometheus.NewDesc(
+		"dispatcher_tasks_failed_total",
+		"队列处理失败的任务总数",
+		[]string{"queue"}, nil,
+	)
+	workersActiveDesc = prometheus.NewDesc(
+		"dispatcher_workers_active",
+		"各服务器正在处理任务的worker数",
+		[]string{"host", "pid"}, nil,
+	)
+	workersConcurrencyDesc = prometheus.NewDesc(
+		"dispatcher_workers_concurrency",
+		"各服务器配置的worker并发数",
+		[]string{"host", "pid"}, nil,
+	)
+	workerUtilizationDesc = prometheus.NewDesc(
+		"dispatcher_worker_utilization",
+		"集群worker利用率（活跃worker数/总并发数）",
+		nil, nil,
+	)
+	scrapeErrorsDesc = prometheus.NewDesc(
+		"dispatcher_metrics_scrape_errors_total",
+		"通过Inspector采集指标失败的次数",
+		nil, nil,
+	)
+)
+
+// MetricsCollector 队列指标收集器，后台定期通过 Inspector 采集队列深度、延迟、处理量和worker利用率，
+// Prometheus 抓取时返回最近一次采集的结果，避免每次抓取都访问Redis
+type MetricsCollector struct {
+	inspector *asynq.Inspector
+	interval  time.Duration
+
+	mu           sync.RWMutex
+	queues       []*asynq.QueueInfo
+	servers      []*asynq.ServerInfo
+	scrapeErrors float64
+
+	startOnce sync.Once
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewMetricsCollector 创建队列指标收集器，interval 为采集间隔，<=0 时使用15秒
+// 需调用 Start 开始采集，并将收集器注册到 Prometheus registry
+func NewMetricsCollector(opts *Options, interval time.Duration) *MetricsCollector {
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+	return &MetricsCollector{
+		inspector: asynq.NewInspector(opts.ToRedisClientOpt()),
+		interval:  interval,
+		done:      make(chan struct{}),
+	}
+}
+
+// Start 启动后台采集，重复调用无效
+func (c *MetricsCollector) Start() {
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+}
+
+// Close 停止采集并关闭 Inspector
+func (c *MetricsCollector) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.inspector.Close()
+	})
+	return err
+}
+
+// run 定期采集
+func (c *MetricsCollector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.refresh()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+// refresh 通过 Inspector 采集一次，失败时保留上次结果
+func (c *MetricsCollector) refresh() {
+	names, err := c.inspector.Queues()
+	if err != nil {
+		c.recordError("list queues", err)
+		return
+	}
+
+	queues := make([]*asynq.QueueInfo, 0, len(names))
+	for _, name := range names {
+		info, err := c.inspector.GetQueueInfo(name)
+		if err != nil {
+			c.recordError("get queue info "+name, err)
+			continue
+		}
+		queues = append(queues, info)
+	}
+
+	servers, err := c.inspector.Servers()
+	if err != nil {
+		c.recordError("list servers", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queues = queues
+	if err == nil {
+		c.servers = servers
+	}
+}
+
+// recordError 记录采集失败
+func (c *MetricsCollector) recordError(action string, err error) {
+	logx.Errorf("Dispatcher metrics: failed to %s: %v", action, err)
+	c.mu.Lock()
+	c.scrapeErrors++
+	c.mu.Unlock()
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueSizeDesc
+	ch <- queueLatencyDesc
+	ch <- queuePausedDesc
+	ch <- queueMemoryDesc
+	ch <- processedTotalDesc
+	ch <- failedTotalDesc
+	ch <- workersActiveDesc
+	ch <- workersConcurrencyDesc
+	ch <- workerUtilizationDesc
+	ch <- scrapeErrorsDesc
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, q := range c.queues {
+		for state, n := range map[string]int{
+			"pending":     q.Pending,
+			"active":      q.Active,
+			"scheduled":   q.Scheduled,
+			"retry":       q.Retry,
+			"archived":    q.Archived,
+			"completed":   q.Completed,
+			"aggregating": q.Aggregating,
+		} {
+			ch <- prometheus.MustNewConstMetric(queueSizeDesc, prometheus.GaugeValue, float64(n), q.Queue, state)
+		}
+
+		paused := 0.0
+		if q.Paused {
+			paused = 1
+		}
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, q.Latency.Seconds(), q.Queue)
+		ch <- prometheus.MustNewConstMetric(queuePausedDesc, prometheus.GaugeValue, paused, q.Queue)
+		ch <- prometheus.MustNewConstMetric(queueMemoryDesc, prometheus.GaugeValue, float64(q.MemoryUsage), q.Queue)
+		ch <- prometheus.MustNewConstMetric(processedTotalDesc, prometheus.CounterValue, float64(q.ProcessedTotal), q.Queue)
+		ch <- prometheus.MustNewConstMetric(failedTotalDesc, prometheus.CounterValue, float64(q.FailedTotal), q.Queue)
+	}
+
+	var active, concurrency int
+	for _, s := range c.servers {
+		pid := strconv.Itoa(s.PID)
+		ch <- prometheus.MustNewConstMetric(workersActiveDesc, prometheus.GaugeValue, float64(len(s.ActiveWorkers)), s.Host, pid)
+		ch <- prometheus.MustNewConstMetric(workersConcurrencyDesc, prometheus.GaugeValue, float64(s.Concurrency), s.Host, pid)
+		active += len(s.ActiveWorkers)
+		concurrency += s.Concurrency
+	}
+	if concurrency > 0 {
+		ch <- prometheus.MustNewConstMetric(workerUtilizationDesc, prometheus.GaugeValue, float64(active)/float64(concurrency))
+	}
+
+	ch <- prometheus.MustNewConstMetric(scrapeErrorsDesc, prometheus.CounterValue, c.scrapeErrors)
+}
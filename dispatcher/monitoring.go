@@ -0,0 +1,69 @@
+package dispatcher
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/hibiken/asynqmon"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// MonitoringHandler 返回带鉴权的监控处理器，可挂载到已有的 go-zero REST 服务上，
+// 无需单独开启监控端口，如：
+//
+//	rest.MustNewServer(c, rest.WithNotFoundHandler(srv.MonitoringHandler()))
+func (s *Server) MonitoringHandler() http.Handler {
+	return NewMonitoringHandler(s.opts)
+}
+
+// NewMonitoringHandler 根据配置创建带鉴权的监控处理器，只有请求路径在 Monitoring.Path 下时才处理，
+// 其余请求返回404，便于作为兜底处理器使用
+func NewMonitoringHandler(opts *Options) http.Handler {
+	h := asynqmon.New(asynqmon.Options{
+		RootPath:     opts.Monitoring.Path,
+		RedisConnOpt: opts.ToRedisClientOpt(),
+		ReadOnly:     opts.Monitoring.ReadOnly,
+	})
+
+	rootPath := h.RootPath()
+	if !strings.HasSuffix(rootPath, "/") {
+		rootPath += "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(rootPath, h)
+
+	return monitoringAuth(opts.Monitoring, mux)
+}
+
+// monitoringAuth 监控页面鉴权，支持 Basic 认证和 Bearer Token，均未配置时不鉴权
+func monitoringAuth(c MonitoringConfig, next http.Handler) http.Handler {
+	if c.Username == "" && c.Token == "" {
+		logx.Info("Monitoring authentication is disabled")
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.Token != "" {
+			if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok &&
+				subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if c.Username != "" {
+			user, pass, ok := r.BasicAuth()
+			if ok &&
+				subtle.ConstantTimeCompare([]byte(user), []byte(c.Username)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(pass), []byte(c.Password)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Basic realm="monitoring"`)
+		}
+
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
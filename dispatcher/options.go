@@ -31,13 +31,22 @@ type ServerConfig struct {
 	Concurrency     int           `json:"concurrency,optional"`
 	ShutdownTimeout int           `json:"shutdownTimeout,optional"`
 	QueuePriorities QueuePriority `json:"queuePriorities,optional"`
+	// Timezone 定时任务默认时区（IANA名称，如 Asia/Shanghai），默认使用本地时区
+	Timezone string `json:"timezone,optional"`
 }
 
 // MonitoringConfig 包含监控服务配置
 type MonitoringConfig struct {
-	Enabled bool   `json:"enabled,optional"`
-	Address string `json:"address,optional"`
-	Path    string `json:"path,optional"`
+	Enabled  bool   `json:"enabled,optional"`
+	Address  string `json:"address,optional"`
+	Path     string `json:"path,optional"`
+	Embedded bool   `json:"embedded,optional"` // 挂载到已有服务上，不单独监听端口
+	ReadOnly bool   `json:"readOnly,optional"` // 只读模式，禁止在页面上操作任务
+	Username string `json:"username,optional"` // Basic 认证用户名
+	Password string `json:"password,optional"` // Basic 认证密码
+	Token    string `json:"token,optional"`    // Bearer Token
+	// MetricsInterval 队列指标采集间隔（秒），默认15
+	MetricsInterval int `json:"metricsInterval,optional"`
 }
 
 // Options 包含所有组件配置
@@ -84,6 +93,18 @@ func DefaultOptions() *Options {
 	}
 }
 
+// Location 定时任务默认时区
+func (o *Options) Location() (*time.Location, error) {
+	if o.Server.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(o.Server.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scheduler timezone %q: %w", o.Server.Timezone, err)
+	}
+	return loc, nil
+}
+
 // ToRedisClientOpt 转换为asynq.RedisClientOpt
 func (o *Options) ToRedisClientOpt() asynq.RedisClientOpt {
 	return asynq.RedisClientOpt{
@@ -138,6 +159,9 @@ func (c *Options) ToDispatcherOptions() (*Options, error) {
 	if c.Server.ShutdownTimeout != 0 {
 		opts.Server.ShutdownTimeout = c.Server.ShutdownTimeout
 	}
+	if c.Server.Timezone != "" {
+		opts.Server.Timezone = c.Server.Timezone
+	}
 
 	// 设置队列优先级
 	if c.Server.QueuePriorities.Low != 0 {
@@ -158,6 +182,12 @@ func (c *Options) ToDispatcherOptions() (*Options, error) {
 	if c.Monitoring.Path != "" {
 		opts.Monitoring.Path = c.Monitoring.Path
 	}
+	opts.Monitoring.Embedded = c.Monitoring.Embedded
+	opts.Monitoring.ReadOnly = c.Monitoring.ReadOnly
+	opts.Monitoring.Username = c.Monitoring.Username
+	opts.Monitoring.Password = c.Monitoring.Password
+	opts.Monitoring.Token = c.Monitoring.Token
+	opts.Monitoring.MetricsInterval = c.Monitoring.MetricsInterval
 
 	return opts, nil
 }
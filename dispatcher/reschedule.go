@@ -0,0 +1,124 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ErrTaskNotMovable indicates that the task is active or completed and cannot be rescheduled.
+var ErrTaskNotMovable = errors.New("task cannot be rescheduled in its current state")
+
+// 恢复归档任务时先按该延迟入队再归档
+const restoreArchivedDelay = 24 * time.Hour
+
+// Reschedule 修改任务的执行时间，processAt 早于当前时间时立即执行
+// 通过删除后重新入队实现，保留任务ID、类型、负载、队列、最大重试次数、超时和保留时间，
+// 已重试次数清零，唯一性锁（TaskUnique）不保留；任务需处于 pending、scheduled、retry 或 archived 状态
+func (c *Client) Reschedule(ctx context.Context, taskID string, processAt time.Time) (*asynq.TaskInfo, error) {
+	info, err := c.findMovableTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return c.requeue(ctx, info, asynq.Queue(info.Queue), asynq.ProcessAt(processAt))
+}
+
+// BumpPriority 将任务移到指定队列（通常为权重更高的队列）并立即执行，实现方式与 Reschedule 相同
+func (c *Client) BumpPriority(ctx context.Context, taskID, queue string) (*asynq.TaskInfo, error) {
+	if queue == "" {
+		return nil, fmt.Errorf("queue cannot be empty")
+	}
+	info, err := c.findMovableTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return c.requeue(ctx, info, asynq.Queue(queue))
+}
+
+// findMovableTask 在所有队列中查找可重新入队的任务
+func (c *Client) findMovableTask(taskID string) (*asynq.TaskInfo, error) {
+	queues, err := c.inspector.Queues()
+	if err != nil {
+		return nil, fmt.Errorf("获取队列列表失败: %w", err)
+	}
+
+	for _, queue := range queues {
+		info, err := c.inspector.GetTaskInfo(queue, taskID)
+		if err != nil {
+			continue
+		}
+		switch info.State {
+		case asynq.TaskStatePending, asynq.TaskStateScheduled, asynq.TaskStateRetry, asynq.TaskStateArchived:
+			return info, nil
+		default:
+			return nil, fmt.Errorf("%w: task %s is %s", ErrTaskNotMovable, taskID, info.State)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+}
+
+// requeue 删除任务后按原有选项和 opts 重新入队，入队失败时尽量按原队列、执行时间和归档状态恢复
+func (c *Client) requeue(ctx context.Context, info *asynq.TaskInfo, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if err := c.inspector.DeleteTask(info.Queue, info.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete task %s: %w", info.ID, err)
+	}
+
+	task := asynq.NewTask(info.Type, info.Payload)
+	base := preservedOptions(info)
+	newInfo, err := c.cli.EnqueueContext(ctx, task, append(base[:len(base):len(base)], opts...)...)
+	if err == nil {
+		return newInfo, nil
+	}
+
+	// 恢复原任务，避免任务丢失；负载可能含敏感数据，日志只记录任务ID和类型
+	if rerr := c.restore(context.WithoutCancel(ctx), task, info, base); rerr != nil {
+		logx.WithContext(ctx).Errorf("Task %s (%s) lost while rescheduling, error: %v", info.ID, info.Type, rerr)
+	}
+	return nil, fmt.Errorf("failed to re-enqueue task %s: %w", info.ID, err)
+}
+
+// restore 按原队列和执行时间重新入队，原任务已归档时重新归档，避免归档任务被意外执行
+func (c *Client) restore(ctx context.Context, task *asynq.Task, info *asynq.TaskInfo, base []asynq.Option) error {
+	opts := append(base[:len(base):len(base)], asynq.Queue(info.Queue))
+	if info.State == asynq.TaskStateArchived {
+		// 入队到远期再归档，归档前不会被执行
+		opts = append(opts, asynq.ProcessIn(restoreArchivedDelay))
+	} else if !info.NextProcessAt.IsZero() {
+		opts = append(opts, asynq.ProcessAt(info.NextProcessAt))
+	}
+	if _, err := c.cli.EnqueueContext(ctx, task, opts...); err != nil {
+		return err
+	}
+
+	if info.State == asynq.TaskStateArchived {
+		if err := c.inspector.ArchiveTask(info.Queue, info.ID); err != nil {
+			return fmt.Errorf("restored but failed to archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// preservedOptions 重新入队时保留的任务选项
+func preservedOptions(info *asynq.TaskInfo) []asynq.Option {
+	opts := []asynq.Option{
+		asynq.TaskID(info.ID),
+		asynq.MaxRetry(info.MaxRetry),
+	}
+	if info.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(info.Timeout))
+	}
+	if !info.Deadline.IsZero() {
+		opts = append(opts, asynq.Deadline(info.Deadline))
+	}
+	if info.Retention > 0 {
+		opts = append(opts, asynq.Retention(info.Retention))
+	}
+	if info.Group != "" {
+		opts = append(opts, asynq.Group(info.Group))
+	}
+	return opts
+}
@@ -0,0 +1,102 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+)
+
+// newTestClient 创建连接 miniredis 的客户端
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	opts := DefaultOptions()
+	opts.Redis.Addr = mr.Addr()
+	c, err := NewClient(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		c.Close()
+		c.inspector.Close()
+	})
+	return c
+}
+
+// enqueueInState 入队任务并置为指定状态
+func enqueueInState(t *testing.T, c *Client, id string, state asynq.TaskState) {
+	t.Helper()
+	opts := []asynq.Option{asynq.TaskID(id), asynq.MaxRetry(3)}
+	if state == asynq.TaskStateScheduled {
+		opts = append(opts, asynq.ProcessIn(time.Hour))
+	}
+	if _, err := c.cli.Enqueue(asynq.NewTask("payout", []byte(`{"card":"4111111111111111"}`)), opts...); err != nil {
+		t.Fatal(err)
+	}
+	if state == asynq.TaskStateArchived {
+		if err := c.inspector.ArchiveTask("default", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRescheduleRestoresOnFailure(t *testing.T) {
+	tests := []struct {
+		name  string
+		state asynq.TaskState
+	}{
+		{"pending", asynq.TaskStatePending},
+		{"scheduled", asynq.TaskStateScheduled},
+		{"archived", asynq.TaskStateArchived},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t)
+			enqueueInState(t, c, "t1", tt.state)
+			before, err := c.inspector.GetTaskInfo("default", "t1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 已取消的上下文使重新入队失败，恢复不受取消影响
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err = c.Reschedule(ctx, "t1", time.Now().Add(time.Minute)); err == nil {
+				t.Fatal("expected re-enqueue failure")
+			}
+
+			after, err := c.inspector.GetTaskInfo("default", "t1")
+			if err != nil {
+				t.Fatalf("task lost: %v", err)
+			}
+			if after.State != tt.state || string(after.Payload) != string(before.Payload) || after.MaxRetry != before.MaxRetry {
+				t.Fatalf("restored task = %+v, want state %s", after, tt.state)
+			}
+			if tt.state == asynq.TaskStateScheduled && !after.NextProcessAt.Equal(before.NextProcessAt) {
+				t.Fatalf("process at = %v, want %v", after.NextProcessAt, before.NextProcessAt)
+			}
+		})
+	}
+}
+
+func TestReschedule(t *testing.T) {
+	c := newTestClient(t)
+	enqueueInState(t, c, "t1", asynq.TaskStateArchived)
+
+	processAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	info, err := c.Reschedule(context.Background(), "t1", processAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != "t1" || info.State != asynq.TaskStateScheduled || !info.NextProcessAt.Equal(processAt) {
+		t.Fatalf("rescheduled task = %+v", info)
+	}
+
+	if _, err = c.Reschedule(context.Background(), "missing", processAt); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("err = %v, want ErrTaskNotFound", err)
+	}
+}
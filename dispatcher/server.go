@@ -9,7 +9,7 @@ import (
 	"time"
 
 	"github.com/hibiken/asynq"
-	"github.com/hibiken/asynqmon"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
@@ -34,6 +34,8 @@ type Server struct {
 	scheduler        *asynq.Scheduler
 	mux              *asynq.ServeMux
 	monitoringServer *http.Server
+	metrics          *MetricsCollector
+	metricsMu        sync.Mutex
 	wg               sync.WaitGroup
 	mu               sync.Mutex
 	running          bool
@@ -47,6 +49,11 @@ func NewServer(opts *Options) (*Server, error) {
 
 	redisOpt := opts.ToRedisClientOpt()
 
+	loc, err := opts.Location()
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建日志适配器
 	logger := NewLogxAdapter()
 
@@ -61,6 +68,7 @@ func NewServer(opts *Options) (*Server, error) {
 				PriorityHigh.String():   opts.Server.QueuePriorities.High,
 			},
 			ShutdownTimeout: time.Duration(opts.Server.ShutdownTimeout) * time.Second,
+			RetryDelayFunc:  retryDelay,
 			Logger:          logger,
 			LogLevel:        asynq.InfoLevel,
 		},
@@ -70,7 +78,7 @@ func NewServer(opts *Options) (*Server, error) {
 	scheduler := asynq.NewScheduler(
 		redisOpt,
 		&asynq.SchedulerOpts{
-			Location: time.Local,
+			Location: loc,
 			Logger:   logger,
 			LogLevel: asynq.InfoLevel,
 		},
@@ -82,11 +90,13 @@ func NewServer(opts *Options) (*Server, error) {
 		srv:       srv,
 		scheduler: scheduler,
 	}
+	// 按 SkipRetry、RetryAfter、Fatal 控制重试
+	server.mux.Use(classifyErrors)
 
 	return server, nil
 }
 
-// Register 注册定时任务
+// Register 注册定时任务，按配置的默认时区执行
 func (s *Server) Register(cronspec string, task *asynq.Task, opts ...asynq.Option) error {
 	entryID, err := s.scheduler.Register(cronspec, task, opts...)
 	if err != nil {
@@ -96,6 +106,19 @@ func (s *Server) Register(cronspec string, task *asynq.Task, opts ...asynq.Optio
 	return nil
 }
 
+// RegisterIn 注册指定时区的定时任务，zone 为IANA时区名称，如各市场的结算任务在当地时间 00:00 执行：
+// RegisterIn("America/Sao_Paulo", "0 0 * * *", task)
+func (s *Server) RegisterIn(zone, cronspec string, task *asynq.Task, opts ...asynq.Option) error {
+	if _, err := time.LoadLocation(zone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", zone, err)
+	}
+	spec := strings.TrimSpace(cronspec)
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		return fmt.Errorf("cronspec %q already specifies a timezone", cronspec)
+	}
+	return s.Register(fmt.Sprintf("CRON_TZ=%s %s", zone, spec), task, opts...)
+}
+
 // HandleFunc 注册处理函数
 func (s *Server) HandleFunc(pattern string, handler asynq.HandlerFunc) {
 	s.mux.HandleFunc(pattern, handler)
@@ -108,6 +131,33 @@ func (s *Server) Handle(pattern string, handler asynq.Handler) {
 	logx.Infof("Registered handler for pattern: %s", pattern)
 }
 
+// Collector 返回队列指标收集器并启动后台采集，由业务方注册到自己的 Prometheus registry，
+// 服务停止时停止采集
+func (s *Server) Collector() prometheus.Collector {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+
+	if s.metrics == nil {
+		s.metrics = NewMetricsCollector(s.opts, time.Duration(s.opts.Monitoring.MetricsInterval)*time.Second)
+		s.metrics.Start()
+	}
+	return s.metrics
+}
+
+// closeMetrics 停止队列指标采集，可与 Collector 并发调用
+func (s *Server) closeMetrics() {
+	s.metricsMu.Lock()
+	metrics := s.metrics
+	s.metricsMu.Unlock()
+
+	if metrics == nil {
+		return
+	}
+	if err := metrics.Close(); err != nil {
+		logx.Errorf("Error closing metrics collector: %v", err)
+	}
+}
+
 // StartMonitoring 启动监控服务
 func (s *Server) StartMonitoring() error {
 	s.mu.Lock()
@@ -118,23 +168,21 @@ func (s *Server) StartMonitoring() error {
 		return nil
 	}
 
-	address := s.opts.Monitoring.Address
-	h := asynqmon.New(asynqmon.Options{
-		RootPath:     s.opts.Monitoring.Path,
-		RedisConnOpt: s.opts.ToRedisClientOpt(),
-	})
+	// 已挂载到业务服务上，不单独监听
+	if s.opts.Monitoring.Embedded {
+		logx.Info("Monitoring is embedded, skip standalone listener")
+		return nil
+	}
 
-	rootPath := h.RootPath()
+	address := s.opts.Monitoring.Address
+	rootPath := s.opts.Monitoring.Path
 	if !strings.HasSuffix(rootPath, "/") {
 		rootPath += "/"
 	}
 
-	mux := http.NewServeMux()
-	mux.Handle(rootPath, h)
-
 	s.monitoringServer = &http.Server{
 		Addr:         address,
-		Handler:      mux,
+		Handler:      s.MonitoringHandler(),
 		ReadTimeout:  1 * time.Minute,
 		WriteTimeout: 1 * time.Minute,
 	}
@@ -217,6 +265,8 @@ func (s *Server) GracefulStop(ctx context.Context) error {
 		}
 	}
 
+	s.closeMetrics()
+
 	// 优雅关闭调度器
 	logx.Info("Shutting down scheduler")
 	s.scheduler.Shutdown()
@@ -258,6 +308,8 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 
+	s.closeMetrics()
+
 	// 立即停止服务器
 	logx.Info("Stopping scheduler")
 	s.scheduler.Shutdown()
@@ -0,0 +1,281 @@
+package etcdc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/zeromicro/go-zero/core/logx"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// 差异摘要最多列出的字段数
+	maxDiffFields = 20
+	// 敏感字段值的替代文本
+	redactedAuditValue = "******"
+)
+
+// 敏感字段名后缀，按忽略大小写、-和_后匹配，如 api_key、AccessKey、db-password
+var secretFieldSuffixes = []string{"password", "passwd", "pwd", "secret", "token", "key", "credential", "authorization", "dsn"}
+
+// AuditRecord 配置变更审计记录
+type AuditRecord struct {
+	// 完整key
+	Key string `json:"key"`
+	// 变更类型：put、delete
+	Type string `json:"type"`
+	// 变更所在的etcd版本号，单key配置中心无法获取时为0
+	Revision int64 `json:"revision"`
+	// key的修改次数，删除时为0
+	Version int64 `json:"version"`
+	// 差异摘要，如 rate_limit: 100 → 200
+	Diff string `json:"diff"`
+	// 观察到变更的时间
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AuditSink 审计记录输出，可实现为写日志、写数据库或投递异步任务
+type AuditSink interface {
+	Record(ctx context.Context, rec AuditRecord) error
+}
+
+// AuditSinkFunc 函数形式的审计输出
+type AuditSinkFunc func(ctx context.Context, rec AuditRecord) error
+
+// Record 实现 AuditSink 接口
+func (f AuditSinkFunc) Record(ctx context.Context, rec AuditRecord) error {
+	return f(ctx, rec)
+}
+
+// LogxAuditSink 输出到 logx 的审计记录，差异摘要中的敏感字段已脱敏
+func LogxAuditSink() AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, rec AuditRecord) error {
+		logx.WithContext(ctx).Infow("etcd config changed",
+			logx.Field("key", rec.Key),
+			logx.Field("type", rec.Type),
+			logx.Field("revision", rec.Revision),
+			logx.Field("version", rec.Version),
+			logx.Field("diff", rec.Diff),
+			logx.Field("timestamp", rec.Timestamp))
+		return nil
+	})
+}
+
+// auditor 审计输出集合
+type auditor struct {
+	mu    sync.RWMutex
+	sinks []AuditSink
+}
+
+// add 添加审计输出
+func (a *auditor) add(sinks ...AuditSink) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sinks = append(a.sinks, sinks...)
+}
+
+// record 写入所有审计输出，失败只记录日志
+func (a *auditor) record(rec AuditRecord) {
+	a.mu.RLock()
+	sinks := a.sinks
+	a.mu.RUnlock()
+
+	for _, s := range sinks {
+		if err := s.Record(context.Background(), rec); err != nil {
+			logx.Errorf("Failed to record config audit for %s: %v", rec.Key, err)
+		}
+	}
+}
+
+// enabled 是否有审计输出
+func (a *auditor) enabled() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.sinks) > 0
+}
+
+// Audit 添加配置变更审计输出，记录监听到的每个key的新增、更新、删除
+func (m *MultiEtcd[T]) Audit(sinks ...AuditSink) {
+	m.audit.add(sinks...)
+}
+
+// 记录etcd变更事件
+func (m *MultiEtcd[T]) auditEvent(ev *clientv3.Event) {
+	if !m.audit.enabled() {
+		return
+	}
+
+	rec := AuditRecord{
+		Key:       string(ev.Kv.Key),
+		Revision:  ev.Kv.ModRevision,
+		Timestamp: time.Now(),
+	}
+
+	var prev []byte
+	if ev.PrevKv != nil {
+		prev = ev.PrevKv.Value
+	}
+
+	switch ev.Type {
+	case clientv3.EventTypePut:
+		rec.Type = "put"
+		rec.Version = ev.Kv.Version
+		rec.Diff = diffSummary(rec.Key, prev, ev.Kv.Value)
+	case clientv3.EventTypeDelete:
+		rec.Type = "delete"
+		rec.Diff = diffSummary(rec.Key, prev, nil)
+	}
+
+	m.audit.record(rec)
+}
+
+// Audit 添加配置变更审计输出，单key配置中心只能观察到变更后的值，Revision 为0
+func (ctr *Etcd[T]) Audit(sinks ...AuditSink) {
+	ctr.audit.add(sinks...)
+
+	ctr.auditOnce.Do(func() {
+		last, _ := ctr.currentJSON()
+		ctr.configurator.AddListener(func() {
+			cur, err := ctr.currentJSON()
+			if err != nil || bytes.Equal(last, cur) {
+				return
+			}
+			ctr.audit.record(AuditRecord{
+				Key:       ctr.key,
+				Type:      "put",
+				Diff:      diffSummary(ctr.key, last, cur),
+				Timestamp: time.Now(),
+			})
+			last = cur
+		})
+	})
+}
+
+// 当前配置的JSON
+func (ctr *Etcd[T]) currentJSON() ([]byte, error) {
+	v, err := ctr.configurator.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// diffSummary 生成JSON配置的差异摘要，对象按顶层字段比较，其他类型整体比较
+// 密码、密钥、令牌等敏感字段（包括key本身为敏感名称时的整体值）只记录变更，不记录值
+func diffSummary(key string, prev, cur []byte) string {
+	switch {
+	case prev == nil && cur == nil:
+		return ""
+	case prev == nil:
+		return fmt.Sprintf("created (%d bytes)", len(cur))
+	case cur == nil:
+		return fmt.Sprintf("deleted (%d bytes)", len(prev))
+	}
+
+	var before, after map[string]any
+	if json.Unmarshal(prev, &before) != nil || json.Unmarshal(cur, &after) != nil {
+		if bytes.Equal(prev, cur) {
+			return "unchanged"
+		}
+		if name := path.Base(key); isSecretField(name) {
+			return fmt.Sprintf("%s → %s", redactedAuditValue, redactedAuditValue)
+		}
+		return fmt.Sprintf("%s → %s", truncateValue(string(prev)), truncateValue(string(cur)))
+	}
+
+	keys := make(map[string]struct{}, len(before)+len(after))
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, k := range sorted {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			changes = append(changes, fmt.Sprintf("+%s: %s", k, jsonValue(k, a)))
+		case !inAfter:
+			changes = append(changes, fmt.Sprintf("-%s: %s", k, jsonValue(k, b)))
+		case !reflect.DeepEqual(a, b):
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", k, jsonValue(k, b), jsonValue(k, a)))
+		}
+	}
+
+	if len(changes) == 0 {
+		return "unchanged"
+	}
+	if len(changes) > maxDiffFields {
+		changes = append(changes[:maxDiffFields], fmt.Sprintf("... %d more", len(changes)-maxDiffFields))
+	}
+	return strings.Join(changes, "; ")
+}
+
+// jsonValue 字段值脱敏后的简短表示
+func jsonValue(name string, v any) string {
+	data, err := json.Marshal(redactValue(name, v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return truncateValue(string(data))
+}
+
+// truncateValue 截断过长的值
+func truncateValue(s string) string {
+	const maxLen = 64
+	if r := []rune(s); len(r) > maxLen {
+		return string(r[:maxLen]) + "..."
+	}
+	return s
+}
+
+// redactValue 脱敏字段值，敏感字段整体隐藏，对象和数组递归处理，其他字段按日志脱敏策略处理
+func redactValue(name string, v any) any {
+	if isSecretField(name) {
+		return redactedAuditValue
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(val))
+		for k, item := range val {
+			result[k] = redactValue(k, item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for i, item := range val {
+			result[i] = redactValue(name, item)
+		}
+		return result
+	}
+	return metadata.Scrub(name, v)
+}
+
+// isSecretField 字段名是否为敏感字段
+func isSecretField(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, suffix := range secretFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package etcdc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffSummaryRedactsSecrets(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		prev, cur string
+		want      []string
+		hidden    []string
+	}{
+		{
+			name:   "changed secret field",
+			key:    "/app/db",
+			prev:   `{"host":"db1","password":"old-pass"}`,
+			cur:    `{"host":"db2","password":"new-pass"}`,
+			want:   []string{`host: "db1" → "db2"`, `password: "******" → "******"`},
+			hidden: []string{"old-pass", "new-pass"},
+		},
+		{
+			name:   "added api key",
+			key:    "/app/pay",
+			prev:   `{}`,
+			cur:    `{"api_key":"sk_live_123","AccessKey":"AKID"}`,
+			want:   []string{`+AccessKey: "******"`, `+api_key: "******"`},
+			hidden: []string{"sk_live_123", "AKID"},
+		},
+		{
+			name:   "nested secret",
+			key:    "/app/oss",
+			prev:   `{"oss":{"bucket":"a","secret_key":"s1"}}`,
+			cur:    `{"oss":{"bucket":"b","secret_key":"s2"}}`,
+			want:   []string{`"bucket":"a"`, `"secret_key":"******"`},
+			hidden: []string{"s1", "s2"},
+		},
+		{
+			name:   "secret key value",
+			key:    "/app/jwt_secret",
+			prev:   `plain-old`,
+			cur:    `plain-new`,
+			want:   []string{"****** → ******"},
+			hidden: []string{"plain-old", "plain-new"},
+		},
+		{
+			name: "plain value",
+			key:  "/app/rate",
+			prev: `100`,
+			cur:  `200`,
+			want: []string{"100 → 200"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffSummary(tt.key, []byte(tt.prev), []byte(tt.cur))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Fatalf("diff %q does not contain %q", got, want)
+				}
+			}
+			for _, hidden := range tt.hidden {
+				if strings.Contains(got, hidden) {
+					t.Fatalf("diff %q leaks %q", got, hidden)
+				}
+			}
+		})
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"github.com/zeromicro/go-zero/core/configcenter/subscriber"
 	"github.com/zeromicro/go-zero/core/logx"
 	"strings"
+	"sync"
 )
 
 type Etcd[T any] struct {
 	configurator configurator.Configurator[T]
+	key          string
+	audit        auditor
+	auditOnce    sync.Once
 }
 
 // NewEtcd 实例化etcd
@@ -19,6 +23,7 @@ func NewEtcd[T any](c Config) *Etcd[T] {
 	cc.Hosts = strings.Split(c.Host, ",")
 
 	return &Etcd[T]{
+		key: c.Key,
 		configurator: configurator.MustNewConfigCenter[T](configurator.Config{
 			Type: "json",
 		}, subscriber.MustNewEtcdSubscriber(cc)),
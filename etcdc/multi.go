@@ -0,0 +1,322 @@
+package etcdc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// EventType 配置变更类型
+type EventType int
+
+const (
+	// EventPut 新增或更新
+	EventPut EventType = iota
+	// EventDelete 删除
+	EventDelete
+)
+
+// Event 单个key的配置变更事件
+type Event[T any] struct {
+	Type  EventType
+	Key   string // 去掉前缀后的key
+	Value T      // 删除事件为删除前的值
+}
+
+// MultiEtcd 前缀下多key配置中心，将前缀下的所有key解析为类型化的注册表，并增量监听变更
+// 适用于游戏、渠道等运行时会增删key的动态配置
+type MultiEtcd[T any] struct {
+	cli       *clientv3.Client
+	prefix    string
+	mu        sync.RWMutex
+	values    map[string]T
+	listeners []func(ev Event[T])
+	audit     auditor
+	// 值无法解析时删除该key而不是保留旧值，由调用方回退到默认值
+	dropInvalid bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewMultiEtcd 实例化前缀配置中心，Config.Key 作为前缀
+func NewMultiEtcd[T any](c Config) (*MultiEtcd[T], error) {
+	return newMultiEtcd[T](c, false)
+}
+
+// 实例化前缀配置中心，lenient 为 true 时首次加载失败不返回错误而是在后台重试，
+// 且无法解析的值视为key不存在
+func newMultiEtcd[T any](c Config, lenient bool) (*MultiEtcd[T], error) {
+	cli, err := newClient(c)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MultiEtcd[T]{
+		cli:         cli,
+		prefix:      c.Key,
+		values:      make(map[string]T),
+		dropInvalid: lenient,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	rev, err := m.load()
+	if err == nil {
+		go m.watch(rev)
+		return m, nil
+	}
+	if !lenient {
+		cancel()
+		_ = cli.Close()
+		return nil, err
+	}
+
+	logx.Errorf("Load prefix %s failed, retrying in background: %v", m.prefix, err)
+	go func() {
+		if rev, ok := m.reload(); ok {
+			m.watch(rev)
+		}
+	}()
+	return m, nil
+}
+
+// MustNewMultiEtcd 实例化前缀配置中心，失败时退出
+func MustNewMultiEtcd[T any](c Config) *MultiEtcd[T] {
+	m, err := NewMultiEtcd[T](c)
+	logx.Must(err)
+	return m
+}
+
+// Get 获取指定key的配置
+func (m *MultiEtcd[T]) Get(key string) (T, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// All 获取全部配置的快照
+func (m *MultiEtcd[T]) All() map[string]T {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make(map[string]T, len(m.values))
+	for k, v := range m.values {
+		result[k] = v
+	}
+	return result
+}
+
+// Keys 获取全部key
+func (m *MultiEtcd[T]) Keys() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.values))
+	for k := range m.values {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Listener 添加变更监听，每个key的新增、更新、删除都会触发一次
+func (m *MultiEtcd[T]) Listener(listener func(ev Event[T])) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.listeners = append(m.listeners, listener)
+}
+
+// Close 停止监听并关闭客户端
+func (m *MultiEtcd[T]) Close() error {
+	m.cancel()
+	return m.cli.Close()
+}
+
+// 全量加载前缀下的配置，返回加载时的版本号
+func (m *MultiEtcd[T]) load() (int64, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+
+	resp, err := m.cli.Get(ctx, m.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return 0, fmt.Errorf("load prefix %s: %w", m.prefix, err)
+	}
+
+	values := make(map[string]T, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), m.prefix)
+		var v T
+		if err := json.Unmarshal(kv.Value, &v); err != nil {
+			logx.Errorf("Failed to decode config %s: %v", kv.Key, err)
+			continue
+		}
+		values[key] = v
+	}
+
+	m.mu.Lock()
+	old := m.values
+	m.values = values
+	m.mu.Unlock()
+
+	// 重新加载时只补发有差异的事件，未变化的key不通知
+	for k, v := range values {
+		if prev, ok := old[k]; ok && reflect.DeepEqual(prev, v) {
+			continue
+		}
+		m.notify(Event[T]{Type: EventPut, Key: k, Value: v})
+	}
+	for k, v := range old {
+		if _, ok := values[k]; !ok {
+			m.notify(Event[T]{Type: EventDelete, Key: k, Value: v})
+		}
+	}
+
+	logx.Infof("Loaded %d configs under prefix %s", len(values), m.prefix)
+	return resp.Header.Revision, nil
+}
+
+// 增量监听前缀变更，版本被压缩时全量重新加载
+func (m *MultiEtcd[T]) watch(rev int64) {
+	for {
+		wch := m.cli.Watch(m.ctx, m.prefix, clientv3.WithPrefix(), clientv3.WithPrevKV(), clientv3.WithRev(rev+1))
+		for resp := range wch {
+			if err := resp.Err(); err != nil {
+				logx.Errorf("Watch prefix %s error: %v", m.prefix, err)
+				if errors.Is(err, rpctypes.ErrCompacted) {
+					break
+				}
+				continue
+			}
+
+			for _, ev := range resp.Events {
+				m.apply(ev)
+				m.auditEvent(ev)
+			}
+			rev = resp.Header.Revision
+		}
+
+		if m.ctx.Err() != nil {
+			return
+		}
+
+		// 监听中断，全量重新加载后继续监听
+		newRev, ok := m.reload()
+		if !ok {
+			return
+		}
+		rev = newRev
+	}
+}
+
+// 重试全量加载直到成功，已关闭时返回 false
+func (m *MultiEtcd[T]) reload() (int64, bool) {
+	for {
+		rev, err := m.load()
+		if err == nil {
+			return rev, true
+		}
+		logx.Errorf("Reload prefix %s failed: %v", m.prefix, err)
+
+		select {
+		case <-m.ctx.Done():
+			return 0, false
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// 应用单个变更事件
+func (m *MultiEtcd[T]) apply(ev *clientv3.Event) {
+	key := strings.TrimPrefix(string(ev.Kv.Key), m.prefix)
+
+	switch ev.Type {
+	case clientv3.EventTypePut:
+		var v T
+		if err := json.Unmarshal(ev.Kv.Value, &v); err != nil {
+			logx.Errorf("Failed to decode config %s: %v", ev.Kv.Key, err)
+			if m.dropInvalid {
+				m.remove(key)
+			}
+			// 否则解析失败时保留旧值
+			return
+		}
+
+		m.mu.Lock()
+		m.values[key] = v
+		m.mu.Unlock()
+		m.notify(Event[T]{Type: EventPut, Key: key, Value: v})
+
+	case clientv3.EventTypeDelete:
+		m.remove(key)
+	}
+}
+
+// 删除key，存在时通知删除事件
+func (m *MultiEtcd[T]) remove(key string) {
+	m.mu.Lock()
+	old, ok := m.values[key]
+	delete(m.values, key)
+	m.mu.Unlock()
+
+	if ok {
+		m.notify(Event[T]{Type: EventDelete, Key: key, Value: old})
+	}
+}
+
+// 通知监听者
+func (m *MultiEtcd[T]) notify(ev Event[T]) {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+
+	for _, l := range listeners {
+		l(ev)
+	}
+}
+
+// 根据配置创建etcd客户端
+func newClient(c Config) (*clientv3.Client, error) {
+	cfg := clientv3.Config{
+		Endpoints:   strings.Split(c.Host, ","),
+		DialTimeout: 5 * time.Second,
+		Username:    c.User,
+		Password:    c.Pass,
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.CertKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load etcd cert: %w", err)
+		}
+
+		caData, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("load etcd ca cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(caData)
+
+		cfg.TLS = &tls.Config{
+			Certificates:       []tls.Certificate{cert},
+			RootCAs:            pool,
+			InsecureSkipVerify: c.InsecureSkipVerify,
+		}
+	}
+
+	return clientv3.New(cfg)
+}
@@ -0,0 +1,86 @@
+package etcdc
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Toggles 前缀下的功能开关集合，每个key的值为JSON布尔值 true/false
+// 适用于熔断开关、灰度开关等需要运行时切换的场景
+type Toggles struct {
+	store *MultiEtcd[bool]
+}
+
+// Toggle 单个功能开关，值来自etcd，key不存在、值无法解析或etcd不可用时使用本地默认值
+type Toggle struct {
+	key   string
+	def   bool
+	store *MultiEtcd[bool]
+}
+
+// NewToggles 实例化功能开关集合，Config.Key 作为前缀
+// etcd不可用时不返回错误，开关先使用本地默认值，后台重试加载成功后切换为etcd中的值
+func NewToggles(c Config) (*Toggles, error) {
+	store, err := newMultiEtcd[bool](c, true)
+	if err != nil {
+		return nil, err
+	}
+	return &Toggles{store: store}, nil
+}
+
+// MustNewToggles 实例化功能开关集合，仅在客户端配置错误（如证书无法加载）时退出
+func MustNewToggles(c Config) *Toggles {
+	t, err := NewToggles(c)
+	logx.Must(err)
+	return t
+}
+
+// Toggle 获取功能开关，key 为去掉前缀后的key，def 为本地默认值
+// Toggles 为 nil 时（如etcd不可用时降级）返回始终使用默认值的开关
+func (t *Toggles) Toggle(key string, def bool) *Toggle {
+	tg := &Toggle{key: key, def: def}
+	if t != nil {
+		tg.store = t.store
+	}
+	return tg
+}
+
+// Close 停止监听并关闭客户端
+func (t *Toggles) Close() error {
+	if t == nil {
+		return nil
+	}
+	return t.store.Close()
+}
+
+// Key 开关的key
+func (t *Toggle) Key() string {
+	return t.key
+}
+
+// Enabled 开关是否打开
+func (t *Toggle) Enabled() bool {
+	if t.store == nil {
+		return t.def
+	}
+	if v, ok := t.store.Get(t.key); ok {
+		return v
+	}
+	return t.def
+}
+
+// OnChange 添加开关变更监听，key被删除或值无法解析时以默认值通知
+func (t *Toggle) OnChange(listener func(enabled bool)) {
+	if t.store == nil {
+		return
+	}
+	t.store.Listener(func(ev Event[bool]) {
+		if ev.Key != t.key {
+			return
+		}
+		if ev.Type == EventDelete {
+			listener(t.def)
+			return
+		}
+		listener(ev.Value)
+	})
+}
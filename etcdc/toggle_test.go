@@ -0,0 +1,99 @@
+package etcdc
+
+import (
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// newTestStore 不连接etcd的配置注册表，变更通过 apply 注入
+func newTestStore[T any](dropInvalid bool) *MultiEtcd[T] {
+	return &MultiEtcd[T]{
+		prefix:      "/toggles/",
+		values:      make(map[string]T),
+		dropInvalid: dropInvalid,
+	}
+}
+
+// putEvent etcd写入事件
+func putEvent(key, value string) *clientv3.Event {
+	return &clientv3.Event{
+		Type: clientv3.EventTypePut,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/toggles/" + key), Value: []byte(value)},
+	}
+}
+
+// deleteEvent etcd删除事件
+func deleteEvent(key string) *clientv3.Event {
+	return &clientv3.Event{
+		Type: clientv3.EventTypeDelete,
+		Kv:   &mvccpb.KeyValue{Key: []byte("/toggles/" + key)},
+	}
+}
+
+func TestToggleFallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []*clientv3.Event
+		def    bool
+		want   bool
+		notify []bool
+	}{
+		{"missing key", nil, true, true, nil},
+		{"enabled", []*clientv3.Event{putEvent("a", "true")}, false, true, []bool{true}},
+		{"disabled", []*clientv3.Event{putEvent("a", "false")}, true, false, []bool{false}},
+		{"unparsable", []*clientv3.Event{putEvent("a", "yes")}, true, true, nil},
+		{"unparsable after value", []*clientv3.Event{putEvent("a", "false"), putEvent("a", "tru")}, true, true, []bool{false, true}},
+		{"deleted", []*clientv3.Event{putEvent("a", "false"), deleteEvent("a")}, true, true, []bool{false, true}},
+		{"other key", []*clientv3.Event{putEvent("b", "false")}, true, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toggles := &Toggles{store: newTestStore[bool](true)}
+			tg := toggles.Toggle("a", tt.def)
+
+			var got []bool
+			tg.OnChange(func(enabled bool) { got = append(got, enabled) })
+			for _, ev := range tt.events {
+				toggles.store.apply(ev)
+			}
+
+			if tg.Enabled() != tt.want {
+				t.Fatalf("Enabled() = %v, want %v", tg.Enabled(), tt.want)
+			}
+			if len(got) != len(tt.notify) {
+				t.Fatalf("OnChange = %v, want %v", got, tt.notify)
+			}
+			for i := range got {
+				if got[i] != tt.notify[i] {
+					t.Fatalf("OnChange = %v, want %v", got, tt.notify)
+				}
+			}
+		})
+	}
+}
+
+func TestNilTogglesUseDefault(t *testing.T) {
+	var toggles *Toggles
+	tg := toggles.Toggle("a", true)
+	tg.OnChange(func(bool) { t.Fatal("OnChange on nil toggles") })
+
+	if !tg.Enabled() || tg.Key() != "a" {
+		t.Fatalf("Enabled() = %v, Key() = %q", tg.Enabled(), tg.Key())
+	}
+	if err := toggles.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMultiEtcdKeepsOldValue(t *testing.T) {
+	// 非开关场景解析失败时保留旧值
+	m := newTestStore[bool](false)
+	m.apply(putEvent("a", "true"))
+	m.apply(putEvent("a", "yes"))
+
+	if v, ok := m.Get("a"); !ok || !v {
+		t.Fatalf("Get(a) = %v, %v", v, ok)
+	}
+}
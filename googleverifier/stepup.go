@@ -0,0 +1,523 @@
+package googleverifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FactorType 二次验证因子
+type FactorType string
+
+const (
+	// FactorReCaptcha reCAPTCHA 人机验证
+	FactorReCaptcha FactorType = "recaptcha"
+	// FactorTOTP 身份验证器动态码
+	FactorTOTP FactorType = "totp"
+	// FactorSMS 短信验证码
+	FactorSMS FactorType = "sms"
+)
+
+const (
+	// 默认挑战有效期
+	defaultChallengeTTL = 5 * time.Minute
+	// 默认最大验证失败次数
+	defaultMaxAttempts = 5
+	// 默认短信验证码长度
+	defaultSMSCodeLength = 6
+	// 默认 reCAPTCHA 最低分数
+	defaultMinReCaptchaScore = 0.5
+)
+
+var (
+	// ErrChallengeNotFound 挑战不存在或已完成
+	ErrChallengeNotFound = errors.New("step-up challenge not found")
+	// ErrChallengeExpired 挑战已过期
+	ErrChallengeExpired = errors.New("step-up challenge expired")
+	// ErrTooManyAttempts 验证失败次数过多，挑战已作废
+	ErrTooManyAttempts = errors.New("too many step-up attempts")
+	// ErrFactorMissing 缺少需要的验证因子
+	ErrFactorMissing = errors.New("step-up factor missing")
+	// ErrFactorInvalid 验证因子校验失败
+	ErrFactorInvalid = errors.New("step-up factor invalid")
+	// ErrFactorUnavailable 需要的验证因子未配置或用户未绑定
+	ErrFactorUnavailable = errors.New("step-up factor unavailable")
+	// ErrChallengeMismatch 挑战不属于当前用户或操作
+	ErrChallengeMismatch = errors.New("step-up challenge mismatch")
+)
+
+// RiskInput 风控策略的输入
+type RiskInput struct {
+	UserID    int64   // 用户ID
+	Action    string  // 操作，如 login、withdraw
+	RiskScore float64 // 风险分，0~1，越高风险越大
+	NewDevice bool    // 是否为新设备
+	HasTOTP   bool    // 用户是否已绑定身份验证器
+	Phone     string  // 用户绑定的手机号，为空表示未绑定
+}
+
+// RiskPolicy 根据风控输入决定需要的验证因子，返回空时无需二次验证
+type RiskPolicy func(ctx context.Context, in RiskInput) []FactorType
+
+// ThresholdPolicy 按风险分阈值选择验证因子：
+// 低于 medium 只需 reCAPTCHA；达到 medium 或新设备时追加一个二次因子（优先身份验证器，未绑定时用短信）；
+// 达到 high 时身份验证器和短信均需验证（按用户已绑定的因子），均未绑定时要求身份验证器，Begin 返回 ErrFactorUnavailable
+func ThresholdPolicy(medium, high float64) RiskPolicy {
+	return func(ctx context.Context, in RiskInput) []FactorType {
+		factors := []FactorType{FactorReCaptcha}
+		switch {
+		case in.RiskScore >= high:
+			if in.HasTOTP || in.Phone == "" {
+				factors = append(factors, FactorTOTP)
+			}
+			if in.Phone != "" {
+				factors = append(factors, FactorSMS)
+			}
+		case in.RiskScore >= medium || in.NewDevice:
+			if in.HasTOTP {
+				factors = append(factors, FactorTOTP)
+			} else if in.Phone != "" {
+				factors = append(factors, FactorSMS)
+			}
+		}
+		return factors
+	}
+}
+
+// Challenge 二次验证挑战，返回给客户端用于展示需要的验证方式
+type Challenge struct {
+	ID          string       `json:"id"`
+	UserID      int64        `json:"user_id"`
+	Action      string       `json:"action"`
+	Factors     []FactorType `json:"factors"`
+	MaskedPhone string       `json:"masked_phone,omitempty"` // 短信发送到的脱敏手机号
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
+// ChallengeState 挑战的服务端状态，不返回给客户端
+type ChallengeState struct {
+	Challenge   Challenge `json:"challenge"`
+	SMSCodeHash string    `json:"sms_code_hash,omitempty"` // 加盐哈希后的短信验证码
+	Attempts    int       `json:"attempts"`                // 已尝试次数
+}
+
+// Required 是否需要二次验证
+func (c *Challenge) Required() bool {
+	return len(c.Factors) > 0
+}
+
+// Requires 是否需要指定的验证因子
+func (c *Challenge) Requires(factor FactorType) bool {
+	return slices.Contains(c.Factors, factor)
+}
+
+// StepUpResponse 客户端提交的验证结果，只需填写挑战要求的因子
+type StepUpResponse struct {
+	ReCaptchaToken string `json:"recaptcha_token,optional"`
+	TOTPCode       string `json:"totp_code,optional"`
+	SMSCode        string `json:"sms_code,optional"`
+}
+
+// SMSSender 短信验证码发送
+type SMSSender interface {
+	SendCode(ctx context.Context, phone, code string) error
+}
+
+// SecretProvider 获取用户的身份验证器密钥
+type SecretProvider func(ctx context.Context, userID int64) (string, error)
+
+// ChallengeStore 挑战存储，多实例部署时需使用共享存储
+type ChallengeStore interface {
+	// Save 保存挑战，ttl 后过期
+	Save(ctx context.Context, state *ChallengeState, ttl time.Duration) error
+	// Load 读取挑战，不存在时返回 ErrChallengeNotFound
+	Load(ctx context.Context, id string) (*ChallengeState, error)
+	// Attempt 原子地将尝试次数加1并返回更新后的挑战，不存在时返回 ErrChallengeNotFound
+	Attempt(ctx context.Context, id string) (*ChallengeState, error)
+	// Delete 删除挑战，不存在时返回 ErrChallengeNotFound，保证挑战只能成功使用一次
+	Delete(ctx context.Context, id string) error
+	// UseOnce 记录一次性凭证（如已使用的身份验证器动态码），有效期内首次记录返回 true
+	UseOnce(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// memoryChallengeStore 基于内存的挑战存储
+type memoryChallengeStore struct {
+	mu    sync.Mutex
+	items map[string]ChallengeState
+	used  map[string]time.Time
+}
+
+// NewMemoryChallengeStore 创建内存挑战存储，仅适用于单实例
+func NewMemoryChallengeStore() ChallengeStore {
+	return &memoryChallengeStore{
+		items: make(map[string]ChallengeState),
+		used:  make(map[string]time.Time),
+	}
+}
+
+// Save 保存挑战，同时清理已过期的挑战
+func (m *memoryChallengeStore) Save(ctx context.Context, state *ChallengeState, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, item := range m.items {
+		if now.After(item.Challenge.ExpiresAt) {
+			delete(m.items, id)
+		}
+	}
+	m.items[state.Challenge.ID] = *state
+	return nil
+}
+
+// Load 读取挑战
+func (m *memoryChallengeStore) Load(ctx context.Context, id string) (*ChallengeState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.items[id]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	return &state, nil
+}
+
+// Attempt 尝试次数加1
+func (m *memoryChallengeStore) Attempt(ctx context.Context, id string) (*ChallengeState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.items[id]
+	if !ok {
+		return nil, ErrChallengeNotFound
+	}
+	state.Attempts++
+	m.items[id] = state
+	return &state, nil
+}
+
+// Delete 删除挑战
+func (m *memoryChallengeStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[id]; !ok {
+		return ErrChallengeNotFound
+	}
+	delete(m.items, id)
+	return nil
+}
+
+// UseOnce 记录一次性凭证，同时清理已过期的记录
+func (m *memoryChallengeStore) UseOnce(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if expireAt, ok := m.used[key]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	for k, expireAt := range m.used {
+		if now.After(expireAt) {
+			delete(m.used, k)
+		}
+	}
+	m.used[key] = now.Add(ttl)
+	return true, nil
+}
+
+// StepUpVerifier 二次验证编排，根据风控策略决定需要的验证因子并统一校验，
+// 供登录、提现等需要加强验证的场景共用
+type StepUpVerifier struct {
+	policy         RiskPolicy
+	recaptcha      *ReCaptchaService
+	minScore       float64
+	totp           *TwoFactorAuth
+	secretProvider SecretProvider
+	sms            SMSSender
+	smsCodeLength  int
+	store          ChallengeStore
+	ttl            time.Duration
+	maxAttempts    int
+}
+
+// StepUpOption 二次验证选项
+type StepUpOption func(v *StepUpVerifier)
+
+// WithRiskPolicy 设置风控策略，默认 ThresholdPolicy(0.5, 0.8)
+func WithRiskPolicy(policy RiskPolicy) StepUpOption {
+	return func(v *StepUpVerifier) {
+		v.policy = policy
+	}
+}
+
+// WithReCaptchaFactor 启用 reCAPTCHA 因子，minScore <=0 时为0.5
+func WithReCaptchaFactor(svc *ReCaptchaService, minScore float64) StepUpOption {
+	return func(v *StepUpVerifier) {
+		v.recaptcha = svc
+		if minScore > 0 {
+			v.minScore = minScore
+		}
+	}
+}
+
+// WithTOTPFactor 启用身份验证器因子
+func WithTOTPFactor(auth *TwoFactorAuth, provider SecretProvider) StepUpOption {
+	return func(v *StepUpVerifier) {
+		v.totp = auth
+		v.secretProvider = provider
+	}
+}
+
+// WithSMSFactor 启用短信因子，codeLength <=0 时为6位
+func WithSMSFactor(sender SMSSender, codeLength int) StepUpOption {
+	return func(v *StepUpVerifier) {
+		v.sms = sender
+		if codeLength > 0 {
+			v.smsCodeLength = codeLength
+		}
+	}
+}
+
+// WithChallengeStore 设置挑战存储，默认使用内存存储
+func WithChallengeStore(store ChallengeStore) StepUpOption {
+	return func(v *StepUpVerifier) {
+		v.store = store
+	}
+}
+
+// WithChallengeTTL 设置挑战有效期，默认5分钟
+func WithChallengeTTL(ttl time.Duration) StepUpOption {
+	return func(v *StepUpVerifier) {
+		if ttl > 0 {
+			v.ttl = ttl
+		}
+	}
+}
+
+// WithMaxAttempts 设置最大验证失败次数，超出后挑战作废，默认5次
+func WithMaxAttempts(n int) StepUpOption {
+	return func(v *StepUpVerifier) {
+		if n > 0 {
+			v.maxAttempts = n
+		}
+	}
+}
+
+// NewStepUpVerifier 创建二次验证编排器，策略要求但未配置的因子在 Begin 时返回 ErrFactorUnavailable
+func NewStepUpVerifier(opts ...StepUpOption) *StepUpVerifier {
+	v := &StepUpVerifier{
+		policy:        ThresholdPolicy(0.5, 0.8),
+		minScore:      defaultMinReCaptchaScore,
+		smsCodeLength: defaultSMSCodeLength,
+		ttl:           defaultChallengeTTL,
+		maxAttempts:   defaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.store == nil {
+		v.store = NewMemoryChallengeStore()
+	}
+	return v
+}
+
+// Begin 根据风控输入创建挑战，需要短信因子时发送验证码
+// 返回的挑战 Required() 为 false 时无需二次验证
+func (v *StepUpVerifier) Begin(ctx context.Context, in RiskInput) (*Challenge, error) {
+	id, err := randomChallengeID()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ChallengeState{}
+	c := &state.Challenge
+	*c = Challenge{
+		ID:        id,
+		UserID:    in.UserID,
+		Action:    in.Action,
+		ExpiresAt: time.Now().Add(v.ttl),
+	}
+	for _, factor := range v.policy(ctx, in) {
+		if !v.enabled(factor) {
+			return nil, fmt.Errorf("%w: %s not configured", ErrFactorUnavailable, factor)
+		}
+		if !c.Requires(factor) {
+			c.Factors = append(c.Factors, factor)
+		}
+	}
+	if !c.Required() {
+		return c, nil
+	}
+
+	if c.Requires(FactorTOTP) {
+		secret, err := v.secretProvider(ctx, in.UserID)
+		if err != nil || secret == "" {
+			return nil, fmt.Errorf("%w: user %d has no authenticator", ErrFactorUnavailable, in.UserID)
+		}
+	}
+
+	if c.Requires(FactorSMS) {
+		if in.Phone == "" {
+			return nil, fmt.Errorf("%w: user %d has no phone", ErrFactorUnavailable, in.UserID)
+		}
+		code, err := randomDigits(v.smsCodeLength)
+		if err != nil {
+			return nil, err
+		}
+		if err := v.sms.SendCode(ctx, in.Phone, code); err != nil {
+			return nil, fmt.Errorf("send sms code failed: %w", err)
+		}
+		state.SMSCodeHash = hashCode(id, code)
+		c.MaskedPhone = maskPhone(in.Phone)
+	}
+
+	if err := v.store.Save(ctx, state, v.ttl); err != nil {
+		return nil, fmt.Errorf("save challenge failed: %w", err)
+	}
+	return c, nil
+}
+
+// Verify 校验挑战要求的所有因子，挑战需属于 userID 和 action，成功后挑战作废；
+// 每次校验前原子地累计尝试次数，超出上限后挑战作废并返回 ErrTooManyAttempts
+func (v *StepUpVerifier) Verify(ctx context.Context, challengeID string, userID int64, action string, resp StepUpResponse) error {
+	state, err := v.store.Attempt(ctx, challengeID)
+	if err != nil {
+		return err
+	}
+	c := &state.Challenge
+	if c.UserID != userID || c.Action != action {
+		return ErrChallengeMismatch
+	}
+	if time.Now().After(c.ExpiresAt) {
+		_ = v.store.Delete(ctx, challengeID)
+		return ErrChallengeExpired
+	}
+	if state.Attempts > v.maxAttempts {
+		_ = v.store.Delete(ctx, challengeID)
+		return ErrTooManyAttempts
+	}
+
+	if err := v.verifyFactors(ctx, state, resp); err != nil {
+		if state.Attempts >= v.maxAttempts {
+			_ = v.store.Delete(ctx, challengeID)
+			return fmt.Errorf("%w: %w", ErrTooManyAttempts, err)
+		}
+		return err
+	}
+
+	// 并发提交时只有删除成功的请求通过
+	return v.store.Delete(ctx, challengeID)
+}
+
+// verifyFactors 依次校验挑战要求的因子
+func (v *StepUpVerifier) verifyFactors(ctx context.Context, state *ChallengeState, resp StepUpResponse) error {
+	c := &state.Challenge
+	for _, factor := range c.Factors {
+		switch factor {
+		case FactorReCaptcha:
+			if resp.ReCaptchaToken == "" {
+				return fmt.Errorf("%w: %s", ErrFactorMissing, factor)
+			}
+			ok, err := v.recaptcha.Verify(c.Action, resp.ReCaptchaToken, v.minScore)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrFactorInvalid, factor, err)
+			}
+			if !ok {
+				return fmt.Errorf("%w: %s score too low", ErrFactorInvalid, factor)
+			}
+		case FactorTOTP:
+			if resp.TOTPCode == "" {
+				return fmt.Errorf("%w: %s", ErrFactorMissing, factor)
+			}
+			secret, err := v.secretProvider(ctx, c.UserID)
+			if err != nil || secret == "" {
+				return fmt.Errorf("%w: %s", ErrFactorUnavailable, factor)
+			}
+			code, err := strconv.ParseInt(strings.TrimSpace(resp.TOTPCode), 10, 32)
+			if err != nil || !v.totp.VerifyCode(secret, int32(code)) {
+				return fmt.Errorf("%w: %s", ErrFactorInvalid, factor)
+			}
+			// 动态码在有效窗口内只能使用一次
+			first, err := v.store.UseOnce(ctx, fmt.Sprintf("totp:%d:%d", c.UserID, code), v.totpTTL())
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrFactorInvalid, factor, err)
+			}
+			if !first {
+				return fmt.Errorf("%w: %s code already used", ErrFactorInvalid, factor)
+			}
+		case FactorSMS:
+			if resp.SMSCode == "" {
+				return fmt.Errorf("%w: %s", ErrFactorMissing, factor)
+			}
+			got := hashCode(c.ID, strings.TrimSpace(resp.SMSCode))
+			if subtle.ConstantTimeCompare([]byte(got), []byte(state.SMSCodeHash)) != 1 {
+				return fmt.Errorf("%w: %s", ErrFactorInvalid, factor)
+			}
+		}
+	}
+	return nil
+}
+
+// enabled 因子是否已配置
+func (v *StepUpVerifier) enabled(factor FactorType) bool {
+	switch factor {
+	case FactorReCaptcha:
+		return v.recaptcha != nil
+	case FactorTOTP:
+		return v.totp != nil && v.secretProvider != nil
+	case FactorSMS:
+		return v.sms != nil
+	}
+	return false
+}
+
+// totpTTL 动态码的有效时长，覆盖校验时允许的前后时间窗口
+func (v *StepUpVerifier) totpTTL() time.Duration {
+	return time.Duration(v.totp.timeStep*int64(2*v.totp.windowSize+1)) * time.Second
+}
+
+// randomChallengeID 生成随机挑战ID
+func randomChallengeID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate challenge id failed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// randomDigits 生成指定长度的数字验证码
+func randomDigits(n int) (string, error) {
+	var sb strings.Builder
+	for range n {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("generate sms code failed: %w", err)
+		}
+		sb.WriteByte(byte('0' + d.Int64()))
+	}
+	return sb.String(), nil
+}
+
+// hashCode 以挑战ID加盐哈希验证码，存储中不保存明文
+func hashCode(challengeID, code string) string {
+	sum := sha256.Sum256([]byte(challengeID + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskPhone 手机号脱敏，保留前3位和后4位
+func maskPhone(phone string) string {
+	if len(phone) <= 7 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
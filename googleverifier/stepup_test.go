@@ -0,0 +1,167 @@
+package googleverifier
+
+import (
+	"context"
+	"encoding/base32"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSMSSender struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func (f *fakeSMSSender) SendCode(ctx context.Context, phone, code string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.codes == nil {
+		f.codes = make(map[string]string)
+	}
+	f.codes[phone] = code
+	return nil
+}
+
+func (f *fakeSMSSender) code(phone string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.codes[phone]
+}
+
+func smsPolicy(ctx context.Context, in RiskInput) []FactorType {
+	return []FactorType{FactorSMS}
+}
+
+func TestStepUpRejectsUnsatisfiablePolicy(t *testing.T) {
+	ctx := context.Background()
+
+	// 策略要求的因子未配置
+	v := NewStepUpVerifier(WithRiskPolicy(smsPolicy))
+	if _, err := v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw", Phone: "13800138000"}); !errors.Is(err, ErrFactorUnavailable) {
+		t.Errorf("Begin without sms sender err = %v", err)
+	}
+
+	// 高风险且用户未绑定任何二次因子
+	v = NewStepUpVerifier(
+		WithReCaptchaFactor(NewReCaptchaService("", "", nil), 0),
+		WithTOTPFactor(NewTwoFactorAuth(), func(ctx context.Context, userID int64) (string, error) { return "", nil }),
+		WithSMSFactor(&fakeSMSSender{}, 0),
+	)
+	if _, err := v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw", RiskScore: 0.9}); !errors.Is(err, ErrFactorUnavailable) {
+		t.Errorf("Begin high risk without factors err = %v", err)
+	}
+}
+
+func TestStepUpVerifySMS(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSMSSender{}
+	v := NewStepUpVerifier(WithRiskPolicy(smsPolicy), WithSMSFactor(sender, 6), WithMaxAttempts(3))
+
+	begin := func() *Challenge {
+		c, err := v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw", Phone: "13800138000"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	c := begin()
+	code := sender.code("13800138000")
+	if err := v.Verify(ctx, c.ID, 2, "withdraw", StepUpResponse{SMSCode: code}); !errors.Is(err, ErrChallengeMismatch) {
+		t.Errorf("other user err = %v", err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "login", StepUpResponse{SMSCode: code}); !errors.Is(err, ErrChallengeMismatch) {
+		t.Errorf("other action err = %v", err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: "000000x"}); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("attempts after mismatches err = %v", err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: code}); !errors.Is(err, ErrChallengeNotFound) {
+		t.Errorf("exhausted challenge err = %v", err)
+	}
+
+	c = begin()
+	code = sender.code("13800138000")
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: "000000x"}); !errors.Is(err, ErrFactorInvalid) {
+		t.Errorf("wrong code err = %v", err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: code}); err != nil {
+		t.Fatalf("correct code err = %v", err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: code}); !errors.Is(err, ErrChallengeNotFound) {
+		t.Errorf("reused challenge err = %v", err)
+	}
+}
+
+func TestStepUpConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSMSSender{}
+	v := NewStepUpVerifier(WithRiskPolicy(smsPolicy), WithSMSFactor(sender, 6), WithMaxAttempts(5))
+
+	c, err := v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw", Phone: "13800138000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := sender.code("13800138000")
+
+	// 并发猜测只有前 maxAttempts 次会被校验
+	var wg sync.WaitGroup
+	var checked, succeeded atomic.Int32
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			guess := strconv.Itoa(100000 + i)
+			if i == 99 {
+				guess = code
+			}
+			err := v.Verify(ctx, c.ID, 1, "withdraw", StepUpResponse{SMSCode: guess})
+			if err == nil {
+				succeeded.Add(1)
+			}
+			if err == nil || errors.Is(err, ErrFactorInvalid) {
+				checked.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if checked.Load() > 5 {
+		t.Errorf("checked %d guesses, want at most 5", checked.Load())
+	}
+	if succeeded.Load() > 1 {
+		t.Errorf("succeeded %d times", succeeded.Load())
+	}
+}
+
+func TestStepUpTOTPReplay(t *testing.T) {
+	ctx := context.Background()
+	auth := NewTwoFactorAuth()
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	v := NewStepUpVerifier(
+		WithRiskPolicy(func(ctx context.Context, in RiskInput) []FactorType { return []FactorType{FactorTOTP} }),
+		WithTOTPFactor(auth, func(ctx context.Context, userID int64) (string, error) { return secret, nil }),
+	)
+
+	code := auth.generateCode([]byte("12345678901234567890"), time.Now().Unix()/auth.timeStep)
+	resp := StepUpResponse{TOTPCode: strconv.Itoa(int(code))}
+
+	c, err := v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", resp); err != nil {
+		t.Fatalf("first use err = %v", err)
+	}
+
+	c, err = v.Begin(ctx, RiskInput{UserID: 1, Action: "withdraw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Verify(ctx, c.ID, 1, "withdraw", resp); !errors.Is(err, ErrFactorInvalid) {
+		t.Errorf("replayed code err = %v", err)
+	}
+}
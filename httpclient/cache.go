@@ -0,0 +1,473 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// CacheStatusHeader 响应头，标记响应来自缓存：HIT 为命中新鲜缓存，REVALIDATED 为条件请求返回304后使用缓存
+	CacheStatusHeader = "X-Httpclient-Cache"
+
+	// 带 ETag/Last-Modified 的响应过期后继续保留用于条件请求的时间
+	defaultCacheRetention = 24 * time.Hour
+	// 默认最大缓存响应体，超出时不缓存
+	defaultMaxCacheBodySize = 10 << 20
+	// 内存缓存默认最大条目数
+	defaultMemoryCacheEntries = 1000
+)
+
+// CachedResponse 缓存的响应
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// 新鲜期截止时间，之后需要条件请求重新验证
+	Expires time.Time `json:"expires"`
+	// Vary 指定的请求头取值，与当前请求不一致时视为未命中
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// CacheStore 响应缓存存储
+type CacheStore interface {
+	// Get 获取缓存，不存在时返回 false
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	// Set 写入缓存，ttl 为存储保留时间
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+	// Delete 删除缓存
+	Delete(ctx context.Context, key string) error
+}
+
+// WithCache 开启GET请求的客户端缓存，遵循 Cache-Control、Expires，
+// 过期后带 ETag/Last-Modified 的响应通过 If-None-Match/If-Modified-Since 条件请求重新验证
+// 适用于频繁访问的元数据类接口；请求头 Cache-Control: no-cache 强制重新验证，no-store 跳过缓存
+// 缓存按共享缓存处理：不缓存 Cache-Control: private 和带 Set-Cookie 的响应，
+// 带 Authorization/Cookie 的请求仅在响应显式允许共享（public、s-maxage、must-revalidate）时缓存，且按凭证区分缓存键
+func WithCache(store CacheStore) Option {
+	return func(c *Client) {
+		c.cache = store
+	}
+}
+
+// applyCache 在传输层外包装缓存
+func (c *Client) applyCache() {
+	if c.cache == nil {
+		return
+	}
+	next := c.client.GetClient().Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.client.SetTransport(&cachingTransport{
+		next:        next,
+		store:       c.cache,
+		maxBodySize: defaultMaxCacheBodySize,
+	})
+}
+
+// cachingTransport 缓存GET响应的传输层
+type cachingTransport struct {
+	next        http.RoundTripper
+	store       CacheStore
+	maxBodySize int64
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqCC := parseCacheControl(req.Header.Get("Cache-Control"))
+	// 调用方自行发起条件请求时不介入
+	if req.Method != http.MethodGet || reqCC.has("no-store") ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	key := cacheKey(req)
+	cached, ok, err := t.store.Get(ctx, key)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("httpclient cache get %s: %v", req.URL, err)
+	}
+	if ok && !cached.matchVary(req) {
+		ok = false
+	}
+
+	if ok && !reqCC.has("no-cache") && time.Now().Before(cached.Expires) {
+		return cached.response(req, "HIT"), nil
+	}
+
+	outReq := req
+	if ok {
+		outReq = req.Clone(ctx)
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lm := cached.Header.Get("Last-Modified"); lm != "" {
+			outReq.Header.Set("If-Modified-Since", lm)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		// 按304响应更新新鲜期和头信息
+		for k, v := range resp.Header {
+			cached.Header[k] = v
+		}
+		if expires, cacheable := freshness(resp.Header); cacheable {
+			cached.Expires = expires
+		}
+		if sharable(req, cached.Header) {
+			t.save(ctx, key, cached)
+		} else if err = t.store.Delete(ctx, key); err != nil {
+			logx.WithContext(ctx).Errorf("httpclient cache delete: %v", err)
+		}
+		return cached.response(req, "REVALIDATED"), nil
+	}
+
+	if resp.StatusCode == http.StatusOK && sharable(req, resp.Header) {
+		t.store200(ctx, key, req, resp)
+	}
+	return resp, nil
+}
+
+// store200 缓存200响应，读取的响应体放回 resp.Body
+func (t *cachingTransport) store200(ctx context.Context, key string, req *http.Request, resp *http.Response) {
+	expires, cacheable := freshness(resp.Header)
+	if !cacheable || strings.TrimSpace(resp.Header.Get("Vary")) == "*" {
+		return
+	}
+	if resp.ContentLength > t.maxBodySize {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize+1))
+	if err != nil || int64(len(body)) > t.maxBodySize {
+		// 读取失败或超出大小时，将已读部分与剩余部分拼回
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		Expires:    expires,
+		Vary:       varyValues(req, resp.Header),
+	}
+	t.save(ctx, key, entry)
+}
+
+// save 写入缓存，带验证器的响应在新鲜期后继续保留用于条件请求
+func (t *cachingTransport) save(ctx context.Context, key string, entry *CachedResponse) {
+	ttl := time.Until(entry.Expires)
+	if entry.Header.Get("ETag") != "" || entry.Header.Get("Last-Modified") != "" {
+		ttl = max(ttl, 0) + defaultCacheRetention
+	}
+	if ttl <= 0 {
+		return
+	}
+	if err := t.store.Set(ctx, key, entry, ttl); err != nil {
+		logx.WithContext(ctx).Errorf("httpclient cache set: %v", err)
+	}
+}
+
+// response 由缓存构造响应
+func (r *CachedResponse) response(req *http.Request, status string) *http.Response {
+	header := r.Header.Clone()
+	header.Set(CacheStatusHeader, status)
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// sharable 响应是否可以存入共享缓存
+func sharable(req *http.Request, h http.Header) bool {
+	cc := parseCacheControl(h.Get("Cache-Control"))
+	if cc.has("private") || h.Get("Set-Cookie") != "" {
+		return false
+	}
+	if hasCredentials(req) {
+		return cc.has("public") || cc.has("s-maxage") || cc.has("must-revalidate")
+	}
+	return true
+}
+
+// hasCredentials 请求是否携带凭证
+func hasCredentials(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+// matchVary 当前请求的 Vary 请求头取值是否与缓存一致
+func (r *CachedResponse) matchVary(req *http.Request) bool {
+	for name, value := range r.Vary {
+		if req.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// freshness 根据响应头计算新鲜期截止时间，返回是否可缓存
+// no-store 不可缓存；no-cache 可缓存但每次重新验证；无新鲜期信息时仅在有验证器时缓存
+func freshness(h http.Header) (time.Time, bool) {
+	cc := parseCacheControl(h.Get("Cache-Control"))
+	if cc.has("no-store") {
+		return time.Time{}, false
+	}
+
+	now := time.Now()
+	hasValidator := h.Get("ETag") != "" || h.Get("Last-Modified") != ""
+	if cc.has("no-cache") {
+		return now, hasValidator
+	}
+
+	if v, ok := cc["max-age"]; ok {
+		maxAge, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return now, hasValidator
+		}
+		age, _ := strconv.ParseInt(h.Get("Age"), 10, 64)
+		expires := now.Add(time.Duration(maxAge-age) * time.Second)
+		return expires, expires.After(now) || hasValidator
+	}
+
+	if v := h.Get("Expires"); v != "" {
+		if expires, err := http.ParseTime(v); err == nil {
+			// 以服务端时间计算，避免本地时钟偏差
+			if date, err := http.ParseTime(h.Get("Date")); err == nil {
+				expires = now.Add(expires.Sub(date))
+			}
+			return expires, expires.After(now) || hasValidator
+		}
+		// 无法解析的 Expires 视为已过期
+		return now, hasValidator
+	}
+
+	return now, hasValidator
+}
+
+// cacheControl 解析后的 Cache-Control 指令
+type cacheControl map[string]string
+
+// has 是否包含指令
+func (cc cacheControl) has(directive string) bool {
+	_, ok := cc[directive]
+	return ok
+}
+
+// parseCacheControl 解析 Cache-Control 头
+func parseCacheControl(value string) cacheControl {
+	cc := cacheControl{}
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, val, _ := strings.Cut(part, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(val), `"`)
+	}
+	return cc
+}
+
+// varyValues 记录响应 Vary 头指定的请求头取值
+func varyValues(req *http.Request, h http.Header) map[string]string {
+	var values map[string]string
+	for _, vary := range h.Values("Vary") {
+		for name := range strings.SplitSeq(vary, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if values == nil {
+				values = make(map[string]string)
+			}
+			values[name] = req.Header.Get(name)
+		}
+	}
+	return values
+}
+
+// cacheKey 缓存键，携带凭证的请求附加凭证摘要，不同凭证互不命中
+func cacheKey(req *http.Request) string {
+	key := req.Method + " " + req.URL.String()
+	if !hasCredentials(req) {
+		return key
+	}
+	h := sha256.New()
+	for _, v := range req.Header.Values("Authorization") {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	h.Write([]byte{0})
+	for _, v := range req.Header.Values("Cookie") {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return key + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// readCloser 组合 Reader 和 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// memoryCacheStore 基于内存的响应缓存，超出条目数时优先淘汰过期条目
+type memoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryCacheEntry
+}
+
+// memoryCacheEntry 内存缓存条目
+type memoryCacheEntry struct {
+	resp     *CachedResponse
+	expireAt time.Time
+}
+
+// NewMemoryCacheStore 创建内存响应缓存，maxEntries <=0 时为1000
+func NewMemoryCacheStore(maxEntries int) CacheStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryCacheEntries
+	}
+	return &memoryCacheStore{
+		maxEntries: maxEntries,
+		entries:    make(map[string]memoryCacheEntry),
+	}
+}
+
+// Get 实现 CacheStore 接口
+func (s *memoryCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expireAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	// 返回副本，避免调用方修改缓存中的头信息
+	resp := *e.resp
+	resp.Header = e.resp.Header.Clone()
+	return &resp, true, nil
+}
+
+// Set 实现 CacheStore 接口
+func (s *memoryCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	s.entries[key] = memoryCacheEntry{resp: resp, expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete 实现 CacheStore 接口
+func (s *memoryCacheStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// evict 淘汰过期条目，没有过期条目时淘汰最早过期的条目
+func (s *memoryCacheStore) evict() {
+	now := time.Now()
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+	for k, e := range s.entries {
+		if now.After(e.expireAt) {
+			delete(s.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.expireAt.Before(oldest) {
+			oldestKey, oldest = k, e.expireAt
+		}
+	}
+	if len(s.entries) >= s.maxEntries && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
+
+// redisCacheStore 基于Redis的响应缓存，多实例共享
+type redisCacheStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisCacheStore 创建Redis响应缓存，key 为 prefix + 请求的sha256
+func NewRedisCacheStore(rdb redis.UniversalClient, prefix string) CacheStore {
+	return &redisCacheStore{rdb: rdb, prefix: prefix}
+}
+
+// key 缓存键
+func (s *redisCacheStore) key(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return s.prefix + hex.EncodeToString(sum[:])
+}
+
+// Get 实现 CacheStore 接口
+func (s *redisCacheStore) Get(ctx context.Context, key string) (*CachedResponse, bool, error) {
+	data, err := s.rdb.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, true, nil
+}
+
+// Set 实现 CacheStore 接口
+func (s *redisCacheStore) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, s.key(key), data, ttl).Err()
+}
+
+// Delete 实现 CacheStore 接口
+func (s *redisCacheStore) Delete(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, s.key(key)).Err()
+}
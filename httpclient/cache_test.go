@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func newCacheTestClient() *http.Client {
+	return &http.Client{Transport: &cachingTransport{
+		next:        http.DefaultTransport,
+		store:       NewMemoryCacheStore(0),
+		maxBodySize: defaultMaxCacheBodySize,
+	}}
+}
+
+func TestCachingTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		cacheControl string
+		setCookie    bool
+		reqHeader    map[string]string
+		// 第二次请求使用的请求头，为空时与第一次相同
+		secondHeader map[string]string
+		wantHits     int32
+	}{
+		{name: "max-age", cacheControl: "max-age=60", wantHits: 1},
+		{name: "no-store", cacheControl: "no-store", wantHits: 2},
+		{name: "private", cacheControl: "private, max-age=60", wantHits: 2},
+		{name: "set-cookie", cacheControl: "max-age=60", setCookie: true, wantHits: 2},
+		{name: "authorization not public", cacheControl: "max-age=60",
+			reqHeader: map[string]string{"Authorization": "Bearer a"}, wantHits: 2},
+		{name: "cookie not public", cacheControl: "max-age=60",
+			reqHeader: map[string]string{"Cookie": "session=a"}, wantHits: 2},
+		{name: "authorization public", cacheControl: "public, max-age=60",
+			reqHeader: map[string]string{"Authorization": "Bearer a"}, wantHits: 1},
+		{name: "public other credentials", cacheControl: "public, max-age=60",
+			reqHeader:    map[string]string{"Authorization": "Bearer a"},
+			secondHeader: map[string]string{"Authorization": "Bearer b"}, wantHits: 2},
+		{name: "anonymous then credentials", cacheControl: "max-age=60",
+			secondHeader: map[string]string{"Authorization": "Bearer b"}, wantHits: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits.Add(1)
+				w.Header().Set("Cache-Control", tt.cacheControl)
+				if tt.setCookie {
+					w.Header().Set("Set-Cookie", "session=x")
+				}
+				_, _ = io.WriteString(w, "body:"+r.Header.Get("Authorization"))
+			}))
+			defer srv.Close()
+
+			client := newCacheTestClient()
+			for i, header := range []map[string]string{tt.reqHeader, tt.secondHeader} {
+				if i == 1 && header == nil {
+					header = tt.reqHeader
+				}
+				req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+				for k, v := range header {
+					req.Header.Set(k, v)
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if want := "body:" + req.Header.Get("Authorization"); string(body) != want {
+					t.Fatalf("request %d body = %q, want %q", i, body, want)
+				}
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Fatalf("server hits = %d, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestCachingTransportRevalidate(t *testing.T) {
+	var hits, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = io.WriteString(w, "v1")
+	}))
+	defer srv.Close()
+
+	client := newCacheTestClient()
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "v1" || resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: %d %q", i, resp.StatusCode, body)
+		}
+		if i == 1 && resp.Header.Get(CacheStatusHeader) != "REVALIDATED" {
+			t.Fatalf("cache status = %q", resp.Header.Get(CacheStatusHeader))
+		}
+	}
+	if hits.Load() != 2 || notModified.Load() != 1 {
+		t.Fatalf("hits=%d notModified=%d", hits.Load(), notModified.Load())
+	}
+}
@@ -0,0 +1,217 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+// GraphQL 自动持久化查询（APQ）的错误码
+const (
+	gqlPersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	gqlPersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+)
+
+// 部分服务端只在 message 中返回APQ错误
+var gqlErrorMessages = map[string]string{
+	gqlPersistedQueryNotFound:     "PersistedQueryNotFound",
+	gqlPersistedQueryNotSupported: "PersistedQueryNotSupported",
+}
+
+// GraphQLRequest GraphQL请求体
+type GraphQLRequest struct {
+	Query         string         `json:"query,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
+}
+
+// GraphQLLocation 错误在查询语句中的位置
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQLError GraphQL响应中的单个错误
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Path       []any             `json:"path,omitempty"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+// Error 实现 error 接口
+func (e GraphQLError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	parts := make([]string, len(e.Path))
+	for i, p := range e.Path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".") + ": " + e.Message
+}
+
+// Code 返回 extensions.code，如 UNAUTHENTICATED、BAD_USER_INPUT
+func (e GraphQLError) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// GraphQLErrors GraphQL响应中的错误列表
+type GraphQLErrors []GraphQLError
+
+// Error 实现 error 接口
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// hasCode 是否包含指定错误码，兼容只返回消息的服务端
+func (e GraphQLErrors) hasCode(code string) bool {
+	for _, err := range e {
+		if err.Code() == code || (gqlErrorMessages[code] != "" && err.Message == gqlErrorMessages[code]) {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLResponse GraphQL响应体
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQLClient 基于 Client 的GraphQL客户端
+type GraphQLClient struct {
+	client    *Client
+	endpoint  string
+	persisted atomic.Bool // 自动持久化查询，服务端不支持时自动关闭
+}
+
+// GraphQLOption GraphQL客户端选项
+type GraphQLOption func(*GraphQLClient)
+
+// WithPersistedQueries 启用自动持久化查询（APQ）：先只发送查询的sha256，服务端未缓存时再发送完整查询
+func WithPersistedQueries() GraphQLOption {
+	return func(g *GraphQLClient) {
+		g.persisted.Store(true)
+	}
+}
+
+// NewGraphQLClient 创建GraphQL客户端，endpoint 为请求路径或完整URL
+func NewGraphQLClient(client *Client, endpoint string, opts ...GraphQLOption) *GraphQLClient {
+	g := &GraphQLClient{
+		client:   client,
+		endpoint: endpoint,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Query 执行查询或变更，data 解析到 result
+func (g *GraphQLClient) Query(ctx context.Context, query string, variables map[string]any, result any) error {
+	return g.Do(ctx, &GraphQLRequest{Query: query, Variables: variables}, result)
+}
+
+// Do 执行GraphQL请求，响应包含 errors 时返回 *xerr.XErr（可通过 errors.As 获取 GraphQLErrors），
+// 部分成功时 data 仍会解析到 result
+func (g *GraphQLClient) Do(ctx context.Context, req *GraphQLRequest, result any) error {
+	if !g.persisted.Load() || req.Query == "" {
+		return g.send(ctx, req, result)
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	extensions := make(map[string]any, len(req.Extensions)+1)
+	for k, v := range req.Extensions {
+		extensions[k] = v
+	}
+	extensions["persistedQuery"] = map[string]any{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(sum[:]),
+	}
+
+	// 先只发送哈希，服务端未缓存时再带上完整查询注册
+	hashOnly := *req
+	hashOnly.Query = ""
+	hashOnly.Extensions = extensions
+	resp, err := g.post(ctx, &hashOnly)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resp.Errors.hasCode(gqlPersistedQueryNotSupported):
+		g.persisted.Store(false)
+		return g.send(ctx, req, result)
+	case resp.Errors.hasCode(gqlPersistedQueryNotFound):
+		register := *req
+		register.Extensions = extensions
+		return g.send(ctx, &register, result)
+	}
+	return resp.decode(result)
+}
+
+// send 发送请求并解析响应
+func (g *GraphQLClient) send(ctx context.Context, req *GraphQLRequest, result any) error {
+	resp, err := g.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	return resp.decode(result)
+}
+
+// post 发送请求，HTTP错误状态码的响应体为GraphQL响应时按GraphQL错误处理
+func (g *GraphQLClient) post(ctx context.Context, req *GraphQLRequest) (*graphQLResponse, error) {
+	resp, err := g.client.Post(ctx, g.endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var gr graphQLResponse
+	if err := json.Unmarshal(resp.Body, &gr); err != nil || (gr.Data == nil && gr.Errors == nil) {
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		if err == nil {
+			err = fmt.Errorf("响应缺少 data 和 errors")
+		}
+		return nil, fmt.Errorf("解析GraphQL响应失败: %w", err)
+	}
+	return &gr, nil
+}
+
+// decode 解析 data 并转换错误
+func (r *graphQLResponse) decode(result any) error {
+	if result != nil && len(r.Data) > 0 && string(r.Data) != "null" {
+		if err := json.Unmarshal(r.Data, result); err != nil {
+			return fmt.Errorf("解析GraphQL数据失败: %w", err)
+		}
+	}
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return xerr.Wrap(graphQLErrCode(r.Errors), r.Errors, "graphql")
+}
+
+// graphQLErrCode 按第一个错误的 extensions.code 映射业务错误码
+// 服务端的 UNAUTHENTICATED、FORBIDDEN 是本服务调用上游的凭证问题，按服务错误处理，不作为终端用户的401、403返回
+func graphQLErrCode(errs GraphQLErrors) xerr.ErrCode {
+	switch errs[0].Code() {
+	case "BAD_USER_INPUT", "GRAPHQL_VALIDATION_FAILED", "GRAPHQL_PARSE_FAILED", "BAD_REQUEST":
+		return xerr.ParamError
+	default:
+		return xerr.ServerError
+	}
+}
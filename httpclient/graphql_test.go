@@ -0,0 +1,45 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+func TestGraphQLErrCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want xerr.ErrCode
+	}{
+		{"UNAUTHENTICATED", xerr.ServerError},
+		{"FORBIDDEN", xerr.ServerError},
+		{"BAD_USER_INPUT", xerr.ParamError},
+		{"INTERNAL_SERVER_ERROR", xerr.ServerError},
+		{"", xerr.ServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"data":{"user":null},"errors":[{"message":"denied","path":["user"],"extensions":{"code":%q}}]}`, tt.code)
+			}))
+			defer srv.Close()
+
+			g := NewGraphQLClient(NewClient(WithBaseURL(srv.URL)), "/graphql")
+			err := g.Query(context.Background(), "{ user { id } }", nil, nil)
+			if !xerr.IsErrorCode(err, tt.want) {
+				t.Fatalf("err = %v (code %d), want code %d", err, xerr.GetErrorCode(err), tt.want)
+			}
+			// 上游的原始错误码仍可获取
+			var gqlErrs GraphQLErrors
+			if !errors.As(err, &gqlErrs) || gqlErrs[0].Code() != tt.code || gqlErrs.Error() != "user: denied" {
+				t.Fatalf("GraphQLErrors = %v", gqlErrs)
+			}
+		})
+	}
+}
@@ -18,6 +18,9 @@ type Client struct {
 	debugMode     bool
 	retryCount    int
 	retryWaitTime time.Duration
+	guard         *hostGuard      // 出站主机校验（SSRF防护）
+	protocol      *protocolConfig // 协议与连接健康配置
+	cache         CacheStore      // 响应缓存（可选）
 }
 
 // Option 是创建客户端的选项函数
@@ -56,6 +59,11 @@ func NewClient(options ...Option) *Client {
 	c.client.SetRetryCount(c.retryCount)
 	c.client.SetRetryWaitTime(c.retryWaitTime)
 
+	// 设置传输层：出站主机校验、协议与连接健康
+	c.applyTransport()
+	// 响应缓存包装在最外层
+	c.applyCache()
+
 	return c
 }
 
@@ -0,0 +1,245 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrStopPagination 在分页回调中返回以提前结束遍历，Paginate 返回 nil
+var ErrStopPagination = errors.New("stop pagination")
+
+// PageRequest 分页请求
+type PageRequest struct {
+	// 请求路径或完整URL
+	Path string
+	// 查询参数
+	Params map[string]string
+}
+
+// NextPageFunc 从当前响应中提取下一页请求，返回 nil 表示没有更多页
+type NextPageFunc func(req *PageRequest, resp *Response) (*PageRequest, error)
+
+// pageConfig 分页配置
+type pageConfig struct {
+	next       NextPageFunc
+	maxPages   int
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// PageOption 分页选项
+type PageOption func(*pageConfig)
+
+// WithNextPage 设置下一页提取方式，默认 LinkHeaderNext
+func WithNextPage(next NextPageFunc) PageOption {
+	return func(c *pageConfig) {
+		c.next = next
+	}
+}
+
+// WithMaxPages 设置最多请求的页数，0为不限制
+func WithMaxPages(n int) PageOption {
+	return func(c *pageConfig) {
+		c.maxPages = n
+	}
+}
+
+// WithRateLimitBackoff 设置遇到429时的退避策略：优先使用 Retry-After，否则从 minWait 开始指数退避至 maxWait
+func WithRateLimitBackoff(maxRetries int, minWait, maxWait time.Duration) PageOption {
+	return func(c *pageConfig) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minWait
+		c.maxBackoff = maxWait
+	}
+}
+
+// LinkHeaderNext 根据 Link 响应头中 rel="next" 的链接翻页（GitHub 等API）
+func LinkHeaderNext() NextPageFunc {
+	return func(_ *PageRequest, resp *Response) (*PageRequest, error) {
+		for _, header := range resp.Headers["Link"] {
+			if next := parseLinkNext(header); next != "" {
+				return &PageRequest{Path: next}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+// CursorNext 根据响应体中的游标字段翻页，field 支持点分路径（如 meta.next_cursor）
+// 游标作为查询参数 param 传给下一页，游标为空或不存在时结束
+func CursorNext(field, param string) NextPageFunc {
+	return func(req *PageRequest, resp *Response) (*PageRequest, error) {
+		var body any
+		if err := json.Unmarshal(resp.Body, &body); err != nil {
+			return nil, fmt.Errorf("decode page body: %w", err)
+		}
+
+		cursor := lookupJSONField(body, field)
+		if cursor == "" {
+			return nil, nil
+		}
+
+		params := maps.Clone(req.Params)
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[param] = cursor
+		return &PageRequest{Path: req.Path, Params: params}, nil
+	}
+}
+
+// Paginate 依次请求每一页并交给回调处理，直到没有下一页、达到最大页数或回调返回 ErrStopPagination
+func (c *Client) Paginate(ctx context.Context, path string, params map[string]string, fn func(resp *Response) error, opts ...PageOption) error {
+	cfg := &pageConfig{
+		next:       LinkHeaderNext(),
+		maxRetries: 5,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	req := &PageRequest{Path: path, Params: params}
+	for page := 1; req != nil; page++ {
+		if cfg.maxPages > 0 && page > cfg.maxPages {
+			return nil
+		}
+
+		resp, err := c.getWithBackoff(ctx, req, cfg)
+		if err != nil {
+			return err
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("page %d: %w", page, resp.Error)
+		}
+
+		if err := fn(resp); err != nil {
+			if errors.Is(err, ErrStopPagination) {
+				return nil
+			}
+			return err
+		}
+
+		if req, err = cfg.next(req, resp); err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+	}
+	return nil
+}
+
+// PaginateJSON 分页请求并将每页响应体解析为 T 后交给回调处理
+func PaginateJSON[T any](ctx context.Context, c *Client, path string, params map[string]string, fn func(page T) error, opts ...PageOption) error {
+	return c.Paginate(ctx, path, params, func(resp *Response) error {
+		var page T
+		if err := json.Unmarshal(resp.Body, &page); err != nil {
+			return fmt.Errorf("decode page body: %w", err)
+		}
+		return fn(page)
+	}, opts...)
+}
+
+// getWithBackoff 请求单页，遇到429时退避重试
+func (c *Client) getWithBackoff(ctx context.Context, req *PageRequest, cfg *pageConfig) (*Response, error) {
+	backoff := cfg.minBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.Get(ctx, req.Path, req.Params)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= cfg.maxRetries {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp.Headers)
+		if wait <= 0 {
+			wait = backoff
+			backoff = min(backoff*2, cfg.maxBackoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryAfter 解析 Retry-After 响应头（秒数或HTTP日期）
+func retryAfter(headers map[string][]string) time.Duration {
+	values := http.Header(headers).Values("Retry-After")
+	if len(values) == 0 {
+		return 0
+	}
+
+	v := strings.TrimSpace(values[0])
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// parseLinkNext 从 Link 头中解析 rel="next" 的URL
+// 格式: <https://api.example.com/items?page=2>; rel="next", <...>; rel="last"
+func parseLinkNext(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		segments := strings.Split(link, ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		url := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(url, "<") || !strings.HasSuffix(url, ">") {
+			continue
+		}
+
+		for _, attr := range segments[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(attr), "=")
+			if !ok || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if rel == "next" {
+					return url[1 : len(url)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// lookupJSONField 按点分路径读取JSON字段，转为字符串；不存在或为null时返回空
+func lookupJSONField(body any, field string) string {
+	current := body
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current = obj[key]
+	}
+
+	switch v := current.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
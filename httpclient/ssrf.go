@@ -0,0 +1,231 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+var (
+	// ErrHostNotAllowed 目标主机不在允许列表中
+	ErrHostNotAllowed = errors.New("host not allowed")
+	// ErrPrivateAddress 目标地址为内网/保留地址
+	ErrPrivateAddress = errors.New("private address not allowed")
+)
+
+// 额外需要拦截的保留地址段
+var reservedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // 本网络
+	"100.64.0.0/10", // 运营商级NAT
+	"192.0.0.0/24",  // IETF协议分配
+	"198.18.0.0/15", // 基准测试
+	"240.0.0.0/4",   // 保留
+	"64:ff9b::/96",  // NAT64
+)
+
+// hostGuard 出站请求的主机校验
+type hostGuard struct {
+	hosts        []string     // 允许的主机名，支持 *.example.com
+	cidrs        []*net.IPNet // 允许的IP段
+	blockPrivate bool         // 是否拦截内网地址
+}
+
+// WithAllowedHosts 限制只能访问允许列表中的主机
+// 支持主机名(example.com)、通配符(*.example.com)、IP 和 CIDR(10.0.0.0/8)
+func WithAllowedHosts(hosts ...string) Option {
+	return func(c *Client) {
+		c.ensureHostGuard().allow(hosts...)
+	}
+}
+
+// WithSSRFProtection 开启SSRF防护：在建立连接时校验实际解析出的IP，
+// 拦截内网、回环、链路本地等地址（包括重定向后的地址），显式允许的CIDR除外
+func WithSSRFProtection() Option {
+	return func(c *Client) {
+		c.ensureHostGuard().blockPrivate = true
+	}
+}
+
+// NewSafeHTTPClient 创建开启SSRF防护的标准库HTTP客户端，用于拉取用户提供的URL：
+// 连接时校验实际解析出的IP，拦截内网、回环等地址，重定向只允许 http/https 且目标同样校验，最多10次
+// allowedHosts 同 WithAllowedHosts，为空时允许所有公网主机
+func NewSafeHTTPClient(timeout time.Duration, allowedHosts ...string) *http.Client {
+	g := &hostGuard{blockPrivate: true}
+	g.allow(allowedHosts...)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	g.applyTransport(transport)
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to %s", ErrHostNotAllowed, req.URL.Redacted())
+			}
+			return g.checkRedirect(req)
+		},
+	}
+}
+
+// ensureHostGuard 获取或创建主机校验器
+func (c *Client) ensureHostGuard() *hostGuard {
+	if c.guard == nil {
+		c.guard = &hostGuard{}
+	}
+	return c.guard
+}
+
+// allow 添加允许的主机名、IP 和 CIDR
+func (g *hostGuard) allow(hosts ...string) {
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" {
+			continue
+		}
+
+		if _, ipNet, err := net.ParseCIDR(h); err == nil {
+			g.cidrs = append(g.cidrs, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(h); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			g.cidrs = append(g.cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		g.hosts = append(g.hosts, h)
+	}
+}
+
+// apply 为传输层设置连接时的IP校验，并为客户端设置重定向策略
+func (g *hostGuard) apply(client *resty.Client, transport *http.Transport) {
+	g.applyTransport(transport)
+
+	// 重定向时提前校验主机名，IP在建立连接时校验
+	client.SetRedirectPolicy(resty.FlexibleRedirectPolicy(10), resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+		return g.checkRedirect(req)
+	}))
+}
+
+// applyTransport 为传输层设置连接时的IP校验，防护模式下禁用代理
+func (g *hostGuard) applyTransport(transport *http.Transport) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	// 代理会绕过连接时的IP校验，防护模式下禁用
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		d := *dialer
+		d.Control = func(network, address string, _ syscall.RawConn) error {
+			ipStr, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return g.check(host, net.ParseIP(ipStr))
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+}
+
+// checkRedirect 重定向时提前校验主机名，IP在建立连接时校验
+func (g *hostGuard) checkRedirect(req *http.Request) error {
+	if len(g.hosts) > 0 && len(g.cidrs) == 0 && !g.hostAllowed(req.URL.Hostname()) {
+		return fmt.Errorf("%w: redirect to %s", ErrHostNotAllowed, req.URL.Hostname())
+	}
+	return nil
+}
+
+// check 校验主机名和实际连接的IP
+func (g *hostGuard) check(host string, ip net.IP) error {
+	if ip == nil {
+		return fmt.Errorf("%w: invalid address for %s", ErrHostNotAllowed, host)
+	}
+
+	inCIDR := g.ipAllowed(ip)
+
+	// 配置了允许列表时，主机名或IP至少命中一项
+	if len(g.hosts) > 0 || len(g.cidrs) > 0 {
+		if !g.hostAllowed(host) && !inCIDR {
+			return fmt.Errorf("%w: %s", ErrHostNotAllowed, host)
+		}
+	}
+
+	// 显式允许的IP段不受内网拦截限制
+	if g.blockPrivate && !inCIDR && isPrivateIP(ip) {
+		return fmt.Errorf("%w: %s resolved to %s", ErrPrivateAddress, host, ip)
+	}
+
+	return nil
+}
+
+// hostAllowed 主机名是否在允许列表中
+func (g *hostGuard) hostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, h := range g.hosts {
+		if h == host {
+			return true
+		}
+		if strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipAllowed IP是否在允许的IP段中
+func (g *hostGuard) ipAllowed(ip net.IP) bool {
+	for _, n := range g.cidrs {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateIP 是否为内网、回环、链路本地或保留地址
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+
+	for _, n := range reservedNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// mustParseCIDRs 解析CIDR列表
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
@@ -0,0 +1,162 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostGuardCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		block   bool
+		host    string
+		ip      string
+		wantErr error
+	}{
+		{"public without guard rules", nil, true, "example.com", "93.184.216.34", nil},
+		{"loopback blocked", nil, true, "localhost", "127.0.0.1", ErrPrivateAddress},
+		{"private blocked", nil, true, "intranet", "10.1.2.3", ErrPrivateAddress},
+		{"link local blocked", nil, true, "metadata", "169.254.169.254", ErrPrivateAddress},
+		{"carrier nat blocked", nil, true, "cgnat", "100.64.0.1", ErrPrivateAddress},
+		{"ipv6 loopback blocked", nil, true, "localhost", "::1", ErrPrivateAddress},
+		{"ipv6 unique local blocked", nil, true, "intranet", "fd00::1", ErrPrivateAddress},
+		{"private allowed without protection", nil, false, "intranet", "10.1.2.3", nil},
+		{"wildcard subdomain", []string{"*.example.com"}, true, "api.example.com", "93.184.216.34", nil},
+		{"wildcard nested subdomain", []string{"*.example.com"}, true, "a.b.example.com", "93.184.216.34", nil},
+		{"wildcard excludes apex", []string{"*.example.com"}, true, "example.com", "93.184.216.34", ErrHostNotAllowed},
+		{"wildcard excludes lookalike", []string{"*.example.com"}, true, "evilexample.com", "93.184.216.34", ErrHostNotAllowed},
+		{"host case and trailing dot", []string{"Example.com"}, true, "EXAMPLE.com.", "93.184.216.34", nil},
+		{"allowed host resolving to private", []string{"example.com"}, true, "example.com", "10.0.0.1", ErrPrivateAddress},
+		{"allowed cidr overrides private block", []string{"10.0.0.0/8"}, true, "intranet", "10.1.2.3", nil},
+		{"allowed ip overrides private block", []string{"127.0.0.1"}, true, "localhost", "127.0.0.1", nil},
+		{"outside allowed cidr", []string{"10.0.0.0/8"}, true, "intranet", "192.168.1.1", ErrHostNotAllowed},
+		{"invalid ip", nil, true, "example.com", "", ErrHostNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{}
+			WithAllowedHosts(tt.allowed...)(c)
+			if tt.block {
+				WithSSRFProtection()(c)
+			}
+			err := c.guard.check(tt.host, net.ParseIP(tt.ip))
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("check(%s, %s) = %v, want %v", tt.host, tt.ip, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// listenLoopback 在指定回环地址上启动测试服务
+func listenLoopback(t *testing.T, addr string, h http.Handler) *httptest.Server {
+	t.Helper()
+	l, err := net.Listen("tcp", addr+":0")
+	if err != nil {
+		t.Skipf("listen on %s: %v", addr, err)
+	}
+	srv := httptest.NewUnstartedServer(h)
+	srv.Listener.Close()
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSSRFProtectionDial(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	private := listenLoopback(t, "127.0.0.2", ok)
+	public := listenLoopback(t, "127.0.0.1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, private.URL, http.StatusFound)
+			return
+		}
+		ok(w, r)
+	}))
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		url     string
+		wantErr error
+	}{
+		{"loopback blocked", []Option{WithSSRFProtection()}, public.URL, ErrPrivateAddress},
+		{"allowed cidr", []Option{WithSSRFProtection(), WithAllowedHosts("127.0.0.1/32")}, public.URL, nil},
+		// 重定向目标在连接时校验，未被允许的内网地址被拦截
+		{"redirect to private blocked", []Option{WithSSRFProtection(), WithAllowedHosts("127.0.0.1/32")}, public.URL + "/redirect", ErrHostNotAllowed},
+		{"host not allowed", []Option{WithAllowedHosts("*.example.com")}, public.URL, ErrHostNotAllowed},
+		{"no guard", nil, public.URL + "/redirect", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(append(tt.opts, WithRetry(0, 0))...)
+			_, err := c.Get(context.Background(), tt.url, nil)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%s) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSSRFProtectionIgnoresProxy(t *testing.T) {
+	c := NewClient(WithSSRFProtection())
+	transport, ok := c.client.GetClient().Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport = %T", c.client.GetClient().Transport)
+	}
+	if transport.Proxy != nil {
+		t.Fatal("proxy must be disabled when SSRF protection is on")
+	}
+}
+
+func TestNewSafeHTTPClient(t *testing.T) {
+	private := listenLoopback(t, "127.0.0.2", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	public := listenLoopback(t, "127.0.0.1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, private.URL, http.StatusFound)
+		case "/file":
+			http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+
+	tests := []struct {
+		name    string
+		allowed []string
+		url     string
+		wantErr error
+	}{
+		{"loopback blocked", nil, public.URL, ErrPrivateAddress},
+		{"allowed cidr", []string{"127.0.0.1/32"}, public.URL, nil},
+		{"redirect to private blocked", []string{"127.0.0.1/32"}, public.URL + "/redirect", ErrHostNotAllowed},
+		{"redirect to other scheme blocked", []string{"127.0.0.1/32"}, public.URL + "/file", ErrHostNotAllowed},
+		{"host not allowed", []string{"*.example.com"}, public.URL, ErrHostNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewSafeHTTPClient(time.Second, tt.allowed...).Get(tt.url)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%s) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+
+	transport, ok := NewSafeHTTPClient(time.Second).Transport.(*http.Transport)
+	if !ok || transport.Proxy != nil {
+		t.Fatal("proxy must be disabled for the safe client")
+	}
+}
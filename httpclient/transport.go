@@ -0,0 +1,266 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认连续失败多少次后降级到HTTP/1.1
+	defaultFallbackThreshold = 3
+	// 默认降级持续时间
+	defaultFallbackCooldown = 10 * time.Minute
+)
+
+// HTTP/2失败的错误特征：标准库内置HTTP/2实现的错误以 http2: 开头，流错误以 stream error: 开头，
+// ALPN协商失败为TLS的 no application protocol 告警
+var http2FailureMarkers = []string{"http2:", "stream error:", "no application protocol"}
+
+// protocolConfig 协议与连接健康配置
+type protocolConfig struct {
+	forceHTTP2      bool
+	h2c             bool
+	idleConnTimeout time.Duration
+	pingInterval    time.Duration
+	pingTimeout     time.Duration
+	fallback        bool
+	threshold       int
+	cooldown        time.Duration
+}
+
+// WithHTTP2 强制使用HTTP/2：TLS连接只协商h2，h2c 为 true 时明文连接使用 h2c（需服务端支持）
+// 未开启 WithProtocolFallback 时，不支持HTTP/2的服务端请求会失败
+func WithHTTP2(h2c bool) Option {
+	return func(c *Client) {
+		p := c.ensureProtocol()
+		p.forceHTTP2 = true
+		p.h2c = h2c
+	}
+}
+
+// WithConnHealth 设置连接健康检查：idleTimeout 为空闲连接保留时间，
+// HTTP/2连接空闲 pingInterval 后发送PING，pingTimeout 内未响应则关闭连接，避免在失效连接上静默挂起
+func WithConnHealth(idleTimeout, pingInterval, pingTimeout time.Duration) Option {
+	return func(c *Client) {
+		p := c.ensureProtocol()
+		p.idleConnTimeout = idleTimeout
+		p.pingInterval = pingInterval
+		p.pingTimeout = pingTimeout
+	}
+}
+
+// WithProtocolFallback 开启协议自动降级：某主机的HTTP/2请求连续失败 threshold 次后，
+// 在 cooldown 内改用HTTP/1.1；GET、HEAD 等安全方法的请求失败时立即用HTTP/1.1重试一次
+// threshold <=0 时为3，cooldown <=0 时为10分钟
+func WithProtocolFallback(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		p := c.ensureProtocol()
+		p.fallback = true
+		p.threshold = threshold
+		p.cooldown = cooldown
+		if p.threshold <= 0 {
+			p.threshold = defaultFallbackThreshold
+		}
+		if p.cooldown <= 0 {
+			p.cooldown = defaultFallbackCooldown
+		}
+	}
+}
+
+// ensureProtocol 获取或创建协议配置
+func (c *Client) ensureProtocol() *protocolConfig {
+	if c.protocol == nil {
+		c.protocol = &protocolConfig{}
+	}
+	return c.protocol
+}
+
+// applyTransport 按出站主机校验和协议配置设置传输层，均未设置时使用resty默认传输层
+func (c *Client) applyTransport() {
+	if c.guard == nil && c.protocol == nil {
+		return
+	}
+
+	p := c.protocol
+	if p == nil {
+		c.client.SetTransport(c.newTransport(nil))
+		return
+	}
+
+	var primary *http.Protocols
+	if p.forceHTTP2 {
+		primary = new(http.Protocols)
+		primary.SetHTTP2(true)
+		primary.SetUnencryptedHTTP2(p.h2c)
+	}
+	if !p.fallback {
+		c.client.SetTransport(c.newTransport(primary))
+		return
+	}
+
+	// 降级用的HTTP/1.1传输层
+	http1 := new(http.Protocols)
+	http1.SetHTTP1(true)
+	c.client.SetTransport(&fallbackTransport{
+		primary:   c.newTransport(primary),
+		http1:     c.newTransport(http1),
+		threshold: p.threshold,
+		cooldown:  p.cooldown,
+		hosts:     make(map[string]*hostFallback),
+	})
+}
+
+// newTransport 创建传输层，protocols 为空时使用默认协议协商
+// 协议须在首次使用或 Clone 前设置，因此每个传输层独立创建
+func (c *Client) newTransport(protocols *http.Protocols) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.guard != nil {
+		c.guard.apply(c.client, transport)
+	}
+
+	if p := c.protocol; p != nil {
+		if p.idleConnTimeout > 0 {
+			transport.IdleConnTimeout = p.idleConnTimeout
+		}
+		if p.pingInterval > 0 {
+			transport.HTTP2 = &http.HTTP2Config{
+				SendPingTimeout: p.pingInterval,
+				PingTimeout:     p.pingTimeout,
+			}
+		}
+	}
+	transport.Protocols = protocols
+	return transport
+}
+
+// hostFallback 单个主机的降级状态
+type hostFallback struct {
+	failures int
+	until    time.Time
+}
+
+// fallbackTransport 按主机在HTTP/2和HTTP/1.1之间自动降级的传输层
+type fallbackTransport struct {
+	primary   *http.Transport
+	http1     *http.Transport
+	threshold int
+	cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostFallback
+}
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if t.degraded(host) {
+		return t.http1.RoundTrip(req)
+	}
+
+	resp, err := t.primary.RoundTrip(req)
+	if err == nil {
+		if resp.ProtoMajor == 2 {
+			t.reset(host)
+		}
+		return resp, nil
+	}
+	if !isHTTP2Failure(req, err) {
+		return nil, err
+	}
+
+	t.recordFailure(host, err)
+
+	// 可安全重放的请求立即用HTTP/1.1重试
+	retry, ok := replayable(req)
+	if !ok {
+		return nil, err
+	}
+	return t.http1.RoundTrip(retry)
+}
+
+// degraded 主机是否处于降级期
+func (t *fallbackTransport) degraded(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.hosts[host]
+	if !ok || h.until.IsZero() {
+		return false
+	}
+	if time.Now().After(h.until) {
+		// 降级期结束，重新尝试HTTP/2
+		delete(t.hosts, host)
+		return false
+	}
+	return true
+}
+
+// recordFailure 记录一次HTTP/2失败，达到阈值后进入降级期
+func (t *fallbackTransport) recordFailure(host string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, ok := t.hosts[host]
+	if !ok {
+		h = &hostFallback{}
+		t.hosts[host] = h
+	}
+	h.failures++
+	if h.failures >= t.threshold && h.until.IsZero() {
+		h.until = time.Now().Add(t.cooldown)
+		logx.Errorf("httpclient: %s failed %d times over HTTP/2 (last: %v), falling back to HTTP/1.1 for %s",
+			host, h.failures, err, t.cooldown)
+	}
+}
+
+// reset HTTP/2请求成功后清空失败计数
+func (t *fallbackTransport) reset(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.hosts, host)
+}
+
+// isHTTP2Failure 是否为HTTP/2实现问题导致的失败：HTTP/2协议或流错误、连接丢失（含PING超时）、ALPN协商失败
+// 普通的超时、连接拒绝等错误与协议无关（HTTP/1.1下同样会发生），不计入；调用方主动取消的请求也不计入
+func isHTTP2Failure(req *http.Request, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	msg := err.Error()
+	for _, marker := range http2FailureMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// replayable 复制可安全重放的请求：安全方法（GET、HEAD、OPTIONS、TRACE）且请求体可重新获取
+// PUT、DELETE 等在实际业务中未必幂等，第一次请求可能已被服务端处理，不重放
+func replayable(req *http.Request) (*http.Request, bool) {
+	if req.Context().Err() != nil {
+		return nil, false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+	default:
+		return nil, false
+	}
+
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		retry.Body = body
+	}
+	return retry, true
+}
@@ -0,0 +1,78 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsHTTP2Failure(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"goaway", context.Background(), errors.New("http2: server sent GOAWAY and closed the connection"), true},
+		{"connection lost", context.Background(), errors.New("http2: client connection lost"), true},
+		{"stream error", context.Background(), errors.New("stream error: stream ID 3; PROTOCOL_ERROR"), true},
+		{"alpn", context.Background(), errors.New("remote error: tls: no application protocol"), true},
+		{"timeout", context.Background(), &url.Error{Op: "Get", URL: "http://a", Err: os.ErrDeadlineExceeded}, false},
+		{"refused", context.Background(), errors.New("dial tcp 127.0.0.1:1: connect: connection refused"), false},
+		{"caller canceled", canceled, errors.New("http2: client connection lost"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequestWithContext(tt.ctx, http.MethodGet, "https://example.com", nil)
+			if got := isHTTP2Failure(req, tt.err); got != tt.want {
+				t.Fatalf("isHTTP2Failure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	tests := []struct {
+		method string
+		body   bool
+		want   bool
+	}{
+		{http.MethodGet, false, true},
+		{http.MethodHead, false, true},
+		{http.MethodOptions, false, true},
+		{http.MethodGet, true, true},
+		{http.MethodPut, true, false},
+		{http.MethodDelete, false, false},
+		{http.MethodPost, true, false},
+		{http.MethodPatch, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var req *http.Request
+			if tt.body {
+				req, _ = http.NewRequest(tt.method, "https://example.com", strings.NewReader("payload"))
+			} else {
+				req, _ = http.NewRequest(tt.method, "https://example.com", nil)
+			}
+
+			retry, ok := replayable(req)
+			if ok != tt.want {
+				t.Fatalf("replayable(%s) = %v, want %v", tt.method, ok, tt.want)
+			}
+			if ok && tt.body {
+				data := make([]byte, 16)
+				n, _ := retry.Body.Read(data)
+				if string(data[:n]) != "payload" {
+					t.Fatalf("replayed body = %q", data[:n])
+				}
+			}
+		})
+	}
+}
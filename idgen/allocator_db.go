@@ -0,0 +1,110 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 号段表默认表名
+	defaultLeafTableName = "leaf_alloc"
+	// 号段默认步长
+	defaultLeafStep = segmentSize
+)
+
+// LeafAlloc 号段表结构（Leaf模型）
+type LeafAlloc struct {
+	BizTag      string    `gorm:"column:biz_tag;primaryKey;size:128"`
+	MaxID       int64     `gorm:"column:max_id;not null;default:1"`
+	Step        int64     `gorm:"column:step;not null"`
+	Description string    `gorm:"column:description;size:256"`
+	UpdatedAt   time.Time `gorm:"column:updated_at"`
+}
+
+// DBSegmentAllocator 基于MySQL/Postgres号段表的分配器
+type DBSegmentAllocator struct {
+	db        *gorm.DB
+	tableName string
+	step      int64
+}
+
+// DBAllocatorOption 数据库号段分配器配置选项
+type DBAllocatorOption func(*DBSegmentAllocator)
+
+// WithLeafTableName 设置号段表名
+func WithLeafTableName(name string) DBAllocatorOption {
+	return func(a *DBSegmentAllocator) {
+		if name != "" {
+			a.tableName = name
+		}
+	}
+}
+
+// WithLeafStep 设置新业务标识的默认步长
+func WithLeafStep(step int64) DBAllocatorOption {
+	return func(a *DBSegmentAllocator) {
+		if step > 0 {
+			a.step = step
+		}
+	}
+}
+
+// NewDBSegmentAllocator 创建数据库号段分配器
+func NewDBSegmentAllocator(db *gorm.DB, opts ...DBAllocatorOption) *DBSegmentAllocator {
+	a := &DBSegmentAllocator{
+		db:        db,
+		tableName: defaultLeafTableName,
+		step:      defaultLeafStep,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// AutoMigrate 创建号段表
+func (a *DBSegmentAllocator) AutoMigrate() error {
+	return a.db.Table(a.tableName).AutoMigrate(&LeafAlloc{})
+}
+
+// NextSegment 在事务中推进 max_id 并返回新号段
+func (a *DBSegmentAllocator) NextSegment(ctx context.Context, bizTag string) (int64, int64, error) {
+	if a.db == nil {
+		return 0, 0, errors.New("database not initialized")
+	}
+
+	var leaf LeafAlloc
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 业务标识不存在时自动初始化
+		if err := tx.Table(a.tableName).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LeafAlloc{BizTag: bizTag, MaxID: 1, Step: a.step, UpdatedAt: time.Now()}).Error; err != nil {
+			return err
+		}
+
+		// 原子推进 max_id，行锁保证多实例之间号段不重叠
+		if err := tx.Table(a.tableName).
+			Where("biz_tag = ?", bizTag).
+			Updates(map[string]interface{}{
+				"max_id":     gorm.Expr("max_id + step"),
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Table(a.tableName).Where("biz_tag = ?", bizTag).Take(&leaf).Error
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to allocate segment from database: %w", err)
+	}
+
+	// 号段范围为 [max_id - step, max_id)
+	return leaf.MaxID - leaf.Step, leaf.Step, nil
+}
@@ -0,0 +1,254 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// etcd节点ID键前缀
+	etcdNodeKeyPrefix = "/idgen/uniqueid/node/"
+	// etcd租约默认TTL（秒）
+	etcdLeaseTTL = 30
+	// etcd操作超时时间
+	etcdOpTimeout = 5 * time.Second
+	// 租约丢失后重新占用节点ID的重试间隔
+	etcdReacquireInterval = time.Second
+)
+
+// ErrNodeIDLost 节点ID的租约已丢失且尚未重新占用，此时拒绝生成ID，避免与占用同一ID的其他节点重复
+var ErrNodeIDLost = errors.New("node id lease lost")
+
+// NodeIDAllocator 节点ID分配器，用于替换默认的Redis分配方式
+type NodeIDAllocator interface {
+	// Allocate 分配节点ID，优先尝试 preferredID，ctx 取消时释放已占用的ID
+	Allocate(ctx context.Context, preferredID int64) (int64, error)
+}
+
+// NodeIDLeaseChecker 可选接口，分配器实现后生成ID前检查已分配的节点ID是否仍被本节点占用
+type NodeIDLeaseChecker interface {
+	// CheckNodeID 节点ID不再被本节点占用时返回错误
+	CheckNodeID() error
+}
+
+// EtcdNodeIDAllocator 基于etcd租约的节点ID分配器
+// 节点ID键绑定租约并持续续约，进程退出或失联后租约过期，ID自动回收；
+// 租约丢失后在后台重新占用同一ID，期间及ID被其他节点占用后 CheckNodeID 返回 ErrNodeIDLost
+type EtcdNodeIDAllocator struct {
+	cli    *clientv3.Client
+	prefix string
+	ttl    int64
+	lost   atomic.Bool
+}
+
+// EtcdAllocatorOption etcd分配器配置选项
+type EtcdAllocatorOption func(*EtcdNodeIDAllocator)
+
+// WithEtcdKeyPrefix 设置节点ID键前缀
+func WithEtcdKeyPrefix(prefix string) EtcdAllocatorOption {
+	return func(a *EtcdNodeIDAllocator) {
+		if prefix != "" {
+			a.prefix = strings.TrimSuffix(prefix, "/") + "/"
+		}
+	}
+}
+
+// WithEtcdLeaseTTL 设置租约TTL（秒）
+func WithEtcdLeaseTTL(ttl int64) EtcdAllocatorOption {
+	return func(a *EtcdNodeIDAllocator) {
+		if ttl > 0 {
+			a.ttl = ttl
+		}
+	}
+}
+
+// NewEtcdNodeIDAllocator 创建etcd节点ID分配器
+func NewEtcdNodeIDAllocator(cli *clientv3.Client, opts ...EtcdAllocatorOption) *EtcdNodeIDAllocator {
+	a := &EtcdNodeIDAllocator{
+		cli:    cli,
+		prefix: etcdNodeKeyPrefix,
+		ttl:    etcdLeaseTTL,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Allocate 通过租约占用节点ID，并在后台保持续约
+func (a *EtcdNodeIDAllocator) Allocate(ctx context.Context, preferredID int64) (int64, error) {
+	if a.cli == nil {
+		return 0, errors.New("etcd client not initialized")
+	}
+
+	// 创建租约
+	grantCtx, cancel := context.WithTimeout(ctx, etcdOpTimeout)
+	lease, err := a.cli.Grant(grantCtx, a.ttl)
+	cancel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+
+	// 节点键的值记录主机名，便于排查
+	owner, _ := os.Hostname()
+
+	// 先尝试首选ID，再按顺序寻找可用ID
+	candidates := make([]int64, 0, nodeIDMask+2)
+	if preferredID >= 0 && preferredID <= nodeIDMask {
+		candidates = append(candidates, preferredID)
+	}
+	for i := int64(0); i <= nodeIDMask; i++ {
+		candidates = append(candidates, i)
+	}
+
+	allocated := int64(-1)
+	for _, id := range candidates {
+		ok, err := a.tryAcquire(ctx, id, owner, lease.ID)
+		if err != nil {
+			a.revoke(lease.ID)
+			return 0, err
+		}
+		if ok {
+			allocated = id
+			break
+		}
+	}
+
+	if allocated < 0 {
+		a.revoke(lease.ID)
+		return 0, errors.New("no available node IDs in etcd")
+	}
+
+	// 启动续约
+	keepAlive, err := a.cli.KeepAlive(ctx, lease.ID)
+	if err != nil {
+		a.revoke(lease.ID)
+		return 0, fmt.Errorf("failed to keep etcd lease alive: %w", err)
+	}
+
+	a.lost.Store(false)
+	go a.watchKeepAlive(ctx, keepAlive, allocated, owner, lease.ID)
+
+	return allocated, nil
+}
+
+// CheckNodeID 实现 NodeIDLeaseChecker 接口，租约丢失且尚未重新占用时返回 ErrNodeIDLost
+func (a *EtcdNodeIDAllocator) CheckNodeID() error {
+	if a.lost.Load() {
+		return ErrNodeIDLost
+	}
+	return nil
+}
+
+// 使用事务抢占节点ID键，键不存在时才写入
+func (a *EtcdNodeIDAllocator) tryAcquire(ctx context.Context, id int64, owner string, leaseID clientv3.LeaseID) (bool, error) {
+	key := fmt.Sprintf("%s%d", a.prefix, id)
+
+	txnCtx, cancel := context.WithTimeout(ctx, etcdOpTimeout)
+	defer cancel()
+
+	resp, err := a.cli.Txn(txnCtx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, owner, clientv3.WithLease(leaseID))).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire etcd node key %s: %w", key, err)
+	}
+
+	return resp.Succeeded, nil
+}
+
+// 消费续约响应，续约中断时停止发号并重新占用同一节点ID；ctx 取消后撤销租约释放节点ID
+func (a *EtcdNodeIDAllocator) watchKeepAlive(ctx context.Context, ch <-chan *clientv3.LeaseKeepAliveResponse, id int64, owner string, leaseID clientv3.LeaseID) {
+	for {
+		for range ch {
+		}
+
+		if ctx.Err() != nil {
+			logx.Infof("Etcd node ID %d keepalive stopped, releasing lease", id)
+			a.revoke(leaseID)
+			return
+		}
+
+		a.lost.Store(true)
+		logx.Errorf("Error: etcd lease for node ID %d lost, stop generating IDs until it is re-acquired", id)
+
+		var ok bool
+		ch, leaseID, ok = a.reacquire(ctx, id, owner, leaseID)
+		if !ok {
+			return
+		}
+		a.lost.Store(false)
+		logx.Infof("Re-acquired etcd node ID %d", id)
+	}
+}
+
+// 重新占用节点ID直到成功，ID已被其他节点占用或 ctx 取消时返回 false
+func (a *EtcdNodeIDAllocator) reacquire(ctx context.Context, id int64, owner string, oldLease clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, clientv3.LeaseID, bool) {
+	for {
+		ch, leaseID, taken, err := a.tryReacquire(ctx, id, owner, oldLease)
+		switch {
+		case err == nil && !taken:
+			return ch, leaseID, true
+		case taken:
+			logx.Errorf("Error: etcd node ID %d has been taken by another node, ID generation stays disabled", id)
+			return nil, 0, false
+		}
+		logx.Errorf("Failed to re-acquire etcd node ID %d: %v", id, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, 0, false
+		case <-time.After(etcdReacquireInterval):
+		}
+	}
+}
+
+// 尝试一次重新占用：旧租约仍有效时继续续约，否则以新租约抢占节点ID键，taken 表示ID已被其他节点占用
+func (a *EtcdNodeIDAllocator) tryReacquire(ctx context.Context, id int64, owner string, oldLease clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, clientv3.LeaseID, bool, error) {
+	ttlCtx, cancel := context.WithTimeout(ctx, etcdOpTimeout)
+	ttl, err := a.cli.TimeToLive(ttlCtx, oldLease)
+	cancel()
+	leaseID := oldLease
+	if err != nil || ttl.TTL <= 0 {
+		grantCtx, cancel := context.WithTimeout(ctx, etcdOpTimeout)
+		lease, err := a.cli.Grant(grantCtx, a.ttl)
+		cancel()
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("failed to grant etcd lease: %w", err)
+		}
+		leaseID = lease.ID
+
+		ok, err := a.tryAcquire(ctx, id, owner, leaseID)
+		if err != nil || !ok {
+			a.revoke(leaseID)
+			return nil, 0, err == nil, err
+		}
+	}
+
+	ch, err := a.cli.KeepAlive(ctx, leaseID)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to keep etcd lease alive: %w", err)
+	}
+	return ch, leaseID, false, nil
+}
+
+// 撤销租约
+func (a *EtcdNodeIDAllocator) revoke(leaseID clientv3.LeaseID) {
+	revokeCtx, cancel := context.WithTimeout(context.Background(), etcdOpTimeout)
+	defer cancel()
+
+	if _, err := a.cli.Revoke(revokeCtx, leaseID); err != nil {
+		logx.Errorf("Warning: Failed to revoke etcd lease: %v", err)
+	}
+}
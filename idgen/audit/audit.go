@@ -0,0 +1,299 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/QuantumShiftX/golib/idgen"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 去重集合键前缀
+	defaultKeyPrefix = "idgen:audit:"
+	// 去重集合分片数，避免单个集合过大
+	defaultShards = 64
+	// 去重集合过期时间
+	defaultTTL = 24 * time.Hour
+	// 报告中最多保留的重复ID和序列号耗尽事件数
+	defaultMaxSamples = 1000
+)
+
+// Exhaustion 序列号耗尽事件：节点在同一毫秒内用完了序列号
+type Exhaustion struct {
+	NodeID int64     `json:"node_id"`
+	Time   time.Time `json:"time"`
+}
+
+// Report 审计报告
+type Report struct {
+	// 审计ID总数
+	Total int64 `json:"total"`
+	// 重复出现的次数（同一ID出现3次计2次）
+	DuplicateCount int64 `json:"duplicate_count"`
+	// 重复ID样本
+	Duplicates []int64 `json:"duplicates,omitempty"`
+	// 各节点ID数量分布
+	Nodes map[int64]int64 `json:"nodes"`
+	// 序列号耗尽事件数
+	ExhaustionCount int64 `json:"exhaustion_count"`
+	// 序列号耗尽事件样本
+	Exhaustions []Exhaustion `json:"exhaustions,omitempty"`
+	// 时间戳不合法（早于纪元或晚于当前时间）的ID数
+	Invalid int64 `json:"invalid"`
+	// ID时间范围
+	Earliest time.Time `json:"earliest"`
+	Latest   time.Time `json:"latest"`
+	// 审计耗时
+	Duration time.Duration `json:"duration"`
+}
+
+// NodeIDs 按节点ID排序返回
+func (r *Report) NodeIDs() []int64 {
+	nodes := make([]int64, 0, len(r.Nodes))
+	for n := range r.Nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	return nodes
+}
+
+// Auditor ID碰撞审计器
+type Auditor struct {
+	rdb         redis.UniversalClient
+	keyPrefix   string
+	shards      int64
+	ttl         time.Duration
+	maxSamples  int
+	decoder     func(id int64) idgen.IDParts
+	onDuplicate func(id int64)
+}
+
+// Option 审计器选项
+type Option func(a *Auditor)
+
+// WithKeyPrefix 设置Redis去重集合键前缀
+func WithKeyPrefix(prefix string) Option {
+	return func(a *Auditor) {
+		a.keyPrefix = prefix
+	}
+}
+
+// WithShards 设置Redis去重集合分片数
+func WithShards(shards int) Option {
+	return func(a *Auditor) {
+		if shards > 0 {
+			a.shards = int64(shards)
+		}
+	}
+}
+
+// WithTTL 设置Redis去重集合过期时间
+func WithTTL(ttl time.Duration) Option {
+	return func(a *Auditor) {
+		a.ttl = ttl
+	}
+}
+
+// WithMaxSamples 设置报告中保留的样本数
+func WithMaxSamples(n int) Option {
+	return func(a *Auditor) {
+		a.maxSamples = n
+	}
+}
+
+// WithDecoder 设置ID拆解函数，默认 idgen.Decompose
+func WithDecoder(decoder func(id int64) idgen.IDParts) Option {
+	return func(a *Auditor) {
+		a.decoder = decoder
+	}
+}
+
+// WithOnDuplicate 发现重复ID时回调
+func WithOnDuplicate(fn func(id int64)) Option {
+	return func(a *Auditor) {
+		a.onDuplicate = fn
+	}
+}
+
+// NewAuditor 创建审计器；rdb 不为空时使用Redis集合去重，可审计超出内存的ID量，否则在内存中去重
+func NewAuditor(rdb redis.UniversalClient, opts ...Option) *Auditor {
+	a := &Auditor{
+		rdb:        rdb,
+		keyPrefix:  defaultKeyPrefix,
+		shards:     defaultShards,
+		ttl:        defaultTTL,
+		maxSamples: defaultMaxSamples,
+		decoder:    idgen.Decompose,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Run 读取数据源中的全部ID并生成审计报告
+func (a *Auditor) Run(ctx context.Context, src Source) (*Report, error) {
+	start := time.Now()
+	report := &Report{Nodes: make(map[int64]int64)}
+
+	dedup := a.newDeduper(start)
+	defer dedup.cleanup(ctx)
+
+	for {
+		ids, err := src.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+
+		dup, err := dedup.add(ctx, ids)
+		if err != nil {
+			return report, err
+		}
+
+		for i, id := range ids {
+			report.Total++
+			if dup[i] {
+				report.DuplicateCount++
+				if len(report.Duplicates) < a.maxSamples {
+					report.Duplicates = append(report.Duplicates, id)
+				}
+				if a.onDuplicate != nil {
+					a.onDuplicate(id)
+				}
+			}
+			a.inspect(report, id, start)
+		}
+	}
+
+	report.Duration = time.Since(start)
+	if report.DuplicateCount > 0 {
+		logx.WithContext(ctx).Errorf("ID audit found %d duplicates in %d ids", report.DuplicateCount, report.Total)
+	}
+	return report, nil
+}
+
+// inspect 统计节点分布、序列号耗尽和时间范围
+func (a *Auditor) inspect(report *Report, id int64, now time.Time) {
+	parts := a.decoder(id)
+	if id <= 0 || parts.Time.After(now) {
+		report.Invalid++
+		return
+	}
+
+	report.Nodes[parts.NodeID]++
+	if parts.Sequence == idgen.MaxSequence {
+		report.ExhaustionCount++
+		if len(report.Exhaustions) < a.maxSamples {
+			report.Exhaustions = append(report.Exhaustions, Exhaustion{NodeID: parts.NodeID, Time: parts.Time})
+		}
+	}
+
+	if report.Earliest.IsZero() || parts.Time.Before(report.Earliest) {
+		report.Earliest = parts.Time
+	}
+	if parts.Time.After(report.Latest) {
+		report.Latest = parts.Time
+	}
+}
+
+// deduper ID去重器
+type deduper interface {
+	// add 添加一批ID，返回每个ID是否已出现过
+	add(ctx context.Context, ids []int64) ([]bool, error)
+	cleanup(ctx context.Context)
+}
+
+// newDeduper 根据是否配置Redis选择去重实现
+func (a *Auditor) newDeduper(start time.Time) deduper {
+	if a.rdb == nil {
+		return &memoryDeduper{seen: make(map[int64]struct{})}
+	}
+	return &redisDeduper{
+		rdb:    a.rdb,
+		prefix: fmt.Sprintf("%s%d:", a.keyPrefix, start.UnixNano()),
+		shards: a.shards,
+		ttl:    a.ttl,
+	}
+}
+
+// memoryDeduper 内存去重
+type memoryDeduper struct {
+	seen map[int64]struct{}
+}
+
+func (m *memoryDeduper) add(_ context.Context, ids []int64) ([]bool, error) {
+	dup := make([]bool, len(ids))
+	for i, id := range ids {
+		if _, ok := m.seen[id]; ok {
+			dup[i] = true
+			continue
+		}
+		m.seen[id] = struct{}{}
+	}
+	return dup, nil
+}
+
+func (m *memoryDeduper) cleanup(context.Context) {}
+
+// redisDeduper 基于分片Redis集合去重，每次审计使用独立的键
+type redisDeduper struct {
+	rdb    redis.UniversalClient
+	prefix string
+	shards int64
+	ttl    time.Duration
+	used   map[string]struct{}
+}
+
+func (r *redisDeduper) add(ctx context.Context, ids []int64) ([]bool, error) {
+	if r.used == nil {
+		r.used = make(map[string]struct{})
+	}
+
+	pipe := r.rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(ids))
+	for i, id := range ids {
+		key := r.key(id)
+		cmds[i] = pipe.SAdd(ctx, key, id)
+		if _, ok := r.used[key]; !ok {
+			r.used[key] = struct{}{}
+			pipe.Expire(ctx, key, r.ttl)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("dedup ids: %w", err)
+	}
+
+	// SADD 返回0表示集合中已存在
+	dup := make([]bool, len(ids))
+	for i, cmd := range cmds {
+		dup[i] = cmd.Val() == 0
+	}
+	return dup, nil
+}
+
+func (r *redisDeduper) cleanup(ctx context.Context) {
+	for key := range r.used {
+		if err := r.rdb.Del(ctx, key).Err(); err != nil {
+			logx.WithContext(ctx).Errorf("Failed to cleanup audit key %s: %v", key, err)
+		}
+	}
+}
+
+// key 按ID取模分片
+func (r *redisDeduper) key(id int64) string {
+	shard := id % r.shards
+	if shard < 0 {
+		shard = -shard
+	}
+	return r.prefix + strconv.FormatInt(shard, 10)
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/idgen"
+)
+
+func TestAuditorRun(t *testing.T) {
+	generator := idgen.NewIDGenX(nil, idgen.WithMachineIDProvider(idgen.StaticMachineIDProvider(1)), idgen.WithMonotonic(idgen.MonotonicNode))
+
+	ids := make([]int64, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		id, err := generator.GenId()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	ids = append(ids, ids[10], ids[20], ids[20])
+
+	report, err := NewAuditor(nil).Run(context.Background(), NewSliceSource(ids))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 5003 || report.DuplicateCount != 3 || len(report.Nodes) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	t.Log(report.Nodes, report.ExhaustionCount, report.Earliest, report.Latest)
+}
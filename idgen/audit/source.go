@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 默认每批读取的ID数
+const defaultBatchSize = 1000
+
+// Source 已发放ID的数据源，读取完毕时返回 io.EOF
+type Source interface {
+	Next(ctx context.Context) ([]int64, error)
+}
+
+// sliceSource 内存切片数据源
+type sliceSource struct {
+	ids   []int64
+	batch int
+}
+
+// NewSliceSource 基于内存切片的数据源
+func NewSliceSource(ids []int64) Source {
+	return &sliceSource{ids: ids, batch: defaultBatchSize}
+}
+
+// Next 读取下一批ID
+func (s *sliceSource) Next(_ context.Context) ([]int64, error) {
+	if len(s.ids) == 0 {
+		return nil, io.EOF
+	}
+	n := min(s.batch, len(s.ids))
+	batch := s.ids[:n]
+	s.ids = s.ids[n:]
+	return batch, nil
+}
+
+// redisStreamSource Redis Stream数据源
+type redisStreamSource struct {
+	rdb    redis.UniversalClient
+	stream string
+	field  string
+	start  string
+	batch  int64
+}
+
+// NewRedisStreamSource 基于Redis Stream的数据源，从消息的 field 字段读取ID
+// 适用于发号时将ID写入审计流的场景
+func NewRedisStreamSource(rdb redis.UniversalClient, stream, field string) Source {
+	return &redisStreamSource{
+		rdb:    rdb,
+		stream: stream,
+		field:  field,
+		start:  "-",
+		batch:  defaultBatchSize,
+	}
+}
+
+// Next 读取下一批ID
+func (s *redisStreamSource) Next(ctx context.Context) ([]int64, error) {
+	msgs, err := s.rdb.XRangeN(ctx, s.stream, s.start, "+", s.batch).Result()
+	if err != nil {
+		return nil, fmt.Errorf("read stream %s: %w", s.stream, err)
+	}
+	if len(msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	// 下一批从最后一条消息之后开始（排他区间）
+	s.start = "(" + msgs[len(msgs)-1].ID
+
+	ids := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		v, ok := msg.Values[s.field]
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %v in stream message %s: %w", v, msg.ID, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// sqlSource 数据库数据源
+type sqlSource struct {
+	db    *sql.DB
+	query string
+	args  []any
+	rows  *sql.Rows
+	batch int
+}
+
+// NewSQLSource 基于数据库查询的数据源，查询结果第一列为ID，如 SELECT id FROM orders
+func NewSQLSource(db *sql.DB, query string, args ...any) Source {
+	return &sqlSource{db: db, query: query, args: args, batch: defaultBatchSize}
+}
+
+// Next 读取下一批ID
+func (s *sqlSource) Next(ctx context.Context) ([]int64, error) {
+	if s.rows == nil {
+		rows, err := s.db.QueryContext(ctx, s.query, s.args...)
+		if err != nil {
+			return nil, fmt.Errorf("query ids: %w", err)
+		}
+		s.rows = rows
+	}
+
+	ids := make([]int64, 0, s.batch)
+	for len(ids) < s.batch && s.rows.Next() {
+		var id int64
+		if err := s.rows.Scan(&id); err != nil {
+			_ = s.rows.Close()
+			return nil, fmt.Errorf("scan id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		err := s.rows.Err()
+		_ = s.rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("iterate ids: %w", err)
+		}
+		return nil, io.EOF
+	}
+	return ids, nil
+}
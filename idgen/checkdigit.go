@@ -0,0 +1,101 @@
+package idgen
+
+// CheckDigitAlgorithm 校验位算法
+type CheckDigitAlgorithm int
+
+const (
+	// CheckDigitNone 不追加校验位
+	CheckDigitNone CheckDigitAlgorithm = iota
+	// CheckDigitLuhn Luhn算法（银行卡号同款），可检测单个数字错误和大部分相邻换位
+	CheckDigitLuhn
+	// CheckDigitDamm Damm算法，可检测全部单个数字错误和相邻换位
+	CheckDigitDamm
+)
+
+// Damm算法使用的10阶全反对称拟群表
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+// Compute 计算非负整数的校验位
+func (a CheckDigitAlgorithm) Compute(n int64) int {
+	switch a {
+	case CheckDigitLuhn:
+		return luhnCheckDigit(n)
+	case CheckDigitDamm:
+		return dammCheckDigit(n)
+	default:
+		return 0
+	}
+}
+
+// Append 在末尾追加校验位
+func (a CheckDigitAlgorithm) Append(n int64) int64 {
+	if a == CheckDigitNone {
+		return n
+	}
+	return n*10 + int64(a.Compute(n))
+}
+
+// Validate 校验末位是否为正确的校验位
+func (a CheckDigitAlgorithm) Validate(id int64) bool {
+	if id < 10 {
+		return false
+	}
+	if a == CheckDigitNone {
+		return true
+	}
+	return a.Compute(id/10) == int(id%10)
+}
+
+// ValidateCheckedID 按当前实例配置的校验位算法校验ID，可用于拦截用户输入错误
+// 未开启校验位时仅检查ID为正数
+func (x *IDGenX) ValidateCheckedID(id int64) bool {
+	if x.checkDigit == CheckDigitNone {
+		return id > 0
+	}
+	return x.checkDigit.Validate(id)
+}
+
+// Luhn校验位：从右往左，偶数位（追加校验位后）乘2
+func luhnCheckDigit(n int64) int {
+	sum := 0
+	double := true
+	for ; n > 0; n /= 10 {
+		d := int(n % 10)
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+// Damm校验位：从左往右查表
+func dammCheckDigit(n int64) int {
+	var buf [20]int
+	i := len(buf)
+	for ; n > 0; n /= 10 {
+		i--
+		buf[i] = int(n % 10)
+	}
+
+	interim := 0
+	for _, d := range buf[i:] {
+		interim = dammTable[interim][d]
+	}
+	return interim
+}
@@ -0,0 +1,45 @@
+package idgen
+
+import "testing"
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		alg  CheckDigitAlgorithm
+		n    int64
+		want int
+	}{
+		{CheckDigitLuhn, 7992739871, 3},
+		{CheckDigitDamm, 572, 4},
+	}
+
+	for _, tt := range tests {
+		if got := tt.alg.Compute(tt.n); got != tt.want {
+			t.Errorf("Compute(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+		id := tt.alg.Append(tt.n)
+		if !tt.alg.Validate(id) {
+			t.Errorf("Validate(%d) = false", id)
+		}
+		// 修改一位数字后校验应失败
+		if tt.alg.Validate(id + 10) {
+			t.Errorf("Validate(%d) = true after typo", id+10)
+		}
+	}
+}
+
+func TestGenIDWithCheckDigit(t *testing.T) {
+	x := NewIDGenX(nil, WithCheckDigit(CheckDigitDamm))
+
+	id, err := x.GenIDWithDigits(10)
+	if err != nil {
+		t.Fatalf("GenIDWithDigits error: %v", err)
+	}
+	t.Logf("checked id: %d", id)
+
+	if id < 1000000000 || id > 9999999999 {
+		t.Errorf("id %d is not 10 digits", id)
+	}
+	if !x.ValidateCheckedID(id) {
+		t.Errorf("ValidateCheckedID(%d) = false", id)
+	}
+}
@@ -0,0 +1,227 @@
+package idgen
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认时钟校验间隔
+	defaultClockCheckInterval = 30 * time.Second
+	// 默认允许的最大时钟偏差
+	defaultMaxClockDrift = time.Second
+	// NTP时间戳起点(1900年)与Unix时间戳起点的秒数差
+	ntpEpochOffset = 2208988800
+)
+
+// ErrClockDrift 系统时钟偏差超过阈值，拒绝生成ID
+var ErrClockDrift = errors.New("system clock drift exceeds threshold")
+
+// ClockSource 参考时钟源
+type ClockSource interface {
+	Now(ctx context.Context) (time.Time, error)
+}
+
+// ClockSourceFunc 函数适配器
+type ClockSourceFunc func(ctx context.Context) (time.Time, error)
+
+// Now 实现 ClockSource 接口
+func (f ClockSourceFunc) Now(ctx context.Context) (time.Time, error) {
+	return f(ctx)
+}
+
+// RedisClockSource 使用Redis TIME作为参考时钟
+func RedisClockSource(rdb redis.UniversalClient) ClockSource {
+	return ClockSourceFunc(func(ctx context.Context) (time.Time, error) {
+		return rdb.Time(ctx).Result()
+	})
+}
+
+// NTPClockSource 使用NTP服务器作为参考时钟，addr 如 pool.ntp.org 或 ntp.aliyun.com:123
+func NTPClockSource(addr string) ClockSource {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "123")
+	}
+
+	return ClockSourceFunc(func(ctx context.Context) (time.Time, error) {
+		return queryNTP(ctx, addr)
+	})
+}
+
+// ClockDriftEvent 时钟校验结果
+type ClockDriftEvent struct {
+	Drift     time.Duration // 本地时钟相对参考时钟的偏差，正数表示本地时钟偏快
+	Threshold time.Duration // 允许的最大偏差
+	Healthy   bool          // 偏差是否在阈值内
+	Err       error         // 查询参考时钟失败时的错误，此时不改变健康状态
+}
+
+// ClockGuardOption 时钟守护配置
+type ClockGuardOption func(*clockGuard)
+
+// WithClockCheckInterval 设置时钟校验间隔，默认30秒
+func WithClockCheckInterval(interval time.Duration) ClockGuardOption {
+	return func(g *clockGuard) {
+		if interval > 0 {
+			g.interval = interval
+		}
+	}
+}
+
+// WithMaxClockDrift 设置允许的最大时钟偏差，默认1秒
+func WithMaxClockDrift(threshold time.Duration) ClockGuardOption {
+	return func(g *clockGuard) {
+		if threshold > 0 {
+			g.threshold = threshold
+		}
+	}
+}
+
+// WithClockDriftHandler 设置校验结果回调，偏差超限、恢复或查询失败时调用
+func WithClockDriftHandler(handler func(ClockDriftEvent)) ClockGuardOption {
+	return func(g *clockGuard) {
+		g.handler = handler
+	}
+}
+
+// WithClockGuard 开启时钟守护：定期将系统时间与参考时钟比对，
+// 偏差超过阈值时拒绝生成ID（返回 ErrClockDrift），直到偏差恢复
+func WithClockGuard(source ClockSource, opts ...ClockGuardOption) Option {
+	return func(x *IDGenX) {
+		if source == nil {
+			return
+		}
+
+		g := &clockGuard{
+			source:    source,
+			interval:  defaultClockCheckInterval,
+			threshold: defaultMaxClockDrift,
+		}
+		for _, opt := range opts {
+			opt(g)
+		}
+		x.clockGuard = g
+	}
+}
+
+// clockGuard 时钟守护
+type clockGuard struct {
+	source    ClockSource
+	interval  time.Duration
+	threshold time.Duration
+	handler   func(ClockDriftEvent)
+	drifted   atomic.Bool
+	drift     atomic.Int64
+}
+
+// check 偏差超限时返回错误
+func (g *clockGuard) check() error {
+	if g.drifted.Load() {
+		return fmt.Errorf("%w: drift %v, threshold %v", ErrClockDrift, time.Duration(g.drift.Load()), g.threshold)
+	}
+	return nil
+}
+
+// run 定期校验时钟，直到ctx取消
+func (g *clockGuard) run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		g.probe(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe 执行一次时钟校验
+func (g *clockGuard) probe(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// 以请求往返的中点作为本地时间，抵消网络延迟
+	start := time.Now()
+	remote, err := g.source.Now(probeCtx)
+	end := time.Now()
+	if err != nil {
+		logx.Errorf("Warning: Failed to query reference clock: %v", err)
+		g.report(ClockDriftEvent{Threshold: g.threshold, Healthy: !g.drifted.Load(), Err: err})
+		return
+	}
+
+	local := start.Add(end.Sub(start) / 2)
+	drift := local.Sub(remote)
+	g.drift.Store(int64(drift))
+	clockDriftSeconds.Set(drift.Seconds())
+
+	exceeded := drift > g.threshold || drift < -g.threshold
+	changed := g.drifted.Swap(exceeded) != exceeded
+	if exceeded {
+		logx.Errorf("Clock drift %v exceeds threshold %v, refusing to generate IDs", drift, g.threshold)
+	} else if changed {
+		logx.Infof("Clock drift recovered: %v", drift)
+	}
+
+	if exceeded || changed {
+		g.report(ClockDriftEvent{Drift: drift, Threshold: g.threshold, Healthy: !exceeded})
+	}
+}
+
+// report 回调校验结果
+func (g *clockGuard) report(event ClockDriftEvent) {
+	if g.handler != nil {
+		g.handler(event)
+	}
+}
+
+// queryNTP 发送SNTP请求获取服务器时间
+func queryNTP(ctx context.Context, addr string) (time.Time, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// LI=0, VN=4, Mode=3(客户端)
+	req := make([]byte, 48)
+	req[0] = 0x23
+	if _, err := conn.Write(req); err != nil {
+		return time.Time{}, err
+	}
+
+	resp := make([]byte, 48)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n < 48 {
+		return time.Time{}, fmt.Errorf("short ntp response: %d bytes", n)
+	}
+
+	// 发送时间戳位于40-47字节：秒 + 秒的小数部分
+	secs := binary.BigEndian.Uint32(resp[40:44])
+	frac := binary.BigEndian.Uint32(resp[44:48])
+	if secs == 0 {
+		return time.Time{}, errors.New("invalid ntp transmit timestamp")
+	}
+
+	nanos := (int64(frac) * int64(time.Second)) >> 32
+	return time.Unix(int64(secs)-ntpEpochOffset, nanos), nil
+}
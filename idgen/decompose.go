@@ -0,0 +1,26 @@
+package idgen
+
+import "time"
+
+// MaxSequence 雪花ID每毫秒每节点的最大序列号
+const MaxSequence = sequenceMask
+
+// IDParts 雪花ID的组成部分
+type IDParts struct {
+	// 生成时间（毫秒精度）
+	Time time.Time
+	// 节点ID
+	NodeID int64
+	// 毫秒内序列号
+	Sequence int64
+}
+
+// Decompose 按雪花ID结构（时间戳 + 10位节点ID + 12位序列号）拆解ID
+// 适用于单调模式和本地生成的ID；全局单调模式下节点ID和序列号位共同组成全局序列号
+func Decompose(id int64) IDParts {
+	return IDParts{
+		Time:     time.UnixMilli((id >> timestampLeftShift) + startTime),
+		NodeID:   (id >> nodeIDLeftShift) & nodeIDMask,
+		Sequence: id & sequenceMask,
+	}
+}
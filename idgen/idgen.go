@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
 	"net"
 	"os"
 	"strconv"
@@ -18,15 +18,28 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+const (
+	// MinDigits GenIDWithDigits 允许的最小位数，更小的位数会被替换为默认位数
+	MinDigits = 8
+	// MaxDigits GenIDWithDigits 允许的最大位数
+	MaxDigits = 16
+)
+
 const (
 	// 邀请码字符集，去掉了容易混淆的字符
 	inviteCodeChars = "1234567890ABCDEFGHIJKLMNPQRSTUVWXYZ"
-	// 邀请码长度 最大长度
+	// 邀请码默认长度
 	inviteCodeMaxLength = 8
+	// 邀请码允许的最小长度
+	inviteCodeMinLength = 4
+	// 邀请码允许的最大长度
+	inviteCodeLimitLength = 32
 	// 最小允许的ID位数
-	minAllowedDigits = 8
+	minAllowedDigits = MinDigits
 	// 最大允许的ID位数
-	maxAllowedDigits = 16
+	maxAllowedDigits = MaxDigits
+	// 兼容层允许的最小ID位数，见 GenCompactIDWithDigits
+	compactMinDigits = 6
 	// 默认生成ID的位数
 	defaultDigits = 10
 	// 节点ID位数
@@ -53,6 +66,8 @@ const (
 	segmentPreloadThreshold = 0.1
 	// 默认步长
 	segmentSize = 1000
+	// 默认关闭超时时间
+	defaultShutdownTimeout = 10 * time.Second
 )
 
 // ID段结构，用于本地缓存一段Redis分配的ID
@@ -63,28 +78,25 @@ type IDSegment struct {
 }
 
 var (
-	flake      *sonyflake.Sonyflake
-	flakeOnce  sync.Once // 用于确保Flake只初始化一次
-	randSource = rand.NewSource(time.Now().UnixNano())
-	randMutex  sync.Mutex // 保护随机数生成的锁
+	flake     *sonyflake.Sonyflake
+	flakeOnce sync.Once // 用于确保Flake只初始化一次
 	// 初始化错误
 	initError error
-	// 上次生成的时间戳（毫秒）
-	lastTimestamp int64
-	// 序列号计数器 - 原子操作确保线程安全
-	sequence int64
+	// 雪花ID状态：上次时间戳（毫秒）<< sequenceBits | 序列号
+	snowflakeState atomic.Int64
 	// 节点ID
 	nodeID int64
-	// 时钟回拨检测锁
-	clockBackwardLock sync.Mutex
-	// 数字ID生成锁 - 为每个位数创建独立的锁
-	digitLocks = make(map[int]*sync.Mutex)
-	// 各位数ID的计数器映射 - 为每个位数创建独立的计数器
-	digitCounters = make(map[int]*uint64)
-	// 数字计数器锁
+	// 各位数ID的最小值，如 8 位为 10000000
+	digitMinValues = func() (values [maxAllowedDigits + 1]int64) {
+		v := int64(1)
+		for i := 1; i <= maxAllowedDigits; i++ {
+			values[i] = v
+			v *= 10
+		}
+		return values
+	}()
+	// 服务器本地段锁
 	counterMutex sync.RWMutex
-	// 用于确保计数器初始化的锁
-	counterInitLock sync.Mutex
 	// 系统启动时间，用于生成唯一标识
 	startTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() / 1000000
 	// 上下文
@@ -100,53 +112,156 @@ var (
 	// 全局上下文和取消函数
 	globalCtx       context.Context
 	globalCtxCancel context.CancelFunc
+	// 后台任务（节点ID续期、号段预加载、时钟守护）
+	backgroundTasks taskGroup
 )
 
 type IDGenX struct {
-	rdb redis.UniversalClient //redis
-	ctx context.Context       // 上下文，用于控制后台任务
+	rdb               redis.UniversalClient //redis
+	ctx               context.Context       // 上下文，用于控制后台任务
+	machineIDProvider MachineIDProvider     // 机器ID提供者
+	nodeIDAllocator   NodeIDAllocator       // 节点ID分配器，为空时使用Redis分配
+	segmentAllocator  SegmentAllocator      // 号段分配器，设置后优先于Redis号段
+	segmentBuffers    sync.Map              // 各业务标识的双缓冲号段
+	monotonic         MonotonicMode         // 单调递增模式
+	inviteRegistry    bool                  // 是否登记邀请码
+	inviteRegistryTTL time.Duration         // 邀请码登记有效期，0为永久
+	inviteCodeLength  int                   // 邀请码长度
+	inviteCodeChars   string                // 邀请码字符集
+	inviteCaseFold    bool                  // 邀请码校验是否忽略大小写
+	checkDigit        CheckDigitAlgorithm   // 数字ID校验位算法
+	clockGuard        *clockGuard           // 时钟守护，偏差超限时拒绝生成ID
+	nodeIDStore       NodeIDStore           // 节点ID持久化存储
+	namespace         string                // 业务命名空间，隔离号段键、计数器和指标
+	root              *IDGenX               // 命名空间子生成器所属的根生成器
+	namespaces        sync.Map              // 已创建的命名空间子生成器
+	shadow            *shadowVerifier       // 新布局影子校验，为空时不开启
+	redisCluster      bool                  // Redis Cluster 模式，号段和节点ID的键带 hash tag
 }
 
-func NewIDGenX(rdb redis.UniversalClient) *IDGenX {
+func NewIDGenX(rdb redis.UniversalClient, opts ...Option) *IDGenX {
 
 	// 创建一个可取消的上下文
 	if globalCtx == nil {
 		globalCtx, globalCtxCancel = context.WithCancel(context.Background())
 	}
 
-	return &IDGenX{rdb: rdb, ctx: globalCtx}
+	x := &IDGenX{
+		rdb:              rdb,
+		ctx:              globalCtx,
+		inviteCodeLength: inviteCodeMaxLength,
+		inviteCodeChars:  inviteCodeChars,
+		inviteCaseFold:   true,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(x)
+	}
+
+	// 未指定机器ID提供者时使用默认探测链
+	if x.machineIDProvider == nil {
+		x.machineIDProvider = defaultMachineIDProvider
+	}
+
+	// 未指定节点ID存储时使用默认文件
+	if x.nodeIDStore == nil {
+		x.nodeIDStore = NewFileNodeIDStore(nodeIDPersistFile)
+	}
+
+	// 启动时钟守护
+	if x.clockGuard != nil {
+		x.goBackground(func() { x.clockGuard.run(x.ctx) })
+	}
+
+	return x
 }
 
-// 添加一个关闭方法，用于优雅关闭
+// Shutdown 优雅关闭，等待后台任务退出，最长等待10秒，超时时记录日志
 func (x *IDGenX) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	if err := x.ShutdownContext(ctx); err != nil {
+		logx.Error(err)
+	}
+}
+
+// ShutdownContext 优雅关闭：先持久化节点ID，再取消后台任务并等待其退出，
+// ctx 超时前后台任务仍未退出时返回错误
+func (x *IDGenX) ShutdownContext(ctx context.Context) error {
+	// 持久化当前节点ID，下次启动优先复用
+	if id := nodeID; id > 0 && x.nodeIDStore != nil {
+		if err := x.nodeIDStore.Save(ctx, id); err != nil {
+			logx.Errorf("Warning: Failed to persist nodeID %d on shutdown: %v", id, err)
+		}
+	}
+
 	if globalCtxCancel != nil {
 		globalCtxCancel()
 	}
+
+	if err := backgroundTasks.wait(ctx); err != nil {
+		return fmt.Errorf("idgen shutdown: background tasks not finished: %w", err)
+	}
+	return nil
 }
 
-// 初始化各位数的计数器和锁
-func initDigitCounters() {
-	counterInitLock.Lock()
-	defer counterInitLock.Unlock()
+// goBackground 启动受 Shutdown 管理的后台任务
+func (x *IDGenX) goBackground(fn func()) {
+	backgroundTasks.goTask(fn)
+}
 
-	for i := minAllowedDigits; i <= maxAllowedDigits; i++ {
-		if _, exists := digitCounters[i]; !exists {
-			var counter uint64 = 0
-			digitCounters[i] = &counter
-			digitLocks[i] = &sync.Mutex{}
-		}
+// taskGroup 可等待的后台任务集合，开始等待后新启动的任务不再计入，
+// 避免 WaitGroup 在等待期间从0开始 Add 的竞态
+type taskGroup struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closing bool
+}
+
+// goTask 启动后台任务，已开始关闭时任务不计入等待（上下文已取消，任务会很快退出）
+func (g *taskGroup) goTask(fn func()) {
+	g.mu.Lock()
+	if g.closing {
+		g.mu.Unlock()
+		go fn()
+		return
+	}
+	g.wg.Add(1)
+	g.mu.Unlock()
+
+	go func() {
+		defer g.wg.Done()
+		fn()
+	}()
+}
+
+// wait 等待已计入的任务退出，ctx 结束时返回 ctx 的错误
+func (g *taskGroup) wait(ctx context.Context) error {
+	g.mu.Lock()
+	g.closing = true
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // 安全初始化函数
 func (x *IDGenX) initFlake() {
 	flakeOnce.Do(func() {
-		// 初始化计数器和锁
-		initDigitCounters()
-
 		// 创建Sonyflake实例
 		flakeSettings := sonyflake.Settings{
-			MachineID: getMachineID,
+			MachineID: x.GetMachineID,
 			// 设置起始时间为2023年1月1日
 			StartTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 		}
@@ -159,35 +274,56 @@ func (x *IDGenX) initFlake() {
 		}
 
 		// 初始化上次时间戳
-		lastTimestamp = time.Now().UnixNano() / 1000000 // 转换为毫秒
+		snowflakeState.Store(timeGen() << sequenceBits)
 
 		// 获取并设置节点ID
-		machineID, err := getMachineID()
+		machineID, err := x.GetMachineID()
 		if err != nil {
 			logx.Errorf("Warning: Failed to get machine ID: %v, using random value", err)
 			// 使用随机值作为备用
-			randMutex.Lock()
-			machineID = uint16(rand.New(randSource).Intn(1024))
-			randMutex.Unlock()
+			machineID = uint16(rand.IntN(1024))
 		}
 
-		// 首先尝试从本地文件加载之前保存的节点ID
-		savedNodeID, err := loadNodeIDFromFile()
+		// 首先尝试从存储中加载之前保存的节点ID
+		savedNodeID, err := x.nodeIDStore.Load(x.ctx)
+
+		// 使用自定义分配器（如etcd）分配节点ID
+		if x.nodeIDAllocator != nil {
+			preferredID := int64(machineID)
+			if err == nil && savedNodeID > 0 {
+				preferredID = savedNodeID
+			}
+
+			allocatedNodeID, err := x.nodeIDAllocator.Allocate(x.ctx, preferredID)
+			if err == nil {
+				nodeID = allocatedNodeID
+				setNodeIDOrigin(NodeIDSourceAllocator, false)
+				logx.Infof("Allocated nodeID from allocator: %d", nodeID)
+				x.saveNodeID(nodeID)
+			} else {
+				nodeID = int64(machineID) & nodeIDMask
+				setNodeIDOrigin(NodeIDSourceLocal, true)
+				logx.Errorf("Failed to allocate nodeID from allocator: %v, using local nodeID: %d", err, nodeID)
+			}
+			return
+		}
 		if err == nil && savedNodeID > 0 {
 			// 验证此节点ID是否仍然有效
 			if x.rdb != nil {
 				valid, err := x.isNodeIDValid(savedNodeID)
 				if err == nil && valid {
 					nodeID = savedNodeID
-					logx.Infof("Restored nodeID from local file: %d", nodeID)
+					setNodeIDOrigin(NodeIDSourceRedis, false)
+					logx.Infof("Restored nodeID from store: %d", nodeID)
 
 					// 更新Redis中的节点ID过期时间
-					go x.refreshNodeIDExpiry(savedNodeID)
+					x.goBackground(func() { x.refreshNodeIDExpiry(savedNodeID) })
 					return
 				}
 			} else {
 				// 如果Redis不可用，直接使用保存的节点ID
 				nodeID = savedNodeID
+				setNodeIDOrigin(NodeIDSourceStore, false)
 				logx.Infof("Redis unavailable, using saved nodeID: %d", nodeID)
 				return
 			}
@@ -198,38 +334,33 @@ func (x *IDGenX) initFlake() {
 			allocatedNodeID, err := x.allocateNodeIDFromRedis(int64(machineID))
 			if err == nil {
 				nodeID = allocatedNodeID
+				setNodeIDOrigin(NodeIDSourceRedis, false)
 				logx.Infof("Allocated nodeID from Redis: %d", nodeID)
 
-				// 保存分配的节点ID到本地文件
-				saveNodeIDToFile(nodeID)
+				// 保存分配的节点ID
+				x.saveNodeID(nodeID)
 
 				// 启动一个goroutine定期刷新节点ID的过期时间
-				go x.startNodeIDRefreshTask()
+				x.goBackground(x.startNodeIDRefreshTask)
 			} else {
 				nodeID = int64(machineID) & nodeIDMask
-				logx.Infof("Failed to allocate nodeID from Redis: %v, using local nodeID: %d", err, nodeID)
+				setNodeIDOrigin(NodeIDSourceLocal, true)
+				markRedisError(err)
+				logx.Errorf("Failed to allocate nodeID from Redis: %v, using local nodeID: %d", err, nodeID)
 			}
 		} else {
 			nodeID = int64(machineID) & nodeIDMask
+			setNodeIDOrigin(NodeIDSourceLocal, false)
 			logx.Infof("Redis unavailable, using local nodeID: %d", nodeID)
 		}
 	})
 }
 
-// 从本地文件加载节点ID
-func loadNodeIDFromFile() (int64, error) {
-	data, err := os.ReadFile(nodeIDPersistFile)
-	if err != nil {
-		return 0, err
+// 持久化节点ID，失败时仅记录日志
+func (x *IDGenX) saveNodeID(id int64) {
+	if err := x.nodeIDStore.Save(x.ctx, id); err != nil {
+		logx.Errorf("Warning: Failed to persist nodeID %d: %v", id, err)
 	}
-
-	nodeIDStr := strings.TrimSpace(string(data))
-	return strconv.ParseInt(nodeIDStr, 10, 64)
-}
-
-// 保存节点ID到本地文件
-func saveNodeIDToFile(id int64) error {
-	return os.WriteFile(nodeIDPersistFile, []byte(fmt.Sprintf("%d", id)), 0644)
 }
 
 // 检查节点ID在Redis中是否仍然有效
@@ -238,13 +369,18 @@ func (x *IDGenX) isNodeIDValid(id int64) (bool, error) {
 		return false, errors.New("Redis client not initialized")
 	}
 
-	nodeKey := fmt.Sprintf("%snode:%d", redisKeyPrefix, id)
-	exists, err := x.rdb.Exists(ctx, nodeKey).Result()
-	if err != nil {
-		return false, err
+	// 集群模式下同时持有未带 hash tag 的旧键，两个键都存在才有效
+	keys := x.redisNodeKeys(id)
+	var exists int64
+	for _, key := range keys {
+		n, err := x.rdb.Exists(ctx, key).Result()
+		if err != nil {
+			return false, err
+		}
+		exists += n
 	}
 
-	return exists > 0, nil
+	return exists == int64(len(keys)), nil
 }
 
 // 刷新节点ID的过期时间
@@ -257,15 +393,24 @@ func (x *IDGenX) refreshNodeIDExpiry(id int64) {
 	redisCtx, cancel := context.WithTimeout(x.ctx, 5*time.Second)
 	defer cancel()
 
-	nodeKey := fmt.Sprintf("%snode:%d", redisKeyPrefix, id)
-
 	// 使用新的过期时间
 	expiry := time.Duration(nodeIDExpiryDays) * 24 * time.Hour
 
+	for _, nodeKey := range x.redisNodeKeys(id) {
+		x.refreshNodeKey(redisCtx, nodeKey, expiry)
+	}
+}
+
+// 刷新单个节点ID键的过期时间，键已不存在时重新设置
+func (x *IDGenX) refreshNodeKey(redisCtx context.Context, nodeKey string, expiry time.Duration) {
 	// 尝试更新过期时间，使用带超时的上下文
-	_, err := x.rdb.Expire(redisCtx, nodeKey, expiry).Result()
+	ok, err := x.rdb.Expire(redisCtx, nodeKey, expiry).Result()
+	if err == nil && !ok {
+		err = redis.Nil
+	}
 	if err != nil {
 		logx.Errorf("Warning: Failed to refresh nodeID expiry: %v", err)
+		nodeIDRefreshFailures.Inc()
 
 		// 检查错误是否是因为上下文取消导致的
 		if redisCtx.Err() != nil {
@@ -327,29 +472,93 @@ func (x *IDGenX) allocateNodeIDFromRedis(preferredID int64) (int64, error) {
 	expiry := time.Duration(nodeIDExpiryDays) * 24 * time.Hour
 
 	// 尝试设置首选节点ID
-	nodeKey := fmt.Sprintf("%snode:%d", redisKeyPrefix, preferredID)
-	success, err := x.rdb.SetNX(ctx, nodeKey, 1, expiry).Result()
-	if err == nil && success {
+	if x.claimNodeID(preferredID, expiry) {
 		// 成功设置首选ID
 		return preferredID, nil
 	}
 
-	// 如果首选ID已被占用，尝试寻找可用ID
-	for i := 0; i < 1024; i++ {
-		nodeKey := fmt.Sprintf("%snode:%d", redisKeyPrefix, i)
-		success, err := x.rdb.SetNX(ctx, nodeKey, 1, expiry).Result()
-		if err == nil && success {
-			return int64(i), nil
+	// 如果首选ID已被占用，通过管道查出空闲ID，只对空闲ID尝试占用
+	free, err := x.freeNodeIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range free {
+		if x.claimNodeID(id, expiry) {
+			return id, nil
 		}
 	}
 
 	return 0, errors.New("no available node IDs in Redis")
 }
 
+// 占用节点ID，集群模式下同时占用未带 hash tag 的旧键，滚动切换期间旧版本节点不会分配到同一ID
+func (x *IDGenX) claimNodeID(id int64, expiry time.Duration) bool {
+	keys := x.redisNodeKeys(id)
+	for i, key := range keys {
+		success, err := x.rdb.SetNX(ctx, key, 1, expiry).Result()
+		if err == nil && success {
+			continue
+		}
+		// 旧键已被占用，释放已占用的新键
+		if i > 0 {
+			x.rdb.Del(ctx, keys[:i]...)
+		}
+		return false
+	}
+	return true
+}
+
+// 通过一次管道查询所有未被占用的节点ID，集群模式下同时检查未带 hash tag 的旧键
+func (x *IDGenX) freeNodeIDs(ctx context.Context) ([]int64, error) {
+	cmds := make([][]*redis.IntCmd, 1024)
+	_, err := x.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i := range cmds {
+			key := x.redisNodeKey(int64(i))
+			cmds[i] = append(cmds[i], pipe.Exists(ctx, key))
+			if legacy := untagRedisKey(key); legacy != key {
+				cmds[i] = append(cmds[i], pipe.Exists(ctx, legacy))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var free []int64
+	for i, group := range cmds {
+		used := false
+		for _, cmd := range group {
+			used = used || cmd.Val() > 0
+		}
+		if !used {
+			free = append(free, int64(i))
+		}
+	}
+	return free, nil
+}
+
 // 确保初始化函数被调用
 func (x *IDGenX) ensureInit() error {
 	x.initFlake()
-	return initError
+	if initError != nil {
+		return initError
+	}
+
+	// 分配器分配的节点ID失效时拒绝生成
+	if origin := currentNodeIDOrigin.Load(); origin != nil && origin.source == NodeIDSourceAllocator {
+		if checker, ok := x.nodeIDAllocator.(NodeIDLeaseChecker); ok {
+			if err := checker.CheckNodeID(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 时钟偏差超限时拒绝生成
+	if x.clockGuard != nil {
+		return x.clockGuard.check()
+	}
+	return nil
 }
 
 // 获取Redis分布式锁
@@ -359,7 +568,7 @@ func (x *IDGenX) getRedisLock(key string, expiry time.Duration) (bool, string, e
 	}
 
 	// 生成唯一值用于锁识别
-	value := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int63())
+	value := fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Int64())
 
 	// 尝试设置锁
 	success, err := x.rdb.SetNX(ctx, key, value, expiry).Result()
@@ -417,7 +626,7 @@ func (x *IDGenX) getUniqueIDFromRedisSegment(idType string, digits int) (int64,
 	maxValue := minValue*10 - 1
 
 	// 段计数器的键
-	segmentKey := fmt.Sprintf("%s%s:%d", redisKeyPrefix, idType, digits)
+	segmentKey := x.redisSegmentKey(idType, digits)
 
 	// 获取当前服务器的本地段
 	counterMutex.Lock()
@@ -435,22 +644,15 @@ func (x *IDGenX) getUniqueIDFromRedisSegment(idType string, digits int) (int64,
 			logx.Infof("Using preloaded segment for %s", segmentKey)
 		} else {
 			// 如果没有预加载的段，从Redis获取新段
-			nextVal, err := x.rdb.Incr(ctx, segmentKey).Result()
+			newSegment, err := x.allocRedisSegment(ctx, segmentKey)
 			if err != nil {
 				counterMutex.Unlock()
 				return 0, fmt.Errorf("failed to get new segment from Redis: %v", err)
 			}
 
-			// 计算新段的范围
-			segmentStart := (nextVal-1)*segmentSize + 1
-
 			// 保存新段到本地
-			serverSegments[segmentKey] = &IDSegment{
-				current: 0,
-				max:     segmentSize,
-				base:    segmentStart,
-			}
-			segment = serverSegments[segmentKey]
+			serverSegments[segmentKey] = newSegment
+			segment = newSegment
 		}
 	} else {
 		// 检查是否需要预加载下一个段
@@ -463,7 +665,7 @@ func (x *IDGenX) getUniqueIDFromRedisSegment(idType string, digits int) (int64,
 
 			if !exists || !loading {
 				// 异步预加载下一个段
-				go x.preloadNextSegment(idType, digits)
+				x.goBackground(func() { x.preloadNextSegment(idType, digits) })
 			}
 		}
 	}
@@ -496,7 +698,7 @@ func (x *IDGenX) getUniqueIDFromRedisSegment(idType string, digits int) (int64,
 
 // 预加载下一个段的函数
 func (x *IDGenX) preloadNextSegment(idType string, digits int) {
-	segmentKey := fmt.Sprintf("%s%s:%d", redisKeyPrefix, idType, digits)
+	segmentKey := x.redisSegmentKey(idType, digits)
 
 	// 使用锁保护加载状态
 	segmentLoadingLock.Lock()
@@ -522,52 +724,41 @@ func (x *IDGenX) preloadNextSegment(idType string, digits int) {
 	defer cancel()
 
 	// 从Redis获取新段
-	nextVal, err := x.rdb.Incr(ctx, segmentKey).Result()
+	start := time.Now()
+	newSegment, err := x.allocRedisSegment(ctx, segmentKey)
+	recordSegmentPreload("redis", time.Since(start).Seconds(), err)
 	if err != nil {
 		logx.Errorf("Warning: Failed to preload next segment from Redis: %v", err)
 		return
 	}
 
-	// 计算新段的范围
-	segmentStart := (nextVal-1)*segmentSize + 1
-
-	// 创建新的段
-	newSegment := &IDSegment{
-		current: 0,
-		max:     segmentSize,
-		base:    segmentStart,
-	}
+	storePreloadedSegment(segmentKey, newSegment)
 
-	// 将新段添加到待用段映射中
-	counterMutex.Lock()
-	// 检查当前是否有激活的段
-	if segment, ok := serverSegments[segmentKey]; ok {
-		// 如果当前段已经存在但差不多用完了，用新段替换它
-		if segment.current >= segment.max {
-			serverSegments[segmentKey] = newSegment
-		} else {
-			// 否则，将新段存储在另一个映射中，以便后续使用
-			if pendingSegments == nil {
-				pendingSegments = make(map[string]*IDSegment)
-			}
-			pendingSegments[segmentKey] = newSegment
-		}
-	} else {
-		// 如果当前没有激活的段，直接使用新段
-		serverSegments[segmentKey] = newSegment
-	}
-	counterMutex.Unlock()
-
-	logx.Infof("Successfully preloaded next segment for %s, starting at %d", segmentKey, segmentStart)
+	logx.Infof("Successfully preloaded next segment for %s, starting at %d", segmentKey, newSegment.base)
 }
 
 // GenId 生成一个唯一的雪花ID (原始长整型)
 func (x *IDGenX) GenId() (int64, error) {
+	id, err := x.genId()
+	if err == nil {
+		x.recordGenerated("snowflake", 0)
+		x.verifyShadow("snowflake", id, 0)
+	}
+	return id, err
+}
+
+// 生成雪花ID
+func (x *IDGenX) genId() (int64, error) {
 	// 确保已初始化
 	if err := x.ensureInit(); err != nil {
 		return 0, err
 	}
 
+	// 单调模式不混入Redis序列号，保证ID有序
+	if x.monotonic != MonotonicOff {
+		return x.genMonotonicID()
+	}
+
 	// 双重检查flake是否为nil
 	if flake == nil {
 		return 0, errors.New("sonyflake instance is nil")
@@ -576,7 +767,7 @@ func (x *IDGenX) GenId() (int64, error) {
 	// 尝试使用Redis生成全局唯一ID
 	if x.rdb != nil {
 		// 从Redis获取序列号
-		seq, err := x.getRedisSequence("snowflake")
+		seq, err := x.getRedisSequence(x.namespaced("snowflake"))
 		if err == nil {
 			// 生成雪花ID
 			id, err := flake.NextID()
@@ -585,11 +776,13 @@ func (x *IDGenX) GenId() (int64, error) {
 			}
 
 			// 组合Redis序列号和雪花ID
+			markRedisOK()
 			finalID := int64(id) ^ (seq << 10)
 			return finalID, nil
 		}
 		// 如果Redis操作失败，回退到本地方式
 		logx.Infof("Warning: Failed to get sequence from Redis: %v, falling back to local generation", err)
+		x.recordRedisFallback("snowflake", err)
 	}
 
 	id, err := flake.NextID()
@@ -614,55 +807,38 @@ func timeGen() int64 {
 	return time.Now().UnixNano() / 1000000
 }
 
-// 处理时钟回拨
-func handleClockBackward(lastTs int64, currentTs int64) int64 {
-	clockBackwardLock.Lock()
-	defer clockBackwardLock.Unlock()
-
-	if currentTs < lastTs {
-		logx.Infof("Clock moved backwards. Waiting until %d.", lastTs)
-		// 等待一段时间
-		time.Sleep(time.Duration(lastTs-currentTs+clockBackwardWaitMs) * time.Millisecond)
-		return timeGen()
-	}
-
-	return currentTs
-}
-
 // 生成雪花算法ID
+// 时间戳和序列号打包在同一个原子变量中，通过CAS推进，热路径无锁
 func generateSnowflakeID() (int64, error) {
-	var timestamp int64
-	var seq int64
+	for {
+		old := snowflakeState.Load()
+		lastTs := old >> sequenceBits
+		lastSeq := old & sequenceMask
+
+		timestamp := timeGen()
+		seq := int64(0)
 
-	// 获取当前时间戳（毫秒）
-	timestamp = timeGen()
+		if timestamp <= lastTs {
+			// 同一毫秒或时钟回拨：沿用上次时间戳，递增序列号，保证单调
+			if timestamp < lastTs {
+				clockBackwardTotal.Inc()
+			}
 
-	// 处理时钟回拨
-	timestamp = handleClockBackward(atomic.LoadInt64(&lastTimestamp), timestamp)
+			timestamp = lastTs
+			seq = lastSeq + 1
 
-	// 如果是同一毫秒内
-	if timestamp == atomic.LoadInt64(&lastTimestamp) {
-		// 序列号递增
-		seq = (atomic.AddInt64(&sequence, 1) & sequenceMask)
+			// 序列号用完，等待下一毫秒后重试
+			if seq > sequenceMask {
+				tilNextMillis(lastTs)
+				continue
+			}
+		}
 
-		// 如果序列号用完，等待下一毫秒
-		if seq == 0 {
-			timestamp = tilNextMillis(atomic.LoadInt64(&lastTimestamp))
+		if snowflakeState.CompareAndSwap(old, timestamp<<sequenceBits|seq) {
+			// ID结构: 时间戳部分 + 节点ID部分 + 序列号部分
+			return ((timestamp - startTime) << timestampLeftShift) | (nodeID << nodeIDLeftShift) | seq, nil
 		}
-	} else {
-		// 不同毫秒，序列号重置
-		atomic.StoreInt64(&sequence, 0)
-		seq = 0
 	}
-
-	// 保存最后的时间戳
-	atomic.StoreInt64(&lastTimestamp, timestamp)
-
-	// 组合成最终的ID
-	// ID结构: 时间戳部分 + 节点ID部分 + 序列号部分
-	id := ((timestamp - startTime) << timestampLeftShift) | (nodeID << nodeIDLeftShift) | seq
-
-	return id, nil
 }
 
 // GenUserID 生成一个10位的唯一用户ID
@@ -676,112 +852,90 @@ func (x *IDGenX) GenIDWithDigits(digits int) (int64, error) {
 	if digits < minAllowedDigits || digits > maxAllowedDigits {
 		digits = defaultDigits // 默认使用10位
 	}
+	return x.genDigitsID(digits)
+}
+
+// GenCompactIDWithDigits 生成6-7位ID，超出范围时为6位，供 uniqueid 兼容层使用
+// 与 GenIDWithDigits 使用相同的号段分配器、Redis分段和本地回退，唯一性保证相同；
+// 6位和7位的容量只有90万和900万，用尽后会重复，新代码请使用8位以上
+func (x *IDGenX) GenCompactIDWithDigits(digits int) (int64, error) {
+	if digits < compactMinDigits || digits >= minAllowedDigits {
+		digits = compactMinDigits
+	}
+	return x.genDigitsID(digits)
+}
 
+// genDigitsID 生成指定位数的ID并记录指标，开启校验位时末位为校验位
+func (x *IDGenX) genDigitsID(digits int) (int64, error) {
+	// 开启校验位时，前 digits-1 位为ID，末位为校验位
+	if x.checkDigit != CheckDigitNone {
+		id, err := x.genIDWithDigits(digits - 1)
+		if err != nil {
+			return 0, err
+		}
+		x.recordGenerated("digits", digits)
+		id = x.checkDigit.Append(id)
+		x.verifyShadow("digits", id, digits)
+		return id, nil
+	}
+
+	id, err := x.genIDWithDigits(digits)
+	if err == nil {
+		x.recordGenerated("digits", digits)
+		x.verifyShadow("digits", id, digits)
+	}
+	return id, err
+}
+
+// 生成指定位数的唯一ID
+func (x *IDGenX) genIDWithDigits(digits int) (int64, error) {
 	// 确保已初始化
 	if err := x.ensureInit(); err != nil {
 		return 0, err
 	}
 
+	// 如果设置了号段分配器（如数据库），优先使用
+	if x.segmentAllocator != nil {
+		id, err := x.getUniqueIDFromSegmentAllocator(x.digitsBizTag(digits), digits)
+		if err == nil {
+			return id, nil
+		}
+
+		logx.Errorf("Warning: Segment allocation failed: %v, falling back", err)
+	}
+
 	// 如果Redis可用，优先使用Redis段分配算法
 	if x.rdb != nil {
 		// 尝试从Redis段获取唯一ID
-		id, err := x.getUniqueIDFromRedisSegment(fmt.Sprintf("digit:%d", digits), digits)
+		id, err := x.getUniqueIDFromRedisSegment(x.digitsBizTag(digits), digits)
 		if err == nil {
+			markRedisOK()
 			return id, nil
 		}
 
 		// 如果Redis操作失败，记录日志并回退到本地生成
 		logx.Errorf("Warning: Redis segment allocation failed: %v, falling back to local ID generation", err)
+		x.recordRedisFallback("digits", err)
 	}
 
-	// 获取该位数对应的锁和计数器
-	counterMutex.RLock()
-	lock, lockExists := digitLocks[digits]
-	counter, counterExists := digitCounters[digits]
-	counterMutex.RUnlock()
-
-	if !lockExists || !counterExists {
-		counterMutex.Lock()
-		var newCounter uint64 = 0
-		digitCounters[digits] = &newCounter
-		digitLocks[digits] = &sync.Mutex{}
-		counter = &newCounter
-		lock = digitLocks[digits]
-		counterMutex.Unlock()
-	}
-
-	// 加锁确保该位数ID生成的原子性
-	lock.Lock()
-	defer lock.Unlock()
-
-	// 生成雪花ID
+	// 本地生成：无锁雪花ID映射到指定位数范围
 	snowflakeID, err := generateSnowflakeID()
 	if err != nil {
 		return 0, fmt.Errorf("failed to generate snowflake ID: %v", err)
 	}
 
-	// 增加计数器值并获取
-	counterValue := atomic.AddUint64(counter, 1)
-
-	// 创建一个唯一的组合值
-	// 1. 雪花ID - 保证全局唯一性
-	// 2. 计数器值 - 确保同一节点连续生成的ID也不重复
-	// 3. 加入时间精度到纳秒 - 进一步减少冲突可能
-
-	// 获取当前纳秒时间戳的后几位
-	nanoTimePart := (time.Now().UnixNano() % 10000)
-
-	// 组合各部分生成一个种子
-	seed := snowflakeID ^ int64(counterValue) ^ int64(nanoTimePart)
-
-	// 使用种子创建一个随机源
-	randMutex.Lock()
-	r := rand.New(rand.NewSource(seed))
-	randMutex.Unlock()
-
-	// 生成数字ID
-	var finalID uint64
+	return localIDWithDigits(snowflakeID, digits), nil
+}
 
-	// 计算最小值和最大值
-	minValue := uint64(1)
-	for i := 1; i < digits; i++ {
-		minValue *= 10
-	}
+// 将雪花ID映射到指定位数范围内
+func localIDWithDigits(snowflakeID int64, digits int) int64 {
+	minValue := digitMinValues[digits]
 	maxValue := minValue*10 - 1
 
-	// 使用雪花ID的低位部分作为基础
-	baseValue := uint64(snowflakeID & 0x7FFFFFFFFFFFFFFF) // 去掉符号位
-
-	// 根据需要的位数生成最终ID
-	finalID = (baseValue % (maxValue - minValue)) + minValue
+	// 使用雪花ID的低位部分作为基础（去掉符号位）
+	baseValue := snowflakeID & 0x7FFFFFFFFFFFFFFF
 
-	// 确保位数正确
-	finalIDStr := fmt.Sprintf("%d", finalID)
-	if len(finalIDStr) > digits {
-		// 如果位数超出，截取右侧digits位
-		finalIDStr = finalIDStr[len(finalIDStr)-digits:]
-		finalID, _ = strconv.ParseUint(finalIDStr, 10, 64)
-	} else if len(finalIDStr) < digits {
-		// 如果位数不足，在左侧补充随机数字
-		padLen := digits - len(finalIDStr)
-		padStr := ""
-		for i := 0; i < padLen; i++ {
-			padStr += fmt.Sprintf("%d", r.Intn(10))
-		}
-		if padStr == strings.Repeat("0", padLen) {
-			// 避免全0前缀
-			padStr = "1" + padStr[1:]
-		}
-		finalIDStr = padStr + finalIDStr
-		finalID, _ = strconv.ParseUint(finalIDStr, 10, 64)
-	}
-
-	// 确保ID不小于指定位数的最小值
-	if finalID < minValue {
-		finalID += minValue
-	}
-
-	return int64(finalID), nil
+	return (baseValue % (maxValue - minValue)) + minValue
 }
 
 // GenSnowIDWithLength 生成指定位数范围内的ID
@@ -797,10 +951,7 @@ func (x *IDGenX) GenSnowIDWithLength(minDigits, maxDigits int) (int64, error) {
 	// 随机选择位数(如果minDigits不等于maxDigits)
 	digits := minDigits
 	if minDigits != maxDigits {
-		randMutex.Lock()
-		r := rand.New(randSource)
-		digits = minDigits + r.Intn(maxDigits-minDigits+1)
-		randMutex.Unlock()
+		digits = minDigits + rand.IntN(maxDigits-minDigits+1)
 	}
 
 	// 使用固定位数生成ID
@@ -818,13 +969,31 @@ func (x *IDGenX) GenDefaultLongSnowID() (int64, error) {
 }
 
 // GenInviteCode 根据用户ID生成邀请码
+// 开启邀请码登记时，生成的邀请码会在Redis中原子占用，保证唯一
 func (x *IDGenX) GenInviteCode(userID uint64) (string, error) {
+	var (
+		code string
+		err  error
+	)
+	if x.inviteRegistry && x.rdb != nil {
+		code, err = x.genRegisteredInviteCode(userID)
+	} else {
+		code, err = x.genInviteCode(userID)
+	}
+	if err == nil {
+		x.recordGenerated("invite_code", 0)
+	}
+	return code, err
+}
+
+// 生成邀请码
+func (x *IDGenX) genInviteCode(userID uint64) (string, error) {
 	if userID == 0 {
 		return "", errors.New("userID cannot be zero")
 	}
 
-	// 固定邀请码长度为8位，避免长度不一致
-	inviteCodeLength := inviteCodeMaxLength
+	// 邀请码长度和字符集由选项配置，同一实例长度固定
+	inviteCodeLength, inviteCodeChars := x.inviteCodeLength, x.inviteCodeChars
 	var code strings.Builder
 	code.Grow(inviteCodeLength)
 
@@ -833,36 +1002,33 @@ func (x *IDGenX) GenInviteCode(userID uint64) (string, error) {
 		// 使用Redis分配一个唯一的邀请码序号
 		inviteCodeSeq, err := x.rdb.Incr(ctx, redisKeyPrefix+"invitecode:seq").Result()
 		if err == nil {
-			// 获取当前时间作为随机种子，结合用户ID
-			now := time.Now().UnixNano()
-			randSeed := now ^ int64(userID)
-			r := rand.New(rand.NewSource(randSeed))
-
-			// 创建8位随机邀请码
+			markRedisOK()
+			// 创建指定长度的随机邀请码
 			codeBytes := make([]byte, inviteCodeLength)
 
 			// 最右边两位保证唯一性
-			// 使用序号的低6位 (0-63) 映射到字符集
+			// 使用序号对字符集长度取模映射到字符集
 			// 这样可以保证生成的邀请码尾部有唯一标识，但看起来仍然是随机的
-			seqLow := inviteCodeSeq % 36
-			seqHigh := (inviteCodeSeq / 36) % 36
+			charsLen := int64(len(inviteCodeChars))
+			seqLow := inviteCodeSeq % charsLen
+			seqHigh := (inviteCodeSeq / charsLen) % charsLen
 
-			// 前面6位完全随机生成
+			// 前面几位完全随机生成
 			for i := 0; i < inviteCodeLength-2; i++ {
-				codeBytes[i] = inviteCodeChars[r.Intn(len(inviteCodeChars))]
+				codeBytes[i] = inviteCodeChars[rand.IntN(len(inviteCodeChars))]
 			}
 
 			// 最后两位用于保证唯一性，但使用伪随机映射使其看起来随机
 			// 映射函数: (value * prime + offset) % len
-			seqLowIdx := (int(seqLow)*31 + r.Intn(7)) % len(inviteCodeChars)
-			seqHighIdx := (int(seqHigh)*37 + r.Intn(11)) % len(inviteCodeChars)
+			seqLowIdx := (int(seqLow)*31 + rand.IntN(7)) % len(inviteCodeChars)
+			seqHighIdx := (int(seqHigh)*37 + rand.IntN(11)) % len(inviteCodeChars)
 
 			codeBytes[inviteCodeLength-2] = inviteCodeChars[seqHighIdx]
 			codeBytes[inviteCodeLength-1] = inviteCodeChars[seqLowIdx]
 
 			// 随机打乱顺序，但保留最后两位的唯一性
 			for i := 0; i < inviteCodeLength-3; i++ {
-				j := r.Intn(inviteCodeLength - 2)
+				j := rand.IntN(inviteCodeLength - 2)
 				codeBytes[i], codeBytes[j] = codeBytes[j], codeBytes[i]
 			}
 
@@ -873,24 +1039,19 @@ func (x *IDGenX) GenInviteCode(userID uint64) (string, error) {
 
 		// 如果Redis操作失败，回退到本地生成
 		logx.Infof("Warning: Failed to get invite code sequence from Redis: %v, falling back to local generation", err)
+		x.recordRedisFallback("invite_code", err)
 	}
 
 	// 本地生成邀请码（Redis不可用时的回退方案）
-	// 确保随机性更高
-	randMutex.Lock()
-	localSource := rand.NewSource(time.Now().UnixNano() ^ int64(userID))
-	r := rand.New(localSource)
-	randMutex.Unlock()
-
 	// 生成完全随机的邀请码
 	for i := 0; i < inviteCodeLength; i++ {
-		randIndex := r.Intn(len(inviteCodeChars))
+		randIndex := rand.IntN(len(inviteCodeChars))
 		code.WriteByte(inviteCodeChars[randIndex])
 	}
 
 	// 在末尾加入用户ID的特征，保证一定的关联性但外观仍然随机
 	userIDHash := int(userID % 1000)
-	charPos := r.Intn(inviteCodeLength - 1)
+	charPos := rand.IntN(inviteCodeLength - 1)
 	charIndex := (userIDHash * 31) % len(inviteCodeChars)
 	codeStr := code.String()
 	codeBytes := []byte(codeStr)
@@ -905,13 +1066,16 @@ func (x *IDGenX) VerifyInviteCode(code string) bool {
 		return false
 	}
 
-	if len(code) != inviteCodeMaxLength {
+	if len(code) != x.inviteCodeLength {
 		return false
 	}
 
-	code = strings.ToUpper(code)
+	chars := x.inviteCodeChars
+	if x.inviteCaseFold {
+		code, chars = strings.ToUpper(code), strings.ToUpper(chars)
+	}
 	for _, c := range code {
-		if !strings.ContainsRune(inviteCodeChars, c) {
+		if !strings.ContainsRune(chars, c) {
 			return false
 		}
 	}
@@ -920,7 +1084,10 @@ func (x *IDGenX) VerifyInviteCode(code string) bool {
 
 // GetMachineID 导出获取机器ID的方法，便于外部使用
 func (x *IDGenX) GetMachineID() (uint16, error) {
-	return getMachineID()
+	if x.machineIDProvider == nil {
+		return getMachineID()
+	}
+	return x.machineIDProvider.MachineID()
 }
 
 // getMachineID 获取机器ID，先后尝试K8s、Docker和MAC地址
@@ -1095,6 +1262,5 @@ func randIntInRange(min, max int) int {
 	if min > max {
 		min, max = max, min
 	}
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	return r.Intn(max-min+1) + min
+	return rand.IntN(max-min+1) + min
 }
@@ -1,9 +1,17 @@
 package idgen
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/zeromicro/go-zero/core/logx"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
+	"testing"
 	"time"
 )
 
@@ -93,3 +101,271 @@ func runMultipleConcurrentTests(generator *IDGenX, count int) {
 			expectedTotal, len(globalIDMap))
 	}
 }
+
+// 并发压测无锁雪花ID，检查唯一性以及单个goroutine内的单调递增
+func TestGenerateSnowflakeIDSoak(t *testing.T) {
+	workers, perWorker := 64, 20000
+	if testing.Short() {
+		perWorker = 2000
+	}
+
+	results := make([][]int64, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ids := make([]int64, 0, perWorker)
+			for i := 0; i < perWorker; i++ {
+				id, err := generateSnowflakeID()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := len(ids); n > 0 && id <= ids[n-1] {
+					t.Errorf("id not monotonic: %d <= %d", id, ids[n-1])
+					return
+				}
+				ids = append(ids, id)
+			}
+			results[w] = ids
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]struct{}, workers*perWorker)
+	for _, ids := range results {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				t.Fatalf("duplicate id: %d", id)
+			}
+			seen[id] = struct{}{}
+		}
+	}
+	t.Logf("generated %d unique ids", len(seen))
+}
+
+// 旧实现：互斥锁保护时间戳和序列号，作为基准对照
+type mutexSnowflake struct {
+	mu       sync.Mutex
+	lastTs   int64
+	sequence int64
+}
+
+func (m *mutexSnowflake) next() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ts := timeGen()
+	if ts == m.lastTs {
+		m.sequence = (m.sequence + 1) & sequenceMask
+		if m.sequence == 0 {
+			ts = tilNextMillis(m.lastTs)
+		}
+	} else {
+		m.sequence = 0
+	}
+	m.lastTs = ts
+	return ((ts - startTime) << timestampLeftShift) | (nodeID << nodeIDLeftShift) | m.sequence
+}
+
+func BenchmarkSnowflakeMutexBaseline(b *testing.B) {
+	m := &mutexSnowflake{}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.next()
+		}
+	})
+}
+
+func BenchmarkGenerateSnowflakeID(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = generateSnowflakeID()
+		}
+	})
+}
+
+func BenchmarkGenIDWithDigitsLocal(b *testing.B) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = generator.GenIDWithDigits(12)
+		}
+	})
+}
+
+// 旧实现的本地位数ID路径：每位数一把锁，并在锁内为每次调用创建随机源
+func BenchmarkGenIDWithDigitsMutexBaseline(b *testing.B) {
+	m := &mutexSnowflake{}
+	var lock, randLock sync.Mutex
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			lock.Lock()
+			id := m.next()
+			randLock.Lock()
+			r := rand.New(rand.NewSource(id ^ time.Now().UnixNano()))
+			randLock.Unlock()
+			_ = localIDWithDigits(id, 12) + int64(r.Intn(1))
+			lock.Unlock()
+		}
+	})
+}
+
+func BenchmarkGenInviteCodeLocal(b *testing.B) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	b.RunParallel(func(pb *testing.PB) {
+		userID := uint64(1)
+		for pb.Next() {
+			_, _ = generator.GenInviteCode(userID)
+			userID++
+		}
+	})
+}
+
+// 旧实现的本地邀请码随机源：全局锁内为每次调用创建随机源
+func BenchmarkInviteCodeRandMutexBaseline(b *testing.B) {
+	var randLock sync.Mutex
+	b.RunParallel(func(pb *testing.PB) {
+		userID := int64(1)
+		for pb.Next() {
+			randLock.Lock()
+			r := rand.New(rand.NewSource(time.Now().UnixNano() ^ userID))
+			randLock.Unlock()
+			for i := 0; i < inviteCodeMaxLength; i++ {
+				_ = inviteCodeChars[r.Intn(len(inviteCodeChars))]
+			}
+			userID++
+		}
+	})
+}
+
+func BenchmarkGenSnowIDWithLength(b *testing.B) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = generator.GenSnowIDWithLength(8, 12)
+		}
+	})
+}
+
+func TestNamespace(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+
+	order := generator.Namespace("order")
+	if order != generator.Namespace("order") {
+		t.Fatal("expected same namespace instance")
+	}
+	refund := order.Namespace("refund")
+	if refund.NamespaceName() != "order:refund" || refund.digitsBizTag(8) != "order:refund:digit:8" {
+		t.Fatalf("unexpected namespace %s", refund.NamespaceName())
+	}
+
+	id, err := order.GenIDWithDigits(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(order.NamespaceName(), id)
+
+	// 原有指标不带命名空间标签，命名空间另计
+	if got := testutil.ToFloat64(namespaceGeneratedTotal.WithLabelValues("order", "digits", "8")); got != 1 {
+		t.Fatalf("namespace generated = %v", got)
+	}
+	if got := testutil.ToFloat64(generatedTotal.WithLabelValues("digits", "8")); got < 1 {
+		t.Fatalf("generated = %v", got)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	if _, err := generator.GenIDWithDigits(8); err != nil {
+		t.Fatal(err)
+	}
+
+	status := generator.Status()
+	if status.Mode != ModeLocal || status.Degraded {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestStatusNodeIDFallback(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	if _, err := generator.GenIDWithDigits(8); err != nil {
+		t.Fatal(err)
+	}
+	origin := currentNodeIDOrigin.Load()
+	defer currentNodeIDOrigin.Store(origin)
+
+	// 节点ID分配失败后，之后的Redis操作成功也不会恢复
+	setNodeIDOrigin(NodeIDSourceLocal, true)
+	markRedisOK()
+	status := generator.Status()
+	if !status.Degraded || !status.NodeIDFallback || status.NodeIDSource != NodeIDSourceLocal {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	setNodeIDOrigin(NodeIDSourceRedis, false)
+	if status = generator.Status(); status.Degraded {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestStatusHandlerHidesRedisError(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	markRedisError(errors.New("dial tcp 10.0.0.7:6379: auth failed"))
+	defer func() {
+		redisHealth.mu.Lock()
+		redisHealth.failed, redisHealth.lastErr, redisHealth.lastErrAt = false, nil, time.Time{}
+		redisHealth.mu.Unlock()
+	}()
+
+	if status := generator.Status(); status.LastRedisError == "" {
+		t.Fatal("Status should keep the redis error")
+	}
+	rec := httptest.NewRecorder()
+	generator.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "10.0.0.7") {
+		t.Fatalf("status response %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGlobalMonotonicFailsClosed(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)), WithMonotonic(MonotonicGlobal))
+	if _, err := generator.GenId(); !errors.Is(err, ErrMonotonicUnavailable) {
+		t.Fatalf("GenId without Redis err = %v", err)
+	}
+}
+
+func TestTaskGroupWait(t *testing.T) {
+	var g taskGroup
+	release := make(chan struct{})
+	var finished sync.WaitGroup
+	finished.Add(1)
+	g.goTask(func() {
+		defer finished.Done()
+		<-release
+	})
+
+	// 等待期间并发启动任务不会触发 WaitGroup 竞态
+	var spawners sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		spawners.Add(1)
+		go func() {
+			defer spawners.Done()
+			g.goTask(func() {})
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := g.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait with running task err = %v", err)
+	}
+
+	close(release)
+	finished.Wait()
+	spawners.Wait()
+	if err := g.wait(context.Background()); err != nil {
+		t.Fatalf("wait after tasks finished err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 邀请码登记键前缀：code -> userID
+	inviteCodeRegistryPrefix = redisKeyPrefix + "invitecode:code:"
+	// 邀请码冲突时的最大重试次数
+	inviteCodeMaxRetries = 10
+)
+
+var (
+	// ErrInviteCodeNotFound 邀请码未登记
+	ErrInviteCodeNotFound = errors.New("invite code not found")
+	// ErrInviteCodeExhausted 多次重试仍然冲突
+	ErrInviteCodeExhausted = errors.New("invite code collision retries exhausted")
+	// ErrInviteRegistryDisabled 未开启邀请码登记或Redis不可用
+	ErrInviteRegistryDisabled = errors.New("invite code registry is not enabled")
+)
+
+// WithInviteCodeRegistry 开启邀请码登记：生成时在Redis中原子占用(SETNX code->userID)，
+// 冲突自动重试，并支持通过 ResolveInviteCode 反查用户ID；ttl 为0时永久保存
+func WithInviteCodeRegistry(ttl time.Duration) Option {
+	return func(x *IDGenX) {
+		x.inviteRegistry = true
+		x.inviteRegistryTTL = ttl
+	}
+}
+
+// ResolveInviteCode 根据邀请码反查用户ID
+func (x *IDGenX) ResolveInviteCode(code string) (uint64, error) {
+	if !x.inviteRegistry || x.rdb == nil {
+		return 0, ErrInviteRegistryDisabled
+	}
+
+	val, err := x.rdb.Get(ctx, x.inviteCodeRegistryKey(code)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, ErrInviteCodeNotFound
+		}
+		return 0, fmt.Errorf("resolve invite code: %w", err)
+	}
+
+	userID, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user id %q for invite code %s: %w", val, code, err)
+	}
+	return userID, nil
+}
+
+// 生成并登记邀请码，冲突时重新生成
+func (x *IDGenX) genRegisteredInviteCode(userID uint64) (string, error) {
+	for i := 0; i < inviteCodeMaxRetries; i++ {
+		code, err := x.genInviteCode(userID)
+		if err != nil {
+			return "", err
+		}
+
+		ok, err := x.reserveInviteCode(code, userID)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return code, nil
+		}
+
+		logx.Infof("Warning: invite code %s already taken, retrying (%d/%d)", code, i+1, inviteCodeMaxRetries)
+		inviteCodeCollisionTotal.Inc()
+	}
+
+	return "", ErrInviteCodeExhausted
+}
+
+// 原子占用邀请码，已被同一用户占用时视为成功
+func (x *IDGenX) reserveInviteCode(code string, userID uint64) (bool, error) {
+	key := x.inviteCodeRegistryKey(code)
+	value := strconv.FormatUint(userID, 10)
+
+	ok, err := x.rdb.SetNX(ctx, key, value, x.inviteRegistryTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("reserve invite code: %w", err)
+	}
+	if ok {
+		return true, nil
+	}
+
+	owner, err := x.rdb.Get(ctx, key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, fmt.Errorf("reserve invite code: %w", err)
+	}
+	return owner == value, nil
+}
+
+// 邀请码登记键，忽略大小写时统一转为大写
+func (x *IDGenX) inviteCodeRegistryKey(code string) string {
+	if x.inviteCaseFold {
+		code = strings.ToUpper(code)
+	}
+	return inviteCodeRegistryPrefix + code
+}
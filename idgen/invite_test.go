@@ -0,0 +1,100 @@
+package idgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInviteCodeOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantLength int
+		wantChars  string
+	}{
+		{"defaults", nil, inviteCodeMaxLength, inviteCodeChars},
+		{"length", []Option{WithInviteCodeLength(6)}, 6, inviteCodeChars},
+		{"length bounds", []Option{WithInviteCodeLength(4), WithInviteCodeLength(32)}, 32, inviteCodeChars},
+		{"length too short", []Option{WithInviteCodeLength(3)}, inviteCodeMaxLength, inviteCodeChars},
+		{"length too long", []Option{WithInviteCodeLength(33)}, inviteCodeMaxLength, inviteCodeChars},
+		{"charset", []Option{WithInviteCodeCharset("ab")}, inviteCodeMaxLength, "ab"},
+		{"charset too short", []Option{WithInviteCodeCharset("a")}, inviteCodeMaxLength, inviteCodeChars},
+		{"charset duplicates", []Option{WithInviteCodeCharset("abca")}, inviteCodeMaxLength, inviteCodeChars},
+		{"charset non ascii", []Option{WithInviteCodeCharset("ab中")}, inviteCodeMaxLength, inviteCodeChars},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewIDGenX(nil, tt.opts...)
+			if x.inviteCodeLength != tt.wantLength || x.inviteCodeChars != tt.wantChars {
+				t.Fatalf("length = %d, chars = %q", x.inviteCodeLength, x.inviteCodeChars)
+			}
+		})
+	}
+}
+
+func TestGenInviteCodeLocal(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []Option
+		chars string
+		len   int
+	}{
+		{"default", nil, inviteCodeChars, inviteCodeMaxLength},
+		{"custom", []Option{WithInviteCodeLength(12), WithInviteCodeCharset("abcdef")}, "abcdef", 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewIDGenX(nil, tt.opts...)
+			code, err := x.GenInviteCode(42)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(code) != tt.len || strings.Trim(code, tt.chars) != "" {
+				t.Fatalf("code %q not %d chars from %q", code, tt.len, tt.chars)
+			}
+			if !x.VerifyInviteCode(code) {
+				t.Fatalf("generated code %q does not verify", code)
+			}
+		})
+	}
+
+	if _, err := NewIDGenX(nil).GenInviteCode(0); err == nil {
+		t.Fatal("expected error for zero user id")
+	}
+}
+
+func TestVerifyInviteCode(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		code string
+		want bool
+	}{
+		{"default upper", nil, "ABCD1234", true},
+		{"default folds case", nil, "abcd1234", true},
+		{"excluded char", nil, "ABCD123O", false},
+		{"wrong length", nil, "ABCD123", false},
+		{"empty", nil, "", false},
+		{"custom length", []Option{WithInviteCodeLength(4)}, "AB12", true},
+		{"case sensitive rejects", []Option{WithInviteCodeCharset("abcd"), WithInviteCodeLength(4), WithInviteCodeCaseInsensitive(false)}, "ABCD", false},
+		{"case sensitive accepts", []Option{WithInviteCodeCharset("abcd"), WithInviteCodeLength(4), WithInviteCodeCaseInsensitive(false)}, "abcd", true},
+		{"mixed charset case sensitive", []Option{WithInviteCodeCharset("aA"), WithInviteCodeLength(4), WithInviteCodeCaseInsensitive(false)}, "aAaA", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewIDGenX(nil, tt.opts...).VerifyInviteCode(tt.code); got != tt.want {
+				t.Fatalf("VerifyInviteCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInviteCodeRegistryKey(t *testing.T) {
+	if got := NewIDGenX(nil).inviteCodeRegistryKey("abC1"); got != inviteCodeRegistryPrefix+"ABC1" {
+		t.Fatalf("folded key = %q", got)
+	}
+	x := NewIDGenX(nil, WithInviteCodeCaseInsensitive(false))
+	if got := x.inviteCodeRegistryKey("abC1"); got != inviteCodeRegistryPrefix+"abC1" {
+		t.Fatalf("case sensitive key = %q", got)
+	}
+}
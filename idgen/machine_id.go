@@ -0,0 +1,111 @@
+package idgen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MachineIDProvider 机器ID提供者
+// 调用方可以实现该接口，从云主机元数据、StatefulSet序号、环境变量等来源获取机器ID
+type MachineIDProvider interface {
+	// MachineID 返回 [0, 1023] 范围内的机器ID
+	MachineID() (uint16, error)
+}
+
+// MachineIDProviderFunc 函数适配器，便于直接使用函数作为提供者
+type MachineIDProviderFunc func() (uint16, error)
+
+// MachineID 实现 MachineIDProvider 接口
+func (f MachineIDProviderFunc) MachineID() (uint16, error) {
+	return f()
+}
+
+// 默认提供者，依次尝试K8s、Docker和MAC地址
+var defaultMachineIDProvider MachineIDProvider = MachineIDProviderFunc(getMachineID)
+
+// DefaultMachineIDProvider 返回默认的K8s/Docker/MAC探测链提供者
+func DefaultMachineIDProvider() MachineIDProvider {
+	return defaultMachineIDProvider
+}
+
+// StaticMachineIDProvider 固定机器ID提供者
+func StaticMachineIDProvider(id uint16) MachineIDProvider {
+	return MachineIDProviderFunc(func() (uint16, error) {
+		return checkMachineID(int64(id))
+	})
+}
+
+// EnvMachineIDProvider 从环境变量读取机器ID
+func EnvMachineIDProvider(key string) MachineIDProvider {
+	return MachineIDProviderFunc(func() (uint16, error) {
+		val := strings.TrimSpace(os.Getenv(key))
+		if val == "" {
+			return 0, fmt.Errorf("environment variable %s is empty", key)
+		}
+
+		id, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid machine ID in %s: %w", key, err)
+		}
+
+		return checkMachineID(id)
+	})
+}
+
+// StatefulSetOrdinalProvider 从StatefulSet的Pod名称(如 app-3)中解析序号作为机器ID
+// offset 用于多个StatefulSet共用ID空间时错开序号
+func StatefulSetOrdinalProvider(offset uint16) MachineIDProvider {
+	return MachineIDProviderFunc(func() (uint16, error) {
+		hostname := os.Getenv("HOSTNAME")
+		if hostname == "" {
+			var err error
+			if hostname, err = os.Hostname(); err != nil {
+				return 0, err
+			}
+		}
+
+		idx := strings.LastIndex(hostname, "-")
+		if idx < 0 || idx == len(hostname)-1 {
+			return 0, fmt.Errorf("hostname %s has no statefulset ordinal", hostname)
+		}
+
+		ordinal, err := strconv.ParseInt(hostname[idx+1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid statefulset ordinal in %s: %w", hostname, err)
+		}
+
+		return checkMachineID(ordinal + int64(offset))
+	})
+}
+
+// ChainMachineIDProvider 依次尝试多个提供者，返回第一个成功的结果
+func ChainMachineIDProvider(providers ...MachineIDProvider) MachineIDProvider {
+	return MachineIDProviderFunc(func() (uint16, error) {
+		var lastErr error
+		for _, p := range providers {
+			if p == nil {
+				continue
+			}
+			id, err := p.MachineID()
+			if err == nil {
+				return id, nil
+			}
+			lastErr = err
+		}
+
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no machine ID provider available")
+		}
+		return 0, lastErr
+	})
+}
+
+// 校验机器ID是否在节点ID范围内
+func checkMachineID(id int64) (uint16, error) {
+	if id < 0 || id > nodeIDMask {
+		return 0, fmt.Errorf("machine ID %d out of range [0, %d]", id, nodeIDMask)
+	}
+	return uint16(id), nil
+}
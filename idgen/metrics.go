@@ -0,0 +1,173 @@
+package idgen
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ID生成总数计数器
+	generatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "generated_total",
+			Help:      "生成的ID总数",
+		},
+		[]string{"type", "digits"},
+	)
+
+	// 按命名空间统计的ID生成总数，仅统计命名空间子生成器
+	namespaceGeneratedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "namespace_generated_total",
+			Help:      "命名空间子生成器生成的ID总数",
+		},
+		[]string{"namespace", "type", "digits"},
+	)
+
+	// Redis失败回退到本地生成的次数
+	redisFallbackTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "redis_fallback_total",
+			Help:      "Redis不可用回退本地生成的次数",
+		},
+		[]string{"type"},
+	)
+
+	// 按命名空间统计的Redis回退次数，仅统计命名空间子生成器
+	namespaceRedisFallbackTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "namespace_redis_fallback_total",
+			Help:      "命名空间子生成器Redis不可用回退本地生成的次数",
+		},
+		[]string{"namespace", "type"},
+	)
+
+	// 号段预加载耗时直方图
+	segmentPreloadDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "idgen",
+			Name:      "segment_preload_duration_seconds",
+			Help:      "号段预加载耗时（秒）",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 5},
+		},
+		[]string{"source", "result"},
+	)
+
+	// 时钟回拨事件计数器
+	clockBackwardTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "clock_backward_total",
+			Help:      "检测到的时钟回拨次数",
+		},
+	)
+
+	// 节点ID刷新失败计数器
+	nodeIDRefreshFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "node_id_refresh_failures_total",
+			Help:      "节点ID续期失败次数",
+		},
+	)
+
+	// 邀请码登记冲突计数器
+	inviteCodeCollisionTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "invite_code_collision_total",
+			Help:      "邀请码登记冲突重试次数",
+		},
+	)
+
+	// 本地时钟相对参考时钟的偏差
+	clockDriftSeconds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "idgen",
+			Name:      "clock_drift_seconds",
+			Help:      "本地时钟相对参考时钟的偏差（秒）",
+		},
+	)
+
+	// 影子校验发现的重复ID数
+	shadowDuplicatesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idgen",
+			Name:      "shadow_duplicates_total",
+			Help:      "影子校验发现的重复ID数",
+		},
+		[]string{"layout"},
+	)
+
+	// 所有指标
+	allCollectors = []prometheus.Collector{
+		generatedTotal,
+		namespaceGeneratedTotal,
+		redisFallbackTotal,
+		namespaceRedisFallbackTotal,
+		segmentPreloadDuration,
+		clockBackwardTotal,
+		nodeIDRefreshFailures,
+		inviteCodeCollisionTotal,
+		clockDriftSeconds,
+		shadowDuplicatesTotal,
+	}
+)
+
+// metricsCollector 汇总idgen的所有指标
+type metricsCollector struct{}
+
+// Describe 实现 prometheus.Collector 接口
+func (metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range allCollectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range allCollectors {
+		c.Collect(ch)
+	}
+}
+
+// Collector 返回idgen指标收集器，由业务方注册到自己的 Prometheus registry
+func (x *IDGenX) Collector() prometheus.Collector {
+	return metricsCollector{}
+}
+
+// 记录ID生成，命名空间子生成器同时计入命名空间指标
+func (x *IDGenX) recordGenerated(idType string, digits int) {
+	d := ""
+	if digits > 0 {
+		d = strconv.Itoa(digits)
+	}
+	generatedTotal.WithLabelValues(idType, d).Inc()
+	if x.namespace != "" {
+		namespaceGeneratedTotal.WithLabelValues(x.namespace, idType, d).Inc()
+	}
+}
+
+// 记录Redis回退
+func (x *IDGenX) recordRedisFallback(idType string, err error) {
+	redisFallbackTotal.WithLabelValues(idType).Inc()
+	if x.namespace != "" {
+		namespaceRedisFallbackTotal.WithLabelValues(x.namespace, idType).Inc()
+	}
+	redisHealth.fallbacks.Add(1)
+	markRedisError(err)
+}
+
+// 记录号段预加载耗时
+func recordSegmentPreload(source string, seconds float64, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	segmentPreloadDuration.WithLabelValues(source, result).Observe(seconds)
+}
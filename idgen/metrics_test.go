@@ -0,0 +1,87 @@
+package idgen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectorRegisters(t *testing.T) {
+	x := NewIDGenX(nil)
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(x.Collector()); err != nil {
+		t.Fatal(err)
+	}
+	recordSegmentPreload("redis", 0.01, nil)
+	if _, err := reg.Gather(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// counterValue 读取计数器当前值
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestRecordMetrics(t *testing.T) {
+	// 指标为全局，按差值断言
+	x := NewIDGenX(nil)
+	orders := x.Namespace("orders")
+	tests := []struct {
+		name   string
+		metric prometheus.Counter
+		record func()
+	}{
+		{"generated digits", generatedTotal.WithLabelValues("digits", "6"), func() { x.recordGenerated("digits", 6) }},
+		{"generated snowflake", generatedTotal.WithLabelValues("snowflake", ""), func() { x.recordGenerated("snowflake", 0) }},
+		{"generated in namespace", namespaceGeneratedTotal.WithLabelValues("orders", "digits", "6"), func() { orders.recordGenerated("digits", 6) }},
+		{"namespace keeps global count", generatedTotal.WithLabelValues("digits", "6"), func() { orders.recordGenerated("digits", 6) }},
+		{"redis fallback", redisFallbackTotal.WithLabelValues("snowflake"), func() { x.recordRedisFallback("snowflake", errors.New("timeout")) }},
+		{"redis fallback in namespace", namespaceRedisFallbackTotal.WithLabelValues("orders", "snowflake"), func() { orders.recordRedisFallback("snowflake", errors.New("timeout")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := counterValue(t, tt.metric)
+			tt.record()
+			if got := counterValue(t, tt.metric) - before; got != 1 {
+				t.Fatalf("increased by %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRecordSegmentPreload(t *testing.T) {
+	// 读取直方图样本数
+	count := func(result string) uint64 {
+		var m dto.Metric
+		if err := segmentPreloadDuration.WithLabelValues("db", result).(prometheus.Histogram).Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		result string
+	}{
+		{"success", nil, "success"},
+		{"error", errors.New("timeout"), "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := count(tt.result)
+			recordSegmentPreload("db", 0.002, tt.err)
+			if got := count(tt.result) - before; got != 1 {
+				t.Fatalf("%s samples increased by %d, want 1", tt.result, got)
+			}
+		})
+	}
+}
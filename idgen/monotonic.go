@@ -0,0 +1,88 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// MonotonicMode 单调递增模式
+type MonotonicMode int
+
+const (
+	// MonotonicOff 不保证单调（默认，兼容原有行为）
+	MonotonicOff MonotonicMode = iota
+	// MonotonicNode 单节点内严格递增
+	MonotonicNode
+	// MonotonicGlobal 基于Redis TIME和序列号全局严格递增
+	MonotonicGlobal
+)
+
+const (
+	// 全局单调状态键
+	monotonicRedisKey = redisKeyPrefix + "monotonic"
+	// 全局模式下每毫秒可用的序列号数（节点ID位+序列号位）
+	monotonicSeqPerMs = int64(1) << (nodeIDBits + sequenceBits)
+)
+
+// ErrMonotonicUnavailable 全局单调模式下Redis不可用
+var ErrMonotonicUnavailable = errors.New("global monotonic ID unavailable: Redis required")
+
+// 全局单调ID脚本：以Redis TIME为时钟，同一毫秒内递增序列号，序列号用完借用下一毫秒
+// 时间戳和序列号分开存储，避免Lua双精度数溢出
+var monotonicScript = redis.NewScript(`
+if redis.replicate_commands then redis.replicate_commands() end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local lastTs = tonumber(redis.call('HGET', KEYS[1], 'ts') or '0')
+local seq = tonumber(redis.call('HGET', KEYS[1], 'seq') or '0')
+local maxSeq = tonumber(ARGV[1])
+if now > lastTs then
+	lastTs = now
+	seq = 0
+else
+	seq = seq + 1
+	if seq >= maxSeq then
+		lastTs = lastTs + 1
+		seq = 0
+	end
+end
+redis.call('HSET', KEYS[1], 'ts', lastTs, 'seq', seq)
+return {lastTs, seq}
+`)
+
+// 按单调模式生成ID
+// 全局模式的ID布局（节点ID位和序列号位合并为全局序列号）与节点内ID重叠，Redis不可用时不能退化，直接返回错误
+func (x *IDGenX) genMonotonicID() (int64, error) {
+	if x.monotonic == MonotonicGlobal {
+		if x.rdb == nil {
+			return 0, ErrMonotonicUnavailable
+		}
+		id, err := x.genGlobalMonotonicID()
+		if err != nil {
+			logx.Errorf("Error: Failed to generate global monotonic ID: %v", err)
+			return 0, fmt.Errorf("%w: %w", ErrMonotonicUnavailable, err)
+		}
+		markRedisOK()
+		return id, nil
+	}
+
+	// 节点内单调：打包状态CAS推进，保证严格递增
+	return generateSnowflakeID()
+}
+
+// 基于Redis生成全局单调ID
+func (x *IDGenX) genGlobalMonotonicID() (int64, error) {
+	res, err := monotonicScript.Run(x.ctx, x.rdb, []string{monotonicRedisKey}, monotonicSeqPerMs).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+	if len(res) != 2 {
+		return 0, fmt.Errorf("unexpected monotonic script result: %v", res)
+	}
+
+	// ID结构: 时间戳部分 + 全局序列号部分（占用节点ID和序列号位）
+	return ((res[0] - startTime) << timestampLeftShift) | res[1], nil
+}
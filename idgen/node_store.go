@@ -0,0 +1,252 @@
+package idgen
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNodeIDNotFound 未保存过节点ID
+var ErrNodeIDNotFound = errors.New("node id not found")
+
+// NodeIDStore 节点ID持久化存储，重启后优先复用之前的节点ID
+type NodeIDStore interface {
+	Load(ctx context.Context) (int64, error)
+	Save(ctx context.Context, id int64) error
+}
+
+// FileNodeIDStore 本地文件存储
+type FileNodeIDStore struct {
+	path string
+}
+
+// NewFileNodeIDStore 创建文件存储，path 为空时使用默认路径
+func NewFileNodeIDStore(path string) *FileNodeIDStore {
+	if path == "" {
+		path = nodeIDPersistFile
+	}
+	return &FileNodeIDStore{path: path}
+}
+
+// Load 从文件加载节点ID
+func (s *FileNodeIDStore) Load(_ context.Context) (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, ErrNodeIDNotFound
+		}
+		return 0, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Save 保存节点ID到文件
+func (s *FileNodeIDStore) Save(_ context.Context, id int64) error {
+	return os.WriteFile(s.path, []byte(strconv.FormatInt(id, 10)), 0644)
+}
+
+// RedisNodeIDStore Redis存储，按实例标识（默认主机名）保存
+type RedisNodeIDStore struct {
+	rdb redis.UniversalClient
+	key string
+}
+
+// NewRedisNodeIDStore 创建Redis存储，owner 为实例标识，为空时使用主机名（K8s中为Pod名）
+func NewRedisNodeIDStore(rdb redis.UniversalClient, owner string) *RedisNodeIDStore {
+	if owner == "" {
+		owner, _ = os.Hostname()
+	}
+	return &RedisNodeIDStore{rdb: rdb, key: redisKeyPrefix + "nodestore:" + owner}
+}
+
+// Load 从Redis加载节点ID
+func (s *RedisNodeIDStore) Load(ctx context.Context) (int64, error) {
+	id, err := s.rdb.Get(ctx, s.key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrNodeIDNotFound
+	}
+	return id, err
+}
+
+// Save 保存节点ID到Redis，与节点ID使用相同的过期时间
+func (s *RedisNodeIDStore) Save(ctx context.Context, id int64) error {
+	return s.rdb.Set(ctx, s.key, id, nodeIDExpiryDays*24*time.Hour).Err()
+}
+
+// NoopNodeIDStore 不持久化节点ID，每次启动重新分配
+type NoopNodeIDStore struct{}
+
+// Load 总是返回未找到
+func (NoopNodeIDStore) Load(context.Context) (int64, error) {
+	return 0, ErrNodeIDNotFound
+}
+
+// Save 不做任何事
+func (NoopNodeIDStore) Save(context.Context, int64) error {
+	return nil
+}
+
+const (
+	// 集群内ServiceAccount挂载目录
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// ConfigMapNodeIDStore Kubernetes ConfigMap存储，以Pod名为键保存节点ID
+// 需要ServiceAccount具有该ConfigMap的 get、create、patch 权限
+type ConfigMapNodeIDStore struct {
+	client    *http.Client
+	apiServer string
+	token     string
+	namespace string
+	name      string
+	key       string
+}
+
+// NewConfigMapNodeIDStore 使用集群内配置创建ConfigMap存储，namespace 为空时使用当前Pod所在命名空间
+func NewConfigMapNodeIDStore(namespace, name string) (*ConfigMapNodeIDStore, error) {
+	if name = strings.TrimSpace(name); name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid configmap name %q", name)
+	}
+
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in kubernetes cluster")
+	}
+
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return nil, fmt.Errorf("read service account token: %w", err)
+	}
+	caData, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("read service account ca: %w", err)
+	}
+	if namespace == "" {
+		ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("read service account namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(caData)
+
+	podName, _ := os.Hostname()
+	return &ConfigMapNodeIDStore{
+		client: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		apiServer: "https://" + host + ":" + port,
+		token:     strings.TrimSpace(string(token)),
+		namespace: namespace,
+		name:      name,
+		key:       podName,
+	}, nil
+}
+
+// Load 从ConfigMap加载当前Pod的节点ID
+func (s *ConfigMapNodeIDStore) Load(ctx context.Context) (int64, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.configMapURL(), "", nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, ErrNodeIDNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, s.statusError(resp)
+	}
+
+	var cm struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cm); err != nil {
+		return 0, err
+	}
+
+	value, ok := cm.Data[s.key]
+	if !ok {
+		return 0, ErrNodeIDNotFound
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Save 保存当前Pod的节点ID到ConfigMap，不存在时创建
+func (s *ConfigMapNodeIDStore) Save(ctx context.Context, id int64) error {
+	data := map[string]string{s.key: strconv.FormatInt(id, 10)}
+
+	patch, _ := json.Marshal(map[string]any{"data": data})
+	resp, err := s.do(ctx, http.MethodPatch, s.configMapURL(), "application/merge-patch+json", patch)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return s.statusError(resp)
+	}
+
+	// ConfigMap不存在时创建
+	body, _ := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]string{"name": s.name, "namespace": s.namespace},
+		"data":       data,
+	})
+	url := fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps", s.apiServer, s.namespace)
+	resp, err = s.do(ctx, http.MethodPost, url, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return s.statusError(resp)
+	}
+	return nil
+}
+
+// configMapURL ConfigMap资源地址
+func (s *ConfigMapNodeIDStore) configMapURL() string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/configmaps/%s", s.apiServer, s.namespace, s.name)
+}
+
+// do 发送API请求
+func (s *ConfigMapNodeIDStore) do(ctx context.Context, method, url, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return s.client.Do(req)
+}
+
+// statusError 将非预期的响应转换为错误
+func (s *ConfigMapNodeIDStore) statusError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("configmap %s/%s: unexpected status %d: %s", s.namespace, s.name, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
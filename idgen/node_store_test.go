@@ -0,0 +1,18 @@
+package idgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewConfigMapNodeIDStoreRequiresName(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "127.0.0.1")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "6443")
+
+	for _, name := range []string{"", "  ", "a/b"} {
+		_, err := NewConfigMapNodeIDStore("default", name)
+		if err == nil || !strings.Contains(err.Error(), "configmap name") {
+			t.Fatalf("NewConfigMapNodeIDStore(%q) err = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package idgen
+
+import (
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"gorm.io/gorm"
+)
+
+// Option IDGenX 配置选项
+type Option func(*IDGenX)
+
+// WithMachineIDProvider 设置自定义机器ID提供者，替换默认的K8s/Docker/MAC探测链
+func WithMachineIDProvider(provider MachineIDProvider) Option {
+	return func(x *IDGenX) {
+		if provider != nil {
+			x.machineIDProvider = provider
+		}
+	}
+}
+
+// WithNodeIDAllocator 设置节点ID分配器，替换默认的Redis分配方式
+func WithNodeIDAllocator(allocator NodeIDAllocator) Option {
+	return func(x *IDGenX) {
+		if allocator != nil {
+			x.nodeIDAllocator = allocator
+		}
+	}
+}
+
+// WithEtcdNodeIDAllocator 使用etcd租约分配节点ID
+func WithEtcdNodeIDAllocator(cli *clientv3.Client, opts ...EtcdAllocatorOption) Option {
+	return WithNodeIDAllocator(NewEtcdNodeIDAllocator(cli, opts...))
+}
+
+// WithNodeIDStore 设置节点ID持久化存储，默认保存到 /tmp/idgen_node_id.dat
+// 只读或临时 /tmp 的容器可使用 ConfigMap、Redis 或 Noop 存储
+func WithNodeIDStore(store NodeIDStore) Option {
+	return func(x *IDGenX) {
+		if store != nil {
+			x.nodeIDStore = store
+		}
+	}
+}
+
+// WithNodeIDFile 设置节点ID持久化文件路径
+func WithNodeIDFile(path string) Option {
+	return WithNodeIDStore(NewFileNodeIDStore(path))
+}
+
+// WithSegmentAllocator 设置号段分配器，GenIDWithDigits 将优先从该分配器获取号段
+func WithSegmentAllocator(allocator SegmentAllocator) Option {
+	return func(x *IDGenX) {
+		if allocator != nil {
+			x.segmentAllocator = allocator
+		}
+	}
+}
+
+// WithDBSegmentAllocator 使用MySQL/Postgres号段表分配ID，无需Redis
+func WithDBSegmentAllocator(db *gorm.DB, opts ...DBAllocatorOption) Option {
+	return WithSegmentAllocator(NewDBSegmentAllocator(db, opts...))
+}
+
+// WithRedisCluster 按Redis Cluster生成键，号段和节点ID的键带 hash tag，同一号段的键落在同一slot
+// 开启后首次取号段时从未带 hash tag 的旧键续接已分配的最大ID，滚动切换期间不应与未开启的节点混用
+func WithRedisCluster() Option {
+	return func(x *IDGenX) {
+		x.redisCluster = true
+	}
+}
+
+// WithMonotonic 设置单调递增模式，开启后 GenId 生成的ID严格递增
+// MonotonicNode 保证单节点内递增，MonotonicGlobal 借助Redis TIME保证全局递增，Redis不可用时返回 ErrMonotonicUnavailable
+func WithMonotonic(mode MonotonicMode) Option {
+	return func(x *IDGenX) {
+		x.monotonic = mode
+	}
+}
+
+// WithCheckDigit 设置 GenIDWithDigits 追加校验位，总位数不变（末位为校验位）
+// 配合 ValidateCheckedID 检测用户输入的账号等数字ID是否有误
+func WithCheckDigit(alg CheckDigitAlgorithm) Option {
+	return func(x *IDGenX) {
+		x.checkDigit = alg
+	}
+}
+
+// WithInviteCodeLength 设置邀请码长度，超出 [4, 32] 范围时忽略
+func WithInviteCodeLength(length int) Option {
+	return func(x *IDGenX) {
+		if length >= inviteCodeMinLength && length <= inviteCodeLimitLength {
+			x.inviteCodeLength = length
+		}
+	}
+}
+
+// WithInviteCodeCharset 设置邀请码字符集，至少需要2个不重复的ASCII字符，否则忽略
+// 忽略大小写校验时，字符集中不应同时包含同一字母的大小写形式
+func WithInviteCodeCharset(chars string) Option {
+	return func(x *IDGenX) {
+		if validInviteCharset(chars) {
+			x.inviteCodeChars = chars
+		}
+	}
+}
+
+// WithInviteCodeCaseInsensitive 设置邀请码校验是否忽略大小写（默认忽略）
+func WithInviteCodeCaseInsensitive(enabled bool) Option {
+	return func(x *IDGenX) {
+		x.inviteCaseFold = enabled
+	}
+}
+
+// 校验邀请码字符集
+func validInviteCharset(chars string) bool {
+	if len(chars) < 2 {
+		return false
+	}
+
+	var seen [128]bool
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
+		if c >= 128 || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
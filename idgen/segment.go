@@ -0,0 +1,109 @@
+package idgen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SegmentAllocator 号段分配器，每次分配一段连续的ID
+type SegmentAllocator interface {
+	// NextSegment 获取业务标识对应的下一个号段，返回起始值和号段长度
+	NextSegment(ctx context.Context, bizTag string) (start int64, size int64, err error)
+}
+
+// segmentBuffer 双缓冲号段，当前号段消耗到阈值时异步预加载下一个号段
+type segmentBuffer struct {
+	mu      sync.Mutex
+	current *IDSegment
+	next    *IDSegment
+	loading bool
+}
+
+// 从号段分配器获取指定位数的唯一ID
+func (x *IDGenX) getUniqueIDFromSegmentAllocator(bizTag string, digits int) (int64, error) {
+	val, _ := x.segmentBuffers.LoadOrStore(bizTag, &segmentBuffer{})
+	buf := val.(*segmentBuffer)
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+
+	// 当前号段不存在或已用完，优先切换到预加载的号段
+	if buf.current == nil || buf.current.current >= buf.current.max {
+		if buf.next != nil {
+			buf.current, buf.next = buf.next, nil
+			logx.Infof("Using preloaded segment for %s", bizTag)
+		} else {
+			segment, err := x.loadSegment(x.ctx, bizTag)
+			if err != nil {
+				return 0, err
+			}
+			buf.current = segment
+		}
+	}
+
+	// 消耗超过阈值时异步预加载下一个号段
+	preloadThreshold := int64(float64(buf.current.max) * (1 - segmentPreloadThreshold))
+	if buf.current.current >= preloadThreshold && buf.next == nil && !buf.loading {
+		buf.loading = true
+		x.goBackground(func() { x.preloadSegmentBuffer(buf, bizTag) })
+	}
+
+	baseID := buf.current.base + buf.current.current
+	buf.current.current++
+
+	return fitSegmentIDToDigits(baseID, digits), nil
+}
+
+// 异步预加载下一个号段
+func (x *IDGenX) preloadSegmentBuffer(buf *segmentBuffer, bizTag string) {
+	ctx, cancel := context.WithTimeout(x.ctx, 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	segment, err := x.loadSegment(ctx, bizTag)
+	recordSegmentPreload("allocator", time.Since(start).Seconds(), err)
+
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	buf.loading = false
+
+	if err != nil {
+		logx.Errorf("Warning: Failed to preload next segment for %s: %v", bizTag, err)
+		return
+	}
+
+	buf.next = segment
+	logx.Infof("Successfully preloaded next segment for %s, starting at %d", bizTag, segment.base)
+}
+
+// 从号段分配器加载一个号段
+func (x *IDGenX) loadSegment(ctx context.Context, bizTag string) (*IDSegment, error) {
+	start, size, err := x.segmentAllocator.NextSegment(ctx, bizTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get new segment for %s: %w", bizTag, err)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid segment size %d for %s", size, bizTag)
+	}
+
+	return &IDSegment{
+		current: 0,
+		max:     size,
+		base:    start,
+	}, nil
+}
+
+// 将号段中的序号映射到指定位数范围内
+func fitSegmentIDToDigits(baseID int64, digits int) int64 {
+	minValue := int64(1)
+	for i := 1; i < digits; i++ {
+		minValue *= 10
+	}
+	maxValue := minValue*10 - 1
+
+	return (baseID % (maxValue - minValue + 1)) + minValue
+}
@@ -0,0 +1,276 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Redis号段哈希键后缀，字段：max_id 已分配的最大ID，step 步长，legacy_floor 集群模式续接旧键后的首个ID
+const redisSegmentAllocSuffix = ":alloc"
+
+// 集群模式从未带 hash tag 的旧键续接时预留的ID间隔，滚动切换期间旧版本节点可在间隔内继续分配
+const redisClusterLegacyGap = 1000 * segmentSize
+
+// ErrLegacySegmentOverlap 旧版本节点分配的号段已追上集群模式的号段，继续分配会重复发号
+var ErrLegacySegmentOverlap = errors.New("legacy Redis segments overlap cluster segments, stop legacy nodes before allocating")
+
+// 号段分配脚本：原子地读取步长并推进 max_id，返回 {start, end}
+// KEYS[1] 号段哈希，KEYS[2] 旧版 INCR 计数器（与号段哈希同一slot），记录已分配段数
+// ARGV[1] 默认步长，ARGV[2] 哈希不存在时的起始 max_id，为空时从0开始，ARGV[3] 旧版计数器每段的ID数
+// ARGV[4] 集群模式下未带 hash tag 的旧键已分配的最大ID，为空时不检查；旧键追上 legacy_floor 时返回 LEGACY_OVERLAP
+// 每次分配都与旧版计数器取大，并将计数器推进到新号段之后，滚动升级期间旧版本节点 INCR 得到的号段不会与新号段重叠
+var segmentAllocScript = redis.NewScript(`
+local size = tonumber(ARGV[3])
+local maxId = tonumber(redis.call('HGET', KEYS[1], 'max_id') or '')
+local legacy = tonumber(ARGV[4])
+if not maxId then
+	maxId = tonumber(ARGV[2]) or 0
+	redis.call('HSETNX', KEYS[1], 'step', ARGV[1])
+	if legacy then
+		redis.call('HSET', KEYS[1], 'legacy_floor', maxId + 1)
+	end
+elseif legacy then
+	local floor = tonumber(redis.call('HGET', KEYS[1], 'legacy_floor') or '0')
+	if floor > 0 and legacy >= floor then
+		return redis.error_reply('LEGACY_OVERLAP')
+	end
+end
+local counter = tonumber(redis.call('GET', KEYS[2]) or '0')
+if counter * size > maxId then
+	maxId = counter * size
+end
+local step = tonumber(redis.call('HGET', KEYS[1], 'step'))
+if not step or step <= 0 then
+	step = tonumber(ARGV[1])
+end
+local start = maxId + 1
+local finish = maxId + step
+redis.call('HSET', KEYS[1], 'max_id', string.format('%d', finish))
+redis.call('SET', KEYS[2], string.format('%d', math.ceil(finish / size)))
+return {start, finish}
+`)
+
+// SetSegmentStep 在线调整 GenIDWithDigits 指定位数的Redis号段步长，下次取号段时生效
+func (x *IDGenX) SetSegmentStep(digits int, step int64) error {
+	if x.rdb == nil {
+		return errors.New("Redis client not initialized")
+	}
+	if step <= 0 {
+		return fmt.Errorf("invalid segment step: %d", step)
+	}
+
+	key := x.redisSegmentKey(x.digitsBizTag(digits), digits) + redisSegmentAllocSuffix
+	return x.rdb.HSet(ctx, key, "step", step).Err()
+}
+
+// 通过Lua脚本从Redis分配一个号段
+func (x *IDGenX) allocRedisSegment(ctx context.Context, segmentKey string) (*IDSegment, error) {
+	segments, err := x.allocRedisSegments(ctx, []string{segmentKey})
+	if err != nil {
+		return nil, err
+	}
+	return segments[0], nil
+}
+
+// 通过一次管道为多个号段键分配号段
+func (x *IDGenX) allocRedisSegments(ctx context.Context, segmentKeys []string) ([]*IDSegment, error) {
+	seed, legacy, err := x.clusterSegmentArgs(ctx, segmentKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segmentKeys) == 1 {
+		res, err := segmentAllocScript.Run(ctx, x.rdb, segmentScriptKeys(segmentKeys[0]),
+			segmentSize, seed[0], segmentSize, legacy[0]).Int64Slice()
+		if err != nil {
+			return nil, segmentScriptError(err)
+		}
+		segment, err := newRedisSegment(res)
+		if err != nil {
+			return nil, err
+		}
+		return []*IDSegment{segment}, nil
+	}
+
+	cmds := make([]*redis.Cmd, len(segmentKeys))
+	_, err = x.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range segmentKeys {
+			// 管道中无法处理 NOSCRIPT，直接发送脚本内容
+			cmds[i] = segmentAllocScript.Eval(ctx, pipe, segmentScriptKeys(key), segmentSize, seed[i], segmentSize, legacy[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, segmentScriptError(err)
+	}
+
+	segments := make([]*IDSegment, len(segmentKeys))
+	for i, cmd := range cmds {
+		res, err := cmd.Int64Slice()
+		if err != nil {
+			return nil, segmentScriptError(err)
+		}
+		if segments[i], err = newRedisSegment(res); err != nil {
+			return nil, err
+		}
+	}
+	return segments, nil
+}
+
+// 号段脚本的键：号段哈希和旧版计数器
+func segmentScriptKeys(segmentKey string) []string {
+	return []string{segmentKey + redisSegmentAllocSuffix, segmentKey}
+}
+
+// 集群模式下号段脚本的续接参数：从未带 hash tag 的旧键读取已分配的最大ID，续接时预留 redisClusterLegacyGap，
+// 之后每次分配都检查旧键是否仍在推进；非集群模式下参数为空
+func (x *IDGenX) clusterSegmentArgs(ctx context.Context, segmentKeys []string) (seed, legacy []any, err error) {
+	seed, legacy = make([]any, len(segmentKeys)), make([]any, len(segmentKeys))
+	for i := range segmentKeys {
+		seed[i], legacy[i] = "", ""
+	}
+	if !x.redisCluster {
+		return seed, legacy, nil
+	}
+
+	maxIDs, err := x.untaggedSegmentMaxIDs(ctx, segmentKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, maxID := range maxIDs {
+		seed[i], legacy[i] = maxID+redisClusterLegacyGap, maxID
+	}
+	return seed, legacy, nil
+}
+
+// 通过一次管道读取各号段未带 hash tag 的旧键已分配的最大ID（旧版计数器和号段哈希取大），
+// 各键可能分布在不同slot，集群客户端会按节点拆分管道
+func (x *IDGenX) untaggedSegmentMaxIDs(ctx context.Context, segmentKeys []string) ([]int64, error) {
+	counters := make([]*redis.StringCmd, len(segmentKeys))
+	allocs := make([]*redis.StringCmd, len(segmentKeys))
+	_, _ = x.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range segmentKeys {
+			untagged := untagRedisKey(key)
+			counters[i] = pipe.Get(ctx, untagged)
+			allocs[i] = pipe.HGet(ctx, untagged+redisSegmentAllocSuffix, "max_id")
+		}
+		return nil
+	})
+
+	maxIDs := make([]int64, len(segmentKeys))
+	for i := range segmentKeys {
+		n, err := counters[i].Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		maxID, err := allocs[i].Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		maxIDs[i] = max(n*segmentSize, maxID)
+	}
+	return maxIDs, nil
+}
+
+// 将脚本的 LEGACY_OVERLAP 错误转换为 ErrLegacySegmentOverlap
+func segmentScriptError(err error) error {
+	if err != nil && strings.Contains(err.Error(), "LEGACY_OVERLAP") {
+		return ErrLegacySegmentOverlap
+	}
+	return err
+}
+
+// 将脚本返回的 {start, end} 转换为号段
+func newRedisSegment(res []int64) (*IDSegment, error) {
+	if len(res) != 2 || res[1] < res[0] {
+		return nil, fmt.Errorf("unexpected segment script result: %v", res)
+	}
+	return &IDSegment{
+		current: 0,
+		max:     res[1] - res[0] + 1,
+		base:    res[0],
+	}, nil
+}
+
+// PreloadSegments 通过一次管道为 GenIDWithDigits 的多个位数预取Redis号段，适用于启动预热
+// 已有可用号段时新号段作为待用段，当前号段用完后切换
+func (x *IDGenX) PreloadSegments(digits ...int) error {
+	if x.rdb == nil {
+		return errors.New("Redis client not initialized")
+	}
+	if len(digits) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(digits))
+	for i, d := range digits {
+		keys[i] = x.redisSegmentKey(x.digitsBizTag(d), d)
+	}
+
+	ctx, cancel := context.WithTimeout(x.ctx, 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	segments, err := x.allocRedisSegments(ctx, keys)
+	recordSegmentPreload("redis", time.Since(start).Seconds(), err)
+	if err != nil {
+		return fmt.Errorf("failed to preload segments: %w", err)
+	}
+
+	for i, segment := range segments {
+		storePreloadedSegment(keys[i], segment)
+	}
+	logx.Infof("Successfully preloaded %d segments", len(segments))
+	return nil
+}
+
+// 保存预取的号段：当前号段已用完或不存在时直接替换，否则作为待用段
+func storePreloadedSegment(segmentKey string, newSegment *IDSegment) {
+	counterMutex.Lock()
+	defer counterMutex.Unlock()
+
+	if segment, ok := serverSegments[segmentKey]; ok && segment.current < segment.max {
+		if pendingSegments == nil {
+			pendingSegments = make(map[string]*IDSegment)
+		}
+		pendingSegments[segmentKey] = newSegment
+		return
+	}
+	serverSegments[segmentKey] = newSegment
+}
+
+// Redis号段计数器的键，集群模式下以业务标识和位数作为 hash tag，计数器和号段哈希落在同一slot
+func (x *IDGenX) redisSegmentKey(idType string, digits int) string {
+	if x.redisCluster {
+		return fmt.Sprintf("%s{%s:%d}", redisKeyPrefix, idType, digits)
+	}
+	return fmt.Sprintf("%s%s:%d", redisKeyPrefix, idType, digits)
+}
+
+// 节点ID的键，集群模式下所有节点ID共用 hash tag，分配时一次管道即可扫描全部节点ID
+func (x *IDGenX) redisNodeKey(id int64) string {
+	if x.redisCluster {
+		return fmt.Sprintf("%s{node}:%d", redisKeyPrefix, id)
+	}
+	return fmt.Sprintf("%snode:%d", redisKeyPrefix, id)
+}
+
+// 节点ID需占用的键，集群模式下包括未带 hash tag 的旧键
+func (x *IDGenX) redisNodeKeys(id int64) []string {
+	key := x.redisNodeKey(id)
+	if legacy := untagRedisKey(key); legacy != key {
+		return []string{key, legacy}
+	}
+	return []string{key}
+}
+
+// 去掉键中的 hash tag 括号，得到未开启集群模式时的键
+func untagRedisKey(key string) string {
+	return strings.NewReplacer("{", "", "}", "").Replace(key)
+}
@@ -0,0 +1,249 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Cluster 的键槽位：有 hash tag 时只对括号内的内容计算 CRC16
+func clusterSlot(key string) uint16 {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc % 16384
+}
+
+func TestRedisClusterKeys(t *testing.T) {
+	if clusterSlot("123456789") != 12739 {
+		t.Fatal("unexpected crc16")
+	}
+
+	generator := NewIDGenX(nil, WithRedisCluster(), WithMachineIDProvider(StaticMachineIDProvider(1)))
+	for _, x := range []*IDGenX{generator, generator.Namespace("order")} {
+		key := x.redisSegmentKey(x.digitsBizTag(8), 8)
+		if clusterSlot(key) != clusterSlot(key+redisSegmentAllocSuffix) {
+			t.Fatalf("segment keys in different slots: %s", key)
+		}
+		if untagRedisKey(key) != redisKeyPrefix+x.digitsBizTag(8)+":8" {
+			t.Fatalf("unexpected legacy key %s", untagRedisKey(key))
+		}
+		if clusterSlot(x.redisNodeKey(0)) != clusterSlot(x.redisNodeKey(1023)) {
+			t.Fatal("node keys in different slots")
+		}
+	}
+
+	// 未开启集群模式时键格式保持不变
+	legacy := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+	if key := legacy.redisSegmentKey("digit:8", 8); key != "idgen:uniqueid:digit:8:8" {
+		t.Fatalf("unexpected key %s", key)
+	}
+	if key := legacy.redisNodeKey(5); key != "idgen:uniqueid:node:5" {
+		t.Fatalf("unexpected key %s", key)
+	}
+}
+
+// 设置 IDGEN_REDIS_CLUSTER（逗号分隔的节点地址）后在真实集群上运行
+func TestRedisClusterSegments(t *testing.T) {
+	addrs := os.Getenv("IDGEN_REDIS_CLUSTER")
+	if addrs == "" {
+		t.Skip("IDGEN_REDIS_CLUSTER not set")
+	}
+
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: strings.Split(addrs, ",")})
+	defer rdb.Close()
+
+	generator := NewIDGenX(rdb, WithRedisCluster(), WithMachineIDProvider(StaticMachineIDProvider(1))).Namespace("cluster_test")
+	if err := generator.PreloadSegments(8, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 3*segmentSize; i++ {
+		id, err := generator.GenIDWithDigits(8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = true
+	}
+	if status := generator.Status(); status.Degraded {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func newMiniRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+func TestRedisClusterSegmentMigration(t *testing.T) {
+	mr, rdb := newMiniRedis(t)
+	ctx := context.Background()
+	legacy := NewIDGenX(rdb, WithMachineIDProvider(StaticMachineIDProvider(1))).Namespace("migrate")
+	cluster := NewIDGenX(rdb, WithRedisCluster(), WithMachineIDProvider(StaticMachineIDProvider(1))).Namespace("migrate")
+
+	legacyKey := legacy.redisSegmentKey(legacy.digitsBizTag(8), 8)
+	clusterKey := cluster.redisSegmentKey(cluster.digitsBizTag(8), 8)
+	if untagRedisKey(clusterKey) != legacyKey {
+		t.Fatalf("legacy key %s, cluster key %s", legacyKey, clusterKey)
+	}
+
+	// 旧版本节点已通过 INCR 分配了5个号段
+	mr.Set(legacyKey, "5")
+	seg, err := cluster.allocRedisSegment(ctx, clusterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(5*segmentSize + redisClusterLegacyGap + 1); seg.base != want {
+		t.Fatalf("cluster segment base = %d, want %d", seg.base, want)
+	}
+
+	// 旧版本节点在间隔内继续分配，集群模式仍可分配
+	legacySeg, err := legacy.allocRedisSegment(ctx, legacyKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacySeg.base+legacySeg.max > seg.base {
+		t.Fatalf("legacy segment %d+%d overlaps cluster segment %d", legacySeg.base, legacySeg.max, seg.base)
+	}
+	if _, err = cluster.allocRedisSegment(ctx, clusterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧键追上集群号段后拒绝分配
+	mr.HSet(legacyKey+redisSegmentAllocSuffix, "max_id", strconv.FormatInt(seg.base, 10))
+	if _, err = cluster.allocRedisSegment(ctx, clusterKey); !errors.Is(err, ErrLegacySegmentOverlap) {
+		t.Fatalf("overlapping legacy segments err = %v", err)
+	}
+	if _, err = cluster.allocRedisSegments(ctx, []string{clusterKey}); !errors.Is(err, ErrLegacySegmentOverlap) {
+		t.Fatalf("pipelined overlapping legacy segments err = %v", err)
+	}
+}
+
+func TestRedisClusterNodeIDMigration(t *testing.T) {
+	mr, rdb := newMiniRedis(t)
+	ctx := context.Background()
+	cluster := NewIDGenX(rdb, WithRedisCluster(), WithMachineIDProvider(StaticMachineIDProvider(1)))
+	expiry := time.Hour
+
+	// 旧版本节点持有节点ID 3
+	mr.Set("idgen:uniqueid:node:3", "1")
+	if cluster.claimNodeID(3, expiry) {
+		t.Fatal("claimed node ID held by legacy node")
+	}
+	if mr.Exists(cluster.redisNodeKey(3)) {
+		t.Fatal("tagged key left behind after failed claim")
+	}
+
+	id, err := cluster.allocateNodeIDFromRedis(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == 3 {
+		t.Fatal("allocated node ID held by legacy node")
+	}
+	// 新旧键都被占用，旧版本节点无法再分配到该ID
+	for _, key := range cluster.redisNodeKeys(id) {
+		if !mr.Exists(key) {
+			t.Fatalf("key %s not claimed", key)
+		}
+	}
+	if ok, err := cluster.isNodeIDValid(id); err != nil || !ok {
+		t.Fatalf("isNodeIDValid = %v, %v", ok, err)
+	}
+
+	// 旧键丢失时节点ID视为无效
+	mr.Del("idgen:uniqueid:node:" + strconv.FormatInt(id, 10))
+	if ok, _ := cluster.isNodeIDValid(id); ok {
+		t.Fatal("node ID valid without legacy key")
+	}
+
+	free, err := cluster.freeNodeIDs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(free, 3) || slices.Contains(free, id) {
+		t.Fatalf("free node IDs include held IDs")
+	}
+}
+
+func TestRedisSegmentLegacyCounter(t *testing.T) {
+	mr, rdb := newMiniRedis(t)
+	ctx := context.Background()
+	x := NewIDGenX(rdb, WithMachineIDProvider(StaticMachineIDProvider(1))).Namespace("counter")
+	key := x.redisSegmentKey(x.digitsBizTag(8), 8)
+
+	// 升级前旧版本节点已分配7个号段
+	mr.Set(key, "7")
+	seg, err := x.allocRedisSegment(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seg.base != 7*segmentSize+1 || seg.max != segmentSize {
+		t.Fatalf("segment = %d+%d", seg.base, seg.max)
+	}
+
+	// 计数器被推进到新号段之后，旧版本节点 INCR 得到的号段不重叠
+	n, err := rdb.Incr(ctx, key).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (n-1)*segmentSize < seg.base+seg.max-1 {
+		t.Fatalf("legacy segment %d overlaps new segment ending at %d", n, seg.base+seg.max-1)
+	}
+
+	// 新版本分配时读取旧版本节点推进后的计数器
+	next, err := x.allocRedisSegment(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.base != n*segmentSize+1 {
+		t.Fatalf("next segment base = %d, want %d", next.base, n*segmentSize+1)
+	}
+
+	// 在线调整步长
+	if err = x.SetSegmentStep(8, 5*segmentSize); err != nil {
+		t.Fatal(err)
+	}
+	segments, err := x.allocRedisSegments(ctx, []string{key, x.redisSegmentKey(x.digitsBizTag(10), 10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if segments[0].base != next.base+next.max || segments[0].max != 5*segmentSize {
+		t.Fatalf("segment after step change = %d+%d", segments[0].base, segments[0].max)
+	}
+	if segments[1].base != 1 {
+		t.Fatalf("new key segment base = %d", segments[1].base)
+	}
+	if v, _ := mr.Get(key); v != strconv.FormatInt((segments[0].base+segments[0].max-1)/segmentSize, 10) {
+		t.Fatalf("legacy counter = %s", v)
+	}
+}
@@ -0,0 +1,95 @@
+package idgen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memorySegmentAllocator 内存号段分配器，按步长依次分配
+type memorySegmentAllocator struct {
+	mu   sync.Mutex
+	next int64
+	step int64
+	err  error
+}
+
+func (a *memorySegmentAllocator) NextSegment(ctx context.Context, bizTag string) (int64, int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return 0, 0, a.err
+	}
+	start := a.next
+	a.next += a.step
+	return start, a.step, nil
+}
+
+func TestSegmentAllocatorIDs(t *testing.T) {
+	x := NewIDGenX(nil, WithSegmentAllocator(&memorySegmentAllocator{next: 1, step: 10}))
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 95; i++ {
+		id, err := x.getUniqueIDFromSegmentAllocator("digit:6", 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id < 100000 || id > 999999 {
+			t.Fatalf("id %d out of 6 digits", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d after %d ids", id, i)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSegmentAllocatorErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		alloc *memorySegmentAllocator
+		want  string
+	}{
+		{"allocator error", &memorySegmentAllocator{err: errors.New("db down")}, "db down"},
+		{"empty segment", &memorySegmentAllocator{step: 0}, "invalid segment size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewIDGenX(nil, WithSegmentAllocator(tt.alloc))
+			if _, err := x.getUniqueIDFromSegmentAllocator("digit:6", 6); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitSegmentIDToDigits(t *testing.T) {
+	tests := []struct {
+		base   int64
+		digits int
+		want   int64
+	}{
+		{0, 6, 100000},
+		{1, 6, 100001},
+		{899999, 6, 999999},
+		{900000, 6, 100000},
+		{5, 1, 6},
+	}
+	for _, tt := range tests {
+		if got := fitSegmentIDToDigits(tt.base, tt.digits); got != tt.want {
+			t.Fatalf("fitSegmentIDToDigits(%d, %d) = %d, want %d", tt.base, tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestDBSegmentAllocatorOptions(t *testing.T) {
+	a := NewDBSegmentAllocator(nil, WithLeafTableName("id_segments"), WithLeafStep(500), WithLeafStep(-1), WithLeafTableName(""))
+	if a.tableName != "id_segments" || a.step != 500 {
+		t.Fatalf("allocator = %+v", a)
+	}
+	if _, _, err := a.NextSegment(context.Background(), "order"); err == nil {
+		t.Fatal("expected error without database")
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/QuantumShiftX/golib/idgen"
+	"github.com/QuantumShiftX/golib/idgen/server/pb"
+	"github.com/QuantumShiftX/golib/xhttp"
+	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"google.golang.org/grpc/status"
+)
+
+// HTTP请求参数
+type (
+	genIDRequest struct {
+		Digits int32 `form:"digits,optional"`
+	}
+
+	batchGenIDRequest struct {
+		Digits int32 `form:"digits,optional"`
+		Count  int32 `form:"count"`
+	}
+
+	genStringIDRequest struct {
+		Prefix string `form:"prefix,optional"`
+		Base36 bool   `form:"base36,optional"`
+	}
+
+	genInviteCodeRequest struct {
+		UserID uint64 `form:"user_id"`
+	}
+)
+
+// RegisterHandlers 注册可选的HTTP接口，prefix 为路由前缀，如 /idgen
+func RegisterHandlers(server *rest.Server, prefix string, gen *idgen.IDGenX) {
+	server.AddRoutes(Routes(NewServer(gen)), rest.WithPrefix(prefix))
+}
+
+// Routes 返回ID生成服务的HTTP路由
+func Routes(s *Server) []rest.Route {
+	return []rest.Route{
+		{Method: http.MethodGet, Path: "/id", Handler: s.genIDHandler},
+		{Method: http.MethodGet, Path: "/ids", Handler: s.batchGenIDHandler},
+		{Method: http.MethodGet, Path: "/string-id", Handler: s.genStringIDHandler},
+		{Method: http.MethodGet, Path: "/invite-code", Handler: s.genInviteCodeHandler},
+		{Method: http.MethodGet, Path: "/status", Handler: s.gen.StatusHandler().ServeHTTP},
+	}
+}
+
+func (s *Server) genIDHandler(w http.ResponseWriter, r *http.Request) {
+	var req genIDRequest
+	if err := httpx.Parse(r, &req); err != nil {
+		xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		return
+	}
+
+	resp, err := s.GenId(r.Context(), &pb.GenIdReq{Digits: req.Digits})
+	writeResponse(w, r, resp, err)
+}
+
+func (s *Server) batchGenIDHandler(w http.ResponseWriter, r *http.Request) {
+	var req batchGenIDRequest
+	if err := httpx.Parse(r, &req); err != nil {
+		xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		return
+	}
+
+	resp, err := s.BatchGenId(r.Context(), &pb.BatchGenIdReq{Digits: req.Digits, Count: req.Count})
+	writeResponse(w, r, resp, err)
+}
+
+func (s *Server) genStringIDHandler(w http.ResponseWriter, r *http.Request) {
+	var req genStringIDRequest
+	if err := httpx.Parse(r, &req); err != nil {
+		xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		return
+	}
+
+	resp, err := s.GenStringId(r.Context(), &pb.GenStringIdReq{Prefix: req.Prefix, Base36: req.Base36})
+	writeResponse(w, r, resp, err)
+}
+
+func (s *Server) genInviteCodeHandler(w http.ResponseWriter, r *http.Request) {
+	var req genInviteCodeRequest
+	if err := httpx.Parse(r, &req); err != nil {
+		xhttp.JsonBaseResponseCtx(r.Context(), w, err)
+		return
+	}
+
+	resp, err := s.GenInviteCode(r.Context(), &pb.GenInviteCodeReq{UserId: req.UserID})
+	writeResponse(w, r, resp, err)
+}
+
+// 写入响应，gRPC状态错误转换为业务错误
+func writeResponse(w http.ResponseWriter, r *http.Request, resp any, err error) {
+	if err != nil {
+		xhttp.JsonBaseResponseCtx(r.Context(), w, status.Convert(err))
+		return
+	}
+	xhttp.JsonBaseResponseCtx(r.Context(), w, resp)
+}
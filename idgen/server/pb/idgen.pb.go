@@ -0,0 +1,500 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v5.29.3
+// source: idgen.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GenIdReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digits        int32                  `protobuf:"varint,1,opt,name=digits,proto3" json:"digits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenIdReq) Reset() {
+	*x = GenIdReq{}
+	mi := &file_idgen_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenIdReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenIdReq) ProtoMessage() {}
+
+func (x *GenIdReq) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenIdReq.ProtoReflect.Descriptor instead.
+func (*GenIdReq) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GenIdReq) GetDigits() int32 {
+	if x != nil {
+		return x.Digits
+	}
+	return 0
+}
+
+type GenIdResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenIdResp) Reset() {
+	*x = GenIdResp{}
+	mi := &file_idgen_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenIdResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenIdResp) ProtoMessage() {}
+
+func (x *GenIdResp) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenIdResp.ProtoReflect.Descriptor instead.
+func (*GenIdResp) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GenIdResp) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type BatchGenIdReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digits        int32                  `protobuf:"varint,1,opt,name=digits,proto3" json:"digits,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGenIdReq) Reset() {
+	*x = BatchGenIdReq{}
+	mi := &file_idgen_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGenIdReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGenIdReq) ProtoMessage() {}
+
+func (x *BatchGenIdReq) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGenIdReq.ProtoReflect.Descriptor instead.
+func (*BatchGenIdReq) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGenIdReq) GetDigits() int32 {
+	if x != nil {
+		return x.Digits
+	}
+	return 0
+}
+
+func (x *BatchGenIdReq) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type BatchGenIdResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGenIdResp) Reset() {
+	*x = BatchGenIdResp{}
+	mi := &file_idgen_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGenIdResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGenIdResp) ProtoMessage() {}
+
+func (x *BatchGenIdResp) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGenIdResp.ProtoReflect.Descriptor instead.
+func (*BatchGenIdResp) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGenIdResp) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type GenStringIdReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Base36        bool                   `protobuf:"varint,2,opt,name=base36,proto3" json:"base36,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenStringIdReq) Reset() {
+	*x = GenStringIdReq{}
+	mi := &file_idgen_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenStringIdReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenStringIdReq) ProtoMessage() {}
+
+func (x *GenStringIdReq) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenStringIdReq.ProtoReflect.Descriptor instead.
+func (*GenStringIdReq) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenStringIdReq) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *GenStringIdReq) GetBase36() bool {
+	if x != nil {
+		return x.Base36
+	}
+	return false
+}
+
+type GenStringIdResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenStringIdResp) Reset() {
+	*x = GenStringIdResp{}
+	mi := &file_idgen_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenStringIdResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenStringIdResp) ProtoMessage() {}
+
+func (x *GenStringIdResp) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenStringIdResp.ProtoReflect.Descriptor instead.
+func (*GenStringIdResp) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GenStringIdResp) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GenInviteCodeReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenInviteCodeReq) Reset() {
+	*x = GenInviteCodeReq{}
+	mi := &file_idgen_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenInviteCodeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenInviteCodeReq) ProtoMessage() {}
+
+func (x *GenInviteCodeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenInviteCodeReq.ProtoReflect.Descriptor instead.
+func (*GenInviteCodeReq) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GenInviteCodeReq) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type GenInviteCodeResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenInviteCodeResp) Reset() {
+	*x = GenInviteCodeResp{}
+	mi := &file_idgen_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenInviteCodeResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenInviteCodeResp) ProtoMessage() {}
+
+func (x *GenInviteCodeResp) ProtoReflect() protoreflect.Message {
+	mi := &file_idgen_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenInviteCodeResp.ProtoReflect.Descriptor instead.
+func (*GenInviteCodeResp) Descriptor() ([]byte, []int) {
+	return file_idgen_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GenInviteCodeResp) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+var File_idgen_proto protoreflect.FileDescriptor
+
+var file_idgen_proto_rawDesc = string([]byte{
+	0x0a, 0x0b, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x69,
+	0x64, 0x67, 0x65, 0x6e, 0x22, 0x22, 0x0a, 0x08, 0x47, 0x65, 0x6e, 0x49, 0x64, 0x52, 0x65, 0x71,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x22, 0x1b, 0x0a, 0x09, 0x47, 0x65, 0x6e, 0x49,
+	0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x3d, 0x0a, 0x0d, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65,
+	0x6e, 0x49, 0x64, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x64, 0x69, 0x67, 0x69, 0x74, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x22, 0x22, 0x0a, 0x0e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x6e,
+	0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x03, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22, 0x40, 0x0a, 0x0e, 0x47, 0x65, 0x6e, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x61, 0x73, 0x65, 0x33, 0x36, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x62, 0x61, 0x73, 0x65, 0x33, 0x36, 0x22, 0x21, 0x0a, 0x0f, 0x47, 0x65,
+	0x6e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x2b, 0x0a,
+	0x10, 0x47, 0x65, 0x6e, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65,
+	0x71, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0x27, 0x0a, 0x11, 0x47, 0x65,
+	0x6e, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x32, 0xf0, 0x01, 0x0a, 0x05, 0x49, 0x64, 0x47, 0x65, 0x6e, 0x12, 0x2a, 0x0a,
+	0x05, 0x47, 0x65, 0x6e, 0x49, 0x64, 0x12, 0x0f, 0x2e, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e, 0x47,
+	0x65, 0x6e, 0x49, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e,
+	0x47, 0x65, 0x6e, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x12, 0x39, 0x0a, 0x0a, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x2e, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x6e, 0x49, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e,
+	0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x6e, 0x49, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x12, 0x3c, 0x0a, 0x0b, 0x47, 0x65, 0x6e, 0x53, 0x74, 0x72, 0x69, 0x6e,
+	0x67, 0x49, 0x64, 0x12, 0x15, 0x2e, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x53,
+	0x74, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x69, 0x64, 0x67,
+	0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x42, 0x0a, 0x0d, 0x47, 0x65, 0x6e, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x43,
+	0x6f, 0x64, 0x65, 0x12, 0x17, 0x2e, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x49,
+	0x6e, 0x76, 0x69, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x18, 0x2e, 0x69,
+	0x64, 0x67, 0x65, 0x6e, 0x2e, 0x47, 0x65, 0x6e, 0x49, 0x6e, 0x76, 0x69, 0x74, 0x65, 0x43, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x51, 0x75, 0x61, 0x6e, 0x74, 0x75, 0x6d, 0x53, 0x68, 0x69, 0x66,
+	0x74, 0x58, 0x2f, 0x67, 0x6f, 0x6c, 0x69, 0x62, 0x2f, 0x69, 0x64, 0x67, 0x65, 0x6e, 0x2f, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_idgen_proto_rawDescOnce sync.Once
+	file_idgen_proto_rawDescData []byte
+)
+
+func file_idgen_proto_rawDescGZIP() []byte {
+	file_idgen_proto_rawDescOnce.Do(func() {
+		file_idgen_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idgen_proto_rawDesc), len(file_idgen_proto_rawDesc)))
+	})
+	return file_idgen_proto_rawDescData
+}
+
+var file_idgen_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_idgen_proto_goTypes = []any{
+	(*GenIdReq)(nil),          // 0: idgen.GenIdReq
+	(*GenIdResp)(nil),         // 1: idgen.GenIdResp
+	(*BatchGenIdReq)(nil),     // 2: idgen.BatchGenIdReq
+	(*BatchGenIdResp)(nil),    // 3: idgen.BatchGenIdResp
+	(*GenStringIdReq)(nil),    // 4: idgen.GenStringIdReq
+	(*GenStringIdResp)(nil),   // 5: idgen.GenStringIdResp
+	(*GenInviteCodeReq)(nil),  // 6: idgen.GenInviteCodeReq
+	(*GenInviteCodeResp)(nil), // 7: idgen.GenInviteCodeResp
+}
+var file_idgen_proto_depIdxs = []int32{
+	0, // 0: idgen.IdGen.GenId:input_type -> idgen.GenIdReq
+	2, // 1: idgen.IdGen.BatchGenId:input_type -> idgen.BatchGenIdReq
+	4, // 2: idgen.IdGen.GenStringId:input_type -> idgen.GenStringIdReq
+	6, // 3: idgen.IdGen.GenInviteCode:input_type -> idgen.GenInviteCodeReq
+	1, // 4: idgen.IdGen.GenId:output_type -> idgen.GenIdResp
+	3, // 5: idgen.IdGen.BatchGenId:output_type -> idgen.BatchGenIdResp
+	5, // 6: idgen.IdGen.GenStringId:output_type -> idgen.GenStringIdResp
+	7, // 7: idgen.IdGen.GenInviteCode:output_type -> idgen.GenInviteCodeResp
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_idgen_proto_init() }
+func file_idgen_proto_init() {
+	if File_idgen_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idgen_proto_rawDesc), len(file_idgen_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_idgen_proto_goTypes,
+		DependencyIndexes: file_idgen_proto_depIdxs,
+		MessageInfos:      file_idgen_proto_msgTypes,
+	}.Build()
+	File_idgen_proto = out.File
+	file_idgen_proto_goTypes = nil
+	file_idgen_proto_depIdxs = nil
+}
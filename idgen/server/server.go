@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/QuantumShiftX/golib/idgen"
+	"github.com/QuantumShiftX/golib/idgen/server/pb"
+	"github.com/zeromicro/go-zero/zrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 单次批量生成的最大数量
+const maxBatchCount = 1000
+
+// Server ID生成服务，多个业务服务共用一个部署的生成器集群，无需各自维护生成器状态
+type Server struct {
+	pb.UnimplementedIdGenServer
+	gen *idgen.IDGenX
+}
+
+// NewServer 创建ID生成服务
+func NewServer(gen *idgen.IDGenX) *Server {
+	return &Server{gen: gen}
+}
+
+// MustNewRpcServer 创建 go-zero RPC 服务并注册ID生成服务
+func MustNewRpcServer(c zrpc.RpcServerConf, gen *idgen.IDGenX) *zrpc.RpcServer {
+	srv := NewServer(gen)
+	return zrpc.MustNewServer(c, func(s *grpc.Server) {
+		pb.RegisterIdGenServer(s, srv)
+	})
+}
+
+// MustNewClient 创建ID生成服务客户端
+func MustNewClient(c zrpc.RpcClientConf) pb.IdGenClient {
+	return pb.NewIdGenClient(zrpc.MustNewClient(c).Conn())
+}
+
+// GenId 生成数字ID，digits 为0时生成雪花ID
+func (s *Server) GenId(ctx context.Context, req *pb.GenIdReq) (*pb.GenIdResp, error) {
+	id, err := s.genID(req.Digits)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GenIdResp{Id: id}, nil
+}
+
+// BatchGenId 批量生成数字ID
+func (s *Server) BatchGenId(ctx context.Context, req *pb.BatchGenIdReq) (*pb.BatchGenIdResp, error) {
+	if req.Count <= 0 || req.Count > maxBatchCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count must be between 1 and %d", maxBatchCount)
+	}
+
+	ids := make([]int64, 0, req.Count)
+	for i := int32(0); i < req.Count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		id, err := s.genID(req.Digits)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return &pb.BatchGenIdResp{Ids: ids}, nil
+}
+
+// GenStringId 生成带前缀的字符串ID
+func (s *Server) GenStringId(ctx context.Context, req *pb.GenStringIdReq) (*pb.GenStringIdResp, error) {
+	var (
+		id  string
+		err error
+	)
+	if req.Base36 {
+		id, err = s.gen.GenStringIDBase36(req.Prefix)
+	} else {
+		id, err = s.gen.GenStringID(req.Prefix)
+	}
+	if err != nil {
+		return nil, genError(err)
+	}
+	return &pb.GenStringIdResp{Id: id}, nil
+}
+
+// GenInviteCode 生成邀请码
+func (s *Server) GenInviteCode(ctx context.Context, req *pb.GenInviteCodeReq) (*pb.GenInviteCodeResp, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	code, err := s.gen.GenInviteCode(req.UserId)
+	if err != nil {
+		return nil, genError(err)
+	}
+	return &pb.GenInviteCodeResp{Code: code}, nil
+}
+
+// 按位数生成ID，位数超出范围时拒绝而不是替换为默认位数
+func (s *Server) genID(digits int32) (int64, error) {
+	if digits != 0 && (digits < idgen.MinDigits || digits > idgen.MaxDigits) {
+		return 0, status.Errorf(codes.InvalidArgument, "digits must be 0 or between %d and %d", idgen.MinDigits, idgen.MaxDigits)
+	}
+
+	var (
+		id  int64
+		err error
+	)
+	if digits == 0 {
+		id, err = s.gen.GenId()
+	} else {
+		id, err = s.gen.GenIDWithDigits(int(digits))
+	}
+	if err != nil {
+		return 0, genError(err)
+	}
+	return id, nil
+}
+
+// 生成错误转换为gRPC状态，参数错误为 InvalidArgument，时钟偏差、Redis不可用和节点ID失效等可重试错误为 Unavailable
+func genError(err error) error {
+	switch {
+	case errors.Is(err, idgen.ErrInvalidPrefix):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, idgen.ErrClockDrift), errors.Is(err, idgen.ErrMonotonicUnavailable), errors.Is(err, idgen.ErrNodeIDLost):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,313 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 影子校验唯一性跟踪默认占用的内存上限
+	defaultShadowMaxMemory = 64 << 20
+	// 每个跟踪ID的估算内存，含map开销
+	shadowEntryBytes = 64
+)
+
+// Layout 雪花ID位布局：时间戳 + 节点ID位 + 序列号位，符号位固定为0
+type Layout struct {
+	// 纪元起点
+	Epoch time.Time
+	// 节点ID位数
+	NodeIDBits int
+	// 序列号位数
+	SequenceBits int
+}
+
+// CurrentLayout 当前使用的位布局
+func CurrentLayout() Layout {
+	return Layout{
+		Epoch:        time.UnixMilli(startTime).UTC(),
+		NodeIDBits:   nodeIDBits,
+		SequenceBits: sequenceBits,
+	}
+}
+
+// Validate 校验布局
+func (l Layout) Validate() error {
+	if l.NodeIDBits <= 0 || l.SequenceBits <= 0 {
+		return errors.New("layout: node id bits and sequence bits must be positive")
+	}
+	if l.timestampBits() < 31 {
+		return fmt.Errorf("layout: only %d timestamp bits left", l.timestampBits())
+	}
+	if l.Epoch.IsZero() || l.Epoch.After(time.Now()) {
+		return errors.New("layout: epoch must be set and not in the future")
+	}
+	return nil
+}
+
+// Compose 按布局组装ID
+func (l Layout) Compose(timestampMs, node, seq int64) int64 {
+	return ((timestampMs - l.Epoch.UnixMilli()) << (l.NodeIDBits + l.SequenceBits)) |
+		(node << l.SequenceBits) | seq
+}
+
+// Decompose 按布局拆解ID
+func (l Layout) Decompose(id int64) IDParts {
+	return IDParts{
+		Time:     time.UnixMilli((id >> (l.NodeIDBits + l.SequenceBits)) + l.Epoch.UnixMilli()),
+		NodeID:   (id >> l.SequenceBits) & l.nodeIDMask(),
+		Sequence: id & l.sequenceMask(),
+	}
+}
+
+// Exhausts 时间戳位耗尽的时间
+func (l Layout) Exhausts() time.Time {
+	return time.UnixMilli(l.Epoch.UnixMilli() + int64(1)<<l.timestampBits() - 1).UTC()
+}
+
+// String 布局描述
+func (l Layout) String() string {
+	return fmt.Sprintf("epoch=%s node=%d seq=%d", l.Epoch.Format(time.DateOnly), l.NodeIDBits, l.SequenceBits)
+}
+
+func (l Layout) timestampBits() int {
+	return 63 - l.NodeIDBits - l.SequenceBits
+}
+
+func (l Layout) nodeIDMask() int64 {
+	return int64(1)<<l.NodeIDBits - 1
+}
+
+func (l Layout) sequenceMask() int64 {
+	return int64(1)<<l.SequenceBits - 1
+}
+
+// ShadowConfig 影子校验配置：迁移到新布局前，对每个（抽样的）ID按新布局再生成一份，
+// 对比位数分布和唯一性，不影响返回给业务的ID
+type ShadowConfig struct {
+	// 新布局
+	Layout Layout
+	// 抽样比例 (0, 1]，默认1
+	SampleRate float64
+	// 唯一性跟踪占用的内存上限（字节），默认64MB，达到上限后丢弃较早的记录，只校验最近生成的ID
+	MaxMemory int64
+}
+
+// ShadowReport 影子校验报告
+type ShadowReport struct {
+	// 新布局
+	Layout string `json:"layout"`
+	// 新布局时间戳位耗尽时间
+	Exhausts time.Time `json:"exhausts"`
+	// 开始时间
+	Since time.Time `json:"since"`
+	// 校验的ID数
+	Samples int64 `json:"samples"`
+	// 现有布局和新布局生成ID的位数分布
+	PrimaryDigits map[int]int64 `json:"primary_digits"`
+	ShadowDigits  map[int]int64 `json:"shadow_digits"`
+	// 现有布局和新布局的重复ID数
+	PrimaryDuplicates int64 `json:"primary_duplicates"`
+	ShadowDuplicates  int64 `json:"shadow_duplicates"`
+	// 节点ID超出新布局节点位数的次数
+	NodeIDOverflow int64 `json:"node_id_overflow"`
+	// 新布局序列号用完借用下一毫秒的次数
+	SequenceOverflow int64 `json:"sequence_overflow"`
+	// 当前跟踪的ID数
+	Tracked int `json:"tracked"`
+}
+
+// shadowKey 唯一性跟踪键，按ID类型隔离
+type shadowKey struct {
+	idType string
+	id     int64
+}
+
+// shadowVerifier 影子校验器
+type shadowVerifier struct {
+	cfg   ShadowConfig
+	state atomic.Int64 // 新布局的时间戳 << 序列号位 | 序列号
+
+	mu      sync.Mutex
+	report  ShadowReport
+	primary *shadowSet
+	shadow  *shadowSet
+}
+
+// shadowSet 按新旧两代记录已生成的ID，当前代写满后替换上一代，内存占用有上限
+type shadowSet struct {
+	limit int
+	cur   map[shadowKey]struct{}
+	prev  map[shadowKey]struct{}
+}
+
+func newShadowSet(limit int) *shadowSet {
+	return &shadowSet{limit: limit, cur: make(map[shadowKey]struct{})}
+}
+
+// add 记录ID，返回是否重复
+func (s *shadowSet) add(key shadowKey) bool {
+	if _, ok := s.cur[key]; ok {
+		return true
+	}
+	if _, ok := s.prev[key]; ok {
+		return true
+	}
+	s.cur[key] = struct{}{}
+	if len(s.cur) >= s.limit {
+		s.prev, s.cur = s.cur, make(map[shadowKey]struct{})
+	}
+	return false
+}
+
+// len 当前跟踪的ID数
+func (s *shadowSet) len() int {
+	return len(s.cur) + len(s.prev)
+}
+
+// WithShadowLayout 开启影子校验：按新布局同步生成影子ID并统计，用于变更纪元或位布局前评估
+// 布局不合法时忽略并不开启
+func WithShadowLayout(cfg ShadowConfig) Option {
+	return func(x *IDGenX) {
+		if err := cfg.Layout.Validate(); err != nil {
+			return
+		}
+		if cfg.SampleRate <= 0 || cfg.SampleRate > 1 {
+			cfg.SampleRate = 1
+		}
+		if cfg.MaxMemory <= 0 {
+			cfg.MaxMemory = defaultShadowMaxMemory
+		}
+		x.shadow = newShadowVerifier(cfg)
+	}
+}
+
+func newShadowVerifier(cfg ShadowConfig) *shadowVerifier {
+	v := &shadowVerifier{cfg: cfg}
+	v.reset()
+	return v
+}
+
+// ShadowReport 返回影子校验报告，未开启时返回 false
+func (x *IDGenX) ShadowReport() (ShadowReport, bool) {
+	if x.shadow == nil {
+		return ShadowReport{}, false
+	}
+	return x.shadow.snapshot(), true
+}
+
+// ResetShadow 清空影子校验统计
+func (x *IDGenX) ResetShadow() {
+	if x.shadow != nil {
+		x.shadow.reset()
+	}
+}
+
+// 对已生成的ID做影子校验，digits 为0表示雪花ID
+func (x *IDGenX) verifyShadow(idType string, id int64, digits int) {
+	v := x.shadow
+	if v == nil || (v.cfg.SampleRate < 1 && rand.Float64() >= v.cfg.SampleRate) {
+		return
+	}
+
+	shadowID, nodeOverflow, seqOverflow := v.next()
+	if digits > 0 {
+		if x.checkDigit != CheckDigitNone {
+			shadowID = x.checkDigit.Append(localIDWithDigits(shadowID, digits-1))
+		} else {
+			shadowID = localIDWithDigits(shadowID, digits)
+		}
+	}
+
+	v.record(x.namespaced(idType), id, shadowID, nodeOverflow, seqOverflow)
+}
+
+// next 按新布局生成影子ID，序列号用完时借用下一毫秒，不阻塞主流程
+func (v *shadowVerifier) next() (id int64, nodeOverflow, seqOverflow bool) {
+	l := v.cfg.Layout
+	node := nodeID
+	if node > l.nodeIDMask() {
+		nodeOverflow = true
+		node &= l.nodeIDMask()
+	}
+
+	for {
+		old := v.state.Load()
+		lastTs, lastSeq := old>>l.SequenceBits, old&l.sequenceMask()
+
+		ts, seq := timeGen(), int64(0)
+		if ts <= lastTs {
+			ts, seq = lastTs, lastSeq+1
+			if seq > l.sequenceMask() {
+				ts, seq = lastTs+1, 0
+				seqOverflow = true
+			}
+		}
+
+		if v.state.CompareAndSwap(old, ts<<l.SequenceBits|seq) {
+			return l.Compose(ts, node, seq), nodeOverflow, seqOverflow
+		}
+	}
+}
+
+// record 记录一次对比结果
+func (v *shadowVerifier) record(idType string, primaryID, shadowID int64, nodeOverflow, seqOverflow bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r := &v.report
+	r.Samples++
+	r.PrimaryDigits[len(strconv.FormatInt(primaryID, 10))]++
+	r.ShadowDigits[len(strconv.FormatInt(shadowID, 10))]++
+	if nodeOverflow {
+		r.NodeIDOverflow++
+	}
+	if seqOverflow {
+		r.SequenceOverflow++
+	}
+
+	if v.primary.add(shadowKey{idType, primaryID}) {
+		r.PrimaryDuplicates++
+		shadowDuplicatesTotal.WithLabelValues("primary").Inc()
+	}
+	if v.shadow.add(shadowKey{idType, shadowID}) {
+		r.ShadowDuplicates++
+		shadowDuplicatesTotal.WithLabelValues("shadow").Inc()
+	}
+}
+
+// snapshot 复制当前报告
+func (v *shadowVerifier) snapshot() ShadowReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	r := v.report
+	r.PrimaryDigits = maps.Clone(v.report.PrimaryDigits)
+	r.ShadowDigits = maps.Clone(v.report.ShadowDigits)
+	r.Tracked = v.shadow.len()
+	return r
+}
+
+// reset 清空统计
+func (v *shadowVerifier) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.report = ShadowReport{
+		Layout:        v.cfg.Layout.String(),
+		Exhausts:      v.cfg.Layout.Exhausts(),
+		Since:         time.Now(),
+		PrimaryDigits: make(map[int]int64),
+		ShadowDigits:  make(map[int]int64),
+	}
+	// 内存上限由现有布局和新布局各两代平分
+	limit := int(max(v.cfg.MaxMemory/shadowEntryBytes/4, 1))
+	v.primary = newShadowSet(limit)
+	v.shadow = newShadowSet(limit)
+}
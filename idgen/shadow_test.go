@@ -0,0 +1,53 @@
+package idgen
+
+import "testing"
+
+func TestShadowSetBounded(t *testing.T) {
+	s := newShadowSet(4)
+	for i := int64(1); i <= 100; i++ {
+		if s.add(shadowKey{"order", i}) {
+			t.Fatalf("add(%d) reported duplicate", i)
+		}
+		if s.len() > 8 {
+			t.Fatalf("tracked %d ids, limit is 2 generations of 4", s.len())
+		}
+	}
+
+	tests := []struct {
+		key  shadowKey
+		want bool
+	}{
+		{shadowKey{"order", 100}, true},
+		{shadowKey{"order", 97}, true},
+		{shadowKey{"user", 100}, false},
+		// 较早的记录已被丢弃
+		{shadowKey{"order", 1}, false},
+	}
+	for _, tt := range tests {
+		if got := s.add(tt.key); got != tt.want {
+			t.Fatalf("add(%v) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestShadowVerifierMemoryBound(t *testing.T) {
+	v := newShadowVerifier(ShadowConfig{Layout: CurrentLayout(), SampleRate: 1, MaxMemory: 64 * shadowEntryBytes})
+	for i := int64(1); i <= 1000; i++ {
+		v.record("order", i, i<<1, false, false)
+	}
+	v.record("order", 1000, 1, false, false)
+
+	r := v.snapshot()
+	if r.Samples != 1001 || r.PrimaryDuplicates != 1 || r.ShadowDuplicates != 0 {
+		t.Fatalf("report = %+v", r)
+	}
+	// 64个ID的内存由两组各两代平分，每代16个
+	if r.Tracked > 32 {
+		t.Fatalf("tracked = %d, want at most 32", r.Tracked)
+	}
+
+	v.reset()
+	if r = v.snapshot(); r.Samples != 0 || r.Tracked != 0 {
+		t.Fatalf("report after reset = %+v", r)
+	}
+}
@@ -0,0 +1,203 @@
+package idgen
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 生成模式
+const (
+	// ModeRedis 通过Redis协调生成全局唯一ID
+	ModeRedis = "redis"
+	// ModeLocal 仅在本地生成，Redis未配置或最近一次Redis操作失败
+	ModeLocal = "local"
+)
+
+// 节点ID来源
+const (
+	// NodeIDSourceAllocator 由自定义分配器（如etcd）分配
+	NodeIDSourceAllocator = "allocator"
+	// NodeIDSourceRedis 由Redis分配，或从存储恢复并经Redis校验
+	NodeIDSourceRedis = "redis"
+	// NodeIDSourceStore 未配置Redis时从存储恢复
+	NodeIDSourceStore = "store"
+	// NodeIDSourceLocal 由机器ID计算，多实例间可能重复
+	NodeIDSourceLocal = "local"
+)
+
+// Status 生成器运行状态，用于探测是否已悄然退化为本地生成
+type Status struct {
+	Mode      string `json:"mode"`                // 当前生成模式 redis/local
+	Degraded  bool   `json:"degraded"`            // 配置了Redis但已退化为本地生成，或时钟偏差超限
+	NodeID    int64  `json:"node_id"`             // 当前节点ID
+	// 节点ID来源，配置了分配器或Redis但分配失败时为 local 且 NodeIDFallback 为 true
+	NodeIDSource   string `json:"node_id_source"`
+	NodeIDFallback bool   `json:"node_id_fallback"` // 节点ID分配失败后回退为本地节点ID
+	Namespace string `json:"namespace,omitempty"` // 业务命名空间
+
+	LastRedisError   string    `json:"last_redis_error,omitempty"`   // 最近一次Redis错误
+	LastRedisErrorAt time.Time `json:"last_redis_error_at,omitzero"` // 最近一次Redis错误时间
+	RedisFallbacks   int64     `json:"redis_fallbacks"`              // 累计回退本地生成的次数
+
+	ClockSkew    time.Duration `json:"clock_skew"`    // 本地时钟相对参考时钟的偏差，未开启时钟守护时为0
+	ClockDrifted bool          `json:"clock_drifted"` // 时钟偏差是否超限
+
+	Segments []SegmentStatus `json:"segments,omitempty"` // 本地缓存的号段
+}
+
+// SegmentStatus 号段使用情况
+type SegmentStatus struct {
+	Key         string  `json:"key"`          // 号段键或业务标识
+	Source      string  `json:"source"`       // 号段来源 redis/allocator
+	Used        int64   `json:"used"`         // 已使用数量
+	Size        int64   `json:"size"`         // 号段长度
+	FillPercent float64 `json:"fill_percent"` // 已使用百分比
+	Preloaded   bool    `json:"preloaded"`    // 是否已预加载下一个号段
+}
+
+// redisHealth 最近一次Redis操作的结果，节点ID和号段均为进程级状态，因此为全局
+var redisHealth struct {
+	mu        sync.RWMutex
+	failed    bool
+	lastErr   error
+	lastErrAt time.Time
+	fallbacks atomic.Int64
+}
+
+// nodeIDOrigin 节点ID的来源，与节点ID相同为进程级状态，启动时分配后不再变化，
+// 不随之后Redis操作的成败改变
+type nodeIDOrigin struct {
+	source   string
+	fallback bool
+}
+
+var currentNodeIDOrigin atomic.Pointer[nodeIDOrigin]
+
+// setNodeIDOrigin 记录节点ID来源
+func setNodeIDOrigin(source string, fallback bool) {
+	currentNodeIDOrigin.Store(&nodeIDOrigin{source: source, fallback: fallback})
+}
+
+// markRedisError 记录Redis操作失败
+func markRedisError(err error) {
+	if err == nil {
+		return
+	}
+	redisHealth.mu.Lock()
+	redisHealth.failed = true
+	redisHealth.lastErr = err
+	redisHealth.lastErrAt = time.Now()
+	redisHealth.mu.Unlock()
+}
+
+// markRedisOK 记录Redis操作成功，状态恢复为 redis 模式
+func markRedisOK() {
+	redisHealth.mu.RLock()
+	failed := redisHealth.failed
+	redisHealth.mu.RUnlock()
+	if !failed {
+		return
+	}
+
+	redisHealth.mu.Lock()
+	redisHealth.failed = false
+	redisHealth.mu.Unlock()
+}
+
+// Status 返回生成器当前运行状态
+func (x *IDGenX) Status() Status {
+	s := Status{
+		Mode:           ModeRedis,
+		NodeID:         nodeID,
+		Namespace:      x.namespace,
+		RedisFallbacks: redisHealth.fallbacks.Load(),
+	}
+
+	redisHealth.mu.RLock()
+	failed := redisHealth.failed
+	if redisHealth.lastErr != nil {
+		s.LastRedisError = redisHealth.lastErr.Error()
+		s.LastRedisErrorAt = redisHealth.lastErrAt
+	}
+	redisHealth.mu.RUnlock()
+
+	if origin := currentNodeIDOrigin.Load(); origin != nil {
+		s.NodeIDSource, s.NodeIDFallback = origin.source, origin.fallback
+	}
+
+	if x.rdb == nil || failed {
+		s.Mode = ModeLocal
+	}
+	if x.clockGuard != nil {
+		s.ClockSkew = time.Duration(x.clockGuard.drift.Load())
+		s.ClockDrifted = x.clockGuard.drifted.Load()
+	}
+	s.Degraded = (x.rdb != nil && failed) || s.NodeIDFallback || s.ClockDrifted
+	s.Segments = x.segmentStatuses()
+	return s
+}
+
+// segmentStatuses 汇总本地缓存的Redis号段和号段分配器号段
+func (x *IDGenX) segmentStatuses() []SegmentStatus {
+	var segments []SegmentStatus
+
+	counterMutex.RLock()
+	for key, seg := range serverSegments {
+		_, preloaded := pendingSegments[key]
+		segments = append(segments, newSegmentStatus(key, "redis", seg, preloaded))
+	}
+	counterMutex.RUnlock()
+
+	x.segmentBuffers.Range(func(key, value any) bool {
+		buf := value.(*segmentBuffer)
+		buf.mu.Lock()
+		if buf.current != nil {
+			segments = append(segments, newSegmentStatus(key.(string), "allocator", buf.current, buf.next != nil))
+		}
+		buf.mu.Unlock()
+		return true
+	})
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Key < segments[j].Key
+	})
+	return segments
+}
+
+// newSegmentStatus 计算号段使用情况
+func newSegmentStatus(key, source string, seg *IDSegment, preloaded bool) SegmentStatus {
+	s := SegmentStatus{
+		Key:       key,
+		Source:    source,
+		Used:      min(seg.current, seg.max),
+		Size:      seg.max,
+		Preloaded: preloaded,
+	}
+	if s.Size > 0 {
+		s.FillPercent = float64(s.Used) * 100 / float64(s.Size)
+	}
+	return s
+}
+
+// StatusHandler 返回状态查询的HTTP处理器，用于编排系统的健康检查
+// 状态退化时返回503，否则返回200，响应体均为 Status 的JSON；
+// 处理器可能暴露在公开路由上，不返回Redis错误内容，只保留错误时间，错误详情见日志或 Status
+func (x *IDGenX) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := x.Status()
+		s.LastRedisError = ""
+		code := http.StatusOK
+		if s.Degraded {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(s)
+	})
+}
@@ -0,0 +1,140 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// base62字符集
+	base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// base36字符集（大小写不敏感场景）
+	base36Chars = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// 前缀分隔符
+	stringIDSeparator = "_"
+)
+
+var (
+	// ErrInvalidStringID 字符串ID格式错误
+	ErrInvalidStringID = errors.New("invalid string id")
+	// ErrInvalidPrefix 字符串ID前缀包含分隔符
+	ErrInvalidPrefix = errors.New("invalid string id prefix")
+)
+
+// GenStringID 生成带类型前缀的base62字符串ID，如 ord_8FkL2m91Qz
+// prefix 为空时不带前缀
+func (x *IDGenX) GenStringID(prefix string) (string, error) {
+	return x.genStringID(prefix, base62Chars)
+}
+
+// GenStringIDBase36 生成带类型前缀的base36字符串ID（仅小写字母和数字）
+func (x *IDGenX) GenStringIDBase36(prefix string) (string, error) {
+	return x.genStringID(prefix, base36Chars)
+}
+
+// ParseStringID 解析base62字符串ID，返回前缀和原始ID
+func ParseStringID(s string) (string, int64, error) {
+	return parseStringID(s, base62Chars)
+}
+
+// ParseStringIDBase36 解析base36字符串ID，返回前缀和原始ID
+func ParseStringIDBase36(s string) (string, int64, error) {
+	return parseStringID(strings.ToLower(s), base36Chars)
+}
+
+// EncodeBase62 将非负整数编码为base62字符串
+func EncodeBase62(id int64) string {
+	return encodeBase(id, base62Chars)
+}
+
+// DecodeBase62 将base62字符串解码为整数
+func DecodeBase62(s string) (int64, error) {
+	return decodeBase(s, base62Chars)
+}
+
+// EncodeBase36 将非负整数编码为base36字符串
+func EncodeBase36(id int64) string {
+	return encodeBase(id, base36Chars)
+}
+
+// DecodeBase36 将base36字符串解码为整数（大小写不敏感）
+func DecodeBase36(s string) (int64, error) {
+	return decodeBase(strings.ToLower(s), base36Chars)
+}
+
+// 生成字符串ID
+func (x *IDGenX) genStringID(prefix, chars string) (string, error) {
+	if strings.Contains(prefix, stringIDSeparator) {
+		return "", fmt.Errorf("%w: %q must not contain %q", ErrInvalidPrefix, prefix, stringIDSeparator)
+	}
+
+	id, err := x.GenId()
+	if err != nil {
+		return "", err
+	}
+	if id < 0 {
+		return "", fmt.Errorf("cannot encode negative id %d", id)
+	}
+
+	encoded := encodeBase(id, chars)
+	if prefix == "" {
+		return encoded, nil
+	}
+	return prefix + stringIDSeparator + encoded, nil
+}
+
+// 解析字符串ID
+func parseStringID(s, chars string) (string, int64, error) {
+	prefix, encoded := "", s
+	if idx := strings.LastIndex(s, stringIDSeparator); idx >= 0 {
+		prefix, encoded = s[:idx], s[idx+1:]
+	}
+
+	id, err := decodeBase(encoded, chars)
+	if err != nil {
+		return "", 0, err
+	}
+	return prefix, id, nil
+}
+
+// 按字符集进制编码
+func encodeBase(id int64, chars string) string {
+	if id <= 0 {
+		return chars[:1]
+	}
+
+	base := int64(len(chars))
+	buf := make([]byte, 0, 12)
+	for id > 0 {
+		buf = append(buf, chars[id%base])
+		id /= base
+	}
+
+	// 反转
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf)
+}
+
+// 按字符集进制解码，检查溢出
+func decodeBase(s, chars string) (int64, error) {
+	if s == "" {
+		return 0, ErrInvalidStringID
+	}
+
+	base := int64(len(chars))
+	var id int64
+	for i := 0; i < len(s); i++ {
+		idx := strings.IndexByte(chars, s[i])
+		if idx < 0 {
+			return 0, fmt.Errorf("%w: unexpected character %q", ErrInvalidStringID, s[i])
+		}
+		if id > (1<<63-1-int64(idx))/base {
+			return 0, fmt.Errorf("%w: overflow", ErrInvalidStringID)
+		}
+		id = id*base + int64(idx)
+	}
+	return id, nil
+}
@@ -0,0 +1,43 @@
+package idgen
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBase62RoundTrip(t *testing.T) {
+	for _, id := range []int64{0, 1, 61, 62, 123456789, math.MaxInt64} {
+		got, err := DecodeBase62(EncodeBase62(id))
+		if err != nil || got != id {
+			t.Errorf("base62 round trip %d: got %d, %v", id, got, err)
+		}
+		got, err = DecodeBase36(EncodeBase36(id))
+		if err != nil || got != id {
+			t.Errorf("base36 round trip %d: got %d, %v", id, got, err)
+		}
+	}
+
+	if _, err := DecodeBase62("zzzzzzzzzzzzzz"); err == nil {
+		t.Error("expected overflow error")
+	}
+}
+
+func TestGenStringID(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+
+	s, err := generator.GenStringID("ord")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix, id, err := ParseStringID(s)
+	if err != nil || prefix != "ord" || id <= 0 {
+		t.Fatalf("ParseStringID(%s) = %s, %d, %v", s, prefix, id, err)
+	}
+	t.Log(s, id)
+
+	if _, err = generator.GenStringID("ord_v2"); !errors.Is(err, ErrInvalidPrefix) {
+		t.Fatalf("GenStringID with separator in prefix err = %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package idgen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenUUIDv7(t *testing.T) {
+	generator := NewIDGenX(nil, WithMachineIDProvider(StaticMachineIDProvider(1)))
+
+	prev, err := generator.GenUUIDv7()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		u, err := generator.GenUUIDv7()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Version() != 7 {
+			t.Fatalf("unexpected version %d", u.Version())
+		}
+		if bytes.Compare(u[:8], prev[:8]) <= 0 {
+			t.Fatalf("uuid not ordered: %s <= %s", u, prev)
+		}
+		prev = u
+	}
+
+	ts, err := UUIDv7Time(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := UUIDv7NodeID(prev)
+	if err != nil || node != nodeID&nodeIDMask {
+		t.Fatalf("UUIDv7NodeID = %d, %v, want %d", node, err, nodeID)
+	}
+	t.Log(prev, ts, node)
+}
@@ -0,0 +1,173 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// 默认每跳预留的时间
+	defaultHopBudget = 50 * time.Millisecond
+	// 默认调用下游所需的最少剩余时间
+	defaultMinRemaining = 10 * time.Millisecond
+)
+
+// 超时原因
+const (
+	// 请求到达时剩余时间已不足
+	deadlineCauseArrivedExpired = "arrived_expired"
+	// 本服务处理期间超时
+	deadlineCauseLocal = "local"
+	// 下游返回超时，本服务仍有剩余时间
+	deadlineCauseDownstream = "downstream"
+	// 扣除每跳预留后剩余时间不足，未调用下游
+	deadlineCauseBudgetExhausted = "budget_exhausted"
+)
+
+// DeadlineBudgetConfig 超时预算配置，调用下游时从剩余时间中扣除每跳预留，避免下游用完全部时间后本服务来不及返回，
+// 导致超时沿调用链层层放大
+type DeadlineBudgetConfig struct {
+	// 每跳预留的时间（本服务的处理、序列化和网络开销），默认50ms
+	HopBudget time.Duration `json:",optional"`
+	// 扣除预留后剩余时间低于该值时不调用下游，直接返回 DeadlineExceeded，默认10ms
+	MinRemaining time.Duration `json:",optional"`
+	// 上游未设置超时时调用下游使用的超时，为0时不设置
+	DefaultTimeout time.Duration `json:",optional"`
+}
+
+var (
+	// 当前生效的超时预算配置
+	currentDeadlineBudget atomic.Pointer[DeadlineBudgetConfig]
+
+	// 超时原因计数器
+	deadlineExceededTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rpc",
+			Subsystem: "deadline",
+			Name:      "exceeded_total",
+			Help:      "RPC超时次数（arrived_expired、local、downstream、budget_exhausted）",
+		},
+		[]string{"service", "method", "cause"},
+	)
+)
+
+func init() {
+	SetDeadlineBudget(DeadlineBudgetConfig{})
+}
+
+// SetDeadlineBudget 设置超时预算，未设置的字段使用默认值
+func SetDeadlineBudget(cfg DeadlineBudgetConfig) {
+	if cfg.HopBudget <= 0 {
+		cfg.HopBudget = defaultHopBudget
+	}
+	if cfg.MinRemaining <= 0 {
+		cfg.MinRemaining = defaultMinRemaining
+	}
+	currentDeadlineBudget.Store(&cfg)
+}
+
+// RemainingBudget 上下文的剩余时间，未设置超时时返回 false
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// DownstreamContext 调用下游使用的上下文，超时为剩余时间扣除每跳预留，上游未设置超时时使用 DefaultTimeout
+// 剩余时间不足时返回 DeadlineExceeded 状态错误，不应再调用下游；调用结束后需调用 cancel
+func DownstreamContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	cfg := currentDeadlineBudget.Load()
+
+	remaining, ok := RemainingBudget(ctx)
+	if !ok {
+		if cfg.DefaultTimeout > 0 {
+			ctx, cancel := context.WithTimeout(ctx, cfg.DefaultTimeout)
+			return ctx, cancel, nil
+		}
+		return ctx, func() {}, nil
+	}
+
+	budget := remaining - cfg.HopBudget
+	if budget < cfg.MinRemaining {
+		return ctx, func() {}, status.Errorf(codes.DeadlineExceeded,
+			"deadline budget exhausted: remaining %v, hop budget %v", remaining, cfg.HopBudget)
+	}
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	return ctx, cancel, nil
+}
+
+// DeadlineBudgetInterceptor 超时预算服务端拦截器，请求到达时剩余时间已不足则直接返回，并按原因记录超时
+func DeadlineBudgetInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	if err = checkArrivalBudget(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	resp, err = handler(ctx, req)
+	recordDeadlineExceeded(ctx, info.FullMethod, err)
+	return resp, err
+}
+
+// StreamDeadlineBudgetInterceptor 流式RPC的超时预算服务端拦截器，同 DeadlineBudgetInterceptor
+func StreamDeadlineBudgetInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	ctx := ss.Context()
+	if err := checkArrivalBudget(ctx, info.FullMethod); err != nil {
+		return err
+	}
+	err := handler(srv, ss)
+	recordDeadlineExceeded(ctx, info.FullMethod, err)
+	return err
+}
+
+// DeadlineBudgetClientInterceptor 超时预算客户端拦截器，调用下游前扣除每跳预留，剩余时间不足时不发起调用
+func DeadlineBudgetClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	downstreamCtx, cancel, err := DownstreamContext(ctx)
+	defer cancel()
+	if err != nil {
+		service, name := splitFullMethod(method)
+		deadlineExceededTotal.WithLabelValues(service, name, deadlineCauseBudgetExhausted).Inc()
+		return err
+	}
+	return invoker(downstreamCtx, method, req, reply, cc, opts...)
+}
+
+// checkArrivalBudget 请求到达时剩余时间不足 MinRemaining 时返回 DeadlineExceeded
+func checkArrivalBudget(ctx context.Context, fullMethod string) error {
+	remaining, ok := RemainingBudget(ctx)
+	if !ok || remaining >= currentDeadlineBudget.Load().MinRemaining {
+		return nil
+	}
+
+	service, method := splitFullMethod(fullMethod)
+	deadlineExceededTotal.WithLabelValues(service, method, deadlineCauseArrivedExpired).Inc()
+	logx.WithContext(ctx).Infof("request arrived with insufficient deadline: method=%s, remaining=%v", fullMethod, remaining)
+	return status.Errorf(codes.DeadlineExceeded, "deadline exceeded before processing: remaining %v", remaining)
+}
+
+// recordDeadlineExceeded 按原因记录超时：本服务上下文已超时为 local，否则为下游超时
+func recordDeadlineExceeded(ctx context.Context, fullMethod string, err error) {
+	if err == nil || (status.Code(err) != codes.DeadlineExceeded && !errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+
+	cause := deadlineCauseDownstream
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		cause = deadlineCauseLocal
+	}
+	service, method := splitFullMethod(fullMethod)
+	deadlineExceededTotal.WithLabelValues(service, method, cause).Inc()
+}
@@ -0,0 +1,137 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcMeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ImpersonationConfig 代操作拦截器配置，未配置可信调用方时代操作请求头一律不生效
+type ImpersonationConfig struct {
+	// 可信的内部调用方地址（CIDR或IP），按连接的对端地址判断，不读取转发请求头
+	TrustedPeers []string `json:",optional"`
+	// 自定义可信调用方判断，如校验mTLS证书
+	TrustPeer func(ctx context.Context) bool `json:"-"`
+}
+
+// 解析后的代操作配置
+type impersonationTrust struct {
+	prefixes  []netip.Prefix
+	trustPeer func(ctx context.Context) bool
+}
+
+// 当前生效的代操作配置
+var currentImpersonationTrust atomic.Pointer[impersonationTrust]
+
+// SetImpersonationConfig 设置代操作拦截器的可信调用方
+func SetImpersonationConfig(cfg ImpersonationConfig) error {
+	trust := &impersonationTrust{trustPeer: cfg.TrustPeer}
+	for _, s := range cfg.TrustedPeers {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(s)
+			if addrErr != nil {
+				return fmt.Errorf("invalid trusted peer %q: %w", s, err)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		trust.prefixes = append(trust.prefixes, prefix.Masked())
+	}
+	currentImpersonationTrust.Store(trust)
+	return nil
+}
+
+// ImpersonationInterceptor 代操作服务端拦截器，从请求头恢复管理员代用户操作的上下文并触发审计钩子
+// 仅在调用方为可信内部服务（见 SetImpersonationConfig），或当前已认证用户就是请求头中有代操作权限的管理员时生效，
+// 否则丢弃代操作请求头并上报安全事件
+func ImpersonationInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	if md, ok := grpcMeta.FromIncomingContext(ctx); ok {
+		adminRaw := getFirstMetadataValue(md, metadata.HeaderImpersonatorID)
+		targetRaw := getFirstMetadataValue(md, metadata.HeaderImpersonatedUserID)
+		if adminRaw != "" || targetRaw != "" {
+			ctx = grpcMeta.NewIncomingContext(ctx, stripImpersonationHeaders(md))
+
+			adminID, _ := strconv.ParseInt(adminRaw, 10, 64)
+			targetID, _ := strconv.ParseInt(targetRaw, 10, 64)
+			if adminID > 0 && targetID > 0 && impersonationAllowed(ctx, adminID) {
+				ctx = metadata.WithImpersonation(ctx, adminID, targetID)
+			} else {
+				ReportSecurityEvent(ctx, SecurityForgedImpersonation, info.FullMethod, codes.PermissionDenied,
+					fmt.Sprintf("untrusted impersonation headers: admin=%q target=%q", adminRaw, targetRaw))
+			}
+		}
+	}
+
+	metadata.AuditImpersonation(ctx, info.FullMethod)
+	return handler(ctx, req)
+}
+
+// ImpersonationClientInterceptor 代操作客户端拦截器，将代操作信息写入请求头传递给下游服务
+func ImpersonationClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	if imp, ok := metadata.GetImpersonationFromCtx(ctx); ok {
+		ctx = grpcMeta.AppendToOutgoingContext(ctx,
+			metadata.HeaderImpersonatorID, strconv.FormatInt(imp.AdminID, 10),
+			metadata.HeaderImpersonatedUserID, strconv.FormatInt(imp.TargetUserID, 10),
+		)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// impersonationAllowed 代操作请求头是否可信：调用方为可信内部服务，或已认证用户即为有权限的管理员本人
+func impersonationAllowed(ctx context.Context, adminID int64) bool {
+	if metadata.CanImpersonate(ctx) && metadata.GetUidFromCtx(ctx) == adminID {
+		return true
+	}
+
+	trust := currentImpersonationTrust.Load()
+	if trust == nil {
+		return false
+	}
+	if trust.trustPeer != nil && trust.trustPeer(ctx) {
+		return true
+	}
+	if len(trust.prefixes) == 0 {
+		return false
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trust.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripImpersonationHeaders 去掉代操作请求头，避免未生效的请求头被继续读取或转发
+func stripImpersonationHeaders(md grpcMeta.MD) grpcMeta.MD {
+	md = md.Copy()
+	delete(md, metadata.HeaderImpersonatorID)
+	delete(md, metadata.HeaderImpersonatedUserID)
+	return md
+}
@@ -21,16 +21,27 @@ import (
 func RequestInfoInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler) (resp interface{}, err error) {
 
+	// 在原上下文上追加请求信息，保留上游的超时、取消和 peer 等信息
+	newCtx := withRequestInfo(ctx, info.FullMethod)
+
+	// 继续处理请求
+	return handler(newCtx, req)
+}
+
+// withRequestInfo 从 ctx 的请求元数据中提取客户端信息、追踪ID和请求ID，写入 ctx 并打印请求日志
+func withRequestInfo(ctx context.Context, fullMethod string) context.Context {
 	md, ok := grpcMeta.FromIncomingContext(ctx)
 	if !ok {
 		md = grpcMeta.MD{}
 	}
 
 	// 生成或获取追踪ID和请求ID
-	traceID := getFirstMetadataValue(md, metadata.HeaderTraceID)
-	if traceID == "" {
-		traceID = uuid.New().String()
+	// 兼容 x-trace-id、W3C traceparent 和 B3 请求头，上游未传递时生成32位十六进制的链路ID
+	tc, ok := metadata.ExtractTraceContext(mdCarrier(md))
+	if !ok {
+		tc = metadata.TraceContext{TraceID: strings.ReplaceAll(uuid.New().String(), "-", "")}
 	}
+	traceID := tc.XTraceID()
 
 	requestID := getFirstMetadataValue(md, metadata.HeaderRequestID)
 	if requestID == "" {
@@ -56,9 +67,8 @@ func RequestInfoInterceptor(ctx context.Context, req interface{}, info *grpc.Una
 	}
 
 	// 将信息添加到上下文
-	newCtx := context.Background()
-	newCtx = metadata.WithMetadata(newCtx, metadata.CtxRequestClientInfo, clientInfo)
-	newCtx = metadata.WithMetadata(newCtx, metadata.CtxTraceID, traceID)
+	newCtx := metadata.WithMetadata(ctx, metadata.CtxRequestClientInfo, clientInfo)
+	newCtx = metadata.WithTraceContext(newCtx, tc)
 	newCtx = metadata.WithMetadata(newCtx, metadata.CtxRequestID, requestID)
 	newCtx = metadata.WithMetadata(newCtx, metadata.CtxIp, clientInfo.IP)
 	newCtx = metadata.WithMetadata(newCtx, metadata.CtxDeviceID, clientInfo.DeviceID)
@@ -77,16 +87,15 @@ func RequestInfoInterceptor(ctx context.Context, req interface{}, info *grpc.Una
 	}
 
 	// 打印请求日志（根据方法决定日志级别）
-	if shouldLogDetailed(info.FullMethod) {
+	if shouldLogDetailed(fullMethod) {
 		logx.WithContext(newCtx).Infof("[%s] RPC请求: 方法=%s, 客户端=%s, TraceID=%s",
-			requestID, info.FullMethod, clientInfo.IP, traceID)
+			requestID, fullMethod, clientInfo.IP, traceID)
 	} else {
 		logx.WithContext(newCtx).Debugf("[%s] RPC请求: 方法=%s, TraceID=%s",
-			requestID, info.FullMethod, traceID)
+			requestID, fullMethod, traceID)
 	}
 
-	// 继续处理请求
-	return handler(newCtx, req)
+	return newCtx
 }
 
 // RecoveryInterceptor 防止RPC服务因panic而崩溃的拦截器
@@ -115,19 +124,23 @@ func RecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryS
 func MetricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler) (resp interface{}, err error) {
 
-	startTime := time.Now()
+	// 被过滤的方法（如健康检查）不记录指标
+	if !shouldLogDetailed(info.FullMethod) {
+		return handler(ctx, req)
+	}
 
-	// 提取method名称，去掉包路径
-	methodName := extractMethodName(info.FullMethod)
+	// 按服务名和方法名记录
+	service, method := splitFullMethod(info.FullMethod)
+	done := trackInFlight(service, method)
+	defer done()
+
+	startTime := time.Now()
 
 	// 处理请求并收集结果
 	resp, err = handler(ctx, req)
 
-	// 计算持续时间
-	duration := time.Since(startTime).Milliseconds()
-
 	// 记录指标
-	recordMetrics(methodName, duration, err)
+	recordMetrics(service, method, time.Since(startTime), err)
 
 	return resp, err
 }
@@ -161,7 +174,6 @@ func RateLimitInterceptor(ctx context.Context, req interface{}, info *grpc.Unary
 		identifier = fmt.Sprintf("user:%d", userID)
 	}
 
-	// TODO: 实现实际的限流逻辑，这里仅为示例
 	if !checkRateLimit(identifier, info.FullMethod) {
 		return nil, status.Error(codes.ResourceExhausted,
 			"请求频率过高，请稍后再试")
@@ -178,12 +190,15 @@ func LoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnarySe
 	traceID := metadata.GetTraceIDFromCtx(ctx)
 	userID := metadata.GetUidFromCtx(ctx)
 
-	// 记录请求开始
+	// 记录请求开始，请求内容按 metadata.SetScrubPolicy 设置的策略脱敏
 	if shouldLogDetailed(info.FullMethod) {
-		logx.WithContext(ctx).Infof("[%s] 开始处理RPC请求: 方法=%s, 用户ID=%d, 请求=%+v",
-			traceID, info.FullMethod, userID, req)
+		logx.WithContext(ctx).Infof("[%s] 开始处理RPC请求: 方法=%s, 用户ID=%d, 请求=%s",
+			traceID, info.FullMethod, userID, metadata.ScrubString(req))
 	}
 
+	// 开启上下文审计时检查注入的值是否过多
+	metadata.CheckContextSize(ctx, info.FullMethod)
+
 	// 处理请求
 	resp, err = handler(ctx, req)
 
@@ -272,14 +287,9 @@ func extractMethodName(fullMethod string) string {
 	return fullMethod
 }
 
-// 辅助函数：检查是否需要详细日志
+// 辅助函数：检查是否需要详细日志，规则见 SetMethodFilter
 func shouldLogDetailed(fullMethod string) bool {
-	if strings.Contains(fullMethod, "Health") ||
-		strings.Contains(fullMethod, "Ping") ||
-		strings.Contains(fullMethod, "Metrics") {
-		return false
-	}
-	return true
+	return currentMethodFilter.Load().allow(fullMethod)
 }
 
 // 辅助函数：从元数据中获取认证Token
@@ -299,21 +309,25 @@ func getAuthToken(md grpcMeta.MD) string {
 	return ""
 }
 
-// 辅助函数：检查限流
+// 辅助函数：检查限流，未设置限流器（见 SetRateLimiter）时放行
 func checkRateLimit(identifier string, method string) bool {
-	// TODO: 实现实际的限流逻辑，这里仅为示例
-	return true
+	l := currentRateLimiter.Load()
+	if l == nil {
+		return true
+	}
+	return l.Allow(identifier, method)
 }
 
 // 创建默认拦截器链
 func CreateDefaultInterceptorChain() grpc.UnaryServerInterceptor {
 	return ChainUnaryInterceptors(
-		RecoveryInterceptor,    // 首先恢复panic
-		RequestInfoInterceptor, // 提取请求信息
-		AuthInterceptor,        // 认证信息传递
-		RateLimitInterceptor,   // 限流
-		MetricsInterceptor,     // 指标收集
-		LoggingInterceptor,     // 详细日志记录（最后执行）
+		RecoveryInterceptor,      // 首先恢复panic
+		RequestInfoInterceptor,   // 提取请求信息
+		SecurityEventInterceptor, // 安全事件记录
+		AuthInterceptor,          // 认证信息传递
+		RateLimitInterceptor,     // 限流
+		MetricsInterceptor,       // 指标收集
+		LoggingInterceptor,       // 详细日志记录（最后执行）
 	)
 }
 
@@ -0,0 +1,229 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcMeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type ctxKey struct{}
+
+func TestRequestInfoInterceptorKeepsContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctx = context.WithValue(ctx, ctxKey{}, "upstream")
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080}})
+	ctx = grpcMeta.NewIncomingContext(ctx, grpcMeta.Pairs(metadata.HeaderTraceID, "trace-1"))
+
+	deadline, _ := ctx.Deadline()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, err := RequestInfoInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+			t.Errorf("deadline = %v, %v, want %v", d, ok, deadline)
+		}
+		if v := ctx.Value(ctxKey{}); v != "upstream" {
+			t.Errorf("value = %v, want upstream", v)
+		}
+		if _, ok := peer.FromContext(ctx); !ok {
+			t.Error("peer missing")
+		}
+		if _, ok := grpcMeta.FromIncomingContext(ctx); !ok {
+			t.Error("incoming metadata missing")
+		}
+		if traceID, _ := metadata.GetMetadata[string](ctx, metadata.CtxTraceID); traceID != "trace-1" {
+			t.Errorf("trace id = %q, want trace-1", traceID)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestInfoInterceptorPropagatesCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	_, _ = RequestInfoInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		cancel()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Error("handler context not canceled")
+		}
+		return nil, nil
+	})
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if err := RegisterMetrics(reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterMetrics(reg); err != nil {
+		t.Fatalf("register twice: %v", err)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUser"}
+	_, _ = MetricsInterceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		if got := testutil.ToFloat64(requestInFlight.WithLabelValues("user.UserService", "GetUser")); got != 1 {
+			t.Errorf("in flight = %v, want 1", got)
+		}
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+
+	if got := testutil.ToFloat64(requestTotal.WithLabelValues("user.UserService", "GetUser")); got != 1 {
+		t.Errorf("total = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(requestError.WithLabelValues("user.UserService", "GetUser", codes.NotFound.String())); got != 1 {
+		t.Errorf("errors = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(requestInFlight.WithLabelValues("user.UserService", "GetUser")); got != 0 {
+		t.Errorf("in flight = %v, want 0", got)
+	}
+}
+
+func TestDeadlineBudget(t *testing.T) {
+	SetDeadlineBudget(DeadlineBudgetConfig{HopBudget: 100 * time.Millisecond, MinRemaining: 20 * time.Millisecond})
+	defer SetDeadlineBudget(DeadlineBudgetConfig{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	downstream, done, err := DownstreamContext(ctx)
+	defer done()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _ := RemainingBudget(downstream); remaining > 900*time.Millisecond {
+		t.Errorf("downstream remaining = %v, want <= 900ms", remaining)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 110*time.Millisecond)
+	defer cancelShort()
+	if _, _, err = DownstreamContext(short); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("DownstreamContext err = %v, want DeadlineExceeded", err)
+	}
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelExpired()
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/GetUser"}
+	_, err = DeadlineBudgetInterceptor(expired, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Error("handler called with insufficient deadline")
+		return nil, nil
+	})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("DeadlineBudgetInterceptor err = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestImpersonationInterceptorRejectsForgedHeaders(t *testing.T) {
+	defer currentImpersonationTrust.Store(nil)
+	if err := SetImpersonationConfig(ImpersonationConfig{TrustedPeers: []string{"10.0.0.0/8"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	call := func(ctx context.Context) (uid, admin int64) {
+		ctx = grpcMeta.NewIncomingContext(ctx, grpcMeta.Pairs(
+			metadata.HeaderImpersonatorID, "1",
+			metadata.HeaderImpersonatedUserID, "9527",
+		))
+		_, _ = ImpersonationInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			uid, admin = metadata.GetUidFromCtx(ctx), metadata.GetImpersonatorIDFromCtx(ctx)
+			if md, _ := grpcMeta.FromIncomingContext(ctx); len(md.Get(metadata.HeaderImpersonatorID)) > 0 {
+				t.Error("impersonation header not stripped")
+			}
+			return nil, nil
+		})
+		return
+	}
+	withPeer := func(ip string) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 8080}})
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantUID   int64
+		wantAdmin int64
+	}{
+		{"no peer", context.Background(), 0, 0},
+		{"external peer", withPeer("203.0.113.5"), 0, 0},
+		{"external peer with forwarded ip", grpcMeta.NewIncomingContext(withPeer("203.0.113.5"),
+			grpcMeta.Pairs(metadata.HeaderRealIP, "10.0.0.2")), 0, 0},
+		{"other authenticated user", metadata.WithMetadata(
+			metadata.WithMetadata(withPeer("203.0.113.5"), metadata.CtxJWTUserId, int64(2)),
+			metadata.CtxUserPermissions, []string{metadata.PermissionImpersonate}), 2, 0},
+		{"admin without permission", metadata.WithMetadata(withPeer("203.0.113.5"), metadata.CtxJWTUserId, int64(1)), 1, 0},
+		{"admin with permission", metadata.WithMetadata(
+			metadata.WithMetadata(withPeer("203.0.113.5"), metadata.CtxJWTUserId, int64(1)),
+			metadata.CtxUserPermissions, []string{metadata.PermissionImpersonate}), 9527, 1},
+		{"trusted peer", withPeer("10.1.2.3"), 9527, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, admin := call(tt.ctx)
+			if uid != tt.wantUID || admin != tt.wantAdmin {
+				t.Errorf("uid, admin = %d, %d, want %d, %d", uid, admin, tt.wantUID, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestSecurityEventCounting(t *testing.T) {
+	var events []SecurityEvent
+	SetSecuritySink(SecuritySinkFunc(func(ctx context.Context, event SecurityEvent) {
+		events = append(events, event)
+	}))
+	SetSecurityCounter(SecurityCounterConfig{Threshold: 3})
+	defer func() {
+		SetSecuritySink(LogxSecuritySink)
+		SetSecurityCounter(SecurityCounterConfig{})
+	}()
+
+	// 未经过请求信息拦截器时从 peer 和请求头读取
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 5000}})
+	ctx = grpcMeta.NewIncomingContext(ctx, grpcMeta.Pairs(metadata.HeaderRequestID, "req-1"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Login"}
+	for i := 0; i < 3; i++ {
+		_, _ = SecurityEventInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unauthenticated, "bad password")
+		})
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("events = %d, want 3 auth failures and 1 anomaly", len(events))
+	}
+	for i, e := range events[:3] {
+		if e.Type != SecurityAuthFailure || e.IP != "203.0.113.9" || e.RequestID != "req-1" || e.IPCount != i+1 {
+			t.Errorf("event %d = %+v", i, e)
+		}
+	}
+	if a := events[3]; a.Type != SecurityAnomaly || a.IP != "203.0.113.9" {
+		t.Errorf("anomaly = %+v", a)
+	}
+}
+
+func TestSecurityCounterMaxKeys(t *testing.T) {
+	c := &securityCounter{cfg: SecurityCounterConfig{Window: time.Minute, MaxKeys: 1}, counts: make(map[string]*securityWindow)}
+	now := time.Now()
+	if n := c.incr("ip:a", now); n != 1 {
+		t.Fatalf("incr = %d", n)
+	}
+	if n := c.incr("ip:b", now); n != 0 {
+		t.Fatalf("incr over MaxKeys = %d, want 0", n)
+	}
+	// 过期的计数被清理后可跟踪新的key
+	if n := c.incr("ip:b", now.Add(time.Minute)); n != 1 {
+		t.Fatalf("incr after window = %d", n)
+	}
+}
@@ -0,0 +1,131 @@
+package interceptor
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// 正则模式前缀，其余按glob匹配
+const regexPatternPrefix = "re:"
+
+// DefaultExcludeMethods 默认不输出详细日志和指标的方法（健康检查、探活、指标抓取）
+// 与旧版本一致，完整方法名中任意位置包含 Health、Ping、Metrics 即排除，如 PingDB、GetHealthStatus
+var DefaultExcludeMethods = []string{
+	"re:Health",
+	"re:Ping",
+	"re:Metrics",
+}
+
+// MethodFilterConfig 方法过滤配置，由 LoggingInterceptor、RequestInfoInterceptor 和 MetricsInterceptor 共享
+// 模式匹配完整方法名（如 /user.User/GetUser），支持glob（/user.User/*、/*/Ping）和正则（re:^/admin\.）
+type MethodFilterConfig struct {
+	// 包含列表，为空时包含所有方法
+	Include []string `json:",optional"`
+	// 排除列表，优先于包含列表；为nil时使用 DefaultExcludeMethods
+	Exclude []string `json:",optional"`
+}
+
+// methodPattern 单个方法匹配模式
+type methodPattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// match 是否匹配完整方法名
+func (p methodPattern) match(fullMethod string) bool {
+	if p.re != nil {
+		return p.re.MatchString(fullMethod)
+	}
+	ok, _ := path.Match(p.glob, fullMethod)
+	return ok
+}
+
+// methodFilter 编译后的方法过滤器
+type methodFilter struct {
+	include []methodPattern
+	exclude []methodPattern
+}
+
+// 当前生效的方法过滤器，支持运行时替换
+var currentMethodFilter atomic.Pointer[methodFilter]
+
+func init() {
+	f, _ := compileMethodFilter(MethodFilterConfig{})
+	currentMethodFilter.Store(f)
+}
+
+// SetMethodFilter 设置方法过滤规则，可在配置热更新回调中调用，立即对所有拦截器生效
+// 任一模式不合法时返回错误，保持原规则不变
+func SetMethodFilter(c MethodFilterConfig) error {
+	f, err := compileMethodFilter(c)
+	if err != nil {
+		return err
+	}
+	currentMethodFilter.Store(f)
+	return nil
+}
+
+// compileMethodFilter 编译方法过滤配置
+func compileMethodFilter(c MethodFilterConfig) (*methodFilter, error) {
+	exclude := c.Exclude
+	if exclude == nil {
+		exclude = DefaultExcludeMethods
+	}
+
+	include, err := compileMethodPatterns(c.Include)
+	if err != nil {
+		return nil, err
+	}
+	excludePatterns, err := compileMethodPatterns(exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &methodFilter{include: include, exclude: excludePatterns}, nil
+}
+
+// compileMethodPatterns 编译模式列表
+func compileMethodPatterns(patterns []string) ([]methodPattern, error) {
+	result := make([]methodPattern, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		if expr, ok := strings.CutPrefix(p, regexPatternPrefix); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid method pattern %q: %w", p, err)
+			}
+			result = append(result, methodPattern{re: re})
+			continue
+		}
+
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid method pattern %q: %w", p, err)
+		}
+		result = append(result, methodPattern{glob: p})
+	}
+	return result, nil
+}
+
+// allow 方法是否命中过滤规则
+func (f *methodFilter) allow(fullMethod string) bool {
+	for _, p := range f.exclude {
+		if p.match(fullMethod) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if p.match(fullMethod) {
+			return true
+		}
+	}
+	return false
+}
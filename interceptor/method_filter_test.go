@@ -0,0 +1,52 @@
+package interceptor
+
+import "testing"
+
+func TestMethodFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		config MethodFilterConfig
+		method string
+		want   bool
+	}{
+		{"default business method", MethodFilterConfig{}, "/user.User/GetUser", true},
+		{"default grpc health", MethodFilterConfig{}, "/grpc.health.v1.Health/Check", false},
+		{"default ping", MethodFilterConfig{}, "/user.User/Ping", false},
+		{"default ping db", MethodFilterConfig{}, "/user.User/PingDB", false},
+		{"default health status", MethodFilterConfig{}, "/user.User/GetHealthStatus", false},
+		{"default metrics", MethodFilterConfig{}, "/user.User/Metrics", false},
+		{"empty exclude keeps health", MethodFilterConfig{Exclude: []string{}}, "/user.User/Ping", true},
+		{"glob exclude", MethodFilterConfig{Exclude: []string{"/admin.*/*"}}, "/admin.Admin/List", false},
+		{"glob exclude other service", MethodFilterConfig{Exclude: []string{"/admin.*/*"}}, "/user.User/List", true},
+		{"include only", MethodFilterConfig{Include: []string{"/user.User/*"}}, "/order.Order/Create", false},
+		{"include match", MethodFilterConfig{Include: []string{"/user.User/*"}}, "/user.User/GetUser", true},
+		{"exclude wins", MethodFilterConfig{Include: []string{"/user.User/*"}}, "/user.User/Ping", false},
+		{"regex include", MethodFilterConfig{Include: []string{`re:^/order\.`}}, "/order.Order/Create", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := compileMethodFilter(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.allow(tt.method); got != tt.want {
+				t.Fatalf("allow(%q) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMethodFilterInvalid(t *testing.T) {
+	defer SetMethodFilter(MethodFilterConfig{})
+
+	if err := SetMethodFilter(MethodFilterConfig{Include: []string{"re:("}}); err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+	if err := SetMethodFilter(MethodFilterConfig{Exclude: []string{"[a-"}}); err == nil {
+		t.Fatal("expected error for invalid glob")
+	}
+	// 非法配置不替换原规则
+	if shouldLogDetailed("/user.User/PingDB") {
+		t.Fatal("default filter replaced by invalid config")
+	}
+}
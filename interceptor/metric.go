@@ -1,6 +1,11 @@
 package interceptor
 
 import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc/status"
 )
@@ -9,57 +14,162 @@ var (
 	// 请求总数计数器
 	requestTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Namespace: "rpc",
-			Subsystem: "requests",
-			Name:      "total",
-			Help:      "RPC请求总数",
+			Name: metadata.MetricKeyRequestTotal,
+			Help: "RPC请求总数",
 		},
-		[]string{"method"},
+		[]string{"service", "method"},
 	)
 
 	// 请求持续时间直方图
 	requestDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Namespace: "rpc",
-			Subsystem: "requests",
-			Name:      "duration_ms",
-			Help:      "RPC请求处理时间（毫秒）",
-			Buckets:   []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000},
+			Name:    metadata.MetricKeyRequestDuration,
+			Help:    "RPC请求处理时间（毫秒）",
+			Buckets: []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000},
 		},
-		[]string{"method"},
+		[]string{"service", "method"},
 	)
 
 	// 错误请求计数器
 	requestError = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
+			Name: metadata.MetricKeyRequestError,
+			Help: "RPC请求错误总数",
+		},
+		[]string{"service", "method", "code"},
+	)
+
+	// 处理中的请求数，包括流
+	requestInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: metadata.MetricKeyRequestInFlight,
+			Help: "处理中的RPC请求数",
+		},
+		[]string{"service", "method"},
+	)
+
+	// 流持续时间直方图
+	streamDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
 			Namespace: "rpc",
-			Subsystem: "requests",
-			Name:      "error_total",
-			Help:      "RPC请求错误总数",
+			Subsystem: "streams",
+			Name:      "duration_seconds",
+			Help:      "RPC流持续时间（秒）",
+			Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600},
 		},
-		[]string{"method", "code"},
+		[]string{"service", "method"},
 	)
+
+	// 流消息计数器
+	streamMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rpc",
+			Subsystem: "streams",
+			Name:      "messages_total",
+			Help:      "RPC流收发消息总数",
+		},
+		[]string{"service", "method", "direction"},
+	)
+
+	// 所有指标
+	allCollectors = []prometheus.Collector{
+		requestTotal,
+		requestDuration,
+		requestError,
+		requestInFlight,
+		securityEventTotal,
+		securityAnomalyTotal,
+		rateLimitDecisions,
+		deadlineExceededTotal,
+		streamDuration,
+		streamMessages,
+	}
 )
 
-// InitMetrics 初始化所有指标
+// metricsCollector 汇总拦截器的所有指标
+type metricsCollector struct{}
+
+// Describe 实现 prometheus.Collector 接口
+func (metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range allCollectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range allCollectors {
+		c.Collect(ch)
+	}
+}
+
+// MetricsCollector 返回拦截器指标收集器，由业务方注册到自己的 Prometheus registry
+func MetricsCollector() prometheus.Collector {
+	return metricsCollector{}
+}
+
+// RegisterMetrics 将拦截器指标注册到 reg，已注册时忽略，reg 为空时使用默认 registry
+func RegisterMetrics(reg prometheus.Registerer) error {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(MetricsCollector()); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// InitMetrics 初始化所有指标，注册到默认 registry
 func InitMetrics() {
-	// 注册所有指标
-	prometheus.MustRegister(requestTotal)
-	prometheus.MustRegister(requestDuration)
-	prometheus.MustRegister(requestError)
+	if err := RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		panic(err)
+	}
+}
+
+// splitFullMethod 拆分 /package.Service/Method 为服务名和方法名
+func splitFullMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if idx := strings.LastIndex(fullMethod, "/"); idx >= 0 {
+		return fullMethod[:idx], fullMethod[idx+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// trackInFlight 处理中的请求数加一，返回减一的函数
+func trackInFlight(service, method string) func() {
+	g := requestInFlight.WithLabelValues(service, method)
+	g.Inc()
+	return g.Dec
 }
 
 // 指标收集函数
-func recordMetrics(method string, duration int64, err error) {
+func recordMetrics(service, method string, duration time.Duration, err error) {
 	// 增加请求计数
-	requestTotal.WithLabelValues(method).Inc()
+	requestTotal.WithLabelValues(service, method).Inc()
 
 	// 记录请求持续时间
-	requestDuration.WithLabelValues(method).Observe(float64(duration))
+	requestDuration.WithLabelValues(service, method).Observe(float64(duration) / float64(time.Millisecond))
 
 	// 如果有错误，记录错误计数
 	if err != nil {
 		code := status.Code(err).String()
-		requestError.WithLabelValues(method, code).Inc()
+		requestError.WithLabelValues(service, method, code).Inc()
+	}
+}
+
+// 流指标收集函数，请求数和错误数与一元RPC共用指标
+func recordStreamMetrics(service, method string, duration time.Duration, recv, sent int64, err error) {
+	requestTotal.WithLabelValues(service, method).Inc()
+	streamDuration.WithLabelValues(service, method).Observe(duration.Seconds())
+	streamMessages.WithLabelValues(service, method, "recv").Add(float64(recv))
+	streamMessages.WithLabelValues(service, method, "sent").Add(float64(sent))
+
+	if err != nil {
+		code := status.Code(err).String()
+		requestError.WithLabelValues(service, method, code).Inc()
 	}
 }
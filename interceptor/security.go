@@ -0,0 +1,293 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcMeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// SecurityEventType 安全事件类型
+type SecurityEventType string
+
+const (
+	// SecurityAuthFailure 未携带凭证或认证失败
+	SecurityAuthFailure SecurityEventType = "auth_failure"
+	// SecurityInvalidToken 携带了无效的Token
+	SecurityInvalidToken SecurityEventType = "invalid_token"
+	// SecurityPermissionDenied 权限不足
+	SecurityPermissionDenied SecurityEventType = "permission_denied"
+	// SecurityRateLimited 触发限流
+	SecurityRateLimited SecurityEventType = "rate_limited"
+	// SecurityForgedImpersonation 来自不可信调用方的代操作请求头
+	SecurityForgedImpersonation SecurityEventType = "forged_impersonation"
+	// SecurityAnomaly 同一IP或用户在统计窗口内的安全事件数达到阈值
+	SecurityAnomaly SecurityEventType = "anomaly"
+)
+
+const (
+	// 默认统计窗口
+	defaultSecurityWindow = time.Minute
+	// 默认最多跟踪的IP和用户数
+	defaultSecurityMaxKeys = 10000
+)
+
+// SecurityCounterConfig 按IP和用户统计安全事件的配置
+type SecurityCounterConfig struct {
+	Window    time.Duration // 统计窗口，默认1分钟
+	Threshold int           // 同一IP或用户在窗口内的事件数达到阈值时上报 SecurityAnomaly 事件，0 表示不上报
+	MaxKeys   int           // 最多跟踪的IP和用户数，默认10000，超出时不统计新的IP和用户
+}
+
+// SecurityEvent 结构化安全事件
+type SecurityEvent struct {
+	Type      SecurityEventType `json:"type"`
+	Method    string            `json:"method"`
+	IP        string            `json:"ip"`
+	UserID    int64             `json:"user_id,omitempty"`
+	DeviceID  string            `json:"device_id,omitempty"`
+	TraceID   string            `json:"trace_id,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Code      string            `json:"code"`
+	Message   string            `json:"message,omitempty"`
+	Time      int64             `json:"time"`
+	IPCount   int               `json:"ip_count,omitempty"`   // 同一IP在统计窗口内的安全事件数
+	UserCount int               `json:"user_count,omitempty"` // 同一用户在统计窗口内的安全事件数
+}
+
+// SecuritySink 安全事件输出，可对接SIEM、消息队列等
+type SecuritySink interface {
+	Emit(ctx context.Context, event SecurityEvent)
+}
+
+// SecuritySinkFunc 函数适配器
+type SecuritySinkFunc func(ctx context.Context, event SecurityEvent)
+
+// Emit 实现 SecuritySink 接口
+func (f SecuritySinkFunc) Emit(ctx context.Context, event SecurityEvent) {
+	f(ctx, event)
+}
+
+// LogxSecuritySink 默认输出：以结构化字段写入日志
+var LogxSecuritySink SecuritySink = SecuritySinkFunc(func(ctx context.Context, event SecurityEvent) {
+	logx.WithContext(ctx).Infow("security event",
+		logx.Field("type", event.Type),
+		logx.Field("method", event.Method),
+		logx.Field("ip", event.IP),
+		logx.Field("user_id", event.UserID),
+		logx.Field("device_id", event.DeviceID),
+		logx.Field("request_id", event.RequestID),
+		logx.Field("code", event.Code),
+		logx.Field("message", event.Message),
+		logx.Field("ip_count", event.IPCount),
+		logx.Field("user_count", event.UserCount),
+	)
+})
+
+var (
+	securitySink   = LogxSecuritySink
+	securitySinkMu sync.RWMutex
+
+	// 安全事件计数器
+	securityEventTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rpc",
+			Subsystem: "security",
+			Name:      "events_total",
+			Help:      "RPC安全事件总数",
+		},
+		[]string{"type", "method"},
+	)
+
+	// 异常IP和用户计数器
+	securityAnomalyTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "rpc",
+			Subsystem: "security",
+			Name:      "anomalies_total",
+			Help:      "统计窗口内安全事件数达到阈值的IP和用户数",
+		},
+		[]string{"subject"},
+	)
+
+	// 按IP和用户的安全事件计数
+	securityCounts atomic.Pointer[securityCounter]
+)
+
+func init() {
+	SetSecurityCounter(SecurityCounterConfig{})
+}
+
+// securityCounter 按IP和用户的固定窗口计数
+type securityCounter struct {
+	cfg    SecurityCounterConfig
+	mu     sync.Mutex
+	counts map[string]*securityWindow
+}
+
+type securityWindow struct {
+	start time.Time
+	n     int
+}
+
+// SetSecurityCounter 设置按IP和用户统计安全事件的窗口和告警阈值，重新设置时清空已有计数
+func SetSecurityCounter(cfg SecurityCounterConfig) {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSecurityWindow
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = defaultSecurityMaxKeys
+	}
+	securityCounts.Store(&securityCounter{cfg: cfg, counts: make(map[string]*securityWindow)})
+}
+
+// incr 累加 key 在当前窗口的事件数，返回累加后的值，跟踪数已满时返回0
+func (c *securityCounter) incr(key string, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w, ok := c.counts[key]
+	if ok && now.Sub(w.start) >= c.cfg.Window {
+		w.start, w.n = now, 0
+	}
+	if !ok {
+		if len(c.counts) >= c.cfg.MaxKeys {
+			// 清理已过窗口的计数后再尝试
+			for k, v := range c.counts {
+				if now.Sub(v.start) >= c.cfg.Window {
+					delete(c.counts, k)
+				}
+			}
+			if len(c.counts) >= c.cfg.MaxKeys {
+				return 0
+			}
+		}
+		w = &securityWindow{start: now}
+		c.counts[key] = w
+	}
+	w.n++
+	return w.n
+}
+
+// SetSecuritySink 设置安全事件输出，可传入多个同时输出
+func SetSecuritySink(sinks ...SecuritySink) {
+	securitySinkMu.Lock()
+	defer securitySinkMu.Unlock()
+
+	if len(sinks) == 1 {
+		securitySink = sinks[0]
+		return
+	}
+
+	securitySink = SecuritySinkFunc(func(ctx context.Context, event SecurityEvent) {
+		for _, s := range sinks {
+			if s != nil {
+				s.Emit(ctx, event)
+			}
+		}
+	})
+}
+
+// ReportSecurityEvent 上报安全事件，业务中自行检测到异常时也可直接调用
+func ReportSecurityEvent(ctx context.Context, eventType SecurityEventType, method string, code codes.Code, message string) {
+	event := SecurityEvent{
+		Type:      eventType,
+		Method:    method,
+		IP:        metadata.GetMetadataOrDefault(ctx, metadata.CtxIp, ""),
+		UserID:    metadata.GetUidFromCtx(ctx),
+		DeviceID:  metadata.GetMetadataOrDefault(ctx, metadata.CtxDeviceID, ""),
+		TraceID:   metadata.GetTraceIDFromCtx(ctx),
+		RequestID: metadata.GetMetadataOrDefault(ctx, metadata.CtxRequestID, ""),
+		Code:      code.String(),
+		Message:   message,
+		Time:      time.Now().UnixMilli(),
+	}
+	// 请求信息拦截器未执行时从请求元数据中读取
+	if event.IP == "" {
+		event.IP = getClientIP(ctx, nil)
+	}
+	if md, ok := grpcMeta.FromIncomingContext(ctx); ok {
+		if event.DeviceID == "" {
+			event.DeviceID = getFirstMetadataValue(md, metadata.HeaderDeviceID)
+		}
+		if event.RequestID == "" {
+			event.RequestID = getFirstMetadataValue(md, metadata.HeaderRequestID)
+		}
+	}
+
+	securityEventTotal.WithLabelValues(string(eventType), extractMethodName(method)).Inc()
+
+	counter := securityCounts.Load()
+	now := time.Now()
+	if event.IP != "" {
+		event.IPCount = counter.incr("ip:"+event.IP, now)
+	}
+	if event.UserID > 0 {
+		event.UserCount = counter.incr("user:"+strconv.FormatInt(event.UserID, 10), now)
+	}
+
+	securitySinkMu.RLock()
+	sink := securitySink
+	securitySinkMu.RUnlock()
+
+	if sink == nil {
+		return
+	}
+	sink.Emit(ctx, event)
+
+	// 达到阈值时上报一次异常事件
+	if threshold := counter.cfg.Threshold; threshold > 0 {
+		if event.IPCount == threshold {
+			securityAnomalyTotal.WithLabelValues("ip").Inc()
+			sink.Emit(ctx, anomalyEvent(event, fmt.Sprintf("ip %s triggered %d security events in %v", event.IP, threshold, counter.cfg.Window)))
+		}
+		if event.UserCount == threshold {
+			securityAnomalyTotal.WithLabelValues("user").Inc()
+			sink.Emit(ctx, anomalyEvent(event, fmt.Sprintf("user %d triggered %d security events in %v", event.UserID, threshold, counter.cfg.Window)))
+		}
+	}
+}
+
+// anomalyEvent 由触发阈值的事件生成异常事件
+func anomalyEvent(event SecurityEvent, message string) SecurityEvent {
+	event.Type = SecurityAnomaly
+	event.Message = message
+	return event
+}
+
+// SecurityEventInterceptor 根据处理结果的状态码记录安全事件
+func SecurityEventInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	resp, err = handler(ctx, req)
+	if err == nil {
+		return resp, err
+	}
+
+	st, _ := status.FromError(err)
+	switch st.Code() {
+	case codes.Unauthenticated:
+		// 携带了Token仍认证失败，视为无效Token
+		eventType := SecurityAuthFailure
+		if md, ok := grpcMeta.FromIncomingContext(ctx); ok && getAuthToken(md) != "" {
+			eventType = SecurityInvalidToken
+		}
+		ReportSecurityEvent(ctx, eventType, info.FullMethod, st.Code(), st.Message())
+	case codes.PermissionDenied:
+		ReportSecurityEvent(ctx, SecurityPermissionDenied, info.FullMethod, st.Code(), st.Message())
+	case codes.ResourceExhausted:
+		ReportSecurityEvent(ctx, SecurityRateLimited, info.FullMethod, st.Code(), st.Message())
+	}
+
+	return resp, err
+}
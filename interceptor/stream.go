@@ -0,0 +1,180 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcMeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contextStream 替换上下文的服务端流
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的上下文
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// WrapServerStream 返回使用 ctx 作为上下文的服务端流，用于在流拦截器中向处理函数传递上下文
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &contextStream{ServerStream: ss, ctx: ctx}
+}
+
+// countingStream 统计收发消息数的服务端流
+type countingStream struct {
+	grpc.ServerStream
+	recv atomic.Int64
+	sent atomic.Int64
+}
+
+// RecvMsg 接收消息并计数
+func (s *countingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.recv.Add(1)
+	}
+	return err
+}
+
+// SendMsg 发送消息并计数
+func (s *countingStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+	return err
+}
+
+// StreamRequestInfoInterceptor 流式RPC的请求信息拦截器，同 RequestInfoInterceptor，保留流的取消和超时
+func StreamRequestInfoInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	ctx := ss.Context()
+	return handler(srv, WrapServerStream(ss, withRequestInfo(ctx, info.FullMethod)))
+}
+
+// StreamRecoveryInterceptor 流式RPC的panic恢复拦截器
+func StreamRecoveryInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) (err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			ctx := ss.Context()
+			traceID := metadata.GetTraceIDFromCtx(ctx)
+
+			logx.WithContext(ctx).Errorf("[PANIC RECOVERED] TraceID=%s, Method=%s\nError: %v\nStack: %s",
+				traceID, info.FullMethod, r, debug.Stack())
+
+			err = status.Errorf(codes.Internal,
+				"服务器内部错误，请稍后重试 (TraceID: %s)", traceID)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// StreamAuthInterceptor 流式RPC的认证拦截器，将认证Token写入上下文
+func StreamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	ctx := ss.Context()
+	md, ok := grpcMeta.FromIncomingContext(ctx)
+	if !ok {
+		md = grpcMeta.MD{}
+	}
+
+	ctx = metadata.WithMetadata(ctx, metadata.CtxToken, getAuthToken(md))
+	return handler(srv, WrapServerStream(ss, ctx))
+}
+
+// StreamLoggingInterceptor 流式RPC的日志拦截器，记录流的开始、结束、耗时和收发消息数，不记录消息内容
+func StreamLoggingInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	ctx := ss.Context()
+	startTime := time.Now()
+	traceID := metadata.GetTraceIDFromCtx(ctx)
+	userID := metadata.GetUidFromCtx(ctx)
+	detailed := shouldLogDetailed(info.FullMethod)
+
+	if detailed {
+		logx.WithContext(ctx).Infof("[%s] 开始处理RPC流: 方法=%s, 用户ID=%d, 客户端流=%v, 服务端流=%v",
+			traceID, info.FullMethod, userID, info.IsClientStream, info.IsServerStream)
+	}
+
+	metadata.CheckContextSize(ctx, info.FullMethod)
+
+	cs := &countingStream{ServerStream: ss}
+	err := handler(srv, cs)
+
+	duration := time.Since(startTime)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("[%s] RPC流失败: 方法=%s, 用户ID=%d, 耗时=%v, 接收=%d, 发送=%d, 错误=%v",
+			traceID, info.FullMethod, userID, duration, cs.recv.Load(), cs.sent.Load(), err)
+	} else if detailed {
+		logx.WithContext(ctx).Infof("[%s] RPC流结束: 方法=%s, 用户ID=%d, 耗时=%v, 接收=%d, 发送=%d",
+			traceID, info.FullMethod, userID, duration, cs.recv.Load(), cs.sent.Load())
+	}
+
+	return err
+}
+
+// StreamMetricsInterceptor 流式RPC的指标拦截器，记录请求数、错误数、流持续时间和收发消息数
+func StreamMetricsInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	if !shouldLogDetailed(info.FullMethod) {
+		return handler(srv, ss)
+	}
+
+	service, method := splitFullMethod(info.FullMethod)
+	done := trackInFlight(service, method)
+	defer done()
+
+	startTime := time.Now()
+	cs := &countingStream{ServerStream: ss}
+	err := handler(srv, cs)
+
+	recordStreamMetrics(service, method, time.Since(startTime), cs.recv.Load(), cs.sent.Load(), err)
+	return err
+}
+
+// CreateDefaultStreamInterceptorChain 创建默认流拦截器链，顺序与 CreateDefaultInterceptorChain 一致
+func CreateDefaultStreamInterceptorChain() grpc.StreamServerInterceptor {
+	return ChainStreamInterceptors(
+		StreamRecoveryInterceptor,    // 首先恢复panic
+		StreamRequestInfoInterceptor, // 提取请求信息
+		StreamAuthInterceptor,        // 认证信息传递
+		StreamMetricsInterceptor,     // 指标收集
+		StreamLoggingInterceptor,     // 详细日志记录（最后执行）
+	)
+}
+
+// ChainStreamInterceptors 链式组合多个流拦截器
+func ChainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		buildChain := func(current grpc.StreamServerInterceptor, next grpc.StreamHandler) grpc.StreamHandler {
+			return func(currentSrv interface{}, currentStream grpc.ServerStream) error {
+				return current(currentSrv, currentStream, info, next)
+			}
+		}
+
+		chain := handler
+		// 反向遍历以保持正确的执行顺序
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chain = buildChain(interceptors[i], chain)
+		}
+
+		return chain(srv, ss)
+	}
+}
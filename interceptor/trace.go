@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"google.golang.org/grpc"
+	grpcMeta "google.golang.org/grpc/metadata"
+)
+
+// TraceClientInterceptor 链路客户端拦截器，将上下文中的链路ID以 x-trace-id、traceparent 和 B3 请求头传递给下游，
+// 兼容只识别其中一种格式的旧服务；已由 OpenTelemetry 写入 traceparent 时不覆盖
+func TraceClientInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+	return invoker(withOutgoingTrace(ctx), method, req, reply, cc, opts...)
+}
+
+// TraceStreamClientInterceptor 流式RPC的链路客户端拦截器，同 TraceClientInterceptor
+func TraceStreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+	method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+	return streamer(withOutgoingTrace(ctx), desc, cc, method, opts...)
+}
+
+// withOutgoingTrace 将链路请求头写入 outgoing metadata
+func withOutgoingTrace(ctx context.Context) context.Context {
+	tc, ok := metadata.GetTraceContextFromCtx(ctx)
+	if !ok {
+		return ctx
+	}
+
+	md, _ := grpcMeta.FromOutgoingContext(ctx)
+	if len(md.Get(metadata.HeaderTraceparent)) > 0 {
+		return ctx
+	}
+	md = md.Copy()
+	// 下游的父 span 为本服务，不沿用上游的 span ID
+	tc.SpanID = ""
+	metadata.InjectTraceContext(mdCarrier(md), tc)
+	return grpcMeta.NewOutgoingContext(ctx, md)
+}
+
+// mdCarrier 适配 gRPC metadata 为 metadata.TraceCarrier
+type mdCarrier grpcMeta.MD
+
+// Get 获取第一个值
+func (c mdCarrier) Get(key string) string {
+	return getFirstMetadataValue(grpcMeta.MD(c), key)
+}
+
+// Set 设置值
+func (c mdCarrier) Set(key, value string) {
+	grpcMeta.MD(c).Set(key, value)
+}
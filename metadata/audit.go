@@ -0,0 +1,271 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认值数量告警阈值
+	defaultAuditMaxValues = 32
+	// 默认估算大小告警阈值（字节）
+	defaultAuditMaxBytes = 16 << 10
+	// 估算值大小时的最大递归深度
+	maxSizeDepth = 8
+)
+
+// AuditConfig 上下文审计配置，仅建议在开发/测试环境开启
+type AuditConfig struct {
+	MaxValues int                                        // 值数量超过该阈值时告警
+	MaxBytes  int                                        // 估算大小超过该阈值时告警
+	OnExceed  func(ctx context.Context, a *ContextAudit) // 超限回调，为空时写日志
+}
+
+// ContextKeyStat 单个上下文键的统计
+type ContextKeyStat struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`  // 值类型
+	Bytes int    `json:"bytes"` // 估算大小
+	Count int    `json:"count"` // 出现次数，大于1说明被重复注入
+}
+
+// ContextAudit 上下文审计结果
+type ContextAudit struct {
+	Label  string           `json:"label"`
+	Depth  int              `json:"depth"`  // 上下文链深度
+	Values int              `json:"values"` // 注入的值数量（含重复）
+	Bytes  int              `json:"bytes"`  // 估算总大小
+	Keys   []ContextKeyStat `json:"keys"`   // 按大小倒序
+}
+
+var (
+	auditEnabled atomic.Bool
+	auditMu      sync.RWMutex
+	auditConfig  = AuditConfig{MaxValues: defaultAuditMaxValues, MaxBytes: defaultAuditMaxBytes}
+)
+
+func init() {
+	// 开发环境可通过环境变量开启
+	if os.Getenv("METADATA_AUDIT") == "true" {
+		auditEnabled.Store(true)
+	}
+}
+
+// EnableContextAudit 开启上下文审计，零值阈值使用默认值
+func EnableContextAudit(cfg AuditConfig) {
+	if cfg.MaxValues <= 0 {
+		cfg.MaxValues = defaultAuditMaxValues
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultAuditMaxBytes
+	}
+
+	auditMu.Lock()
+	auditConfig = cfg
+	auditMu.Unlock()
+	auditEnabled.Store(true)
+}
+
+// DisableContextAudit 关闭上下文审计
+func DisableContextAudit() {
+	auditEnabled.Store(false)
+}
+
+// CheckContextSize 审计上下文并在超过阈值时告警，未开启审计时返回nil
+// label 用于标识调用位置，如路由或RPC方法名
+func CheckContextSize(ctx context.Context, label string) *ContextAudit {
+	if !auditEnabled.Load() || ctx == nil {
+		return nil
+	}
+
+	a := AuditContext(ctx)
+	a.Label = label
+
+	auditMu.RLock()
+	cfg := auditConfig
+	auditMu.RUnlock()
+
+	if a.Values <= cfg.MaxValues && a.Bytes <= cfg.MaxBytes {
+		return a
+	}
+
+	if cfg.OnExceed != nil {
+		cfg.OnExceed(ctx, a)
+		return a
+	}
+
+	top := a.Keys
+	if len(top) > 5 {
+		top = top[:5]
+	}
+	logx.WithContext(ctx).Infow("context size exceeds threshold",
+		logx.Field("label", label),
+		logx.Field("values", a.Values),
+		logx.Field("bytes", a.Bytes),
+		logx.Field("depth", a.Depth),
+		logx.Field("top_keys", top),
+	)
+	return a
+}
+
+// AuditContext 遍历上下文链，统计通过 WithValue 注入的所有值
+// 依赖标准库context的内部结构，仅用于排查问题，不要在热路径中调用
+func AuditContext(ctx context.Context) *ContextAudit {
+	a := &ContextAudit{}
+	stats := make(map[string]*ContextKeyStat)
+
+	cur := reflect.ValueOf(ctx)
+	for cur.IsValid() {
+		// 解开接口和指针
+		for cur.Kind() == reflect.Interface || cur.Kind() == reflect.Pointer {
+			if cur.IsNil() {
+				cur = reflect.Value{}
+				break
+			}
+			cur = cur.Elem()
+		}
+		if !cur.IsValid() || cur.Kind() != reflect.Struct {
+			break
+		}
+		a.Depth++
+
+		// context.valueCtx { Context; key, val any }
+		if key, val := cur.FieldByName("key"), cur.FieldByName("val"); key.IsValid() && val.IsValid() {
+			name, typ := describeKey(key), describeType(val)
+			size := estimateSize(val, 0, make(map[uintptr]bool))
+
+			a.Values++
+			a.Bytes += size
+			if s, ok := stats[name]; ok {
+				s.Count++
+				s.Bytes += size
+			} else {
+				stats[name] = &ContextKeyStat{Key: name, Type: typ, Bytes: size, Count: 1}
+			}
+		}
+
+		cur = parentContext(cur)
+	}
+
+	a.Keys = make([]ContextKeyStat, 0, len(stats))
+	for _, s := range stats {
+		a.Keys = append(a.Keys, *s)
+	}
+	sort.Slice(a.Keys, func(i, j int) bool {
+		if a.Keys[i].Bytes != a.Keys[j].Bytes {
+			return a.Keys[i].Bytes > a.Keys[j].Bytes
+		}
+		return a.Keys[i].Key < a.Keys[j].Key
+	})
+	return a
+}
+
+// contextType context.Context 接口类型
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// parentContext 查找结构体中的父上下文（嵌入的 Context 或 cancelCtx 等）
+func parentContext(v reflect.Value) reflect.Value {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Type() == contextType {
+			return f
+		}
+	}
+
+	// timerCtx 等通过嵌入的结构体间接持有父上下文
+	for i := 0; i < v.NumField(); i++ {
+		if sf := v.Type().Field(i); sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if p := parentContext(v.Field(i)); p.IsValid() {
+				return p
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// describeKey 键的可读名称
+func describeKey(v reflect.Value) string {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	return v.Type().String()
+}
+
+// describeType 值的类型名称
+func describeType(v reflect.Value) string {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+		return "<nil>"
+	}
+	return v.Type().String()
+}
+
+// estimateSize 粗略估算值占用的字节数
+func estimateSize(v reflect.Value, depth int, seen map[uintptr]bool) int {
+	if !v.IsValid() || depth > maxSizeDepth {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return estimateSize(v.Elem(), depth+1, seen)
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return int(v.Type().Size())
+		}
+		seen[v.Pointer()] = true
+		return int(v.Type().Size()) + estimateSize(v.Elem(), depth+1, seen)
+	case reflect.String:
+		return int(v.Type().Size()) + v.Len()
+	case reflect.Slice:
+		if v.IsNil() {
+			return int(v.Type().Size())
+		}
+		size := int(v.Type().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += estimateSize(v.Index(i), depth+1, seen)
+		}
+		return size
+	case reflect.Array:
+		size := 0
+		for i := 0; i < v.Len(); i++ {
+			size += estimateSize(v.Index(i), depth+1, seen)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() {
+			return int(v.Type().Size())
+		}
+		size := int(v.Type().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateSize(iter.Key(), depth+1, seen) + estimateSize(iter.Value(), depth+1, seen)
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateSize(v.Field(i), depth+1, seen)
+		}
+		return size
+	default:
+		return int(v.Type().Size())
+	}
+}
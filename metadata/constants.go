@@ -1,5 +1,10 @@
 package metadata
 
+import (
+	"slices"
+	"sync"
+)
+
 // Header keys
 const (
 	// Standard headers (标准 HTTP 头,使用大写)
@@ -29,6 +34,19 @@ const (
 	HeaderClientIP             = "x-client-ip"
 	HeaderCFConnectingIP       = "x-cf-connecting-ip"
 	HeaderToken                = "x-token"
+
+	// 链路追踪：W3C Trace Context 和 Zipkin B3（单头和多头）
+	HeaderTraceparent    = "traceparent"
+	HeaderB3             = "b3"
+	HeaderB3TraceID      = "x-b3-traceid"
+	HeaderB3SpanID       = "x-b3-spanid"
+	HeaderB3ParentSpanID = "x-b3-parentspanid"
+	HeaderB3Sampled      = "x-b3-sampled"
+	HeaderB3Flags        = "x-b3-flags"
+
+	// 代操作：执行操作的管理员ID和被代理的用户ID
+	HeaderImpersonatorID     = "x-impersonator-id"
+	HeaderImpersonatedUserID = "x-impersonated-user-id"
 )
 
 // Context keys
@@ -57,6 +75,7 @@ const (
 	CtxTimezone           = "timezone"            // 时区
 	CtxSessionID          = "session_id"          // 会话ID
 	CtxTraceID            = "trace_id"            // 追踪ID
+	CtxTraceContext       = "trace_context"       // 链路上下文（TraceContext）
 	CtxRequestID          = "request_id"          // 请求ID
 	CtxRequestTime        = "request_time"        // 请求时间
 
@@ -66,11 +85,69 @@ const (
 	CtxToken           = "token"            // Token
 	CtxTokenExpiry     = "token_expiry"     // Token过期时间
 	CtxIssuer          = "issuer"           // Token颁发者
+
+	// Impersonation related
+	CtxImpersonatorID = "impersonator_id" // 代操作的管理员ID
+)
+
+var (
+	// 所有已知的上下文键，用于导出和审计
+	knownKeys = []string{
+		CtxJWTUserId, CtxJWTUsername, CtxUserRoleCode, CtxUserRoleID, CtxUserPermissions,
+		CtxUserStatus, CtxUserLastLoginTime, CtxUserAgentId, CtxUserParentAgentId,
+		CtxIp, CtxDomain, CtxRegion, CtxDeviceID, CtxDeviceType, CtxBrowserFingerprint,
+		CtxCurrencyCode, CtxRequestClientInfo, CtxLanguage, CtxTimezone, CtxSessionID,
+		CtxTraceID, CtxTraceContext, CtxRequestID, CtxRequestTime,
+		CtxIsAuthenticated, CtxAuthType, CtxToken, CtxTokenExpiry, CtxIssuer,
+		CtxImpersonatorID,
+	}
+	// 敏感的上下文键，ExportMetadataToMap 默认不导出，需要时显式传入
+	sensitiveKeys = []string{CtxToken, CtxTokenExpiry, CtxSessionID, CtxBrowserFingerprint}
+	knownKeysMu   sync.RWMutex
 )
 
+// RegisterKeys 注册业务自定义的上下文键，ExportMetadataToMap 默认会一并导出
+func RegisterKeys(keys ...string) {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+
+	for _, key := range keys {
+		if !slices.Contains(knownKeys, key) {
+			knownKeys = append(knownKeys, key)
+		}
+	}
+}
+
+// RegisterSensitiveKeys 注册敏感的上下文键，ExportMetadataToMap 默认导出时会跳过
+func RegisterSensitiveKeys(keys ...string) {
+	knownKeysMu.Lock()
+	defer knownKeysMu.Unlock()
+
+	for _, key := range keys {
+		if !slices.Contains(sensitiveKeys, key) {
+			sensitiveKeys = append(sensitiveKeys, key)
+		}
+	}
+}
+
+// IsSensitiveKey 判断上下文键是否为敏感键
+func IsSensitiveKey(key string) bool {
+	knownKeysMu.RLock()
+	defer knownKeysMu.RUnlock()
+	return slices.Contains(sensitiveKeys, key)
+}
+
+// KnownKeys 返回所有已知的上下文键
+func KnownKeys() []string {
+	knownKeysMu.RLock()
+	defer knownKeysMu.RUnlock()
+	return slices.Clone(knownKeys)
+}
+
 // Metrics keys
 const (
 	MetricKeyRequestDuration = "rpc_request_duration_ms" // 请求耗时
 	MetricKeyRequestTotal    = "rpc_request_total"       // 请求总数
 	MetricKeyRequestError    = "rpc_request_error_total" // 错误请求数
+	MetricKeyRequestInFlight = "rpc_request_in_flight"   // 处理中的请求数
 )
@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// PermissionImpersonate 代用户操作所需的权限
+const PermissionImpersonate = "user:impersonate"
+
+// ErrImpersonationDenied 当前用户无代操作权限
+var ErrImpersonationDenied = errors.New("impersonation denied")
+
+// Impersonation 管理员代用户操作的信息
+type Impersonation struct {
+	AdminID      int64     `json:"admin_id"`       // 执行操作的管理员ID
+	TargetUserID int64     `json:"target_user_id"` // 被代理的用户ID
+	Action       string    `json:"action"`         // 操作，如RPC方法名或路由
+	TraceID      string    `json:"trace_id"`
+	Time         time.Time `json:"time"`
+}
+
+// ImpersonationAuditHook 代操作审计钩子，用于写审计日志或告警
+type ImpersonationAuditHook func(ctx context.Context, imp Impersonation)
+
+var (
+	impersonationHooksMu sync.RWMutex
+	impersonationHooks   []ImpersonationAuditHook
+)
+
+// RegisterImpersonationAuditHook 注册代操作审计钩子，未注册时写日志
+func RegisterImpersonationAuditHook(hooks ...ImpersonationAuditHook) {
+	impersonationHooksMu.Lock()
+	defer impersonationHooksMu.Unlock()
+	impersonationHooks = append(impersonationHooks, hooks...)
+}
+
+// WithImpersonation 管理员代用户操作：上下文中的用户ID变为 targetUserID，同时记录管理员ID
+// 管理员的用户名、角色和权限会被清除，由下游按被代理用户加载；不做权限校验，入口处应使用 Impersonate
+func WithImpersonation(ctx context.Context, adminID, targetUserID int64) context.Context {
+	ctx = WithMultiMetadata(ctx, map[string]interface{}{
+		CtxJWTUserId:       targetUserID,
+		CtxJWTUsername:     "",
+		CtxUserRoleCode:    "",
+		CtxUserRoleID:      int64(0),
+		CtxUserPermissions: []string{},
+	})
+	return WithMetadata(ctx, CtxImpersonatorID, adminID)
+}
+
+// CanImpersonate 当前已认证用户是否可以代用户操作：需拥有 PermissionImpersonate 权限，且自身不处于代操作中
+func CanImpersonate(ctx context.Context) bool {
+	return GetUidFromCtx(ctx) > 0 && !IsImpersonated(ctx) && HasPermission(ctx, PermissionImpersonate)
+}
+
+// Impersonate 当前已认证的管理员代 targetUserID 操作，无代操作权限时返回 ErrImpersonationDenied
+func Impersonate(ctx context.Context, targetUserID int64) (context.Context, error) {
+	if targetUserID <= 0 || !CanImpersonate(ctx) {
+		return ctx, ErrImpersonationDenied
+	}
+	adminID := GetUidFromCtx(ctx)
+	if adminID == targetUserID {
+		return ctx, ErrImpersonationDenied
+	}
+	return WithImpersonation(ctx, adminID, targetUserID), nil
+}
+
+// GetImpersonatorIDFromCtx 获取代操作的管理员ID，非代操作时返回0
+func GetImpersonatorIDFromCtx(ctx context.Context) int64 {
+	return GetMetadataOrDefault(ctx, CtxImpersonatorID, int64(0))
+}
+
+// IsImpersonated 是否为管理员代用户操作
+func IsImpersonated(ctx context.Context) bool {
+	return GetImpersonatorIDFromCtx(ctx) > 0
+}
+
+// GetImpersonationFromCtx 获取代操作信息
+func GetImpersonationFromCtx(ctx context.Context) (Impersonation, bool) {
+	adminID := GetImpersonatorIDFromCtx(ctx)
+	if adminID <= 0 {
+		return Impersonation{}, false
+	}
+	return Impersonation{
+		AdminID:      adminID,
+		TargetUserID: GetUidFromCtx(ctx),
+		TraceID:      GetTraceIDFromCtx(ctx),
+		Time:         time.Now(),
+	}, true
+}
+
+// AuditImpersonation 代操作时调用审计钩子，非代操作时不做处理
+func AuditImpersonation(ctx context.Context, action string) {
+	imp, ok := GetImpersonationFromCtx(ctx)
+	if !ok {
+		return
+	}
+	imp.Action = action
+
+	impersonationHooksMu.RLock()
+	hooks := impersonationHooks
+	impersonationHooksMu.RUnlock()
+
+	if len(hooks) == 0 {
+		logx.WithContext(ctx).Infow("impersonated request",
+			logx.Field("admin_id", imp.AdminID),
+			logx.Field("target_user_id", imp.TargetUserID),
+			logx.Field("action", imp.Action),
+		)
+		return
+	}
+	for _, hook := range hooks {
+		hook(ctx, imp)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cast"
 	"github.com/zeromicro/go-zero/core/logx"
 	"net"
+	"slices"
 	"time"
 )
 
@@ -334,8 +335,12 @@ func getFirstHeaderValue(headers map[string][]string, keys ...string) string {
 	return ""
 }
 
-// ExportMetadataToMap 将上下文中的元数据导出到map
+// ExportMetadataToMap 将上下文中的元数据导出到map，keys 为空时导出除敏感键外所有已知的键
 func ExportMetadataToMap(ctx context.Context, keys []string) map[string]interface{} {
+	if len(keys) == 0 {
+		keys = slices.DeleteFunc(KnownKeys(), IsSensitiveKey)
+	}
+
 	result := make(map[string]interface{})
 	for _, key := range keys {
 		if val := ctx.Value(key); val != nil {
@@ -345,6 +350,22 @@ func ExportMetadataToMap(ctx context.Context, keys []string) map[string]interfac
 	return result
 }
 
+// WithLanguage 添加语言到上下文
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return WithMetadata(ctx, CtxLanguage, lang)
+}
+
+// GetLanguageFromCtx 从上下文中获取语言，未设置时回退到客户端信息中的语言
+func GetLanguageFromCtx(ctx context.Context) string {
+	if lang := GetMetadataOrDefault(ctx, CtxLanguage, ""); lang != "" {
+		return lang
+	}
+	if info := GetRequestClientInfoFromCtx(ctx); info != nil {
+		return info.Language
+	}
+	return ""
+}
+
 // WithTracing 添加追踪信息到上下文
 func WithTracing(ctx context.Context, traceID, requestID string) context.Context {
 	ctx = WithMetadata(ctx, CtxTraceID, traceID)
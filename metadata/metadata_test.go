@@ -2,6 +2,8 @@ package metadata
 
 import (
 	"context"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -23,3 +25,172 @@ func TestGetUsernameFromCtx(t *testing.T) {
 	ctx = WithMetadata(ctx, CtxJWTUsername, "tom")
 	t.Log(GetUsernameFromCtx(ctx))
 }
+
+func TestAuditContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx = WithUserInfo(ctx, 9527, "tom")
+	ctx = WithMetadata(ctx, CtxJWTUsername, "jerry")
+
+	a := AuditContext(ctx)
+	t.Logf("%+v", a)
+	if a.Values != 3 {
+		t.Errorf("Values = %d, want 3", a.Values)
+	}
+
+	t.Log(ExportMetadataToMap(ctx, nil))
+}
+
+func TestExportMetadataToMapSkipsSensitiveKeys(t *testing.T) {
+	ctx := WithUserInfo(context.Background(), 9527, "tom")
+	ctx = WithMetadata(ctx, CtxToken, "secret-token")
+	ctx = WithMetadata(ctx, CtxSessionID, "sess-1")
+
+	m := ExportMetadataToMap(ctx, nil)
+	if _, ok := m[CtxToken]; ok {
+		t.Errorf("default export contains %s", CtxToken)
+	}
+	if _, ok := m[CtxSessionID]; ok {
+		t.Errorf("default export contains %s", CtxSessionID)
+	}
+	if m[CtxJWTUsername] != "tom" {
+		t.Errorf("username = %v, want tom", m[CtxJWTUsername])
+	}
+
+	m = ExportMetadataToMap(ctx, []string{CtxToken})
+	if m[CtxToken] != "secret-token" {
+		t.Errorf("explicit export token = %v", m[CtxToken])
+	}
+}
+
+func TestWithImpersonation(t *testing.T) {
+	ctx := WithImpersonation(context.Background(), 1, 9527)
+	if uid := GetUidFromCtx(ctx); uid != 9527 {
+		t.Errorf("uid = %d, want 9527", uid)
+	}
+
+	imp, ok := GetImpersonationFromCtx(ctx)
+	if !ok || imp.AdminID != 1 || imp.TargetUserID != 9527 {
+		t.Errorf("GetImpersonationFromCtx = %+v, %v", imp, ok)
+	}
+	if IsImpersonated(context.Background()) {
+		t.Error("IsImpersonated on empty context = true")
+	}
+}
+
+func TestScrubString(t *testing.T) {
+	req := struct {
+		UserPhone string            `json:"user_phone"`
+		Email     string            `json:"email"`
+		Password  string            `json:"password"`
+		Name      string            `json:"name"`
+		Extra     map[string]string `json:"extra"`
+	}{
+		UserPhone: "13812345678",
+		Email:     "tom@example.com",
+		Password:  "secret",
+		Name:      "tom",
+		Extra:     map[string]string{"device-id": "abcdef123456"},
+	}
+
+	got := ScrubString(req)
+	want := `{"email":"t***@example.com","extra":{"device-id":"abcd****3456"},"name":"tom","password":"******","user_phone":"138****5678"}`
+	if got != want {
+		t.Fatalf("ScrubString() = %s, want %s", got, want)
+	}
+}
+
+func TestTraceContextBridging(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers map[string]string
+		want    TraceContext
+	}{
+		{"traceparent", map[string]string{HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}},
+		{"b3 single", map[string]string{HeaderB3: "a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+			TraceContext{TraceID: "0000000000000000a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}},
+		{"b3 multi", map[string]string{HeaderB3TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", HeaderB3SpanID: "00f067aa0ba902b7", HeaderB3Sampled: "0"},
+			TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}},
+		{"legacy uuid", map[string]string{HeaderTraceID: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736"},
+			TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", LegacyTraceID: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736"}},
+		{"traceparent with legacy uuid", map[string]string{
+			HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			HeaderTraceID:     "4bf92f35-77b3-4da6-a3ce-929d0e0e4736",
+		}, TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true,
+			LegacyTraceID: "4bf92f35-77b3-4da6-a3ce-929d0e0e4736"}},
+		{"traceparent with other trace id", map[string]string{
+			HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			HeaderTraceID:     "trace-1",
+		}, TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}},
+		{"legacy raw", map[string]string{HeaderTraceID: "trace-1"}, TraceContext{TraceID: "trace-1"}},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		for k, v := range c.headers {
+			h.Set(k, v)
+		}
+		got, ok := ExtractTraceContext(h)
+		if !ok || got != c.want {
+			t.Errorf("%s: ExtractTraceContext = %+v, %v, want %+v", c.name, got, ok, c.want)
+		}
+	}
+
+	h := http.Header{}
+	InjectTraceContext(h, TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true})
+	if got := h.Get(HeaderTraceparent); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent = %s", got)
+	}
+	if got := h.Get(HeaderB3); got != "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1" {
+		t.Errorf("b3 = %s", got)
+	}
+	if h.Get(HeaderTraceID) != "4bf92f3577b34da6a3ce929d0e0e4736" || h.Get(HeaderB3Sampled) != "1" {
+		t.Errorf("headers = %v", h)
+	}
+	// 旧服务的 x-trace-id 原样作为追踪ID和 x-trace-id 传递，traceparent 使用转换后的链路ID
+	legacy := TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", LegacyTraceID: "4BF92F35-77B3-4DA6-A3CE-929D0E0E4736"}
+	ctx := WithTraceContext(context.Background(), legacy)
+	if got := GetTraceIDFromCtx(ctx); got != legacy.LegacyTraceID {
+		t.Errorf("trace id in ctx = %s", got)
+	}
+	h = http.Header{}
+	InjectTraceContext(h, legacy)
+	if h.Get(HeaderTraceID) != legacy.LegacyTraceID || !strings.Contains(h.Get(HeaderTraceparent), legacy.TraceID) {
+		t.Errorf("legacy headers = %v", h)
+	}
+	if tc, ok := GetTraceContextFromCtx(WithMetadata(context.Background(), CtxTraceID, legacy.LegacyTraceID)); !ok || tc != legacy {
+		t.Errorf("GetTraceContextFromCtx = %+v, %v", tc, ok)
+	}
+
+	h = http.Header{}
+	h.Set(HeaderB3, "0")
+	if _, ok := ExtractTraceContext(h); ok {
+		t.Error("sampling-only b3 header extracted")
+	}
+}
+
+func TestImpersonate(t *testing.T) {
+	admin := WithMultiMetadata(context.Background(), map[string]interface{}{
+		CtxJWTUserId:       int64(1),
+		CtxUserRoleCode:    "admin",
+		CtxUserPermissions: []string{PermissionImpersonate, "order:refund"},
+	})
+	if _, err := Impersonate(WithMetadata(admin, CtxUserPermissions, []string{}), 9527); err != ErrImpersonationDenied {
+		t.Errorf("Impersonate without permission err = %v", err)
+	}
+
+	ctx, err := Impersonate(admin, 9527)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetUidFromCtx(ctx) != 9527 || GetImpersonatorIDFromCtx(ctx) != 1 {
+		t.Errorf("uid = %d, admin = %d", GetUidFromCtx(ctx), GetImpersonatorIDFromCtx(ctx))
+	}
+	if GetUserRoleCodeFromCtx(ctx) != "" || HasPermission(ctx, "order:refund") {
+		t.Error("admin role and permissions kept after impersonation")
+	}
+	if _, err := Impersonate(ctx, 42); err != ErrImpersonationDenied {
+		t.Errorf("nested Impersonate err = %v", err)
+	}
+}
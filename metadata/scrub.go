@@ -0,0 +1,210 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 默认单个字符串值的最大日志长度
+const defaultScrubMaxValueLen = 1024
+
+// MaskFunc 脱敏函数
+type MaskFunc func(string) string
+
+// ScrubPolicy 日志脱敏策略
+// Rules 的键按忽略大小写、-和_后的后缀匹配字段名，如 phone 同时匹配 phone、user_phone、UserPhone
+type ScrubPolicy struct {
+	Rules map[string]MaskFunc
+	// 字符串值超过该长度时截断，<=0 时不截断
+	MaxValueLen int
+}
+
+// DefaultScrubPolicy 默认脱敏策略：手机号、邮箱、证件号部分脱敏，设备ID和指纹保留首尾，密码和令牌完全隐藏
+func DefaultScrubPolicy() ScrubPolicy {
+	return ScrubPolicy{
+		Rules: map[string]MaskFunc{
+			"phone":         MaskPhone,
+			"mobile":        MaskPhone,
+			"email":         MaskEmail,
+			"idcard":        MaskPartial(4, 4),
+			"deviceid":      MaskPartial(4, 4),
+			"fingerprint":   MaskPartial(4, 4),
+			"password":      MaskAll,
+			"token":         MaskAll,
+			"secret":        MaskAll,
+			"authorization": MaskAll,
+		},
+		MaxValueLen: defaultScrubMaxValueLen,
+	}
+}
+
+// 当前生效的脱敏策略
+var currentScrubPolicy atomic.Pointer[ScrubPolicy]
+
+func init() {
+	SetScrubPolicy(DefaultScrubPolicy())
+}
+
+// SetScrubPolicy 设置日志脱敏策略，立即对 Scrub 系列函数和 Logger 生效
+func SetScrubPolicy(p ScrubPolicy) {
+	rules := make(map[string]MaskFunc, len(p.Rules))
+	for k, fn := range p.Rules {
+		rules[normalizeScrubKey(k)] = fn
+	}
+	p.Rules = rules
+	currentScrubPolicy.Store(&p)
+}
+
+// MaskPhone 手机号脱敏，保留前3位和后4位
+func MaskPhone(phone string) string {
+	if len(phone) <= 7 {
+		return strings.Repeat("*", len(phone))
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
+
+// MaskEmail 邮箱脱敏，保留用户名首字符和域名
+func MaskEmail(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i <= 0 {
+		return MaskAll(email)
+	}
+	return email[:1] + "***" + email[i:]
+}
+
+// MaskPartial 保留前 head 位和后 tail 位，长度不足时完全隐藏
+func MaskPartial(head, tail int) MaskFunc {
+	return func(s string) string {
+		if len(s) <= head+tail {
+			return strings.Repeat("*", len(s))
+		}
+		return s[:head] + strings.Repeat("*", len(s)-head-tail) + s[len(s)-tail:]
+	}
+}
+
+// MaskAll 完全隐藏
+func MaskAll(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "******"
+}
+
+// Scrub 按字段名脱敏单个值，结构体、map等复合值按JSON字段名递归脱敏
+func Scrub(key string, val any) any {
+	return currentScrubPolicy.Load().scrub(key, val)
+}
+
+// ScrubMap 脱敏map中的所有值，返回新的map
+func ScrubMap(m map[string]any) map[string]any {
+	p := currentScrubPolicy.Load()
+	result := make(map[string]any, len(m))
+	for k, v := range m {
+		result[k] = p.scrub(k, v)
+	}
+	return result
+}
+
+// ScrubString 将值脱敏后格式化为JSON字符串，用于在日志中输出请求、响应等对象
+// 无法序列化为JSON的值按 %+v 格式化并截断
+func ScrubString(val any) string {
+	p := currentScrubPolicy.Load()
+	data, err := json.Marshal(p.scrub("", val))
+	if err != nil {
+		return p.truncate(fmt.Sprintf("%+v", val))
+	}
+	return string(data)
+}
+
+// Logger 返回附带上下文元数据字段的日志记录器，字段值按脱敏策略处理，keys 为空时使用所有已知的键
+func Logger(ctx context.Context, keys ...string) logx.Logger {
+	m := ScrubMap(ExportMetadataToMap(ctx, keys))
+	fields := make([]logx.LogField, 0, len(m))
+	for k, v := range m {
+		fields = append(fields, logx.Field(k, v))
+	}
+	return logx.WithContext(ctx).WithFields(fields...)
+}
+
+// scrub 按字段名脱敏值
+func (p *ScrubPolicy) scrub(key string, val any) any {
+	if key != "" {
+		if fn := p.match(key); fn != nil {
+			if val == nil {
+				return nil
+			}
+			return fn(fmt.Sprint(val))
+		}
+	}
+
+	switch v := val.(type) {
+	case nil, bool, json.Number, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return p.truncate(v)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for k, item := range v {
+			result[k] = p.scrub(k, item)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = p.scrub(key, item)
+		}
+		return result
+	}
+
+	// 其他类型按JSON字段名处理
+	data, err := json.Marshal(val)
+	if err != nil {
+		return p.truncate(fmt.Sprintf("%+v", val))
+	}
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err != nil {
+		return p.truncate(string(data))
+	}
+	return p.scrub(key, decoded)
+}
+
+// match 查找字段名匹配的脱敏规则
+func (p *ScrubPolicy) match(key string) MaskFunc {
+	key = normalizeScrubKey(key)
+	if fn, ok := p.Rules[key]; ok {
+		return fn
+	}
+	for suffix, fn := range p.Rules {
+		if strings.HasSuffix(key, suffix) {
+			return fn
+		}
+	}
+	return nil
+}
+
+// truncate 截断过长的字符串
+func (p *ScrubPolicy) truncate(s string) string {
+	if p.MaxValueLen <= 0 || len(s) <= p.MaxValueLen {
+		return s
+	}
+	// 避免截断在多字节字符中间
+	n := p.MaxValueLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...(%d bytes)", s[:n], len(s))
+}
+
+// normalizeScrubKey 字段名转小写并去掉-和_
+func normalizeScrubKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+}
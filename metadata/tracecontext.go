@@ -0,0 +1,223 @@
+package metadata
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceCarrier 链路请求头的读写，http.Header 可直接使用，gRPC metadata 需适配
+type TraceCarrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// TraceContext 链路上下文，在 x-trace-id、W3C traceparent 和 B3 请求头之间转换
+type TraceContext struct {
+	// 32位小写十六进制，旧服务的 x-trace-id 无法转换时为原值，此时只传递 x-trace-id
+	TraceID string `json:"trace_id"`
+	// 上游的 span ID，16位小写十六进制，可为空
+	SpanID string `json:"span_id,omitempty"`
+	// 上游是否采样，旧服务的 x-trace-id 不带采样标记，为 false
+	Sampled bool `json:"sampled,omitempty"`
+	// 旧服务传递的原始 x-trace-id（如 UUID），与 TraceID 对应同一链路，继续作为追踪ID和 x-trace-id 传递
+	LegacyTraceID string `json:"legacy_trace_id,omitempty"`
+}
+
+// XTraceID 追踪ID，用于日志和 x-trace-id，有旧服务的原始 x-trace-id 时使用原值，便于与旧服务的日志关联
+func (tc TraceContext) XTraceID() string {
+	if tc.LegacyTraceID != "" {
+		return tc.LegacyTraceID
+	}
+	return tc.TraceID
+}
+
+// Valid TraceID 是否为合法的 W3C/B3 链路ID
+func (tc TraceContext) Valid() bool {
+	return isTraceHex(tc.TraceID, 32)
+}
+
+// Traceparent 转为 W3C traceparent，SpanID 为空时生成，链路ID不合法时返回空
+func (tc TraceContext) Traceparent() string {
+	if !tc.Valid() {
+		return ""
+	}
+	return fmt.Sprintf("00-%s-%s-%s", tc.TraceID, tc.spanID(), sampledFlag(tc.Sampled, "01", "00"))
+}
+
+// B3 转为 B3 单头格式 {TraceId}-{SpanId}-{SamplingState}，链路ID不合法时返回空
+func (tc TraceContext) B3() string {
+	if !tc.Valid() {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s", tc.TraceID, tc.spanID(), sampledFlag(tc.Sampled, "1", "0"))
+}
+
+func (tc TraceContext) spanID() string {
+	if tc.SpanID != "" {
+		return tc.SpanID
+	}
+	return NewSpanID()
+}
+
+// ExtractTraceContext 从请求头提取链路上下文，依次读取 traceparent、b3、X-B3-* 和 x-trace-id，都没有时返回 false
+// x-trace-id 与链路ID不同但对应同一链路（如旧服务的 UUID）时保留原值
+func ExtractTraceContext(c TraceCarrier) (TraceContext, bool) {
+	tc, ok := extractTraceContext(c)
+	if !ok {
+		return TraceContext{}, false
+	}
+	if raw := strings.TrimSpace(c.Get(HeaderTraceID)); raw != "" && raw != tc.TraceID {
+		if traceID, ok := NormalizeTraceID(raw); ok && traceID == tc.TraceID {
+			tc.LegacyTraceID = raw
+		}
+	}
+	return tc, true
+}
+
+// extractTraceContext 按优先级读取链路请求头
+func extractTraceContext(c TraceCarrier) (TraceContext, bool) {
+	if tc, ok := ParseTraceparent(c.Get(HeaderTraceparent)); ok {
+		return tc, true
+	}
+	if tc, ok := ParseB3(c.Get(HeaderB3)); ok {
+		return tc, true
+	}
+	if traceID, ok := NormalizeTraceID(c.Get(HeaderB3TraceID)); ok {
+		tc := TraceContext{
+			TraceID: traceID,
+			Sampled: c.Get(HeaderB3Sampled) == "1" || c.Get(HeaderB3Sampled) == "true" || c.Get(HeaderB3Flags) == "1",
+		}
+		if spanID := strings.ToLower(c.Get(HeaderB3SpanID)); isTraceHex(spanID, 16) {
+			tc.SpanID = spanID
+		}
+		return tc, true
+	}
+	if raw := strings.TrimSpace(c.Get(HeaderTraceID)); raw != "" {
+		// UUID 格式的旧链路ID转为32位十六进制，只用于 traceparent 和 B3
+		if traceID, ok := NormalizeTraceID(raw); ok {
+			return TraceContext{TraceID: traceID}, true
+		}
+		return TraceContext{TraceID: raw}, true
+	}
+	return TraceContext{}, false
+}
+
+// InjectTraceContext 将链路上下文写入请求头，同时写入 x-trace-id、traceparent 和 B3 单头、多头，兼容新旧服务
+// x-trace-id 使用 XTraceID，多种格式使用相同的 span ID；链路ID不合法时只写入 x-trace-id
+func InjectTraceContext(c TraceCarrier, tc TraceContext) {
+	if tc.TraceID == "" {
+		return
+	}
+	c.Set(HeaderTraceID, tc.XTraceID())
+	if !tc.Valid() {
+		return
+	}
+
+	tc.SpanID = tc.spanID()
+	c.Set(HeaderTraceparent, tc.Traceparent())
+	c.Set(HeaderB3, tc.B3())
+	c.Set(HeaderB3TraceID, tc.TraceID)
+	c.Set(HeaderB3SpanID, tc.SpanID)
+	c.Set(HeaderB3Sampled, sampledFlag(tc.Sampled, "1", "0"))
+}
+
+// ParseTraceparent 解析 W3C traceparent：{version}-{trace-id}-{parent-id}-{trace-flags}
+func ParseTraceparent(v string) (TraceContext, bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(v)), "-")
+	if len(parts) < 4 || !isTraceHex(parts[0], 2) || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+	if !isTraceHex(parts[1], 32) || !isTraceHex(parts[2], 16) || !isTraceHex(parts[3], 2) {
+		return TraceContext{}, false
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return TraceContext{TraceID: parts[1], SpanID: parts[2], Sampled: flags[0]&0x01 == 1}, true
+}
+
+// ParseB3 解析 B3 单头：{TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]，只有采样标记（如 0）时返回 false
+// 64位的 TraceId 左侧补零为128位
+func ParseB3(v string) (TraceContext, bool) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(v)), "-")
+	if len(parts) < 2 || !isTraceHex(parts[1], 16) {
+		return TraceContext{}, false
+	}
+	traceID, ok := NormalizeTraceID(parts[0])
+	if !ok {
+		return TraceContext{}, false
+	}
+	tc := TraceContext{TraceID: traceID, SpanID: parts[1]}
+	if len(parts) > 2 {
+		tc.Sampled = parts[2] == "1" || parts[2] == "d"
+	}
+	return tc, true
+}
+
+// NormalizeTraceID 转为32位小写十六进制的链路ID，支持 W3C/B3 链路ID、64位 B3 链路ID 和 UUID（旧服务的 x-trace-id）
+func NormalizeTraceID(id string) (string, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if len(id) == 36 {
+		id = strings.ReplaceAll(id, "-", "")
+	}
+	if len(id) == 16 && isTraceHex(id, 16) {
+		id = strings.Repeat("0", 16) + id
+	}
+	if !isTraceHex(id, 32) {
+		return "", false
+	}
+	return id, true
+}
+
+// NewSpanID 生成随机的 span ID
+func NewSpanID() string {
+	b := make([]byte, 8)
+	for {
+		_, _ = rand.Read(b)
+		if id := hex.EncodeToString(b); id != "0000000000000000" {
+			return id
+		}
+	}
+}
+
+// WithTraceContext 添加链路上下文，同时设置追踪ID为 XTraceID
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	ctx = WithMetadata(ctx, CtxTraceContext, tc)
+	return WithMetadata(ctx, CtxTraceID, tc.XTraceID())
+}
+
+// GetTraceContextFromCtx 获取链路上下文，未设置时由追踪ID构建
+func GetTraceContextFromCtx(ctx context.Context) (TraceContext, bool) {
+	if tc, ok := GetMetadata[TraceContext](ctx, CtxTraceContext); ok && tc.TraceID != "" {
+		return tc, true
+	}
+	traceID := GetTraceIDFromCtx(ctx)
+	if traceID == "" {
+		return TraceContext{}, false
+	}
+	if normalized, ok := NormalizeTraceID(traceID); ok && normalized != traceID {
+		return TraceContext{TraceID: normalized, LegacyTraceID: traceID}, true
+	}
+	return TraceContext{TraceID: traceID}, true
+}
+
+// isTraceHex 是否为指定长度的小写十六进制，链路ID和 span ID 不能全为0
+func isTraceHex(s string, n int) bool {
+	if len(s) != n || (n > 2 && strings.Trim(s, "0") == "") {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func sampledFlag(sampled bool, yes, no string) string {
+	if sampled {
+		return yes
+	}
+	return no
+}
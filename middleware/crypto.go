@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/QuantumShiftX/golib/config"
 	"github.com/QuantumShiftX/golib/crypto"
@@ -35,7 +36,8 @@ func CryptoMiddleware(cfg *config.CryptoConfig) Handler {
 			}
 
 			// 解密请求
-			if err := decryptHTTPRequest(r, cfg); err != nil {
+			var err error
+			if r, err = decryptHTTPRequest(r, cfg); err != nil {
 				if cfg.Debug {
 					logx.Infof("[Crypto] Request decryption failed: %v", err)
 				}
@@ -52,7 +54,7 @@ func CryptoMiddleware(cfg *config.CryptoConfig) Handler {
 			next.ServeHTTP(recorder, r)
 
 			// 加密响应
-			if err := encryptHTTPResponse(recorder, w, cfg); err != nil {
+			if err := encryptHTTPResponse(recorder, w, r, cfg); err != nil {
 				if cfg.Debug {
 					logx.Infof("[Crypto] Response encryption failed: %v", err)
 				}
@@ -67,21 +69,30 @@ func CryptoMiddleware(cfg *config.CryptoConfig) Handler {
 	}
 }
 
+// 会话密钥上下文键
+type sessionKeyCtxKey struct{}
+
+// envelopeAAD 信封附加认证数据，绑定请求方法和路径
+func envelopeAAD(r *http.Request) []byte {
+	return []byte(r.Method + " " + r.URL.Path)
+}
+
 // decryptHTTPRequest 解密HTTP请求（优化版）
-func decryptHTTPRequest(r *http.Request, cfg *config.CryptoConfig) error {
+// 返回的请求可能携带协商出的会话密钥，调用方应使用返回值继续处理
+func decryptHTTPRequest(r *http.Request, cfg *config.CryptoConfig) (*http.Request, error) {
 	if r.Method == "GET" || r.Method == "DELETE" || r.Method == "HEAD" {
-		return nil
+		return r, nil
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("read request body failed: %w", err)
+		return r, fmt.Errorf("read request body failed: %w", err)
 	}
 	r.Body.Close()
 
 	if len(body) == 0 {
 		r.Body = io.NopCloser(bytes.NewReader(body))
-		return nil
+		return r, nil
 	}
 
 	if cfg.Debug {
@@ -93,19 +104,25 @@ func decryptHTTPRequest(r *http.Request, cfg *config.CryptoConfig) error {
 		if cfg.Debug {
 			logx.Infof("[Crypto] Request is not in encrypted format, keeping original")
 		}
-		return nil
+		return r, nil
 	}
 
-	// 解密数据
+	// 解密数据，临时密钥信封格式需协商会话密钥
 	var decryptedData interface{}
-	if err = crypto.DecryptRequest(body, &decryptedData); err != nil {
-		return fmt.Errorf("decrypt request data failed: %w", err)
+	if crypto.IsEnvelopeFormat(body) {
+		session, err := crypto.OpenRequestEnvelope(body, envelopeAAD(r), &decryptedData)
+		if err != nil {
+			return r, fmt.Errorf("open request envelope failed: %w", err)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), sessionKeyCtxKey{}, session))
+	} else if err = crypto.DecryptRequest(body, &decryptedData); err != nil {
+		return r, fmt.Errorf("decrypt request data failed: %w", err)
 	}
 
 	// 重新序列化为JSON
 	decryptedJSON, err := jsonx.Marshal(decryptedData)
 	if err != nil {
-		return fmt.Errorf("marshal decrypted data failed: %w", err)
+		return r, fmt.Errorf("marshal decrypted data failed: %w", err)
 	}
 
 	if cfg.Debug {
@@ -116,11 +133,11 @@ func decryptHTTPRequest(r *http.Request, cfg *config.CryptoConfig) error {
 	r.Body = io.NopCloser(bytes.NewReader(decryptedJSON))
 	r.ContentLength = int64(len(decryptedJSON))
 
-	return nil
+	return r, nil
 }
 
 // encryptHTTPResponse 加密HTTP响应（优化版）
-func encryptHTTPResponse(recorder *ResponseRecorder, w http.ResponseWriter, cfg *config.CryptoConfig) error {
+func encryptHTTPResponse(recorder *ResponseRecorder, w http.ResponseWriter, r *http.Request, cfg *config.CryptoConfig) error {
 	// 复制头部
 	for k, v := range recorder.header {
 		w.Header()[k] = v
@@ -144,28 +161,69 @@ func encryptHTTPResponse(recorder *ResponseRecorder, w http.ResponseWriter, cfg
 		return fmt.Errorf("unmarshal response data failed: %w", err)
 	}
 
-	// 加密响应
-	encryptedData, err := crypto.QuickEncrypt(originalData)
+	// 加密响应，请求使用信封格式时用协商出的会话密钥加密，全局服务为JWE格式时返回紧凑JWE
+	var (
+		encrypted   []byte
+		contentType = "application/json"
+		err         error
+	)
+	if session, ok := r.Context().Value(sessionKeyCtxKey{}).(*crypto.SessionKey); ok {
+		encrypted, err = sealResponse(session, originalData)
+	} else {
+		encrypted, contentType, err = encryptResponse(originalData)
+	}
 	if err != nil {
-		return fmt.Errorf("encrypt response data failed: %w", err)
+		return err
 	}
 
 	if cfg.Debug {
 		logx.Infof("[Crypto] Response encrypted successfully data: %+v", originalData)
 	}
 
-	// 序列化加密响应
+	// 写入响应
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(encrypted)
+
+	return nil
+}
+
+// sealResponse 使用协商出的会话密钥加密响应
+func sealResponse(session *crypto.SessionKey, data interface{}) ([]byte, error) {
+	encryptedData, err := session.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt response data failed: %w", err)
+	}
 	encryptedJSON, err := jsonx.Marshal(encryptedData)
 	if err != nil {
-		return fmt.Errorf("marshal encrypted response failed: %w", err)
+		return nil, fmt.Errorf("marshal encrypted response failed: %w", err)
 	}
+	return encryptedJSON, nil
+}
 
-	// 写入响应
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	w.Write(encryptedJSON)
+// encryptResponse 使用全局加密服务按其输出格式加密响应，返回密文和对应的 Content-Type
+func encryptResponse(data interface{}) ([]byte, string, error) {
+	service, err := crypto.GetGlobalService()
+	if err != nil {
+		return nil, "", fmt.Errorf("encrypt response data failed: %w", err)
+	}
+	if service.Format() == crypto.FormatJWE {
+		payload, err := service.EncryptPayload(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("encrypt response data failed: %w", err)
+		}
+		return payload, "application/jose", nil
+	}
 
-	return nil
+	encryptedData, err := service.EncryptJSON(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("encrypt response data failed: %w", err)
+	}
+	encryptedJSON, err := jsonx.Marshal(encryptedData)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal encrypted response failed: %w", err)
+	}
+	return encryptedJSON, "application/json", nil
 }
 
 // writeOriginalResponse 写入原始响应（回退）
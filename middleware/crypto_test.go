@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/crypto"
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+const testCryptoKey = "0123456789abcdef0123456789abcdef"
+
+// cryptoPayload 测试用请求和响应体
+type cryptoPayload struct {
+	Amount int    `json:"amount"`
+	Memo   string `json:"memo"`
+}
+
+// newCryptoService 注册全局AES-GCM服务并设置输出格式，测试结束后恢复信封格式
+func newCryptoService(t *testing.T, format string) *crypto.XCryptoService {
+	t.Helper()
+	if err := crypto.RegisterGlobalAESGCM(testCryptoKey, false); err != nil {
+		t.Fatal(err)
+	}
+	service, err := crypto.GetGlobalService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.SetFormat(format); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { service.SetFormat(crypto.FormatEnvelope) })
+	return service
+}
+
+func TestCryptoMiddlewareRoundTrip(t *testing.T) {
+	cfg := &config.CryptoConfig{Enable: true, EnableURI: []string{"/pay"}, FailOnError: true}
+	// 原样返回解密后的请求体
+	handler := CryptoMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+
+	tests := []struct {
+		name        string
+		format      string
+		contentType string
+	}{
+		{"envelope", crypto.FormatEnvelope, "application/json"},
+		{"jwe", crypto.FormatJWE, "application/jose"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newCryptoService(t, tt.format)
+			in := cryptoPayload{Amount: 100, Memo: "order"}
+			body, err := service.EncryptPayload(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pay", bytes.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("code = %d, body = %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := crypto.IsJWEFormat(w.Body.Bytes()); got != (tt.format == crypto.FormatJWE) {
+				t.Fatalf("IsJWEFormat(response) = %v", got)
+			}
+			var out cryptoPayload
+			if err := service.DecryptPayload(w.Body.Bytes(), &out); err != nil || out != in {
+				t.Fatalf("DecryptPayload = %+v, %v", out, err)
+			}
+		})
+	}
+}
+
+func TestCryptoMiddlewareKeyExchange(t *testing.T) {
+	newCryptoService(t, crypto.FormatEnvelope)
+	priv, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.RegisterGlobalX25519Key("middleware-test", priv.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.CryptoConfig{Enable: true, EnableURI: []string{"/pay", "/refund"}, FailOnError: true}
+	handler := CryptoMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+
+	in := cryptoPayload{Amount: 100, Memo: "order"}
+	// 信封绑定请求方法和路径
+	sealed, client, err := crypto.SealEnvelope(priv.PublicKey().Bytes(), "middleware-test", in, []byte("POST /pay"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := jsonx.Marshal(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/pay", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d, body = %s", w.Code, w.Body)
+	}
+	var response crypto.EncryptedData
+	if err := jsonx.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	var out cryptoPayload
+	if err := client.Open(&response, &out); err != nil || out != in {
+		t.Fatalf("client Open = %+v, %v", out, err)
+	}
+
+	// 发往其他路径的信封解密失败
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refund", bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("replayed to other path code = %d", w.Code)
+	}
+}
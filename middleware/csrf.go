@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/QuantumShiftX/golib/config"
+)
+
+// csrfTokenKey 上下文中CSRF令牌的键
+type csrfTokenKey struct{}
+
+// csrfTokenLen 令牌随机字节数
+const csrfTokenLen = 32
+
+// CSRFMiddleware 双重提交Cookie的CSRF防护中间件：令牌Cookie不存在时生成并下发（前端可读取），
+// 非安全方法要求请求头（或表单字段）中的令牌与Cookie一致，否则返回403
+// 不同路由组可使用各自的配置创建中间件，如管理后台：NewChain().Append(CSRFMiddleware(adminCSRF))
+func CSRFMiddleware(cfg *config.CSRFConfig) Handler {
+	if cfg == nil {
+		cfg = config.DefaultMiddlewareConfig().CSRF
+	}
+
+	cookieName := cfg.CookieName
+	if cookieName == "" {
+		cookieName = "csrf_token"
+	}
+	headerName := cfg.HeaderName
+	if headerName == "" {
+		headerName = "X-CSRF-Token"
+	}
+	cookiePath := cfg.CookiePath
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+	sameSite := parseSameSite(cfg.SameSite)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isCSRFExempt(r.URL.Path, cfg.ExemptPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := ""
+			if c, err := r.Cookie(cookieName); err == nil {
+				token = c.Value
+			}
+			if token == "" {
+				var err error
+				if token, err = generateCSRFToken(); err != nil {
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+				// 前端需读取Cookie并放入请求头，不设置 HttpOnly
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookieName,
+					Value:    token,
+					Path:     cookiePath,
+					Domain:   cfg.CookieDomain,
+					MaxAge:   cfg.CookieMaxAge,
+					Secure:   cfg.Secure,
+					SameSite: sameSite,
+				})
+			}
+
+			if !isSafeMethod(r.Method) {
+				submitted := r.Header.Get(headerName)
+				if submitted == "" && cfg.FormField != "" {
+					submitted = r.PostFormValue(cfg.FormField)
+				}
+				// 新生成的令牌不可能被提交，请求同样被拒绝
+				if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(token)) != 1 {
+					http.Error(w, "Forbidden - CSRF token invalid", http.StatusForbidden)
+					return
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), csrfTokenKey{}, token)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CSRFToken 获取当前请求的CSRF令牌，用于服务端渲染页面时写入表单或meta标签
+func CSRFToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfTokenKey{}).(string)
+	return token
+}
+
+// generateCSRFToken 生成随机令牌
+func generateCSRFToken() (string, error) {
+	b := make([]byte, csrfTokenLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isSafeMethod 是否为不修改状态的安全方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// isCSRFExempt 路径是否在豁免前缀中，按路由使用的 path.Clean 规范化后以路径段为边界匹配，
+// 避免 /webhook/../admin 或 /webhookX 被当作 /webhook 豁免
+func isCSRFExempt(p string, exempt []string) bool {
+	cleaned := path.Clean("/" + p)
+	for _, prefix := range exempt {
+		if prefix == "" {
+			continue
+		}
+		prefix = path.Clean("/" + prefix)
+		if prefix == "/" || cleaned == prefix || strings.HasPrefix(cleaned, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSameSite 解析 SameSite 配置，默认 Lax
+func parseSameSite(s string) http.SameSite {
+	switch strings.ToLower(s) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
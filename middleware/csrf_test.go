@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/config"
+)
+
+func TestIsCSRFExempt(t *testing.T) {
+	exempt := []string{"/webhook", "/callback/"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/webhook", true},
+		{"/webhook/pay", true},
+		{"/callback/wechat", true},
+		{"/callback", true},
+		{"/webhookX", false},
+		{"/webhook/../admin/delete", false},
+		{"//webhook/pay", true},
+		{"/admin/delete", false},
+	}
+	for _, tt := range tests {
+		if got := isCSRFExempt(tt.path, exempt); got != tt.want {
+			t.Errorf("isCSRFExempt(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCSRFMiddleware(t *testing.T) {
+	cfg := &config.CSRFConfig{CookieName: "csrf_token", HeaderName: "X-CSRF-Token", ExemptPaths: []string{"/webhook"}}
+	handler := CSRFMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(CSRFToken(r)))
+	}))
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	// 安全方法下发令牌
+	w := serve(httptest.NewRequest(http.MethodGet, "/admin", nil))
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusOK || len(cookies) != 1 || cookies[0].Value != w.Body.String() {
+		t.Fatalf("GET status = %d, cookies = %v", w.Code, cookies)
+	}
+	token := cookies[0].Value
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		want   int
+	}{
+		{"valid token", "/admin/delete", token, http.StatusOK},
+		{"missing token", "/admin/delete", "", http.StatusForbidden},
+		{"wrong token", "/admin/delete", "x" + token, http.StatusForbidden},
+		{"exempt path", "/webhook/pay", "", http.StatusOK},
+		{"encoded traversal", "/webhook/..%2fadmin/delete", "", http.StatusForbidden},
+		{"prefix without boundary", "/webhookX", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader("{}"))
+			r.AddCookie(&http.Cookie{Name: "csrf_token", Value: token})
+			if tt.header != "" {
+				r.Header.Set("X-CSRF-Token", tt.header)
+			}
+			if w := serve(r); w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
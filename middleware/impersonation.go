@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/QuantumShiftX/golib/metadata"
+)
+
+// 网关转发到gRPC元数据时使用的请求头前缀
+const grpcMetadataHeaderPrefix = "Grpc-Metadata-"
+
+// StripImpersonationHeaders 在入口处清除客户端传入的代操作请求头，
+// 代操作只能由服务端调用 metadata.Impersonate 校验权限后发起
+func StripImpersonationHeaders() Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, h := range []string{metadata.HeaderImpersonatorID, metadata.HeaderImpersonatedUserID} {
+				r.Header.Del(h)
+				r.Header.Del(grpcMetadataHeaderPrefix + h)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
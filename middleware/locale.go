@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/metadata"
+)
+
+// HeaderContentLanguage 响应语言头
+const HeaderContentLanguage = "Content-Language"
+
+// LocaleMiddleware 语言协商中间件：按 查询参数 → Cookie → x-language → Accept-Language 确定语言，
+// 写入上下文（metadata.GetLanguageFromCtx），并通过 Content-Language 响应头回显，
+// 校验错误（validator.ValidateCtx）和错误消息（xerr.LocalizeMessage）据此本地化
+func LocaleMiddleware(cfg *config.LocaleConfig) Handler {
+	if cfg == nil {
+		cfg = config.DefaultMiddlewareConfig().Locale
+	}
+
+	matcher := newLocaleMatcher(cfg.Supported)
+	queryParam := cfg.QueryParam
+	if queryParam == "" {
+		queryParam = "lang"
+	}
+	cookieName := cfg.CookieName
+	if cookieName == "" {
+		cookieName = "lang"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang, fromQuery := resolveLocale(r, matcher, queryParam, cookieName)
+
+			// 通过查询参数切换语言时持久化，后续请求无需再带参数
+			if fromQuery && cfg.PersistCookie {
+				http.SetCookie(w, &http.Cookie{
+					Name:     cookieName,
+					Value:    lang,
+					Path:     "/",
+					MaxAge:   cfg.CookieMaxAge,
+					HttpOnly: true,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+
+			w.Header().Set(HeaderContentLanguage, lang)
+			w.Header().Add("Vary", metadata.HeaderAcceptLanguage)
+
+			ctx := metadata.WithLanguage(r.Context(), lang)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// resolveLocale 按优先级解析语言，返回语言及是否来自查询参数
+func resolveLocale(r *http.Request, m *localeMatcher, queryParam, cookieName string) (string, bool) {
+	if lang, ok := m.match(r.URL.Query().Get(queryParam)); ok {
+		return lang, true
+	}
+	if cookie, err := r.Cookie(cookieName); err == nil {
+		if lang, ok := m.match(cookie.Value); ok {
+			return lang, false
+		}
+	}
+	if lang, ok := m.match(r.Header.Get(metadata.HeaderLanguage)); ok {
+		return lang, false
+	}
+	for _, tag := range parseAcceptLanguage(r.Header.Get(metadata.HeaderAcceptLanguage)) {
+		if lang, ok := m.match(tag); ok {
+			return lang, false
+		}
+	}
+	return m.fallback, false
+}
+
+// localeMatcher 语言匹配器，先精确匹配（忽略大小写和 _/-），再按主语言匹配，如 zh-CN → zh
+type localeMatcher struct {
+	exact    map[string]string
+	base     map[string]string
+	fallback string
+}
+
+func newLocaleMatcher(supported []string) *localeMatcher {
+	if len(supported) == 0 {
+		supported = []string{"en"}
+	}
+
+	m := &localeMatcher{
+		exact:    make(map[string]string, len(supported)),
+		base:     make(map[string]string, len(supported)),
+		fallback: supported[0],
+	}
+	for _, lang := range supported {
+		key := normalizeLocale(lang)
+		m.exact[key] = lang
+		if base, _, _ := strings.Cut(key, "-"); base != "" {
+			if _, ok := m.base[base]; !ok {
+				m.base[base] = lang
+			}
+		}
+	}
+	return m
+}
+
+// match 匹配支持的语言
+func (m *localeMatcher) match(tag string) (string, bool) {
+	key := normalizeLocale(tag)
+	if key == "" {
+		return "", false
+	}
+	if lang, ok := m.exact[key]; ok {
+		return lang, true
+	}
+	base, _, _ := strings.Cut(key, "-")
+	lang, ok := m.base[base]
+	return lang, ok
+}
+
+// normalizeLocale 统一为小写并使用 - 分隔
+func normalizeLocale(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// parseAcceptLanguage 解析 Accept-Language，按权重从高到低返回语言标签
+// 如 "zh-CN,zh;q=0.9,en;q=0.8" → [zh-CN zh en]
+func parseAcceptLanguage(header string) []string {
+	if header == "" {
+		return nil
+	}
+
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/metadata"
 	"log"
 	"net/http"
 	"time"
@@ -22,6 +23,9 @@ func LoggingMiddleware(cfg *config.LoggingConfig) Handler {
 				logRequest(r, cfg)
 			}
 
+			// 开启上下文审计时检查注入的值是否过多
+			metadata.CheckContextSize(r.Context(), r.URL.Path)
+
 			// 执行下一个处理器
 			next.ServeHTTP(recorder, r)
 
@@ -126,6 +126,14 @@ func CreateMiddlewareChain(cfg *config.GlobalConfig) *Chain {
 		chain = chain.Append(RecoveryMiddleware())
 	}
 
+	// 清除外部传入的代操作请求头，代操作只能由服务端校验权限后发起
+	chain = chain.Append(StripImpersonationHeaders())
+
+	// 链路追踪中间件
+	if cfg.Middleware != nil && cfg.Middleware.EnableTracing {
+		chain = chain.Append(TracingMiddleware(cfg.Middleware.Tracing))
+	}
+
 	// 日志中间件
 	if cfg.Middleware != nil && cfg.Middleware.EnableLogging {
 		chain = chain.Append(LoggingMiddleware(cfg.Middleware.Logging))
@@ -136,6 +144,21 @@ func CreateMiddlewareChain(cfg *config.GlobalConfig) *Chain {
 		chain = chain.Append(CORSMiddleware(cfg.Middleware.CORS))
 	}
 
+	// 语言协商中间件
+	if cfg.Middleware != nil && cfg.Middleware.EnableLocale {
+		chain = chain.Append(LocaleMiddleware(cfg.Middleware.Locale))
+	}
+
+	// CSRF防护中间件，按路由组配置时可单独创建链并追加 CSRFMiddleware
+	if cfg.Middleware != nil && cfg.Middleware.EnableCSRF {
+		chain = chain.Append(CSRFMiddleware(cfg.Middleware.CSRF))
+	}
+
+	// 请求签名中间件，签名路径的报文不加密
+	if cfg.Crypto != nil && cfg.Crypto.Signature != nil && cfg.Crypto.Signature.Enable {
+		chain = chain.Append(SignatureMiddleware(cfg.Crypto.Signature))
+	}
+
 	// 加密中间件（最内层）
 	if cfg.Crypto != nil && cfg.Crypto.Enable {
 		chain = chain.Append(CryptoMiddleware(cfg.Crypto))
@@ -0,0 +1,289 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/QuantumShiftX/golib/xhttp"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认摘流等待时间，就绪探针失败后等待负载均衡摘除实例
+	defaultDrainDelay = 5 * time.Second
+	// 默认关闭超时时间，包括摘流等待和等待处理中的请求
+	defaultShutdownTimeout = 30 * time.Second
+	// 默认停止钩子超时时间，停止钩子使用独立的超时，不占用关闭超时
+	defaultPreStopTimeout = 10 * time.Second
+)
+
+// ShutdownHook 停止钩子，如从注册中心下线、停止消费任务
+type ShutdownHook func(ctx context.Context) error
+
+// namedHook 带名称的停止钩子，用于日志
+type namedHook struct {
+	name string
+	fn   ShutdownHook
+}
+
+// ServerManager 管理 http.Server 的启动和优雅关闭，统一各服务 main.go 中的关闭流程：
+// 收到信号后先将就绪状态置为失败，等待摘流，依次执行停止钩子，再停止接收新请求并等待处理中的请求完成
+type ServerManager struct {
+	server          *http.Server
+	signals         []os.Signal
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	preStopTimeout  time.Duration
+
+	mu           sync.Mutex
+	preStopHooks []namedHook
+	ready        atomic.Bool
+	readySet     atomic.Bool
+	shuttingDown atomic.Bool
+	shutdownOnce sync.Once
+	shutdownErr  error
+	stop         chan struct{}
+	// 取消所有请求上下文，关闭超时后仍未完成的请求以 xhttp.ErrServerShutdown 取消
+	cancelRequests context.CancelCauseFunc
+}
+
+// ServerOption 服务管理选项
+type ServerOption func(m *ServerManager)
+
+// WithShutdownSignals 设置触发关闭的信号，默认 SIGINT、SIGTERM
+func WithShutdownSignals(signals ...os.Signal) ServerOption {
+	return func(m *ServerManager) {
+		m.signals = signals
+	}
+}
+
+// WithDrainDelay 设置摘流等待时间，默认5秒，应不小于就绪探针的检测周期
+func WithDrainDelay(d time.Duration) ServerOption {
+	return func(m *ServerManager) {
+		if d >= 0 {
+			m.drainDelay = d
+		}
+	}
+}
+
+// WithShutdownTimeout 设置关闭超时时间，默认30秒
+func WithShutdownTimeout(d time.Duration) ServerOption {
+	return func(m *ServerManager) {
+		if d > 0 {
+			m.shutdownTimeout = d
+		}
+	}
+}
+
+// WithPreStopTimeout 设置停止钩子的超时时间，默认10秒，与关闭超时分开计算
+func WithPreStopTimeout(d time.Duration) ServerOption {
+	return func(m *ServerManager) {
+		if d > 0 {
+			m.preStopTimeout = d
+		}
+	}
+}
+
+// WithPreStopHook 添加停止钩子
+func WithPreStopHook(name string, hook ShutdownHook) ServerOption {
+	return func(m *ServerManager) {
+		m.preStopHooks = append(m.preStopHooks, namedHook{name: name, fn: hook})
+	}
+}
+
+// NewServerManager 创建服务管理器
+func NewServerManager(server *http.Server, opts ...ServerOption) *ServerManager {
+	m := &ServerManager{
+		server:          server,
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		drainDelay:      defaultDrainDelay,
+		shutdownTimeout: defaultShutdownTimeout,
+		preStopTimeout:  defaultPreStopTimeout,
+		stop:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	// 未自定义 BaseContext 时接管请求上下文，关闭超时后取消的请求不会被识别为客户端断开
+	if server.BaseContext == nil {
+		base, cancel := context.WithCancelCause(context.Background())
+		server.BaseContext = func(net.Listener) context.Context { return base }
+		m.cancelRequests = cancel
+	}
+	return m
+}
+
+// AddPreStopHook 添加停止钩子，摘流后、HTTP服务停止前按添加顺序执行
+func (m *ServerManager) AddPreStopHook(name string, hook ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.preStopHooks = append(m.preStopHooks, namedHook{name: name, fn: hook})
+}
+
+// Ready 是否就绪，关闭开始后总是未就绪
+func (m *ServerManager) Ready() bool {
+	return m.ready.Load() && !m.shuttingDown.Load()
+}
+
+// SetReady 设置就绪状态，如依赖预热完成前可先置为未就绪；
+// 调用过 SetReady 后 Serve 不再自动置为就绪，预热完成后需调用 SetReady(true)
+func (m *ServerManager) SetReady(ready bool) {
+	m.readySet.Store(true)
+	m.ready.Store(ready)
+}
+
+// ReadinessHandler 就绪探针处理器，就绪时返回200，启动中或关闭中返回503
+func (m *ServerManager) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+}
+
+// Run 监听 server.Addr 并阻塞直到收到关闭信号或服务异常退出，返回前已完成优雅关闭
+func (m *ServerManager) Run() error {
+	addr := m.server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+	return m.Serve(ln)
+}
+
+// Serve 在指定监听器上提供服务，阻塞直到收到关闭信号或服务异常退出，返回前已完成优雅关闭
+func (m *ServerManager) Serve(ln net.Listener) error {
+	sigCh := make(chan os.Signal, 1)
+	if len(m.signals) > 0 {
+		signal.Notify(sigCh, m.signals...)
+		defer signal.Stop(sigCh)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if m.server.TLSConfig != nil {
+			errCh <- m.server.ServeTLS(ln, "", "")
+		} else {
+			errCh <- m.server.Serve(ln)
+		}
+	}()
+
+	if !m.readySet.Load() {
+		m.ready.Store(true)
+	}
+	logx.Infof("HTTP server listening on %s", ln.Addr())
+
+	var serveErr error
+	select {
+	case sig := <-sigCh:
+		logx.Infof("Received signal %v, shutting down", sig)
+	case <-m.stop:
+		logx.Info("Shutdown requested")
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			serveErr = fmt.Errorf("http server: %w", err)
+			logx.Errorf("HTTP server exited: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	return errors.Join(serveErr, m.Shutdown(ctx))
+}
+
+// Stop 请求关闭，Run/Serve 随后执行优雅关闭并返回
+func (m *ServerManager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.stop:
+	default:
+		close(m.stop)
+	}
+}
+
+// Shutdown 执行优雅关闭：置为未就绪，等待摘流，依次执行停止钩子，再停止HTTP服务；
+// ctx 用于摘流等待和停止HTTP服务，停止钩子使用独立的超时；
+// 多次调用只执行一次，钩子失败不影响后续钩子执行
+func (m *ServerManager) Shutdown(ctx context.Context) error {
+	m.shutdownOnce.Do(func() {
+		m.shutdownErr = m.shutdown(ctx)
+	})
+	return m.shutdownErr
+}
+
+// shutdown 关闭流程
+func (m *ServerManager) shutdown(ctx context.Context) error {
+	m.shuttingDown.Store(true)
+
+	// 等待负载均衡感知就绪探针失败，期间继续处理请求
+	if m.drainDelay > 0 {
+		logx.Infof("Draining for %v before shutdown", m.drainDelay)
+		timer := time.NewTimer(m.drainDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	errs := m.runPreStopHooks(ctx)
+	if err := m.server.Shutdown(ctx); err != nil {
+		logx.Errorf("HTTP server shutdown: %v", err)
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+	if m.cancelRequests != nil {
+		m.cancelRequests(xhttp.ErrServerShutdown)
+	}
+
+	logx.Info("HTTP server stopped")
+	return errors.Join(errs...)
+}
+
+// runPreStopHooks 依次执行停止钩子，使用独立的超时，不受摘流等待消耗的关闭超时影响
+func (m *ServerManager) runPreStopHooks(ctx context.Context) []error {
+	m.mu.Lock()
+	hooks := append([]namedHook(nil), m.preStopHooks...)
+	m.mu.Unlock()
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	hookCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.preStopTimeout)
+	defer cancel()
+
+	var errs []error
+	for _, hook := range hooks {
+		if err := runShutdownHook(hookCtx, hook); err != nil {
+			logx.Errorf("Shutdown hook %s failed: %v", hook.name, err)
+			errs = append(errs, fmt.Errorf("shutdown hook %s: %w", hook.name, err))
+		}
+	}
+	return errs
+}
+
+// runShutdownHook 执行停止钩子，panic 转为错误
+func runShutdownHook(ctx context.Context, hook namedHook) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return hook.fn(ctx)
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/QuantumShiftX/golib/xhttp"
+)
+
+// startServer 在监听器上启动服务管理器并等待开始处理请求，返回 Serve 的结果
+func startServer(t *testing.T, m *ServerManager, ln net.Listener) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- m.Serve(ln) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+			return done
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server not started: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readiness 请求就绪探针并返回状态码
+func readiness(m *ServerManager) int {
+	w := httptest.NewRecorder()
+	m.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	return w.Code
+}
+
+func TestServerManagerReady(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(m *ServerManager)
+		want  int
+	}{
+		{"ready after serve", func(m *ServerManager) {}, http.StatusOK},
+		{"warm-up keeps not ready", func(m *ServerManager) { m.SetReady(false) }, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := NewServerManager(&http.Server{Handler: http.NotFoundHandler()},
+				WithShutdownSignals(), WithDrainDelay(0))
+			tt.setup(m)
+			done := startServer(t, m, ln)
+
+			if code := readiness(m); code != tt.want {
+				t.Fatalf("readiness = %d, want %d", code, tt.want)
+			}
+			m.SetReady(true)
+			if code := readiness(m); code != http.StatusOK {
+				t.Fatalf("readiness after SetReady(true) = %d", code)
+			}
+
+			m.Stop()
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+			// 关闭后即使调用方置为就绪也保持未就绪
+			m.SetReady(true)
+			if code := readiness(m); code != http.StatusServiceUnavailable {
+				t.Fatalf("readiness after shutdown = %d", code)
+			}
+		})
+	}
+}
+
+func TestServerManagerPreStopHooks(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewServerManager(&http.Server{Handler: http.NotFoundHandler()},
+		WithShutdownSignals(), WithDrainDelay(0), WithPreStopTimeout(time.Minute))
+	done := startServer(t, m, ln)
+
+	var order []string
+	m.AddPreStopHook("serving", func(ctx context.Context) error {
+		// 停止钩子执行时HTTP服务仍在处理请求
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		order = append(order, "serving")
+		return nil
+	})
+	m.AddPreStopHook("failing", func(ctx context.Context) error {
+		order = append(order, "failing")
+		panic("boom")
+	})
+	m.AddPreStopHook("budget", func(ctx context.Context) error {
+		// 关闭超时已用完时停止钩子仍有独立的超时
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 30*time.Second {
+			return errors.New("hook has no budget of its own")
+		}
+		order = append(order, "budget")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = m.Shutdown(ctx)
+	if err == nil {
+		t.Fatal("expected hook failure")
+	}
+	if got := len(order); got != 3 || order[0] != "serving" || order[2] != "budget" {
+		t.Fatalf("hooks run = %v, err = %v", order, err)
+	}
+	<-done
+	if _, err = http.Get("http://" + ln.Addr().String()); err == nil {
+		t.Fatal("server still serving after shutdown")
+	}
+}
+
+func TestServerManagerShutdownIsNotClientClose(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	closed := make(chan bool, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/slow" {
+			return
+		}
+		close(started)
+		<-r.Context().Done()
+		closed <- xhttp.IsClientClosed(r.Context())
+	})
+	m := NewServerManager(&http.Server{Handler: handler}, WithShutdownSignals(), WithDrainDelay(0))
+	done := startServer(t, m, ln)
+
+	go http.Get("http://" + ln.Addr().String() + "/slow")
+	<-started
+
+	// 关闭超时后仍在处理的请求被取消，但不视为客户端断开
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err = m.Shutdown(ctx); err == nil {
+		t.Fatal("expected shutdown timeout")
+	}
+	if <-closed {
+		t.Fatal("server shutdown treated as client disconnect")
+	}
+	<-done
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/crypto"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 请求签名的默认请求头和时间偏差
+const (
+	defaultSignatureHeader          = "X-Signature"
+	defaultSignatureTimestampHeader = "X-Timestamp"
+	defaultSignatureKeyIDHeader     = "X-Key-Id"
+	defaultSignatureNonceHeader     = "X-Nonce"
+	defaultSignatureMaxSkew         = 300
+	defaultSignatureKeyID           = "default"
+)
+
+// 请求体大小上限，防止签名校验时读取过大的请求体
+const maxSignedBodySize = 10 << 20
+
+// nonce 长度范围
+const (
+	minSignatureNonceLen = 8
+	maxSignatureNonceLen = 128
+)
+
+// SignatureOption 请求签名中间件选项
+type SignatureOption func(*signatureOptions)
+
+type signatureOptions struct {
+	nonces crypto.NonceStore
+}
+
+// WithNonceStore 设置 nonce 存储，默认为内存存储，多实例部署时应使用 crypto.NewRedisNonceStore
+func WithNonceStore(store crypto.NonceStore) SignatureOption {
+	return func(o *signatureOptions) {
+		if store != nil {
+			o.nonces = store
+		}
+	}
+}
+
+// SignatureMiddleware 请求签名中间件，只校验 HMAC 签名不加密报文，用于需要明文报文的合作方
+// 只处理 cfg.URI 匹配的路径，签名覆盖方法、规范化路径、查询参数、时间戳、nonce 和请求体，
+// 签名、时间戳不正确或 nonce 重复时返回401；开启 SignResponse 时对响应签名
+func SignatureMiddleware(cfg *config.SignatureConfig, opts ...SignatureOption) Handler {
+	var (
+		keys        = resolveSignatureKeys(cfg)
+		header      = defaultSignatureHeader
+		tsHeader    = defaultSignatureTimestampHeader
+		keyIDHeader = defaultSignatureKeyIDHeader
+		nonceHeader = defaultSignatureNonceHeader
+		maxSkew     = int64(defaultSignatureMaxSkew)
+		options     = signatureOptions{nonces: crypto.NewMemoryNonceStore()}
+	)
+	if cfg != nil {
+		header = cmp.Or(cfg.Header, header)
+		tsHeader = cmp.Or(cfg.TimestampHeader, tsHeader)
+		keyIDHeader = cmp.Or(cfg.KeyIDHeader, keyIDHeader)
+		nonceHeader = cmp.Or(cfg.NonceHeader, nonceHeader)
+		maxSkew = cmp.Or(cfg.MaxSkew, maxSkew)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	// nonce 至少保留到时间戳失效，之后的重放由时间戳校验拒绝
+	nonceTTL := 2 * time.Duration(max(maxSkew, defaultSignatureMaxSkew)) * time.Second
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.ShouldSign(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			keyID := r.Header.Get(keyIDHeader)
+			if keyID == "" {
+				keyID = defaultSignatureKeyID
+			}
+			key, ok := keys[keyID]
+			if !ok {
+				http.Error(w, "unknown signature key", http.StatusUnauthorized)
+				return
+			}
+
+			timestamp, err := strconv.ParseInt(r.Header.Get(tsHeader), 10, 64)
+			if err != nil {
+				http.Error(w, "invalid signature timestamp", http.StatusUnauthorized)
+				return
+			}
+			nonce := r.Header.Get(nonceHeader)
+			if len(nonce) < minSignatureNonceLen || len(nonce) > maxSignatureNonceLen {
+				http.Error(w, "invalid signature nonce", http.StatusUnauthorized)
+				return
+			}
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodySize+1))
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, "read request body failed", http.StatusBadRequest)
+				return
+			}
+			if len(body) > maxSignedBodySize {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			input := crypto.SignatureInput{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Query:     r.URL.Query(),
+				Timestamp: timestamp,
+				Nonce:     nonce,
+				Body:      body,
+			}
+			err = crypto.VerifyRequest(key, input, r.Header.Get(header), time.Duration(maxSkew)*time.Second)
+			if err == nil {
+				// 签名通过后再记录 nonce，避免伪造请求占用合法 nonce
+				var first bool
+				if first, err = options.nonces.Use(r.Context(), keyID+":"+nonce, nonceTTL); err == nil && !first {
+					err = crypto.ErrSignatureReplayed
+				}
+			}
+			if err != nil {
+				logx.WithContext(r.Context()).Infof("[Signature] verify failed: path=%s, key_id=%s, err=%v", r.URL.Path, keyID, err)
+				msg := "invalid signature"
+				switch {
+				case errors.Is(err, crypto.ErrSignatureExpired):
+					msg = "signature expired"
+				case errors.Is(err, crypto.ErrSignatureReplayed):
+					msg = "signature replayed"
+				}
+				http.Error(w, msg, http.StatusUnauthorized)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if !cfg.SignResponse {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			recorder := NewResponseRecorder(w)
+			next.ServeHTTP(recorder, r)
+
+			now := time.Now().Unix()
+			for k, v := range recorder.header {
+				w.Header()[k] = v
+			}
+			w.Header().Set(tsHeader, strconv.FormatInt(now, 10))
+			w.Header().Set(keyIDHeader, keyID)
+			input.Timestamp, input.Body = now, recorder.body.Bytes()
+			w.Header().Set(nonceHeader, nonce)
+			w.Header().Set(header, crypto.SignRequest(key, input))
+			w.WriteHeader(recorder.status)
+			w.Write(recorder.body.Bytes())
+		})
+	}
+}
+
+// resolveSignatureKeys 解析签名密钥引用，解析失败的密钥不可用
+func resolveSignatureKeys(cfg *config.SignatureConfig) map[string][]byte {
+	keys := make(map[string][]byte)
+	if cfg == nil {
+		return keys
+	}
+	for id, ref := range cfg.Keys {
+		key, err := crypto.ResolveKey(context.Background(), ref)
+		if err != nil {
+			logx.Errorf("[Signature] resolve key %s failed: %v", id, err)
+			continue
+		}
+		keys[id] = []byte(key)
+	}
+	return keys
+}
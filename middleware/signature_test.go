@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/crypto"
+)
+
+func TestSignatureMiddleware(t *testing.T) {
+	cfg := &config.SignatureConfig{
+		Enable: true,
+		URI:    []string{"/partner/*"},
+		Keys:   map[string]string{"default": "partner-secret"},
+	}
+	handler := SignatureMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	newRequest := func(target, nonce string, sign func(*crypto.SignatureInput)) *http.Request {
+		body := `{"amount":100}`
+		r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		now := time.Now().Unix()
+		u, _ := url.Parse(target)
+		in := crypto.SignatureInput{Method: http.MethodPost, Path: u.Path, Query: u.Query(), Timestamp: now, Nonce: nonce, Body: []byte(body)}
+		if sign != nil {
+			sign(&in)
+		}
+		r.Header.Set("X-Timestamp", strconv.FormatInt(now, 10))
+		r.Header.Set("X-Nonce", nonce)
+		r.Header.Set("X-Signature", crypto.SignRequest([]byte("partner-secret"), in))
+		return r
+	}
+	serve := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := serve(newRequest("/partner/pay?amount=1", "nonce-0001", nil)); code != http.StatusOK {
+		t.Fatalf("signed request code = %d", code)
+	}
+	if code := serve(newRequest("/partner/pay?amount=1", "nonce-0001", nil)); code != http.StatusUnauthorized {
+		t.Errorf("replayed request code = %d", code)
+	}
+
+	// 签名后篡改查询参数
+	r := newRequest("/partner/pay?amount=1", "nonce-0002", nil)
+	r.URL.RawQuery = "amount=1000"
+	if code := serve(r); code != http.StatusUnauthorized {
+		t.Errorf("tampered query code = %d", code)
+	}
+
+	// 未签名的非规范路径不能绕过校验
+	for _, target := range []string{"//partner/pay", "/public/../partner/pay"} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+		r.URL.Path = target
+		if code := serve(r); code != http.StatusUnauthorized {
+			t.Errorf("unsigned %s code = %d", target, code)
+		}
+	}
+
+	if code := serve(newRequest("/partner/pay", "short", nil)); code != http.StatusUnauthorized {
+		t.Errorf("short nonce code = %d", code)
+	}
+	if code := serve(httptest.NewRequest(http.MethodGet, "/public/info", nil)); code != http.StatusOK {
+		t.Errorf("unsigned path code = %d", code)
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+
+	"github.com/QuantumShiftX/golib/config"
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/zeromicro/go-zero/core/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// W3C traceparent + baggage 传播器
+var tracingPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// TracingMiddleware OpenTelemetry链路追踪中间件
+// 从请求头(W3C traceparent)中恢复上游链路，为每个请求创建服务端Span，
+// 使用 go-zero 的 tracer，因此 trace.TraceIDFromContext 可以直接取到链路ID
+func TracingMiddleware(cfg *config.TracingConfig) Handler {
+	if cfg == nil {
+		cfg = &config.TracingConfig{SampleRatio: 1}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 排除的路径不追踪
+			for _, prefix := range cfg.ExcludePaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			// 提取上游链路上下文，旧服务只传递 x-trace-id 或 B3 请求头时转换为 traceparent
+			ctx := tracingPropagator.Extract(r.Context(), propagation.HeaderCarrier(bridgeTraceHeaders(r.Header)))
+
+			// 上游未采样时按采样率决定是否记录
+			parent := oteltrace.SpanContextFromContext(ctx)
+			if !parent.IsSampled() && rand.Float64() >= cfg.SampleRatio {
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			route := r.Pattern
+			if route == "" {
+				route = r.URL.Path
+			}
+
+			tracer := otel.GetTracerProvider().Tracer(trace.TraceName)
+			ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, route),
+				oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+				oteltrace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+					attribute.String("user_agent.original", r.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			// 将链路ID写入 metadata 和响应头，便于日志关联，旧服务的原始 x-trace-id 保持不变
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				tc := metadata.TraceContext{
+					TraceID: sc.TraceID().String(),
+					SpanID:  sc.SpanID().String(),
+					Sampled: sc.IsSampled(),
+				}
+				if upstream, ok := metadata.ExtractTraceContext(r.Header); ok && upstream.TraceID == tc.TraceID {
+					tc.LegacyTraceID = upstream.LegacyTraceID
+				}
+				ctx = metadata.WithTraceContext(ctx, tc)
+				w.Header().Set(metadata.HeaderTraceID, tc.XTraceID())
+			}
+			tracingPropagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+			if sw.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(sw.status))
+			}
+		})
+	}
+}
+
+// bridgeTraceHeaders 请求未携带 traceparent 时，由 B3 或 x-trace-id 请求头生成，不修改原请求头
+func bridgeTraceHeaders(h http.Header) http.Header {
+	if h.Get(metadata.HeaderTraceparent) != "" {
+		return h
+	}
+	tc, ok := metadata.ExtractTraceContext(h)
+	if !ok || !tc.Valid() {
+		return h
+	}
+
+	h = h.Clone()
+	h.Set(metadata.HeaderTraceparent, tc.Traceparent())
+	return h
+}
+
+// statusWriter 记录响应状态码，不缓冲响应体
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader 记录状态码
+func (w *statusWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 写入响应体
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
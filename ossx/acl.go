@@ -0,0 +1,46 @@
+package ossx
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// ACLStorage 支持对象访问权限的存储，内置存储均已实现
+type ACLStorage interface {
+	// UploadWithACL 按指定访问权限上传
+	UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error)
+	// SetACL 修改已有对象的访问权限
+	SetACL(ctx context.Context, path string, acl configx.ACL) error
+}
+
+// ACLReader 支持读取对象访问权限的存储，内置存储均已实现
+type ACLReader interface {
+	// GetACL 获取对象的访问权限，对象继承存储桶权限时返回空
+	GetACL(ctx context.Context, path string) (configx.ACL, error)
+}
+
+// SetACL 修改已有对象的访问权限
+func (u *UploadManager) SetACL(ctx context.Context, storageType, path string, acl configx.ACL) error {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	as, ok := storage.(ACLStorage)
+	if !ok {
+		return fmt.Errorf("storage type %s does not support ACL", storageType)
+	}
+	return u.withRetry(ctx, "set acl "+path, nil, func() error {
+		return as.SetACL(ctx, path, acl)
+	})
+}
+
+// uploadObject 按访问权限上传，存储不支持ACL时使用其默认权限
+func uploadObject(ctx context.Context, storage Storage, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	if as, ok := storage.(ACLStorage); ok {
+		return as.UploadWithACL(ctx, file, path, contentType, acl)
+	}
+	return storage.Upload(ctx, file, path, contentType)
+}
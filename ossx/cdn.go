@@ -0,0 +1,147 @@
+package ossx
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/google/uuid"
+)
+
+// cdnSigner 生成CDN鉴权URL
+type cdnSigner struct {
+	domain     string
+	authType   string
+	key        string
+	param      string
+	uid        string
+	validity   time.Duration
+	keyPairID  string
+	privateKey *rsa.PrivateKey
+}
+
+// newCDNSigner 根据CDN鉴权配置创建签名器，未配置鉴权时返回 nil
+// defaultParam 为A型鉴权的默认参数名，不同云厂商不同
+func newCDNSigner(domain string, cfg *configx.CDNAuthConfig, defaultParam string) (*cdnSigner, error) {
+	if cfg == nil || cfg.Type == "" {
+		return nil, nil
+	}
+	if domain == "" {
+		return nil, errors.New("cdn auth requires cdn_domain")
+	}
+	if !strings.HasPrefix(domain, "http") {
+		domain = "https://" + domain
+	}
+
+	s := &cdnSigner{
+		domain:   strings.TrimSuffix(domain, "/"),
+		authType: cfg.Type,
+		key:      cfg.Key,
+		param:    cfg.ParamName,
+		uid:      cfg.UID,
+		validity: time.Duration(cfg.ValiditySeconds) * time.Second,
+	}
+
+	switch cfg.Type {
+	case configx.CDNAuthTypeA:
+		if cfg.Key == "" {
+			return nil, errors.New("cdn auth key is required")
+		}
+		if s.param == "" {
+			s.param = defaultParam
+		}
+		if s.uid == "" {
+			s.uid = "0"
+		}
+	case configx.CDNAuthCloudFront:
+		if cfg.KeyPairID == "" {
+			return nil, errors.New("cloudfront key_pair_id is required")
+		}
+		key, err := parseRSAPrivateKey(cfg.Key)
+		if err != nil {
+			return nil, fmt.Errorf("cloudfront private key: %w", err)
+		}
+		s.keyPairID = cfg.KeyPairID
+		s.privateKey = key
+	default:
+		return nil, fmt.Errorf("unsupported cdn auth type: %s", cfg.Type)
+	}
+	return s, nil
+}
+
+// sign 生成对象的CDN签名URL
+func (s *cdnSigner) sign(path string, expiration time.Duration) (string, error) {
+	uri := "/" + strings.TrimPrefix(path, "/")
+	rawURL := s.domain + (&url.URL{Path: uri}).EscapedPath()
+
+	if s.authType == configx.CDNAuthCloudFront {
+		return s.signCloudFront(rawURL, time.Now().Add(expiration))
+	}
+	return s.signTypeA(rawURL, uri, expiration), nil
+}
+
+// signTypeA A型鉴权：md5hash = md5(uri-timestamp-rand-uid-key)
+func (s *cdnSigner) signTypeA(rawURL, uri string, expiration time.Duration) string {
+	timestamp := time.Now()
+	if s.validity > 0 {
+		// CDN按 timestamp+有效时长 判断过期，反推时间戳使URL在 expiration 后过期
+		timestamp = timestamp.Add(expiration - s.validity)
+	}
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	rand := strings.ReplaceAll(uuid.NewString(), "-", "")
+
+	sum := md5.Sum([]byte(strings.Join([]string{uri, ts, rand, s.uid, s.key}, "-")))
+	return fmt.Sprintf("%s?%s=%s-%s-%s-%s", rawURL, s.param, ts, rand, s.uid, hex.EncodeToString(sum[:]))
+}
+
+// signCloudFront CloudFront 预设策略签名：对策略做 RSA-SHA1 签名
+func (s *cdnSigner) signCloudFront(rawURL string, expires time.Time) (string, error) {
+	epoch := expires.Unix()
+	policy := fmt.Sprintf(`{"Statement":[{"Resource":"%s","Condition":{"DateLessThan":{"AWS:EpochTime":%d}}}]}`, rawURL, epoch)
+
+	digest := sha1.Sum([]byte(policy))
+	sig, err := rsa.SignPKCS1v15(nil, s.privateKey, crypto.SHA1, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign cloudfront url: %w", err)
+	}
+
+	return fmt.Sprintf("%s?Expires=%d&Signature=%s&Key-Pair-Id=%s",
+		rawURL, epoch, cloudFrontEncode(sig), url.QueryEscape(s.keyPairID)), nil
+}
+
+// cloudFrontEncode CloudFront 使用的URL安全base64：+ 换为 -，= 换为 _，/ 换为 ~
+func cloudFrontEncode(b []byte) string {
+	return strings.NewReplacer("+", "-", "=", "_", "/", "~").Replace(base64.StdEncoding.EncodeToString(b))
+}
+
+// parseRSAPrivateKey 解析PEM格式的PKCS#1或PKCS#8 RSA私钥
+func parseRSAPrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return rsaKey, nil
+}
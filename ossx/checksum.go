@@ -0,0 +1,190 @@
+package ossx
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// CRC64-ECMA，与阿里云OSS、腾讯云COS返回的 x-oss-hash-crc64ecma / x-cos-hash-crc64ecma 一致
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// checksumReader 读取时计算MD5和CRC64，只对从起始位置连续读取的内容计算，
+// 重试时回到起始位置重新读取的内容不会重复计算
+type checksumReader struct {
+	r     io.Reader
+	base  int64 // 创建时底层读取器的位置
+	mu    sync.Mutex
+	pos   int64 // 相对 base 的读取位置
+	sum   int64 // 已计算的连续字节数
+	gap   bool  // 出现跳跃读取，校验和不完整
+	md5   hash.Hash
+	crc64 hash.Hash64
+}
+
+// newChecksumReader 创建校验和计算读取器
+func newChecksumReader(r io.Reader) *checksumReader {
+	c := &checksumReader{r: r, md5: md5.New(), crc64: crc64.New(crc64Table)}
+	if seeker, ok := r.(io.Seeker); ok {
+		c.base, _ = seeker.Seek(0, io.SeekCurrent)
+	}
+	return c
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.mu.Lock()
+	c.add(p[:n], c.pos)
+	c.pos += int64(n)
+	c.mu.Unlock()
+	return n, err
+}
+
+// add 记录从 off 开始的内容，只计算紧接已计算部分的字节
+func (c *checksumReader) add(p []byte, off int64) {
+	end := off + int64(len(p))
+	switch {
+	case len(p) == 0 || end <= c.sum:
+	case off > c.sum:
+		c.gap = true
+	default:
+		c.md5.Write(p[c.sum-off:])
+		c.crc64.Write(p[c.sum-off:])
+		c.sum = end
+	}
+}
+
+// reader 返回上传使用的读取器，底层读取器支持 Seek、ReadAt 时保留，便于SDK计算长度和并发分片上传
+func (c *checksumReader) reader() io.Reader {
+	if _, ok := c.r.(io.Seeker); !ok {
+		return c
+	}
+	if _, ok := c.r.(io.ReaderAt); ok {
+		return checksumReadSeekerAt{c}
+	}
+	return checksumSeeker{c}
+}
+
+// seek 移动底层读取器的位置
+func (c *checksumReader) seek(offset int64, whence int) (int64, error) {
+	abs, err := c.r.(io.Seeker).Seek(offset, whence)
+	if err == nil {
+		c.mu.Lock()
+		c.pos = abs - c.base
+		c.mu.Unlock()
+	}
+	return abs, err
+}
+
+// checksumSeeker 支持 Seek 的校验和读取器
+type checksumSeeker struct{ *checksumReader }
+
+func (c checksumSeeker) Seek(offset int64, whence int) (int64, error) {
+	return c.seek(offset, whence)
+}
+
+// checksumReadSeekerAt 支持 Seek 和 ReadAt 的校验和读取器
+type checksumReadSeekerAt struct{ *checksumReader }
+
+func (c checksumReadSeekerAt) Seek(offset int64, whence int) (int64, error) {
+	return c.seek(offset, whence)
+}
+
+func (c checksumReadSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.(io.ReaderAt).ReadAt(p, off)
+	c.mu.Lock()
+	c.add(p[:n], off-c.base)
+	c.mu.Unlock()
+	return n, err
+}
+
+// complete 校验和是否覆盖了全部内容
+func (c *checksumReader) complete() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.gap
+}
+
+// MD5 十六进制MD5，内容未连续读取时为空
+func (c *checksumReader) MD5() string {
+	if !c.complete() {
+		return ""
+	}
+	return hex.EncodeToString(c.md5.Sum(nil))
+}
+
+// CRC64 十进制CRC64-ECMA，内容未连续读取时为空
+func (c *checksumReader) CRC64() string {
+	if !c.complete() {
+		return ""
+	}
+	return strconv.FormatUint(c.crc64.Sum64(), 10)
+}
+
+// verifyChecksum 查询对象元信息并与本地校验和比较，返回ETag以及是否已校验
+// 存储返回CRC64（OSS、COS）时比较CRC64，否则比较ETag与MD5；
+// 分片上传、KMS或客户密钥加密的ETag和本地存储生成的ETag不是内容MD5，无法比较，记录日志并返回未校验
+func verifyChecksum(ctx context.Context, storage Storage, path string, sum *checksumReader) (string, bool, error) {
+	rr, ok := storage.(RangeReader)
+	if !ok {
+		return "", false, nil
+	}
+	meta, err := rr.StatObject(ctx, path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to stat uploaded object: %w", err)
+	}
+	etag := strings.ToLower(strings.Trim(meta.ETag, `"`))
+
+	if !sum.complete() {
+		logx.WithContext(ctx).Infof("checksum of %s not verified: content not read sequentially", path)
+		return etag, false, nil
+	}
+	if meta.Size != sum.sum {
+		return etag, true, fmt.Errorf("%w: size %d, uploaded %d", ErrChecksumMismatch, meta.Size, sum.sum)
+	}
+	if meta.CRC64 != "" {
+		if local := sum.CRC64(); meta.CRC64 != local {
+			return etag, true, fmt.Errorf("%w: crc64 %s, local %s", ErrChecksumMismatch, meta.CRC64, local)
+		}
+		return etag, true, nil
+	}
+	if reason := etagNotMD5(etag, meta.Encryption); reason != "" {
+		logx.WithContext(ctx).Infof("checksum of %s not verified: %s", path, reason)
+		return etag, false, nil
+	}
+	if md5Hex := sum.MD5(); etag != md5Hex {
+		return etag, true, fmt.Errorf("%w: etag %s, md5 %s", ErrChecksumMismatch, etag, md5Hex)
+	}
+	return etag, true, nil
+}
+
+// etagNotMD5 ETag不是内容MD5的原因，为空表示可与MD5比较
+func etagNotMD5(etag, encryption string) string {
+	switch enc := strings.ToLower(encryption); {
+	case strings.IndexByte(etag, '-') == 32 && isMD5Hex(etag[:32]):
+		return "multipart upload"
+	case !isMD5Hex(etag):
+		return "etag is not an md5"
+	case enc != "" && enc != "aes256":
+		return "server side encryption " + encryption
+	}
+	return ""
+}
+
+// isMD5Hex 是否为32位十六进制
+func isMD5Hex(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,123 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"hash/crc64"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// statStorage 返回固定元信息的存储
+type statStorage struct {
+	Storage
+	meta ObjectMeta
+}
+
+func (s statStorage) StatObject(ctx context.Context, path string) (*ObjectMeta, error) {
+	return &s.meta, nil
+}
+
+func (s statStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestChecksumReader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	md5Sum := md5.Sum(data)
+	want := hex.EncodeToString(md5Sum[:])
+
+	t.Run("keeps seeker and reader at", func(t *testing.T) {
+		r := newChecksumReader(bytes.NewReader(data)).reader()
+		if _, ok := r.(io.Seeker); !ok {
+			t.Fatal("seeker dropped")
+		}
+		if _, ok := r.(io.ReaderAt); !ok {
+			t.Fatal("reader at dropped")
+		}
+		if _, ok := newChecksumReader(io.MultiReader(bytes.NewReader(data))).reader().(io.Seeker); ok {
+			t.Fatal("non-seekable reader became seekable")
+		}
+	})
+
+	t.Run("reread after rewind", func(t *testing.T) {
+		sum := newChecksumReader(bytes.NewReader(data))
+		r := sum.reader()
+		_, _ = io.CopyN(io.Discard, r, 300)
+		if _, err := r.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, r)
+		if got := sum.MD5(); got != want {
+			t.Fatalf("MD5 = %s, want %s", got, want)
+		}
+		if got := sum.CRC64(); got != strconv.FormatUint(crc64.Checksum(data, crc64Table), 10) {
+			t.Fatalf("CRC64 = %s", got)
+		}
+	})
+
+	t.Run("sequential read at", func(t *testing.T) {
+		sum := newChecksumReader(bytes.NewReader(data))
+		ra := sum.reader().(io.ReaderAt)
+		buf := make([]byte, 500)
+		_, _ = ra.ReadAt(buf, 0)
+		_, _ = ra.ReadAt(buf, 500)
+		if got := sum.MD5(); got != want {
+			t.Fatalf("MD5 = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("out of order read at", func(t *testing.T) {
+		sum := newChecksumReader(bytes.NewReader(data))
+		ra := sum.reader().(io.ReaderAt)
+		buf := make([]byte, 500)
+		_, _ = ra.ReadAt(buf, 500)
+		_, _ = ra.ReadAt(buf, 0)
+		if sum.complete() || sum.MD5() != "" {
+			t.Fatal("incomplete checksum reported as complete")
+		}
+	})
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("hello checksum")
+	md5Sum := md5.Sum(data)
+	md5Hex := hex.EncodeToString(md5Sum[:])
+	crc := strconv.FormatUint(crc64.Checksum(data, crc64Table), 10)
+	size := int64(len(data))
+
+	tests := []struct {
+		name     string
+		meta     ObjectMeta
+		verified bool
+		mismatch bool
+	}{
+		{name: "md5 etag", meta: ObjectMeta{Size: size, ETag: `"` + md5Hex + `"`}, verified: true},
+		{name: "md5 mismatch", meta: ObjectMeta{Size: size, ETag: `"00000000000000000000000000000000"`}, verified: true, mismatch: true},
+		{name: "crc64", meta: ObjectMeta{Size: size, ETag: "kms-etag", CRC64: crc}, verified: true},
+		{name: "crc64 mismatch", meta: ObjectMeta{Size: size, CRC64: "1"}, verified: true, mismatch: true},
+		{name: "size mismatch", meta: ObjectMeta{Size: size + 1, ETag: md5Hex}, verified: true, mismatch: true},
+		{name: "kms", meta: ObjectMeta{Size: size, ETag: "0123456789abcdef0123456789abcdef", Encryption: "aws:kms"}},
+		{name: "sse-s3", meta: ObjectMeta{Size: size, ETag: md5Hex, Encryption: "AES256"}, verified: true},
+		{name: "multipart", meta: ObjectMeta{Size: size, ETag: md5Hex + "-3"}},
+		{name: "local", meta: ObjectMeta{Size: size, ETag: "18a2b-e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := newChecksumReader(bytes.NewReader(data))
+			_, _ = io.Copy(io.Discard, sum.reader())
+
+			_, verified, err := verifyChecksum(context.Background(), statStorage{meta: tt.meta}, "a.txt", sum)
+			if errors.Is(err, ErrChecksumMismatch) != tt.mismatch {
+				t.Fatalf("err = %v, mismatch %v", err, tt.mismatch)
+			}
+			if verified != tt.verified {
+				t.Fatalf("verified = %v, want %v", verified, tt.verified)
+			}
+		})
+	}
+}
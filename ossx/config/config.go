@@ -16,18 +16,51 @@ type StorageConfig struct {
 	Bucket string `json:"bucket,omitempty"`
 	// 访问密钥
 	AccessKey string `json:"access_key,omitempty"`
-	// 密钥
+	// 密钥，本地存储用作签名URL的HMAC密钥
 	SecretKey string `json:"secret_key,omitempty"`
 	// 区域或Endpoint
 	Region string `json:"region,omitempty"`
 	// CDN域名（可选）
 	CdnDomain string `json:"cdn_domain,omitempty"`
+	// CDN URL鉴权（可选），设置后签名URL指向CDN域名而非源站
+	CDNAuth *CDNAuthConfig `json:"cdn_auth,omitempty"`
 	// 基础存储路径
 	BasePath string `json:"base_path,omitempty"`
+	// 自定义Endpoint（S3兼容存储，如 MinIO、Ceph RGW、Cloudflare R2），可省略协议
+	Endpoint string `json:"endpoint,omitempty"`
+	// 使用路径风格访问（endpoint/bucket/key），MinIO、Ceph 通常需要开启
+	ForcePathStyle bool `json:"force_path_style,omitempty"`
+	// Endpoint 未指定协议时使用 http
+	DisableSSL bool `json:"disable_ssl,omitempty"`
 	// 上传配置
 	UploadConfig *UploadConfig `json:"upload_config,omitempty"`
 }
 
+// CDN URL鉴权方式
+const (
+	// CDNAuthTypeA 阿里云/腾讯云CDN A型鉴权：?auth_key=timestamp-rand-uid-md5hash
+	CDNAuthTypeA = "typea"
+	// CDNAuthCloudFront AWS CloudFront 签名URL（预设策略）
+	CDNAuthCloudFront = "cloudfront"
+)
+
+// CDNAuthConfig CDN URL鉴权配置
+type CDNAuthConfig struct {
+	// 鉴权方式: typea, cloudfront
+	Type string `json:"type"`
+	// A型鉴权为鉴权密钥，CloudFront 为PEM格式的RSA私钥
+	Key string `json:"key"`
+	// CloudFront 公钥ID（Key-Pair-Id）
+	KeyPairID string `json:"key_pair_id,omitempty"`
+	// A型鉴权参数名，默认阿里云为 auth_key，腾讯云为 sign
+	ParamName string `json:"param_name,omitempty"`
+	// A型鉴权用户ID，默认0
+	UID string `json:"uid,omitempty"`
+	// A型鉴权在CDN控制台配置的有效时长（秒），设置后按签名URL的过期时间反推时间戳，
+	// 未设置时时间戳为签名时间，过期时间由CDN控制台配置决定
+	ValiditySeconds int64 `json:"validity_seconds,omitempty"`
+}
+
 // UploadConfig 上传限制配置
 type UploadConfig struct {
 	// 最大文件大小（字节）
@@ -38,6 +71,69 @@ type UploadConfig struct {
 	BasePath string `json:"base_path"`
 	// 路径生成器函数
 	PathGenerator func(userId int64, fileType string, fileName string) string `json:"-"`
+	// 文件名生成器，为空时以UUID命名，内容寻址模式下不生效，见 UUIDName、SlugName、ContentHashName、DateShardedName
+	NameGenerator NameGenerator `json:"-"`
+	// 是否启用内容寻址，启用后文件名为内容的sha256值，路径由哈希生成（见 ContentAddressablePathGenerator），不使用 PathGenerator
+	ContentAddressable bool `json:"content_addressable"`
+	// 按文件分类（images、documents 等，见 DetectFileType）设置访问权限
+	ACL map[string]ACL `json:"acl,omitempty"`
+	// 未单独设置的文件分类使用的访问权限，为空时使用存储的默认权限（见 ACLDefault）；
+	// 需要私有上传时设置为 private，已有的公开链接和本地静态服务需先改为签名URL
+	DefaultACL ACL `json:"default_acl,omitempty"`
+	// 上传后比对存储返回的ETag与内容MD5，不一致时删除对象并返回错误
+	VerifyChecksum bool `json:"verify_checksum,omitempty"`
+	// 按文件头检测实际类型（见 ValidateContent），检测到的类型也需在允许列表中，不一致时按实际类型上传
+	SniffContent bool `json:"sniff_content,omitempty"`
+	// 声明的类型、扩展名与文件头不一致时拒绝上传，需开启 SniffContent
+	RejectMismatch bool `json:"reject_mismatch,omitempty"`
+	// 目标路径已存在时不上传也不覆盖，返回已有对象，适用于内容寻址等路径确定的幂等导入
+	IfAbsent bool `json:"if_absent,omitempty"`
+	// 按文件分类设置存储类型，见 StorageClassStandard 等，也可直接使用存储的原生取值
+	StorageClass map[string]string `json:"storage_class,omitempty"`
+	// 未单独设置的文件分类使用的存储类型，为空时使用存储桶默认值
+	DefaultStorageClass string `json:"default_storage_class,omitempty"`
+}
+
+// ACL 对象访问权限
+type ACL string
+
+const (
+	// ACLDefault 存储的默认权限：S3公共可读，OSS、COS继承存储桶权限，本地文件 0644
+	ACLDefault ACL = ""
+	// ACLPublic 公共可读
+	ACLPublic ACL = "public"
+	// ACLPrivate 私有，只能通过签名URL访问
+	ACLPrivate ACL = "private"
+	// ACLAuthenticated 云账号认证用户可读，仅S3支持，其他存储按私有处理
+	ACLAuthenticated ACL = "authenticated"
+)
+
+// ACLFor 获取文件分类的访问权限，未配置时为 ACLDefault，与存储原有行为一致
+func (c *UploadConfig) ACLFor(fileType string) ACL {
+	if acl, ok := c.ACL[fileType]; ok && acl != "" {
+		return acl
+	}
+	return c.DefaultACL
+}
+
+// 通用存储类型，上传和生命周期规则中由各存储转换为对应取值，其他取值原样传给存储
+const (
+	// StorageClassStandard 标准存储
+	StorageClassStandard = "standard"
+	// StorageClassIA 低频访问，S3 STANDARD_IA、OSS IA、COS STANDARD_IA
+	StorageClassIA = "ia"
+	// StorageClassArchive 归档，S3 GLACIER、OSS Archive、COS ARCHIVE
+	StorageClassArchive = "archive"
+	// StorageClassDeepArchive 深度归档，S3 DEEP_ARCHIVE、OSS ColdArchive、COS DEEP_ARCHIVE
+	StorageClassDeepArchive = "deep_archive"
+)
+
+// StorageClassFor 获取文件分类的存储类型，未配置时为空，使用存储桶默认值
+func (c *UploadConfig) StorageClassFor(fileType string) string {
+	if class, ok := c.StorageClass[fileType]; ok && class != "" {
+		return class
+	}
+	return c.DefaultStorageClass
 }
 
 // NewDefaultUploadConfig 创建默认的上传配置
@@ -68,6 +164,28 @@ func NewDefaultUploadConfig() *UploadConfig {
 	}
 }
 
+// NewContentAddressableUploadConfig 创建内容寻址的上传配置
+// 相同内容的文件总是得到相同的路径，重复上传是幂等的
+func NewContentAddressableUploadConfig() *UploadConfig {
+	c := NewDefaultUploadConfig()
+	c.ContentAddressable = true
+	c.PathGenerator = ContentAddressablePathGenerator(c.BasePath)
+	return c
+}
+
+// ContentAddressablePathGenerator 内容寻址路径生成器
+// 文件名需为内容哈希，路径格式: uploads/ab/cd/abcdef....jpg
+func ContentAddressablePathGenerator(basePath string) func(userId int64, fileType string, fileName string) string {
+	return func(userId int64, fileType string, fileName string) string {
+		name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+		if len(name) < 4 {
+			return fmt.Sprintf("%s/%s", basePath, fileName)
+		}
+
+		return fmt.Sprintf("%s/%s/%s/%s", basePath, name[0:2], name[2:4], fileName)
+	}
+}
+
 // ValidateFile 验证文件是否符合上传配置
 func (c *UploadConfig) ValidateFile(header *multipart.FileHeader) error {
 	// 检查文件大小
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// 保留原文件名时文件名主体的最大长度（字符）
+const maxSlugLen = 64
+
+// NameInfo 生成文件名时可用的信息
+type NameInfo struct {
+	// 用户上传时的原文件名
+	OriginalName string
+	// 扩展名（含点），来自原文件名，拉取远程文件时没有扩展名按内容类型推断
+	Ext         string
+	ContentType string
+	// 文件分类，见 DetectFileType
+	FileType string
+	UserID   int64
+	// 内容哈希（sha256），仅生成器实现 ContentHashNamer 且返回 true 时有值
+	ContentHash string
+}
+
+// NameGenerator 文件名生成器，返回的文件名可包含 / 作为子目录，最终路径由 PathGenerator 生成
+type NameGenerator interface {
+	GenerateName(info NameInfo) string
+}
+
+// ContentHashNamer 需要内容哈希的文件名生成器，上传时会先计算内容哈希
+type ContentHashNamer interface {
+	NeedsContentHash() bool
+}
+
+// NameGeneratorFunc 函数形式的文件名生成器
+type NameGeneratorFunc func(info NameInfo) string
+
+// GenerateName 实现 NameGenerator 接口
+func (f NameGeneratorFunc) GenerateName(info NameInfo) string {
+	return f(info)
+}
+
+// UUIDName 以随机UUID命名，如 3f2b...c1.jpg，为默认策略
+func UUIDName() NameGenerator {
+	return NameGeneratorFunc(func(info NameInfo) string {
+		return uuid.NewString() + info.Ext
+	})
+}
+
+// SlugName 保留原文件名，转换为小写并将空白和特殊字符替换为 -，追加8位随机后缀避免重名
+// 如 "My Report (1).PDF" 生成 my-report-1-3f2b9a1c.pdf
+func SlugName() NameGenerator {
+	return NameGeneratorFunc(func(info NameInfo) string {
+		base := strings.TrimSuffix(filepath.Base(info.OriginalName), filepath.Ext(info.OriginalName))
+		return Slugify(base) + "-" + uuid.NewString()[:8] + strings.ToLower(info.Ext)
+	})
+}
+
+// contentHashName 以内容哈希命名
+type contentHashName struct{}
+
+// ContentHashName 以内容sha256命名，相同内容得到相同文件名
+func ContentHashName() NameGenerator {
+	return contentHashName{}
+}
+
+// GenerateName 实现 NameGenerator 接口
+func (contentHashName) GenerateName(info NameInfo) string {
+	return info.ContentHash + strings.ToLower(info.Ext)
+}
+
+// NeedsContentHash 实现 ContentHashNamer 接口
+func (contentHashName) NeedsContentHash() bool {
+	return true
+}
+
+// dateShardedName 按日期分目录
+type dateShardedName struct {
+	layout string
+	inner  NameGenerator
+}
+
+// DateShardedName 在 inner 生成的文件名前加上日期目录，layout 为时间格式，默认 2006/01/02
+// inner 为空时使用 UUIDName，如 2024/01/02/3f2b...c1.jpg
+func DateShardedName(layout string, inner NameGenerator) NameGenerator {
+	if layout == "" {
+		layout = "2006/01/02"
+	}
+	if inner == nil {
+		inner = UUIDName()
+	}
+	return dateShardedName{layout: layout, inner: inner}
+}
+
+// GenerateName 实现 NameGenerator 接口
+func (d dateShardedName) GenerateName(info NameInfo) string {
+	return path.Join(time.Now().Format(d.layout), d.inner.GenerateName(info))
+}
+
+// NeedsContentHash 实现 ContentHashNamer 接口
+func (d dateShardedName) NeedsContentHash() bool {
+	return needsContentHash(d.inner)
+}
+
+// Slugify 将字符串转换为适合作为文件名的形式：保留字母和数字（含中文），其他字符替换为 -，
+// 转换为小写并限制长度，结果为空时返回 file
+func Slugify(s string) string {
+	var (
+		b    strings.Builder
+		n    int
+		dash bool
+	)
+	for _, r := range strings.ToLower(s) {
+		if n >= maxSlugLen {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			n++
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			n++
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "file"
+	}
+	return slug
+}
+
+// NeedsContentHash 上传时是否需要计算内容哈希
+func (c *UploadConfig) NeedsContentHash() bool {
+	return c.ContentAddressable || needsContentHash(c.NameGenerator)
+}
+
+// ObjectPath 按配置生成对象路径：内容寻址模式下由内容哈希生成，如 uploads/ab/cd/abcdef....jpg，不使用 PathGenerator，
+// 相同内容总是得到相同的路径；否则使用 PathGenerator
+func (c *UploadConfig) ObjectPath(userId int64, fileType, fileName string) string {
+	if c.ContentAddressable {
+		return ContentAddressablePathGenerator(c.BasePath)(userId, fileType, fileName)
+	}
+	return c.PathGenerator(userId, fileType, fileName)
+}
+
+// GenerateName 按配置生成文件名：内容寻址模式下为内容哈希，否则使用 NameGenerator，未设置时为UUID
+func (c *UploadConfig) GenerateName(info NameInfo) string {
+	switch {
+	case c.ContentAddressable:
+		return contentHashName{}.GenerateName(info)
+	case c.NameGenerator != nil:
+		return c.NameGenerator.GenerateName(info)
+	default:
+		return uuid.NewString() + info.Ext
+	}
+}
+
+// needsContentHash 生成器是否需要内容哈希
+func needsContentHash(g NameGenerator) bool {
+	h, ok := g.(ContentHashNamer)
+	return ok && h.NeedsContentHash()
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SniffLen 检测文件类型读取的文件头长度
+const SniffLen = 512
+
+// ErrContentTypeMismatch 文件内容与声明的类型或扩展名不一致
+var ErrContentTypeMismatch = errors.New("content type mismatch")
+
+// magicSignature 文件头特征
+type magicSignature struct {
+	offset      int
+	magic       []byte
+	contentType string
+}
+
+var (
+	// 补充 http.DetectContentType 不能识别的类型，先于其检测
+	magicTable = []magicSignature{
+		{offset: 4, magic: []byte("ftypheic"), contentType: "image/heic"},
+		{offset: 4, magic: []byte("ftypheix"), contentType: "image/heic"},
+		{offset: 4, magic: []byte("ftypmif1"), contentType: "image/heif"},
+		{offset: 4, magic: []byte("ftypavif"), contentType: "image/avif"},
+		{offset: 4, magic: []byte("ftypqt  "), contentType: "video/quicktime"},
+		{magic: []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}, contentType: "application/x-ole-storage"},
+		{magic: []byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, contentType: "application/x-7z-compressed"},
+		{magic: []byte("MZ"), contentType: "application/x-msdownload"},
+		{magic: []byte{0x7F, 'E', 'L', 'F'}, contentType: "application/x-executable"},
+	}
+	magicMu sync.RWMutex
+
+	// 容器格式检测结果可对应的具体类型，如 docx 的文件头为 zip
+	containerTypes = map[string][]string{
+		"application/zip": {
+			"application/vnd.openxmlformats-officedocument.",
+			"application/vnd.oasis.opendocument.",
+			"application/epub+zip",
+			"application/java-archive",
+			"application/x-zip-compressed",
+		},
+		"application/x-ole-storage": {"application/msword", "application/vnd.ms-"},
+		"text/plain":                {"text/", "application/json", "application/xml", "application/javascript", "application/x-ndjson"},
+		"text/xml":                  {"application/xml", "image/svg+xml", "application/rss+xml", "application/atom+xml"},
+		"video/mp4":                 {"audio/mp4", "video/quicktime"},
+		"application/ogg":           {"audio/ogg", "video/ogg"},
+		"image/jpeg":                {"image/jpg", "image/pjpeg"},
+		"image/heic":                {"image/heif"},
+		"audio/wave":                {"audio/wav", "audio/x-wav"},
+	}
+)
+
+// RegisterMagic 注册文件头特征，offset 为特征在文件中的偏移，后注册的优先匹配
+func RegisterMagic(contentType string, offset int, magic []byte) {
+	magicMu.Lock()
+	defer magicMu.Unlock()
+	sig := magicSignature{offset: offset, magic: bytes.Clone(magic), contentType: contentType}
+	magicTable = append([]magicSignature{sig}, magicTable...)
+}
+
+// SniffContentType 按文件头检测实际类型，先匹配注册的特征，再使用 http.DetectContentType，返回值不含参数
+func SniffContentType(head []byte) string {
+	magicMu.RLock()
+	for _, sig := range magicTable {
+		if end := sig.offset + len(sig.magic); len(head) >= end && bytes.Equal(head[sig.offset:end], sig.magic) {
+			magicMu.RUnlock()
+			return sig.contentType
+		}
+	}
+	magicMu.RUnlock()
+
+	return baseMediaType(http.DetectContentType(head))
+}
+
+// ValidateContent 按文件头校验文件类型，返回上传使用的类型，未开启 SniffContent 时直接返回声明的类型
+// 声明的类型、扩展名与检测结果一致时使用声明的类型（更具体，如docx），不一致时使用检测到的类型，开启 RejectMismatch 时返回 ErrContentTypeMismatch
+// 检测到的类型同样需在允许列表中
+func (c *UploadConfig) ValidateContent(fileName, declared string, head []byte) (string, error) {
+	if !c.SniffContent {
+		return declared, nil
+	}
+	declared = baseMediaType(declared)
+
+	sniffed := SniffContentType(head)
+	// 无法识别的内容只能信任声明的类型
+	if sniffed == "application/octet-stream" {
+		return declared, nil
+	}
+	if declared == "" {
+		declared = sniffed
+	}
+
+	byExt := baseMediaType(mime.TypeByExtension(strings.ToLower(filepath.Ext(fileName))))
+	switch {
+	case !compatibleType(declared, sniffed):
+		if c.RejectMismatch {
+			return "", fmt.Errorf("%w: 文件内容与类型不符，声明 %s，实际 %s", ErrContentTypeMismatch, declared, sniffed)
+		}
+		declared = sniffed
+	case byExt != "" && !compatibleType(byExt, sniffed):
+		if c.RejectMismatch {
+			return "", fmt.Errorf("%w: 文件内容与扩展名不符，扩展名 %s，实际 %s", ErrContentTypeMismatch, filepath.Ext(fileName), sniffed)
+		}
+	}
+
+	if !c.AllowedTypes[declared] {
+		return "", fmt.Errorf("不支持的文件类型: %s", declared)
+	}
+	return declared, nil
+}
+
+// compatibleType 声明的类型是否与检测结果一致
+func compatibleType(declared, sniffed string) bool {
+	if declared == "" || declared == sniffed {
+		return true
+	}
+	for _, prefix := range containerTypes[sniffed] {
+		if strings.HasPrefix(declared, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// baseMediaType 去掉参数并转为小写，如 text/plain; charset=utf-8 返回 text/plain
+func baseMediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	pngHead  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	zipHead  = []byte("PK\x03\x04\x14\x00\x06\x00")
+	oleHead  = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	exeHead  = []byte("MZ\x90\x00\x03\x00")
+	heicHead = []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00")
+)
+
+func TestSniffContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{"png", pngHead, "image/png"},
+		{"zip", zipHead, "application/zip"},
+		{"ole", oleHead, "application/x-ole-storage"},
+		{"exe", exeHead, "application/x-msdownload"},
+		{"heic", heicHead, "image/heic"},
+		{"html without charset", []byte("<html><body>x"), "text/html"},
+		{"unknown", []byte{0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := SniffContentType(tt.head); got != tt.want {
+			t.Fatalf("%s: SniffContentType = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateContent(t *testing.T) {
+	const docx = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+	tests := []struct {
+		name     string
+		sniff    bool
+		reject   bool
+		file     string
+		declared string
+		head     []byte
+		want     string
+		wantErr  error
+	}{
+		{"sniff disabled", false, true, "a.png", "image/png", exeHead, "image/png", nil},
+		{"match", true, true, "a.png", "image/png; charset=binary", pngHead, "image/png", nil},
+		{"container keeps declared", true, true, "a.docx", docx, zipHead, docx, nil},
+		{"ole keeps declared", true, true, "a.doc", "application/msword", oleHead, "application/msword", nil},
+		{"empty declared uses sniffed", true, true, "a.png", "", pngHead, "image/png", nil},
+		{"unknown trusts declared", true, true, "a.pdf", "application/pdf", []byte{0x01, 0x02}, "application/pdf", nil},
+		{"mismatch replaced", true, false, "a.gif", "image/gif", pngHead, "image/png", nil},
+		{"mismatch rejected", true, true, "a.png", "image/png", exeHead, "", ErrContentTypeMismatch},
+		{"extension rejected", true, true, "a.gif", "image/png", pngHead, "", ErrContentTypeMismatch},
+		{"extension tolerated", true, false, "a.gif", "image/png", pngHead, "image/png", nil},
+		{"sniffed type not allowed", true, false, "a.png", "image/png", exeHead, "", errNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDefaultUploadConfig()
+			c.SniffContent, c.RejectMismatch = tt.sniff, tt.reject
+
+			got, err := c.ValidateContent(tt.file, tt.declared, tt.head)
+			switch {
+			case tt.wantErr == errNotAllowed:
+				if err == nil || errors.Is(err, ErrContentTypeMismatch) {
+					t.Fatalf("err = %v, want not allowed", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil || got != tt.want:
+				t.Fatalf("ValidateContent = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// errNotAllowed 表示期望类型不在允许列表中的错误
+var errNotAllowed = errors.New("not allowed")
+
+func TestRegisterMagic(t *testing.T) {
+	head := []byte("\x00\x00GLB2")
+	if got := SniffContentType(head); got == "model/gltf-binary" {
+		t.Fatal("sniffed before registration")
+	}
+	RegisterMagic("model/gltf-binary", 2, []byte("GLB2"))
+	if got := SniffContentType(head); got != "model/gltf-binary" {
+		t.Fatalf("SniffContentType = %q", got)
+	}
+}
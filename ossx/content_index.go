@@ -0,0 +1,98 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ContentIndex 逻辑文件名到内容键（存储路径）的索引
+type ContentIndex interface {
+	// Put 记录逻辑文件名对应的内容键
+	Put(ctx context.Context, logicalName, contentKey string) error
+	// Get 查询逻辑文件名对应的内容键
+	Get(ctx context.Context, logicalName string) (string, bool, error)
+}
+
+// memoryContentIndex 基于内存的内容索引
+type memoryContentIndex struct {
+	mu    sync.RWMutex
+	items map[string]string
+}
+
+// NewMemoryContentIndex 创建内存内容索引
+func NewMemoryContentIndex() ContentIndex {
+	return &memoryContentIndex{
+		items: make(map[string]string),
+	}
+}
+
+// Put 记录逻辑文件名对应的内容键
+func (m *memoryContentIndex) Put(ctx context.Context, logicalName, contentKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[logicalName] = contentKey
+	return nil
+}
+
+// Get 查询逻辑文件名对应的内容键
+func (m *memoryContentIndex) Get(ctx context.Context, logicalName string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, ok := m.items[logicalName]
+	return key, ok, nil
+}
+
+// SetContentIndex 设置内容索引
+func (u *UploadManager) SetContentIndex(index ContentIndex) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.contentIndex = index
+}
+
+// contentIndexStore 当前的内容索引
+func (u *UploadManager) contentIndexStore() ContentIndex {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.contentIndex
+}
+
+// ResolveContentKey 根据用户ID和逻辑文件名查询内容键
+func (u *UploadManager) ResolveContentKey(ctx context.Context, userId int64, logicalName string) (string, bool, error) {
+	index := u.contentIndexStore()
+	if index == nil {
+		return "", false, nil
+	}
+	return index.Get(ctx, contentLogicalName(userId, logicalName))
+}
+
+// contentLogicalName 生成逻辑文件名索引键
+func contentLogicalName(userId int64, fileName string) string {
+	return fmt.Sprintf("%d:%s", userId, fileName)
+}
+
+// hashContent 计算文件内容的sha256，并返回可重新读取的Reader
+func hashContent(file io.Reader) (string, io.Reader, error) {
+	h := sha256.New()
+
+	// 支持Seek的文件直接计算后回到起始位置，避免整体读入内存
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		if _, err := io.Copy(h, seeker); err != nil {
+			return "", nil, fmt.Errorf("failed to hash file: %w", err)
+		}
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return "", nil, fmt.Errorf("failed to reset file reader: %w", err)
+		}
+		return hex.EncodeToString(h.Sum(nil)), seeker, nil
+	}
+
+	data, err := io.ReadAll(io.TeeReader(file, h))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), bytes.NewReader(data), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	configx "github.com/QuantumShiftX/golib/ossx/config"
 	"github.com/tencentyun/cos-go-sdk-v5"
 	"io"
 	"mime"
@@ -11,16 +12,21 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// COS所有用户组
+const cosAllUsers = "http://cam.qcloud.com/groups/global/AllUsers"
+
 // CosStorage 实现腾讯云COS存储
 type CosStorage struct {
 	client     *cos.Client
 	bucket     string
 	region     string
 	requestURL string
+	cdn        *cdnSigner // CDN鉴权（可选），设置后签名URL指向CDN
 }
 
 type CosStorageConfig struct {
@@ -32,6 +38,8 @@ type CosStorageConfig struct {
 	SecretID string `json:"secret_id"`
 	// 密钥
 	SecretKey string `json:"secret_key"`
+	// CDN鉴权（可选），需同时设置 RequestURL 为CDN域名
+	CDNAuth *configx.CDNAuthConfig `json:"cdn_auth,omitempty"`
 }
 
 // NewCosStorage 创建腾讯云COS存储实例
@@ -47,6 +55,18 @@ func NewCosStorage(c CosStorageConfig) (*CosStorage, error) {
 		return nil, errors.New("cos bucket_url is required")
 	}
 
+	var cdn *cdnSigner
+	if c.CDNAuth != nil {
+		if c.RequestURL == "" {
+			return nil, errors.New("cos cdn auth requires request_url")
+		}
+		signer, err := newCDNSigner(c.RequestURL, c.CDNAuth, "sign")
+		if err != nil {
+			return nil, fmt.Errorf("cos cdn auth: %w", err)
+		}
+		cdn = signer
+	}
+
 	// 解析Bucket URL
 	u, err := url.Parse(c.BucketURL)
 	if err != nil {
@@ -83,24 +103,59 @@ func NewCosStorage(c CosStorageConfig) (*CosStorage, error) {
 		bucket:     bucket,
 		region:     region,
 		requestURL: requestURL,
+		cdn:        cdn,
 	}, nil
 }
 
-// Upload 实现Storage接口的上传方法
+// Upload 实现Storage接口的上传方法，使用存储的默认权限
 func (s *CosStorage) Upload(ctx context.Context, file io.Reader, path, contentType string) (string, error) {
+	return s.UploadWithACL(ctx, file, path, contentType, configx.ACLDefault)
+}
+
+// UploadWithACL 实现 ACLStorage 接口，按指定访问权限上传
+func (s *CosStorage) UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl})
+}
+
+// UploadIfAbsent 实现 ConditionalUploader 接口，使用 x-cos-forbid-overwrite 禁止覆盖，已存在时返回 ErrObjectExists
+func (s *CosStorage) UploadIfAbsent(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl, IfAbsent: true})
+}
+
+// UploadWithOptions 实现 OptionsUploader 接口
+func (s *CosStorage) UploadWithOptions(ctx context.Context, file io.Reader, path, contentType string, opts ObjectOptions) (string, error) {
 	// 标准化路径，处理前导斜杠
 	path = strings.TrimPrefix(path, "/")
 
 	// 创建上传选项
 	opt := &cos.ObjectPutOptions{
+		ACLHeaderOptions: &cos.ACLHeaderOptions{
+			XCosACL: cosACL(opts.ACL),
+		},
 		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
-			ContentType: contentType,
+			ContentType:      contentType,
+			CacheControl:     opts.CacheControl,
+			XCosStorageClass: cosStorageClass(opts.StorageClass),
 		},
 	}
+	if len(opts.Metadata) > 0 {
+		meta := http.Header{}
+		for k, v := range opts.Metadata {
+			meta.Set("x-cos-meta-"+k, v)
+		}
+		opt.ObjectPutHeaderOptions.XCosMetaXXX = &meta
+	}
+	if opts.IfAbsent {
+		opt.ObjectPutHeaderOptions.XOptionHeader = &http.Header{}
+		opt.ObjectPutHeaderOptions.XOptionHeader.Set("x-cos-forbid-overwrite", "true")
+	}
 
 	// 执行文件上传
 	_, err := s.client.Object.Put(ctx, path, file, opt)
 	if err != nil {
+		if opts.IfAbsent && isConflict(err) {
+			return "", fmt.Errorf("%w: %s", ErrObjectExists, path)
+		}
 		return "", fmt.Errorf("failed to upload file to cos: %w", err)
 	}
 
@@ -117,6 +172,60 @@ func (s *CosStorage) Upload(ctx context.Context, file io.Reader, path, contentTy
 	return fileURL, nil
 }
 
+// SetACL 实现 ACLStorage 接口，修改已有对象的访问权限
+func (s *CosStorage) SetACL(ctx context.Context, path string, acl configx.ACL) error {
+	_, err := s.client.Object.PutACL(ctx, strings.TrimPrefix(path, "/"), &cos.ObjectPutACLOptions{
+		Header: &cos.ACLHeaderOptions{XCosACL: cosACL(acl)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set cos object ACL: %w", err)
+	}
+	return nil
+}
+
+// GetACL 实现 ACLReader 接口，所有用户可读时为公共可读，否则为私有
+func (s *CosStorage) GetACL(ctx context.Context, path string) (configx.ACL, error) {
+	result, _, err := s.client.Object.GetACL(ctx, strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get cos object ACL: %w", err)
+	}
+	for _, grant := range result.AccessControlList {
+		if grant.Grantee != nil && grant.Grantee.URI == cosAllUsers &&
+			(grant.Permission == "READ" || grant.Permission == "FULL_CONTROL") {
+			return configx.ACLPublic, nil
+		}
+	}
+	return configx.ACLPrivate, nil
+}
+
+// cosACL 转换为COS对象ACL，未指定时继承存储桶权限，COS不支持认证用户可读，按私有处理
+func cosACL(acl configx.ACL) string {
+	switch acl {
+	case configx.ACLDefault:
+		return "default"
+	case configx.ACLPublic:
+		return "public-read"
+	default:
+		return "private"
+	}
+}
+
+// cosStorageClass 转换为COS存储类型
+func cosStorageClass(class string) string {
+	switch class {
+	case configx.StorageClassStandard:
+		return "STANDARD"
+	case configx.StorageClassIA:
+		return "STANDARD_IA"
+	case configx.StorageClassArchive:
+		return "ARCHIVE"
+	case configx.StorageClassDeepArchive:
+		return "DEEP_ARCHIVE"
+	default:
+		return class
+	}
+}
+
 // Delete 实现Storage接口的删除方法
 func (s *CosStorage) Delete(ctx context.Context, path string) error {
 	// 标准化路径，处理前导斜杠
@@ -160,6 +269,11 @@ func (s *CosStorage) CreateSignedURL(ctx context.Context, path string, expiratio
 	// 标准化路径
 	path = strings.TrimPrefix(path, "/")
 
+	// 配置CDN鉴权时生成CDN签名URL
+	if s.cdn != nil {
+		return s.cdn.sign(path, expiration)
+	}
+
 	// 创建带签名的临时URL
 	signedURL, err := s.client.Object.GetPresignedURL(ctx, http.MethodGet, path, s.client.GetCredential().SecretID, s.client.GetCredential().SecretKey, expiration, nil)
 	if err != nil {
@@ -239,3 +353,195 @@ func (s *CosStorage) ListObjects(ctx context.Context, prefix string, maxKeys int
 
 	return keys, nil
 }
+
+// ListPage 分页列出指定前缀的对象，marker 为上一页最后一个对象的key
+func (s *CosStorage) ListPage(ctx context.Context, prefix, marker string, limit int) ([]ObjectInfo, string, error) {
+	result, _, err := s.client.Bucket.Get(ctx, &cos.BucketGetOptions{
+		Prefix:  strings.TrimPrefix(prefix, "/"),
+		Marker:  marker,
+		MaxKeys: limit,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	objects := make([]ObjectInfo, 0, len(result.Contents))
+	for _, obj := range result.Contents {
+		info := ObjectInfo{
+			Key:  obj.Key,
+			Size: obj.Size,
+			ETag: strings.Trim(obj.ETag, `"`),
+		}
+		if t, err := time.Parse(time.RFC3339, obj.LastModified); err == nil {
+			info.LastModified = t
+		}
+		objects = append(objects, info)
+	}
+
+	if !result.IsTruncated {
+		return objects, "", nil
+	}
+	// 未指定delimiter时COS可能不返回NextMarker，使用最后一个key续传
+	next := result.NextMarker
+	if next == "" && len(objects) > 0 {
+		next = objects[len(objects)-1].Key
+	}
+	return objects, next, nil
+}
+
+// Download 流式下载对象，调用方负责关闭
+func (s *CosStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := s.client.Object.Get(ctx, strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object from COS: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// CopyObject 实现 ObjectCopier 接口，桶内服务端复制
+func (s *CosStorage) CopyObject(ctx context.Context, src, dst string) error {
+	sourceURL := s.client.BaseURL.BucketURL.Host + "/" + strings.TrimPrefix(src, "/")
+	if _, _, err := s.client.Object.Copy(ctx, strings.TrimPrefix(dst, "/"), sourceURL, nil); err != nil {
+		return fmt.Errorf("failed to copy object in COS: %w", err)
+	}
+	return nil
+}
+
+// StatObject 实现 RangeReader 接口，获取对象元信息
+func (s *CosStorage) StatObject(ctx context.Context, path string) (*ObjectMeta, error) {
+	resp, err := s.client.Object.Head(ctx, strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object info from COS: %w", err)
+	}
+
+	meta := &ObjectMeta{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+		CRC64:       resp.Header.Get("x-cos-hash-crc64ecma"),
+		Encryption:  resp.Header.Get("x-cos-server-side-encryption"),
+	}
+	if resp.Header.Get("x-cos-server-side-encryption-customer-algorithm") != "" {
+		meta.Encryption = "SSE-C"
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		meta.LastModified = t
+	}
+	return meta, nil
+}
+
+// DownloadRange 实现 RangeReader 接口，按范围流式下载对象
+func (s *CosStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	resp, err := s.client.Object.Get(ctx, strings.TrimPrefix(path, "/"), &cos.ObjectGetOptions{
+		Range: rangeHeader(offset, length),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object range from COS: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// PutLifecycle 实现 LifecycleStorage 接口，设置存储桶生命周期规则
+func (s *CosStorage) PutLifecycle(ctx context.Context, rules []LifecycleRule) error {
+	cosRules := make([]cos.BucketLifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		rule := cos.BucketLifecycleRule{
+			ID:     r.ID,
+			Status: lifecycleStatus(r.Disabled),
+			Filter: &cos.BucketLifecycleFilter{Prefix: r.Prefix},
+		}
+		for _, t := range r.Transitions {
+			rule.Transition = append(rule.Transition, cos.BucketLifecycleTransition{
+				Days:         t.Days,
+				StorageClass: cosStorageClass(t.StorageClass),
+			})
+		}
+		if r.ExpirationDays > 0 {
+			rule.Expiration = &cos.BucketLifecycleExpiration{Days: r.ExpirationDays}
+		}
+		if r.AbortMultipartDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &cos.BucketLifecycleAbortIncompleteMultipartUpload{
+				DaysAfterInitiation: r.AbortMultipartDays,
+			}
+		}
+		cosRules = append(cosRules, rule)
+	}
+
+	if _, err := s.client.Bucket.PutLifecycle(ctx, &cos.BucketPutLifecycleOptions{Rules: cosRules}); err != nil {
+		return fmt.Errorf("failed to put cos bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// GetLifecycle 实现 LifecycleStorage 接口，获取存储桶生命周期规则
+func (s *CosStorage) GetLifecycle(ctx context.Context) ([]LifecycleRule, error) {
+	result, _, err := s.client.Bucket.GetLifecycle(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cos bucket lifecycle: %w", err)
+	}
+
+	rules := make([]LifecycleRule, 0, len(result.Rules))
+	for _, r := range result.Rules {
+		rule := LifecycleRule{
+			ID:       r.ID,
+			Disabled: r.Status != lifecycleStatus(false),
+		}
+		if r.Filter != nil {
+			rule.Prefix = r.Filter.Prefix
+			rule.unsupportedIf(r.Filter.And != nil, "filter.and")
+			rule.unsupportedIf(r.Filter.Tag != nil, "filter.tag")
+		}
+		for _, t := range r.Transition {
+			rule.unsupportedIf(t.Date != "", "transition.date")
+			rule.unsupportedIf(t.AccessFrequency != nil, "transition.access_frequency")
+			rule.Transitions = append(rule.Transitions, LifecycleTransition{
+				Days:         t.Days,
+				StorageClass: t.StorageClass,
+			})
+		}
+		if r.Expiration != nil {
+			rule.unsupportedIf(r.Expiration.Date != "", "expiration.date")
+			rule.unsupportedIf(r.Expiration.ExpiredObjectDeleteMarker, "expiration.expired_object_delete_marker")
+			rule.ExpirationDays = r.Expiration.Days
+		}
+		rule.unsupportedIf(r.NoncurrentVersionExpiration != nil || len(r.NoncurrentVersionTransition) > 0, "noncurrent_version")
+		if r.AbortIncompleteMultipartUpload != nil {
+			rule.AbortMultipartDays = r.AbortIncompleteMultipartUpload.DaysAfterInitiation
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DeleteLifecycle 实现 LifecycleStorage 接口，删除存储桶生命周期规则
+func (s *CosStorage) DeleteLifecycle(ctx context.Context) error {
+	if _, err := s.client.Bucket.DeleteLifecycle(ctx); err != nil {
+		return fmt.Errorf("failed to delete cos bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// PresignPut 实现 PresignedUploader 接口，签名包含 Content-Type、Content-Length 和禁止覆盖
+func (s *CosStorage) PresignPut(ctx context.Context, path, contentType string, size int64, expiration time.Duration) (string, map[string]string, error) {
+	path = strings.TrimPrefix(path, "/")
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.FormatInt(size, 10))
+	header.Set("x-cos-forbid-overwrite", "true")
+	signedURL, err := s.client.Object.GetPresignedURL(ctx, http.MethodPut, path,
+		s.client.GetCredential().SecretID, s.client.GetCredential().SecretKey, expiration,
+		&cos.PresignedURLOptions{Header: &header})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create presigned put URL: %w", err)
+	}
+
+	return signedURL.String(), map[string]string{
+		"Content-Type":           contentType,
+		"Content-Length":         strconv.FormatInt(size, 10),
+		"x-cos-forbid-overwrite": "true",
+	}, nil
+}
@@ -0,0 +1,266 @@
+package ossx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// DedupEntry 去重索引记录的已有对象
+type DedupEntry struct {
+	URL          string `json:"url"`
+	RelativePath string `json:"relative_path"`
+	FileName     string `json:"file_name"`
+	FileType     string `json:"file_type"`
+	Size         int64  `json:"size"`
+}
+
+// DedupIndex 内容哈希到已有对象的去重索引，按存储类型和范围隔离
+// 范围由上传用户和访问权限组成，不同用户或不同访问权限的相同内容不会共用对象
+type DedupIndex interface {
+	// Lookup 查询范围内内容哈希对应的对象
+	Lookup(ctx context.Context, storageType, scope, hash string) (*DedupEntry, bool, error)
+	// Store 记录范围内内容哈希对应的对象
+	Store(ctx context.Context, storageType, scope, hash string, entry DedupEntry) error
+	// Remove 删除指向该对象路径的所有记录，对象被删除后调用
+	Remove(ctx context.Context, storageType, path string) error
+}
+
+// memoryDedupIndex 基于内存的去重索引
+type memoryDedupIndex struct {
+	mu    sync.RWMutex
+	items map[string]DedupEntry
+	paths map[string]map[string]struct{} // 对象路径到索引键的反向索引
+}
+
+// NewMemoryDedupIndex 创建内存去重索引，仅适用于单实例
+func NewMemoryDedupIndex() DedupIndex {
+	return &memoryDedupIndex{
+		items: make(map[string]DedupEntry),
+		paths: make(map[string]map[string]struct{}),
+	}
+}
+
+// Lookup 查询范围内内容哈希对应的对象
+func (m *memoryDedupIndex) Lookup(ctx context.Context, storageType, scope, hash string) (*DedupEntry, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.items[dedupKey(storageType, scope, hash)]
+	if !ok {
+		return nil, false, nil
+	}
+	return &entry, true, nil
+}
+
+// Store 记录范围内内容哈希对应的对象，已存在时保留先上传的对象
+func (m *memoryDedupIndex) Store(ctx context.Context, storageType, scope, hash string, entry DedupEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := dedupKey(storageType, scope, hash)
+	if _, ok := m.items[key]; ok {
+		return nil
+	}
+	m.items[key] = entry
+
+	pathKey := dedupPathKey(storageType, entry.RelativePath)
+	if m.paths[pathKey] == nil {
+		m.paths[pathKey] = make(map[string]struct{})
+	}
+	m.paths[pathKey][key] = struct{}{}
+	return nil
+}
+
+// Remove 删除指向该对象路径的所有记录
+func (m *memoryDedupIndex) Remove(ctx context.Context, storageType, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pathKey := dedupPathKey(storageType, path)
+	for key := range m.paths[pathKey] {
+		delete(m.items, key)
+	}
+	delete(m.paths, pathKey)
+	return nil
+}
+
+// redisDedupIndex 基于Redis的去重索引
+type redisDedupIndex struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisDedupIndex 创建Redis去重索引，ttl 为0时永不过期
+func NewRedisDedupIndex(rdb redis.UniversalClient, prefix string, ttl time.Duration) DedupIndex {
+	if prefix == "" {
+		prefix = "ossx:dedup:"
+	}
+	return &redisDedupIndex{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// Lookup 查询范围内内容哈希对应的对象
+func (r *redisDedupIndex) Lookup(ctx context.Context, storageType, scope, hash string) (*DedupEntry, bool, error) {
+	data, err := r.rdb.Get(ctx, r.prefix+dedupKey(storageType, scope, hash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup dedup index: %w", err)
+	}
+
+	var entry DedupEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false, fmt.Errorf("failed to decode dedup entry: %w", err)
+	}
+	return &entry, true, nil
+}
+
+// Store 记录范围内内容哈希对应的对象，已存在时保留先上传的对象，同时记录对象路径的反向索引
+func (r *redisDedupIndex) Store(ctx context.Context, storageType, scope, hash string, entry DedupEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dedup entry: %w", err)
+	}
+	key := r.prefix + dedupKey(storageType, scope, hash)
+	ok, err := r.rdb.SetNX(ctx, key, data, r.ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store dedup entry: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	pathKey := r.prefix + dedupPathKey(storageType, entry.RelativePath)
+	_, err = r.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, pathKey, key)
+		if r.ttl > 0 {
+			pipe.Expire(ctx, pathKey, r.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store dedup path index: %w", err)
+	}
+	return nil
+}
+
+// Remove 删除指向该对象路径的所有记录
+func (r *redisDedupIndex) Remove(ctx context.Context, storageType, path string) error {
+	pathKey := r.prefix + dedupPathKey(storageType, path)
+	keys, err := r.rdb.SMembers(ctx, pathKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove dedup entry: %w", err)
+	}
+	// 集群模式下各键可能位于不同slot，逐个删除
+	for _, key := range append(keys, pathKey) {
+		if err := r.rdb.Del(ctx, key).Err(); err != nil {
+			return fmt.Errorf("failed to remove dedup entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// dedupKey 去重索引键
+func dedupKey(storageType, scope, hash string) string {
+	return storageType + ":" + scope + ":" + hash
+}
+
+// dedupPathKey 对象路径反向索引键
+func dedupPathKey(storageType, path string) string {
+	return storageType + ":path:" + strings.TrimPrefix(path, "/")
+}
+
+// dedupScope 去重范围，只在同一用户、同一访问权限的上传之间共用对象
+func dedupScope(userId int64, acl configx.ACL) string {
+	return strconv.FormatInt(userId, 10) + ":" + string(acl)
+}
+
+// SetDedupIndex 开启内容去重：上传前计算sha256，同一用户以相同访问权限上传过相同内容时直接返回已有对象，为空时关闭
+func (u *UploadManager) SetDedupIndex(index DedupIndex) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.dedup = index
+}
+
+// dedupIndex 当前的去重索引，未开启时为空
+func (u *UploadManager) dedupIndex() DedupIndex {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.dedup
+}
+
+// lookupDedup 查询去重索引，命中且对象仍存在时生成已有对象的上传结果；索引不可用时继续正常上传
+func (u *UploadManager) lookupDedup(ctx context.Context, dedup DedupIndex, storage Storage, storageType, scope, hash string, expiration time.Duration) (*UploadResult, bool) {
+	entry, ok, err := dedup.Lookup(ctx, storageType, scope, hash)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to lookup dedup index: %v", err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	// 对象已被删除时清理失效的记录
+	if rr, ok := storage.(RangeReader); ok {
+		if _, err := rr.StatObject(ctx, entry.RelativePath); isNotFound(err) {
+			if err := dedup.Remove(ctx, storageType, entry.RelativePath); err != nil {
+				logx.WithContext(ctx).Errorf("failed to remove dedup entry for %s: %v", entry.RelativePath, err)
+			}
+			return nil, false
+		}
+	}
+
+	result := &UploadResult{
+		URL:          entry.URL,
+		SignedURL:    entry.URL,
+		RelativePath: entry.RelativePath,
+		FileName:     entry.FileName,
+		FileType:     entry.FileType,
+		Size:         entry.Size,
+		StorageType:  storageType,
+		ContentHash:  hash,
+		Deduplicated: true,
+	}
+
+	signedURL, err := u.createSignedURL(ctx, storageType, storage, entry.RelativePath, expiration)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to generate signed URL: %v", err)
+	} else if signedURL != entry.URL {
+		result.SignedURL = signedURL
+		result.SignedURLExpire = time.Now().Add(expiration).Unix()
+	}
+	return result, true
+}
+
+// storeDedup 记录上传结果到去重索引，失败只记录日志
+func (u *UploadManager) storeDedup(ctx context.Context, dedup DedupIndex, scope, hash string, result *UploadResult) {
+	err := dedup.Store(ctx, result.StorageType, scope, hash, DedupEntry{
+		URL:          result.URL,
+		RelativePath: result.RelativePath,
+		FileName:     result.FileName,
+		FileType:     result.FileType,
+		Size:         result.Size,
+	})
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to store dedup entry for %s: %v", result.RelativePath, err)
+	}
+}
+
+// removeDedup 对象删除后清理去重索引，失败只记录日志
+func (u *UploadManager) removeDedup(ctx context.Context, storageType, path string) {
+	dedup := u.dedupIndex()
+	if dedup == nil {
+		return
+	}
+	if err := dedup.Remove(ctx, storageType, path); err != nil {
+		logx.WithContext(ctx).Errorf("failed to remove dedup entry for %s: %v", path, err)
+	}
+}
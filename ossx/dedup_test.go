@@ -0,0 +1,92 @@
+package ossx
+
+import (
+	"context"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestUploadDedup(t *testing.T) {
+	u, _ := newTestManager(t)
+	u.SetDedupIndex(NewMemoryDedupIndex())
+	ctx := context.Background()
+
+	first, err := uploadBytes(u, 1, "a.png", testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userId  int64
+		opts    []UploadOption
+		deduped bool
+	}{
+		{name: "same user", userId: 1, deduped: true},
+		{name: "other user", userId: 2},
+		{name: "other acl", userId: 1, opts: []UploadOption{WithACL(configx.ACLPublic)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := uploadBytes(u, tt.userId, "b.png", testPNG, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Deduplicated != tt.deduped || (result.RelativePath == first.RelativePath) != tt.deduped {
+				t.Fatalf("result = %+v, first = %s", result, first.RelativePath)
+			}
+		})
+	}
+
+	// 删除后不再返回已删除的对象
+	if err = u.Delete(ctx, Local, first.RelativePath); err != nil {
+		t.Fatal(err)
+	}
+	result, err := uploadBytes(u, 1, "c.png", testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deduplicated {
+		t.Fatalf("deleted object returned: %+v", result)
+	}
+}
+
+func TestDedupIndexes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	indexes := map[string]DedupIndex{
+		"memory": NewMemoryDedupIndex(),
+		"redis":  NewRedisDedupIndex(rdb, "", 0),
+	}
+	for name, index := range indexes {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			entry := DedupEntry{RelativePath: "/images/1/a.png"}
+			if err := index.Store(ctx, Local, "1:private", "h", entry); err != nil {
+				t.Fatal(err)
+			}
+			// 已存在时保留先上传的对象
+			if err := index.Store(ctx, Local, "1:private", "h", DedupEntry{RelativePath: "/images/1/b.png"}); err != nil {
+				t.Fatal(err)
+			}
+			if got, ok, err := index.Lookup(ctx, Local, "1:private", "h"); err != nil || !ok || got.RelativePath != entry.RelativePath {
+				t.Fatalf("Lookup = %+v, %v, %v", got, ok, err)
+			}
+			if _, ok, _ := index.Lookup(ctx, Local, "2:private", "h"); ok {
+				t.Fatal("lookup crossed scopes")
+			}
+
+			if err := index.Remove(ctx, Local, "images/1/a.png"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok, _ := index.Lookup(ctx, Local, "1:private", "h"); ok {
+				t.Fatal("entry not removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ErrObjectExists 条件上传时目标对象已存在
+var ErrObjectExists = errors.New("object already exists")
+
+// ConditionalUploader 支持条件上传（目标不存在时才写入）的存储，内置存储均已实现
+type ConditionalUploader interface {
+	// UploadIfAbsent 目标不存在时上传，已存在时返回 ErrObjectExists，不覆盖已有对象
+	UploadIfAbsent(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error)
+}
+
+// Exists 通过HEAD请求判断对象是否存在，存储需实现 RangeReader
+func (u *UploadManager) Exists(ctx context.Context, storageType, path string) (bool, error) {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return false, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	return objectExists(ctx, storage, path)
+}
+
+// objectExists 判断对象是否存在
+func objectExists(ctx context.Context, storage Storage, path string) (bool, error) {
+	rr, ok := storage.(RangeReader)
+	if !ok {
+		return false, errors.New("storage does not support stat")
+	}
+	if _, err := rr.StatObject(ctx, path); err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// uploadObjectIfAbsent 目标不存在时按访问权限上传，已存在时返回 ErrObjectExists
+// 存储不支持条件上传时先检查是否存在再上传，检查与上传之间并发写入的对象可能被覆盖
+func uploadObjectIfAbsent(ctx context.Context, storage Storage, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	if cu, ok := storage.(ConditionalUploader); ok {
+		return cu.UploadIfAbsent(ctx, file, path, contentType, acl)
+	}
+
+	exists, err := objectExists(ctx, storage, path)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", fmt.Errorf("%w: %s", ErrObjectExists, path)
+	}
+	return uploadObject(ctx, storage, file, path, contentType, acl)
+}
+
+// isConflict 条件写入是否因对象已存在失败，S3返回412，OSS和COS返回409
+func isConflict(err error) bool {
+	code, ok := errorStatusCode(err)
+	return ok && (code == http.StatusPreconditionFailed || code == http.StatusConflict)
+}
+
+// existingResult 条件上传时目标已存在，按已有对象生成上传结果，URL 为签名URL
+func (u *UploadManager) existingResult(ctx context.Context, storage Storage, storageType, path, fileType, contentHash string, expiration time.Duration) *UploadResult {
+	result := &UploadResult{
+		RelativePath: "/" + strings.TrimPrefix(path, "/"),
+		FileName:     filepath.Base(path),
+		FileType:     fileType,
+		StorageType:  storageType,
+		ContentHash:  contentHash,
+		Existed:      true,
+	}
+	if rr, ok := storage.(RangeReader); ok {
+		if meta, err := rr.StatObject(ctx, path); err == nil {
+			result.Size = meta.Size
+			result.ETag = meta.ETag
+		}
+	}
+
+	signedURL, err := u.createSignedURL(ctx, storageType, storage, path, expiration)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to generate signed URL: %v", err)
+		return result
+	}
+	if signedURL == "" {
+		return result
+	}
+	result.URL, result.SignedURL = signedURL, signedURL
+	result.SignedURLExpire = time.Now().Add(expiration).Unix()
+	return result
+}
@@ -0,0 +1,179 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// fakeS3 最小的S3兼容服务，支持 PUT、HEAD 和 If-None-Match: * 条件写入，记录上传请求头
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]http.Header
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, exists := f.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodPut:
+		_, _ = io.Copy(io.Discard, r.Body)
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = io.WriteString(w, `<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`)
+			return
+		}
+		f.objects[r.URL.Path] = r.Header.Clone()
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// header 对象上传时的请求头
+func (f *fakeS3) header(key string) http.Header {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects["/bucket/"+key]
+}
+
+// newFakeS3Storage 创建连接 fakeS3 的S3存储
+func newFakeS3Storage(t *testing.T) (*fakeS3, Storage) {
+	t.Helper()
+	f := &fakeS3{objects: make(map[string]http.Header)}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	storage, err := newS3Storage(configx.StorageConfig{
+		Type:           AmazonS3,
+		Bucket:         "bucket",
+		AccessKey:      "ak",
+		SecretKey:      "sk",
+		Endpoint:       srv.URL,
+		ForcePathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, storage
+}
+
+// basicStorage 只实现 Storage 和 RangeReader 的存储，不支持条件上传
+type basicStorage struct {
+	Storage
+	RangeReader
+}
+
+func TestPutObjectIfAbsent(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		storage func(t *testing.T) Storage
+	}{
+		{"local", func(t *testing.T) Storage {
+			l, err := newLocalStorage(localStorageConfig(t.TempDir()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return l
+		}},
+		{"s3", func(t *testing.T) Storage {
+			_, s := newFakeS3Storage(t)
+			return s
+		}},
+		{"stat fallback", func(t *testing.T) Storage {
+			l, err := newLocalStorage(localStorageConfig(t.TempDir()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return basicStorage{l, l.(RangeReader)}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := tt.storage(t)
+
+			if exists, err := objectExists(ctx, storage, "a/b.png"); err != nil || exists {
+				t.Fatalf("objectExists before upload = %v, %v", exists, err)
+			}
+			opts := ObjectOptions{IfAbsent: true}
+			if _, err := putObject(ctx, storage, bytes.NewReader(testPNG), "a/b.png", "image/png", opts); err != nil {
+				t.Fatal(err)
+			}
+			if exists, err := objectExists(ctx, storage, "a/b.png"); err != nil || !exists {
+				t.Fatalf("objectExists after upload = %v, %v", exists, err)
+			}
+			if _, err := putObject(ctx, storage, bytes.NewReader(testPNG), "a/b.png", "image/png", opts); !errors.Is(err, ErrObjectExists) {
+				t.Fatalf("second upload err = %v, want ErrObjectExists", err)
+			}
+			// 未要求条件写入时覆盖
+			if _, err := putObject(ctx, storage, bytes.NewReader(testPNG), "a/b.png", "image/png", ObjectOptions{}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUploadIfAbsent(t *testing.T) {
+	newer := append(bytes.Clone(testPNG), "newer"...)
+
+	tests := []struct {
+		name        string
+		config      bool
+		opts        []UploadOption
+		wantExisted bool
+		wantContent []byte
+	}{
+		{"overwrite", false, nil, false, newer},
+		{"option", false, []UploadOption{WithIfAbsent()}, true, testPNG},
+		{"config", true, nil, true, testPNG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, dir := newTestManager(t)
+			u.uploadConfig.IfAbsent = tt.config
+			opts := append([]UploadOption{withObjectPath("fixed/a.png")}, tt.opts...)
+
+			if _, err := uploadBytes(u, 7, "a.png", testPNG, opts...); err != nil {
+				t.Fatal(err)
+			}
+			result, err := uploadBytes(u, 7, "a.png", newer, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Existed != tt.wantExisted || result.RelativePath != "/fixed/a.png" {
+				t.Fatalf("result = %+v", result)
+			}
+			if tt.wantExisted && (result.Size != int64(len(testPNG)) || !strings.Contains(result.SignedURL, "fixed/a.png")) {
+				t.Fatalf("existing result = %+v", result)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "fixed/a.png"))
+			if err != nil || !bytes.Equal(got, tt.wantContent) {
+				t.Fatalf("content = %q, %v", got, err)
+			}
+			if exists, err := u.Exists(context.Background(), Local, "fixed/a.png"); err != nil || !exists {
+				t.Fatalf("Exists = %v, %v", exists, err)
+			}
+		})
+	}
+}
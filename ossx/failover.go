@@ -0,0 +1,326 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认连续失败多少次后认为主存储不可用
+	defaultFailureThreshold = 3
+	// 默认健康探测间隔
+	defaultProbeInterval = 30 * time.Second
+	// 默认健康探测对象
+	defaultProbePath = ".ossx-health"
+	// 默认复制并发数
+	defaultReplicateWorkers = 2
+	// 默认复制队列长度
+	defaultReplicateQueueSize = 1000
+)
+
+// ErrReplicationQueueFull 复制队列已满，对象未复制到备存储
+var ErrReplicationQueueFull = errors.New("replication queue full")
+
+// FailoverPolicy 主备存储策略：写入主存储后异步复制到备存储，
+// 主存储不可用时读取和签名URL切换到备存储，探测恢复后切回
+type FailoverPolicy struct {
+	// 主存储类型，需实现 ObjectReader 用于复制
+	Primary string
+	// 备存储类型
+	Secondary string
+	// 连续失败多少次后认为主存储不可用，默认3
+	FailureThreshold int
+	// 健康探测间隔，默认30秒
+	ProbeInterval time.Duration
+	// 健康探测，返回错误表示不可用；默认查询探测对象元信息，对象不存在视为可用
+	HealthCheck func(ctx context.Context, storage Storage) error
+	// 复制并发数，默认2
+	ReplicateWorkers int
+	// 复制队列长度，默认1000，队列满时放弃复制
+	ReplicateQueueSize int
+	// 复制失败回调，可用于记录待补偿的对象
+	OnReplicateError func(path string, err error)
+}
+
+// FailoverStatus 主备存储状态
+type FailoverStatus struct {
+	Primary             string `json:"primary"`
+	Secondary           string `json:"secondary"`
+	PrimaryHealthy      bool   `json:"primary_healthy"`
+	PendingReplications int    `json:"pending_replications"`
+}
+
+// replicateJob 复制任务，deleted 为 true 时删除备存储中的对象
+type replicateJob struct {
+	path        string
+	contentType string
+	acl         configx.ACL
+	deleted     bool
+}
+
+// failover 主备存储切换和复制
+type failover struct {
+	policy    FailoverPolicy
+	primary   Storage
+	secondary Storage
+	reader    ObjectReader
+	u         *UploadManager
+
+	failures atomic.Int32
+	healthy  atomic.Bool
+	queue    chan replicateJob
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// SetFailover 设置主备存储策略并启动复制和健康探测，替换或为空时停止原有的复制和健康探测
+func (u *UploadManager) SetFailover(policy *FailoverPolicy) error {
+	if policy == nil {
+		u.swapFailover(nil)
+		return nil
+	}
+
+	primary, ok := u.storages[policy.Primary]
+	if !ok {
+		return fmt.Errorf("storage type %s not initialized", policy.Primary)
+	}
+	secondary, ok := u.storages[policy.Secondary]
+	if !ok {
+		return fmt.Errorf("storage type %s not initialized", policy.Secondary)
+	}
+	if policy.Primary == policy.Secondary {
+		return errors.New("primary and secondary storage must differ")
+	}
+	reader, ok := primary.(ObjectReader)
+	if !ok {
+		return fmt.Errorf("storage type %s does not support download", policy.Primary)
+	}
+
+	p := *policy
+	if p.FailureThreshold <= 0 {
+		p.FailureThreshold = defaultFailureThreshold
+	}
+	if p.ProbeInterval <= 0 {
+		p.ProbeInterval = defaultProbeInterval
+	}
+	if p.HealthCheck == nil {
+		p.HealthCheck = statHealthCheck
+	}
+	if p.ReplicateWorkers <= 0 {
+		p.ReplicateWorkers = defaultReplicateWorkers
+	}
+	if p.ReplicateQueueSize <= 0 {
+		p.ReplicateQueueSize = defaultReplicateQueueSize
+	}
+
+	f := &failover{
+		policy:    p,
+		primary:   primary,
+		secondary: secondary,
+		reader:    reader,
+		u:         u,
+		queue:     make(chan replicateJob, p.ReplicateQueueSize),
+		done:      make(chan struct{}),
+	}
+	f.healthy.Store(true)
+	f.start()
+	u.swapFailover(f)
+	return nil
+}
+
+// swapFailover 替换主备存储状态，在锁外停止原有的复制和健康探测，复制任务读取配置时不会死锁
+func (u *UploadManager) swapFailover(f *failover) {
+	u.mu.Lock()
+	old := u.failover
+	u.failover = f
+	u.mu.Unlock()
+	if old != nil {
+		old.stop()
+	}
+}
+
+// currentFailover 当前的主备存储状态
+func (u *UploadManager) currentFailover() *failover {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.failover
+}
+
+// FailoverStatus 主备存储状态，未设置主备策略时返回 false
+func (u *UploadManager) FailoverStatus() (FailoverStatus, bool) {
+	f := u.currentFailover()
+	if f == nil {
+		return FailoverStatus{}, false
+	}
+	return FailoverStatus{
+		Primary:             f.policy.Primary,
+		Secondary:           f.policy.Secondary,
+		PrimaryHealthy:      f.healthy.Load(),
+		PendingReplications: len(f.queue),
+	}, true
+}
+
+// readStorage 读取和签名使用的存储，主存储不可用时返回备存储
+func (u *UploadManager) readStorage(storageType string) (Storage, bool) {
+	if f := u.currentFailover(); f != nil && storageType == f.policy.Primary && !f.healthy.Load() {
+		return f.secondary, true
+	}
+	storage, ok := u.storages[storageType]
+	return storage, ok
+}
+
+// observePrimary 记录对存储的操作结果，用于判断主存储是否可用
+func (u *UploadManager) observePrimary(storageType string, err error) {
+	if f := u.currentFailover(); f != nil && storageType == f.policy.Primary {
+		f.observe(err)
+	}
+}
+
+// replicate 将主存储中写入或删除的对象异步同步到备存储
+func (u *UploadManager) replicate(storageType string, job replicateJob) {
+	if f := u.currentFailover(); f != nil && storageType == f.policy.Primary {
+		f.enqueue(job)
+	}
+}
+
+// start 启动复制和健康探测
+func (f *failover) start() {
+	for range f.policy.ReplicateWorkers {
+		f.wg.Add(1)
+		go f.replicateLoop()
+	}
+	f.wg.Add(1)
+	go f.probeLoop()
+}
+
+// stop 停止复制和健康探测，队列中未处理的任务被丢弃
+func (f *failover) stop() {
+	close(f.done)
+	f.wg.Wait()
+	if n := len(f.queue); n > 0 {
+		logx.Errorf("ossx failover: %d pending replications dropped", n)
+	}
+}
+
+// observe 记录主存储操作结果，连续出现可重试错误达到阈值后切换到备存储，成功时恢复
+func (f *failover) observe(err error) {
+	if err == nil {
+		f.failures.Store(0)
+		if !f.healthy.Swap(true) {
+			logx.Infof("ossx failover: primary storage %s recovered", f.policy.Primary)
+		}
+		return
+	}
+	if !IsRetryableError(err) {
+		return
+	}
+	if int(f.failures.Add(1)) >= f.policy.FailureThreshold && f.healthy.Swap(false) {
+		logx.Errorf("ossx failover: primary storage %s unhealthy (%v), failing over to %s",
+			f.policy.Primary, err, f.policy.Secondary)
+	}
+}
+
+// enqueue 加入复制队列，队列满时放弃并回调
+func (f *failover) enqueue(job replicateJob) {
+	select {
+	case <-f.done:
+	case f.queue <- job:
+	default:
+		f.replicateFailed(job.path, ErrReplicationQueueFull)
+	}
+}
+
+// replicateLoop 处理复制队列
+func (f *failover) replicateLoop() {
+	defer f.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-f.done
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case job := <-f.queue:
+			if err := f.replicateObject(ctx, job); err != nil {
+				f.replicateFailed(job.path, err)
+			}
+		}
+	}
+}
+
+// replicateObject 从主存储下载对象并写入备存储，或删除备存储中的对象
+func (f *failover) replicateObject(ctx context.Context, job replicateJob) error {
+	if job.deleted {
+		err := f.u.withRetry(ctx, "replicate delete "+job.path, nil, func() error {
+			return f.secondary.Delete(ctx, job.path)
+		})
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	// 每次重试重新下载，上传过程中无法回退已读取的内容
+	return f.u.withRetry(ctx, "replicate "+job.path, nil, func() error {
+		body, err := f.reader.Download(ctx, job.path)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		_, err = uploadObject(ctx, f.secondary, body, job.path, job.contentType, job.acl)
+		return err
+	})
+}
+
+// replicateFailed 记录复制失败
+func (f *failover) replicateFailed(path string, err error) {
+	logx.Errorf("ossx failover: replicate %s to %s failed: %v", path, f.policy.Secondary, err)
+	if f.policy.OnReplicateError != nil {
+		f.policy.OnReplicateError(path, err)
+	}
+}
+
+// probeLoop 定期探测主存储
+func (f *failover) probeLoop() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.policy.ProbeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), f.policy.ProbeInterval)
+			err := f.policy.HealthCheck(ctx, f.primary)
+			cancel()
+			f.observe(err)
+		}
+	}
+}
+
+// statHealthCheck 查询探测对象的元信息，对象不存在视为可用；存储不支持查询时视为可用
+func statHealthCheck(ctx context.Context, storage Storage) error {
+	rr, ok := storage.(RangeReader)
+	if !ok {
+		return nil
+	}
+	if _, err := rr.StatObject(ctx, defaultProbePath); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
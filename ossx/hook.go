@@ -0,0 +1,113 @@
+package ossx
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UploadContext 上传钩子可见的上传信息，BeforeUpload 钩子可修改 Reader、ContentType、Size 和 FileName
+type UploadContext struct {
+	// 存储类型
+	StorageType string
+	// 上传用户
+	UserID int64
+	// 原始文件名
+	FileName string
+	// 文件MIME类型
+	ContentType string
+	// 文件大小，替换 Reader 时需同步修改
+	Size int64
+	// 文件内容，可替换为处理后的内容（如去除EXIF、添加水印）
+	Reader io.Reader
+	// 对象路径，生成后才有值
+	Path string
+	// 钩子间传递的自定义数据
+	Values map[string]any
+}
+
+// BeforeUploadHook 上传前钩子，返回错误时终止上传（如病毒扫描不通过）
+type BeforeUploadHook func(ctx context.Context, uc *UploadContext) error
+
+// AfterUploadHook 上传后钩子，返回错误时上传失败，已上传的对象会被删除
+type AfterUploadHook func(ctx context.Context, uc *UploadContext, result *UploadResult) error
+
+// UploadErrorHook 上传失败钩子，用于审计和告警
+type UploadErrorHook func(ctx context.Context, uc *UploadContext, err error)
+
+// uploadHooks 上传钩子链
+type uploadHooks struct {
+	before  []BeforeUploadHook
+	after   []AfterUploadHook
+	onError []UploadErrorHook
+}
+
+// BeforeUpload 添加上传前钩子，按添加顺序执行
+func (u *UploadManager) BeforeUpload(hooks ...BeforeUploadHook) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.hooks.before = append(u.hooks.before, hooks...)
+}
+
+// AfterUpload 添加上传后钩子，按添加顺序执行
+func (u *UploadManager) AfterUpload(hooks ...AfterUploadHook) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.hooks.after = append(u.hooks.after, hooks...)
+}
+
+// OnError 添加上传失败钩子，按添加顺序执行
+func (u *UploadManager) OnError(hooks ...UploadErrorHook) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.hooks.onError = append(u.hooks.onError, hooks...)
+}
+
+// uploadHooks 获取当前钩子链
+func (u *UploadManager) uploadHooks() uploadHooks {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.hooks
+}
+
+// runBeforeUpload 依次执行上传前钩子，任一钩子返回错误时停止
+func (h uploadHooks) runBeforeUpload(ctx context.Context, uc *UploadContext) error {
+	for _, hook := range h.before {
+		if err := hook(ctx, uc); err != nil {
+			return fmt.Errorf("before upload hook rejected %s: %w", uc.FileName, err)
+		}
+		// 钩子读取内容后重置读取位置
+		if seeker, ok := uc.Reader.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to reset file reader: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runAfterUpload 依次执行上传后钩子，任一钩子返回错误时停止
+func (h uploadHooks) runAfterUpload(ctx context.Context, uc *UploadContext, result *UploadResult) error {
+	for _, hook := range h.after {
+		if err := hook(ctx, uc, result); err != nil {
+			return fmt.Errorf("after upload hook failed for %s: %w", uc.FileName, err)
+		}
+	}
+	return nil
+}
+
+// runOnError 执行上传失败钩子，钩子panic时只记录日志
+func (h uploadHooks) runOnError(ctx context.Context, uc *UploadContext, err error) {
+	for _, hook := range h.onError {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logx.WithContext(ctx).Errorf("upload error hook panic: %v", r)
+				}
+			}()
+			hook(ctx, uc, err)
+		}()
+	}
+}
@@ -0,0 +1,149 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// LifecycleTransition 对象创建指定天数后转换存储类型
+type LifecycleTransition struct {
+	// 对象创建后的天数
+	Days int `json:"days"`
+	// 目标存储类型，见 configx.StorageClassIA 等，也可使用存储的原生取值
+	StorageClass string `json:"storage_class"`
+}
+
+// LifecycleRule 存储桶生命周期规则
+type LifecycleRule struct {
+	// 规则ID，同一存储桶内唯一
+	ID string `json:"id"`
+	// 对象前缀，为空时作用于整个存储桶
+	Prefix string `json:"prefix,omitempty"`
+	// 停用规则，保留配置但不执行
+	Disabled bool `json:"disabled,omitempty"`
+	// 存储类型转换，天数需递增
+	Transitions []LifecycleTransition `json:"transitions,omitempty"`
+	// 对象创建指定天数后删除，0 表示不删除
+	ExpirationDays int `json:"expiration_days,omitempty"`
+	// 分片上传初始化指定天数后仍未完成时清理分片，0 表示不清理
+	AbortMultipartDays int `json:"abort_multipart_days,omitempty"`
+	// GetLifecycle 返回的规则中无法表示的配置，如标签过滤、按日期过期、历史版本规则，
+	// 非空时规则不完整，SetLifecycle 会拒绝写回，避免按前缀的规则被放大到整个存储桶
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// LifecycleStorage 支持存储桶生命周期管理的存储，S3、OSS、COS 已实现
+type LifecycleStorage interface {
+	// PutLifecycle 设置生命周期规则，覆盖存储桶已有的全部规则
+	PutLifecycle(ctx context.Context, rules []LifecycleRule) error
+	// GetLifecycle 获取生命周期规则，未配置时返回空
+	GetLifecycle(ctx context.Context) ([]LifecycleRule, error)
+	// DeleteLifecycle 删除全部生命周期规则
+	DeleteLifecycle(ctx context.Context) error
+}
+
+// ErrLifecycleUnsupported 规则包含无法表示的配置，不能写回
+var ErrLifecycleUnsupported = errors.New("lifecycle rule has unsupported settings")
+
+// Validate 校验规则
+func (r LifecycleRule) Validate() error {
+	if r.ID == "" {
+		return errors.New("lifecycle rule id is required")
+	}
+	if len(r.Unsupported) > 0 {
+		return fmt.Errorf("%w: %s %v", ErrLifecycleUnsupported, r.ID, r.Unsupported)
+	}
+	if len(r.Transitions) == 0 && r.ExpirationDays <= 0 && r.AbortMultipartDays <= 0 {
+		return fmt.Errorf("lifecycle rule %s has no action", r.ID)
+	}
+	if r.ExpirationDays < 0 || r.AbortMultipartDays < 0 {
+		return fmt.Errorf("lifecycle rule %s: days must not be negative", r.ID)
+	}
+
+	last := 0
+	for _, t := range r.Transitions {
+		if t.StorageClass == "" {
+			return fmt.Errorf("lifecycle rule %s: transition storage class is required", r.ID)
+		}
+		if t.Days <= last {
+			return fmt.Errorf("lifecycle rule %s: transition days must be positive and increasing", r.ID)
+		}
+		last = t.Days
+	}
+	if r.ExpirationDays > 0 && r.ExpirationDays <= last {
+		return fmt.Errorf("lifecycle rule %s: expiration must be later than the last transition", r.ID)
+	}
+	return nil
+}
+
+// SetLifecycle 设置存储桶生命周期规则，覆盖已有的全部规则，rules 为空时删除规则
+// GetLifecycle 返回的含 Unsupported 的规则不能写回，需在存储控制台维护
+func (u *UploadManager) SetLifecycle(ctx context.Context, storageType string, rules []LifecycleRule) error {
+	ls, err := u.lifecycleStorage(storageType)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if ids[r.ID] {
+			return fmt.Errorf("duplicate lifecycle rule id: %s", r.ID)
+		}
+		ids[r.ID] = true
+	}
+
+	return u.withRetry(ctx, "set lifecycle", nil, func() error {
+		if len(rules) == 0 {
+			return ls.DeleteLifecycle(ctx)
+		}
+		return ls.PutLifecycle(ctx, rules)
+	})
+}
+
+// GetLifecycle 获取存储桶生命周期规则，无法表示的配置记录在规则的 Unsupported 中
+func (u *UploadManager) GetLifecycle(ctx context.Context, storageType string) ([]LifecycleRule, error) {
+	ls, err := u.lifecycleStorage(storageType)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []LifecycleRule
+	err = u.withRetry(ctx, "get lifecycle", nil, func() error {
+		rules, err = ls.GetLifecycle(ctx)
+		return err
+	})
+	return rules, err
+}
+
+// lifecycleStorage 获取支持生命周期管理的存储
+func (u *UploadManager) lifecycleStorage(storageType string) (LifecycleStorage, error) {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	ls, ok := storage.(LifecycleStorage)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support lifecycle", storageType)
+	}
+	return ls, nil
+}
+
+// lifecycleStatus 规则状态，三种存储取值相同
+func lifecycleStatus(disabled bool) string {
+	if disabled {
+		return "Disabled"
+	}
+	return "Enabled"
+}
+
+// unsupportedIf 条件成立时记录无法表示的配置
+func (r *LifecycleRule) unsupportedIf(cond bool, setting string) {
+	if cond && !slices.Contains(r.Unsupported, setting) {
+		r.Unsupported = append(r.Unsupported, setting)
+	}
+}
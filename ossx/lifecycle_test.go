@@ -0,0 +1,37 @@
+package ossx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLifecycleRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    LifecycleRule
+		wantErr bool
+	}{
+		{name: "expiration", rule: LifecycleRule{ID: "logs", Prefix: "logs/", ExpirationDays: 30}},
+		{name: "missing id", rule: LifecycleRule{ExpirationDays: 30}, wantErr: true},
+		{name: "no action", rule: LifecycleRule{ID: "logs"}, wantErr: true},
+		{name: "transition after expiration", rule: LifecycleRule{ID: "logs", ExpirationDays: 10,
+			Transitions: []LifecycleTransition{{Days: 30, StorageClass: "IA"}}}, wantErr: true},
+		{name: "unsupported", rule: LifecycleRule{ID: "retention", ExpirationDays: 365,
+			Unsupported: []string{"filter.and"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	rule := LifecycleRule{ID: "retention", ExpirationDays: 365}
+	rule.unsupportedIf(true, "filter.tag")
+	rule.unsupportedIf(true, "filter.tag")
+	rule.unsupportedIf(false, "expiration.date")
+	if len(rule.Unsupported) != 1 || !errors.Is(rule.Validate(), ErrLifecycleUnsupported) {
+		t.Fatalf("unsupported = %v", rule.Unsupported)
+	}
+}
@@ -2,21 +2,29 @@ package ossx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	configx "github.com/QuantumShiftX/golib/ossx/config"
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrInvalidPath 对象路径超出存储目录
+var ErrInvalidPath = errors.New("invalid object path")
+
 // localStorage 实现本地文件系统存储
 type localStorage struct {
 	basePath  string
 	cdnDomain string // CDN域名（可选）
+	signKey   []byte // 签名URL密钥（可选），为空时不生成签名URL
 }
 
 // newLocalStorage 创建本地存储实例
@@ -33,16 +41,35 @@ func newLocalStorage(config configx.StorageConfig) (Storage, error) {
 	return &localStorage{
 		basePath:  config.Bucket,
 		cdnDomain: config.CdnDomain,
+		signKey:   []byte(config.SecretKey),
 	}, nil
 }
 
-// Upload 实现Storage接口的上传方法
+// Upload 实现Storage接口的上传方法，使用存储的默认权限
 func (l *localStorage) Upload(ctx context.Context, file io.Reader, path, contentType string) (string, error) {
+	return l.UploadWithACL(ctx, file, path, contentType, configx.ACLDefault)
+}
+
+// UploadWithACL 实现 ACLStorage 接口，私有文件仅属主可读写（0600），公共文件和未指定权限时所有用户可读（0644）
+func (l *localStorage) UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return l.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl})
+}
+
+// UploadIfAbsent 实现 ConditionalUploader 接口，以 O_EXCL 创建文件，已存在时返回 ErrObjectExists
+func (l *localStorage) UploadIfAbsent(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return l.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl, IfAbsent: true})
+}
+
+// UploadWithOptions 实现 OptionsUploader 接口，本地存储只支持 ACL 和 IfAbsent，其他选项忽略
+func (l *localStorage) UploadWithOptions(ctx context.Context, file io.Reader, path, contentType string, opts ObjectOptions) (string, error) {
 	// 标准化路径，处理前导斜杠
 	path = strings.TrimPrefix(path, "/")
 
 	// 创建完整的路径，包括基础路径
-	fullPath := filepath.Join(l.basePath, path)
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return "", err
+	}
 	dir := filepath.Dir(fullPath)
 
 	// 确保目录存在
@@ -51,14 +78,27 @@ func (l *localStorage) Upload(ctx context.Context, file io.Reader, path, content
 	}
 
 	// 创建文件
-	f, err := os.Create(fullPath)
+	flag := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if opts.IfAbsent {
+		flag = os.O_RDWR | os.O_CREATE | os.O_EXCL
+	}
+	f, err := os.OpenFile(fullPath, flag, localFileMode(opts.ACL))
 	if err != nil {
+		if opts.IfAbsent && errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("%w: %s", ErrObjectExists, path)
+		}
 		return "", fmt.Errorf("failed to create file: %w", err)
 	}
 	defer f.Close()
 
-	// 写入文件内容
+	// 覆盖已有文件时同步权限
+	if err := f.Chmod(localFileMode(opts.ACL)); err != nil {
+		return "", fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	// 写入文件内容，失败时删除不完整的文件，避免留下截断的对象，条件写入也可以重试
 	if _, err := io.Copy(f, file); err != nil {
+		_ = os.Remove(fullPath)
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -79,17 +119,66 @@ func (l *localStorage) Upload(ctx context.Context, file io.Reader, path, content
 	return url, nil
 }
 
+// SetACL 实现 ACLStorage 接口，修改已有文件的权限
+func (l *localStorage) SetACL(ctx context.Context, path string, acl configx.ACL) error {
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(fullPath, localFileMode(acl)); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	return nil
+}
+
+// GetACL 实现 ACLReader 接口，其他用户可读的文件为公共可读
+func (l *localStorage) GetACL(ctx context.Context, path string) (configx.ACL, error) {
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.Mode().Perm()&0004 != 0 {
+		return configx.ACLPublic, nil
+	}
+	return configx.ACLPrivate, nil
+}
+
+// localFileMode 访问权限对应的文件权限，未指定时与原有行为一致为 0644，便于nginx等静态服务直接读取
+func localFileMode(acl configx.ACL) os.FileMode {
+	switch acl {
+	case configx.ACLDefault, configx.ACLPublic:
+		return 0644
+	default:
+		return 0600
+	}
+}
+
+// objectPath 对象的完整路径，路径须位于存储目录内，防止通过 .. 或绝对路径访问存储目录之外的文件
+func (l *localStorage) objectPath(path string) (string, error) {
+	rel := filepath.FromSlash(strings.TrimPrefix(path, "/"))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPath, path)
+	}
+	return filepath.Join(l.basePath, rel), nil
+}
+
 // Delete 实现Storage接口的删除方法
 func (l *localStorage) Delete(ctx context.Context, path string) error {
 	// 标准化路径
 	path = strings.TrimPrefix(path, "/")
 
 	// 构建完整路径
-	fullPath := filepath.Join(l.basePath, path)
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return err
+	}
 
 	// 检查文件是否存在
-	_, err := os.Stat(fullPath)
-	if err != nil {
+	if _, err = os.Stat(fullPath); err != nil {
 		if os.IsNotExist(err) {
 			// 文件不存在，视为删除成功
 			return nil
@@ -329,7 +418,214 @@ func (l *localStorage) ServeFile(w http.ResponseWriter, r *http.Request, path st
 	http.ServeFile(w, r, fullPath)
 }
 
-// CreateSignedURL 创建带签名的临时访问URL
+// CreateSignedURL 创建带HMAC签名的临时访问URL，由 ServeSignedFile 校验并提供文件
+// 配置 CDN 域名时URL指向该域名，否则为以 / 开头的相对路径；未配置密钥（SecretKey）时返回空
 func (l *localStorage) CreateSignedURL(ctx context.Context, path string, expiration time.Duration) (string, error) {
-	return "", nil
+	if len(l.signKey) == 0 {
+		return "", nil
+	}
+	path = strings.TrimPrefix(path, "/")
+	expires := time.Now().Add(expiration).Unix()
+
+	query := url.Values{}
+	query.Set(localExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(localSignatureParam, l.sign(path, expires))
+
+	base := ""
+	if l.cdnDomain != "" {
+		base = strings.TrimSuffix(l.cdnDomain, "/")
+		if !strings.HasPrefix(base, "http") {
+			base = "https://" + base
+		}
+	}
+	return base + "/" + (&url.URL{Path: path}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+// ListPage 分页列出指定前缀的文件，按key字典序返回，marker 为上一页最后一个文件的key
+// 按key顺序逐层读取目录，跳过不含前缀或已在 marker 之前的子目录，凑满一页即停止
+func (l *localStorage) ListPage(ctx context.Context, prefix, marker string, limit int) ([]ObjectInfo, string, error) {
+	prefix = strings.TrimPrefix(prefix, "/")
+
+	// 从前缀所在目录开始遍历，避免扫描整个存储目录
+	root, rootKey := l.basePath, ""
+	if dir := filepath.Dir(filepath.FromSlash(prefix)); dir != "." {
+		if !filepath.IsLocal(dir) {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidPath, prefix)
+		}
+		root, rootKey = filepath.Join(l.basePath, dir), filepath.ToSlash(dir)+"/"
+	}
+
+	// 多取一个用于判断是否还有下一页
+	objects := make([]ObjectInfo, 0, limit+1)
+	if err := l.listOrdered(ctx, root, rootKey, prefix, marker, limit+1, &objects); err != nil {
+		return nil, "", fmt.Errorf("failed to list files: %w", err)
+	}
+
+	if len(objects) <= limit {
+		return objects, "", nil
+	}
+	objects = objects[:limit]
+	return objects, objects[limit-1].Key, nil
+}
+
+// listOrdered 按key字典序深度遍历目录 dir（其key前缀为 dirKey），收集前缀下大于 marker 的文件直到 n 个
+func (l *localStorage) listOrdered(ctx context.Context, dir, dirKey, prefix, marker string, n int, objects *[]ObjectInfo) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// 目录的key带 / 后缀参与排序，与其下文件key的字典序一致（如 a.txt 在 a/b 之前）
+	entryKey := func(e fs.DirEntry) string {
+		if e.IsDir() {
+			return dirKey + e.Name() + "/"
+		}
+		return dirKey + e.Name()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entryKey(entries[i]) < entryKey(entries[j])
+	})
+
+	for _, e := range entries {
+		if len(*objects) >= n {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := entryKey(e)
+		// 之后的key都大于前缀范围
+		if key > prefix && !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		if e.IsDir() {
+			// 前缀不在此目录下，或目录下的key都不大于 marker
+			if !strings.HasPrefix(prefix, key) && !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if key < marker && !strings.HasPrefix(marker, key) {
+				continue
+			}
+			if err := l.listOrdered(ctx, filepath.Join(dir, e.Name()), key, prefix, marker, n, objects); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !strings.HasPrefix(key, prefix) || key <= marker {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		*objects = append(*objects, ObjectInfo{
+			Key:          key,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	}
+	return nil
+}
+
+// Download 打开本地文件用于读取，调用方负责关闭
+func (l *localStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// CopyObject 实现 ObjectCopier 接口，复制文件并保留访问权限
+func (l *localStorage) CopyObject(ctx context.Context, src, dst string) error {
+	srcPath, err := l.objectPath(src)
+	if err != nil {
+		return err
+	}
+	dstPath, err := l.objectPath(dst)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.OpenFile(dstPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
+
+// StatObject 实现 RangeReader 接口，获取文件元信息，ETag 由大小和修改时间生成
+func (l *localStorage) StatObject(ctx context.Context, path string) (*ObjectMeta, error) {
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to stat file: %s is a directory: %w", path, os.ErrNotExist)
+	}
+	return &ObjectMeta{
+		Size:         info.Size(),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// DownloadRange 实现 RangeReader 接口，按范围读取本地文件
+func (l *localStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	fullPath, err := l.objectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek file: %w", err)
+	}
+	if length < 0 {
+		return file, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
 }
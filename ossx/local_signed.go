@@ -0,0 +1,227 @@
+package ossx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// 本地存储签名URL的查询参数
+const (
+	localExpiresParam     = "expires"
+	localSignatureParam   = "signature"
+	localContentTypeParam = "content_type"
+	localSizeParam        = "size"
+)
+
+// SignedFileServer 提供签名URL访问的存储，本地存储已实现
+type SignedFileServer interface {
+	// ServeSignedFile 校验签名和有效期后提供文件，对象路径取自 r.URL.Path
+	ServeSignedFile(w http.ResponseWriter, r *http.Request)
+}
+
+// SignedUploadServer 接收临时上传URL直传的存储，本地存储已实现
+type SignedUploadServer interface {
+	// ServeSignedUpload 校验签名、有效期、类型和大小后写入对象，对象路径取自 r.URL.Path
+	ServeSignedUpload(w http.ResponseWriter, r *http.Request)
+}
+
+// ServeSignedUpload 返回接收本地存储临时上传URL的处理器，挂载方式同 ServeSignedFile
+func (u *UploadManager) ServeSignedUpload(storageType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storage, ok := u.storages[storageType]
+		if !ok {
+			http.Error(w, fmt.Sprintf("storage type %s not initialized", storageType), http.StatusInternalServerError)
+			return
+		}
+		sus, ok := storage.(SignedUploadServer)
+		if !ok {
+			http.Error(w, fmt.Sprintf("storage type %s does not serve signed uploads", storageType), http.StatusNotImplemented)
+			return
+		}
+		sus.ServeSignedUpload(w, r)
+	})
+}
+
+// ServeSignedFile 返回校验本地存储签名URL的处理器，挂载路径需与签名URL一致，
+// 挂载在子路径下时使用 http.StripPrefix 去掉前缀，如：
+//
+//	mux.Handle("/files/", http.StripPrefix("/files", ossx.Uploader.ServeSignedFile("local")))
+func (u *UploadManager) ServeSignedFile(storageType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storage, ok := u.storages[storageType]
+		if !ok {
+			http.Error(w, fmt.Sprintf("storage type %s not initialized", storageType), http.StatusInternalServerError)
+			return
+		}
+		sfs, ok := storage.(SignedFileServer)
+		if !ok {
+			http.Error(w, fmt.Sprintf("storage type %s does not serve signed files", storageType), http.StatusNotImplemented)
+			return
+		}
+		sfs.ServeSignedFile(w, r)
+	})
+}
+
+// ServeSignedFile 实现 SignedFileServer 接口
+func (l *localStorage) ServeSignedFile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if len(l.signKey) == 0 {
+		http.Error(w, "signed urls are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	// 清理路径，防止通过 .. 访问存储目录之外的文件
+	objectPath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get(localExpiresParam), 10, 64)
+	if err != nil || objectPath == "" {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	signature := query.Get(localSignatureParam)
+	if !hmac.Equal([]byte(signature), []byte(l.sign(objectPath, expires))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if time.Now().Unix() > expires {
+		http.Error(w, "signed url expired", http.StatusForbidden)
+		return
+	}
+
+	f, err := os.Open(filepath.Join(l.basePath, filepath.FromSlash(objectPath)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// 签名URL有有效期，不允许共享缓存
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(max(expires-time.Now().Unix(), 0), 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// sign 计算对象路径和过期时间的签名
+func (l *localStorage) sign(objectPath string, expires int64) string {
+	mac := hmac.New(sha256.New, l.signKey)
+	mac.Write([]byte(objectPath))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// PresignPut 实现 PresignedUploader 接口，生成由 ServeSignedUpload 校验的上传URL，签名包含路径、类型和大小
+func (l *localStorage) PresignPut(ctx context.Context, path, contentType string, size int64, expiration time.Duration) (string, map[string]string, error) {
+	if len(l.signKey) == 0 {
+		return "", nil, errors.New("signed urls are not enabled")
+	}
+	path = strings.TrimPrefix(path, "/")
+	expires := time.Now().Add(expiration).Unix()
+
+	query := url.Values{}
+	query.Set(localExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(localContentTypeParam, contentType)
+	query.Set(localSizeParam, strconv.FormatInt(size, 10))
+	query.Set(localSignatureParam, l.signUpload(path, contentType, size, expires))
+
+	base := ""
+	if l.cdnDomain != "" {
+		base = strings.TrimSuffix(l.cdnDomain, "/")
+		if !strings.HasPrefix(base, "http") {
+			base = "https://" + base
+		}
+	}
+	return base + "/" + (&url.URL{Path: path}).EscapedPath() + "?" + query.Encode(),
+		map[string]string{"Content-Type": contentType}, nil
+}
+
+// ServeSignedUpload 实现 SignedUploadServer 接口，目标已存在时拒绝写入，同一URL只能上传一次
+func (l *localStorage) ServeSignedUpload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if len(l.signKey) == 0 {
+		http.Error(w, "signed urls are not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	objectPath := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get(localExpiresParam), 10, 64)
+	if err != nil || objectPath == "" {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	size, err := strconv.ParseInt(query.Get(localSizeParam), 10, 64)
+	if err != nil || size <= 0 {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	contentType := query.Get(localContentTypeParam)
+	signature := query.Get(localSignatureParam)
+	if !hmac.Equal([]byte(signature), []byte(l.signUpload(objectPath, contentType, size, expires))) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	if time.Now().Unix() > expires {
+		http.Error(w, "signed url expired", http.StatusForbidden)
+		return
+	}
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != contentType {
+		http.Error(w, "content type mismatch", http.StatusForbidden)
+		return
+	}
+	if r.ContentLength >= 0 && r.ContentLength != size {
+		http.Error(w, "content length mismatch", http.StatusForbidden)
+		return
+	}
+
+	// 超过声明大小时中止写入并删除不完整的文件
+	body := &limitedReader{r: r.Body, remaining: size}
+	if _, err = l.UploadIfAbsent(r.Context(), body, objectPath, contentType, configx.ACLPrivate); err != nil {
+		switch {
+		case errors.Is(err, ErrObjectExists):
+			http.Error(w, "object already uploaded", http.StatusConflict)
+		case errors.Is(err, ErrRemoteFileTooLarge):
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		default:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// signUpload 计算上传URL的签名，与访问URL的签名区分
+func (l *localStorage) signUpload(objectPath, contentType string, size, expires int64) string {
+	mac := hmac.New(sha256.New, l.signKey)
+	for _, part := range []string{http.MethodPut, objectPath, contentType, strconv.FormatInt(size, 10), strconv.FormatInt(expires, 10)} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{'\n'})
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,190 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newSignedServer 创建挂载签名访问和签名上传处理器的测试服务
+func newSignedServer(t *testing.T) (*UploadManager, *localStorage, *httptest.Server, string) {
+	t.Helper()
+	u, dir := newTestManager(t)
+	mux := http.NewServeMux()
+	mux.Handle("/files/", http.StripPrefix("/files", u.ServeSignedFile(Local)))
+	mux.Handle("/uploads/", http.StripPrefix("/uploads", u.ServeSignedUpload(Local)))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return u, u.storages[Local].(*localStorage), srv, dir
+}
+
+// signedFileURL 按指定过期时间生成签名访问URL
+func signedFileURL(l *localStorage, objectPath string, expires int64) string {
+	query := url.Values{}
+	query.Set(localExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(localSignatureParam, l.sign(objectPath, expires))
+	return "/" + objectPath + "?" + query.Encode()
+}
+
+func TestServeSignedFile(t *testing.T) {
+	ctx := context.Background()
+	_, l, srv, dir := newSignedServer(t)
+	if _, err := l.Upload(ctx, bytes.NewReader(testPNG), "a/b.png", "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "secret.txt"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := l.CreateSignedURL(ctx, "a/b.png", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute).Unix()
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		want   int
+	}{
+		{"valid", http.MethodGet, signed, http.StatusOK},
+		{"head", http.MethodHead, signed, http.StatusOK},
+		{"post", http.MethodPost, signed, http.StatusMethodNotAllowed},
+		{"expired", http.MethodGet, signedFileURL(l, "a/b.png", time.Now().Add(-time.Minute).Unix()), http.StatusForbidden},
+		{"tampered expiry", http.MethodGet, signedFileURL(l, "a/b.png", future) + "0", http.StatusForbidden},
+		{"other path", http.MethodGet, "/a/c.png?" + mustQuery(t, signed), http.StatusForbidden},
+		{"missing signature", http.MethodGet, "/a/b.png?expires=" + strconv.FormatInt(future, 10), http.StatusForbidden},
+		{"traversal", http.MethodGet, signedFileURL(l, "../secret.txt", future), http.StatusNotFound},
+		{"missing object", http.MethodGet, signedFileURL(l, "a/missing.png", future), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+"/files"+tt.target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.want, body)
+			}
+			if tt.want == http.StatusOK && tt.method == http.MethodGet {
+				if !bytes.Equal(body, testPNG) || resp.Header.Get("Cache-Control") == "" {
+					t.Fatalf("body = %q, headers = %v", body, resp.Header)
+				}
+			}
+		})
+	}
+}
+
+// mustQuery 取URL的查询串
+func mustQuery(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.RawQuery
+}
+
+func TestServeSignedFileWithoutKey(t *testing.T) {
+	u, _ := newTestManager(t)
+	l := u.storages[Local].(*localStorage)
+	l.signKey = nil
+
+	if signed, err := l.CreateSignedURL(context.Background(), "a.png", time.Minute); err != nil || signed != "" {
+		t.Fatalf("CreateSignedURL = %q, %v", signed, err)
+	}
+	w := httptest.NewRecorder()
+	u.ServeSignedFile(Local).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a.png?expires=1&signature=x", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("status = %d", w.Code)
+	}
+}
+
+func TestServeSignedUpload(t *testing.T) {
+	size := int64(len(testPNG))
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        []byte
+		chunked     bool
+		tamper      func(q url.Values)
+		want        int
+	}{
+		{name: "valid", method: http.MethodPut, contentType: "image/png", body: testPNG, want: http.StatusOK},
+		{name: "get", method: http.MethodGet, contentType: "image/png", want: http.StatusMethodNotAllowed},
+		{name: "content type mismatch", method: http.MethodPut, contentType: "text/html", body: testPNG, want: http.StatusForbidden},
+		{name: "content length mismatch", method: http.MethodPut, contentType: "image/png", body: testPNG[:10], want: http.StatusForbidden},
+		{name: "oversized chunked body", method: http.MethodPut, contentType: "image/png", body: append(bytes.Clone(testPNG), 0), chunked: true, want: http.StatusRequestEntityTooLarge},
+		{name: "tampered size", method: http.MethodPut, contentType: "image/png", body: testPNG, tamper: func(q url.Values) {
+			q.Set(localSizeParam, strconv.FormatInt(size+1, 10))
+		}, want: http.StatusForbidden},
+		{name: "expired", method: http.MethodPut, contentType: "image/png", body: testPNG, tamper: func(q url.Values) {
+			q.Set(localExpiresParam, "1")
+		}, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, l, srv, dir := newSignedServer(t)
+			signed, headers, err := l.PresignPut(context.Background(), "up/a.png", "image/png", size, time.Minute)
+			if err != nil || headers["Content-Type"] != "image/png" {
+				t.Fatalf("PresignPut headers = %v, %v", headers, err)
+			}
+			target, err := url.Parse(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper != nil {
+				q := target.Query()
+				tt.tamper(q)
+				target.RawQuery = q.Encode()
+			}
+
+			var body io.Reader = bytes.NewReader(tt.body)
+			if tt.chunked {
+				// 隐藏长度，按分块编码发送
+				body = io.MultiReader(body)
+			}
+			req, err := http.NewRequest(tt.method, srv.URL+"/uploads"+target.String(), body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+
+			info, err := os.Stat(filepath.Join(dir, "up/a.png"))
+			if tt.want != http.StatusOK {
+				// 拒绝或中止的上传不留下文件
+				if err == nil {
+					t.Fatalf("rejected upload left a file of %d bytes", info.Size())
+				}
+				return
+			}
+			if err != nil || info.Size() != size || info.Mode().Perm() != 0600 {
+				t.Fatalf("uploaded file = %v, %v", info, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+func TestLocalStoragePathContainment(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "bucket")
+	if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := newTestManager(t)
+	if err := u.addStorage(localStorageConfig(base)); err != nil {
+		t.Fatal(err)
+	}
+	l := u.storages[Local].(*localStorage)
+	ctx := context.Background()
+
+	if _, err := l.Upload(ctx, strings.NewReader("ok"), "a/b.txt", "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := map[string]func(path string) error{
+		"StatObject": func(path string) error {
+			_, err := l.StatObject(ctx, path)
+			return err
+		},
+		"DownloadRange": func(path string) error {
+			_, err := l.DownloadRange(ctx, path, 0, -1)
+			return err
+		},
+		"Download": func(path string) error {
+			_, err := l.Download(ctx, path)
+			return err
+		},
+		"Upload": func(path string) error {
+			_, err := l.Upload(ctx, strings.NewReader("x"), path, "text/plain")
+			return err
+		},
+		"Delete": func(path string) error {
+			return l.Delete(ctx, path)
+		},
+		"CopyObject": func(path string) error {
+			return l.CopyObject(ctx, path, "copy.txt")
+		},
+	}
+	for name, op := range ops {
+		for _, path := range []string{"../secret.txt", "/../secret.txt", "a/../../secret.txt", ""} {
+			if err := op(path); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("%s(%q) err = %v", name, path, err)
+			}
+		}
+	}
+	if _, _, err := l.ListPage(ctx, "../", "", 10); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ListPage err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "secret.txt")); err != nil {
+		t.Fatal("file outside base path removed")
+	}
+
+	// 路径内的 .. 仍在存储目录内时允许
+	r, err := l.DownloadRange(ctx, "a/x/../b.txt", 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != "k" {
+		t.Fatalf("data = %q", data)
+	}
+}
+
+func TestLocalStorageListPage(t *testing.T) {
+	u, _ := newTestManager(t)
+	l := u.storages[Local].(*localStorage)
+	ctx := context.Background()
+
+	// 目录遍历顺序与key字典序不一致的布局
+	keys := []string{"a.txt", "a/b/c.txt", "a/b.txt", "a-b.txt", "ab/c.txt", "b/x.txt", "b/y/z.txt", "c.txt"}
+	for _, key := range keys {
+		if _, err := l.Upload(ctx, strings.NewReader(key), key, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"a-b.txt", "a.txt", "a/b.txt", "a/b/c.txt", "ab/c.txt", "b/x.txt", "b/y/z.txt", "c.txt"}},
+		{"a", []string{"a-b.txt", "a.txt", "a/b.txt", "a/b/c.txt", "ab/c.txt"}},
+		{"a/", []string{"a/b.txt", "a/b/c.txt"}},
+		{"b/y", []string{"b/y/z.txt"}},
+		{"missing/", nil},
+	}
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 3, 100} {
+			var got []string
+			marker := ""
+			for pages := 0; ; pages++ {
+				if pages > len(keys) {
+					t.Fatalf("prefix %q limit %d: too many pages", tt.prefix, limit)
+				}
+				objects, next, err := l.ListPage(ctx, tt.prefix, marker, limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(objects) > limit {
+					t.Fatalf("prefix %q: page of %d objects, limit %d", tt.prefix, len(objects), limit)
+				}
+				for _, obj := range objects {
+					got = append(got, obj.Key)
+				}
+				if next == "" {
+					break
+				}
+				marker = next
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("prefix %q limit %d: got %v, want %v", tt.prefix, limit, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLocalStorageFileMode(t *testing.T) {
+	tests := []struct {
+		name       string
+		defaultACL configx.ACL
+		opts       []UploadOption
+		mode       os.FileMode
+	}{
+		{"default", configx.ACLDefault, nil, 0644},
+		{"configured private", configx.ACLPrivate, nil, 0600},
+		{"per upload public", configx.ACLPrivate, []UploadOption{WithACL(configx.ACLPublic)}, 0644},
+		{"per upload private", configx.ACLDefault, []UploadOption{WithACL(configx.ACLPrivate)}, 0600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, dir := newTestManager(t)
+			u.uploadConfig.DefaultACL = tt.defaultACL
+
+			result, err := uploadBytes(u, 7, "a.png", testPNG, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Stat(filepath.Join(dir, result.RelativePath))
+			if err != nil || info.Mode().Perm() != tt.mode {
+				t.Fatalf("mode = %v, %v, want %v", info.Mode().Perm(), err, tt.mode)
+			}
+		})
+	}
+}
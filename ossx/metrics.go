@@ -0,0 +1,121 @@
+package ossx
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// 上传次数计数器
+	uploadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ossx",
+			Name:      "uploads_total",
+			Help:      "上传次数",
+		},
+		[]string{"storage", "result"},
+	)
+
+	// 传输字节数计数器
+	transferredBytesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ossx",
+			Name:      "transferred_bytes_total",
+			Help:      "上传和下载的字节数",
+		},
+		[]string{"storage", "direction"},
+	)
+
+	// 存储操作耗时直方图
+	operationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "ossx",
+			Name:      "operation_duration_seconds",
+			Help:      "存储操作耗时（秒），包含重试",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"storage", "operation", "result"},
+	)
+
+	// 签名URL生成次数计数器
+	signedURLsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ossx",
+			Name:      "signed_urls_total",
+			Help:      "签名URL生成次数",
+		},
+		[]string{"storage", "result"},
+	)
+
+	// 所有指标
+	allCollectors = []prometheus.Collector{
+		uploadsTotal,
+		transferredBytesTotal,
+		operationDuration,
+		signedURLsTotal,
+	}
+)
+
+// metricsCollector 汇总ossx的所有指标
+type metricsCollector struct{}
+
+// Describe 实现 prometheus.Collector 接口
+func (metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range allCollectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range allCollectors {
+		c.Collect(ch)
+	}
+}
+
+// Collector 返回ossx指标收集器，由业务方注册到自己的 Prometheus registry，所有 UploadManager 共用
+func (u *UploadManager) Collector() prometheus.Collector {
+	return metricsCollector{}
+}
+
+// metricResult 操作结果标签
+func metricResult(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// recordUpload 记录上传次数、字节数和耗时，已存在或去重命中的上传不计字节数
+func recordUpload(storageType string, start time.Time, result *UploadResult, err error) {
+	label := metricResult(err)
+	switch {
+	case err != nil:
+	case result.Existed:
+		label = "existed"
+	case result.Deduplicated:
+		label = "deduplicated"
+	default:
+		recordTransfer(storageType, "upload", result.Size)
+	}
+	uploadsTotal.WithLabelValues(storageType, label).Inc()
+	operationDuration.WithLabelValues(storageType, "upload", label).Observe(time.Since(start).Seconds())
+}
+
+// recordOperation 记录存储操作耗时
+func recordOperation(storageType, operation string, start time.Time, err error) {
+	operationDuration.WithLabelValues(storageType, operation, metricResult(err)).Observe(time.Since(start).Seconds())
+}
+
+// recordTransfer 记录传输字节数
+func recordTransfer(storageType, direction string, n int64) {
+	if n > 0 {
+		transferredBytesTotal.WithLabelValues(storageType, direction).Add(float64(n))
+	}
+}
+
+// recordSignedURL 记录签名URL生成
+func recordSignedURL(storageType string, err error) {
+	signedURLsTotal.WithLabelValues(storageType, metricResult(err)).Inc()
+}
@@ -0,0 +1,95 @@
+package ossx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCollectorRegisters(t *testing.T) {
+	u, _ := newTestManager(t)
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(u.Collector()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reg.Gather(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUploadMetrics(t *testing.T) {
+	// 指标为全局，按差值断言
+	counters := map[string]prometheus.Counter{
+		"success":        uploadsTotal.WithLabelValues(Local, "success"),
+		"existed":        uploadsTotal.WithLabelValues(Local, "existed"),
+		"error":          uploadsTotal.WithLabelValues(Local, "error"),
+		"upload bytes":   transferredBytesTotal.WithLabelValues(Local, "upload"),
+		"download bytes": transferredBytesTotal.WithLabelValues(Local, "download"),
+		"signed":         signedURLsTotal.WithLabelValues(Local, "success"),
+	}
+	snapshot := func() map[string]float64 {
+		m := make(map[string]float64, len(counters))
+		for k, c := range counters {
+			var metric dto.Metric
+			if err := c.Write(&metric); err != nil {
+				t.Fatal(err)
+			}
+			m[k] = metric.GetCounter().GetValue()
+		}
+		return m
+	}
+	size := float64(len(testPNG))
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, u *UploadManager)
+		want map[string]float64
+	}{
+		{"upload", func(t *testing.T, u *UploadManager) {
+			if _, err := uploadBytes(u, 7, "a.png", testPNG); err != nil {
+				t.Fatal(err)
+			}
+		}, map[string]float64{"success": 1, "upload bytes": size, "signed": 1}},
+		{"existed", func(t *testing.T, u *UploadManager) {
+			opts := []UploadOption{withObjectPath("fixed.png"), WithIfAbsent()}
+			for range 2 {
+				if _, err := uploadBytes(u, 7, "a.png", testPNG, opts...); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}, map[string]float64{"success": 1, "existed": 1, "upload bytes": size, "signed": 2}},
+		{"rejected", func(t *testing.T, u *UploadManager) {
+			u.uploadConfig.MaxSize = 1
+			if _, err := uploadBytes(u, 7, "a.png", testPNG); err == nil {
+				t.Fatal("expected size limit")
+			}
+		}, map[string]float64{"error": 1}},
+		{"download", func(t *testing.T, u *UploadManager) {
+			result, err := uploadBytes(u, 7, "a.png", testPNG)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			u.ServeObject(w, httptest.NewRequest(http.MethodGet, "/", nil), Local, result.RelativePath)
+			if w.Code != http.StatusOK {
+				t.Fatalf("ServeObject status = %d", w.Code)
+			}
+		}, map[string]float64{"success": 1, "upload bytes": size, "download bytes": size, "signed": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := newTestManager(t)
+			before := snapshot()
+			tt.run(t, u)
+			after := snapshot()
+			for k := range counters {
+				if got := after[k] - before[k]; got != tt.want[k] {
+					t.Fatalf("%s increased by %v, want %v", k, got, tt.want[k])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package ossx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mr"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMigratePageSize    = 1000
+	defaultMigrateConcurrency = 4
+)
+
+// ErrChecksumMismatch 迁移后目标对象校验和与源对象不一致
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ObjectInfo 对象信息
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ObjectLister 支持分页列举对象的存储
+type ObjectLister interface {
+	// ListPage 列出一页对象，返回下一页的续传标记，为空表示已列举完毕
+	ListPage(ctx context.Context, prefix, marker string, limit int) ([]ObjectInfo, string, error)
+}
+
+// ObjectReader 支持流式下载对象的存储
+type ObjectReader interface {
+	// Download 下载对象，调用方负责关闭
+	Download(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	// 每页列举数量，默认1000
+	PageSize int
+	// 并发复制数，默认4
+	Concurrency int
+	// 迁移后重新下载目标对象校验sha256
+	VerifyChecksum bool
+	// 断点文件，设置后每完成一页保存进度，重新执行时从断点继续
+	CheckpointFile string
+	// 单个对象失败时继续迁移，失败的key记录在结果中
+	ContinueOnError bool
+	// 进度回调，每个对象处理完成后调用
+	OnProgress func(p MigrateProgress)
+}
+
+// MigrateProgress 迁移进度
+type MigrateProgress struct {
+	Key     string        `json:"key"`
+	Error   error         `json:"-"`
+	Copied  int64         `json:"copied"`
+	Failed  int64         `json:"failed"`
+	Bytes   int64         `json:"bytes"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// MigrateReport 迁移结果，同时作为断点文件内容
+type MigrateReport struct {
+	Src        string   `json:"src"`
+	Dst        string   `json:"dst"`
+	Prefix     string   `json:"prefix"`
+	Marker     string   `json:"marker"`
+	Copied     int64    `json:"copied"`
+	Bytes      int64    `json:"bytes"`
+	FailedKeys []string `json:"failed_keys,omitempty"`
+	Done       bool     `json:"done"`
+}
+
+// Migrate 将源存储中指定前缀的对象复制到目标存储，保持相同的key
+// 分页列举并流式复制，不在内存中缓存整个对象；源存储需实现 ObjectLister 和 ObjectReader
+func (u *UploadManager) Migrate(ctx context.Context, src, dst, prefix string, opts MigrateOptions) (*MigrateReport, error) {
+	u.mu.RLock()
+	srcStorage, srcOk := u.storages[src]
+	dstStorage, dstOk := u.storages[dst]
+	u.mu.RUnlock()
+	if !srcOk {
+		return nil, fmt.Errorf("storage type %s not initialized", src)
+	}
+	if !dstOk {
+		return nil, fmt.Errorf("storage type %s not initialized", dst)
+	}
+
+	lister, ok := srcStorage.(ObjectLister)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support listing", src)
+	}
+	reader, ok := srcStorage.(ObjectReader)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support download", src)
+	}
+	var dstReader ObjectReader
+	if opts.VerifyChecksum {
+		if dstReader, ok = dstStorage.(ObjectReader); !ok {
+			return nil, fmt.Errorf("storage type %s does not support download for verification", dst)
+		}
+	}
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultMigratePageSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultMigrateConcurrency
+	}
+
+	report, err := loadMigrateCheckpoint(opts.CheckpointFile, src, dst, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if report.Done {
+		return report, nil
+	}
+
+	var (
+		mu    sync.Mutex
+		start = time.Now()
+	)
+	copyObject := func(obj ObjectInfo) {
+		n, err := migrateObject(ctx, reader, dstStorage, dstReader, obj, u.uploadConfig)
+
+		mu.Lock()
+		if err != nil {
+			report.FailedKeys = append(report.FailedKeys, obj.Key)
+			logx.WithContext(ctx).Errorf("failed to migrate %s from %s to %s: %v", obj.Key, src, dst, err)
+		} else {
+			report.Copied++
+			report.Bytes += n
+		}
+		progress := MigrateProgress{
+			Key:     obj.Key,
+			Error:   err,
+			Copied:  report.Copied,
+			Failed:  int64(len(report.FailedKeys)),
+			Bytes:   report.Bytes,
+			Elapsed: time.Since(start),
+		}
+		mu.Unlock()
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+	}
+
+	for {
+		objects, next, err := lister.ListPage(ctx, prefix, report.Marker, opts.PageSize)
+		if err != nil {
+			return report, fmt.Errorf("list %s objects after %q: %w", src, report.Marker, err)
+		}
+
+		failedBefore := len(report.FailedKeys)
+		mr.ForEach(func(source chan<- ObjectInfo) {
+			for _, obj := range objects {
+				// 跳过目录占位对象
+				if strings.HasSuffix(obj.Key, "/") {
+					continue
+				}
+				source <- obj
+			}
+		}, copyObject, mr.WithContext(ctx), mr.WithWorkers(opts.Concurrency))
+
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if !opts.ContinueOnError && len(report.FailedKeys) > failedBefore {
+			return report, fmt.Errorf("migrate %d objects failed, first: %s", len(report.FailedKeys)-failedBefore, report.FailedKeys[failedBefore])
+		}
+
+		// 整页完成后才推进断点，保证中断后不会遗漏对象
+		report.Marker = next
+		report.Done = next == ""
+		if err := saveMigrateCheckpoint(opts.CheckpointFile, report); err != nil {
+			return report, err
+		}
+		if report.Done {
+			break
+		}
+	}
+
+	logx.WithContext(ctx).Infof("Migrated %d objects (%d bytes) from %s to %s with prefix %q, %d failed",
+		report.Copied, report.Bytes, src, dst, prefix, len(report.FailedKeys))
+	return report, nil
+}
+
+// migrateObject 流式复制单个对象，按上传配置的访问权限写入目标存储，返回复制的字节数
+func migrateObject(ctx context.Context, src ObjectReader, dst Storage, dstReader ObjectReader, obj ObjectInfo, cfg *configx.UploadConfig) (int64, error) {
+	body, err := src.Download(ctx, obj.Key)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(obj.Key)))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// 上传的同时计算源内容的sha256和长度
+	h := sha256.New()
+	counter := &countingReader{r: io.TeeReader(body, h)}
+	acl := cfg.ACLFor(configx.DetectFileType(contentType))
+	if _, err := uploadObject(ctx, dst, counter, obj.Key, contentType, acl); err != nil {
+		return 0, err
+	}
+	if obj.Size > 0 && counter.n != obj.Size {
+		return 0, fmt.Errorf("%w: %s size %d, copied %d", ErrChecksumMismatch, obj.Key, obj.Size, counter.n)
+	}
+
+	if dstReader == nil {
+		return counter.n, nil
+	}
+
+	expected := hex.EncodeToString(h.Sum(nil))
+	actual, err := sha256Object(ctx, dstReader, obj.Key)
+	if err != nil {
+		return 0, fmt.Errorf("verify %s: %w", obj.Key, err)
+	}
+	if actual != expected {
+		return 0, fmt.Errorf("%w: %s source %s, destination %s", ErrChecksumMismatch, obj.Key, expected, actual)
+	}
+	return counter.n, nil
+}
+
+// sha256Object 下载对象并计算sha256
+func sha256Object(ctx context.Context, reader ObjectReader, key string) (string, error) {
+	body, err := reader.Download(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// countingReader 统计读取字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// loadMigrateCheckpoint 加载断点，不存在时返回新的迁移结果
+func loadMigrateCheckpoint(file, src, dst, prefix string) (*MigrateReport, error) {
+	report := &MigrateReport{Src: src, Dst: dst, Prefix: prefix}
+	if file == "" {
+		return report, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return report, nil
+		}
+		return nil, fmt.Errorf("failed to read migrate checkpoint: %w", err)
+	}
+
+	var saved MigrateReport
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode migrate checkpoint: %w", err)
+	}
+	if saved.Src != src || saved.Dst != dst || saved.Prefix != prefix {
+		return nil, fmt.Errorf("migrate checkpoint %s belongs to %s -> %s with prefix %q", file, saved.Src, saved.Dst, saved.Prefix)
+	}
+
+	logx.Infof("Resuming migration from %s to %s after %q (%d objects copied)", src, dst, saved.Marker, saved.Copied)
+	return &saved, nil
+}
+
+// saveMigrateCheckpoint 原子写入断点文件
+func saveMigrateCheckpoint(file string, report *MigrateReport) error {
+	if file == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode migrate checkpoint: %w", err)
+	}
+
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write migrate checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to write migrate checkpoint: %w", err)
+	}
+	return nil
+}
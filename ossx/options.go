@@ -0,0 +1,133 @@
+package ossx
+
+import (
+	"context"
+	"io"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// 上传结果中签名URL的默认有效期
+const defaultSignedURLExpiration = 24 * time.Hour
+
+// ObjectOptions 上传对象的选项
+type ObjectOptions struct {
+	ACL configx.ACL
+	// 目标已存在时不覆盖，返回 ErrObjectExists
+	IfAbsent bool
+	// 存储类型，见 configx.StorageClassIA 等，也可使用 S3 的 STANDARD_IA、OSS 的 IA 等原生取值，为空时使用存储桶默认值
+	StorageClass string
+	// Cache-Control 响应头
+	CacheControl string
+	// 自定义元数据，各存储按 x-amz-meta-、x-oss-meta-、x-cos-meta- 前缀保存
+	Metadata map[string]string
+}
+
+// OptionsUploader 支持存储类型、缓存控制和自定义元数据等上传选项的存储，内置存储均已实现
+type OptionsUploader interface {
+	UploadWithOptions(ctx context.Context, file io.Reader, path, contentType string, opts ObjectOptions) (string, error)
+}
+
+// uploadOptions 单次上传的选项
+type uploadOptions struct {
+	acl                 configx.ACL
+	ifAbsent            bool
+	signedURLExpiration time.Duration
+	storageClass        string
+	cacheControl        string
+	metadata            map[string]string
+	path                string
+}
+
+// UploadOption 单次上传的选项，优先于上传配置
+type UploadOption func(o *uploadOptions)
+
+// WithACL 设置访问权限，默认按文件分类取上传配置的权限
+func WithACL(acl configx.ACL) UploadOption {
+	return func(o *uploadOptions) {
+		o.acl = acl
+	}
+}
+
+// WithIfAbsent 目标路径已存在时不上传，返回已有对象，同上传配置的 IfAbsent
+func WithIfAbsent() UploadOption {
+	return func(o *uploadOptions) {
+		o.ifAbsent = true
+	}
+}
+
+// WithSignedURLExpiration 设置上传结果中签名URL的有效期，默认24小时
+func WithSignedURLExpiration(d time.Duration) UploadOption {
+	return func(o *uploadOptions) {
+		if d > 0 {
+			o.signedURLExpiration = d
+		}
+	}
+}
+
+// WithStorageClass 设置存储类型，默认按文件分类取上传配置的存储类型，本地存储忽略
+func WithStorageClass(class string) UploadOption {
+	return func(o *uploadOptions) {
+		o.storageClass = class
+	}
+}
+
+// WithCacheControl 设置 Cache-Control，本地存储忽略
+func WithCacheControl(cacheControl string) UploadOption {
+	return func(o *uploadOptions) {
+		o.cacheControl = cacheControl
+	}
+}
+
+// WithMetadata 设置自定义元数据，本地存储忽略
+func WithMetadata(metadata map[string]string) UploadOption {
+	return func(o *uploadOptions) {
+		o.metadata = metadata
+	}
+}
+
+// withObjectPath 指定对象路径，不使用路径生成器，仅供内部使用，避免客户端指定任意路径
+func withObjectPath(path string) UploadOption {
+	return func(o *uploadOptions) {
+		o.path = path
+	}
+}
+
+// newUploadOptions 合并上传配置和单次上传选项
+func newUploadOptions(c *configx.UploadConfig, fileType string, opts []UploadOption) uploadOptions {
+	o := uploadOptions{signedURLExpiration: defaultSignedURLExpiration}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.acl == "" {
+		o.acl = c.ACLFor(fileType)
+	}
+	if o.storageClass == "" {
+		o.storageClass = c.StorageClassFor(fileType)
+	}
+	o.ifAbsent = o.ifAbsent || c.IfAbsent
+	return o
+}
+
+// objectOptions 转换为存储的上传选项
+func (o uploadOptions) objectOptions() ObjectOptions {
+	return ObjectOptions{
+		ACL:          o.acl,
+		IfAbsent:     o.ifAbsent,
+		StorageClass: o.storageClass,
+		CacheControl: o.cacheControl,
+		Metadata:     o.metadata,
+	}
+}
+
+// putObject 按选项上传，存储不支持 OptionsUploader 时只使用访问权限和 IfAbsent
+func putObject(ctx context.Context, storage Storage, file io.Reader, path, contentType string, opts ObjectOptions) (string, error) {
+	if ou, ok := storage.(OptionsUploader); ok {
+		return ou.UploadWithOptions(ctx, file, path, contentType, opts)
+	}
+	if opts.IfAbsent {
+		return uploadObjectIfAbsent(ctx, storage, file, path, contentType, opts.ACL)
+	}
+	return uploadObject(ctx, storage, file, path, contentType, opts.ACL)
+}
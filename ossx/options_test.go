@@ -0,0 +1,115 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+func TestNewUploadOptions(t *testing.T) {
+	cfg := configx.NewDefaultUploadConfig()
+	cfg.ACL = map[string]configx.ACL{"images": configx.ACLPublic}
+	cfg.DefaultACL = configx.ACLPrivate
+	cfg.StorageClass = map[string]string{"videos": configx.StorageClassIA}
+	cfg.DefaultStorageClass = configx.StorageClassStandard
+
+	tests := []struct {
+		name     string
+		fileType string
+		cfg      func(c *configx.UploadConfig)
+		opts     []UploadOption
+		want     ObjectOptions
+		wantTTL  time.Duration
+	}{
+		{"config by file type", "images", nil, nil,
+			ObjectOptions{ACL: configx.ACLPublic, StorageClass: configx.StorageClassStandard}, defaultSignedURLExpiration},
+		{"config default", "videos", nil, nil,
+			ObjectOptions{ACL: configx.ACLPrivate, StorageClass: configx.StorageClassIA}, defaultSignedURLExpiration},
+		{"options override config", "images", nil,
+			[]UploadOption{WithACL(configx.ACLAuthenticated), WithStorageClass("GLACIER_IR"), WithCacheControl("no-cache"),
+				WithMetadata(map[string]string{"owner": "7"}), WithSignedURLExpiration(time.Minute)},
+			ObjectOptions{ACL: configx.ACLAuthenticated, StorageClass: "GLACIER_IR", CacheControl: "no-cache", Metadata: map[string]string{"owner": "7"}},
+			time.Minute},
+		{"non-positive expiration ignored", "images", nil, []UploadOption{WithSignedURLExpiration(-time.Minute)},
+			ObjectOptions{ACL: configx.ACLPublic, StorageClass: configx.StorageClassStandard}, defaultSignedURLExpiration},
+		{"if absent from config", "images", func(c *configx.UploadConfig) { c.IfAbsent = true }, nil,
+			ObjectOptions{ACL: configx.ACLPublic, IfAbsent: true, StorageClass: configx.StorageClassStandard}, defaultSignedURLExpiration},
+		{"if absent from option", "images", nil, []UploadOption{WithIfAbsent()},
+			ObjectOptions{ACL: configx.ACLPublic, IfAbsent: true, StorageClass: configx.StorageClassStandard}, defaultSignedURLExpiration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := *cfg
+			if tt.cfg != nil {
+				tt.cfg(&c)
+			}
+			o := newUploadOptions(&c, tt.fileType, tt.opts)
+			got := o.objectOptions()
+			if got.ACL != tt.want.ACL || got.IfAbsent != tt.want.IfAbsent || got.StorageClass != tt.want.StorageClass ||
+				got.CacheControl != tt.want.CacheControl || got.Metadata["owner"] != tt.want.Metadata["owner"] {
+				t.Fatalf("objectOptions = %+v, want %+v", got, tt.want)
+			}
+			if o.signedURLExpiration != tt.wantTTL {
+				t.Fatalf("signedURLExpiration = %v, want %v", o.signedURLExpiration, tt.wantTTL)
+			}
+		})
+	}
+}
+
+func TestUploadOptionsS3Headers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []UploadOption
+		want map[string]string
+	}{
+		{"defaults", nil, map[string]string{
+			"X-Amz-Acl": "public-read", "X-Amz-Storage-Class": "", "Cache-Control": "",
+		}},
+		{"all options", []UploadOption{
+			WithACL(configx.ACLPrivate), WithStorageClass(configx.StorageClassIA), WithCacheControl("max-age=60"),
+			WithMetadata(map[string]string{"owner": "7"}),
+		}, map[string]string{
+			"X-Amz-Acl": "private", "X-Amz-Storage-Class": "STANDARD_IA", "Cache-Control": "max-age=60", "X-Amz-Meta-Owner": "7",
+		}},
+		{"native storage class", []UploadOption{WithStorageClass("GLACIER_IR")}, map[string]string{
+			"X-Amz-Storage-Class": "GLACIER_IR",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := newTestManager(t)
+			f, storage := newFakeS3Storage(t)
+			u.storages[AmazonS3] = storage
+
+			header := &multipart.FileHeader{
+				Filename: "a.png",
+				Size:     int64(len(testPNG)),
+				Header:   textproto.MIMEHeader{"Content-Type": {"image/png"}},
+			}
+			opts := append(tt.opts, WithSignedURLExpiration(time.Minute))
+			result, err := u.Upload(context.Background(), AmazonS3, bytes.NewReader(testPNG), header, 7, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expire := time.Until(time.Unix(result.SignedURLExpire, 0)); expire > time.Minute || expire < 50*time.Second {
+				t.Fatalf("signed url expires in %v", expire)
+			}
+
+			got := f.header(strings.TrimPrefix(result.RelativePath, "/"))
+			if got == nil {
+				t.Fatalf("object %s not uploaded", result.RelativePath)
+			}
+			for k, v := range tt.want {
+				if got.Get(k) != v {
+					t.Fatalf("header %s = %q, want %q", k, got.Get(k), v)
+				}
+			}
+		})
+	}
+}
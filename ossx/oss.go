@@ -20,6 +20,7 @@ type ossStorage struct {
 	bucketName string
 	endpoint   string
 	cdnDomain  string
+	cdn        *cdnSigner // CDN鉴权（可选），设置后签名URL指向CDN
 }
 
 // newOSSStorage 创建新的阿里云OSS存储实例（使用SDK v2）
@@ -45,13 +46,20 @@ func newOSSStorage(sc configx.StorageConfig) (Storage, error) {
 		}
 	}
 
+	cdn, err := newCDNSigner(sc.CdnDomain, sc.CDNAuth, "auth_key")
+	if err != nil {
+		return nil, fmt.Errorf("oss cdn auth: %w", err)
+	}
+
 	// 创建凭证提供者
 	cred := credentials.NewStaticCredentialsProvider(sc.AccessKey, sc.SecretKey)
 
 	// 创建配置
+	// 临时上传URL的签名需包含 Content-Length，限制上传大小
 	cfg := oss.LoadDefaultConfig().
 		WithCredentialsProvider(cred).
-		WithRegion(sc.Region)
+		WithRegion(sc.Region).
+		WithAdditionalHeaders([]string{"Content-Length"})
 
 	// 创建客户端
 	client := oss.NewClient(cfg)
@@ -61,11 +69,27 @@ func newOSSStorage(sc configx.StorageConfig) (Storage, error) {
 		bucketName: sc.Bucket,
 		endpoint:   endpoint,
 		cdnDomain:  sc.CdnDomain,
+		cdn:        cdn,
 	}, nil
 }
 
-// Upload 实现Storage接口的上传方法（使用SDK v2）
+// Upload 实现Storage接口的上传方法（使用SDK v2），使用存储的默认权限
 func (s *ossStorage) Upload(ctx context.Context, file io.Reader, path, contentType string) (string, error) {
+	return s.UploadWithACL(ctx, file, path, contentType, configx.ACLDefault)
+}
+
+// UploadWithACL 实现 ACLStorage 接口，按指定访问权限上传
+func (s *ossStorage) UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl})
+}
+
+// UploadIfAbsent 实现 ConditionalUploader 接口，使用 x-oss-forbid-overwrite 禁止覆盖，已存在时返回 ErrObjectExists
+func (s *ossStorage) UploadIfAbsent(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl, IfAbsent: true})
+}
+
+// UploadWithOptions 实现 OptionsUploader 接口
+func (s *ossStorage) UploadWithOptions(ctx context.Context, file io.Reader, path, contentType string, opts ObjectOptions) (string, error) {
 	// 标准化路径，处理前导斜杠
 	path = strings.TrimPrefix(path, "/")
 
@@ -75,30 +99,101 @@ func (s *ossStorage) Upload(ctx context.Context, file io.Reader, path, contentTy
 		Key:         oss.Ptr(path),
 		Body:        file,
 		ContentType: oss.Ptr(contentType),
-		//Acl:         oss.ObjectACLPublicRead,
+		Acl:         ossACL(opts.ACL),
+		Metadata:    opts.Metadata,
+	}
+	if opts.IfAbsent {
+		request.ForbidOverwrite = oss.Ptr("true")
+	}
+	if opts.StorageClass != "" {
+		request.StorageClass = ossStorageClass(opts.StorageClass)
+	}
+	if opts.CacheControl != "" {
+		request.CacheControl = oss.Ptr(opts.CacheControl)
 	}
 
 	// 执行文件上传
 	_, err := s.client.PutObject(ctx, request)
 	if err != nil {
+		if opts.IfAbsent && isConflict(err) {
+			return "", fmt.Errorf("%w: %s", ErrObjectExists, path)
+		}
 		return "", fmt.Errorf("failed to upload file to OSS: %w", err)
 	}
 
 	// 生成文件URL
-	var fileURL string
+	return s.objectURL(path), nil
+}
+
+// SetACL 实现 ACLStorage 接口，修改已有对象的访问权限
+func (s *ossStorage) SetACL(ctx context.Context, path string, acl configx.ACL) error {
+	_, err := s.client.PutObjectAcl(ctx, &oss.PutObjectAclRequest{
+		Bucket: oss.Ptr(s.bucketName),
+		Key:    oss.Ptr(strings.TrimPrefix(path, "/")),
+		Acl:    ossACL(acl),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set object ACL: %w", err)
+	}
+	return nil
+}
+
+// GetACL 实现 ACLReader 接口，对象ACL为 default 时继承存储桶权限，返回空
+func (s *ossStorage) GetACL(ctx context.Context, path string) (configx.ACL, error) {
+	result, err := s.client.GetObjectAcl(ctx, &oss.GetObjectAclRequest{
+		Bucket: oss.Ptr(s.bucketName),
+		Key:    oss.Ptr(strings.TrimPrefix(path, "/")),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object ACL: %w", err)
+	}
+	switch oss.ToString(result.ACL) {
+	case string(oss.ObjectACLPublicRead), string(oss.ObjectACLPublicReadWrite):
+		return configx.ACLPublic, nil
+	case string(oss.ObjectACLPrivate):
+		return configx.ACLPrivate, nil
+	default:
+		return "", nil
+	}
+}
+
+// ossACL 转换为OSS对象ACL，未指定时继承存储桶权限，OSS不支持认证用户可读，按私有处理
+func ossACL(acl configx.ACL) oss.ObjectACLType {
+	switch acl {
+	case configx.ACLDefault:
+		return oss.ObjectACLDefault
+	case configx.ACLPublic:
+		return oss.ObjectACLPublicRead
+	default:
+		return oss.ObjectACLPrivate
+	}
+}
+
+// ossStorageClass 转换为OSS存储类型
+func ossStorageClass(class string) oss.StorageClassType {
+	switch class {
+	case configx.StorageClassStandard:
+		return oss.StorageClassStandard
+	case configx.StorageClassIA:
+		return oss.StorageClassIA
+	case configx.StorageClassArchive:
+		return oss.StorageClassArchive
+	case configx.StorageClassDeepArchive:
+		return oss.StorageClassColdArchive
+	default:
+		return oss.StorageClassType(class)
+	}
+}
+
+// objectURL 生成对象的访问URL，优先使用CDN域名
+func (s *ossStorage) objectURL(path string) string {
 	if s.cdnDomain != "" {
-		// 使用CDN域名生成URL
 		if strings.HasPrefix(s.cdnDomain, "http") {
-			fileURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
-		} else {
-			fileURL = fmt.Sprintf("https://%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
+			return fmt.Sprintf("%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
 		}
-	} else {
-		// 使用OSS默认域名生成URL
-		fileURL = fmt.Sprintf("https://%s.%s/%s", s.bucketName, s.endpoint, path)
+		return fmt.Sprintf("https://%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
 	}
-
-	return fileURL, nil
+	return fmt.Sprintf("https://%s.%s/%s", s.bucketName, s.endpoint, path)
 }
 
 // Delete 实现Storage接口的删除方法（使用SDK v2）
@@ -123,6 +218,11 @@ func (s *ossStorage) CreateSignedURL(ctx context.Context, path string, expiratio
 	// 标准化路径
 	path = strings.TrimPrefix(path, "/")
 
+	// 配置CDN鉴权时生成CDN签名URL
+	if s.cdn != nil {
+		return s.cdn.sign(path, expiration)
+	}
+
 	// 计算过期时间（绝对时间）
 	expirationTime := time.Now().Add(expiration)
 
@@ -186,7 +286,7 @@ func (s *ossStorage) ListObjects(ctx context.Context, prefix string, maxKeys int
 	return keys, nil
 }
 
-// CopyObject 在OSS内部复制对象（使用SDK v2）
+// CopyObject 在OSS内部复制对象（使用SDK v2），实现 ObjectCopier 接口
 func (s *ossStorage) CopyObject(ctx context.Context, srcPath, destPath string) error {
 	// 标准化路径
 	srcPath = strings.TrimPrefix(srcPath, "/")
@@ -304,18 +404,7 @@ func (s *ossStorage) UploadLargeFile(ctx context.Context, filePath, objectKey st
 	}
 
 	// 生成文件URL
-	var fileURL string
-	if s.cdnDomain != "" {
-		if strings.HasPrefix(s.cdnDomain, "http") {
-			fileURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), objectKey)
-		} else {
-			fileURL = fmt.Sprintf("https://%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), objectKey)
-		}
-	} else {
-		fileURL = fmt.Sprintf("https://%s.%s/%s", s.bucketName, s.endpoint, objectKey)
-	}
-
-	return fileURL, nil
+	return s.objectURL(objectKey), nil
 }
 
 // ProcessImage 使用OSS图片处理服务处理图片（使用SDK v2）
@@ -377,3 +466,271 @@ func (s *ossStorage) BatchDeleteObjects(ctx context.Context, keys []string) erro
 
 	return nil
 }
+
+// ListPage 分页列出指定前缀的对象，marker 为上一页返回的续传标记
+func (s *ossStorage) ListPage(ctx context.Context, prefix, marker string, limit int) ([]ObjectInfo, string, error) {
+	request := &oss.ListObjectsV2Request{
+		Bucket:  oss.Ptr(s.bucketName),
+		Prefix:  oss.Ptr(strings.TrimPrefix(prefix, "/")),
+		MaxKeys: int32(limit),
+	}
+	if marker != "" {
+		request.ContinuationToken = oss.Ptr(marker)
+	}
+
+	result, err := s.client.ListObjectsV2(ctx, request)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	objects := make([]ObjectInfo, 0, len(result.Contents))
+	for _, obj := range result.Contents {
+		info := ObjectInfo{
+			Key:  oss.ToString(obj.Key),
+			Size: obj.Size,
+			ETag: strings.Trim(oss.ToString(obj.ETag), `"`),
+		}
+		if obj.LastModified != nil {
+			info.LastModified = *obj.LastModified
+		}
+		objects = append(objects, info)
+	}
+
+	if !result.IsTruncated {
+		return objects, "", nil
+	}
+	return objects, oss.ToString(result.NextContinuationToken), nil
+}
+
+// Download 流式下载对象，调用方负责关闭
+func (s *ossStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(ctx, &oss.GetObjectRequest{
+		Bucket: oss.Ptr(s.bucketName),
+		Key:    oss.Ptr(strings.TrimPrefix(path, "/")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object from OSS: %w", err)
+	}
+	return result.Body, nil
+}
+
+// StatObject 实现 RangeReader 接口，获取对象元信息
+func (s *ossStorage) StatObject(ctx context.Context, path string) (*ObjectMeta, error) {
+	result, err := s.GetObjectMeta(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	meta := &ObjectMeta{
+		Size:        result.ContentLength,
+		ContentType: oss.ToString(result.ContentType),
+		ETag:        oss.ToString(result.ETag),
+		CRC64:       oss.ToString(result.HashCRC64),
+		Encryption:  oss.ToString(result.ServerSideEncryption),
+	}
+	if result.LastModified != nil {
+		meta.LastModified = *result.LastModified
+	}
+	return meta, nil
+}
+
+// DownloadRange 实现 RangeReader 接口，按范围流式下载对象
+func (s *ossStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(ctx, &oss.GetObjectRequest{
+		Bucket:        oss.Ptr(s.bucketName),
+		Key:           oss.Ptr(strings.TrimPrefix(path, "/")),
+		Range:         oss.Ptr(rangeHeader(offset, length)),
+		RangeBehavior: oss.Ptr("standard"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object range from OSS: %w", err)
+	}
+	return result.Body, nil
+}
+
+// InitMultipart 实现 MultipartUploader 接口，初始化分片上传
+func (s *ossStorage) InitMultipart(ctx context.Context, path, contentType string) (string, error) {
+	result, err := s.client.InitiateMultipartUpload(ctx, &oss.InitiateMultipartUploadRequest{
+		Bucket:      oss.Ptr(s.bucketName),
+		Key:         oss.Ptr(strings.TrimPrefix(path, "/")),
+		ContentType: oss.Ptr(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	return oss.ToString(result.UploadId), nil
+}
+
+// UploadPart 实现 MultipartUploader 接口，上传单个分片
+func (s *ossStorage) UploadPart(ctx context.Context, path, uploadID string, partNumber int32, body io.ReadSeeker, size int64) (string, error) {
+	result, err := s.client.UploadPart(ctx, &oss.UploadPartRequest{
+		Bucket:        oss.Ptr(s.bucketName),
+		Key:           oss.Ptr(strings.TrimPrefix(path, "/")),
+		UploadId:      oss.Ptr(uploadID),
+		PartNumber:    partNumber,
+		Body:          body,
+		ContentLength: oss.Ptr(size),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part to OSS: %w", err)
+	}
+	return oss.ToString(result.ETag), nil
+}
+
+// CompleteMultipart 实现 MultipartUploader 接口，合并分片
+func (s *ossStorage) CompleteMultipart(ctx context.Context, path, uploadID string, parts []CompletedPart) (string, error) {
+	path = strings.TrimPrefix(path, "/")
+	uploadParts := make([]oss.UploadPart, len(parts))
+	for i, p := range parts {
+		uploadParts[i] = oss.UploadPart{PartNumber: p.Number, ETag: oss.Ptr(p.ETag)}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &oss.CompleteMultipartUploadRequest{
+		Bucket:   oss.Ptr(s.bucketName),
+		Key:      oss.Ptr(path),
+		UploadId: oss.Ptr(uploadID),
+		CompleteMultipartUpload: &oss.CompleteMultipartUpload{
+			Parts: uploadParts,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return s.objectURL(path), nil
+}
+
+// AbortMultipart 实现 MultipartUploader 接口，取消分片上传
+func (s *ossStorage) AbortMultipart(ctx context.Context, path, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &oss.AbortMultipartUploadRequest{
+		Bucket:   oss.Ptr(s.bucketName),
+		Key:      oss.Ptr(strings.TrimPrefix(path, "/")),
+		UploadId: oss.Ptr(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// PutLifecycle 实现 LifecycleStorage 接口，设置存储桶生命周期规则
+func (s *ossStorage) PutLifecycle(ctx context.Context, rules []LifecycleRule) error {
+	ossRules := make([]oss.LifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		rule := oss.LifecycleRule{
+			ID:     oss.Ptr(r.ID),
+			Prefix: oss.Ptr(r.Prefix),
+			Status: oss.Ptr(lifecycleStatus(r.Disabled)),
+		}
+		for _, t := range r.Transitions {
+			rule.Transitions = append(rule.Transitions, oss.LifecycleRuleTransition{
+				Days:         oss.Ptr(int32(t.Days)),
+				StorageClass: ossStorageClass(t.StorageClass),
+			})
+		}
+		if r.ExpirationDays > 0 {
+			rule.Expiration = &oss.LifecycleRuleExpiration{Days: oss.Ptr(int32(r.ExpirationDays))}
+		}
+		if r.AbortMultipartDays > 0 {
+			rule.AbortMultipartUpload = &oss.LifecycleRuleAbortMultipartUpload{Days: oss.Ptr(int32(r.AbortMultipartDays))}
+		}
+		ossRules = append(ossRules, rule)
+	}
+
+	_, err := s.client.PutBucketLifecycle(ctx, &oss.PutBucketLifecycleRequest{
+		Bucket:                 oss.Ptr(s.bucketName),
+		LifecycleConfiguration: &oss.LifecycleConfiguration{Rules: ossRules},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put OSS bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// GetLifecycle 实现 LifecycleStorage 接口，获取存储桶生命周期规则
+func (s *ossStorage) GetLifecycle(ctx context.Context) ([]LifecycleRule, error) {
+	result, err := s.client.GetBucketLifecycle(ctx, &oss.GetBucketLifecycleRequest{
+		Bucket: oss.Ptr(s.bucketName),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get OSS bucket lifecycle: %w", err)
+	}
+	if result.LifecycleConfiguration == nil {
+		return nil, nil
+	}
+
+	rules := make([]LifecycleRule, 0, len(result.LifecycleConfiguration.Rules))
+	for _, r := range result.LifecycleConfiguration.Rules {
+		rule := LifecycleRule{
+			ID:       oss.ToString(r.ID),
+			Prefix:   oss.ToString(r.Prefix),
+			Disabled: oss.ToString(r.Status) != lifecycleStatus(false),
+		}
+		rule.unsupportedIf(r.Filter != nil, "filter.not")
+		rule.unsupportedIf(len(r.Tags) > 0, "filter.tag")
+		for _, t := range r.Transitions {
+			rule.unsupportedIf(t.CreatedBeforeDate != nil, "transition.date")
+			rule.unsupportedIf(oss.ToBool(t.IsAccessTime), "transition.access_time")
+			rule.Transitions = append(rule.Transitions, LifecycleTransition{
+				Days:         ossDays(t.Days),
+				StorageClass: string(t.StorageClass),
+			})
+		}
+		if r.Expiration != nil {
+			rule.unsupportedIf(r.Expiration.CreatedBeforeDate != nil || r.Expiration.Date != nil, "expiration.date")
+			rule.unsupportedIf(oss.ToBool(r.Expiration.ExpiredObjectDeleteMarker), "expiration.expired_object_delete_marker")
+			rule.ExpirationDays = ossDays(r.Expiration.Days)
+		}
+		if r.AbortMultipartUpload != nil {
+			rule.unsupportedIf(r.AbortMultipartUpload.CreatedBeforeDate != nil || r.AbortMultipartUpload.Date != nil, "abort_multipart.date")
+			rule.AbortMultipartDays = ossDays(r.AbortMultipartUpload.Days)
+		}
+		rule.unsupportedIf(r.NoncurrentVersionExpiration != nil || len(r.NoncurrentVersionTransitions) > 0, "noncurrent_version")
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DeleteLifecycle 实现 LifecycleStorage 接口，删除存储桶生命周期规则
+func (s *ossStorage) DeleteLifecycle(ctx context.Context) error {
+	_, err := s.client.DeleteBucketLifecycle(ctx, &oss.DeleteBucketLifecycleRequest{
+		Bucket: oss.Ptr(s.bucketName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete OSS bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// ossDays 读取规则中的天数
+func ossDays(days *int32) int {
+	if days == nil {
+		return 0
+	}
+	return int(*days)
+}
+
+// PresignPut 实现 PresignedUploader 接口，签名包含 Content-Type、Content-Length 和禁止覆盖
+func (s *ossStorage) PresignPut(ctx context.Context, path, contentType string, size int64, expiration time.Duration) (string, map[string]string, error) {
+	path = strings.TrimPrefix(path, "/")
+
+	result, err := s.client.Presign(ctx, &oss.PutObjectRequest{
+		Bucket:          oss.Ptr(s.bucketName),
+		Key:             oss.Ptr(path),
+		ContentType:     oss.Ptr(contentType),
+		ContentLength:   oss.Ptr(size),
+		ForbidOverwrite: oss.Ptr("true"),
+	}, func(opts *oss.PresignOptions) {
+		opts.Expiration = time.Now().Add(expiration)
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create presigned put URL: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": contentType}
+	for key, value := range result.SignedHeaders {
+		headers[key] = value
+	}
+	return result.URL, headers, nil
+}
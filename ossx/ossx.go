@@ -1,12 +1,12 @@
 package ossx
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	configx "github.com/QuantumShiftX/golib/ossx/config"
-	"github.com/google/uuid"
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/core/mr"
 	"io"
 	"mime"
 	"mime/multipart"
@@ -42,6 +42,8 @@ type UploadResult struct {
 	RelativePath string `json:"relative_path"`
 	// 文件名
 	FileName string `json:"file_name"`
+	// 用户上传时的原文件名
+	OriginalName string `json:"original_name,omitempty"`
 	// 文件类型
 	FileType string `json:"file_type"`
 	// 文件大小
@@ -50,6 +52,20 @@ type UploadResult struct {
 	StorageType string `json:"storage_type"`
 	// 签名URL过期时间（Unix时间戳）
 	SignedURLExpire int64 `json:"signed_url_expire,omitempty"`
+	// 内容哈希（sha256，仅内容寻址和去重模式）
+	ContentHash string `json:"content_hash,omitempty"`
+	// 是否命中去重，命中时返回已有对象，未实际上传
+	Deduplicated bool `json:"deduplicated,omitempty"`
+	// 内容MD5（十六进制）
+	MD5 string `json:"md5,omitempty"`
+	// 内容CRC64-ECMA（十进制）
+	CRC64 string `json:"crc64,omitempty"`
+	// 存储返回的ETag（仅开启校验时）
+	ETag string `json:"etag,omitempty"`
+	// 是否已与存储返回的CRC64或ETag比对一致，分片上传、KMS加密等无法比对的情况为 false
+	ChecksumVerified bool `json:"checksum_verified,omitempty"`
+	// 开启 IfAbsent 时目标已存在，未实际上传
+	Existed bool `json:"existed,omitempty"`
 }
 
 // SignedURLResult 批量签名URL结果
@@ -58,6 +74,8 @@ type SignedURLResult struct {
 	SignedURL    string `json:"signed_url"`
 	Error        string `json:"error,omitempty"`
 	Expire       int64  `json:"expire"`
+	// 签名失败时的错误
+	Err *SignedURLError `json:"-"`
 }
 
 var (
@@ -71,6 +89,16 @@ type UploadManager struct {
 	configs      map[string]configx.StorageConfig
 	storages     map[string]Storage
 	uploadConfig *configx.UploadConfig
+	contentIndex ContentIndex
+	quota        *QuotaTracker
+	checkpoints  CheckpointStore
+	retry        *RetryPolicy
+	dedup        DedupIndex
+	hooks        uploadHooks
+	failover     *failover
+	trash        *TrashPolicy
+	fetchClient  *http.Client
+	uploadTokens UploadTokenStore
 	errors       []error
 }
 
@@ -83,6 +111,7 @@ func Must(configs ...configx.StorageConfig) {
 			configs:      make(map[string]configx.StorageConfig),
 			storages:     make(map[string]Storage),
 			uploadConfig: configx.NewDefaultUploadConfig(),
+			contentIndex: NewMemoryContentIndex(),
 			errors:       make([]error, 0),
 		}
 		for _, c := range configs {
@@ -150,6 +179,9 @@ func newCOSStorage(cfg configx.StorageConfig) (Storage, error) {
 		SecretID:   cfg.AccessKey,
 		SecretKey:  cfg.SecretKey,
 	}
+	if cfg.CdnDomain != "" {
+		cosConfig.CDNAuth = cfg.CDNAuth
+	}
 
 	if cosConfig.RequestURL == "" {
 		cosConfig.RequestURL = cosConfig.BucketURL
@@ -158,8 +190,26 @@ func newCOSStorage(cfg configx.StorageConfig) (Storage, error) {
 	return NewCosStorage(cosConfig)
 }
 
-// Upload 上传文件 - 使用指定的存储类型
-func (u *UploadManager) Upload(ctx context.Context, storageType string, file io.Reader, header *multipart.FileHeader, userId int64) (*UploadResult, error) {
+// Upload 上传文件 - 使用指定的存储类型，opts 可按次设置访问权限、签名URL有效期、存储类型等
+func (u *UploadManager) Upload(ctx context.Context, storageType string, file io.Reader, header *multipart.FileHeader, userId int64, opts ...UploadOption) (result *UploadResult, err error) {
+	uc := &UploadContext{
+		StorageType: storageType,
+		UserID:      userId,
+		FileName:    header.Filename,
+		Size:        header.Size,
+		Reader:      file,
+		Values:      make(map[string]any),
+	}
+	hooks := u.uploadHooks()
+	dedup := u.dedupIndex()
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			hooks.runOnError(ctx, uc, err)
+		}
+		recordUpload(storageType, start, result, err)
+	}()
+
 	// 查找存储实例
 	storage, ok := u.storages[storageType]
 	if !ok {
@@ -177,30 +227,135 @@ func (u *UploadManager) Upload(ctx context.Context, storageType string, file io.
 		contentType = u.detectContentType(file, header.Filename)
 	}
 
+	// 重置文件读取位置（如果文件是 io.Seeker）
+	if seeker, ok := file.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to reset file reader: %w", err)
+		}
+	}
+
+	// 按文件头校验实际类型
+	if u.uploadConfig.SniffContent {
+		var head []byte
+		if head, file, err = peekHead(file, configx.SniffLen); err != nil {
+			return nil, fmt.Errorf("failed to read file header: %w", err)
+		}
+		uc.Reader = file
+		if contentType, err = u.uploadConfig.ValidateContent(header.Filename, contentType, head); err != nil {
+			return nil, fmt.Errorf("file validation failed: %w", err)
+		}
+	}
+
+	// 执行上传前钩子，钩子可替换文件内容
+	uc.ContentType = contentType
+	if err := hooks.runBeforeUpload(ctx, uc); err != nil {
+		return nil, err
+	}
+	file, contentType, size := uc.Reader, uc.ContentType, uc.Size
+
 	// 获取文件类型分类
 	fileType := configx.DetectFileType(contentType)
 
-	// 生成唯一文件名
-	fileName := uuid.New().String() + filepath.Ext(header.Filename)
+	// 内容寻址、去重模式或文件名需要内容哈希时计算内容哈希
+	var contentHash string
+	if u.uploadConfig.NeedsContentHash() || dedup != nil {
+		if contentHash, file, err = hashContent(file); err != nil {
+			return nil, err
+		}
+	}
+
+	// 去重模式下同一用户以相同访问权限上传过的内容直接返回已有对象
+	o := newUploadOptions(u.uploadConfig, fileType, opts)
+	scope := dedupScope(userId, o.acl)
+	if dedup != nil && o.path == "" {
+		if result, ok := u.lookupDedup(ctx, dedup, storage, storageType, scope, contentHash, o.signedURLExpiration); ok {
+			uc.Path = strings.TrimPrefix(result.RelativePath, "/")
+			if err := hooks.runAfterUpload(ctx, uc, result); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}
+	}
+
+	// 按文件名策略生成文件名，内容寻址模式下使用内容哈希
+	fileName := u.uploadConfig.GenerateName(configx.NameInfo{
+		OriginalName: uc.FileName,
+		Ext:          filepath.Ext(uc.FileName),
+		ContentType:  contentType,
+		FileType:     fileType,
+		UserID:       userId,
+		ContentHash:  contentHash,
+	})
 
-	// 生成文件路径
-	path := u.uploadConfig.PathGenerator(userId, fileType, fileName)
+	// 生成文件路径，内容寻址模式下由内容哈希生成
+	path := o.path
+	if path == "" {
+		path = u.uploadConfig.ObjectPath(userId, fileType, fileName)
+	} else {
+		fileName = filepath.Base(path)
+	}
+	uc.Path = path
 
-	// 重置文件读取位置（如果文件是 io.Seeker）
-	if seeker, ok := file.(io.Seeker); ok {
-		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
-			return nil, fmt.Errorf("failed to reset file reader: %w", err)
+	// 占用用户配额，上传失败时归还
+	quota := u.QuotaTracker()
+	if quota != nil {
+		if err := quota.Reserve(ctx, userId, size); err != nil {
+			return nil, err
+		}
+	}
+	releaseQuota := func() {
+		if quota != nil {
+			if qerr := quota.Release(ctx, userId, size); qerr != nil {
+				logx.WithContext(ctx).Errorf("failed to release quota: %v", qerr)
+			}
 		}
 	}
 
-	// 执行上传操作
-	url, err := storage.Upload(ctx, file, path, contentType)
+	// 执行上传操作，按文件分类的访问权限上传，失败时按重试策略重试
+	acl := o.acl
+	var url string
+	sum := newChecksumReader(file)
+	body := sum.reader()
+	rewind := rewinder(body)
+	err = u.withRetry(ctx, "upload "+path, rewind, func() (err error) {
+		url, err = putObject(ctx, storage, body, path, contentType, o.objectOptions())
+		return err
+	})
+	// 目标已存在时返回已有对象
+	if errors.Is(err, ErrObjectExists) {
+		releaseQuota()
+		result = u.existingResult(ctx, storage, storageType, path, fileType, contentHash, o.signedURLExpiration)
+		result.OriginalName = uc.FileName
+		if err := hooks.runAfterUpload(ctx, uc, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+	u.observePrimary(storageType, err)
 	if err != nil {
+		releaseQuota()
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// 生成签名URL（24小时有效期）
-	signedURL, err := storage.CreateSignedURL(ctx, path, 24*time.Hour)
+	// 比对存储返回的CRC64或ETag与本地校验和，不一致时删除对象
+	md5Hex := sum.MD5()
+	var (
+		etag     string
+		verified bool
+	)
+	if u.uploadConfig.VerifyChecksum {
+		etag, verified, err = verifyChecksum(ctx, storage, path, sum)
+		if err != nil {
+			if derr := u.deletePermanently(ctx, storageType, storage, path); derr != nil {
+				logx.WithContext(ctx).Errorf("failed to delete %s after checksum failure: %v", path, derr)
+			}
+			releaseQuota()
+			return nil, err
+		}
+	}
+
+	// 生成签名URL，默认24小时有效期
+	signedURL, err := u.createSignedURL(ctx, storageType, storage, path, o.signedURLExpiration)
 	if err != nil {
 		// 如果生成签名URL失败，仍然返回原始结果，但记录错误
 		logx.WithContext(ctx).Errorf("failed to generate signed URL: %v", err)
@@ -208,123 +363,137 @@ func (u *UploadManager) Upload(ctx context.Context, storageType string, file io.
 	}
 
 	// 返回上传结果
-	result := &UploadResult{
-		URL:          url,
-		SignedURL:    signedURL,
-		RelativePath: "/" + strings.TrimPrefix(path, "/"),
-		FileName:     fileName,
-		FileType:     fileType,
-		Size:         header.Size,
-		StorageType:  storageType,
+	result = &UploadResult{
+		URL:              url,
+		SignedURL:        signedURL,
+		RelativePath:     "/" + strings.TrimPrefix(path, "/"),
+		FileName:         filepath.Base(fileName),
+		OriginalName:     uc.FileName,
+		FileType:         fileType,
+		Size:             size,
+		StorageType:      storageType,
+		ContentHash:      contentHash,
+		MD5:              md5Hex,
+		CRC64:            sum.CRC64(),
+		ETag:             etag,
+		ChecksumVerified: verified,
 	}
 
 	// 如果是签名URL，添加过期时间
 	if signedURL != url {
-		result.SignedURLExpire = time.Now().Add(24 * time.Hour).Unix()
+		result.SignedURLExpire = time.Now().Add(o.signedURLExpiration).Unix()
+	}
+
+	// 执行上传后钩子，失败时删除已上传的对象
+	if err := hooks.runAfterUpload(ctx, uc, result); err != nil {
+		if derr := u.deletePermanently(ctx, storageType, storage, path); derr != nil {
+			logx.WithContext(ctx).Errorf("failed to delete %s after hook failure: %v", path, derr)
+		}
+		releaseQuota()
+		return nil, err
 	}
 
+	// 记录对象占用的配额，删除时归还
+	if quota != nil {
+		quota.own(ctx, quotaObject(storageType, path), userId, size)
+	}
+
+	// 记录逻辑文件名到内容键的索引
+	if index := u.contentIndexStore(); contentHash != "" && index != nil {
+		if err := index.Put(ctx, contentLogicalName(userId, uc.FileName), result.RelativePath); err != nil {
+			logx.WithContext(ctx).Errorf("failed to index content key for %s: %v", uc.FileName, err)
+		}
+	}
+
+	// 记录内容哈希到对象的去重索引
+	if dedup != nil {
+		u.storeDedup(ctx, dedup, scope, contentHash, result)
+	}
+
+	// 配置主备存储时异步复制到备存储
+	u.replicate(storageType, replicateJob{path: path, contentType: contentType, acl: acl})
+
 	return result, nil
 }
 
 // UploadWithUid 直接使用userId上传文件（简化版）
-func (u *UploadManager) UploadWithUid(ctx context.Context, storageType string, file multipart.File, header *multipart.FileHeader, userId int64) (*UploadResult, error) {
-	return u.Upload(ctx, storageType, file, header, userId)
+func (u *UploadManager) UploadWithUid(ctx context.Context, storageType string, file multipart.File, header *multipart.FileHeader, userId int64, opts ...UploadOption) (*UploadResult, error) {
+	return u.Upload(ctx, storageType, file, header, userId, opts...)
 }
 
-// Delete 删除文件
+// Delete 删除文件，开启回收站时先移入回收站，回收站中的对象直接删除，删除后归还上传时占用的配额；对象不存在时视为删除成功
 func (u *UploadManager) Delete(ctx context.Context, storageType string, path string) error {
 	storage, ok := u.storages[storageType]
 	if !ok {
 		return fmt.Errorf("storage type %s not initialized", storageType)
 	}
-	return storage.Delete(ctx, path)
+	trash := u.trashPolicy()
+	trashed := trash != nil && trash.inTrash(path)
+	var trashPath string
+	if trash != nil && !trashed {
+		// 对象已不存在时无需移入回收站，删除保持幂等
+		var err error
+		if trashPath, err = u.moveToTrash(ctx, trash, storageType, storage, path); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	if err := u.deletePermanently(ctx, storageType, storage, path); err != nil {
+		return err
+	}
+	u.removeDedup(ctx, storageType, path)
+	if quota := u.QuotaTracker(); quota != nil {
+		var err error
+		switch {
+		case trashed:
+			err = quota.dropObject(ctx, quotaObject(storageType, path))
+		case trashPath != "":
+			err = quota.trashObject(ctx, quotaObject(storageType, path), quotaObject(storageType, trashPath))
+		default:
+			err = quota.releaseObject(ctx, quotaObject(storageType, path))
+		}
+		if err != nil {
+			logx.WithContext(ctx).Errorf("failed to release quota of %s: %v", path, err)
+		}
+	}
+	return nil
 }
 
-// GetSignedURL 为已存在的文件生成签名URL
+// GetSignedURL 为已存在的文件生成签名URL，配置主备存储且主存储不可用时使用备存储
 func (u *UploadManager) GetSignedURL(ctx context.Context, storageType string, path string, expiration time.Duration) (string, error) {
-	storage, ok := u.storages[storageType]
+	storage, ok := u.readStorage(storageType)
 	if !ok {
 		return "", fmt.Errorf("storage type %s not initialized", storageType)
 	}
 
-	return storage.CreateSignedURL(ctx, path, expiration)
+	return u.createSignedURL(ctx, storageType, storage, path, expiration)
 }
 
-// GetSignedURLs 批量并发获取签名URL
-func (u *UploadManager) GetSignedURLs(ctx context.Context, storageType string, paths []string, expiration time.Duration) ([]SignedURLResult, error) {
-	storage, ok := u.storages[storageType]
-	if !ok {
-		return nil, fmt.Errorf("storage type %s not initialized", storageType)
-	}
+// createSignedURL 生成签名URL，失败时按重试策略重试
+func (u *UploadManager) createSignedURL(ctx context.Context, storageType string, storage Storage, path string, expiration time.Duration) (signedURL string, err error) {
+	err = u.withRetry(ctx, "sign "+path, nil, func() (err error) {
+		signedURL, err = storage.CreateSignedURL(ctx, path, expiration)
+		return err
+	})
+	recordSignedURL(storageType, err)
+	return signedURL, err
+}
 
-	if len(paths) == 0 {
-		return []SignedURLResult{}, nil
+// peekHead 读取文件头，返回可从头读取的 Reader，可 Seek 时回到起始位置，否则将文件头拼接回去
+func peekHead(r io.Reader, n int) ([]byte, io.Reader, error) {
+	head := make([]byte, n)
+	m, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
 	}
+	head = head[:m]
 
-	// 使用 mr.MapReduce 并发获取签名URL
-	results, err := mr.MapReduce(
-		// generate: 生成待处理的数据
-		func(source chan<- interface{}) {
-			for i, path := range paths {
-				source <- struct {
-					index int
-					path  string
-				}{i, path}
-			}
-		},
-
-		// mapper: 并发处理每个path
-		func(item interface{}, writer mr.Writer[*SignedURLResult], cancel func(error)) {
-			input := item.(struct {
-				index int
-				path  string
-			})
-
-			signedURL, err := storage.CreateSignedURL(ctx, input.path, expiration)
-			result := &SignedURLResult{
-				RelativePath: input.path,
-				SignedURL:    signedURL,
-				Error:        "",
-				Expire:       time.Now().Add(expiration).Unix(),
-			}
-
-			if err != nil {
-				result.Error = err.Error()
-				result.SignedURL = ""
-				result.Expire = 0
-				// 记录错误但不取消其他任务
-				logx.WithContext(ctx).Errorf("failed to get signed URL for %s: %v", input.path, err)
-			}
-
-			// 写入结果，保留索引信息用于排序
-			writer.Write(result)
-		},
-
-		// reducer: 收集结果
-		func(pipe <-chan *SignedURLResult, writer mr.Writer[[]SignedURLResult], cancel func(error)) {
-			// 使用 slice 保持原始顺序
-			results := make([]SignedURLResult, len(paths))
-
-			for result := range pipe {
-				// 找到结果对应的原始位置
-				for i, path := range paths {
-					if path == result.RelativePath {
-						results[i] = *result
-						break
-					}
-				}
-			}
-
-			writer.Write(results)
-		},
-		mr.WithWorkers(10), // 设置最大并发数为10
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get signed URLs: %w", err)
+	if seeker, ok := r.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		return head, r, nil
 	}
-
-	return results, nil
+	return head, io.MultiReader(bytes.NewReader(head), r), nil
 }
 
 // detectContentType 检测文件内容类型
@@ -0,0 +1,111 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// newTestManager 创建使用本地存储的上传管理器
+func newTestManager(t *testing.T) (*UploadManager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	u := &UploadManager{
+		configs:      make(map[string]configx.StorageConfig),
+		storages:     make(map[string]Storage),
+		uploadConfig: configx.NewDefaultUploadConfig(),
+		contentIndex: NewMemoryContentIndex(),
+	}
+	if err := u.addStorage(localStorageConfig(dir)); err != nil {
+		t.Fatal(err)
+	}
+	return u, dir
+}
+
+// localStorageConfig 本地存储配置
+func localStorageConfig(dir string) configx.StorageConfig {
+	return configx.StorageConfig{Type: Local, Bucket: dir, SecretKey: "test"}
+}
+
+// uploadBytes 以 image/png 类型上传内容
+func uploadBytes(u *UploadManager, userId int64, name string, data []byte, opts ...UploadOption) (*UploadResult, error) {
+	header := &multipart.FileHeader{
+		Filename: name,
+		Size:     int64(len(data)),
+		Header:   textproto.MIMEHeader{"Content-Type": {"image/png"}},
+	}
+	return u.Upload(context.Background(), Local, bytes.NewReader(data), header, userId, opts...)
+}
+
+func TestConcurrentSettings(t *testing.T) {
+	ctx := context.Background()
+	u, _ := newTestManager(t)
+	backup, err := newLocalStorage(localStorageConfig(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.storages["backup"] = backup
+
+	// 上传和删除过程中修改配置，复制任务运行时替换主备策略不会死锁
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				result, err := uploadBytes(u, int64(i), fmt.Sprintf("%d-%d.png", i, j), testPNG)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if err := u.Delete(ctx, Local, result.RelativePath); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := range 10 {
+		if err := u.SetFailover(&FailoverPolicy{Primary: Local, Secondary: "backup"}); err != nil {
+			t.Fatal(err)
+		}
+		u.SetRetryPolicy(&RetryPolicy{MaxRetries: i})
+		u.SetTrash(&TrashPolicy{})
+		u.SetQuotaTracker(NewQuotaTracker(nil, QuotaLimit{}, WithQuotaStore(NewMemoryQuotaStore())))
+		u.SetDedupIndex(NewMemoryDedupIndex())
+		u.SetTrash(nil)
+		u.SetDedupIndex(nil)
+	}
+	wg.Wait()
+	if err := u.SetFailover(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContentAddressableUpload(t *testing.T) {
+	u, _ := newTestManager(t)
+	// 默认的路径生成器包含日期和用户，内容寻址模式下不使用
+	u.uploadConfig.ContentAddressable = true
+
+	sum := sha256.Sum256(testPNG)
+	hash := hex.EncodeToString(sum[:])
+	want := "uploads/" + hash[:2] + "/" + hash[2:4] + "/" + hash + ".png"
+	for _, uid := range []int64{7, 8} {
+		result, err := uploadBytes(u, uid, "A.PNG", testPNG)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimPrefix(result.RelativePath, "/") != want || result.ContentHash != hash {
+			t.Fatalf("user %d path = %s, hash = %s, want %s", uid, result.RelativePath, result.ContentHash, want)
+		}
+	}
+}
@@ -0,0 +1,517 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuantumShiftX/golib/xerr"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 配额键前缀
+	defaultQuotaPrefix = "ossx:quota:"
+	// 全局配额主体
+	globalQuotaKey = "global"
+	// 默认对账间隔
+	defaultReconcileInterval = time.Hour
+)
+
+var (
+	// ErrQuotaBytesExceeded 超出存储字节配额
+	ErrQuotaBytesExceeded = errors.New("upload quota exceeded: bytes")
+	// ErrQuotaObjectsExceeded 超出对象数配额
+	ErrQuotaObjectsExceeded = errors.New("upload quota exceeded: objects")
+	// ErrGlobalQuotaBytesExceeded 超出全局存储字节配额
+	ErrGlobalQuotaBytesExceeded = errors.New("global upload quota exceeded: bytes")
+	// ErrGlobalQuotaObjectsExceeded 超出全局对象数配额
+	ErrGlobalQuotaObjectsExceeded = errors.New("global upload quota exceeded: objects")
+)
+
+// QuotaLimit 配额上限，0 表示不限制
+type QuotaLimit struct {
+	// 最大存储字节数
+	MaxBytes int64 `json:"max_bytes"`
+	// 最大对象数
+	MaxObjects int64 `json:"max_objects"`
+}
+
+// QuotaUsage 配额使用量
+type QuotaUsage struct {
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// QuotaInfo 配额上限和使用量
+type QuotaInfo struct {
+	Limit QuotaLimit `json:"limit"`
+	Usage QuotaUsage `json:"usage"`
+}
+
+// RemainingBytes 剩余可用字节数，不限制时返回 -1
+func (q QuotaInfo) RemainingBytes() int64 {
+	if q.Limit.MaxBytes <= 0 {
+		return -1
+	}
+	return max(q.Limit.MaxBytes-q.Usage.Bytes, 0)
+}
+
+// RemainingObjects 剩余可用对象数，不限制时返回 -1
+func (q QuotaInfo) RemainingObjects() int64 {
+	if q.Limit.MaxObjects <= 0 {
+		return -1
+	}
+	return max(q.Limit.MaxObjects-q.Usage.Objects, 0)
+}
+
+// UsageFunc 统计用户在存储中的实际使用量，用于对账
+type UsageFunc func(ctx context.Context, userId int64) (QuotaUsage, error)
+
+// QuotaTracker 用户和全局上传配额，上传前原子占用，失败或通过 UploadManager 删除对象时归还，
+// 直接在存储中删除等原因产生的偏差通过定期对账修正
+type QuotaTracker struct {
+	store     QuotaStore
+	prefix    string
+	limit     QuotaLimit
+	global    QuotaLimit
+	limitFunc func(ctx context.Context, userId int64) QuotaLimit
+	usageFunc UsageFunc
+	interval  time.Duration
+
+	// 全局使用量是否已初始化
+	seedMu sync.Mutex
+	seeded bool
+
+	startOnce sync.Once
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// QuotaOption 配额选项
+type QuotaOption func(q *QuotaTracker)
+
+// WithQuotaPrefix 设置配额键前缀
+func WithQuotaPrefix(prefix string) QuotaOption {
+	return func(q *QuotaTracker) {
+		q.prefix = prefix
+	}
+}
+
+// WithQuotaStore 设置配额计数存储，未设置时使用 rdb 创建Redis存储
+func WithQuotaStore(store QuotaStore) QuotaOption {
+	return func(q *QuotaTracker) {
+		q.store = store
+	}
+}
+
+// WithGlobalQuota 设置所有用户合计的配额上限，默认不限制
+func WithGlobalQuota(limit QuotaLimit) QuotaOption {
+	return func(q *QuotaTracker) {
+		q.global = limit
+	}
+}
+
+// WithQuotaLimitFunc 按用户设置配额上限（如按会员等级），未设置时使用默认上限
+func WithQuotaLimitFunc(fn func(ctx context.Context, userId int64) QuotaLimit) QuotaOption {
+	return func(q *QuotaTracker) {
+		q.limitFunc = fn
+	}
+}
+
+// WithQuotaReconcile 开启定期对账，interval <=0 时为1小时
+func WithQuotaReconcile(fn UsageFunc, interval time.Duration) QuotaOption {
+	return func(q *QuotaTracker) {
+		q.usageFunc = fn
+		if interval > 0 {
+			q.interval = interval
+		}
+	}
+}
+
+// NewQuotaTracker 创建配额跟踪器，limit 为每个用户的默认上限
+// 未通过 WithQuotaStore 指定存储时使用 rdb，两者都为空时 panic，单实例可显式使用 NewMemoryQuotaStore；
+// 开启对账时需调用 Start
+func NewQuotaTracker(rdb redis.UniversalClient, limit QuotaLimit, opts ...QuotaOption) *QuotaTracker {
+	q := &QuotaTracker{
+		prefix:   defaultQuotaPrefix,
+		limit:    limit,
+		interval: defaultReconcileInterval,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if q.store == nil {
+		if rdb == nil {
+			panic("ossx: NewQuotaTracker requires a redis client or WithQuotaStore")
+		}
+		q.store = NewRedisQuotaStore(rdb, q.prefix)
+	}
+	return q
+}
+
+// Limit 用户的配额上限，优先使用 SetUserLimit 单独设置的上限，其次为 WithQuotaLimitFunc 和默认上限
+func (q *QuotaTracker) Limit(ctx context.Context, userId int64) QuotaLimit {
+	limit, ok, err := q.store.Limit(ctx, userKey(userId))
+	if err != nil {
+		logx.WithContext(ctx).Errorf("ossx quota: get limit for user %d failed: %v", userId, err)
+	}
+	if ok {
+		return limit
+	}
+	if q.limitFunc != nil {
+		return q.limitFunc(ctx, userId)
+	}
+	return q.limit
+}
+
+// GlobalLimit 全局配额上限，优先使用 SetGlobalLimit 设置的上限
+func (q *QuotaTracker) GlobalLimit(ctx context.Context) QuotaLimit {
+	limit, ok, err := q.store.Limit(ctx, globalQuotaKey)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("ossx quota: get global limit failed: %v", err)
+	}
+	if ok {
+		return limit
+	}
+	return q.global
+}
+
+// SetUserLimit 单独设置用户的配额上限，如临时扩容
+func (q *QuotaTracker) SetUserLimit(ctx context.Context, userId int64, limit QuotaLimit) error {
+	if err := q.store.SetLimit(ctx, userKey(userId), limit); err != nil {
+		return fmt.Errorf("set quota limit for user %d: %w", userId, err)
+	}
+	return nil
+}
+
+// ResetUserLimit 删除用户单独设置的配额上限，恢复默认上限
+func (q *QuotaTracker) ResetUserLimit(ctx context.Context, userId int64) error {
+	if err := q.store.DeleteLimit(ctx, userKey(userId)); err != nil {
+		return fmt.Errorf("reset quota limit for user %d: %w", userId, err)
+	}
+	return nil
+}
+
+// SetGlobalLimit 在线调整全局配额上限
+func (q *QuotaTracker) SetGlobalLimit(ctx context.Context, limit QuotaLimit) error {
+	if err := q.store.SetLimit(ctx, globalQuotaKey, limit); err != nil {
+		return fmt.Errorf("set global quota limit: %w", err)
+	}
+	return nil
+}
+
+// Reserve 原子地占用用户和全局的 size 字节和一个对象的配额，任一项不足时都不占用，
+// 超出时返回 xerr.QuotaExceededError 错误，可用 errors.Is 判断超出的是哪一项
+func (q *QuotaTracker) Reserve(ctx context.Context, userId, size int64) error {
+	if err := q.seedGlobal(ctx); err != nil {
+		return err
+	}
+
+	limit, global := q.Limit(ctx, userId), q.GlobalLimit(ctx)
+	key, err := q.store.Reserve(ctx, size, []QuotaSubject{
+		{Key: userKey(userId), Limit: limit},
+		{Key: globalQuotaKey, Limit: global},
+	})
+	if err == nil {
+		return nil
+	}
+	if key != globalQuotaKey {
+		return quotaError(err, fmt.Sprintf("user %d", userId), limit)
+	}
+	switch {
+	case errors.Is(err, ErrQuotaBytesExceeded):
+		err = ErrGlobalQuotaBytesExceeded
+	case errors.Is(err, ErrQuotaObjectsExceeded):
+		err = ErrGlobalQuotaObjectsExceeded
+	}
+	return quotaError(err, "global", global)
+}
+
+// Release 归还用户和全局 size 字节和一个对象的配额，用于上传失败或删除文件后
+func (q *QuotaTracker) Release(ctx context.Context, userId, size int64) error {
+	return q.AdjustUsage(ctx, userId, -size, -1)
+}
+
+// AdjustUsage 手动增减用户和全局的使用量，不校验上限，用于补偿或迁移
+func (q *QuotaTracker) AdjustUsage(ctx context.Context, userId, bytes, objects int64) error {
+	if err := q.seedGlobal(ctx); err != nil {
+		return err
+	}
+	if err := q.store.Add(ctx, userKey(userId), bytes, objects); err != nil {
+		return fmt.Errorf("adjust quota for user %d: %w", userId, err)
+	}
+	if err := q.store.Add(ctx, globalQuotaKey, bytes, objects); err != nil {
+		return fmt.Errorf("adjust global quota: %w", err)
+	}
+	return nil
+}
+
+// own 记录对象占用的用户配额，删除对象时由 releaseObject 归还
+func (q *QuotaTracker) own(ctx context.Context, object string, userId, size int64) {
+	if err := q.store.Own(ctx, object, userKey(userId), size); err != nil {
+		logx.WithContext(ctx).Errorf("ossx quota: record owner of %s failed: %v", object, err)
+	}
+}
+
+// releaseObject 归还对象占用的配额，没有占用记录（如旧版本上传或秒传）时不处理
+func (q *QuotaTracker) releaseObject(ctx context.Context, object string) error {
+	key, size, ok, err := q.store.Disown(ctx, object)
+	if err != nil || !ok {
+		return err
+	}
+	userId, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return q.Release(ctx, userId, size)
+}
+
+// trashObject 对象移入回收站时归还其占用的配额，占用记录转到回收站中的对象，恢复时据此重新占用
+func (q *QuotaTracker) trashObject(ctx context.Context, object, trashed string) error {
+	key, size, ok, err := q.store.Disown(ctx, object)
+	if err != nil || !ok {
+		return err
+	}
+	userId, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if err := q.store.Own(ctx, trashed, key, size); err != nil {
+		logx.WithContext(ctx).Errorf("ossx quota: record owner of %s failed: %v", trashed, err)
+	}
+	return q.Release(ctx, userId, size)
+}
+
+// reserveTrashed 恢复回收站中的对象前为其所有者重新占用 size 字节和一个对象的配额，
+// 没有占用记录时返回 false 不占用；超出配额时保留占用记录并返回配额错误
+func (q *QuotaTracker) reserveTrashed(ctx context.Context, trashed string, size int64) (int64, bool, error) {
+	key, _, ok, err := q.store.Disown(ctx, trashed)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	userId, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return 0, false, nil
+	}
+	if err := q.Reserve(ctx, userId, size); err != nil {
+		q.own(ctx, trashed, userId, size)
+		return 0, false, err
+	}
+	return userId, true, nil
+}
+
+// dropObject 删除回收站中对象的占用记录，回收站中的对象不占用配额
+func (q *QuotaTracker) dropObject(ctx context.Context, trashed string) error {
+	_, _, _, err := q.store.Disown(ctx, trashed)
+	return err
+}
+
+// seedGlobal 升级前只记录了用户使用量，全局使用量不存在时以所有用户合计初始化
+func (q *QuotaTracker) seedGlobal(ctx context.Context) error {
+	q.seedMu.Lock()
+	defer q.seedMu.Unlock()
+	if q.seeded {
+		return nil
+	}
+
+	if usage, err := q.store.Usage(ctx, globalQuotaKey); err != nil {
+		return fmt.Errorf("get global quota usage: %w", err)
+	} else if usage != (QuotaUsage{}) {
+		q.seeded = true
+		return nil
+	}
+
+	var total QuotaUsage
+	err := q.store.Keys(ctx, func(key string) error {
+		if _, err := strconv.ParseInt(key, 10, 64); err != nil {
+			return nil
+		}
+		usage, err := q.store.Usage(ctx, key)
+		if err != nil {
+			return err
+		}
+		total.Bytes += usage.Bytes
+		total.Objects += usage.Objects
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("sum user quota usage: %w", err)
+	}
+	if _, err := q.store.InitUsage(ctx, globalQuotaKey, total); err != nil {
+		return fmt.Errorf("init global quota usage: %w", err)
+	}
+	q.seeded = true
+	return nil
+}
+
+// Usage 用户当前的配额使用量
+func (q *QuotaTracker) Usage(ctx context.Context, userId int64) (QuotaUsage, error) {
+	usage, err := q.store.Usage(ctx, userKey(userId))
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("get quota usage for user %d: %w", userId, err)
+	}
+	return usage, nil
+}
+
+// Quota 用户的配额上限和使用量
+func (q *QuotaTracker) Quota(ctx context.Context, userId int64) (QuotaInfo, error) {
+	usage, err := q.Usage(ctx, userId)
+	if err != nil {
+		return QuotaInfo{}, err
+	}
+	return QuotaInfo{Limit: q.Limit(ctx, userId), Usage: usage}, nil
+}
+
+// GlobalQuota 全局配额上限和使用量
+func (q *QuotaTracker) GlobalQuota(ctx context.Context) (QuotaInfo, error) {
+	if err := q.seedGlobal(ctx); err != nil {
+		return QuotaInfo{}, err
+	}
+	usage, err := q.store.Usage(ctx, globalQuotaKey)
+	if err != nil {
+		return QuotaInfo{}, fmt.Errorf("get global quota usage: %w", err)
+	}
+	return QuotaInfo{Limit: q.GlobalLimit(ctx), Usage: usage}, nil
+}
+
+// Reconcile 用实际使用量覆盖用户的计数，未设置 UsageFunc 时不处理
+func (q *QuotaTracker) Reconcile(ctx context.Context, userId int64) error {
+	if q.usageFunc == nil {
+		return nil
+	}
+
+	usage, err := q.usageFunc(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("compute usage for user %d: %w", userId, err)
+	}
+	if err := q.store.SetUsage(ctx, userKey(userId), usage); err != nil {
+		return fmt.Errorf("reconcile quota for user %d: %w", userId, err)
+	}
+	return nil
+}
+
+// ReconcileAll 对所有已跟踪的用户对账，单个用户失败只记录日志，
+// 全部成功时以用户合计覆盖全局使用量
+func (q *QuotaTracker) ReconcileAll(ctx context.Context) error {
+	if q.usageFunc == nil {
+		return nil
+	}
+
+	var total QuotaUsage
+	complete := true
+	err := q.store.Keys(ctx, func(key string) error {
+		userId, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil
+		}
+		if err := q.Reconcile(ctx, userId); err != nil {
+			logx.WithContext(ctx).Errorf("ossx quota: %v", err)
+			complete = false
+			return nil
+		}
+		usage, err := q.store.Usage(ctx, key)
+		if err != nil {
+			complete = false
+			return nil
+		}
+		total.Bytes += usage.Bytes
+		total.Objects += usage.Objects
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan quota users: %w", err)
+	}
+
+	if complete {
+		if err := q.store.SetUsage(ctx, globalQuotaKey, total); err != nil {
+			return fmt.Errorf("reconcile global quota: %w", err)
+		}
+	}
+	return nil
+}
+
+// Start 启动定期对账，未设置 UsageFunc 或重复调用时无效
+func (q *QuotaTracker) Start() {
+	if q.usageFunc == nil {
+		return
+	}
+	q.startOnce.Do(func() {
+		go q.run()
+	})
+}
+
+// Close 停止定期对账
+func (q *QuotaTracker) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+	})
+}
+
+// run 定期对账
+func (q *QuotaTracker) run() {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+			if err := q.ReconcileAll(context.Background()); err != nil {
+				logx.Errorf("ossx quota: reconcile failed: %v", err)
+			}
+		}
+	}
+}
+
+// userKey 用户配额主体
+func userKey(userId int64) string {
+	return strconv.FormatInt(userId, 10)
+}
+
+// quotaObject 对象的配额占用记录标识
+func quotaObject(storageType, path string) string {
+	return storageType + ":" + strings.TrimPrefix(path, "/")
+}
+
+// quotaError 将存储返回的超额错误转为带上限说明的 xerr 错误
+func quotaError(err error, subject string, limit QuotaLimit) error {
+	switch {
+	case errors.Is(err, ErrQuotaBytesExceeded), errors.Is(err, ErrGlobalQuotaBytesExceeded):
+		return xerr.Wrap(xerr.QuotaExceededError, err, "%s storage limit %d bytes reached", subject, limit.MaxBytes)
+	case errors.Is(err, ErrQuotaObjectsExceeded), errors.Is(err, ErrGlobalQuotaObjectsExceeded):
+		return xerr.Wrap(xerr.QuotaExceededError, err, "%s object limit %d reached", subject, limit.MaxObjects)
+	}
+	return fmt.Errorf("reserve %s quota: %w", subject, err)
+}
+
+// parseQuotaValue 解析 HMGET 返回值，不存在时为0
+func parseQuotaValue(v any) int64 {
+	s, ok := v.(string)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// SetQuotaTracker 设置上传配额，为空时不限制
+func (u *UploadManager) SetQuotaTracker(q *QuotaTracker) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.quota = q
+}
+
+// QuotaTracker 返回上传配额，用于查询使用量或调整上限
+func (u *UploadManager) QuotaTracker() *QuotaTracker {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.quota
+}
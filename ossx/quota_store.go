@@ -0,0 +1,381 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QuotaSubject 配额主体和本次占用时的上限
+type QuotaSubject struct {
+	Key   string
+	Limit QuotaLimit
+}
+
+// QuotaStore 配额计数存储，key 为配额主体，用户为用户ID，全局配额为 global
+type QuotaStore interface {
+	// Reserve 原子地校验所有主体的上限并各占用 size 字节和一个对象，任一主体超出时都不占用，
+	// 返回超出的主体和 ErrQuotaBytesExceeded 或 ErrQuotaObjectsExceeded
+	Reserve(ctx context.Context, size int64, subjects []QuotaSubject) (string, error)
+	// Add 增减使用量，不校验上限
+	Add(ctx context.Context, key string, bytes, objects int64) error
+	// Usage 查询使用量，不存在时为0
+	Usage(ctx context.Context, key string) (QuotaUsage, error)
+	// SetUsage 覆盖使用量，用于对账
+	SetUsage(ctx context.Context, key string, usage QuotaUsage) error
+	// InitUsage 使用量不存在时写入，已存在时返回 false，用于升级后初始化全局使用量
+	InitUsage(ctx context.Context, key string, usage QuotaUsage) (bool, error)
+	// Own 记录对象占用的配额主体和大小，删除对象时据此归还
+	Own(ctx context.Context, object, key string, size int64) error
+	// Disown 删除并返回对象的占用记录，没有记录时返回 false
+	Disown(ctx context.Context, object string) (key string, size int64, ok bool, err error)
+	// Keys 遍历已记录使用量的配额主体，fn 返回错误时停止遍历
+	Keys(ctx context.Context, fn func(key string) error) error
+	// Limit 查询单独设置的上限，未设置时返回 false
+	Limit(ctx context.Context, key string) (QuotaLimit, bool, error)
+	// SetLimit 单独设置上限
+	SetLimit(ctx context.Context, key string, limit QuotaLimit) error
+	// DeleteLimit 删除单独设置的上限，恢复默认上限
+	DeleteLimit(ctx context.Context, key string) error
+}
+
+// 对象的配额占用记录
+type quotaOwner struct {
+	key  string
+	size int64
+}
+
+// memoryQuotaStore 基于内存的配额存储
+type memoryQuotaStore struct {
+	mu     sync.RWMutex
+	usage  map[string]QuotaUsage
+	limits map[string]QuotaLimit
+	owners map[string]quotaOwner
+}
+
+// NewMemoryQuotaStore 创建内存配额存储，仅适用于单实例
+func NewMemoryQuotaStore() QuotaStore {
+	return &memoryQuotaStore{
+		usage:  make(map[string]QuotaUsage),
+		limits: make(map[string]QuotaLimit),
+		owners: make(map[string]quotaOwner),
+	}
+}
+
+// Reserve 校验所有主体的上限并占用配额
+func (m *memoryQuotaStore) Reserve(ctx context.Context, size int64, subjects []QuotaSubject) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range subjects {
+		usage := m.usage[s.Key]
+		if s.Limit.MaxBytes > 0 && usage.Bytes+size > s.Limit.MaxBytes {
+			return s.Key, ErrQuotaBytesExceeded
+		}
+		if s.Limit.MaxObjects > 0 && usage.Objects+1 > s.Limit.MaxObjects {
+			return s.Key, ErrQuotaObjectsExceeded
+		}
+	}
+	for _, s := range subjects {
+		usage := m.usage[s.Key]
+		m.usage[s.Key] = QuotaUsage{Bytes: usage.Bytes + size, Objects: usage.Objects + 1}
+	}
+	return "", nil
+}
+
+// Add 增减使用量
+func (m *memoryQuotaStore) Add(ctx context.Context, key string, bytes, objects int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := m.usage[key]
+	m.usage[key] = QuotaUsage{Bytes: usage.Bytes + bytes, Objects: usage.Objects + objects}
+	return nil
+}
+
+// Usage 查询使用量
+func (m *memoryQuotaStore) Usage(ctx context.Context, key string) (QuotaUsage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.usage[key], nil
+}
+
+// SetUsage 覆盖使用量
+func (m *memoryQuotaStore) SetUsage(ctx context.Context, key string, usage QuotaUsage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage[key] = usage
+	return nil
+}
+
+// InitUsage 使用量不存在时写入
+func (m *memoryQuotaStore) InitUsage(ctx context.Context, key string, usage QuotaUsage) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.usage[key]; ok {
+		return false, nil
+	}
+	m.usage[key] = usage
+	return true, nil
+}
+
+// Own 记录对象的配额占用
+func (m *memoryQuotaStore) Own(ctx context.Context, object, key string, size int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.owners[object] = quotaOwner{key: key, size: size}
+	return nil
+}
+
+// Disown 删除并返回对象的配额占用
+func (m *memoryQuotaStore) Disown(ctx context.Context, object string) (string, int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	owner, ok := m.owners[object]
+	delete(m.owners, object)
+	return owner.key, owner.size, ok, nil
+}
+
+// Keys 遍历已记录使用量的配额主体
+func (m *memoryQuotaStore) Keys(ctx context.Context, fn func(key string) error) error {
+	m.mu.RLock()
+	keys := make([]string, 0, len(m.usage))
+	for key := range m.usage {
+		keys = append(keys, key)
+	}
+	m.mu.RUnlock()
+
+	for _, key := range keys {
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Limit 查询单独设置的上限
+func (m *memoryQuotaStore) Limit(ctx context.Context, key string) (QuotaLimit, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	limit, ok := m.limits[key]
+	return limit, ok, nil
+}
+
+// SetLimit 单独设置上限
+func (m *memoryQuotaStore) SetLimit(ctx context.Context, key string, limit QuotaLimit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits[key] = limit
+	return nil
+}
+
+// DeleteLimit 删除单独设置的上限
+func (m *memoryQuotaStore) DeleteLimit(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.limits, key)
+	return nil
+}
+
+// 原子地校验所有主体的上限并占用配额，KEYS 为主体集合和各主体的使用量，
+// ARGV 为 size 和每个主体的 主体、字节上限、对象数上限；
+// 返回 {0} 成功，{1, i} 第i个主体超出字节配额，{2, i} 第i个主体超出对象数配额
+var reserveQuotaScript = redis.NewScript(`
+local size = tonumber(ARGV[1])
+for i = 2, #KEYS do
+	local base = (i - 2) * 3 + 1
+	local maxBytes = tonumber(ARGV[base + 2])
+	local maxObjects = tonumber(ARGV[base + 3])
+	local bytes = tonumber(redis.call('HGET', KEYS[i], 'bytes') or '0')
+	local objects = tonumber(redis.call('HGET', KEYS[i], 'objects') or '0')
+	if maxBytes > 0 and bytes + size > maxBytes then
+		return {1, i - 1}
+	end
+	if maxObjects > 0 and objects + 1 > maxObjects then
+		return {2, i - 1}
+	end
+end
+for i = 2, #KEYS do
+	redis.call('HINCRBY', KEYS[i], 'bytes', size)
+	redis.call('HINCRBY', KEYS[i], 'objects', 1)
+	redis.call('SADD', KEYS[1], ARGV[(i - 2) * 3 + 2])
+end
+return {0}
+`)
+
+// 使用量不存在时写入，返回 1 已写入，0 已存在
+var initQuotaUsageScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'bytes', ARGV[1], 'objects', ARGV[2])
+return 1
+`)
+
+// redisQuotaStore 基于Redis的配额存储
+// 使用量为哈希 prefix+key（bytes、objects），上限为哈希 prefix+"limit:"+key，
+// 已记录的配额主体为集合 prefix+"users"，对象的占用记录为字符串 prefix+"object:"+对象
+type redisQuotaStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisQuotaStore 创建Redis配额存储，prefix 为空时使用 ossx:quota:
+// 用户和全局配额在同一个Lua脚本中占用，Redis Cluster 下 prefix 需包含哈希标签，如 {ossx:quota}:
+func NewRedisQuotaStore(rdb redis.UniversalClient, prefix string) QuotaStore {
+	if prefix == "" {
+		prefix = defaultQuotaPrefix
+	}
+	return &redisQuotaStore{rdb: rdb, prefix: prefix}
+}
+
+// Reserve 通过Lua脚本原子地校验所有主体的上限并占用配额
+func (r *redisQuotaStore) Reserve(ctx context.Context, size int64, subjects []QuotaSubject) (string, error) {
+	keys := make([]string, 0, len(subjects)+1)
+	args := make([]any, 0, len(subjects)*3+1)
+	keys = append(keys, r.keysKey())
+	args = append(args, size)
+	for _, s := range subjects {
+		keys = append(keys, r.prefix+s.Key)
+		args = append(args, s.Key, s.Limit.MaxBytes, s.Limit.MaxObjects)
+	}
+
+	res, err := reserveQuotaScript.Run(ctx, r.rdb, keys, args...).Int64Slice()
+	if err != nil {
+		return "", fmt.Errorf("failed to reserve quota: %w", err)
+	}
+	if len(res) < 2 || res[0] == 0 {
+		return "", nil
+	}
+	key := subjects[res[1]-1].Key
+	if res[0] == 1 {
+		return key, ErrQuotaBytesExceeded
+	}
+	return key, ErrQuotaObjectsExceeded
+}
+
+// Add 增减使用量
+func (r *redisQuotaStore) Add(ctx context.Context, key string, bytes, objects int64) error {
+	pipe := r.rdb.Pipeline()
+	pipe.HIncrBy(ctx, r.prefix+key, "bytes", bytes)
+	pipe.HIncrBy(ctx, r.prefix+key, "objects", objects)
+	pipe.SAdd(ctx, r.keysKey(), key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update quota usage: %w", err)
+	}
+	return nil
+}
+
+// Usage 查询使用量
+func (r *redisQuotaStore) Usage(ctx context.Context, key string) (QuotaUsage, error) {
+	vals, err := r.rdb.HMGet(ctx, r.prefix+key, "bytes", "objects").Result()
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("failed to get quota usage: %w", err)
+	}
+	return QuotaUsage{Bytes: parseQuotaValue(vals[0]), Objects: parseQuotaValue(vals[1])}, nil
+}
+
+// SetUsage 覆盖使用量
+func (r *redisQuotaStore) SetUsage(ctx context.Context, key string, usage QuotaUsage) error {
+	pipe := r.rdb.Pipeline()
+	pipe.HSet(ctx, r.prefix+key, "bytes", usage.Bytes, "objects", usage.Objects)
+	pipe.SAdd(ctx, r.keysKey(), key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set quota usage: %w", err)
+	}
+	return nil
+}
+
+// InitUsage 使用量不存在时写入
+func (r *redisQuotaStore) InitUsage(ctx context.Context, key string, usage QuotaUsage) (bool, error) {
+	n, err := initQuotaUsageScript.Run(ctx, r.rdb, []string{r.prefix + key}, usage.Bytes, usage.Objects).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to init quota usage: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Own 记录对象的配额占用，值为 主体 大小
+func (r *redisQuotaStore) Own(ctx context.Context, object, key string, size int64) error {
+	if err := r.rdb.Set(ctx, r.objectKey(object), key+" "+strconv.FormatInt(size, 10), 0).Err(); err != nil {
+		return fmt.Errorf("failed to record quota owner: %w", err)
+	}
+	return nil
+}
+
+// Disown 删除并返回对象的配额占用
+func (r *redisQuotaStore) Disown(ctx context.Context, object string) (string, int64, bool, error) {
+	val, err := r.rdb.GetDel(ctx, r.objectKey(object)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, fmt.Errorf("failed to remove quota owner: %w", err)
+	}
+	key, sizeStr, _ := strings.Cut(val, " ")
+	size, _ := strconv.ParseInt(sizeStr, 10, 64)
+	return key, size, true, nil
+}
+
+// Keys 遍历已记录使用量的配额主体
+func (r *redisQuotaStore) Keys(ctx context.Context, fn func(key string) error) error {
+	iter := r.rdb.SScan(ctx, r.keysKey(), 0, "", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := fn(iter.Val()); err != nil {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan quota keys: %w", err)
+	}
+	return nil
+}
+
+// Limit 查询单独设置的上限
+func (r *redisQuotaStore) Limit(ctx context.Context, key string) (QuotaLimit, bool, error) {
+	vals, err := r.rdb.HMGet(ctx, r.limitKey(key), "max_bytes", "max_objects").Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return QuotaLimit{}, false, fmt.Errorf("failed to get quota limit: %w", err)
+	}
+	if len(vals) != 2 || (vals[0] == nil && vals[1] == nil) {
+		return QuotaLimit{}, false, nil
+	}
+	return QuotaLimit{MaxBytes: parseQuotaValue(vals[0]), MaxObjects: parseQuotaValue(vals[1])}, true, nil
+}
+
+// SetLimit 单独设置上限
+func (r *redisQuotaStore) SetLimit(ctx context.Context, key string, limit QuotaLimit) error {
+	err := r.rdb.HSet(ctx, r.limitKey(key), "max_bytes", limit.MaxBytes, "max_objects", limit.MaxObjects).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set quota limit: %w", err)
+	}
+	return nil
+}
+
+// DeleteLimit 删除单独设置的上限
+func (r *redisQuotaStore) DeleteLimit(ctx context.Context, key string) error {
+	if err := r.rdb.Del(ctx, r.limitKey(key)).Err(); err != nil {
+		return fmt.Errorf("failed to delete quota limit: %w", err)
+	}
+	return nil
+}
+
+// limitKey 上限键
+func (r *redisQuotaStore) limitKey(key string) string {
+	return r.prefix + "limit:" + key
+}
+
+// objectKey 对象占用记录键
+func (r *redisQuotaStore) objectKey(object string) string {
+	return r.prefix + "object:" + object
+}
+
+// keysKey 已记录配额主体集合键，沿用旧版的用户集合
+func (r *redisQuotaStore) keysKey() string {
+	return r.prefix + "users"
+}
@@ -0,0 +1,166 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	stores := map[string]func() QuotaStore{
+		"memory": NewMemoryQuotaStore,
+		"redis": func() QuotaStore {
+			mr.FlushAll()
+			return NewRedisQuotaStore(rdb, "")
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore()
+
+			// 升级前只有用户使用量，全局使用量按用户合计初始化
+			if err := store.SetUsage(ctx, "1", QuotaUsage{Bytes: 30, Objects: 3}); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Add(ctx, "2", 20, 2); err != nil {
+				t.Fatal(err)
+			}
+			q := NewQuotaTracker(nil, QuotaLimit{MaxBytes: 100}, WithQuotaStore(store),
+				WithGlobalQuota(QuotaLimit{MaxBytes: 60}))
+			if info, err := q.GlobalQuota(ctx); err != nil || info.Usage != (QuotaUsage{Bytes: 50, Objects: 5}) {
+				t.Fatalf("seeded global = %+v, %v", info, err)
+			}
+
+			// 全局配额不足时不占用用户配额
+			if err := q.Reserve(ctx, 1, 20); !errors.Is(err, ErrGlobalQuotaBytesExceeded) {
+				t.Fatalf("Reserve err = %v", err)
+			}
+			if usage, _ := q.Usage(ctx, 1); usage.Bytes != 30 {
+				t.Fatalf("user usage after rejected reserve = %+v", usage)
+			}
+
+			if err := q.Reserve(ctx, 1, 10); err != nil {
+				t.Fatal(err)
+			}
+			q.own(ctx, quotaObject(Local, "/a.png"), 1, 10)
+			if err := q.releaseObject(ctx, quotaObject(Local, "a.png")); err != nil {
+				t.Fatal(err)
+			}
+			if err := q.releaseObject(ctx, quotaObject(Local, "a.png")); err != nil {
+				t.Fatal(err)
+			}
+			if usage, _ := q.Usage(ctx, 1); usage != (QuotaUsage{Bytes: 30, Objects: 3}) {
+				t.Fatalf("user usage after release = %+v", usage)
+			}
+			if info, _ := q.GlobalQuota(ctx); info.Usage != (QuotaUsage{Bytes: 50, Objects: 5}) {
+				t.Fatalf("global usage after release = %+v", info.Usage)
+			}
+		})
+	}
+}
+
+func TestUploadDeleteReleasesQuota(t *testing.T) {
+	ctx := context.Background()
+	u, _ := newTestManager(t)
+	quota := NewQuotaTracker(nil, QuotaLimit{MaxObjects: 1}, WithQuotaStore(NewMemoryQuotaStore()))
+	u.SetQuotaTracker(quota)
+
+	result, err := uploadBytes(u, 7, "a.png", testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = uploadBytes(u, 7, "b.png", testPNG); !errors.Is(err, ErrQuotaObjectsExceeded) {
+		t.Fatalf("second upload err = %v", err)
+	}
+	if err = u.Delete(ctx, Local, result.RelativePath); err != nil {
+		t.Fatal(err)
+	}
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{}) {
+		t.Fatalf("usage after delete = %+v", usage)
+	}
+	if _, err = uploadBytes(u, 7, "b.png", testPNG); err != nil {
+		t.Fatalf("upload after delete: %v", err)
+	}
+}
+
+func TestRestoreReservesQuota(t *testing.T) {
+	ctx := context.Background()
+	u, _ := newTestManager(t)
+	u.SetTrash(&TrashPolicy{})
+	quota := NewQuotaTracker(nil, QuotaLimit{MaxObjects: 1}, WithQuotaStore(NewMemoryQuotaStore()))
+	u.SetQuotaTracker(quota)
+	size := int64(len(testPNG))
+
+	first, err := uploadBytes(u, 7, "a.png", testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = u.Delete(ctx, Local, first.RelativePath); err != nil {
+		t.Fatal(err)
+	}
+	// 回收站中的对象不占用配额
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{}) {
+		t.Fatalf("usage after delete = %+v", usage)
+	}
+	second, err := uploadBytes(u, 7, "b.png", testPNG)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 超出配额时不恢复，对象保留在回收站
+	if err = u.Restore(ctx, Local, first.RelativePath); !errors.Is(err, ErrQuotaObjectsExceeded) {
+		t.Fatalf("Restore over quota err = %v", err)
+	}
+	if entries, _ := u.ListTrash(ctx, Local, ""); len(entries) != 1 {
+		t.Fatalf("trash after rejected restore = %+v", entries)
+	}
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{Bytes: size, Objects: 1}) {
+		t.Fatalf("usage after rejected restore = %+v", usage)
+	}
+
+	if err = u.Delete(ctx, Local, second.RelativePath); err != nil {
+		t.Fatal(err)
+	}
+	if err = u.Restore(ctx, Local, first.RelativePath); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{Bytes: size, Objects: 1}) {
+		t.Fatalf("usage after restore = %+v", usage)
+	}
+	if info, _ := quota.GlobalQuota(ctx); info.Usage != (QuotaUsage{Bytes: size, Objects: 1}) {
+		t.Fatalf("global usage after restore = %+v", info.Usage)
+	}
+
+	// 恢复的对象删除时再次归还配额
+	if err = u.Delete(ctx, Local, first.RelativePath); err != nil {
+		t.Fatal(err)
+	}
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{}) {
+		t.Fatalf("usage after second delete = %+v", usage)
+	}
+	// 回收站中的对象永久删除时不重复归还
+	if n, err := u.PurgeTrash(ctx, Local, time.Nanosecond); err != nil || n != 2 {
+		t.Fatalf("PurgeTrash = %d, %v", n, err)
+	}
+	if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{}) {
+		t.Fatalf("usage after purge = %+v", usage)
+	}
+}
+
+func TestNewQuotaTrackerRequiresStore(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic without store")
+		}
+	}()
+	NewQuotaTracker(nil, QuotaLimit{})
+}
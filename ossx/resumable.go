@@ -0,0 +1,553 @@
+package ossx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认分片大小
+	defaultPartSize int64 = 8 * 1024 * 1024
+	// 最小分片大小（S3、OSS 除最后一片外要求不小于5MB）
+	minPartSize int64 = 5 * 1024 * 1024
+	// Redis 断点默认过期时间，与云存储清理未完成分片上传的常见周期一致
+	defaultCheckpointTTL = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrCheckpointNotFound 断点不存在
+	ErrCheckpointNotFound = errors.New("upload checkpoint not found")
+	// ErrCheckpointMismatch 断点与本次上传的对象不一致
+	ErrCheckpointMismatch = errors.New("upload checkpoint mismatch")
+
+	// errNoCheckpointStore 未设置断点存储
+	errNoCheckpointStore = errors.New("checkpoint store not configured")
+)
+
+// MultipartUploader 支持分片上传的存储
+type MultipartUploader interface {
+	// InitMultipart 初始化分片上传，返回 uploadId
+	InitMultipart(ctx context.Context, path, contentType string) (string, error)
+	// UploadPart 上传单个分片，返回 ETag
+	UploadPart(ctx context.Context, path, uploadID string, partNumber int32, body io.ReadSeeker, size int64) (string, error)
+	// CompleteMultipart 合并分片，返回文件URL
+	CompleteMultipart(ctx context.Context, path, uploadID string, parts []CompletedPart) (string, error)
+	// AbortMultipart 取消分片上传
+	AbortMultipart(ctx context.Context, path, uploadID string) error
+}
+
+// CompletedPart 已上传的分片
+type CompletedPart struct {
+	Number int32  `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
+
+// UploadCheckpoint 分片上传断点
+type UploadCheckpoint struct {
+	// 断点ID，由调用方指定，如 用户ID+文件指纹
+	ID          string          `json:"id"`
+	StorageType string          `json:"storage_type"`
+	Path        string          `json:"path"`
+	ContentType string          `json:"content_type"`
+	ACL         configx.ACL     `json:"acl,omitempty"`
+	UserID      int64           `json:"user_id,omitempty"`
+	FileName    string          `json:"file_name,omitempty"`
+	UploadID    string          `json:"upload_id"`
+	Size        int64           `json:"size"`
+	PartSize    int64           `json:"part_size"`
+	Parts       []CompletedPart `json:"parts"`
+	// 合并后的URL，已合并但尚未设置访问权限时有值
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Uploaded 已上传的字节数
+func (c *UploadCheckpoint) Uploaded() int64 {
+	var n int64
+	for _, p := range c.Parts {
+		n += p.Size
+	}
+	return n
+}
+
+// CheckpointStore 断点存储
+type CheckpointStore interface {
+	Save(ctx context.Context, cp *UploadCheckpoint) error
+	// Load 加载断点，不存在时返回 ErrCheckpointNotFound
+	Load(ctx context.Context, id string) (*UploadCheckpoint, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// fileCheckpointStore 基于本地目录的断点存储，适用于挂载持久卷的场景
+type fileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore 创建基于本地目录的断点存储
+func NewFileCheckpointStore(dir string) (CheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint dir: %w", err)
+	}
+	return &fileCheckpointStore{dir: dir}, nil
+}
+
+// Save 原子写入断点文件
+func (s *fileCheckpointStore) Save(ctx context.Context, cp *UploadCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload checkpoint: %w", err)
+	}
+
+	file := s.file(cp.ID)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write upload checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return fmt.Errorf("failed to write upload checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load 加载断点
+func (s *fileCheckpointStore) Load(ctx context.Context, id string) (*UploadCheckpoint, error) {
+	data, err := os.ReadFile(s.file(id))
+	if os.IsNotExist(err) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload checkpoint: %w", err)
+	}
+
+	var cp UploadCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to decode upload checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// Delete 删除断点
+func (s *fileCheckpointStore) Delete(ctx context.Context, id string) error {
+	if err := os.Remove(s.file(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete upload checkpoint: %w", err)
+	}
+	return nil
+}
+
+// file 断点文件路径，ID 经过哈希避免路径穿越
+func (s *fileCheckpointStore) file(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// redisCheckpointStore 基于Redis的断点存储，适用于多副本部署
+type redisCheckpointStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCheckpointStore 创建基于Redis的断点存储，ttl <=0 时为7天
+func NewRedisCheckpointStore(rdb redis.UniversalClient, prefix string, ttl time.Duration) CheckpointStore {
+	if prefix == "" {
+		prefix = "ossx:checkpoint:"
+	}
+	if ttl <= 0 {
+		ttl = defaultCheckpointTTL
+	}
+	return &redisCheckpointStore{rdb: rdb, prefix: prefix, ttl: ttl}
+}
+
+// Save 保存断点
+func (s *redisCheckpointStore) Save(ctx context.Context, cp *UploadCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload checkpoint: %w", err)
+	}
+	if err := s.rdb.Set(ctx, s.prefix+cp.ID, data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save upload checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load 加载断点
+func (s *redisCheckpointStore) Load(ctx context.Context, id string) (*UploadCheckpoint, error) {
+	data, err := s.rdb.Get(ctx, s.prefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upload checkpoint: %w", err)
+	}
+
+	var cp UploadCheckpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to decode upload checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+// Delete 删除断点
+func (s *redisCheckpointStore) Delete(ctx context.Context, id string) error {
+	if err := s.rdb.Del(ctx, s.prefix+id).Err(); err != nil {
+		return fmt.Errorf("failed to delete upload checkpoint: %w", err)
+	}
+	return nil
+}
+
+// SetCheckpointStore 设置分片上传的断点存储
+func (u *UploadManager) SetCheckpointStore(store CheckpointStore) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.checkpoints = store
+}
+
+// checkpointStore 当前的断点存储
+func (u *UploadManager) checkpointStore() CheckpointStore {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.checkpoints
+}
+
+// UploadResumable 可断点续传的分片上传，见 UploadResumableWithUid，上传用户为0
+func (u *UploadManager) UploadResumable(ctx context.Context, storageType, id string, file io.ReaderAt, size int64, path, contentType string, partSize int64) (*UploadResult, error) {
+	return u.UploadResumableWithUid(ctx, storageType, id, file, size, path, contentType, partSize, 0)
+}
+
+// UploadResumableWithUid 可断点续传的分片上传，每完成一个分片保存断点，
+// 与 Upload 相同地校验文件、执行钩子、占用配额和记录指标，上传前钩子不能替换分片上传的内容
+// id 相同且断点中的存储、路径、大小和用户一致时从断点继续，否则重新开始；partSize <=0 时为8MB
+func (u *UploadManager) UploadResumableWithUid(ctx context.Context, storageType, id string, file io.ReaderAt, size int64, path, contentType string, partSize int64, userId int64) (*UploadResult, error) {
+	store := u.checkpointStore()
+	if store == nil {
+		return nil, errNoCheckpointStore
+	}
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil, errors.New("upload path is required")
+	}
+
+	cp, err := store.Load(ctx, id)
+	switch {
+	case errors.Is(err, ErrCheckpointNotFound):
+		cp = nil
+	case err != nil:
+		return nil, err
+	case cp.StorageType != storageType || cp.Path != path || cp.Size != size || cp.UserID != userId:
+		logx.WithContext(ctx).Infof("upload checkpoint %s does not match, restarting", id)
+		u.abortCheckpoint(ctx, cp)
+		cp = nil
+	}
+
+	if cp == nil {
+		if partSize <= 0 {
+			partSize = defaultPartSize
+		}
+		cp = &UploadCheckpoint{
+			ID:          id,
+			StorageType: storageType,
+			Path:        path,
+			ContentType: contentType,
+			UserID:      userId,
+			FileName:    filepath.Base(path),
+			Size:        size,
+			PartSize:    max(partSize, minPartSize),
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	return u.uploadMultipart(ctx, cp, file)
+}
+
+// ResumeUpload 按断点继续上传，file 需与首次上传的内容一致
+func (u *UploadManager) ResumeUpload(ctx context.Context, id string, file io.ReaderAt) (*UploadResult, error) {
+	store := u.checkpointStore()
+	if store == nil {
+		return nil, errNoCheckpointStore
+	}
+
+	cp, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.uploadMultipart(ctx, cp, file)
+}
+
+// GetUploadCheckpoint 查询断点，用于展示上传进度
+func (u *UploadManager) GetUploadCheckpoint(ctx context.Context, id string) (*UploadCheckpoint, error) {
+	store := u.checkpointStore()
+	if store == nil {
+		return nil, ErrCheckpointNotFound
+	}
+	return store.Load(ctx, id)
+}
+
+// AbortUpload 取消分片上传并删除断点
+func (u *UploadManager) AbortUpload(ctx context.Context, id string) error {
+	cp, err := u.GetUploadCheckpoint(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.abortCheckpoint(ctx, cp)
+	return u.deleteCheckpoint(ctx, id)
+}
+
+// uploadMultipart 校验文件、执行钩子并占用配额后上传断点中未完成的分片，流程与 Upload 一致
+func (u *UploadManager) uploadMultipart(ctx context.Context, cp *UploadCheckpoint, file io.ReaderAt) (result *UploadResult, err error) {
+	if cp.FileName == "" {
+		cp.FileName = filepath.Base(cp.Path)
+	}
+	content := io.NewSectionReader(file, 0, cp.Size)
+	uc := &UploadContext{
+		StorageType: cp.StorageType,
+		UserID:      cp.UserID,
+		FileName:    cp.FileName,
+		ContentType: cp.ContentType,
+		Size:        cp.Size,
+		Reader:      content,
+		Path:        cp.Path,
+		Values:      make(map[string]any),
+	}
+	hooks := u.uploadHooks()
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			hooks.runOnError(ctx, uc, err)
+		}
+		recordUpload(cp.StorageType, start, result, err)
+	}()
+
+	storage, ok := u.storages[cp.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", cp.StorageType)
+	}
+	mu, err := u.multipartUploader(cp.StorageType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验文件，空文件无法完成分片上传
+	if n, ok := readerAtSize(file); ok && n != cp.Size {
+		return nil, fmt.Errorf("%w: size %d, checkpoint %d", ErrCheckpointMismatch, n, cp.Size)
+	}
+	if cp.Size <= 0 {
+		return nil, errors.New("file validation failed: multipart upload requires a non-empty file")
+	}
+	header := &multipart.FileHeader{
+		Filename: cp.FileName,
+		Size:     cp.Size,
+		Header:   textproto.MIMEHeader{"Content-Type": {cp.ContentType}},
+	}
+	if err := u.uploadConfig.ValidateFile(header); err != nil {
+		return nil, fmt.Errorf("file validation failed: %w", err)
+	}
+	if u.uploadConfig.SniffContent {
+		head := make([]byte, min(int64(configx.SniffLen), cp.Size))
+		if _, err := file.ReadAt(head, 0); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read file header: %w", err)
+		}
+		if uc.ContentType, err = u.uploadConfig.ValidateContent(cp.FileName, cp.ContentType, head); err != nil {
+			return nil, fmt.Errorf("file validation failed: %w", err)
+		}
+	}
+
+	// 执行上传前钩子，分片上传按偏移读取文件，不支持替换内容
+	if err := hooks.runBeforeUpload(ctx, uc); err != nil {
+		return nil, err
+	}
+	if uc.Reader != io.Reader(content) || uc.Size != cp.Size {
+		return nil, errors.New("before upload hooks cannot replace the content of a resumable upload")
+	}
+	if cp.UploadID == "" {
+		cp.ContentType = uc.ContentType
+	}
+	fileType := configx.DetectFileType(cp.ContentType)
+	if cp.ACL == "" {
+		cp.ACL = u.uploadConfig.ACLFor(fileType)
+	}
+
+	// 占用用户配额，上传失败时归还，断点续传时重新占用
+	quota := u.QuotaTracker()
+	if quota != nil {
+		if err := quota.Reserve(ctx, cp.UserID, cp.Size); err != nil {
+			return nil, err
+		}
+	}
+	releaseQuota := func() {
+		if quota != nil {
+			if qerr := quota.Release(ctx, cp.UserID, cp.Size); qerr != nil {
+				logx.WithContext(ctx).Errorf("failed to release quota: %v", qerr)
+			}
+		}
+	}
+
+	url, err := u.runMultipart(ctx, mu, cp, file)
+	if err != nil {
+		releaseQuota()
+		return nil, err
+	}
+
+	expiration := newUploadOptions(u.uploadConfig, fileType, nil).signedURLExpiration
+	signedURL, err := u.createSignedURL(ctx, cp.StorageType, storage, cp.Path, expiration)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("failed to generate signed URL: %v", err)
+		signedURL = url
+	}
+	result = &UploadResult{
+		URL:          url,
+		SignedURL:    signedURL,
+		RelativePath: "/" + cp.Path,
+		FileName:     filepath.Base(cp.Path),
+		OriginalName: cp.FileName,
+		FileType:     fileType,
+		Size:         cp.Size,
+		StorageType:  cp.StorageType,
+	}
+	if signedURL != url {
+		result.SignedURLExpire = time.Now().Add(expiration).Unix()
+	}
+
+	// 执行上传后钩子，失败时删除已上传的对象
+	if err := hooks.runAfterUpload(ctx, uc, result); err != nil {
+		if derr := u.deletePermanently(ctx, cp.StorageType, storage, cp.Path); derr != nil {
+			logx.WithContext(ctx).Errorf("failed to delete %s after hook failure: %v", cp.Path, derr)
+		}
+		releaseQuota()
+		return nil, err
+	}
+
+	if quota != nil {
+		quota.own(ctx, quotaObject(cp.StorageType, cp.Path), cp.UserID, cp.Size)
+	}
+	u.replicate(cp.StorageType, replicateJob{path: cp.Path, contentType: cp.ContentType, acl: cp.ACL})
+	return result, nil
+}
+
+// runMultipart 上传断点中未完成的分片并合并，设置访问权限后删除断点
+func (u *UploadManager) runMultipart(ctx context.Context, mu MultipartUploader, cp *UploadCheckpoint, file io.ReaderAt) (string, error) {
+	var err error
+	if cp.UploadID == "" {
+		if cp.UploadID, err = mu.InitMultipart(ctx, cp.Path, cp.ContentType); err != nil {
+			return "", err
+		}
+		if err := u.saveCheckpoint(ctx, cp); err != nil {
+			return "", err
+		}
+	}
+
+	// 已合并但设置访问权限失败的断点直接重新设置
+	if cp.URL == "" {
+		// 已完成的分片按顺序记录，从下一片继续
+		next := int32(len(cp.Parts)) + 1
+		for offset := int64(next-1) * cp.PartSize; offset < cp.Size; offset += cp.PartSize {
+			partSize := min(cp.PartSize, cp.Size-offset)
+			etag, err := mu.UploadPart(ctx, cp.Path, cp.UploadID, next, io.NewSectionReader(file, offset, partSize), partSize)
+			if err != nil {
+				return "", fmt.Errorf("failed to upload part %d: %w", next, err)
+			}
+
+			cp.Parts = append(cp.Parts, CompletedPart{Number: next, ETag: etag, Size: partSize})
+			if err := u.saveCheckpoint(ctx, cp); err != nil {
+				return "", err
+			}
+			next++
+		}
+
+		if cp.URL, err = mu.CompleteMultipart(ctx, cp.Path, cp.UploadID, cp.Parts); err != nil {
+			return "", err
+		}
+		if err := u.saveCheckpoint(ctx, cp); err != nil {
+			logx.WithContext(ctx).Errorf("failed to save upload checkpoint %s: %v", cp.ID, err)
+		}
+	}
+
+	// 分片上传无法统一在初始化时指定访问权限，合并后设置（未指定时为存储默认权限），成功后才删除断点以便重试
+	if as, ok := mu.(ACLStorage); ok {
+		if err := as.SetACL(ctx, cp.Path, cp.ACL); err != nil {
+			return "", fmt.Errorf("upload completed but %w", err)
+		}
+	}
+
+	if err := u.deleteCheckpoint(ctx, cp.ID); err != nil {
+		logx.WithContext(ctx).Errorf("failed to delete upload checkpoint %s: %v", cp.ID, err)
+	}
+	return cp.URL, nil
+}
+
+// readerAtSize 获取 bytes.Reader、io.SectionReader、*os.File 等的大小
+func readerAtSize(r io.ReaderAt) (int64, bool) {
+	switch f := r.(type) {
+	case interface{ Size() int64 }:
+		return f.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := f.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		return info.Size(), true
+	}
+	return 0, false
+}
+
+// saveCheckpoint 保存断点
+func (u *UploadManager) saveCheckpoint(ctx context.Context, cp *UploadCheckpoint) error {
+	store := u.checkpointStore()
+	if store == nil {
+		return errNoCheckpointStore
+	}
+	cp.UpdatedAt = time.Now()
+	return store.Save(ctx, cp)
+}
+
+// deleteCheckpoint 删除断点
+func (u *UploadManager) deleteCheckpoint(ctx context.Context, id string) error {
+	store := u.checkpointStore()
+	if store == nil {
+		return errNoCheckpointStore
+	}
+	return store.Delete(ctx, id)
+}
+
+// abortCheckpoint 取消断点对应的分片上传，失败只记录日志
+func (u *UploadManager) abortCheckpoint(ctx context.Context, cp *UploadCheckpoint) {
+	if cp.UploadID == "" {
+		return
+	}
+	mu, err := u.multipartUploader(cp.StorageType)
+	if err != nil {
+		return
+	}
+	if err := mu.AbortMultipart(ctx, cp.Path, cp.UploadID); err != nil {
+		logx.WithContext(ctx).Errorf("failed to abort multipart upload %s: %v", cp.UploadID, err)
+	}
+}
+
+// multipartUploader 获取支持分片上传的存储
+func (u *UploadManager) multipartUploader(storageType string) (MultipartUploader, error) {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	mu, ok := storage.(MultipartUploader)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support multipart upload", storageType)
+	}
+	return mu, nil
+}
@@ -0,0 +1,185 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+// multipartStorage 在本地存储上模拟分片上传，可注入分片和设置访问权限的失败
+type multipartStorage struct {
+	Storage
+	mu       sync.Mutex
+	parts    map[string][]byte
+	uploaded []int32
+	failPart int32
+	failACL  int
+}
+
+func (m *multipartStorage) InitMultipart(ctx context.Context, path, contentType string) (string, error) {
+	return "upload-" + path, nil
+}
+
+func (m *multipartStorage) UploadPart(ctx context.Context, path, uploadID string, partNumber int32, body io.ReadSeeker, size int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if partNumber == m.failPart {
+		m.failPart = 0
+		return "", errors.New("connection reset")
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	m.parts[fmt.Sprintf("%s/%d", uploadID, partNumber)] = data
+	m.uploaded = append(m.uploaded, partNumber)
+	return fmt.Sprintf("etag-%d", partNumber), nil
+}
+
+func (m *multipartStorage) CompleteMultipart(ctx context.Context, path, uploadID string, parts []CompletedPart) (string, error) {
+	m.mu.Lock()
+	var buf bytes.Buffer
+	for _, p := range parts {
+		buf.Write(m.parts[fmt.Sprintf("%s/%d", uploadID, p.Number)])
+	}
+	m.mu.Unlock()
+	return m.Storage.Upload(ctx, &buf, path, "")
+}
+
+func (m *multipartStorage) AbortMultipart(ctx context.Context, path, uploadID string) error {
+	return nil
+}
+
+func (m *multipartStorage) UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return m.Storage.(ACLStorage).UploadWithACL(ctx, file, path, contentType, acl)
+}
+
+func (m *multipartStorage) SetACL(ctx context.Context, path string, acl configx.ACL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failACL > 0 {
+		m.failACL--
+		return errors.New("access denied")
+	}
+	return nil
+}
+
+// newMultipartManager 创建使用模拟分片上传存储的上传管理器
+func newMultipartManager(t *testing.T) (*UploadManager, *multipartStorage, string) {
+	t.Helper()
+	u, dir := newTestManager(t)
+	ms := &multipartStorage{Storage: u.storages[Local], parts: make(map[string][]byte)}
+	u.storages[Local] = ms
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.SetCheckpointStore(store)
+	return u, ms, dir
+}
+
+func TestUploadResumable(t *testing.T) {
+	ctx := context.Background()
+	data := append(bytes.Clone(testPNG), bytes.Repeat([]byte{1}, int(minPartSize))...)
+
+	t.Run("pipeline", func(t *testing.T) {
+		u, ms, dir := newMultipartManager(t)
+		quota := NewQuotaTracker(nil, QuotaLimit{MaxObjects: 10}, WithQuotaStore(NewMemoryQuotaStore()))
+		u.SetQuotaTracker(quota)
+		var hooked bool
+		u.BeforeUpload(func(ctx context.Context, uc *UploadContext) error {
+			hooked = uc.UserID == 7 && uc.FileName == "a.png"
+			return nil
+		})
+		ms.failPart = 2
+
+		_, err := u.UploadResumableWithUid(ctx, Local, "cp", bytes.NewReader(data), int64(len(data)), "big/a.png", "image/png", minPartSize, 7)
+		if err == nil {
+			t.Fatal("expected part failure")
+		}
+		if usage, _ := quota.Usage(ctx, 7); usage != (QuotaUsage{}) {
+			t.Fatalf("quota not released after failure: %+v", usage)
+		}
+
+		// 续传只上传未完成的分片
+		result, err := u.ResumeUpload(ctx, "cp", bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(ms.uploaded) != "[1 2]" {
+			t.Fatalf("uploaded parts = %v", ms.uploaded)
+		}
+		if !hooked || result.RelativePath != "/big/a.png" || result.Size != int64(len(data)) {
+			t.Fatalf("result = %+v, hooked = %v", result, hooked)
+		}
+		if got, err := os.ReadFile(filepath.Join(dir, "big/a.png")); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("object content mismatch: %v", err)
+		}
+		if usage, _ := quota.Usage(ctx, 7); usage.Objects != 1 || usage.Bytes != int64(len(data)) {
+			t.Fatalf("quota usage = %+v", usage)
+		}
+		if _, err = u.GetUploadCheckpoint(ctx, "cp"); !errors.Is(err, ErrCheckpointNotFound) {
+			t.Fatalf("checkpoint not deleted: %v", err)
+		}
+	})
+
+	t.Run("checkpoint kept until acl is set", func(t *testing.T) {
+		u, ms, _ := newMultipartManager(t)
+		ms.failACL = 1
+
+		if _, err := u.UploadResumable(ctx, Local, "cp", bytes.NewReader(testPNG), int64(len(testPNG)), "a.png", "image/png", 0); err == nil {
+			t.Fatal("expected acl failure")
+		}
+		cp, err := u.GetUploadCheckpoint(ctx, "cp")
+		if err != nil || cp.URL == "" {
+			t.Fatalf("checkpoint = %+v, %v", cp, err)
+		}
+		if _, err = u.ResumeUpload(ctx, "cp", bytes.NewReader(testPNG)); err != nil {
+			t.Fatal(err)
+		}
+		if len(ms.uploaded) != 1 {
+			t.Fatalf("parts uploaded again: %v", ms.uploaded)
+		}
+	})
+
+	t.Run("rejected before upload", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "short")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err = file.Write(testPNG[:10]); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name        string
+			file        io.ReaderAt
+			size        int64
+			contentType string
+		}{
+			{name: "empty file", file: bytes.NewReader(nil), size: 0, contentType: "image/png"},
+			{name: "os file size mismatch", file: file, size: int64(len(testPNG)), contentType: "image/png"},
+			{name: "type not allowed", file: bytes.NewReader(testPNG), size: int64(len(testPNG)), contentType: "application/x-msdownload"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				u, ms, _ := newMultipartManager(t)
+				if _, err := u.UploadResumable(ctx, Local, "cp", tt.file, tt.size, "a.png", tt.contentType, 0); err == nil {
+					t.Fatal("expected error")
+				}
+				if len(ms.uploaded) != 0 {
+					t.Fatalf("parts uploaded: %v", ms.uploaded)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/aliyun/alibabacloud-oss-go-sdk-v2/oss"
+	"github.com/tencentyun/cos-go-sdk-v5"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RetryPolicy 存储操作重试策略
+type RetryPolicy struct {
+	// 最大重试次数（不含首次）
+	MaxRetries int
+	// 首次重试等待时间
+	InitialBackoff time.Duration
+	// 最大等待时间
+	MaxBackoff time.Duration
+	// 等待时间倍数，默认2
+	Multiplier float64
+	// 判断错误是否可重试，默认 IsRetryableError
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy 默认重试策略：最多重试3次，等待 200ms、400ms、800ms（含随机抖动），最长5秒
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Retryable:      IsRetryableError,
+	}
+}
+
+// backoff 第 attempt 次重试前的等待时间，带 ±20% 抖动
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	return time.Duration(d * (0.8 + 0.4*rand.Float64()))
+}
+
+// retryable 错误是否可重试
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// IsRetryableError 是否为临时性错误：超时、连接中断、429 和 5xx 响应
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if code, ok := errorStatusCode(err); ok {
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// errorStatusCode 从各存储SDK的错误中提取HTTP状态码
+func errorStatusCode(err error) (int, bool) {
+	// AWS SDK
+	var awsErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &awsErr) {
+		return awsErr.HTTPStatusCode(), true
+	}
+
+	var ossErr *oss.ServiceError
+	if errors.As(err, &ossErr) {
+		return ossErr.StatusCode, true
+	}
+
+	var cosErr *cos.ErrorResponse
+	if errors.As(err, &cosErr) && cosErr.Response != nil {
+		return cosErr.Response.StatusCode, true
+	}
+	return 0, false
+}
+
+// SetRetryPolicy 设置存储操作的重试策略，为空时不重试
+func (u *UploadManager) SetRetryPolicy(p *RetryPolicy) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.retry = p
+}
+
+// retryPolicy 当前的重试策略
+func (u *UploadManager) retryPolicy() *RetryPolicy {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.retry
+}
+
+// withRetry 按重试策略执行存储操作，rewind 在重试前调用，返回错误时不再重试
+func (u *UploadManager) withRetry(ctx context.Context, op string, rewind func() error, fn func() error) error {
+	err := fn()
+	p := u.retryPolicy()
+	if p == nil {
+		return err
+	}
+
+	for attempt := 1; attempt <= p.MaxRetries && err != nil && p.retryable(err); attempt++ {
+		wait := p.backoff(attempt)
+		logx.WithContext(ctx).Infof("ossx: %s failed (%v), retry %d/%d in %s", op, err, attempt, p.MaxRetries, wait)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		if rewind != nil {
+			if rerr := rewind(); rerr != nil {
+				return fmt.Errorf("%w (not retried: %v)", err, rerr)
+			}
+		}
+		err = fn()
+	}
+	return err
+}
+
+// rewinder 返回将 Reader 重置到当前位置的函数，不支持 Seek 时返回的函数报错
+func rewinder(r io.Reader) func() error {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return func() error { return errors.New("reader is not seekable") }
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return func() error { return err }
+	}
+	return func() error {
+		_, err := seeker.Seek(start, io.SeekStart)
+		return err
+	}
+}
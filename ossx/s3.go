@@ -12,19 +12,30 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"io"
 	"mime"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
+const (
+	// S3所有用户组
+	s3AllUsers = "http://acs.amazonaws.com/groups/global/AllUsers"
+	// S3认证用户组
+	s3AuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
 // s3Storage 实现AWS S3的存储接口
 type s3Storage struct {
 	client    *s3.Client
 	uploader  *manager.Uploader
 	bucket    string
 	region    string
-	cdnDomain string // CDN域名（可选）
+	cdnDomain string     // CDN域名（可选）
+	endpoint  string     // 自定义Endpoint（可选，含协议）
+	pathStyle bool       // 路径风格访问
+	cdn       *cdnSigner // CDN鉴权（可选），设置后签名URL指向CDN
 }
 
 // newS3Storage 创建新的S3存储实例
@@ -35,8 +46,18 @@ func newS3Storage(sc configx.StorageConfig) (Storage, error) {
 	if sc.Bucket == "" {
 		return nil, fmt.Errorf("s3 bucket is required")
 	}
+	endpoint := normalizeEndpoint(sc.Endpoint, sc.DisableSSL)
 	if sc.Region == "" {
-		return nil, fmt.Errorf("s3 region is required")
+		if endpoint == "" {
+			return nil, fmt.Errorf("s3 region is required")
+		}
+		// S3兼容存储通常不校验区域，签名时使用默认区域
+		sc.Region = "us-east-1"
+	}
+
+	cdn, err := newCDNSigner(sc.CdnDomain, sc.CDNAuth, "auth_key")
+	if err != nil {
+		return nil, fmt.Errorf("s3 cdn auth: %w", err)
 	}
 
 	// 创建AWS配置
@@ -54,7 +75,12 @@ func newS3Storage(sc configx.StorageConfig) (Storage, error) {
 	}
 
 	// 创建S3客户端
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = sc.ForcePathStyle
+	})
 
 	// 创建上传管理器
 	uploader := manager.NewUploader(client, func(u *manager.Uploader) {
@@ -68,42 +94,96 @@ func newS3Storage(sc configx.StorageConfig) (Storage, error) {
 		bucket:    sc.Bucket,
 		region:    sc.Region,
 		cdnDomain: sc.CdnDomain,
+		endpoint:  endpoint,
+		pathStyle: sc.ForcePathStyle,
+		cdn:       cdn,
 	}, nil
 }
 
-// Upload 实现Storage接口的上传方法
+// normalizeEndpoint 补全Endpoint协议并去掉末尾斜杠
+func normalizeEndpoint(endpoint string, disableSSL bool) string {
+	endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" || strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return endpoint
+	}
+	if disableSSL {
+		return "http://" + endpoint
+	}
+	return "https://" + endpoint
+}
+
+// objectURL 生成对象的访问URL，优先使用CDN域名
+func (s *s3Storage) objectURL(path string) string {
+	if s.cdnDomain != "" {
+		if strings.HasPrefix(s.cdnDomain, "http") {
+			return fmt.Sprintf("%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
+		}
+		return fmt.Sprintf("https://%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
+	}
+
+	if s.endpoint != "" {
+		if s.pathStyle {
+			return fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, path)
+		}
+		scheme, host, _ := strings.Cut(s.endpoint, "://")
+		return fmt.Sprintf("%s://%s.%s/%s", scheme, s.bucket, host, path)
+	}
+
+	if s.pathStyle {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", s.region, s.bucket, path)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, path)
+}
+
+// Upload 实现Storage接口的上传方法，使用存储的默认权限
 func (s *s3Storage) Upload(ctx context.Context, file io.Reader, path, contentType string) (string, error) {
+	return s.UploadWithACL(ctx, file, path, contentType, configx.ACLDefault)
+}
+
+// UploadWithACL 实现 ACLStorage 接口，按指定访问权限上传
+func (s *s3Storage) UploadWithACL(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl})
+}
+
+// UploadIfAbsent 实现 ConditionalUploader 接口，使用 If-None-Match: * 条件写入，已存在时返回 ErrObjectExists
+func (s *s3Storage) UploadIfAbsent(ctx context.Context, file io.Reader, path, contentType string, acl configx.ACL) (string, error) {
+	return s.UploadWithOptions(ctx, file, path, contentType, ObjectOptions{ACL: acl, IfAbsent: true})
+}
+
+// UploadWithOptions 实现 OptionsUploader 接口，IfAbsent 时分片上传在合并时校验
+func (s *s3Storage) UploadWithOptions(ctx context.Context, file io.Reader, path, contentType string, opts ObjectOptions) (string, error) {
 	// 标准化路径，处理前导斜杠
 	path = strings.TrimPrefix(path, "/")
 
-	// 使用上传管理器上传文件
-	_, err := s.uploader.Upload(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
 		Key:         aws.String(path),
 		Body:        file,
 		ContentType: aws.String(contentType),
-		ACL:         types.ObjectCannedACLPublicRead, // 默认为公共可读
-	})
+		ACL:         s3ACL(opts.ACL),
+		Metadata:    opts.Metadata,
+	}
+	if opts.IfAbsent {
+		input.IfNoneMatch = aws.String("*")
+	}
+	if opts.StorageClass != "" {
+		input.StorageClass = types.StorageClass(s3StorageClass(opts.StorageClass))
+	}
+	if opts.CacheControl != "" {
+		input.CacheControl = aws.String(opts.CacheControl)
+	}
 
+	// 使用上传管理器上传文件
+	_, err := s.uploader.Upload(ctx, input)
 	if err != nil {
+		if opts.IfAbsent && isConflict(err) {
+			return "", fmt.Errorf("%w: %s", ErrObjectExists, path)
+		}
 		return "", fmt.Errorf("failed to upload file to S3: %w", err)
 	}
 
 	// 生成文件URL
-	var fileURL string
-	if s.cdnDomain != "" {
-		// 使用CDN域名生成URL
-		if strings.HasPrefix(s.cdnDomain, "http") {
-			fileURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
-		} else {
-			fileURL = fmt.Sprintf("https://%s/%s", strings.TrimSuffix(s.cdnDomain, "/"), path)
-		}
-	} else {
-		// 使用S3默认域名生成URL
-		fileURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, path)
-	}
-
-	return fileURL, nil
+	return s.objectURL(path), nil
 }
 
 // Delete 实现Storage接口的删除方法
@@ -129,6 +209,11 @@ func (s *s3Storage) CreateSignedURL(ctx context.Context, path string, expiration
 	// 标准化路径
 	path = strings.TrimPrefix(path, "/")
 
+	// 配置CDN鉴权时生成CDN签名URL
+	if s.cdn != nil {
+		return s.cdn.sign(path, expiration)
+	}
+
 	// 创建预签名客户端
 	presignClient := s3.NewPresignClient(s.client)
 
@@ -190,21 +275,20 @@ func (s *s3Storage) ListObjects(ctx context.Context, prefix string, maxKeys int3
 	return keys, nil
 }
 
-// CopyObject 在S3内部复制对象（扩展功能）
+// CopyObject 在S3内部复制对象，实现 ObjectCopier 接口
 func (s *s3Storage) CopyObject(ctx context.Context, srcPath, destPath string) error {
 	// 标准化路径
 	srcPath = strings.TrimPrefix(srcPath, "/")
 	destPath = strings.TrimPrefix(destPath, "/")
 
-	// 构建源对象的完整路径
-	srcObject := fmt.Sprintf("%s/%s", s.bucket, srcPath)
+	// 构建源对象的完整路径，CopySource 需要URL编码
+	srcObject := (&url.URL{Path: fmt.Sprintf("%s/%s", s.bucket, srcPath)}).EscapedPath()
 
 	// 执行复制操作
 	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(s.bucket),
 		CopySource: aws.String(srcObject),
 		Key:        aws.String(destPath),
-		ACL:        types.ObjectCannedACLPublicRead, // 默认为公共可读
 	})
 
 	if err != nil {
@@ -315,3 +399,319 @@ func (s *s3Storage) SetObjectACL(ctx context.Context, path string, acl types.Obj
 
 	return nil
 }
+
+// SetACL 实现 ACLStorage 接口，修改已有对象的访问权限
+func (s *s3Storage) SetACL(ctx context.Context, path string, acl configx.ACL) error {
+	return s.SetObjectACL(ctx, path, s3ACL(acl))
+}
+
+// GetACL 实现 ACLReader 接口，按所有用户和认证用户的读权限转换
+func (s *s3Storage) GetACL(ctx context.Context, path string) (configx.ACL, error) {
+	result, err := s.client.GetObjectAcl(ctx, &s3.GetObjectAclInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(strings.TrimPrefix(path, "/")),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object ACL: %w", err)
+	}
+	acl := configx.ACLPrivate
+	for _, grant := range result.Grants {
+		if grant.Grantee == nil || (grant.Permission != types.PermissionRead && grant.Permission != types.PermissionFullControl) {
+			continue
+		}
+		switch aws.ToString(grant.Grantee.URI) {
+		case s3AllUsers:
+			return configx.ACLPublic, nil
+		case s3AuthenticatedUsers:
+			acl = configx.ACLAuthenticated
+		}
+	}
+	return acl, nil
+}
+
+// s3ACL 转换为S3预设ACL，未指定时与原有行为一致为公共可读
+func s3ACL(acl configx.ACL) types.ObjectCannedACL {
+	switch acl {
+	case configx.ACLDefault, configx.ACLPublic:
+		return types.ObjectCannedACLPublicRead
+	case configx.ACLAuthenticated:
+		return types.ObjectCannedACLAuthenticatedRead
+	default:
+		return types.ObjectCannedACLPrivate
+	}
+}
+
+// s3StorageClass 转换为S3存储类型
+func s3StorageClass(class string) string {
+	switch class {
+	case configx.StorageClassStandard:
+		return string(types.StorageClassStandard)
+	case configx.StorageClassIA:
+		return string(types.StorageClassStandardIa)
+	case configx.StorageClassArchive:
+		return string(types.StorageClassGlacier)
+	case configx.StorageClassDeepArchive:
+		return string(types.StorageClassDeepArchive)
+	default:
+		return class
+	}
+}
+
+// ListPage 分页列出指定前缀的对象，marker 为上一页返回的续传标记
+func (s *s3Storage) ListPage(ctx context.Context, prefix, marker string, limit int) ([]ObjectInfo, string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket:  aws.String(s.bucket),
+		Prefix:  aws.String(strings.TrimPrefix(prefix, "/")),
+		MaxKeys: aws.Int32(int32(limit)),
+	}
+	if marker != "" {
+		input.ContinuationToken = aws.String(marker)
+	}
+
+	output, err := s.client.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	objects := make([]ObjectInfo, 0, len(output.Contents))
+	for _, obj := range output.Contents {
+		objects = append(objects, ObjectInfo{
+			Key:          aws.ToString(obj.Key),
+			Size:         aws.ToInt64(obj.Size),
+			ETag:         strings.Trim(aws.ToString(obj.ETag), `"`),
+			LastModified: aws.ToTime(obj.LastModified),
+		})
+	}
+
+	if !aws.ToBool(output.IsTruncated) {
+		return objects, "", nil
+	}
+	return objects, aws.ToString(output.NextContinuationToken), nil
+}
+
+// Download 流式下载对象，调用方负责关闭
+func (s *s3Storage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(strings.TrimPrefix(path, "/")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object from S3: %w", err)
+	}
+	return output.Body, nil
+}
+
+// StatObject 实现 RangeReader 接口，获取对象元信息
+func (s *s3Storage) StatObject(ctx context.Context, path string) (*ObjectMeta, error) {
+	output, err := s.GetObjectInfo(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	meta := &ObjectMeta{
+		Size:         aws.ToInt64(output.ContentLength),
+		ContentType:  aws.ToString(output.ContentType),
+		ETag:         aws.ToString(output.ETag),
+		LastModified: aws.ToTime(output.LastModified),
+		Encryption:   string(output.ServerSideEncryption),
+	}
+	if output.SSECustomerAlgorithm != nil {
+		meta.Encryption = "SSE-C"
+	}
+	return meta, nil
+}
+
+// DownloadRange 实现 RangeReader 接口，按范围流式下载对象
+func (s *s3Storage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(strings.TrimPrefix(path, "/")),
+		Range:  aws.String(rangeHeader(offset, length)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object range from S3: %w", err)
+	}
+	return output.Body, nil
+}
+
+// InitMultipart 实现 MultipartUploader 接口，初始化分片上传
+func (s *s3Storage) InitMultipart(ctx context.Context, path, contentType string) (string, error) {
+	result, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(strings.TrimPrefix(path, "/")),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to initiate multipart upload: %w", err)
+	}
+	return aws.ToString(result.UploadId), nil
+}
+
+// UploadPart 实现 MultipartUploader 接口，上传单个分片
+func (s *s3Storage) UploadPart(ctx context.Context, path, uploadID string, partNumber int32, body io.ReadSeeker, size int64) (string, error) {
+	result, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(strings.TrimPrefix(path, "/")),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part to S3: %w", err)
+	}
+	return aws.ToString(result.ETag), nil
+}
+
+// CompleteMultipart 实现 MultipartUploader 接口，合并分片
+func (s *s3Storage) CompleteMultipart(ctx context.Context, path, uploadID string, parts []CompletedPart) (string, error) {
+	path = strings.TrimPrefix(path, "/")
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{PartNumber: aws.Int32(p.Number), ETag: aws.String(p.ETag)}
+	}
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(path),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return s.objectURL(path), nil
+}
+
+// AbortMultipart 实现 MultipartUploader 接口，取消分片上传
+func (s *s3Storage) AbortMultipart(ctx context.Context, path, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(strings.TrimPrefix(path, "/")),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// PutLifecycle 实现 LifecycleStorage 接口，设置存储桶生命周期规则
+func (s *s3Storage) PutLifecycle(ctx context.Context, rules []LifecycleRule) error {
+	s3Rules := make([]types.LifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		rule := types.LifecycleRule{
+			ID:     aws.String(r.ID),
+			Status: types.ExpirationStatus(lifecycleStatus(r.Disabled)),
+			Filter: &types.LifecycleRuleFilter{Prefix: aws.String(r.Prefix)},
+		}
+		for _, t := range r.Transitions {
+			rule.Transitions = append(rule.Transitions, types.Transition{
+				Days:         aws.Int32(int32(t.Days)),
+				StorageClass: types.TransitionStorageClass(s3StorageClass(t.StorageClass)),
+			})
+		}
+		if r.ExpirationDays > 0 {
+			rule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(int32(r.ExpirationDays))}
+		}
+		if r.AbortMultipartDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int32(int32(r.AbortMultipartDays)),
+			}
+		}
+		s3Rules = append(s3Rules, rule)
+	}
+
+	_, err := s.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(s.bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: s3Rules},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put S3 bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// GetLifecycle 实现 LifecycleStorage 接口，获取存储桶生命周期规则
+func (s *s3Storage) GetLifecycle(ctx context.Context) ([]LifecycleRule, error) {
+	output, err := s.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(s.bucket),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get S3 bucket lifecycle: %w", err)
+	}
+
+	rules := make([]LifecycleRule, 0, len(output.Rules))
+	for _, r := range output.Rules {
+		rule := LifecycleRule{
+			ID:       aws.ToString(r.ID),
+			Prefix:   aws.ToString(r.Prefix),
+			Disabled: r.Status != types.ExpirationStatusEnabled,
+		}
+		if f := r.Filter; f != nil {
+			if f.Prefix != nil {
+				rule.Prefix = *f.Prefix
+			}
+			rule.unsupportedIf(f.And != nil, "filter.and")
+			rule.unsupportedIf(f.Tag != nil, "filter.tag")
+			rule.unsupportedIf(f.ObjectSizeGreaterThan != nil || f.ObjectSizeLessThan != nil, "filter.object_size")
+		}
+		for _, t := range r.Transitions {
+			rule.unsupportedIf(t.Date != nil, "transition.date")
+			rule.Transitions = append(rule.Transitions, LifecycleTransition{
+				Days:         int(aws.ToInt32(t.Days)),
+				StorageClass: string(t.StorageClass),
+			})
+		}
+		if r.Expiration != nil {
+			rule.unsupportedIf(r.Expiration.Date != nil, "expiration.date")
+			rule.unsupportedIf(aws.ToBool(r.Expiration.ExpiredObjectDeleteMarker), "expiration.expired_object_delete_marker")
+			rule.ExpirationDays = int(aws.ToInt32(r.Expiration.Days))
+		}
+		rule.unsupportedIf(r.NoncurrentVersionExpiration != nil || len(r.NoncurrentVersionTransitions) > 0, "noncurrent_version")
+		if r.AbortIncompleteMultipartUpload != nil {
+			rule.AbortMultipartDays = int(aws.ToInt32(r.AbortIncompleteMultipartUpload.DaysAfterInitiation))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DeleteLifecycle 实现 LifecycleStorage 接口，删除存储桶生命周期规则
+func (s *s3Storage) DeleteLifecycle(ctx context.Context) error {
+	_, err := s.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(s.bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete S3 bucket lifecycle: %w", err)
+	}
+	return nil
+}
+
+// PresignPut 实现 PresignedUploader 接口，签名包含 Content-Type、Content-Length 和 If-None-Match，目标已存在时拒绝上传
+func (s *s3Storage) PresignPut(ctx context.Context, path, contentType string, size int64, expiration time.Duration) (string, map[string]string, error) {
+	path = strings.TrimPrefix(path, "/")
+
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(path),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+		IfNoneMatch:   aws.String("*"),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiration
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create presigned put URL: %w", err)
+	}
+
+	headers := map[string]string{"Content-Type": contentType}
+	for key := range req.SignedHeader {
+		if !strings.EqualFold(key, "Host") {
+			headers[key] = req.SignedHeader.Get(key)
+		}
+	}
+	return req.URL, headers, nil
+}
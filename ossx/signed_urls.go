@@ -0,0 +1,133 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mr"
+)
+
+// 批量签名默认并发数
+const defaultSignWorkers = 10
+
+// SignedURLError 批量签名中单个路径的错误
+type SignedURLError struct {
+	// 在请求路径列表中的下标
+	Index int
+	Path  string
+	Err   error
+}
+
+func (e *SignedURLError) Error() string {
+	return fmt.Sprintf("sign %s (#%d): %v", e.Path, e.Index, e.Err)
+}
+
+func (e *SignedURLError) Unwrap() error {
+	return e.Err
+}
+
+// signedURLsOptions 批量签名选项
+type signedURLsOptions struct {
+	failFast bool
+	workers  int
+}
+
+// SignedURLsOption 批量签名选项
+type SignedURLsOption func(o *signedURLsOptions)
+
+// WithFailFast 任一路径签名失败时取消其余任务并返回该错误，默认继续处理并在结果中记录错误
+func WithFailFast() SignedURLsOption {
+	return func(o *signedURLsOptions) {
+		o.failFast = true
+	}
+}
+
+// WithSignWorkers 设置批量签名并发数，默认10
+func WithSignWorkers(n int) SignedURLsOption {
+	return func(o *signedURLsOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// signItem 待签名的路径及其下标
+type signItem struct {
+	index int
+	path  string
+}
+
+// GetSignedURLs 批量并发获取签名URL，结果与 paths 一一对应（重复路径各自占位），配置主备存储且主存储不可用时使用备存储
+// 默认部分失败时仍返回 nil 错误，失败项的 Error/Err 非空，可用 SignedURLErrors 汇总；
+// 开启 WithFailFast 时返回第一个 *SignedURLError
+func (u *UploadManager) GetSignedURLs(ctx context.Context, storageType string, paths []string, expiration time.Duration, opts ...SignedURLsOption) ([]SignedURLResult, error) {
+	storage, ok := u.readStorage(storageType)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+
+	if len(paths) == 0 {
+		return []SignedURLResult{}, nil
+	}
+
+	o := signedURLsOptions{workers: defaultSignWorkers}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// 每个任务只写入自己下标的位置，无需加锁
+	results := make([]SignedURLResult, len(paths))
+	err := mr.MapReduceVoid(
+		func(source chan<- signItem) {
+			for i, path := range paths {
+				source <- signItem{index: i, path: path}
+			}
+		},
+		func(item signItem, writer mr.Writer[struct{}], cancel func(error)) {
+			result := &results[item.index]
+			result.RelativePath = item.path
+
+			signedURL, err := u.createSignedURL(ctx, storageType, storage, item.path, expiration)
+			if err != nil {
+				result.Err = &SignedURLError{Index: item.index, Path: item.path, Err: err}
+				result.Error = err.Error()
+				logx.WithContext(ctx).Errorf("failed to get signed URL for %s: %v", item.path, err)
+				if o.failFast {
+					cancel(result.Err)
+				}
+				return
+			}
+			result.SignedURL = signedURL
+			result.Expire = time.Now().Add(expiration).Unix()
+		},
+		func(pipe <-chan struct{}, cancel func(error)) {
+			for range pipe {
+			}
+		},
+		mr.WithContext(ctx),
+		mr.WithWorkers(o.workers),
+	)
+	if err != nil {
+		var signErr *SignedURLError
+		if errors.As(err, &signErr) {
+			return nil, signErr
+		}
+		return nil, fmt.Errorf("failed to get signed URLs: %w", err)
+	}
+
+	return results, nil
+}
+
+// SignedURLErrors 汇总批量签名结果中的错误，全部成功时返回 nil
+func SignedURLErrors(results []SignedURLResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+func TestUploadSniffContent(t *testing.T) {
+	exe := append([]byte("MZ\x90\x00"), testPNG[8:]...)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 总是声明为PNG，内容按路径返回
+		w.Header().Set("Content-Type", "image/png")
+		if r.URL.Path == "/exe.png" {
+			_, _ = w.Write(exe)
+			return
+		}
+		_, _ = w.Write(testPNG)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		reject  bool
+		data    []byte
+		url     string
+		wantErr bool
+	}{
+		{"png upload", true, testPNG, "", false},
+		{"disguised upload rejected", true, exe, "", true},
+		{"disguised upload not allowed", false, exe, "", true},
+		{"png from url", true, nil, "/a.png", false},
+		{"disguised from url rejected", true, nil, "/exe.png", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, dir := newTestManager(t)
+			u.SetFetchClient(srv.Client())
+			u.uploadConfig.SniffContent, u.uploadConfig.RejectMismatch = true, tt.reject
+
+			var (
+				result *UploadResult
+				err    error
+			)
+			if tt.url != "" {
+				result, err = u.UploadFromURL(context.Background(), Local, srv.URL+tt.url, "")
+			} else {
+				result, err = uploadBytes(u, 7, "a.png", tt.data)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected validation failure")
+				}
+				if tt.reject && !errors.Is(err, configx.ErrContentTypeMismatch) {
+					t.Fatalf("err = %v, want ErrContentTypeMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 读取文件头后内容仍完整上传
+			got, err := os.ReadFile(filepath.Join(dir, result.RelativePath))
+			if err != nil || len(got) != len(testPNG) {
+				t.Fatalf("uploaded %d bytes, %v", len(got), err)
+			}
+		})
+	}
+}
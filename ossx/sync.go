@@ -0,0 +1,265 @@
+package ossx
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mr"
+)
+
+// 目录同步默认并发数
+const defaultSyncConcurrency = 4
+
+// ErrSyncDeleteAll 未指定远程前缀时开启 Delete 会删除整个存储桶中本地不存在的对象，需设置 DeleteAll 确认
+var ErrSyncDeleteAll = errors.New("sync delete without remote prefix requires DeleteAll")
+
+// SyncOptions 目录同步选项
+type SyncOptions struct {
+	// 删除远程前缀下本地不存在的对象，开启回收站时移入回收站
+	Delete bool
+	// 确认在未指定远程前缀时删除整个存储桶中本地不存在的对象，否则返回 ErrSyncDeleteAll
+	DeleteAll bool
+	// 只计算差异，不实际上传和删除
+	DryRun bool
+	// 并发上传数，默认4
+	Concurrency int
+	// 排除的文件，参数为相对 localDir 的路径（使用 /），返回 true 时不上传也不删除
+	Exclude func(relPath string) bool
+	// 单个文件失败时继续同步，失败的路径记录在结果中
+	ContinueOnError bool
+}
+
+// SyncReport 目录同步结果
+type SyncReport struct {
+	// 上传的对象key
+	Uploaded []string `json:"uploaded"`
+	// 删除的对象key
+	Deleted []string `json:"deleted"`
+	// 内容未变化跳过的文件数
+	Skipped int `json:"skipped"`
+	// 上传的字节数
+	Bytes int64 `json:"bytes"`
+	// 失败的对象key
+	FailedKeys []string `json:"failed_keys,omitempty"`
+}
+
+// syncFile 待同步的本地文件
+type syncFile struct {
+	localPath string
+	key       string
+	info      fs.FileInfo
+}
+
+// SyncDirectory 将本地目录同步到存储的 remotePrefix 下，只上传新增或变化的文件，存储需实现 ObjectLister
+// 远程ETag为内容MD5时按MD5比较，否则按大小和修改时间比较（本地文件更新时上传）
+func (u *UploadManager) SyncDirectory(ctx context.Context, storageType, localDir, remotePrefix string, opts SyncOptions) (*SyncReport, error) {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	lister, ok := storage.(ObjectLister)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support listing", storageType)
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultSyncConcurrency
+	}
+
+	remotePrefix = strings.TrimPrefix(remotePrefix, "/")
+	if opts.Delete && remotePrefix == "" && !opts.DeleteAll {
+		return nil, ErrSyncDeleteAll
+	}
+	if remotePrefix != "" && !strings.HasSuffix(remotePrefix, "/") {
+		remotePrefix += "/"
+	}
+
+	remote, err := u.listRemote(ctx, lister, remotePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []syncFile
+	err = filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		rel = filepath.ToSlash(rel)
+		if opts.Exclude != nil && opts.Exclude(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, syncFile{localPath: p, key: remotePrefix + rel, info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", localDir, err)
+	}
+
+	report := &SyncReport{}
+	var mu sync.Mutex
+	syncOne := func(f syncFile) {
+		obj, exists := remote[f.key]
+		changed, err := fileChanged(f, obj, exists)
+		if err == nil && changed && !opts.DryRun {
+			err = u.syncUpload(ctx, storageType, storage, f)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err != nil:
+			report.FailedKeys = append(report.FailedKeys, f.key)
+			logx.WithContext(ctx).Errorf("failed to sync %s to %s: %v", f.localPath, f.key, err)
+		case changed:
+			report.Uploaded = append(report.Uploaded, f.key)
+			report.Bytes += f.info.Size()
+		default:
+			report.Skipped++
+		}
+	}
+	mr.ForEach(func(source chan<- syncFile) {
+		for _, f := range files {
+			source <- f
+		}
+	}, syncOne, mr.WithContext(ctx), mr.WithWorkers(opts.Concurrency))
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if !opts.ContinueOnError && len(report.FailedKeys) > 0 {
+		return report, fmt.Errorf("sync %d files failed, first: %s", len(report.FailedKeys), report.FailedKeys[0])
+	}
+
+	if opts.Delete {
+		local := make(map[string]struct{}, len(files))
+		for _, f := range files {
+			local[f.key] = struct{}{}
+		}
+		for key := range remote {
+			if _, ok := local[key]; ok {
+				continue
+			}
+			if opts.Exclude != nil && opts.Exclude(strings.TrimPrefix(key, remotePrefix)) {
+				continue
+			}
+			if !opts.DryRun {
+				if err := u.Delete(ctx, storageType, key); err != nil {
+					report.FailedKeys = append(report.FailedKeys, key)
+					logx.WithContext(ctx).Errorf("failed to delete orphan %s: %v", key, err)
+					if !opts.ContinueOnError {
+						return report, fmt.Errorf("delete orphan %s: %w", key, err)
+					}
+					continue
+				}
+			}
+			report.Deleted = append(report.Deleted, key)
+		}
+	}
+
+	sort.Strings(report.Uploaded)
+	sort.Strings(report.Deleted)
+	logx.WithContext(ctx).Infof("Synced %s to %s:%s, %d uploaded, %d skipped, %d deleted, %d failed",
+		localDir, storageType, remotePrefix, len(report.Uploaded), report.Skipped, len(report.Deleted), len(report.FailedKeys))
+	return report, nil
+}
+
+// listRemote 列出前缀下的全部对象，跳过目录占位对象和回收站
+func (u *UploadManager) listRemote(ctx context.Context, lister ObjectLister, prefix string) (map[string]ObjectInfo, error) {
+	objects := make(map[string]ObjectInfo)
+	trash := u.trashPolicy()
+	var marker string
+	for {
+		page, next, err := lister.ListPage(ctx, prefix, marker, defaultMigratePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("list objects after %q: %w", marker, err)
+		}
+		for _, obj := range page {
+			if strings.HasSuffix(obj.Key, "/") || (trash != nil && trash.inTrash(obj.Key)) {
+				continue
+			}
+			objects[obj.Key] = obj
+		}
+		if next == "" {
+			return objects, nil
+		}
+		marker = next
+	}
+}
+
+// syncUpload 上传单个文件，失败时按重试策略重试
+func (u *UploadManager) syncUpload(ctx context.Context, storageType string, storage Storage, f syncFile) error {
+	file, err := os.Open(f.localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(strings.ToLower(path.Ext(f.key)))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	acl := u.uploadConfig.ACLFor(configx.DetectFileType(contentType))
+
+	err = u.withRetry(ctx, "sync "+f.key, rewinder(file), func() error {
+		_, err := uploadObject(ctx, storage, file, f.key, contentType, acl)
+		return err
+	})
+	u.observePrimary(storageType, err)
+	if err != nil {
+		return err
+	}
+	u.replicate(storageType, replicateJob{path: f.key, contentType: contentType, acl: acl})
+	return nil
+}
+
+// fileChanged 本地文件相对远程对象是否有变化
+func fileChanged(f syncFile, obj ObjectInfo, exists bool) (bool, error) {
+	if !exists || obj.Size != f.info.Size() {
+		return true, nil
+	}
+
+	if etag := strings.ToLower(strings.Trim(obj.ETag, `"`)); isMD5Hex(etag) {
+		sum, err := md5File(f.localPath)
+		if err != nil {
+			return false, err
+		}
+		return sum != etag, nil
+	}
+	return f.info.ModTime().After(obj.LastModified), nil
+}
+
+// md5File 计算本地文件的MD5
+func md5File(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,45 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyncDirectoryDelete(t *testing.T) {
+	ctx := context.Background()
+	u, dir := newTestManager(t)
+	for _, key := range []string{"site/stale.txt", "other/keep.txt"} {
+		if _, err := u.storages[Local].Upload(ctx, bytes.NewReader([]byte("x")), key, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	localDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(localDir, "index.html"), []byte("<html></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := u.SyncDirectory(ctx, Local, localDir, "", SyncOptions{Delete: true}); !errors.Is(err, ErrSyncDeleteAll) {
+		t.Fatalf("err = %v, want ErrSyncDeleteAll", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other/keep.txt")); err != nil {
+		t.Fatalf("object deleted without confirmation: %v", err)
+	}
+
+	report, err := u.SyncDirectory(ctx, Local, localDir, "site", SyncOptions{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Uploaded) != 1 || report.Uploaded[0] != "site/index.html" {
+		t.Fatalf("uploaded = %v", report.Uploaded)
+	}
+	if len(report.Deleted) != 1 || report.Deleted[0] != "site/stale.txt" {
+		t.Fatalf("deleted = %v", report.Deleted)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "other/keep.txt")); err != nil {
+		t.Fatalf("object outside prefix deleted: %v", err)
+	}
+}
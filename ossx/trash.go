@@ -0,0 +1,375 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 默认回收站前缀
+	defaultTrashPrefix = ".trash/"
+	// 默认回收站保留时间
+	defaultTrashTTL = 30 * 24 * time.Hour
+	// 回收站对象key中原路径与删除时间的分隔符
+	trashSeparator = "~"
+)
+
+// ErrNotInTrash 回收站中没有该对象
+var ErrNotInTrash = errors.New("object not in trash")
+
+// ObjectCopier 支持服务端复制对象的存储
+type ObjectCopier interface {
+	// CopyObject 复制对象，目标已存在时覆盖
+	CopyObject(ctx context.Context, src, dst string) error
+}
+
+// TrashPolicy 回收站策略：删除时先将对象复制到回收站前缀下再删除原对象，可在保留期内恢复
+// 回收站对象key为 前缀+原路径+~+删除时间戳+~+原访问权限，如 .trash/avatar/1.png~1700000000~public，
+// 对象继承存储桶权限时不带访问权限；回收站中的对象为私有访问权限，恢复时还原原访问权限
+type TrashPolicy struct {
+	// 回收站前缀，默认 .trash/
+	Prefix string
+	// 保留时间，默认30天，PurgeTrash 未指定时间时按此清理
+	TTL time.Duration
+}
+
+// TrashEntry 回收站中的对象
+type TrashEntry struct {
+	// 原路径
+	Path string `json:"path"`
+	// 回收站中的路径
+	TrashPath string `json:"trash_path"`
+	// 删除时间
+	DeletedAt time.Time `json:"deleted_at"`
+	// 删除前的访问权限，对象继承存储桶权限时为空
+	ACL  configx.ACL `json:"acl,omitempty"`
+	Size int64       `json:"size"`
+}
+
+// SetTrash 设置回收站策略，为空时关闭回收站，Delete 直接删除对象
+func (u *UploadManager) SetTrash(policy *TrashPolicy) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if policy == nil {
+		u.trash = nil
+		return
+	}
+	p := *policy
+	if p.Prefix == "" {
+		p.Prefix = defaultTrashPrefix
+	}
+	if !strings.HasSuffix(p.Prefix, "/") {
+		p.Prefix += "/"
+	}
+	p.Prefix = strings.TrimPrefix(p.Prefix, "/")
+	if p.TTL <= 0 {
+		p.TTL = defaultTrashTTL
+	}
+	u.trash = &p
+}
+
+// trashPolicy 当前的回收站策略，未开启时为空
+func (u *UploadManager) trashPolicy() *TrashPolicy {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.trash
+}
+
+// moveToTrash 将对象复制到回收站，记录原访问权限并将回收站中的副本设为私有，返回回收站中的路径；对象不存在时返回的错误满足 isNotFound
+func (u *UploadManager) moveToTrash(ctx context.Context, trash *TrashPolicy, storageType string, storage Storage, path string) (string, error) {
+	var acl configx.ACL
+	if ar, ok := storage.(ACLReader); ok {
+		err := u.withRetry(ctx, "get acl "+path, nil, func() (err error) {
+			acl, err = ar.GetACL(ctx, path)
+			return err
+		})
+		if isNotFound(err) {
+			return "", fmt.Errorf("failed to move %s to trash: %w", path, err)
+		}
+		if err != nil {
+			logx.WithContext(ctx).Errorf("ossx trash: failed to get acl of %s, restoring keeps the trash acl: %v", path, err)
+		}
+	}
+
+	trashPath := trashKey(trash.Prefix, path, time.Now(), acl)
+	err := u.withRetry(ctx, "trash "+path, nil, func() error {
+		return copyObject(ctx, storage, path, trashPath)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to move %s to trash: %w", path, err)
+	}
+
+	// 服务端复制可能保留原访问权限，已删除的公开对象不应继续公开
+	if as, ok := storage.(ACLStorage); ok && acl != configx.ACLPrivate {
+		err = u.withRetry(ctx, "set acl "+trashPath, nil, func() error {
+			return as.SetACL(ctx, trashPath, configx.ACLPrivate)
+		})
+		if err != nil {
+			if derr := storage.Delete(ctx, trashPath); derr != nil {
+				logx.WithContext(ctx).Errorf("ossx trash: failed to remove %s: %v", trashPath, derr)
+			}
+			return "", fmt.Errorf("failed to move %s to trash: %w", path, err)
+		}
+	}
+	u.replicate(storageType, replicateJob{path: trashPath, acl: configx.ACLPrivate})
+	return trashPath, nil
+}
+
+// Restore 从回收站恢复最近一次删除的对象到原路径，原路径已存在时覆盖
+// 恢复的对象重新占用所有者的配额，超出配额时不恢复并返回配额错误
+// 恢复的对象还原删除前的访问权限，设置失败时保留回收站中的对象以便重试
+func (u *UploadManager) Restore(ctx context.Context, storageType, path string) error {
+	trash := u.trashPolicy()
+	if trash == nil {
+		return errors.New("trash is not enabled")
+	}
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return fmt.Errorf("storage type %s not initialized", storageType)
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	entries, err := u.listTrash(ctx, storage, trash, path+trashSeparator)
+	if err != nil {
+		return err
+	}
+	var latest *TrashEntry
+	for i := range entries {
+		if entries[i].Path == path && (latest == nil || entries[i].DeletedAt.After(latest.DeletedAt)) {
+			latest = &entries[i]
+		}
+	}
+	if latest == nil {
+		return fmt.Errorf("%w: %s", ErrNotInTrash, path)
+	}
+
+	// 复制前重新占用配额
+	quota := u.QuotaTracker()
+	var (
+		userId   int64
+		reserved bool
+	)
+	trashObject := quotaObject(storageType, latest.TrashPath)
+	if quota != nil {
+		if userId, reserved, err = quota.reserveTrashed(ctx, trashObject, latest.Size); err != nil {
+			return err
+		}
+	}
+
+	err = u.withRetry(ctx, "restore "+path, nil, func() error {
+		return copyObject(ctx, storage, latest.TrashPath, path)
+	})
+	if err != nil {
+		if reserved {
+			if qerr := quota.Release(ctx, userId, latest.Size); qerr != nil {
+				logx.WithContext(ctx).Errorf("failed to release quota of %s: %v", path, qerr)
+			}
+			quota.own(ctx, trashObject, userId, latest.Size)
+		}
+		return fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	if reserved {
+		// 被覆盖的对象归还配额，恢复的对象记录占用
+		if qerr := quota.releaseObject(ctx, quotaObject(storageType, path)); qerr != nil {
+			logx.WithContext(ctx).Errorf("failed to release quota of %s: %v", path, qerr)
+		}
+		quota.own(ctx, quotaObject(storageType, path), userId, latest.Size)
+	}
+	if as, ok := storage.(ACLStorage); ok && latest.ACL != "" {
+		err = u.withRetry(ctx, "set acl "+path, nil, func() error {
+			return as.SetACL(ctx, path, latest.ACL)
+		})
+		if err != nil {
+			// 保留占用记录，重试恢复时据此重新占用
+			if reserved {
+				quota.own(ctx, trashObject, userId, latest.Size)
+			}
+			return fmt.Errorf("restored %s but failed to set acl: %w", path, err)
+		}
+	}
+	u.replicate(storageType, replicateJob{path: path, acl: latest.ACL})
+
+	if err := u.deletePermanently(ctx, storageType, storage, latest.TrashPath); err != nil {
+		logx.Errorf("ossx trash: failed to remove restored object %s: %v", latest.TrashPath, err)
+	}
+	return nil
+}
+
+// ListTrash 列出回收站中原路径以 prefix 开头的对象，存储需实现 ObjectLister
+func (u *UploadManager) ListTrash(ctx context.Context, storageType, prefix string) ([]TrashEntry, error) {
+	trash := u.trashPolicy()
+	if trash == nil {
+		return nil, errors.New("trash is not enabled")
+	}
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	return u.listTrash(ctx, storage, trash, strings.TrimPrefix(prefix, "/"))
+}
+
+// PurgeTrash 永久删除回收站中删除时间早于 olderThan 之前的对象，olderThan <=0 时按保留时间清理，返回删除数量
+// 也可以在存储桶上为回收站前缀配置生命周期规则代替定期调用
+func (u *UploadManager) PurgeTrash(ctx context.Context, storageType string, olderThan time.Duration) (int, error) {
+	trash := u.trashPolicy()
+	if trash == nil {
+		return 0, errors.New("trash is not enabled")
+	}
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return 0, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	if olderThan <= 0 {
+		olderThan = trash.TTL
+	}
+
+	entries, err := u.listTrash(ctx, storage, trash, "")
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var (
+		purged int
+		errs   []error
+	)
+	for _, entry := range entries {
+		if entry.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := u.deletePermanently(ctx, storageType, storage, entry.TrashPath); err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", entry.TrashPath, err))
+			continue
+		}
+		if quota := u.QuotaTracker(); quota != nil {
+			if err := quota.dropObject(ctx, quotaObject(storageType, entry.TrashPath)); err != nil {
+				logx.WithContext(ctx).Errorf("ossx trash: failed to drop quota owner of %s: %v", entry.TrashPath, err)
+			}
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// listTrash 列出回收站中原路径以 prefix 开头的对象
+func (u *UploadManager) listTrash(ctx context.Context, storage Storage, trash *TrashPolicy, prefix string) ([]TrashEntry, error) {
+	lister, ok := storage.(ObjectLister)
+	if !ok {
+		return nil, errors.New("storage does not support listing")
+	}
+
+	var (
+		entries []TrashEntry
+		marker  string
+	)
+	for {
+		objects, next, err := lister.ListPage(ctx, trash.Prefix+prefix, marker, defaultMigratePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list trash: %w", err)
+		}
+		for _, obj := range objects {
+			entry, ok := parseTrashKey(trash.Prefix, obj.Key)
+			if !ok {
+				continue
+			}
+			entry.Size = obj.Size
+			entries = append(entries, entry)
+		}
+		if next == "" {
+			return entries, nil
+		}
+		marker = next
+	}
+}
+
+// deletePermanently 直接删除对象，不经过回收站
+func (u *UploadManager) deletePermanently(ctx context.Context, storageType string, storage Storage, path string) error {
+	start := time.Now()
+	err := u.withRetry(ctx, "delete "+path, nil, func() error {
+		return storage.Delete(ctx, path)
+	})
+	recordOperation(storageType, "delete", start, err)
+	if err == nil {
+		u.replicate(storageType, replicateJob{path: path, deleted: true})
+	}
+	return err
+}
+
+// inTrash 路径是否在回收站中
+func (t *TrashPolicy) inTrash(path string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(path, "/"), t.Prefix)
+}
+
+// trashKey 生成回收站对象key，acl 为空时不记录访问权限
+func trashKey(prefix, path string, deletedAt time.Time, acl configx.ACL) string {
+	key := prefix + strings.TrimPrefix(path, "/") + trashSeparator + strconv.FormatInt(deletedAt.Unix(), 10)
+	if acl != "" {
+		key += trashSeparator + string(acl)
+	}
+	return key
+}
+
+// parseTrashKey 解析回收站对象key
+func parseTrashKey(prefix, key string) (TrashEntry, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimPrefix(key, "/"), prefix)
+	if !ok {
+		return TrashEntry{}, false
+	}
+	name := rest
+	var acl configx.ACL
+	if i := strings.LastIndex(name, trashSeparator); i > 0 {
+		switch a := configx.ACL(name[i+len(trashSeparator):]); a {
+		case configx.ACLPublic, configx.ACLPrivate, configx.ACLAuthenticated:
+			name, acl = name[:i], a
+		}
+	}
+	i := strings.LastIndex(name, trashSeparator)
+	if i <= 0 {
+		return TrashEntry{}, false
+	}
+	ts, err := strconv.ParseInt(name[i+len(trashSeparator):], 10, 64)
+	if err != nil {
+		return TrashEntry{}, false
+	}
+	return TrashEntry{
+		Path:      name[:i],
+		TrashPath: prefix + rest,
+		DeletedAt: time.Unix(ts, 0),
+		ACL:       acl,
+	}, true
+}
+
+// copyObject 复制对象，存储不支持服务端复制时下载后重新上传
+func copyObject(ctx context.Context, storage Storage, src, dst string) error {
+	if copier, ok := storage.(ObjectCopier); ok {
+		return copier.CopyObject(ctx, src, dst)
+	}
+
+	reader, ok := storage.(ObjectReader)
+	if !ok {
+		return errors.New("storage does not support copy")
+	}
+	var contentType string
+	if rr, ok := storage.(RangeReader); ok {
+		if meta, err := rr.StatObject(ctx, src); err == nil {
+			contentType = meta.ContentType
+		}
+	}
+
+	body, err := reader.Download(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = uploadObject(ctx, storage, body, dst, contentType, configx.ACLPrivate)
+	return err
+}
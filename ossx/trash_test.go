@@ -0,0 +1,121 @@
+package ossx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+)
+
+func TestTrashKeepsACL(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		acl  configx.ACL
+		mode os.FileMode
+	}{
+		{"public", configx.ACLPublic, 0644},
+		{"private", configx.ACLPrivate, 0600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, dir := newTestManager(t)
+			u.SetTrash(&TrashPolicy{})
+
+			result, err := uploadBytes(u, 7, "a.png", testPNG, WithACL(tt.acl))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = u.Delete(ctx, Local, result.RelativePath); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := u.ListTrash(ctx, Local, "")
+			if err != nil || len(entries) != 1 || entries[0].ACL != tt.acl {
+				t.Fatalf("ListTrash = %+v, %v", entries, err)
+			}
+			// 回收站中的副本总是私有
+			info, err := os.Stat(filepath.Join(dir, entries[0].TrashPath))
+			if err != nil || info.Mode().Perm() != 0600 {
+				t.Fatalf("trash copy mode = %v, %v", info.Mode().Perm(), err)
+			}
+
+			if err = u.Restore(ctx, Local, entries[0].Path); err != nil {
+				t.Fatal(err)
+			}
+			info, err = os.Stat(filepath.Join(dir, entries[0].Path))
+			if err != nil || info.Mode().Perm() != tt.mode {
+				t.Fatalf("restored mode = %v, %v", info.Mode().Perm(), err)
+			}
+			if entries, _ = u.ListTrash(ctx, Local, ""); len(entries) != 0 {
+				t.Fatalf("trash not emptied after restore: %+v", entries)
+			}
+		})
+	}
+}
+
+func TestTrashDeleteMissing(t *testing.T) {
+	u, _ := newTestManager(t)
+	u.SetTrash(&TrashPolicy{})
+
+	if err := u.Delete(context.Background(), Local, "missing.png"); err != nil {
+		t.Fatalf("Delete missing object err = %v", err)
+	}
+}
+
+func TestHookRollbackSkipsTrash(t *testing.T) {
+	ctx := context.Background()
+	u, dir := newTestManager(t)
+	u.SetTrash(&TrashPolicy{})
+	hookErr := errors.New("hook failed")
+	var path string
+	u.AfterUpload(func(ctx context.Context, uc *UploadContext, result *UploadResult) error {
+		path = result.RelativePath
+		return hookErr
+	})
+
+	if _, err := uploadBytes(u, 7, "a.png", testPNG); !errors.Is(err, hookErr) {
+		t.Fatalf("upload err = %v, want hook error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, path)); !os.IsNotExist(err) {
+		t.Fatalf("object kept after hook failure: %v", err)
+	}
+	// 未成功的上传不进入回收站
+	if entries, err := u.ListTrash(ctx, Local, ""); err != nil || len(entries) != 0 {
+		t.Fatalf("ListTrash = %+v, %v", entries, err)
+	}
+}
+
+func TestParseTrashKey(t *testing.T) {
+	deletedAt := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		key    string
+		want   TrashEntry
+		wantOk bool
+	}{
+		{".trash/a.png~1700000000", TrashEntry{Path: "a.png", DeletedAt: deletedAt}, true},
+		{".trash/a.png~1700000000~public", TrashEntry{Path: "a.png", DeletedAt: deletedAt, ACL: configx.ACLPublic}, true},
+		{".trash/a~b.png~1700000000~private", TrashEntry{Path: "a~b.png", DeletedAt: deletedAt, ACL: configx.ACLPrivate}, true},
+		{".trash/a.png~public", TrashEntry{}, false},
+		{".trash/a.png", TrashEntry{}, false},
+		{"a.png~1700000000", TrashEntry{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTrashKey(".trash/", tt.key)
+		if ok != tt.wantOk {
+			t.Fatalf("parseTrashKey(%q) ok = %v", tt.key, ok)
+		}
+		if ok && (got.Path != tt.want.Path || !got.DeletedAt.Equal(tt.want.DeletedAt) || got.ACL != tt.want.ACL || got.TrashPath != tt.key) {
+			t.Fatalf("parseTrashKey(%q) = %+v", tt.key, got)
+		}
+		if ok && trashKey(".trash/", got.Path, got.DeletedAt, got.ACL) != tt.key {
+			t.Fatalf("trashKey round trip of %q", tt.key)
+		}
+	}
+}
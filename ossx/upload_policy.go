@@ -0,0 +1,592 @@
+package ossx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// 临时上传URL默认有效期
+	defaultUploadURLExpiration = 15 * time.Minute
+	// 每批清理的过期令牌数量
+	expiredUploadBatch = 100
+)
+
+var (
+	// ErrUploadTokenInvalid 上传令牌不存在、已使用、已过期或不属于当前用户
+	ErrUploadTokenInvalid = errors.New("upload token invalid")
+	// ErrUploadPolicyViolation 上传的对象不符合临时上传URL的约束，对象已被删除
+	ErrUploadPolicyViolation = errors.New("upload policy violation")
+	// ErrUploadIncomplete 对象尚未上传完成，令牌仍可使用
+	ErrUploadIncomplete = errors.New("upload incomplete")
+)
+
+// UploadPolicy 临时上传URL的约束
+type UploadPolicy struct {
+	// 上传用户，对象路径由上传配置的路径生成器按用户生成，客户端不能指定路径
+	UserID int64
+	// 原始文件名，用于生成对象文件名和扩展名
+	FileName string
+	// 上传必须使用的类型，需在允许列表中
+	ContentType string
+	// 声明的文件大小，上传的大小必须一致
+	Size int64
+	// 大小上限，为0时使用上传配置的 MaxSize
+	MaxSize int64
+	// 有效期，默认15分钟
+	Expiration time.Duration
+}
+
+// PresignedUpload 临时上传URL，客户端使用 Method 和 Headers 直传到存储，完成后凭 Token 调用 CompleteUpload
+type PresignedUpload struct {
+	Token     string            `json:"token"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Path      string            `json:"path"`
+	ExpiresAt int64             `json:"expires_at"`
+}
+
+// UploadGrant 上传令牌记录的约束
+type UploadGrant struct {
+	StorageType string `json:"storage_type"`
+	Path        string `json:"path"`
+	UserID      int64  `json:"user_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+// PresignedUploader 支持临时上传URL的存储，内置存储均已实现
+type PresignedUploader interface {
+	// PresignPut 生成PUT上传的签名URL，返回上传时必须携带的请求头，签名包含对象路径、类型和大小，目标已存在时拒绝上传
+	PresignPut(ctx context.Context, path, contentType string, size int64, expiration time.Duration) (string, map[string]string, error)
+}
+
+// UploadTokenStore 上传令牌存储，令牌只能使用一次
+type UploadTokenStore interface {
+	// Save 保存令牌，ttl 后过期
+	Save(ctx context.Context, token string, grant UploadGrant, ttl time.Duration) error
+	// Get 读取令牌但不删除，不存在或已过期时返回 ErrUploadTokenInvalid
+	Get(ctx context.Context, token string) (*UploadGrant, error)
+	// Consume 取出并删除令牌，不存在或已过期时返回 ErrUploadTokenInvalid
+	Consume(ctx context.Context, token string) (*UploadGrant, error)
+	// Expired 取出并删除 before 之前过期且未使用的令牌，至多 limit 个，用于清理未完成的上传
+	Expired(ctx context.Context, before time.Time, limit int) ([]UploadGrant, error)
+}
+
+// SetUploadTokenStore 设置上传令牌存储，设置后才能使用 CreateUploadURL，多实例部署需使用 NewRedisUploadTokenStore
+func (u *UploadManager) SetUploadTokenStore(store UploadTokenStore) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.uploadTokens = store
+}
+
+// uploadTokenStore 当前的上传令牌存储
+func (u *UploadManager) uploadTokenStore() UploadTokenStore {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	return u.uploadTokens
+}
+
+// CreateUploadURL 生成绑定用户和内容约束的临时上传URL，对象路径由服务端生成，类型和大小按上传配置校验
+// 客户端上传后需调用 CompleteUpload，未完成的上传不会生成上传结果，由 CleanupExpiredUploads 删除
+func (u *UploadManager) CreateUploadURL(ctx context.Context, storageType string, policy UploadPolicy) (*PresignedUpload, error) {
+	storage, ok := u.storages[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	pu, ok := storage.(PresignedUploader)
+	if !ok {
+		return nil, fmt.Errorf("storage type %s does not support presigned uploads", storageType)
+	}
+	tokens := u.uploadTokenStore()
+	if tokens == nil {
+		return nil, errors.New("upload token store not set")
+	}
+
+	contentType, _, _ := mime.ParseMediaType(policy.ContentType)
+	if !u.uploadConfig.AllowedTypes[contentType] {
+		return nil, fmt.Errorf("file validation failed: 不支持的文件类型: %s", policy.ContentType)
+	}
+	maxSize := policy.MaxSize
+	if maxSize <= 0 || (u.uploadConfig.MaxSize > 0 && maxSize > u.uploadConfig.MaxSize) {
+		maxSize = u.uploadConfig.MaxSize
+	}
+	if policy.Size <= 0 || (maxSize > 0 && policy.Size > maxSize) {
+		return nil, fmt.Errorf("file validation failed: 文件大小超过限制，最大允许 %d 字节", maxSize)
+	}
+	expiration := policy.Expiration
+	if expiration <= 0 {
+		expiration = defaultUploadURLExpiration
+	}
+
+	fileType := configx.DetectFileType(contentType)
+	path := u.uploadConfig.PathGenerator(policy.UserID, fileType, u.remoteFileName(policy.FileName, contentType, fileType))
+	path = strings.TrimPrefix(path, "/")
+
+	signedURL, headers, err := pu.PresignPut(ctx, path, contentType, policy.Size, expiration)
+	if err != nil {
+		recordSignedURL(storageType, err)
+		return nil, fmt.Errorf("failed to create upload url: %w", err)
+	}
+	recordSignedURL(storageType, nil)
+
+	token, err := newUploadToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(expiration)
+	grant := UploadGrant{
+		StorageType: storageType,
+		Path:        path,
+		UserID:      policy.UserID,
+		FileName:    policy.FileName,
+		ContentType: contentType,
+		Size:        policy.Size,
+		ExpiresAt:   expiresAt.Unix(),
+	}
+	// 令牌比URL多保留一段时间，URL过期前开始的上传仍可完成
+	if err = tokens.Save(ctx, token, grant, expiration+time.Minute); err != nil {
+		return nil, fmt.Errorf("failed to save upload token: %w", err)
+	}
+
+	return &PresignedUpload{
+		Token:     token,
+		URL:       signedURL,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		Path:      "/" + path,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+// CompleteUpload 完成临时URL上传，令牌需属于 userID，对象尚未上传时返回 ErrUploadIncomplete 且令牌仍可使用，
+// 校验对象的大小、类型和文件头并执行上传钩子和配额，全部通过后才使用令牌；不符合约束时删除对象并返回 ErrUploadPolicyViolation
+func (u *UploadManager) CompleteUpload(ctx context.Context, userID int64, token string) (result *UploadResult, err error) {
+	tokens := u.uploadTokenStore()
+	if tokens == nil {
+		return nil, errors.New("upload token store not set")
+	}
+	grant, err := tokens.Get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if grant.UserID != userID {
+		return nil, fmt.Errorf("%w: user mismatch", ErrUploadTokenInvalid)
+	}
+
+	storage, ok := u.storages[grant.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", grant.StorageType)
+	}
+	rr, ok := storage.(RangeReader)
+	if !ok {
+		return nil, errors.New("storage does not support stat")
+	}
+
+	meta, err := rr.StatObject(ctx, grant.Path)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, ErrUploadIncomplete
+		}
+		return nil, fmt.Errorf("failed to stat uploaded object: %w", err)
+	}
+
+	uc := &UploadContext{
+		StorageType: grant.StorageType,
+		UserID:      grant.UserID,
+		FileName:    grant.FileName,
+		ContentType: grant.ContentType,
+		Size:        meta.Size,
+		Path:        grant.Path,
+		Values:      make(map[string]any),
+	}
+	hooks := u.uploadHooks()
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			hooks.runOnError(ctx, uc, err)
+		}
+		recordUpload(grant.StorageType, start, result, err)
+	}()
+
+	// 不符合约束的对象删除后使用令牌，避免重复校验
+	reject := func(cause error) error {
+		if derr := storage.Delete(ctx, grant.Path); derr != nil && !isNotFound(derr) {
+			logx.WithContext(ctx).Errorf("failed to delete object %s violating upload policy: %v", grant.Path, derr)
+		}
+		if _, cerr := tokens.Consume(ctx, token); cerr != nil && !errors.Is(cerr, ErrUploadTokenInvalid) {
+			logx.WithContext(ctx).Errorf("failed to consume upload token: %v", cerr)
+		}
+		return cause
+	}
+
+	if violation := grant.check(meta); violation != "" {
+		return nil, reject(fmt.Errorf("%w: %s", ErrUploadPolicyViolation, violation))
+	}
+	if err = u.verifyUploadedContent(ctx, rr, grant); err != nil {
+		return nil, reject(err)
+	}
+	if err = u.runCompleteHooks(ctx, hooks, storage, rr, uc); err != nil {
+		return nil, reject(err)
+	}
+
+	// 占用用户配额，令牌已被使用或后续失败时归还
+	quota := u.QuotaTracker()
+	if quota != nil {
+		if err = quota.Reserve(ctx, grant.UserID, uc.Size); err != nil {
+			return nil, reject(err)
+		}
+	}
+	releaseQuota := func() {
+		if quota != nil {
+			if qerr := quota.Release(ctx, grant.UserID, uc.Size); qerr != nil {
+				logx.WithContext(ctx).Errorf("failed to release quota: %v", qerr)
+			}
+		}
+	}
+
+	// 校验通过后才使用令牌，并发完成同一令牌时只有一个成功
+	if _, err = tokens.Consume(ctx, token); err != nil {
+		releaseQuota()
+		return nil, err
+	}
+
+	result = &UploadResult{
+		RelativePath: "/" + grant.Path,
+		FileName:     filepath.Base(grant.Path),
+		OriginalName: grant.FileName,
+		FileType:     configx.DetectFileType(grant.ContentType),
+		Size:         uc.Size,
+		ETag:         meta.ETag,
+		StorageType:  grant.StorageType,
+	}
+	// 直传的对象为私有，使用签名URL访问
+	signedURL, serr := u.createSignedURL(ctx, grant.StorageType, storage, grant.Path, 24*time.Hour)
+	if serr != nil {
+		logx.WithContext(ctx).Errorf("failed to generate signed URL: %v", serr)
+	} else if signedURL != "" {
+		result.URL, result.SignedURL = signedURL, signedURL
+		result.SignedURLExpire = time.Now().Add(24 * time.Hour).Unix()
+	}
+
+	// 执行上传后钩子，失败时删除对象
+	if err = hooks.runAfterUpload(ctx, uc, result); err != nil {
+		if derr := storage.Delete(ctx, grant.Path); derr != nil {
+			logx.WithContext(ctx).Errorf("failed to delete %s after hook failure: %v", grant.Path, derr)
+		}
+		releaseQuota()
+		return nil, err
+	}
+
+	if quota != nil {
+		quota.own(ctx, quotaObject(grant.StorageType, grant.Path), grant.UserID, uc.Size)
+	}
+	u.replicate(grant.StorageType, replicateJob{path: grant.Path, contentType: grant.ContentType, acl: configx.ACLPrivate})
+	return result, nil
+}
+
+// verifyUploadedContent 开启 SniffContent 时按文件头校验直传对象的实际类型，与声明的类型不一致时拒绝
+func (u *UploadManager) verifyUploadedContent(ctx context.Context, rr RangeReader, grant *UploadGrant) error {
+	if !u.uploadConfig.SniffContent {
+		return nil
+	}
+	body, err := rr.DownloadRange(ctx, grant.Path, 0, configx.SniffLen)
+	if err != nil {
+		return fmt.Errorf("failed to read uploaded object: %w", err)
+	}
+	defer body.Close()
+	head, err := io.ReadAll(io.LimitReader(body, configx.SniffLen))
+	if err != nil {
+		return fmt.Errorf("failed to read uploaded object: %w", err)
+	}
+
+	contentType, err := u.uploadConfig.ValidateContent(grant.FileName, grant.ContentType, head)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUploadPolicyViolation, err)
+	}
+	if contentType != grant.ContentType {
+		return fmt.Errorf("%w: content type %s, declared %s", ErrUploadPolicyViolation, contentType, grant.ContentType)
+	}
+	return nil
+}
+
+// runCompleteHooks 对直传的对象执行上传前钩子，钩子替换内容时覆盖写入处理后的内容
+func (u *UploadManager) runCompleteHooks(ctx context.Context, hooks uploadHooks, storage Storage, rr RangeReader, uc *UploadContext) error {
+	if len(hooks.before) == 0 {
+		return nil
+	}
+	body, err := rr.DownloadRange(ctx, uc.Path, 0, -1)
+	if err != nil {
+		return fmt.Errorf("failed to read uploaded object: %w", err)
+	}
+	defer body.Close()
+
+	uc.Reader = body
+	if err = hooks.runBeforeUpload(ctx, uc); err != nil {
+		return err
+	}
+	if uc.Reader == io.Reader(body) {
+		return nil
+	}
+	// 替换后的内容需与声明的类型一致
+	if ct, _, _ := mime.ParseMediaType(uc.ContentType); !u.uploadConfig.AllowedTypes[ct] {
+		return fmt.Errorf("file validation failed: 不支持的文件类型: %s", uc.ContentType)
+	}
+	if _, err = uploadObject(ctx, storage, uc.Reader, uc.Path, uc.ContentType, configx.ACLPrivate); err != nil {
+		return fmt.Errorf("failed to upload processed file: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpiredUploads 删除令牌已过期但未完成的直传对象，返回删除的数量，需定期调用
+func (u *UploadManager) CleanupExpiredUploads(ctx context.Context) (int, error) {
+	tokens := u.uploadTokenStore()
+	if tokens == nil {
+		return 0, errors.New("upload token store not set")
+	}
+
+	deleted := 0
+	for {
+		grants, err := tokens.Expired(ctx, time.Now(), expiredUploadBatch)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list expired upload tokens: %w", err)
+		}
+		for _, grant := range grants {
+			storage, ok := u.storages[grant.StorageType]
+			if !ok {
+				continue
+			}
+			if err = storage.Delete(ctx, grant.Path); err != nil {
+				if !isNotFound(err) {
+					logx.WithContext(ctx).Errorf("failed to delete incomplete upload %s: %v", grant.Path, err)
+				}
+				continue
+			}
+			deleted++
+		}
+		if len(grants) < expiredUploadBatch {
+			return deleted, nil
+		}
+	}
+}
+
+// check 对象是否符合约束，不符合时返回原因
+func (g *UploadGrant) check(meta *ObjectMeta) string {
+	if meta.Size != g.Size {
+		return fmt.Sprintf("size %d, declared %d", meta.Size, g.Size)
+	}
+	// 本地存储不记录类型，由上传处理器校验
+	if meta.ContentType != "" {
+		if ct, _, _ := mime.ParseMediaType(meta.ContentType); ct != g.ContentType {
+			return fmt.Sprintf("content type %s, declared %s", meta.ContentType, g.ContentType)
+		}
+	}
+	return ""
+}
+
+// newUploadToken 生成随机的上传令牌
+func newUploadToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// memoryUploadTokenStore 基于内存的上传令牌存储
+type memoryUploadTokenStore struct {
+	mu     sync.Mutex
+	grants map[string]memoryUploadGrant
+}
+
+type memoryUploadGrant struct {
+	grant    UploadGrant
+	expireAt time.Time
+}
+
+// NewMemoryUploadTokenStore 创建内存上传令牌存储，仅适用于单实例
+func NewMemoryUploadTokenStore() UploadTokenStore {
+	return &memoryUploadTokenStore{grants: make(map[string]memoryUploadGrant)}
+}
+
+// Save 保存令牌
+func (m *memoryUploadTokenStore) Save(ctx context.Context, token string, grant UploadGrant, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grants[token] = memoryUploadGrant{grant: grant, expireAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Get 读取令牌
+func (m *memoryUploadTokenStore) Get(ctx context.Context, token string) (*UploadGrant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.grants[token]
+	if !ok || time.Now().After(v.expireAt) {
+		return nil, ErrUploadTokenInvalid
+	}
+	return &v.grant, nil
+}
+
+// Consume 取出并删除令牌，过期的令牌保留给 Expired 清理
+func (m *memoryUploadTokenStore) Consume(ctx context.Context, token string) (*UploadGrant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.grants[token]
+	if !ok || time.Now().After(v.expireAt) {
+		return nil, ErrUploadTokenInvalid
+	}
+	delete(m.grants, token)
+	return &v.grant, nil
+}
+
+// Expired 取出并删除 before 之前过期的令牌
+func (m *memoryUploadTokenStore) Expired(ctx context.Context, before time.Time, limit int) ([]UploadGrant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var grants []UploadGrant
+	for k, v := range m.grants {
+		if len(grants) >= limit {
+			break
+		}
+		if v.expireAt.Before(before) {
+			grants = append(grants, v.grant)
+			delete(m.grants, k)
+		}
+	}
+	return grants, nil
+}
+
+// 使用令牌：未过期时删除令牌及过期索引并返回令牌内容
+var consumeUploadTokenScript = redis.NewScript(`
+local g = redis.call('HGET', KEYS[1], ARGV[1])
+if not g then
+	return false
+end
+local exp = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if exp and tonumber(exp) < tonumber(ARGV[2]) then
+	return false
+end
+if ARGV[3] == '1' then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+	redis.call('ZREM', KEYS[2], ARGV[1])
+end
+return g
+`)
+
+// 取出过期令牌：按过期时间取出至多 ARGV[2] 个并删除
+var popExpiredUploadTokensScript = redis.NewScript(`
+local tokens = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+local grants = {}
+for _, token in ipairs(tokens) do
+	local g = redis.call('HGET', KEYS[1], token)
+	if g then
+		table.insert(grants, g)
+	end
+	redis.call('HDEL', KEYS[1], token)
+	redis.call('ZREM', KEYS[2], token)
+end
+return grants
+`)
+
+// redisUploadTokenStore 基于Redis的上传令牌存储，令牌保存在哈希中，按过期时间索引以便清理未完成的上传
+type redisUploadTokenStore struct {
+	rdb       redis.UniversalClient
+	grantsKey string
+	expiryKey string
+}
+
+// NewRedisUploadTokenStore 创建Redis上传令牌存储，使用脚本保证令牌只能使用一次
+func NewRedisUploadTokenStore(rdb redis.UniversalClient, prefix string) UploadTokenStore {
+	if prefix == "" {
+		prefix = "ossx:upload_token:"
+	}
+	// 使用相同的hash tag，集群模式下脚本访问的键位于同一slot
+	return &redisUploadTokenStore{
+		rdb:       rdb,
+		grantsKey: prefix + "{tokens}:grants",
+		expiryKey: prefix + "{tokens}:expiry",
+	}
+}
+
+// Save 保存令牌
+func (r *redisUploadTokenStore) Save(ctx context.Context, token string, grant UploadGrant, ttl time.Duration) error {
+	data, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, r.grantsKey, token, data)
+		pipe.ZAdd(ctx, r.expiryKey, redis.Z{Score: float64(time.Now().Add(ttl).Unix()), Member: token})
+		return nil
+	})
+	return err
+}
+
+// Get 读取令牌
+func (r *redisUploadTokenStore) Get(ctx context.Context, token string) (*UploadGrant, error) {
+	return r.load(ctx, token, false)
+}
+
+// Consume 取出并删除令牌
+func (r *redisUploadTokenStore) Consume(ctx context.Context, token string) (*UploadGrant, error) {
+	return r.load(ctx, token, true)
+}
+
+// load 读取未过期的令牌，consume 为true时删除
+func (r *redisUploadTokenStore) load(ctx context.Context, token string, consume bool) (*UploadGrant, error) {
+	flag := "0"
+	if consume {
+		flag = "1"
+	}
+	data, err := consumeUploadTokenScript.Run(ctx, r.rdb, []string{r.grantsKey, r.expiryKey},
+		token, time.Now().Unix(), flag).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrUploadTokenInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var grant UploadGrant
+	if err = json.Unmarshal([]byte(data), &grant); err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// Expired 取出并删除 before 之前过期的令牌
+func (r *redisUploadTokenStore) Expired(ctx context.Context, before time.Time, limit int) ([]UploadGrant, error) {
+	values, err := popExpiredUploadTokensScript.Run(ctx, r.rdb, []string{r.grantsKey, r.expiryKey},
+		before.Unix(), limit).StringSlice()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	grants := make([]UploadGrant, 0, len(values))
+	for _, v := range values {
+		var grant UploadGrant
+		if err = json.Unmarshal([]byte(v), &grant); err != nil {
+			logx.Errorf("failed to decode expired upload token: %v", err)
+			continue
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
@@ -0,0 +1,211 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// putSigned 按临时上传URL的要求上传内容
+func putSigned(t *testing.T, srv *httptest.Server, pu *PresignedUpload, body []byte) int {
+	t.Helper()
+	req, err := http.NewRequest(pu.Method, srv.URL+pu.URL, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range pu.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestCompleteUpload(t *testing.T) {
+	ctx := context.Background()
+	policy := UploadPolicy{UserID: 7, FileName: "a.png", ContentType: "image/png", Size: int64(len(testPNG))}
+
+	t.Run("complete after upload", func(t *testing.T) {
+		u, dir := newTestManager(t)
+		u.SetUploadTokenStore(NewMemoryUploadTokenStore())
+		quota := NewQuotaTracker(nil, QuotaLimit{MaxObjects: 10}, WithQuotaStore(NewMemoryQuotaStore()))
+		u.SetQuotaTracker(quota)
+		var hooked bool
+		u.BeforeUpload(func(ctx context.Context, uc *UploadContext) error {
+			hooked = uc.UserID == 7 && uc.Reader != nil
+			return nil
+		})
+		srv := httptest.NewServer(u.ServeSignedUpload(Local))
+		defer srv.Close()
+
+		pu, err := u.CreateUploadURL(ctx, Local, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 上传前完成不会使用令牌
+		if _, err = u.CompleteUpload(ctx, 7, pu.Token); !errors.Is(err, ErrUploadIncomplete) {
+			t.Fatalf("complete before upload err = %v", err)
+		}
+		if code := putSigned(t, srv, pu, testPNG); code != http.StatusOK {
+			t.Fatalf("put status = %d", code)
+		}
+		// 同一URL不能覆盖已上传的对象
+		if code := putSigned(t, srv, pu, testPNG); code != http.StatusConflict {
+			t.Fatalf("second put status = %d", code)
+		}
+		if _, err = u.CompleteUpload(ctx, 8, pu.Token); !errors.Is(err, ErrUploadTokenInvalid) {
+			t.Fatalf("other user err = %v", err)
+		}
+
+		result, err := u.CompleteUpload(ctx, 7, pu.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RelativePath != pu.Path || result.Size != int64(len(testPNG)) {
+			t.Fatalf("result = %+v", result)
+		}
+		if !hooked {
+			t.Fatal("before upload hook not run")
+		}
+		if usage, _ := quota.Usage(ctx, 7); usage.Objects != 1 || usage.Bytes != int64(len(testPNG)) {
+			t.Fatalf("quota usage = %+v", usage)
+		}
+		if _, err = os.Stat(filepath.Join(dir, pu.Path)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = u.CompleteUpload(ctx, 7, pu.Token); !errors.Is(err, ErrUploadTokenInvalid) {
+			t.Fatalf("reused token err = %v", err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		sniff   bool
+		content []byte
+		hook    BeforeUploadHook
+	}{
+		{name: "size mismatch", content: append(bytes.Clone(testPNG), 0)},
+		{name: "content mismatch", sniff: true, content: bytes.Repeat([]byte("a"), len(testPNG))},
+		{name: "hook rejected", content: testPNG, hook: func(ctx context.Context, uc *UploadContext) error {
+			return errors.New("virus found")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, dir := newTestManager(t)
+			u.SetUploadTokenStore(NewMemoryUploadTokenStore())
+			u.uploadConfig.SniffContent = tt.sniff
+			if tt.hook != nil {
+				u.BeforeUpload(tt.hook)
+			}
+
+			pu, err := u.CreateUploadURL(ctx, Local, policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = u.storages[Local].Upload(ctx, bytes.NewReader(tt.content), pu.Path, "image/png"); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err = u.CompleteUpload(ctx, 7, pu.Token); err == nil {
+				t.Fatal("expected error")
+			}
+			if _, err = os.Stat(filepath.Join(dir, pu.Path)); !os.IsNotExist(err) {
+				t.Fatalf("rejected object not deleted: %v", err)
+			}
+			if _, err = u.CompleteUpload(ctx, 7, pu.Token); !errors.Is(err, ErrUploadTokenInvalid) {
+				t.Fatalf("token not consumed: %v", err)
+			}
+		})
+	}
+}
+
+func TestCleanupExpiredUploads(t *testing.T) {
+	ctx := context.Background()
+	u, dir := newTestManager(t)
+	store := NewMemoryUploadTokenStore()
+	u.SetUploadTokenStore(store)
+
+	grant := UploadGrant{StorageType: Local, Path: "uploads/orphan.png", UserID: 7}
+	if err := store.Save(ctx, "expired", grant, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, "pending", UploadGrant{StorageType: Local, Path: "uploads/pending.png"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"uploads/orphan.png", "uploads/pending.png"} {
+		if _, err := u.storages[Local].Upload(ctx, bytes.NewReader(testPNG), p, "image/png"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := u.CleanupExpiredUploads(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("cleanup = %d, %v", n, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "uploads/orphan.png")); !os.IsNotExist(err) {
+		t.Fatalf("orphan not deleted: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "uploads/pending.png")); err != nil {
+		t.Fatalf("pending upload deleted: %v", err)
+	}
+}
+
+func TestUploadTokenStores(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	stores := map[string]UploadTokenStore{
+		"memory": NewMemoryUploadTokenStore(),
+		"redis":  NewRedisUploadTokenStore(rdb, ""),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			grant := UploadGrant{StorageType: Local, Path: "a.png", UserID: 7}
+			if err := store.Save(ctx, "t1", grant, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Save(ctx, "t2", grant, -time.Second); err != nil {
+				t.Fatal(err)
+			}
+
+			if g, err := store.Get(ctx, "t1"); err != nil || g.Path != "a.png" {
+				t.Fatalf("Get = %+v, %v", g, err)
+			}
+			if _, err := store.Get(ctx, "t2"); !errors.Is(err, ErrUploadTokenInvalid) {
+				t.Fatalf("Get expired err = %v", err)
+			}
+			if _, err := store.Consume(ctx, "t1"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Consume(ctx, "t1"); !errors.Is(err, ErrUploadTokenInvalid) {
+				t.Fatalf("second Consume err = %v", err)
+			}
+			if _, err := store.Consume(ctx, "t2"); !errors.Is(err, ErrUploadTokenInvalid) {
+				t.Fatalf("Consume expired err = %v", err)
+			}
+
+			expired, err := store.Expired(ctx, time.Now(), 10)
+			if err != nil || len(expired) != 1 || expired[0].Path != "a.png" {
+				t.Fatalf("Expired = %+v, %v", expired, err)
+			}
+			if expired, _ = store.Expired(ctx, time.Now(), 10); len(expired) != 0 {
+				t.Fatalf("Expired twice = %+v", expired)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package ossx
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/QuantumShiftX/golib/httpclient"
+	configx "github.com/QuantumShiftX/golib/ossx/config"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 默认拉取远程文件的超时时间
+const defaultFetchTimeout = 60 * time.Second
+
+// 常见类型的扩展名，mime.ExtensionsByType 按字母排序，首个扩展名不一定常用
+var preferredExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"video/mp4":  ".mp4",
+	"text/plain": ".txt",
+}
+
+// 默认拉取远程文件的客户端，开启SSRF防护
+var defaultFetchClient = httpclient.NewSafeHTTPClient(defaultFetchTimeout)
+
+// ErrRemoteFileTooLarge 远程文件超过上传大小限制
+var ErrRemoteFileTooLarge = errors.New("remote file too large")
+
+// SetFetchClient 设置 UploadFromURL 拉取远程文件使用的HTTP客户端，
+// 默认使用 httpclient.NewSafeHTTPClient，拦截内网地址并校验重定向目标，替换时应保留SSRF防护
+func (u *UploadManager) SetFetchClient(client *http.Client) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.fetchClient = client
+}
+
+// UploadFromURL 拉取远程资源并上传到存储，见 UploadFromURLWithUid，上传用户为0
+func (u *UploadManager) UploadFromURL(ctx context.Context, storageType, sourceURL, path string, opts ...UploadOption) (*UploadResult, error) {
+	return u.UploadFromURLWithUid(ctx, storageType, sourceURL, path, 0, opts...)
+}
+
+// UploadFromURLWithUid 拉取远程资源并上传到存储，拉取失败时按重试策略重新拉取
+// 远程文件先流式写入临时文件，不在内存中缓存整个文件，超过上传配置的大小限制时立即终止；
+// 之后与 Upload 相同地校验类型、执行钩子、占用配额和去重，扩展名按校验后的类型确定
+// path 为空时按上传配置的路径生成器生成
+func (u *UploadManager) UploadFromURLWithUid(ctx context.Context, storageType, sourceURL, path string, userId int64, opts ...UploadOption) (*UploadResult, error) {
+	if _, ok := u.storages[storageType]; !ok {
+		return nil, fmt.Errorf("storage type %s not initialized", storageType)
+	}
+	src, err := url.Parse(sourceURL)
+	if err != nil || (src.Scheme != "http" && src.Scheme != "https") || src.Host == "" {
+		return nil, fmt.Errorf("invalid source url: %s", sourceURL)
+	}
+
+	var (
+		file        *os.File
+		contentType string
+	)
+	start := time.Now()
+	err = u.withRetry(ctx, "fetch "+src.Redacted(), nil, func() (err error) {
+		file, contentType, err = u.fetchRemote(ctx, sourceURL)
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to upload from url: %w", err)
+		u.uploadHooks().runOnError(ctx, &UploadContext{StorageType: storageType, UserID: userId, FileName: remoteBaseName(src.Path)}, err)
+		recordUpload(storageType, start, nil, err)
+		return nil, err
+	}
+	defer removeTempFile(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat fetched file: %w", err)
+	}
+	header := &multipart.FileHeader{
+		Filename: remoteFileNameWithExt(src.Path, contentType),
+		Size:     info.Size(),
+		Header:   textproto.MIMEHeader{"Content-Type": {contentType}},
+	}
+	if path != "" {
+		opts = append(opts[:len(opts):len(opts)], withObjectPath(path))
+	}
+	return u.Upload(ctx, storageType, file, header, userId, opts...)
+}
+
+// fetchRemote 发起GET请求并校验大小和类型，响应体写入临时文件，返回读取位置在起始处的临时文件和类型
+func (u *UploadManager) fetchRemote(ctx context.Context, sourceURL string) (*os.File, string, error) {
+	u.mu.RLock()
+	client := u.fetchClient
+	u.mu.RUnlock()
+	if client == nil {
+		client = defaultFetchClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", &fetchStatusError{url: req.URL.Redacted(), code: resp.StatusCode}
+	}
+
+	maxSize := u.uploadConfig.MaxSize
+	if maxSize > 0 && resp.ContentLength > maxSize {
+		return nil, "", fmt.Errorf("%w: %d bytes, max %d", ErrRemoteFileTooLarge, resp.ContentLength, maxSize)
+	}
+
+	// 响应未声明类型时按内容检测，开启 SniffContent 时按文件头校验
+	br := bufio.NewReader(resp.Body)
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if contentType == "" || contentType == "application/octet-stream" {
+		head, _ := br.Peek(512)
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+	if u.uploadConfig.SniffContent {
+		head, _ := br.Peek(configx.SniffLen)
+		if contentType, err = u.uploadConfig.ValidateContent(remoteBaseName(resp.Request.URL.Path), contentType, head); err != nil {
+			return nil, "", fmt.Errorf("file validation failed: %w", err)
+		}
+	}
+	if !u.uploadConfig.AllowedTypes[contentType] {
+		return nil, "", fmt.Errorf("file validation failed: 不支持的文件类型: %s", contentType)
+	}
+
+	var body io.Reader = br
+	if maxSize > 0 {
+		body = &limitedReader{r: br, remaining: maxSize}
+	}
+	file, err := os.CreateTemp("", "ossx-fetch-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err = io.Copy(file, body); err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeTempFile(file)
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", req.URL.Redacted(), err)
+	}
+	return file, contentType, nil
+}
+
+// removeTempFile 关闭并删除临时文件
+func removeTempFile(file *os.File) {
+	_ = file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		logx.Errorf("failed to remove temp file %s: %v", file.Name(), err)
+	}
+}
+
+// remoteFileName 按文件名策略生成远程文件的文件名，流式上传时无法预先计算内容哈希，需要内容哈希的策略使用UUID命名
+func (u *UploadManager) remoteFileName(urlPath, contentType, fileType string) string {
+	ext := remoteExt(urlPath, contentType)
+	if u.uploadConfig.NeedsContentHash() {
+		return uuid.NewString() + ext
+	}
+	return u.uploadConfig.GenerateName(configx.NameInfo{
+		OriginalName: remoteBaseName(urlPath),
+		Ext:          ext,
+		ContentType:  contentType,
+		FileType:     fileType,
+	})
+}
+
+// remoteFileNameWithExt URL中的文件名，扩展名替换为校验后的类型对应的扩展名
+func remoteFileNameWithExt(urlPath, contentType string) string {
+	name := remoteBaseName(urlPath)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "" {
+		name = "file"
+	}
+	return name + remoteExt(urlPath, contentType)
+}
+
+// remoteBaseName URL路径中的文件名，没有时为空
+func remoteBaseName(urlPath string) string {
+	if i := strings.LastIndex(urlPath, "/"); i >= 0 {
+		urlPath = urlPath[i+1:]
+	}
+	return urlPath
+}
+
+// remoteExt 按类型确定扩展名，URL中的扩展名与类型一致时使用URL中的扩展名，
+// 避免以 .html 等扩展名保存声明为图片的内容
+func remoteExt(urlPath, contentType string) string {
+	if ext := strings.ToLower(filepath.Ext(urlPath)); ext != "" && len(ext) <= 6 {
+		if byExt, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext)); byExt == contentType {
+			return ext
+		}
+	}
+	if ext, ok := preferredExts[contentType]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// fetchStatusError 远程资源返回非200状态码
+type fetchStatusError struct {
+	url  string
+	code int
+}
+
+func (e *fetchStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch %s: status %d", e.url, e.code)
+}
+
+// HTTPStatusCode 远程资源的状态码，5xx和429可重试
+func (e *fetchStatusError) HTTPStatusCode() int {
+	return e.code
+}
+
+// limitedReader 超过大小限制时返回 ErrRemoteFileTooLarge，避免截断后写入不完整的文件
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrRemoteFileTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrRemoteFileTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,105 @@
+package ossx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/httpclient"
+)
+
+// 最小的PNG文件头
+var testPNG = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+func TestUploadFromURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/avatar.html", http.StatusFound)
+		case "/large":
+			w.Header().Set("Content-Type", "image/png")
+			w.(http.Flusher).Flush()
+			for i := 0; i < 4; i++ {
+				_, _ = w.Write(testPNG)
+			}
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(testPNG)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	t.Run("ssrf guard by default", func(t *testing.T) {
+		u, _ := newTestManager(t)
+		if _, err := u.UploadFromURL(ctx, Local, srv.URL+"/a.png", ""); !errors.Is(err, httpclient.ErrPrivateAddress) {
+			t.Fatalf("err = %v", err)
+		}
+	})
+
+	t.Run("extension from content type", func(t *testing.T) {
+		u, dir := newTestManager(t)
+		u.SetFetchClient(srv.Client())
+		quota := NewQuotaTracker(nil, QuotaLimit{MaxObjects: 10}, WithQuotaStore(NewMemoryQuotaStore()))
+		u.SetQuotaTracker(quota)
+		var hooked bool
+		u.BeforeUpload(func(ctx context.Context, uc *UploadContext) error {
+			hooked = uc.UserID == 7 && uc.ContentType == "image/png"
+			return nil
+		})
+
+		result, err := u.UploadFromURLWithUid(ctx, Local, srv.URL+"/redirect", "", 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(result.RelativePath, ".png") || result.Size != int64(len(testPNG)) {
+			t.Fatalf("result = %+v", result)
+		}
+		if !hooked {
+			t.Fatal("before upload hook not run")
+		}
+		if usage, _ := quota.Usage(ctx, 7); usage.Objects != 1 || usage.Bytes != int64(len(testPNG)) {
+			t.Fatalf("quota usage = %+v", usage)
+		}
+		if _, err = os.Stat(filepath.Join(dir, result.RelativePath)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("explicit path", func(t *testing.T) {
+		u, dir := newTestManager(t)
+		u.SetFetchClient(srv.Client())
+		result, err := u.UploadFromURL(ctx, Local, srv.URL+"/a.png", "imports/a.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.RelativePath != "/imports/a.png" {
+			t.Fatalf("path = %s", result.RelativePath)
+		}
+		if _, err = os.Stat(filepath.Join(dir, "imports/a.png")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("size overrun", func(t *testing.T) {
+		u, dir := newTestManager(t)
+		u.SetFetchClient(srv.Client())
+		u.uploadConfig.MaxSize = int64(2 * len(testPNG))
+		if _, err := u.UploadFromURL(ctx, Local, srv.URL+"/large", "imports/large.png"); !errors.Is(err, ErrRemoteFileTooLarge) {
+			t.Fatalf("err = %v", err)
+		}
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				t.Fatalf("unexpected file %s", path)
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,304 @@
+package gormx
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+const (
+	// 缓存键前缀
+	defaultCachePrefix = "gormx:cache:"
+	// 缓存过期时间
+	defaultCacheTTL = 5 * time.Minute
+)
+
+// QueryCache 查询结果缓存，以SQL语句哈希为键读穿Redis
+// 失效基于标签版本号：每个标签在Redis中维护一个版本号，缓存键包含所有标签的当前版本，
+// 写操作递增版本号后旧缓存不再命中，随TTL自然过期
+type QueryCache struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// CacheOption 查询缓存选项
+type CacheOption func(c *QueryCache)
+
+// WithCachePrefix 设置缓存键前缀
+func WithCachePrefix(prefix string) CacheOption {
+	return func(c *QueryCache) {
+		c.prefix = prefix
+	}
+}
+
+// WithCacheTTL 设置缓存过期时间
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *QueryCache) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// NewQueryCache 创建查询缓存，rdb 为空时不缓存，查询直接访问数据库
+func NewQueryCache(rdb redis.UniversalClient, opts ...CacheOption) *QueryCache {
+	c := &QueryCache{
+		rdb:    rdb,
+		prefix: defaultCachePrefix,
+		ttl:    defaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Query 读穿缓存查询：fn 须包含终结操作（如 Find(dest)、First(dest)），未命中时执行并缓存 dest
+// 记录不存在的错误不缓存；Redis不可用时直接查询数据库；事务中的查询可能读到未提交的数据，不读写缓存
+func (c *QueryCache) Query(ctx context.Context, db *gorm.DB, dest any, tags []string, fn func(tx *gorm.DB) *gorm.DB) error {
+	db = db.WithContext(ctx)
+	if c == nil || c.rdb == nil || inTransaction(db) {
+		return fn(db).Error
+	}
+	sql := db.ToSQL(fn)
+
+	key, err := c.key(ctx, sql, tags)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("gormx cache: build key failed: %v", err)
+		return fn(db).Error
+	}
+
+	data, err := c.rdb.Get(ctx, key).Bytes()
+	if err == nil {
+		if err = json.Unmarshal(data, dest); err == nil {
+			return nil
+		}
+		logx.WithContext(ctx).Errorf("gormx cache: decode %s failed: %v", key, err)
+	} else if !errors.Is(err, redis.Nil) {
+		logx.WithContext(ctx).Errorf("gormx cache: get %s failed: %v", key, err)
+	}
+
+	if err := fn(db).Error; err != nil {
+		return err
+	}
+
+	if data, err = json.Marshal(dest); err != nil {
+		logx.WithContext(ctx).Errorf("gormx cache: encode %s failed: %v", key, err)
+		return nil
+	}
+	if err := c.rdb.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		logx.WithContext(ctx).Errorf("gormx cache: set %s failed: %v", key, err)
+	}
+	return nil
+}
+
+// Invalidate 使带有指定标签的缓存失效
+func (c *QueryCache) Invalidate(ctx context.Context, tags ...string) error {
+	if c == nil || c.rdb == nil || len(tags) == 0 {
+		return nil
+	}
+
+	pipe := c.rdb.Pipeline()
+	for _, tag := range tags {
+		pipe.Incr(ctx, c.tagKey(tag))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("invalidate cache tags %v: %w", tags, err)
+	}
+	return nil
+}
+
+// key 生成缓存键：SQL语句与标签版本号的哈希
+func (c *QueryCache) key(ctx context.Context, sql string, tags []string) (string, error) {
+	h := sha1.New()
+	h.Write([]byte(sql))
+
+	if len(tags) > 0 {
+		keys := make([]string, len(tags))
+		for i, tag := range tags {
+			keys[i] = c.tagKey(tag)
+		}
+		versions, err := c.rdb.MGet(ctx, keys...).Result()
+		if err != nil {
+			return "", err
+		}
+		for i, v := range versions {
+			fmt.Fprintf(h, "|%s=%v", tags[i], v)
+		}
+	}
+
+	return c.prefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// tagKey 标签版本号键
+func (c *QueryCache) tagKey(tag string) string {
+	return c.prefix + "tag:" + tag
+}
+
+// inTransaction 连接是否处于事务中
+func inTransaction(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// pendingTags 事务中待提交后失效的标签
+type pendingTags struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+// add 记录待失效的标签
+func (p *pendingTags) add(tags ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tags = append(p.tags, tags...)
+}
+
+// Repo 带查询缓存的通用仓储，读操作走缓存，写操作成功后按标签失效
+// 事务中的写入请使用 Transaction，提交后才失效，避免并发读在提交前重新缓存旧数据
+type Repo[T any] struct {
+	db      *gorm.DB
+	cache   *QueryCache
+	tags    []string
+	pending *pendingTags // Transaction 中的仓储，写入后的失效推迟到提交后
+}
+
+// NewRepo 创建仓储，tags 为空时使用模型的表名作为失效标签
+// cache 为空时不使用缓存
+func NewRepo[T any](db *gorm.DB, cache *QueryCache, tags ...string) *Repo[T] {
+	if len(tags) == 0 {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(new(T)); err == nil {
+			tags = []string{stmt.Schema.Table}
+		}
+	}
+	return &Repo[T]{db: db, cache: cache, tags: tags}
+}
+
+// DB 返回底层连接，用于不经过缓存的复杂操作，写入后需调用 Invalidate
+func (r *Repo[T]) DB(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(new(T))
+}
+
+// Find 按条件查询列表
+func (r *Repo[T]) Find(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) ([]T, error) {
+	var list []T
+	err := r.query(ctx, &list, func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(scopes...).Find(&list)
+	})
+	return list, err
+}
+
+// First 按条件查询第一条，不存在时返回 gorm.ErrRecordNotFound
+func (r *Repo[T]) First(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (*T, error) {
+	var item T
+	err := r.query(ctx, &item, func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(scopes...).First(&item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Count 按条件计数
+func (r *Repo[T]) Count(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var count int64
+	err := r.query(ctx, &count, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(new(T)).Scopes(scopes...).Count(&count)
+	})
+	return count, err
+}
+
+// Create 创建记录
+func (r *Repo[T]) Create(ctx context.Context, value *T) error {
+	return r.write(ctx, func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(value)
+	})
+}
+
+// Updates 按条件更新，values 为结构体或 map
+func (r *Repo[T]) Updates(ctx context.Context, values any, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var affected int64
+	err := r.write(ctx, func(tx *gorm.DB) *gorm.DB {
+		res := tx.Model(new(T)).Scopes(scopes...).Updates(values)
+		affected = res.RowsAffected
+		return res
+	})
+	return affected, err
+}
+
+// Delete 按条件删除
+func (r *Repo[T]) Delete(ctx context.Context, scopes ...func(*gorm.DB) *gorm.DB) (int64, error) {
+	var affected int64
+	err := r.write(ctx, func(tx *gorm.DB) *gorm.DB {
+		res := tx.Scopes(scopes...).Delete(new(T))
+		affected = res.RowsAffected
+		return res
+	})
+	return affected, err
+}
+
+// Transaction 在事务中执行 fn，fn 中通过 tx 读写，读不经过缓存，写入的失效在事务提交后执行，回滚时不失效
+// 在已有事务的仓储上调用时使用嵌套事务（保存点），失效推迟到最外层事务提交后
+func (r *Repo[T]) Transaction(ctx context.Context, fn func(tx *Repo[T]) error) error {
+	pending := r.pending
+	if pending == nil {
+		pending = &pendingTags{}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Repo[T]{db: tx, cache: r.cache, tags: r.tags, pending: pending})
+	})
+	if err != nil || r.pending != nil || len(pending.tags) == 0 {
+		return err
+	}
+
+	if err := r.cache.Invalidate(ctx, pending.tags...); err != nil {
+		logx.WithContext(ctx).Errorf("gormx cache: %v", err)
+	}
+	return nil
+}
+
+// Invalidate 使仓储标签和额外标签下的缓存失效，Transaction 中调用时推迟到提交后
+func (r *Repo[T]) Invalidate(ctx context.Context, extra ...string) error {
+	if r.cache == nil {
+		return nil
+	}
+	tags := append(append([]string{}, r.tags...), extra...)
+	if r.pending != nil {
+		r.pending.add(tags...)
+		return nil
+	}
+	return r.cache.Invalidate(ctx, tags...)
+}
+
+// query 执行读操作
+func (r *Repo[T]) query(ctx context.Context, dest any, fn func(tx *gorm.DB) *gorm.DB) error {
+	if r.cache == nil {
+		return fn(r.db.WithContext(ctx)).Error
+	}
+	return r.cache.Query(ctx, r.db, dest, r.tags, fn)
+}
+
+// write 执行写操作，成功后使缓存失效；失效失败只记录日志，缓存随TTL过期
+func (r *Repo[T]) write(ctx context.Context, fn func(tx *gorm.DB) *gorm.DB) error {
+	if err := fn(r.db.WithContext(ctx)).Error; err != nil {
+		return err
+	}
+	if err := r.Invalidate(ctx); err != nil {
+		logx.WithContext(ctx).Errorf("gormx cache: %v (tags: %s)", err, strings.Join(r.tags, ","))
+	}
+	return nil
+}
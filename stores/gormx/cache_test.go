@@ -0,0 +1,191 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// cacheUser 测试模型
+type cacheUser struct {
+	ID   int64
+	Name string
+}
+
+// fakeConnPool 支持开启事务的空连接池，配合 DryRun 使用，不执行SQL
+type fakeConnPool struct {
+	fakeConn
+	commits, rollbacks int
+}
+
+var errFakeConnPool = errors.New("fake conn pool")
+
+func (p *fakeConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{pool: p}, nil
+}
+
+// fakeTx 空事务，不支持再开启事务
+type fakeTx struct {
+	fakeConn
+	pool *fakeConnPool
+}
+
+func (t *fakeTx) Commit() error {
+	t.pool.commits++
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.pool.rollbacks++
+	return nil
+}
+
+// fakeConn 不执行SQL的连接
+type fakeConn struct{}
+
+func (fakeConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errFakeConnPool
+}
+
+func (fakeConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errFakeConnPool
+}
+
+func (fakeConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errFakeConnPool
+}
+
+func (fakeConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+// newCacheTestDB 不连接数据库的 DryRun 连接和 miniredis
+func newCacheTestDB(t *testing.T) (*gorm.DB, *fakeConnPool, *miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+	pool := &fakeConnPool{}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, ConnPool: pool})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return db, pool, mr, rdb
+}
+
+// countingQuery 记录执行次数的查询，DryRun 下不返回数据，执行时写入 name；
+// 生成缓存键的 ToSQL 调用（SkipDefaultTransaction 会话）不计入
+func countingQuery(dest *[]cacheUser, calls *int, name string) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if !tx.Config.SkipDefaultTransaction {
+			*calls++
+			*dest = []cacheUser{{ID: 1, Name: name}}
+		}
+		return tx.Where("name = ?", name).Find(dest)
+	}
+}
+
+func TestQueryCacheNilRedis(t *testing.T) {
+	db, _, _, _ := newCacheTestDB(t)
+	cache := NewQueryCache(nil)
+	ctx := context.Background()
+
+	var list []cacheUser
+	if err := cache.Query(ctx, db, &list, []string{"users"}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&list)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewRepo[cacheUser](db, cache)
+	if _, err := repo.Find(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, &cacheUser{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryCacheReadThrough(t *testing.T) {
+	db, _, _, rdb := newCacheTestDB(t)
+	cache := NewQueryCache(rdb)
+	ctx := context.Background()
+
+	calls := 0
+	query := func() []cacheUser {
+		var list []cacheUser
+		if err := cache.Query(ctx, db, &list, []string{"users"}, countingQuery(&list, &calls, "alice")); err != nil {
+			t.Fatal(err)
+		}
+		return list
+	}
+
+	first := query()
+	second := query()
+	if calls != 1 || len(first) != 1 || len(second) != 1 || second[0].Name != "alice" {
+		t.Fatalf("calls = %d, results = %v, %v", calls, first, second)
+	}
+
+	if err := cache.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	query()
+	if calls != 2 {
+		t.Fatalf("query not executed after invalidation: %d calls", calls)
+	}
+}
+
+func TestRepoTransactionInvalidatesAfterCommit(t *testing.T) {
+	tests := []struct {
+		name        string
+		fnErr       error
+		wantVersion string
+	}{
+		{"commit", nil, "1"},
+		{"rollback", errors.New("abort"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, pool, mr, rdb := newCacheTestDB(t)
+			cache := NewQueryCache(rdb)
+			repo := NewRepo[cacheUser](db, cache)
+			ctx := context.Background()
+			tagKey := cache.tagKey("cache_users")
+
+			err := repo.Transaction(ctx, func(tx *Repo[cacheUser]) error {
+				if err := tx.Create(ctx, &cacheUser{Name: "bob"}); err != nil {
+					return err
+				}
+				// 事务内的读不写入缓存
+				if _, err := tx.Find(ctx); err != nil {
+					return err
+				}
+				// 提交前不失效
+				if mr.Exists(tagKey) || len(mr.Keys()) != 0 {
+					t.Errorf("cache touched before commit: %v", mr.Keys())
+				}
+				return tt.fnErr
+			})
+			if !errors.Is(err, tt.fnErr) {
+				t.Fatalf("Transaction err = %v", err)
+			}
+
+			got, _ := mr.Get(tagKey)
+			if got != tt.wantVersion {
+				t.Fatalf("tag version after %s = %q, want %q", tt.name, got, tt.wantVersion)
+			}
+			if tt.fnErr == nil && pool.commits != 1 || tt.fnErr != nil && pool.rollbacks != 1 {
+				t.Fatalf("commits = %d, rollbacks = %d", pool.commits, pool.rollbacks)
+			}
+		})
+	}
+}
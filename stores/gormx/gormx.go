@@ -97,6 +97,9 @@ func (dm *DBManager) newEngine(c config.Config) error {
 		}
 	}
 
+	if err := registerMetricsHook(engine, metricsName(dbKey)); err != nil {
+		return fmt.Errorf("failed to register %s metrics: %v", dbKey, err)
+	}
 	return nil
 }
 
@@ -114,6 +117,7 @@ func (dm *DBManager) CloseAll() error {
 				return
 			}
 
+			collector.untrack(metricsName(dbName))
 			if e := sqlDB.Close(); e != nil {
 				errs = append(errs, fmt.Errorf("failed to close %s connection: %v", dbName, e))
 			}
@@ -0,0 +1,213 @@
+package gormx
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// 查询开始时间在 gorm 实例中的键
+const metricsStartKey = "gormx:metrics_start"
+
+var (
+	poolOpenDesc = prometheus.NewDesc(
+		"gormx_pool_open_connections",
+		"连接池当前打开的连接数（使用中+空闲）",
+		[]string{"db"}, nil,
+	)
+	poolInUseDesc = prometheus.NewDesc(
+		"gormx_pool_in_use_connections",
+		"连接池当前使用中的连接数",
+		[]string{"db"}, nil,
+	)
+	poolIdleDesc = prometheus.NewDesc(
+		"gormx_pool_idle_connections",
+		"连接池当前空闲的连接数",
+		[]string{"db"}, nil,
+	)
+	poolMaxOpenDesc = prometheus.NewDesc(
+		"gormx_pool_max_open_connections",
+		"连接池允许的最大连接数（0为不限制）",
+		[]string{"db"}, nil,
+	)
+	poolWaitCountDesc = prometheus.NewDesc(
+		"gormx_pool_wait_count_total",
+		"等待空闲连接的总次数",
+		[]string{"db"}, nil,
+	)
+	poolWaitDurationDesc = prometheus.NewDesc(
+		"gormx_pool_wait_duration_seconds_total",
+		"等待空闲连接的总耗时（秒）",
+		[]string{"db"}, nil,
+	)
+	poolMaxIdleClosedDesc = prometheus.NewDesc(
+		"gormx_pool_max_idle_closed_total",
+		"因超过最大空闲连接数而关闭的连接总数",
+		[]string{"db"}, nil,
+	)
+	poolMaxIdleTimeClosedDesc = prometheus.NewDesc(
+		"gormx_pool_max_idle_time_closed_total",
+		"因超过最大空闲时间而关闭的连接总数",
+		[]string{"db"}, nil,
+	)
+	poolMaxLifetimeClosedDesc = prometheus.NewDesc(
+		"gormx_pool_max_lifetime_closed_total",
+		"因超过最大生命周期而关闭的连接总数",
+		[]string{"db"}, nil,
+	)
+
+	// 各操作的查询次数
+	queryTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "gormx",
+			Name:      "queries_total",
+			Help:      "按操作和结果统计的SQL执行次数",
+		},
+		[]string{"db", "operation", "status"},
+	)
+
+	// 各操作的查询耗时
+	queryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "gormx",
+			Name:      "query_duration_seconds",
+			Help:      "按操作统计的SQL执行耗时（秒）",
+			Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"db", "operation"},
+	)
+
+	collector = &metricsCollector{dbs: make(map[string]*sql.DB)}
+)
+
+// metricsCollector 汇总连接池状态和查询指标，连接池状态在抓取时从 sql.DB.Stats 读取
+type metricsCollector struct {
+	mu  sync.RWMutex
+	dbs map[string]*sql.DB
+}
+
+// Collector 返回gormx指标收集器，由业务方注册到自己的 Prometheus registry，所有 DBManager 共用
+func (dm *DBManager) Collector() prometheus.Collector {
+	return collector
+}
+
+// track 记录需要采集连接池状态的数据库
+func (c *metricsCollector) track(name string, db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dbs[name] = db
+}
+
+// untrack 连接关闭后停止采集
+func (c *metricsCollector) untrack(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.dbs, name)
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolOpenDesc
+	ch <- poolInUseDesc
+	ch <- poolIdleDesc
+	ch <- poolMaxOpenDesc
+	ch <- poolWaitCountDesc
+	ch <- poolWaitDurationDesc
+	ch <- poolMaxIdleClosedDesc
+	ch <- poolMaxIdleTimeClosedDesc
+	ch <- poolMaxLifetimeClosedDesc
+	queryTotal.Describe(ch)
+	queryDuration.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for name, db := range c.dbs {
+		s := db.Stats()
+		ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(s.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, float64(s.InUse), name)
+		ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(s.Idle), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxOpenDesc, prometheus.GaugeValue, float64(s.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(s.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, s.WaitDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxIdleClosedDesc, prometheus.CounterValue, float64(s.MaxIdleClosed), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxIdleTimeClosedDesc, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), name)
+		ch <- prometheus.MustNewConstMetric(poolMaxLifetimeClosedDesc, prometheus.CounterValue, float64(s.MaxLifetimeClosed), name)
+	}
+	queryTotal.Collect(ch)
+	queryDuration.Collect(ch)
+}
+
+// registerMetricsHook 注册查询计时回调并采集连接池状态，name 为指标中的 db 标签
+func registerMetricsHook(engine *gorm.DB, name string) error {
+	sqlDB, err := engine.DB()
+	if err != nil {
+		return err
+	}
+
+	cb := engine.Callback()
+	err = errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startTimer),
+		cb.Create().After("gorm:create").Register("metrics:after_create", observe(name, "create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startTimer),
+		cb.Query().After("gorm:query").Register("metrics:after_query", observe(name, "query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startTimer),
+		cb.Update().After("gorm:update").Register("metrics:after_update", observe(name, "update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", observe(name, "delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startTimer),
+		cb.Row().After("gorm:row").Register("metrics:after_row", observe(name, "row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", observe(name, "raw")),
+	)
+	if err != nil {
+		return err
+	}
+
+	collector.track(name, sqlDB)
+	return nil
+}
+
+// startTimer 记录查询开始时间
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+// observe 记录查询次数和耗时
+func observe(name, operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		status := "ok"
+		switch {
+		case db.Error == nil:
+		case errors.Is(db.Error, gorm.ErrRecordNotFound):
+			status = "not_found"
+		default:
+			status = "error"
+		}
+
+		queryTotal.WithLabelValues(name, operation, status).Inc()
+		queryDuration.WithLabelValues(name, operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricsName 指标中的 db 标签
+func metricsName(dbKey string) string {
+	return strings.ToLower(dbKey)
+}
@@ -0,0 +1,172 @@
+package scopes
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maskText 脱敏后的占位文本
+const maskText = "'****'"
+
+// MaskPolicy 字段脱敏策略，根据数据库方言返回替换字段的SQL表达式及其参数，column 已转义
+type MaskPolicy func(dialect, column string) (expr string, args []any)
+
+// MaskPartial 保留前 head 位和后 tail 位，中间替换为 ****，长度不足时整体替换
+func MaskPartial(head, tail int) MaskPolicy {
+	return func(dialect, column string) (string, []any) {
+		text := textExpr(dialect, column)
+		var expr string
+		switch dialect {
+		case "clickhouse":
+			expr = fmt.Sprintf("concat(leftUTF8(%s, %d), %s, rightUTF8(%s, %d))", text, head, maskText, text, tail)
+			return nullGuard(column, fmt.Sprintf("CASE WHEN lengthUTF8(%s) > %d THEN %s ELSE %s END", text, head+tail, expr, maskText)), nil
+		case "sqlite":
+			expr = fmt.Sprintf("substr(%s, 1, %d) || %s", text, head, maskText)
+			if tail > 0 {
+				expr += fmt.Sprintf(" || substr(%s, -%d)", text, tail)
+			}
+			return nullGuard(column, fmt.Sprintf("CASE WHEN LENGTH(%s) > %d THEN %s ELSE %s END", text, head+tail, expr, maskText)), nil
+		default:
+			expr = fmt.Sprintf("CONCAT(LEFT(%s, %d), %s, RIGHT(%s, %d))", text, head, maskText, text, tail)
+			return nullGuard(column, fmt.Sprintf("CASE WHEN CHAR_LENGTH(%s) > %d THEN %s ELSE %s END", text, head+tail, expr, maskText)), nil
+		}
+	}
+}
+
+// MaskPhone 手机号脱敏，保留前3位和后4位
+func MaskPhone() MaskPolicy {
+	return MaskPartial(3, 4)
+}
+
+// MaskHash 替换为 sha256(salt + 小写去空格的值) 的十六进制，相同的值得到相同的结果，导出数据仍可关联统计
+// salt 需为保密的随机值，否则可通过字典还原原值；salt 为空或数据库不支持哈希函数（如 sqlite）时整体替换为 ****
+func MaskHash(salt string) MaskPolicy {
+	if salt == "" {
+		return MaskRedact()
+	}
+	return func(dialect, column string) (string, []any) {
+		text := textExpr(dialect, column)
+		switch dialect {
+		case "mysql":
+			return nullGuard(column, fmt.Sprintf("SHA2(CONCAT(?, LOWER(TRIM(%s))), 256)", text)), []any{salt}
+		case "postgres":
+			return nullGuard(column, fmt.Sprintf("encode(sha256(convert_to(CONCAT(CAST(? AS TEXT), LOWER(TRIM(%s))), 'UTF8')), 'hex')", text)), []any{salt}
+		case "clickhouse":
+			return nullGuard(column, fmt.Sprintf("lower(hex(SHA256(concat(?, lower(trimBoth(%s))))))", text)), []any{salt}
+		default:
+			return MaskRedact()(dialect, column)
+		}
+	}
+}
+
+// MaskEmail 邮箱脱敏，使用 MaskHash，salt 为空时整体替换为 ****
+func MaskEmail(salt string) MaskPolicy {
+	return MaskHash(salt)
+}
+
+// MaskRedact 整体替换为 ****，保留 NULL
+func MaskRedact() MaskPolicy {
+	return func(_, column string) (string, []any) {
+		return nullGuard(column, maskText), nil
+	}
+}
+
+// MaskNull 替换为 NULL，用于密码、密钥等不应导出的字段
+func MaskNull() MaskPolicy {
+	return func(_, _ string) (string, []any) {
+		return "NULL", nil
+	}
+}
+
+// DefaultMaskPolicies 默认脱敏策略，按字段名（不区分大小写，忽略 _ 和 -）后缀匹配，如 user_phone 匹配 phone
+// 邮箱整体替换为 ****，导出数据需要按邮箱关联时使用 MaskEmail 并配置保密的 salt 替换 "email"
+func DefaultMaskPolicies() map[string]MaskPolicy {
+	return map[string]MaskPolicy{
+		"phone":    MaskPhone(),
+		"mobile":   MaskPhone(),
+		"email":    MaskRedact(),
+		"idcard":   MaskPartial(4, 4),
+		"idno":     MaskPartial(4, 4),
+		"bankcard": MaskPartial(4, 4),
+		"password": MaskNull(),
+		"secret":   MaskNull(),
+		"token":    MaskNull(),
+	}
+}
+
+// ExportSelect 导出查询的字段筛选，按 DefaultMaskPolicies 脱敏，用于CSV、报表等导出场景
+func ExportSelect(fields ...string) func(db *gorm.DB) *gorm.DB {
+	return MaskedSelect(DefaultMaskPolicies(), fields...)
+}
+
+// MaskedSelect 筛选字段并按策略脱敏，脱敏字段以原字段名作为别名，可直接扫描到原结构体
+// 字段可带表名（users.phone），策略按字段名匹配，未匹配的字段原样查询
+func MaskedSelect(policies map[string]MaskPolicy, fields ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(fields) == 0 {
+			return db
+		}
+
+		dialect := db.Dialector.Name()
+		var (
+			columns = make([]string, 0, len(fields))
+			args    []any
+		)
+		for _, field := range fields {
+			name := field[strings.LastIndex(field, ".")+1:]
+			policy := matchMaskPolicy(policies, name)
+			if policy == nil {
+				columns = append(columns, db.Statement.Quote(field))
+				continue
+			}
+
+			expr, exprArgs := policy(dialect, db.Statement.Quote(field))
+			columns = append(columns, expr+" AS "+db.Statement.Quote(name))
+			args = append(args, exprArgs...)
+		}
+		return db.Select(strings.Join(columns, ", "), args...)
+	}
+}
+
+// matchMaskPolicy 按字段名后缀匹配脱敏策略，多个策略匹配时使用最长的后缀（即精确匹配优先），长度相同时按字典序
+func matchMaskPolicy(policies map[string]MaskPolicy, name string) MaskPolicy {
+	key := normalizeMaskKey(name)
+	suffixes := slices.Collect(maps.Keys(policies))
+	slices.SortFunc(suffixes, func(a, b string) int {
+		na, nb := normalizeMaskKey(a), normalizeMaskKey(b)
+		return cmp.Or(cmp.Compare(len(nb), len(na)), cmp.Compare(na, nb), cmp.Compare(a, b))
+	})
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(key, normalizeMaskKey(suffix)) {
+			return policies[suffix]
+		}
+	}
+	return nil
+}
+
+// normalizeMaskKey 转为小写并去掉 _ 和 -
+func normalizeMaskKey(key string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+}
+
+// textExpr 将字段转为文本，兼容数字类型的手机号等字段
+func textExpr(dialect, column string) string {
+	switch dialect {
+	case "postgres":
+		return "CAST(" + column + " AS TEXT)"
+	case "clickhouse":
+		return "toString(" + column + ")"
+	default:
+		return column
+	}
+}
+
+// nullGuard 字段为 NULL 时保持 NULL
+func nullGuard(column, expr string) string {
+	return "CASE WHEN " + column + " IS NULL THEN NULL ELSE " + expr + " END"
+}
@@ -0,0 +1,112 @@
+package scopes
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// exprOf 策略在指定方言下的SQL表达式
+func exprOf(p MaskPolicy, dialect string) (string, []any) {
+	return p(dialect, "`email`")
+}
+
+func TestMaskHashRequiresSalt(t *testing.T) {
+	redacted, _ := exprOf(MaskRedact(), "mysql")
+
+	tests := []struct {
+		name     string
+		policy   MaskPolicy
+		dialect  string
+		wantHash bool
+	}{
+		{"email without salt", MaskEmail(""), "mysql", false},
+		{"hash without salt", MaskHash(""), "postgres", false},
+		{"default email", DefaultMaskPolicies()["email"], "mysql", false},
+		{"salted mysql", MaskEmail("s3cret"), "mysql", true},
+		{"salted postgres", MaskHash("s3cret"), "postgres", true},
+		{"salted sqlite", MaskHash("s3cret"), "sqlite", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, args := exprOf(tt.policy, tt.dialect)
+			if tt.wantHash {
+				if !strings.Contains(strings.ToLower(expr), "sha") || len(args) != 1 || args[0] != "s3cret" {
+					t.Fatalf("expr = %s, args = %v", expr, args)
+				}
+				return
+			}
+			if expr != redacted || len(args) != 0 {
+				t.Fatalf("expr = %s, args = %v, want redacted", expr, args)
+			}
+		})
+	}
+}
+
+func TestMatchMaskPolicy(t *testing.T) {
+	phone, token, idCard := MaskPhone(), MaskNull(), MaskPartial(4, 4)
+	policies := map[string]MaskPolicy{
+		"phone":         phone,
+		"token":         token,
+		"card":          MaskRedact(),
+		"id_card":       idCard,
+		"refresh_token": token,
+	}
+	redacted := policies["card"]
+
+	tests := []struct {
+		name string
+		want MaskPolicy
+	}{
+		{"phone", phone},
+		{"User_Phone", phone},
+		{"user-id-card", idCard},
+		{"bank_card", redacted},
+		{"access_token", token},
+		{"nickname", nil},
+	}
+	for _, tt := range tests {
+		// 重复匹配，结果不受 map 遍历顺序影响
+		for range 20 {
+			got, _ := exprOrNil(matchMaskPolicy(policies, tt.name))
+			want, _ := exprOrNil(tt.want)
+			if got != want {
+				t.Fatalf("matchMaskPolicy(%q) = %q, want %q", tt.name, got, want)
+			}
+		}
+	}
+}
+
+// exprOrNil 策略的表达式，用于比较策略，策略为空时返回空
+func exprOrNil(p MaskPolicy) (string, []any) {
+	if p == nil {
+		return "", nil
+	}
+	return exprOf(p, "mysql")
+}
+
+func TestExportSelect(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []map[string]any
+	stmt := db.Table("users").Scopes(ExportSelect("id", "users.email", "user_phone", "password")).Find(&rows).Statement
+	sql := stmt.SQL.String()
+	for _, want := range []string{
+		"`id`",
+		"CASE WHEN `users`.`email` IS NULL THEN NULL ELSE '****' END AS `email`",
+		"AS `user_phone`",
+		"NULL AS `password`",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("sql %s does not contain %s", sql, want)
+		}
+	}
+	if strings.Contains(sql, "SHA2") || len(stmt.Vars) != 0 {
+		t.Fatalf("unsalted hash in export: %s %v", sql, stmt.Vars)
+	}
+}
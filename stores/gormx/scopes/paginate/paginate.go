@@ -1,10 +1,20 @@
 package paginate
 
 import (
-	"gorm.io/gorm"
+	"errors"
 	"math"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrInvalidCursorField 游标分页的排序字段不是合法的列名
+var ErrInvalidCursorField = errors.New("paginate: invalid cursor field")
+
+// cursorFieldPattern 游标排序字段允许的格式：列名或 表名.列名
+var cursorFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
 // Paginate 分页
 func Paginate(pagination *Pagination) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -25,3 +35,25 @@ func Paginate(pagination *Pagination) func(db *gorm.DB) *gorm.DB {
 		return db.Offset(int(offset)).Limit(int(limit))
 	}
 }
+
+// Cursor 游标分页，返回 field 在 cursor 之后的 limit 条记录；desc 为 true 时按 field 倒序，取小于 cursor 的记录
+// cursor 为空时从第一条开始，调用方以最后一条记录的 field 值作为下一页的游标
+// field 只能是列名或 表名.列名，按方言加引号，不合法时查询返回 ErrInvalidCursorField
+func Cursor(field string, cursor any, limit int64, desc bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !cursorFieldPattern.MatchString(field) {
+			_ = db.AddError(ErrInvalidCursorField)
+			return db
+		}
+
+		column := clause.Column{Name: field}
+		if cursor != nil && cursor != "" {
+			if desc {
+				db = db.Where(clause.Lt{Column: column, Value: cursor})
+			} else {
+				db = db.Where(clause.Gt{Column: column, Value: cursor})
+			}
+		}
+		return db.Order(clause.OrderByColumn{Column: column, Desc: desc}).Limit(int(limit))
+	}
+}
@@ -0,0 +1,50 @@
+package paginate
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestCursor(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		field   string
+		cursor  any
+		desc    bool
+		wantSQL string
+		wantErr error
+	}{
+		{"first page", "id", "", false, "SELECT * FROM `users` ORDER BY `id` LIMIT ?", nil},
+		{"asc", "id", 5, false, "SELECT * FROM `users` WHERE `id` > ? ORDER BY `id` LIMIT ?", nil},
+		{"desc with table", "users.created_at", 5, true, "SELECT * FROM `users` WHERE `users`.`created_at` < ? ORDER BY `users`.`created_at` DESC LIMIT ?", nil},
+		{"injection", "id; DROP TABLE users", 5, false, "", ErrInvalidCursorField},
+		{"expression", "id desc, (select 1)", nil, false, "", ErrInvalidCursorField},
+		{"empty", "", nil, false, "", ErrInvalidCursorField},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]any
+			tx := db.Table("users").Scopes(Cursor(tt.field, tt.cursor, 10, tt.desc)).Find(&rows)
+			if tt.wantErr != nil {
+				if !errors.Is(tx.Error, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", tx.Error, tt.wantErr)
+				}
+				return
+			}
+			if tx.Error != nil {
+				t.Fatal(tx.Error)
+			}
+			if sql := tx.Statement.SQL.String(); sql != tt.wantSQL {
+				t.Fatalf("sql = %s, want %s", sql, tt.wantSQL)
+			}
+		})
+	}
+}
@@ -28,16 +28,77 @@ func Equal2(field string, value any, apply bool) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
-// NotEqual 不等于
-func NotEqual(field string, value any) func(db *gorm.DB) *gorm.DB {
+// compareOptions 比较查询选项
+type compareOptions struct {
+	includeNull bool
+	keepZero    bool
+}
+
+// CompareOption 比较查询选项
+type CompareOption func(o *compareOptions)
+
+// IncludeNull 结果同时包含字段为 NULL 的记录，SQL中 NULL != x 不成立，默认会被排除
+func IncludeNull() CompareOption {
+	return func(o *compareOptions) {
+		o.includeNull = true
+	}
+}
+
+// KeepZero 值为零值时仍然添加条件，默认零值时忽略该条件
+func KeepZero() CompareOption {
+	return func(o *compareOptions) {
+		o.keepZero = true
+	}
+}
+
+// NotEqual 不等于，值为零值时忽略该条件（可用 KeepZero 保留），IncludeNull 时包含字段为 NULL 的记录
+func NotEqual(field string, value any, opts ...CompareOption) func(db *gorm.DB) *gorm.DB {
+	var o compareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	return func(db *gorm.DB) *gorm.DB {
-		if isZero(value) {
+		if !o.keepZero && isZero(value) {
 			return db
 		}
+		if o.includeNull {
+			return db.Where(field+" != ? OR "+field+" IS NULL", value)
+		}
 		return db.Where(field+" != ?", value)
 	}
 }
 
+// IsNull 字段为 NULL
+func IsNull(field string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(field + " IS NULL")
+	}
+}
+
+// IsNotNull 字段不为 NULL
+func IsNotNull(field string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(field + " IS NOT NULL")
+	}
+}
+
+// IsNullOrEmpty 字段为 NULL 或空字符串
+func IsNullOrEmpty(field string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(field + " IS NULL OR " + field + " = ''")
+	}
+}
+
+// EqualOrNull 等于指定值或为 NULL，值为 nil 时只查询 NULL
+func EqualOrNull(field string, value any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if value == nil {
+			return db.Where(field + " IS NULL")
+		}
+		return db.Where(field+" = ? OR "+field+" IS NULL", value)
+	}
+}
+
 // Like 模糊查询
 func Like(field string, value any) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
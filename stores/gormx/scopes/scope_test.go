@@ -0,0 +1,45 @@
+package scopes
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+func TestNullScopes(t *testing.T) {
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		scope    func(db *gorm.DB) *gorm.DB
+		wantSQL  string
+		wantVars int
+	}{
+		{"not equal", NotEqual("status", 1), "SELECT * FROM `users` WHERE tenant_id = ? AND status != ?", 2},
+		{"not equal zero ignored", NotEqual("status", 0), "SELECT * FROM `users` WHERE tenant_id = ?", 1},
+		{"not equal keep zero", NotEqual("status", 0, KeepZero()), "SELECT * FROM `users` WHERE tenant_id = ? AND status != ?", 2},
+		{"not equal include null", NotEqual("status", 1, IncludeNull()), "SELECT * FROM `users` WHERE tenant_id = ? AND (status != ? OR status IS NULL)", 2},
+		{"is null", IsNull("deleted_at"), "SELECT * FROM `users` WHERE tenant_id = ? AND deleted_at IS NULL", 1},
+		{"is not null", IsNotNull("deleted_at"), "SELECT * FROM `users` WHERE tenant_id = ? AND deleted_at IS NOT NULL", 1},
+		{"is null or empty", IsNullOrEmpty("nickname"), "SELECT * FROM `users` WHERE tenant_id = ? AND (nickname IS NULL OR nickname = '')", 1},
+		{"equal or null", EqualOrNull("region", "eu"), "SELECT * FROM `users` WHERE tenant_id = ? AND (region = ? OR region IS NULL)", 2},
+		{"equal or null nil", EqualOrNull("region", nil), "SELECT * FROM `users` WHERE tenant_id = ? AND region IS NULL", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]any
+			// OR 条件与其他条件组合时需加括号
+			stmt := db.Table("users").Where("tenant_id = ?", 7).Scopes(tt.scope).Find(&rows).Statement
+			if got := stmt.SQL.String(); got != tt.wantSQL {
+				t.Fatalf("SQL = %s, want %s", got, tt.wantSQL)
+			}
+			if len(stmt.Vars) != tt.wantVars {
+				t.Fatalf("vars = %v, want %d", stmt.Vars, tt.wantVars)
+			}
+		})
+	}
+}
@@ -118,8 +118,9 @@ func ConvertExchangeRateToInt64(exchangeRate string) (int64, error) {
 //	例如: 1 USDT = 0.998 USD，则 exchangeRate = 998000
 //
 // usdtAmount: 要兑换的USDT金额（以Wei为单位）
+// opts: 舍入选项，默认使用 TxConversion 的策略（截断）
 // 返回兑换后的目标货币金额（以Wei为单位）
-func ConvertUSDTToCurrency(exchangeRate int64, usdtAmount int64) (int64, error) {
+func ConvertUSDTToCurrency(exchangeRate int64, usdtAmount int64, opts ...Option) (int64, error) {
 	// 验证汇率有效性
 	if exchangeRate <= 0 {
 		return 0, fmt.Errorf("汇率必须大于0，收到: %v", exchangeRate)
@@ -129,10 +130,9 @@ func ConvertUSDTToCurrency(exchangeRate int64, usdtAmount int64) (int64, error)
 	// 等价于 = USDT金额 * 汇率 / 1000000
 	result := decimal.NewFromInt(usdtAmount).
 		Mul(decimal.NewFromInt(exchangeRate)).
-		Div(decimal.NewFromInt(int64(Wei))).
-		IntPart()
+		Div(decimal.NewFromInt(int64(Wei)))
 
-	return result, nil
+	return resolvePolicy(TxConversion, opts).Round(result, 0).IntPart(), nil
 }
 
 // ConvertCurrencyToUSDT 将指定货币金额兑换成USDT
@@ -141,8 +141,9 @@ func ConvertUSDTToCurrency(exchangeRate int64, usdtAmount int64) (int64, error)
 //	例如: 1 USDT = 0.998 USD，则 exchangeRate = 998000
 //
 // amount: 要兑换的指定货币金额（以Wei为单位）
+// opts: 舍入选项，默认使用 TxConversion 的策略（截断）
 // 返回兑换后的USDT金额（以Wei为单位）
-func ConvertCurrencyToUSDT(exchangeRate int64, amount int64) (int64, error) {
+func ConvertCurrencyToUSDT(exchangeRate int64, amount int64, opts ...Option) (int64, error) {
 	// 验证汇率有效性
 	if exchangeRate <= 0 {
 		return 0, fmt.Errorf("汇率必须大于0，收到: %v", exchangeRate)
@@ -152,10 +153,9 @@ func ConvertCurrencyToUSDT(exchangeRate int64, amount int64) (int64, error) {
 	// 等价于 = 目标货币金额 * 1000000 / 汇率
 	result := decimal.NewFromInt(amount).
 		Mul(decimal.NewFromInt(int64(Wei))).
-		Div(decimal.NewFromInt(exchangeRate)).
-		IntPart()
+		Div(decimal.NewFromInt(exchangeRate))
 
-	return result, nil
+	return resolvePolicy(TxConversion, opts).Round(result, 0).IntPart(), nil
 }
 
 // CalculateFee 计算手续费金额，使用 decimal 避免溢出和精度问题
@@ -163,11 +163,12 @@ func ConvertCurrencyToUSDT(exchangeRate int64, amount int64) (int64, error) {
 //
 //	amount: 原始金额 (已经乘以1000000的int64值)
 //	rateInMillionths: 以百万分之一为单位的费率 (例如，20% = 200000)
+//	opts: 舍入选项，默认使用 TxFee 的策略（向上取整）
 //
 // 返回:
 //
 //	计算后的手续费金额 (int64)
-func CalculateFee(amount int64, rateInMillionths int64) int64 {
+func CalculateFee(amount int64, rateInMillionths int64, opts ...Option) int64 {
 	// 定义百万分之一的除数
 	million := decimal.NewFromInt(int64(Wei))
 
@@ -180,8 +181,8 @@ func CalculateFee(amount int64, rateInMillionths int64) int64 {
 	// 计算手续费：amount * rate
 	feeDec := amountDec.Mul(rateDec)
 
-	// 将结果乘以 1000000，转换为 int64
-	result := feeDec.Mul(million).Round(0).IntPart()
+	// 将结果乘以 1000000，按舍入策略转换为 int64
+	result := resolvePolicy(TxFee, opts).Round(feeDec.Mul(million), 0).IntPart()
 
 	return result
 }
@@ -10,3 +10,29 @@ func TestCurrencyUnit_ConvertTo(t *testing.T) {
 	var c int64 = 12345678
 	fmt.Println(WeiToYuan(c))
 }
+
+func TestRoundingPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"fee default ceil", CalculateFee(1000001, 100000), 100001},
+		{"fee half up", CalculateFee(1000001, 100000, WithRounding(RoundHalfUp)), 100000},
+		{"fee as payout floor", CalculateFee(1000009, 100000, WithTxType(TxPayout)), 100000},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+
+	usdt, err := ConvertCurrencyToUSDT(3000000, 1000000, WithRounding(RoundCeil))
+	if err != nil || usdt != 333334 {
+		t.Errorf("ConvertCurrencyToUSDT ceil: got %d, %v", usdt, err)
+	}
+	usdt, _ = ConvertCurrencyToUSDT(3000000, 1000000)
+	if usdt != 333333 {
+		t.Errorf("ConvertCurrencyToUSDT default: got %d", usdt)
+	}
+}
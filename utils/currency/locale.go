@@ -0,0 +1,239 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// 微单位相对元的小数位数
+const weiDecimals int32 = 6
+
+var (
+	// ErrInvalidAmount 金额格式错误
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrAmountPrecision 小数位数超出币种精度
+	ErrAmountPrecision = errors.New("amount exceeds currency precision")
+	// ErrAmountOverflow 金额超出 int64 微单位范围
+	ErrAmountOverflow = errors.New("amount overflow")
+	// ErrUnknownCurrency 币种未注册
+	ErrUnknownCurrency = errors.New("unknown currency")
+)
+
+// Separators 数字分隔符
+type Separators struct {
+	// 小数点
+	Decimal rune
+	// 千分位分隔符
+	Group rune
+	// 印度式分组：最后一组3位，其余每组2位，如 1,23,45,678
+	Indian bool
+}
+
+var (
+	dotDecimal   = Separators{Decimal: '.', Group: ','}
+	commaDecimal = Separators{Decimal: ',', Group: '.'}
+	spaceGroup   = Separators{Decimal: ',', Group: ' '}
+
+	// 按完整地区匹配
+	regionSeparators = map[string]Separators{
+		"de-ch": {Decimal: '.', Group: '\''},
+		"it-ch": {Decimal: '.', Group: '\''},
+		"fr-ch": {Decimal: ',', Group: ' '},
+		"en-in": {Decimal: '.', Group: ',', Indian: true},
+		"hi-in": {Decimal: '.', Group: ',', Indian: true},
+		"pt-pt": spaceGroup,
+		"es-mx": dotDecimal,
+		"en-za": spaceGroup,
+	}
+
+	// 按语言匹配
+	languageSeparators = map[string]Separators{
+		"de": commaDecimal,
+		"es": commaDecimal,
+		"it": commaDecimal,
+		"pt": commaDecimal,
+		"nl": commaDecimal,
+		"id": commaDecimal,
+		"vi": commaDecimal,
+		"tr": commaDecimal,
+		"da": commaDecimal,
+		"el": commaDecimal,
+		"fr": spaceGroup,
+		"ru": spaceGroup,
+		"uk": spaceGroup,
+		"pl": spaceGroup,
+		"cs": spaceGroup,
+		"sk": spaceGroup,
+		"sv": spaceGroup,
+		"nb": spaceGroup,
+		"fi": spaceGroup,
+		"hi": {Decimal: '.', Group: ',', Indian: true},
+	}
+)
+
+// SeparatorsFor 地区使用的数字分隔符，locale 如 de-DE、pt_BR、en，未知地区使用 1,234.56 格式
+func SeparatorsFor(locale string) Separators {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if sep, ok := regionSeparators[locale]; ok {
+		return sep
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	if sep, ok := languageSeparators[lang]; ok {
+		return sep
+	}
+	return dotDecimal
+}
+
+// ParseAmount 按地区格式解析用户输入的金额并转为微单位，如 de-DE 下 "1.234,56"、en-US 下 "1,234.56"
+// 千分位分组不规范、出现多个小数点、小数位数超出币种精度、负数或超出 int64 范围时返回错误
+func ParseAmount(input, currency, locale string) (int64, error) {
+	info, ok := Lookup(currency)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	normalized, err := normalizeAmount(input, SeparatorsFor(locale))
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := decimal.NewFromString(normalized)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+	// 微单位为6位小数，币种精度更高时也只能保留6位
+	places := min(info.Decimals, weiDecimals)
+	if _, frac, _ := strings.Cut(normalized, "."); int32(len(strings.TrimRight(frac, "0"))) > places {
+		return 0, fmt.Errorf("%w: %s allows %d decimal places", ErrAmountPrecision, info.Code, places)
+	}
+
+	wei := amount.Mul(Wei.Decimal())
+	if wei.GreaterThan(decimal.NewFromInt(math.MaxInt64)) {
+		return 0, fmt.Errorf("%w: %q", ErrAmountOverflow, input)
+	}
+	return wei.IntPart(), nil
+}
+
+// FormatAmount 将微单位金额按币种精度和地区格式输出，超出精度的部分截断，如 de-DE 下 1234560000 格式化为 "1.234,56"
+func FormatAmount(wei int64, currency, locale string) (string, error) {
+	info, ok := Lookup(currency)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	sep := SeparatorsFor(locale)
+
+	s := decimal.NewFromInt(wei).Div(Wei.Decimal()).Truncate(info.Decimals).StringFixed(info.Decimals)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+
+	var sb strings.Builder
+	sb.WriteString(sign)
+	for i, group := range splitGroups(intPart, sep.Indian) {
+		if i > 0 {
+			sb.WriteRune(sep.Group)
+		}
+		sb.WriteString(group)
+	}
+	if frac != "" {
+		sb.WriteRune(sep.Decimal)
+		sb.WriteString(frac)
+	}
+	return sb.String(), nil
+}
+
+// normalizeAmount 校验分组并转为 1234.56 格式
+func normalizeAmount(input string, sep Separators) (string, error) {
+	s := strings.TrimSpace(input)
+	s = strings.TrimPrefix(s, "+")
+	if strings.HasPrefix(s, "-") {
+		return "", fmt.Errorf("%w: negative amount %q", ErrInvalidAmount, input)
+	}
+	if s == "" {
+		return "", fmt.Errorf("%w: empty amount", ErrInvalidAmount)
+	}
+
+	intPart, frac, hasFrac := strings.Cut(s, string(sep.Decimal))
+	if hasFrac && (frac == "" || !isDigits(frac)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+
+	// 空格分组的地区同时接受不换行空格
+	if sep.Group == ' ' {
+		intPart = strings.NewReplacer("\u00a0", " ", "\u202f", " ").Replace(intPart)
+	}
+	groups := strings.Split(intPart, string(sep.Group))
+	if !validGroups(groups, sep.Indian) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+
+	normalized := strings.Join(groups, "")
+	if hasFrac {
+		normalized += "." + frac
+	}
+	return normalized, nil
+}
+
+// validGroups 校验千分位分组：各组均为数字，无分组时不限长度，
+// 有分组时首组1~3位，其余每组3位；印度式首组1~2位，最后一组3位，其余每组2位
+func validGroups(groups []string, indian bool) bool {
+	for _, g := range groups {
+		if !isDigits(g) {
+			return false
+		}
+	}
+	if len(groups) == 1 {
+		return true
+	}
+
+	for i, g := range groups {
+		switch {
+		case i == 0:
+			if len(g) > 3 || (indian && len(g) > 2) {
+				return false
+			}
+		case i == len(groups)-1 || !indian:
+			if len(g) != 3 {
+				return false
+			}
+		default:
+			if len(g) != 2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitGroups 按千分位拆分整数部分
+func splitGroups(intPart string, indian bool) []string {
+	var groups []string
+	size := 3
+	for len(intPart) > size {
+		groups = append([]string{intPart[len(intPart)-size:]}, groups...)
+		intPart = intPart[:len(intPart)-size]
+		if indian {
+			size = 2
+		}
+	}
+	return append([]string{intPart}, groups...)
+}
+
+// isDigits 是否全为ASCII数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency string
+		locale   string
+		want     int64
+		err      error
+	}{
+		{"1,234.56", "USD", "en-US", 1234560000, nil},
+		{"1.234,56", "EUR", "de-DE", 1234560000, nil},
+		{"1 234,56", "EUR", "fr-FR", 1234560000, nil},
+		{"1 234,56", "EUR", "fr", 1234560000, nil},
+		{"1'234.56", "CHF", "de-CH", 0, ErrUnknownCurrency},
+		{"1,23,456.78", "INR", "en-IN", 123456780000, nil},
+		{"1234", "JPY", "ja-JP", 1234000000, nil},
+		{"1,234.56", "EUR", "de-DE", 0, ErrInvalidAmount},
+		{"12.34", "EUR", "de-DE", 0, ErrInvalidAmount},
+		{"1,2345.6", "USD", "en", 0, ErrInvalidAmount},
+		{"-5", "USD", "en", 0, ErrInvalidAmount},
+		{"1.005", "USD", "en", 0, ErrAmountPrecision},
+		{"1.500", "USD", "en", 1500000, nil},
+		{"12.5", "JPY", "ja", 0, ErrAmountPrecision},
+		{"99999999999999", "USD", "en", 0, ErrAmountOverflow},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.input, tt.currency, tt.locale)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseAmount(%q, %s, %s) = %d, %v; want %d, %v", tt.input, tt.currency, tt.locale, got, err, tt.want, tt.err)
+		}
+	}
+
+	s, err := FormatAmount(123456789000, "EUR", "de-DE")
+	if err != nil || s != "123.456,78" {
+		t.Errorf("FormatAmount: got %q, %v", s, err)
+	}
+}
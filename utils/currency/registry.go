@@ -0,0 +1,62 @@
+package currency
+
+import (
+	"strings"
+	"sync"
+)
+
+// Info 币种信息
+type Info struct {
+	// 币种代码，如 CNY、USD
+	Code string
+	// 小数位数（最小单位精度），如 CNY 为2，JPY 为0
+	Decimals int32
+}
+
+var (
+	registryMu sync.RWMutex
+	// 币种注册表，小数位数参考 ISO 4217
+	registry = map[string]Info{
+		"CNY":  {Code: "CNY", Decimals: 2},
+		"USD":  {Code: "USD", Decimals: 2},
+		"EUR":  {Code: "EUR", Decimals: 2},
+		"GBP":  {Code: "GBP", Decimals: 2},
+		"HKD":  {Code: "HKD", Decimals: 2},
+		"TWD":  {Code: "TWD", Decimals: 2},
+		"MOP":  {Code: "MOP", Decimals: 2},
+		"SGD":  {Code: "SGD", Decimals: 2},
+		"MYR":  {Code: "MYR", Decimals: 2},
+		"THB":  {Code: "THB", Decimals: 2},
+		"PHP":  {Code: "PHP", Decimals: 2},
+		"INR":  {Code: "INR", Decimals: 2},
+		"BRL":  {Code: "BRL", Decimals: 2},
+		"AED":  {Code: "AED", Decimals: 2},
+		"CAD":  {Code: "CAD", Decimals: 2},
+		"AUD":  {Code: "AUD", Decimals: 2},
+		"JPY":  {Code: "JPY", Decimals: 0},
+		"KRW":  {Code: "KRW", Decimals: 0},
+		"VND":  {Code: "VND", Decimals: 0},
+		"IDR":  {Code: "IDR", Decimals: 0},
+		"KWD":  {Code: "KWD", Decimals: 3},
+		"BHD":  {Code: "BHD", Decimals: 3},
+		"USDT": {Code: "USDT", Decimals: 6},
+	}
+)
+
+// Register 注册或覆盖币种信息
+func Register(info Info) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	info.Code = strings.ToUpper(info.Code)
+	registry[info.Code] = info
+}
+
+// Lookup 查询币种信息，币种代码大小写不敏感
+func Lookup(code string) (Info, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	info, ok := registry[strings.ToUpper(code)]
+	return info, ok
+}
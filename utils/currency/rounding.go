@@ -0,0 +1,133 @@
+package currency
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// RoundingPolicy 舍入策略
+type RoundingPolicy int
+
+const (
+	// RoundTruncate 向零截断（原 IntPart 行为）
+	RoundTruncate RoundingPolicy = iota
+	// RoundFloor 向下取整，用于出款，避免多付
+	RoundFloor
+	// RoundCeil 向上取整，用于手续费，避免少收
+	RoundCeil
+	// RoundHalfUp 四舍五入（远离零），用于展示
+	RoundHalfUp
+	// RoundHalfEven 银行家舍入，用于对账汇总
+	RoundHalfEven
+)
+
+// String 策略名称
+func (p RoundingPolicy) String() string {
+	switch p {
+	case RoundTruncate:
+		return "truncate"
+	case RoundFloor:
+		return "floor"
+	case RoundCeil:
+		return "ceil"
+	case RoundHalfUp:
+		return "half_up"
+	case RoundHalfEven:
+		return "half_even"
+	default:
+		return fmt.Sprintf("RoundingPolicy(%d)", int(p))
+	}
+}
+
+// Round 按策略保留 places 位小数
+func (p RoundingPolicy) Round(d decimal.Decimal, places int32) decimal.Decimal {
+	switch p {
+	case RoundFloor:
+		return d.RoundFloor(places)
+	case RoundCeil:
+		return d.RoundCeil(places)
+	case RoundHalfUp:
+		return d.Round(places)
+	case RoundHalfEven:
+		return d.RoundBank(places)
+	default:
+		return d.Truncate(places)
+	}
+}
+
+// TxType 交易类型，决定默认舍入策略
+type TxType string
+
+const (
+	// TxFee 手续费
+	TxFee TxType = "fee"
+	// TxPayout 出款（提现、返佣等）
+	TxPayout TxType = "payout"
+	// TxDisplay 展示
+	TxDisplay TxType = "display"
+	// TxConversion 汇率兑换
+	TxConversion TxType = "conversion"
+)
+
+var (
+	policies = map[TxType]RoundingPolicy{
+		TxFee:        RoundCeil,
+		TxPayout:     RoundFloor,
+		TxDisplay:    RoundHalfUp,
+		TxConversion: RoundTruncate,
+	}
+	policiesMu sync.RWMutex
+)
+
+// SetPolicy 设置交易类型的默认舍入策略
+func SetPolicy(tx TxType, p RoundingPolicy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[tx] = p
+}
+
+// PolicyFor 获取交易类型的默认舍入策略，未配置时为 RoundTruncate
+func PolicyFor(tx TxType) RoundingPolicy {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+	return policies[tx]
+}
+
+// roundOptions 舍入选项
+type roundOptions struct {
+	tx       TxType
+	policy   RoundingPolicy
+	explicit bool
+}
+
+// Option 金额计算选项
+type Option func(*roundOptions)
+
+// WithRounding 指定舍入策略，优先于交易类型的默认策略
+func WithRounding(p RoundingPolicy) Option {
+	return func(o *roundOptions) {
+		o.policy = p
+		o.explicit = true
+	}
+}
+
+// WithTxType 按交易类型选择默认舍入策略，如兑换后用于出款时传 TxPayout
+func WithTxType(tx TxType) Option {
+	return func(o *roundOptions) {
+		o.tx = tx
+	}
+}
+
+// resolvePolicy 解析最终舍入策略
+func resolvePolicy(tx TxType, opts []Option) RoundingPolicy {
+	o := &roundOptions{tx: tx}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.explicit {
+		return o.policy
+	}
+	return PolicyFor(o.tx)
+}
@@ -32,6 +32,14 @@ type Config struct {
 type BusinessConfig struct {
 	KeyPrefix  string        `json:"key_prefix"`
 	Expiration time.Duration `json:"expiration"`
+	// 开启结果gzip压缩，默认关闭；旧版本节点无法读取压缩结果，需所有节点升级后再开启
+	Compress bool `json:"compress,optional"`
+	// 结果压缩阈值（字节），0 使用默认值1KB
+	CompressThreshold int `json:"compress_threshold,optional"`
+	// 结果大小上限（字节，压缩后），0 使用默认值1MB，<0 不限制
+	MaxResultSize int `json:"max_result_size,optional"`
+	// 超过上限时的策略：drop_result（默认）、reject
+	OversizePolicy OversizePolicy `json:"oversize_policy,optional"`
 }
 
 // 默认配置
@@ -87,12 +95,33 @@ func (f *Factory) GetService(businessType string) *IdemService {
 		config.KeyPrefix,
 		f.cfg.LocalCacheSize,
 		config.Expiration,
+		config.options()...,
 	)
 
 	f.services[businessType] = service
 	return service
 }
 
+// options 转换为服务选项，零值使用默认值
+func (c BusinessConfig) options() []Option {
+	var opts []Option
+	if c.Compress {
+		threshold := c.CompressThreshold
+		if threshold <= 0 {
+			threshold = DefaultCompressThreshold
+		}
+		opts = append(opts, WithCompressThreshold(threshold))
+	}
+	if c.MaxResultSize != 0 || c.OversizePolicy != "" {
+		size := c.MaxResultSize
+		if size == 0 {
+			size = DefaultMaxResultSize
+		}
+		opts = append(opts, WithMaxResultSize(size, c.OversizePolicy))
+	}
+	return opts
+}
+
 func (f *Factory) getBusinessConfig(businessType string) BusinessConfig {
 	if config, exists := f.cfg.BusinessConfigs[businessType]; exists {
 		return config
@@ -29,10 +29,11 @@ const (
 
 // IdemResult 幂等结果（扩展功能）
 type IdemResult struct {
-	Status    string      `json:"status"`           // processing, completed
-	Result    interface{} `json:"result,omitempty"` // 业务执行结果
-	Error     string      `json:"error,omitempty"`  // 错误信息
-	Timestamp int64       `json:"timestamp"`        // 时间戳
+	Status    string      `json:"status"`              // processing, completed
+	Result    interface{} `json:"result,omitempty"`    // 业务执行结果
+	Error     string      `json:"error,omitempty"`     // 错误信息
+	Timestamp int64       `json:"timestamp"`           // 时间戳
+	Truncated bool        `json:"truncated,omitempty"` // 结果超过大小上限，Result 未保存
 }
 
 // IdemService 提供幂等性检查服务
@@ -44,10 +45,14 @@ type IdemService struct {
 	expiration  time.Duration
 	lockTTL     time.Duration
 	hashPool    sync.Pool // SHA256计算复用池
+
+	compressThreshold int            // 压缩阈值
+	maxResultSize     int            // 结果大小上限
+	oversizePolicy    OversizePolicy // 超限策略
 }
 
-// NewIdempotencyService 创建幂等性服务实例
-func NewIdempotencyService(redisClient redis.UniversalClient, keyPrefix string, size int, expiration time.Duration) *IdemService {
+// NewIdempotencyService 创建幂等性服务实例，结果默认不压缩、超过1MB丢弃业务结果
+func NewIdempotencyService(redisClient redis.UniversalClient, keyPrefix string, size int, expiration time.Duration, opts ...Option) *IdemService {
 	if redisClient == nil {
 		panic("redis client is nil")
 	}
@@ -60,7 +65,7 @@ func NewIdempotencyService(redisClient redis.UniversalClient, keyPrefix string,
 		expiration = DefaultExpiration
 	}
 
-	return &IdemService{
+	s := &IdemService{
 		redisClient: redisClient,
 		localCache:  freecache.NewCache(size),
 		rs:          redislock.New(redisx.Engine),
@@ -72,7 +77,13 @@ func NewIdempotencyService(redisClient redis.UniversalClient, keyPrefix string,
 				return sha256.New()
 			},
 		},
+		maxResultSize:     DefaultMaxResultSize,
+		oversizePolicy:    OversizeDropResult,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CheckIdempotency 检查操作是否重复（核心方法）
@@ -164,7 +175,11 @@ func (s *IdemService) CheckIdempotencyWithResult(ctx context.Context, requestID
 	}
 
 	// 快速路径：检查是否已完成
-	if result := s.getResult(ctx, key); result != nil {
+	result, err := s.getResult(ctx, key)
+	if err != nil {
+		return false, nil, err
+	}
+	if result != nil {
 		return false, result, nil
 	}
 
@@ -183,7 +198,10 @@ func (s *IdemService) CheckIdempotencyWithResult(ctx context.Context, requestID
 	defer mutex.Unlock()
 
 	// 双重检查
-	if result := s.getResult(ctx, key); result != nil {
+	if result, err = s.getResult(ctx, key); err != nil {
+		return false, nil, err
+	}
+	if result != nil {
 		return false, result, nil
 	}
 
@@ -299,40 +317,44 @@ func (s *IdemService) updateLocalCache(key string, value []byte) {
 	}
 }
 
-// getResult 获取执行结果
-func (s *IdemService) getResult(ctx context.Context, key string) *IdemResult {
+// getResult 获取执行结果，键不存在时返回 nil
+// 键存在但无法解析（如旧版本节点读取压缩结果、CheckIdempotency 写入的标记）时视为处理中，不能当作新请求
+func (s *IdemService) getResult(ctx context.Context, key string) (*IdemResult, error) {
 	// 先查本地缓存
 	if data, err := s.localCache.Get([]byte(key)); err == nil {
-		var result IdemResult
-		if err = json.Unmarshal(data, &result); err == nil {
-			return &result
+		if result, err := decodeResult(data); err == nil {
+			return result, nil
 		}
 	}
 
 	// 查Redis
-	data, err := s.redisClient.Get(ctx, key).Result()
+	data, err := s.redisClient.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("get redis result error: %w", err)
 	}
 
-	var result IdemResult
-	if err = json.Unmarshal([]byte(data), &result); err != nil {
-		return nil
+	result, err := decodeResult(data)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("[getResult] undecodable result, treating as processing, key=%s, err=%v", key, err)
+		return &IdemResult{Status: "processing"}, nil
 	}
 
 	// 同步到本地缓存
-	s.updateLocalCache(key, []byte(data))
-	return &result
+	s.updateLocalCache(key, data)
+	return result, nil
 }
 
-// setResult 设置执行结果
+// setResult 设置执行结果，超过阈值时压缩，超过上限时按策略处理
 func (s *IdemService) setResult(ctx context.Context, key string, result *IdemResult) error {
-	data, err := json.Marshal(result)
+	data, err := s.encodeResult(result)
 	if err != nil {
-		return fmt.Errorf("marshal result error: %w", err)
+		return err
 	}
 
-	if err = s.redisClient.Set(ctx, key, string(data), s.expiration).Err(); err != nil {
+	if err = s.redisClient.Set(ctx, key, data, s.expiration).Err(); err != nil {
 		return fmt.Errorf("set redis result error: %w", err)
 	}
 
@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/stores/redisx"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestService(t *testing.T, opts ...Option) (*IdemService, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	engine := redisx.Engine
+	redisx.Engine = rdb
+	t.Cleanup(func() { redisx.Engine = engine })
+	return NewIdempotencyService(rdb, "test", 0, 0, opts...), mr
+}
+
+func TestResultCompressionDefaultOff(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestService(t)
+
+	big := strings.Repeat("x", 4*DefaultCompressThreshold)
+	if err := s.CompleteIdempotency(ctx, "req", nil, big, nil); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := s.generateKey("req", nil)
+	raw, _ := mr.Get(key)
+	if bytes.HasPrefix([]byte(raw), gzipMagic) {
+		t.Fatal("result compressed without WithCompressThreshold")
+	}
+
+	// 开启压缩后写入的结果，未开启压缩的节点也能读取
+	c := NewIdempotencyService(s.redisClient, "test", 0, 0, WithCompressThreshold(DefaultCompressThreshold))
+	if err := c.CompleteIdempotency(ctx, "req2", nil, big, nil); err != nil {
+		t.Fatal(err)
+	}
+	key2, _ := s.generateKey("req2", nil)
+	raw, _ = mr.Get(key2)
+	if !bytes.HasPrefix([]byte(raw), gzipMagic) {
+		t.Fatal("result not compressed")
+	}
+	isNew, result, err := s.CheckIdempotencyWithResult(ctx, "req2", nil)
+	if err != nil || isNew || result.Status != "completed" || result.Result != big {
+		t.Fatalf("isNew=%v result=%+v err=%v", isNew, result, err)
+	}
+}
+
+func TestUndecodableResultIsNotNew(t *testing.T) {
+	ctx := context.Background()
+	s, mr := newTestService(t)
+
+	for _, stored := range []string{"1", "\x1f\x8bcorrupt"} {
+		key, _ := s.generateKey("req", stored)
+		mr.Set(key, stored)
+
+		isNew, result, err := s.CheckIdempotencyWithResult(ctx, "req", stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isNew || result == nil || result.Status != "processing" {
+			t.Fatalf("stored %q: isNew=%v result=%+v", stored, isNew, result)
+		}
+		if v, _ := mr.Get(key); v != stored {
+			t.Fatalf("stored %q overwritten with %q", stored, v)
+		}
+	}
+}
+
+func TestRedisErrorIsNotNew(t *testing.T) {
+	s, mr := newTestService(t)
+	mr.Close()
+
+	isNew, _, err := s.CheckIdempotencyWithResult(context.Background(), "req", nil)
+	if err == nil || isNew {
+		t.Fatalf("isNew=%v err=%v", isNew, err)
+	}
+}
+
+func TestOversizeResult(t *testing.T) {
+	ctx := context.Background()
+	big := strings.Repeat("x", 2048)
+
+	s, _ := newTestService(t, WithMaxResultSize(1024, OversizeDropResult))
+	before := observedResults(t, s.keyPrefix)
+	if err := s.CompleteIdempotency(ctx, "req", nil, big, errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+	if n := observedResults(t, s.keyPrefix) - before; n != 1 {
+		t.Fatalf("result size observed %d times", n)
+	}
+	_, result, err := s.CheckIdempotencyWithResult(ctx, "req", nil)
+	if err != nil || !result.Truncated || result.Result != nil || result.Error != "failed" {
+		t.Fatalf("result=%+v err=%v", result, err)
+	}
+
+	s, _ = newTestService(t, WithMaxResultSize(1024, OversizeReject))
+	if err = s.CompleteIdempotency(ctx, "req", nil, big, nil); !errors.Is(err, ErrResultTooLarge) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func observedResults(t *testing.T, prefix string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := resultSizeBytes.WithLabelValues(prefix, "raw").(prometheus.Histogram).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
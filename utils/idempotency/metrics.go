@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// 存储的结果大小直方图
+	resultSizeBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "idempotency",
+			Name:      "result_size_bytes",
+			Help:      "存储的幂等结果大小（字节，压缩后）",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		},
+		[]string{"prefix", "encoding"},
+	)
+
+	// 结果超过大小上限的次数
+	resultOversizeTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "idempotency",
+			Name:      "result_oversize_total",
+			Help:      "幂等结果超过大小上限的次数",
+		},
+		[]string{"prefix", "policy"},
+	)
+
+	// 所有指标
+	allCollectors = []prometheus.Collector{
+		resultSizeBytes,
+		resultOversizeTotal,
+	}
+)
+
+// metricsCollector 汇总幂等服务的所有指标
+type metricsCollector struct{}
+
+// Describe 实现 prometheus.Collector 接口
+func (metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range allCollectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range allCollectors {
+		c.Collect(ch)
+	}
+}
+
+// Collector 返回幂等服务指标收集器，由业务方注册到自己的 Prometheus registry，所有业务共用
+func Collector() prometheus.Collector {
+	return metricsCollector{}
+}
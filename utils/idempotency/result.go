@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultCompressThreshold 开启压缩时的默认阈值，序列化后超过该大小的结果使用gzip压缩
+	DefaultCompressThreshold = 1 << 10 // 1KB
+	// DefaultMaxResultSize 默认结果大小上限（压缩后）
+	DefaultMaxResultSize = 1 << 20 // 1MB
+)
+
+// OversizePolicy 结果超过大小上限时的处理策略
+type OversizePolicy string
+
+const (
+	// OversizeDropResult 丢弃业务结果，只保存状态和错误信息，IdemResult.Truncated 为 true，重复请求仍会被拦截
+	OversizeDropResult OversizePolicy = "drop_result"
+	// OversizeReject 不保存结果，CompleteIdempotency 返回 ErrResultTooLarge
+	OversizeReject OversizePolicy = "reject"
+)
+
+// ErrResultTooLarge 结果超过大小上限
+var ErrResultTooLarge = errors.New("idempotency result too large")
+
+// gzip数据的魔数，JSON不会以此开头，用于区分压缩和未压缩的结果
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Option 幂等服务选项
+type Option func(s *IdemService)
+
+// WithCompressThreshold 开启gzip压缩并设置阈值，<=0 时不压缩（默认）
+// 旧版本节点无法读取压缩结果，需所有节点升级到支持解压的版本后再开启
+func WithCompressThreshold(n int) Option {
+	return func(s *IdemService) {
+		s.compressThreshold = n
+	}
+}
+
+// WithMaxResultSize 设置结果大小上限（压缩后）和超限策略，<=0 时不限制
+func WithMaxResultSize(n int, policy OversizePolicy) Option {
+	return func(s *IdemService) {
+		s.maxResultSize = n
+		if policy != "" {
+			s.oversizePolicy = policy
+		}
+	}
+}
+
+// encodeResult 序列化结果，超过阈值时压缩，超过上限时按策略处理
+func (s *IdemService) encodeResult(result *IdemResult) ([]byte, error) {
+	data, encoding, err := s.marshalResult(result)
+	if err != nil {
+		return nil, err
+	}
+	if s.maxResultSize > 0 && len(data) > s.maxResultSize {
+		resultOversizeTotal.WithLabelValues(s.keyPrefix, string(s.oversizePolicy)).Inc()
+		if s.oversizePolicy == OversizeReject || result.Result == nil {
+			return nil, fmt.Errorf("%w: %d bytes, max %d", ErrResultTooLarge, len(data), s.maxResultSize)
+		}
+
+		dropped := *result
+		dropped.Result = nil
+		dropped.Truncated = true
+		if data, encoding, err = s.marshalResult(&dropped); err != nil {
+			return nil, err
+		}
+	}
+
+	resultSizeBytes.WithLabelValues(s.keyPrefix, encoding).Observe(float64(len(data)))
+	return data, nil
+}
+
+// marshalResult 序列化并按阈值压缩，返回数据和编码方式
+func (s *IdemService) marshalResult(result *IdemResult) ([]byte, string, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal result error: %w", err)
+	}
+	if s.compressThreshold <= 0 || len(data) <= s.compressThreshold {
+		return data, "raw", nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("compress result error: %w", err)
+	}
+	return buf.Bytes(), "gzip", nil
+}
+
+// decodeResult 反序列化结果，兼容未压缩的旧数据
+func decodeResult(data []byte) (*IdemResult, error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+
+	var result IdemResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,238 @@
+package uniqueid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 短码字符集，按ASCII升序排列，定长短码的字典序与序号顺序一致；
+	// 去掉了 0/O、1/I/l/o 等容易混淆的字符
+	shortCodeChars = "23456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnpqrstuvwxyz"
+	// 短码长度范围
+	shortCodeMinLength = 4
+	shortCodeMaxLength = 16
+	// 每次批量预占的短码数
+	shortCodeBatchSize = 64
+	// 冲突时的最大重试轮数
+	shortCodeMaxRetries = 10
+	// 已发放短码集合键前缀，按长度分集合
+	shortCodeKeyPrefix = "uniqueid:shortcode:"
+	// 短码序号键前缀，按长度分计数器
+	shortCodeSeqKeyPrefix = "uniqueid:shortcode:seq:"
+)
+
+var (
+	// ErrShortCodeLength 短码长度超出范围
+	ErrShortCodeLength = fmt.Errorf("short code length must be between %d and %d", shortCodeMinLength, shortCodeMaxLength)
+	// ErrShortCodeExhausted 该长度的短码序号已用尽，或多次重试仍然冲突
+	ErrShortCodeExhausted = errors.New("short code space exhausted")
+)
+
+// shortCodePool 某一长度已预占未发放的短码，按升序排列
+type shortCodePool struct {
+	mu    sync.Mutex
+	codes []string
+}
+
+var (
+	// 按长度分池，下标为长度
+	shortCodePools [shortCodeMaxLength + 1]shortCodePool
+	// 未配置Redis时的进程内序号，下标为长度
+	localShortCodeSeqs [shortCodeMaxLength + 1]atomic.Int64
+)
+
+// GenShortCode 生成 n 位有序短码，用于短链接、优惠券等
+// 短码由递增序号按定长编码而来，同一进程内后生成的短码字典序更大；多进程时按批次递增，批次之间可能交错。
+// 配置了Redis（见 Init）时序号由Redis分配并在Redis集合中登记，保证全局唯一，否则仅在进程内唯一；
+// 短码按批预占后在本地缓存发放，进程退出时未发放的短码不会被复用
+func GenShortCode(n int) (string, error) {
+	if n < shortCodeMinLength || n > shortCodeMaxLength {
+		return "", ErrShortCodeLength
+	}
+
+	pool := &shortCodePools[n]
+	if code, ok := pool.pop(); ok {
+		return code, nil
+	}
+
+	// 预占时不持有锁，并发补充的批次合并后仍按升序发放
+	codes, err := reserveShortCodes(context.Background(), n, shortCodeBatchSize)
+	if err != nil {
+		return "", err
+	}
+	pool.push(codes)
+
+	if code, ok := pool.pop(); ok {
+		return code, nil
+	}
+	return "", ErrShortCodeExhausted
+}
+
+// GenShortCodes 批量生成 count 个 n 位有序短码，如批量发放优惠券
+func GenShortCodes(n, count int) ([]string, error) {
+	if n < shortCodeMinLength || n > shortCodeMaxLength {
+		return nil, ErrShortCodeLength
+	}
+	if count <= 0 {
+		return nil, nil
+	}
+	return reserveShortCodes(context.Background(), n, count)
+}
+
+// ReleaseShortCode 释放短码（如优惠券作废），从Redis的已发放集合中移除；
+// 序号不会回退，已释放的短码不会再次生成
+func ReleaseShortCode(code string) error {
+	if rdb := shortCodeRedis(); rdb != nil {
+		if err := rdb.SRem(context.Background(), shortCodeKey(len(code)), code).Err(); err != nil {
+			return fmt.Errorf("release short code: %w", err)
+		}
+	}
+	return nil
+}
+
+// pop 取出最小的短码
+func (p *shortCodePool) pop() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.codes) == 0 {
+		return "", false
+	}
+	code := p.codes[0]
+	p.codes = p.codes[1:]
+	return code, true
+}
+
+// push 放入一批短码并保持升序
+func (p *shortCodePool) push(codes []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.codes = append(p.codes, codes...)
+	slices.Sort(p.codes)
+}
+
+// reserveShortCodes 分配连续序号编码为短码并登记，已被登记的部分跳过后继续分配
+func reserveShortCodes(ctx context.Context, n, count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < shortCodeMaxRetries && len(codes) < count; i++ {
+		candidates, err := nextShortCodes(ctx, n, count-len(codes))
+		if err != nil {
+			return nil, err
+		}
+
+		accepted, err := registerShortCodes(ctx, n, candidates)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, accepted...)
+	}
+
+	if len(codes) < count {
+		return nil, ErrShortCodeExhausted
+	}
+	return codes, nil
+}
+
+// nextShortCodes 分配 count 个连续序号并编码为 n 位短码
+func nextShortCodes(ctx context.Context, n, count int) ([]string, error) {
+	var end int64
+	if rdb := shortCodeRedis(); rdb != nil {
+		var err error
+		if end, err = rdb.IncrBy(ctx, shortCodeSeqKey(n), int64(count)).Result(); err != nil {
+			return nil, fmt.Errorf("reserve short code sequence: %w", err)
+		}
+	} else {
+		end = localShortCodeSeqs[n].Add(int64(count))
+	}
+
+	// 序号从1开始，编码值从0开始
+	start := end - int64(count)
+	if capacity := shortCodeCapacity(n); end > capacity {
+		return nil, ErrShortCodeExhausted
+	}
+
+	codes := make([]string, count)
+	for i := range codes {
+		codes[i] = encodeShortCode(start+int64(i), n)
+	}
+	return codes, nil
+}
+
+// registerShortCodes 在Redis集合中登记候选短码，返回登记成功（此前未发放）的短码；
+// 未配置Redis时序号在进程内唯一，无需登记
+func registerShortCodes(ctx context.Context, n int, candidates []string) ([]string, error) {
+	rdb := shortCodeRedis()
+	if rdb == nil {
+		return candidates, nil
+	}
+
+	// 批量SADD，返回1表示新登记
+	key := shortCodeKey(n)
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(candidates))
+	for i, code := range candidates {
+		cmds[i] = pipe.SAdd(ctx, key, code)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("reserve short codes: %w", err)
+	}
+
+	accepted := make([]string, 0, len(candidates))
+	for i, cmd := range cmds {
+		if cmd.Val() == 1 {
+			accepted = append(accepted, candidates[i])
+		}
+	}
+	return accepted, nil
+}
+
+// encodeShortCode 将序号编码为 n 位短码，高位补字符集首字符
+func encodeShortCode(v int64, n int) string {
+	code := []byte(strings.Repeat(shortCodeChars[:1], n))
+	base := int64(len(shortCodeChars))
+	for i := n - 1; i >= 0 && v > 0; i-- {
+		code[i] = shortCodeChars[v%base]
+		v /= base
+	}
+	return string(code)
+}
+
+// shortCodeCapacity n 位短码的数量，超过 int64 时为 math.MaxInt64
+func shortCodeCapacity(n int) int64 {
+	base := int64(len(shortCodeChars))
+	capacity := int64(1)
+	for i := 0; i < n; i++ {
+		if capacity > math.MaxInt64/base {
+			return math.MaxInt64
+		}
+		capacity *= base
+	}
+	return capacity
+}
+
+// shortCodeKey 已发放短码集合键
+func shortCodeKey(n int) string {
+	return fmt.Sprintf("%s%d", shortCodeKeyPrefix, n)
+}
+
+// shortCodeSeqKey 短码序号键
+func shortCodeSeqKey(n int) string {
+	return fmt.Sprintf("%s%d", shortCodeSeqKeyPrefix, n)
+}
+
+// shortCodeRedis 获取 Init 配置的Redis
+func shortCodeRedis() redis.UniversalClient {
+	engineMu.RLock()
+	defer engineMu.RUnlock()
+	return rdb
+}
@@ -0,0 +1,142 @@
+package uniqueid
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGenShortCodeOrdered(t *testing.T) {
+	tests := []struct {
+		name  string
+		redis bool
+	}{
+		{"local", false},
+		{"redis", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.redis {
+				initRedis(t)
+			} else {
+				Init(nil)
+			}
+
+			// 长度各不相同，避免子测试之间共用本地池
+			n := 5
+			if tt.redis {
+				n = 6
+			}
+			prev := ""
+			for i := 0; i < 200; i++ {
+				code, err := GenShortCode(n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(code) != n || code <= prev {
+					t.Fatalf("code %q after %q is not an ordered %d-char code", code, prev, n)
+				}
+				prev = code
+			}
+
+			batch, err := GenShortCodes(n, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.IsSorted(batch) || batch[0] <= prev {
+				t.Fatalf("batch %v is not ordered after %q", batch[:3], prev)
+			}
+		})
+	}
+}
+
+func TestGenShortCodeConcurrentUnique(t *testing.T) {
+	Init(nil)
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]bool)
+		wg   sync.WaitGroup
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev := ""
+			for i := 0; i < 500; i++ {
+				code, err := GenShortCode(7)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				// 单个调用方看到的短码递增
+				if code <= prev {
+					t.Errorf("code %q after %q", code, prev)
+					return
+				}
+				prev = code
+
+				mu.Lock()
+				if seen[code] {
+					t.Errorf("duplicate code %q", code)
+				}
+				seen[code] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGenShortCodeSkipsRegistered(t *testing.T) {
+	rdb := initRedis(t)
+	ctx := context.Background()
+
+	// 已登记的短码（如旧版本发放的）不会再次发放
+	next := encodeShortCode(redisShortCodeSeq(t, 8), 8)
+	if err := rdb.SAdd(ctx, shortCodeKey(8), next).Err(); err != nil {
+		t.Fatal(err)
+	}
+	codes, err := GenShortCodes(8, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(codes, next) || len(codes) != 3 {
+		t.Fatalf("codes %v include registered code %q", codes, next)
+	}
+}
+
+// redisShortCodeSeq Redis中 n 位短码的下一个编码值
+func redisShortCodeSeq(t *testing.T, n int) int64 {
+	t.Helper()
+	v, err := testRedis.Get(context.Background(), shortCodeSeqKey(n)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestEncodeShortCode(t *testing.T) {
+	tests := []struct {
+		v    int64
+		n    int
+		want string
+	}{
+		{0, 4, "2222"},
+		{1, 4, "2223"},
+		{int64(len(shortCodeChars)), 4, "2232"},
+		{shortCodeCapacity(4) - 1, 4, "zzzz"},
+	}
+	for _, tt := range tests {
+		if got := encodeShortCode(tt.v, tt.n); got != tt.want {
+			t.Errorf("encodeShortCode(%d, %d) = %q, want %q", tt.v, tt.n, got, tt.want)
+		}
+	}
+	if !slices.IsSorted([]string{encodeShortCode(9, 4), encodeShortCode(55, 4), encodeShortCode(56, 4), encodeShortCode(3000, 4)}) {
+		t.Fatal("encoded codes are not in sequence order")
+	}
+}
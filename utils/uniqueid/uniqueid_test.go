@@ -0,0 +1,156 @@
+package uniqueid
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/idgen"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// 共享的Redis，idgen 的号段缓存为进程级，测试间不能重置计数器
+var testRedis redis.UniversalClient
+
+func TestMain(m *testing.M) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	testRedis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	code := m.Run()
+	testRedis.Close()
+	mr.Close()
+	os.Exit(code)
+}
+
+// initRedis 使用共享Redis初始化共享引擎
+func initRedis(t *testing.T) redis.UniversalClient {
+	t.Helper()
+	Init(testRedis)
+	return testRedis
+}
+
+func TestGenSnowIDWithLengthCompat(t *testing.T) {
+	initRedis(t)
+
+	tests := []struct {
+		name             string
+		minDigits        int
+		maxDigits        int
+		wantMin, wantMax int
+	}{
+		{"six digits", 6, 6, 6, 6},
+		{"seven digits", 7, 7, 7, 7},
+		{"ten digits", 10, 10, 10, 10},
+		{"default range", 0, 0, 6, 12},
+		{"invalid range", 13, 20, 6, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				id, err := GenSnowIDWithLength(tt.minDigits, tt.maxDigits)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n := len(strconv.FormatUint(id, 10)); n < tt.wantMin || n > tt.wantMax {
+					t.Fatalf("id %d has %d digits, want %d-%d", id, n, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+
+	if id, err := GenDefaultSnowID(); err != nil || id < 100000 || id > 999999999999 {
+		t.Fatalf("GenDefaultSnowID = %d, %v", id, err)
+	}
+	if id, err := GenUserID(); err != nil || len(strconv.FormatUint(id, 10)) != 10 {
+		t.Fatalf("GenUserID = %d, %v", id, err)
+	}
+}
+
+func TestCompactIDsUnique(t *testing.T) {
+	initRedis(t)
+
+	for _, digits := range []int{6, 7} {
+		seen := make(map[uint64]bool)
+		for i := 0; i < 3000; i++ {
+			id, err := GenSnowIDWithLength(digits, digits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seen[id] {
+				t.Fatalf("duplicate %d-digit id %d after %d ids", digits, id, i)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestSharedSequenceWithIDGen(t *testing.T) {
+	rdb := initRedis(t)
+	x := idgen.NewIDGenX(rdb)
+
+	// 兼容层和 idgen 交替生成，使用同一个Redis分段，不会重复
+	seen := make(map[int64]bool)
+	for i := 0; i < 2000; i++ {
+		legacy, err := GenSnowIDWithLength(10, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		current, err := x.GenIDWithDigits(10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range []int64{int64(legacy), current} {
+			if seen[id] {
+				t.Fatalf("duplicate id %d across packages", id)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestInviteCodeCompat(t *testing.T) {
+	// 调用方的选项切片有剩余容量时不被修改
+	opts := make([]idgen.Option, 1, 4)
+	opts[0] = idgen.WithInviteCodeLength(8)
+	Init(nil, opts...)
+	if extended := opts[:cap(opts)]; extended[1] != nil {
+		t.Fatal("Init modified the caller's options")
+	}
+
+	code, err := GenInviteCode(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != inviteCodeLength {
+		t.Fatalf("invite code %q length = %d", code, len(code))
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(legacyInviteCodeChars, c) {
+			t.Fatalf("invite code %q has char %q outside legacy charset", code, c)
+		}
+	}
+	if _, err = GenInviteCode(0); err == nil {
+		t.Fatal("GenInviteCode(0) succeeded")
+	}
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code, true},
+		{strings.ToLower(code), true},
+		{"ABC12", false},
+		{"ABC1234", false},
+		{"ABCDEO", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := VerifyInviteCode(tt.code); got != tt.want {
+			t.Errorf("VerifyInviteCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package xhttp
 import (
 	"context"
 	"github.com/QuantumShiftX/golib/gerr"
+	"github.com/QuantumShiftX/golib/metadata"
 	"github.com/QuantumShiftX/golib/xerr"
 	"github.com/zeromicro/go-zero/core/trace"
 	"github.com/zeromicro/go-zero/rest/httpx"
@@ -102,7 +103,7 @@ func wrapBaseResponse(v any, ctx context.Context) BaseResponse[any] {
 	switch data := v.(type) {
 	case *xerr.XErr:
 		resp.Code = int(data.Code)
-		resp.Message = data.Msg
+		resp.Message = localizeMessage(ctx, data)
 	case *gerr.GError:
 		resp.Code = int(data.Code)
 		resp.Message = data.Msg
@@ -125,3 +126,11 @@ func wrapBaseResponse(v any, ctx context.Context) BaseResponse[any] {
 	}
 	return resp
 }
+
+// localizeMessage 按上下文中的语言（见 middleware.LocaleMiddleware）本地化错误消息
+func localizeMessage(ctx context.Context, err *xerr.XErr) string {
+	if ctx == nil {
+		return err.Msg
+	}
+	return xerr.LocalizeMessage(err, metadata.GetLanguageFromCtx(ctx))
+}
@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/QuantumShiftX/golib/utils/currency"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// 默认的币种字段名
+const defaultCurrencyField = "CurrencyCode"
+
+// amountMatchesCurrency 金额小数位不超过同级币种字段对应币种的精度
+// 用法: Amount string `validate:"amount_matches_currency=CurrencyCode"`，参数为币种字段的Go字段名，默认 CurrencyCode
+// 支持 string、float、decimal.Decimal 和整数类型的金额；币种未注册时校验失败
+func amountMatchesCurrency(fl validator.FieldLevel) bool {
+	param := fl.Param()
+	if param == "" {
+		param = defaultCurrencyField
+	}
+
+	currencyField, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), param)
+	if !found || kind != reflect.String {
+		return false
+	}
+	info, ok := currency.Lookup(currencyField.String())
+	if !ok {
+		return false
+	}
+
+	amount, ok := amountDecimal(fl.Field())
+	if !ok {
+		return false
+	}
+	// 截断到币种精度后不变，说明没有多余的小数位（末尾的0不计）
+	return amount.Equal(amount.Truncate(info.Decimals))
+}
+
+// amountDecimal 将金额字段转换为decimal，空字符串视为0
+func amountDecimal(field reflect.Value) (decimal.Decimal, bool) {
+	if d, ok := field.Interface().(decimal.Decimal); ok {
+		return d, true
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if field.String() == "" {
+			return decimal.Zero, true
+		}
+		d, err := decimal.NewFromString(field.String())
+		return d, err == nil
+	case reflect.Float32:
+		// 按float32精度格式化，避免 0.1 变成 0.10000000149
+		d, err := decimal.NewFromString(strconv.FormatFloat(field.Float(), 'f', -1, 32))
+		return d, err == nil
+	case reflect.Float64:
+		return decimal.NewFromFloat(field.Float()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return decimal.NewFromInt(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decimal.NewFromUint64(field.Uint()), true
+	default:
+		return decimal.Decimal{}, false
+	}
+}
@@ -0,0 +1,38 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAmountMatchesCurrency(t *testing.T) {
+	Init()
+
+	type deposit struct {
+		Amount       string          `json:"amount" validate:"amount_matches_currency"`
+		Fee          decimal.Decimal `json:"fee" validate:"amount_matches_currency=CurrencyCode"`
+		CurrencyCode string          `json:"currency_code"`
+	}
+
+	cases := []struct {
+		req deposit
+		ok  bool
+	}{
+		{deposit{Amount: "100", Fee: decimal.RequireFromString("1"), CurrencyCode: "JPY"}, true},
+		{deposit{Amount: "0.001", CurrencyCode: "JPY"}, false},
+		{deposit{Amount: "12.50", Fee: decimal.RequireFromString("0.10"), CurrencyCode: "usd"}, true},
+		{deposit{Amount: "12.345", CurrencyCode: "USD"}, false},
+		{deposit{Amount: "1", Fee: decimal.RequireFromString("0.001"), CurrencyCode: "CNY"}, false},
+		{deposit{Amount: "1.000001", CurrencyCode: "USDT"}, true},
+		{deposit{Amount: "1", CurrencyCode: "XXX"}, false},
+	}
+
+	for _, c := range cases {
+		err := Validate(&c.req)
+		if (err == nil) != c.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", c.req, err, c.ok)
+		}
+		t.Log(c.req.Amount, c.req.CurrencyCode, err)
+	}
+}
@@ -0,0 +1,73 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// 已注册的枚举
+var (
+	enumMu sync.RWMutex
+	enums  = make(map[string][]string)
+)
+
+// RegisterEnum 注册枚举取值，配合 enum 标签使用，重复注册时覆盖
+// 用法: RegisterEnum("order_status", []string{"pending", "paid"})，Status string `validate:"enum=order_status"`
+func RegisterEnum(name string, values []string) {
+	enumMu.Lock()
+	defer enumMu.Unlock()
+	enums[name] = slices.Clone(values)
+}
+
+// RegisterEnumOf 以Go常量注册枚举取值，整数类型按十进制字符串比较
+// 用法: RegisterEnumOf("order_status", OrderStatusPending, OrderStatusPaid)
+func RegisterEnumOf[T ~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](name string, values ...T) {
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		strs = append(strs, fmt.Sprint(v))
+	}
+	RegisterEnum(name, strs)
+}
+
+// EnumValues 获取已注册的枚举取值
+func EnumValues(name string) ([]string, bool) {
+	enumMu.RLock()
+	defer enumMu.RUnlock()
+	values, ok := enums[name]
+	return slices.Clone(values), ok
+}
+
+// enum 字段值在已注册的枚举取值中
+// 用法: Status string `validate:"enum=order_status"`，支持字符串和整数类型；枚举未注册时校验失败
+func enum(fl validator.FieldLevel) bool {
+	values, ok := EnumValues(fl.Param())
+	if !ok {
+		return false
+	}
+
+	field := fl.Field()
+	var value string
+	switch field.Kind() {
+	case reflect.String:
+		value = field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = strconv.FormatUint(field.Uint(), 10)
+	default:
+		return false
+	}
+	return slices.Contains(values, value)
+}
+
+// enumValuesText 错误消息中列出的枚举取值
+func enumValuesText(name string) string {
+	values, _ := EnumValues(name)
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,47 @@
+package validator
+
+import "testing"
+
+type orderStatus string
+
+const (
+	orderStatusPending orderStatus = "pending"
+	orderStatusPaid    orderStatus = "paid"
+)
+
+func TestEnum(t *testing.T) {
+	Init()
+	RegisterEnumOf("test_order_status", orderStatusPending, orderStatusPaid)
+	RegisterEnumOf("test_level", 1, 2, 3)
+
+	type order struct {
+		Status orderStatus `json:"status" validate:"enum=test_order_status"`
+		Level  int         `json:"level" validate:"omitempty,enum=test_level"`
+	}
+
+	cases := []struct {
+		req order
+		ok  bool
+	}{
+		{order{Status: orderStatusPaid, Level: 2}, true},
+		{order{Status: orderStatusPending}, true},
+		{order{Status: "refunded"}, false},
+		{order{Status: orderStatusPaid, Level: 4}, false},
+	}
+
+	for _, c := range cases {
+		err := Validate(&c.req)
+		if (err == nil) != c.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", c.req, err, c.ok)
+		}
+		t.Log(c.req, err)
+	}
+
+	type unknown struct {
+		Status string `validate:"enum=not_registered"`
+	}
+	if err := Validate(&unknown{Status: "x"}); err == nil {
+		t.Error("expected error for unregistered enum")
+	}
+	t.Log(ValidateZH(&order{Status: "refunded"}))
+}
@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	// ErrInvalidPhone 手机号格式错误
+	ErrInvalidPhone = errors.New("invalid phone number")
+	// ErrUnknownPhoneRegion 无法确定手机号所属地区
+	ErrUnknownPhoneRegion = errors.New("unknown phone region")
+)
+
+// phoneRegion 地区号码规则
+type phoneRegion struct {
+	// 国际区号
+	countryCode string
+	// 国内长途前缀
+	trunkPrefix string
+	// 去掉国际区号和长途前缀后的国内号码格式
+	pattern *regexp.Regexp
+}
+
+// 各地区号码规则，key 为 ISO 3166-1 alpha-2 地区码
+var phoneRegions = map[string]phoneRegion{
+	"CN": {"86", "0", regexp.MustCompile(`^(1[3-9]\d{9}|[2-9]\d{9,10})$`)},
+	"HK": {"852", "", regexp.MustCompile(`^[2-9]\d{7}$`)},
+	"MO": {"853", "", regexp.MustCompile(`^[2-8]\d{7}$`)},
+	"TW": {"886", "0", regexp.MustCompile(`^(9\d{8}|[2-8]\d{7,8})$`)},
+	"SG": {"65", "", regexp.MustCompile(`^[3689]\d{7}$`)},
+	"MY": {"60", "0", regexp.MustCompile(`^(1\d{8,9}|[3-9]\d{7,8})$`)},
+	"TH": {"66", "0", regexp.MustCompile(`^([689]\d{8}|[2-57]\d{7})$`)},
+	"VN": {"84", "0", regexp.MustCompile(`^([35789]\d{8}|2\d{9})$`)},
+	"ID": {"62", "0", regexp.MustCompile(`^(8\d{8,11}|[2-7]\d{7,10})$`)},
+	"PH": {"63", "0", regexp.MustCompile(`^(9\d{9}|[2-8]\d{7,9})$`)},
+	"IN": {"91", "0", regexp.MustCompile(`^[1-9]\d{9}$`)},
+	"JP": {"81", "0", regexp.MustCompile(`^[1-9]\d{8,9}$`)},
+	"KR": {"82", "0", regexp.MustCompile(`^(1\d{8,9}|[2-6]\d{7,9})$`)},
+	"US": {"1", "1", regexp.MustCompile(`^[2-9]\d{2}[2-9]\d{6}$`)},
+	"CA": {"1", "1", regexp.MustCompile(`^[2-9]\d{2}[2-9]\d{6}$`)},
+	"GB": {"44", "0", regexp.MustCompile(`^[1-9]\d{9}$`)},
+	"BR": {"55", "0", regexp.MustCompile(`^[1-9]{2}9?\d{8}$`)},
+	"AE": {"971", "0", regexp.MustCompile(`^(5\d{8}|[2-9]\d{7})$`)},
+}
+
+// 号码中允许出现的分隔符
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "", " ", "")
+
+// NormalizePhone 将手机号规范化为 E.164 格式（如 +8613800138000）
+// 号码带 + 或 00 国际前缀时按国际区号解析，否则按 defaultRegion 解析
+func NormalizePhone(raw, defaultRegion string) (string, error) {
+	number := phoneSeparators.Replace(strings.TrimSpace(raw))
+	if number == "" {
+		return "", ErrInvalidPhone
+	}
+
+	// 国际格式
+	if strings.HasPrefix(number, "+") || strings.HasPrefix(number, "00") {
+		if strings.HasPrefix(number, "+") {
+			number = number[1:]
+		} else {
+			number = number[2:]
+		}
+		if !isDigits(number) {
+			return "", ErrInvalidPhone
+		}
+		return normalizeInternationalPhone(number)
+	}
+
+	if !isDigits(number) {
+		return "", ErrInvalidPhone
+	}
+
+	// 国内格式
+	region, ok := phoneRegions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownPhoneRegion, defaultRegion)
+	}
+
+	national, ok := matchNationalNumber(region, number)
+	if !ok {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + region.countryCode + national, nil
+}
+
+// NormalizePhoneCtx 规范化手机号，地区取自上下文中的 metadata 区域
+func NormalizePhoneCtx(ctx context.Context, raw string) (string, error) {
+	return NormalizePhone(raw, metadata.GetRegionFromCtx(ctx))
+}
+
+// IsValidPhone 检查手机号是否有效
+func IsValidPhone(raw, defaultRegion string) bool {
+	_, err := NormalizePhone(raw, defaultRegion)
+	return err == nil
+}
+
+// 按国际区号解析号码，区号为1到3位，取能匹配号码规则的区号
+func normalizeInternationalPhone(number string) (string, error) {
+	for l := 1; l <= 3 && l < len(number); l++ {
+		cc := number[:l]
+		for _, region := range phoneRegions {
+			if region.countryCode != cc {
+				continue
+			}
+			if national, ok := matchNationalNumber(region, number[l:]); ok {
+				return "+" + cc + national, nil
+			}
+		}
+	}
+
+	return "", ErrInvalidPhone
+}
+
+// 匹配国内号码，必要时去掉长途前缀
+func matchNationalNumber(region phoneRegion, number string) (string, bool) {
+	if region.pattern.MatchString(number) {
+		return number, true
+	}
+
+	if region.trunkPrefix != "" && strings.HasPrefix(number, region.trunkPrefix) {
+		national := strings.TrimPrefix(number, region.trunkPrefix)
+		if region.pattern.MatchString(national) {
+			return national, true
+		}
+	}
+
+	return "", false
+}
+
+// 是否全部为数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// 手机号验证，可通过参数指定地区，如 `validate:"phone=CN"`
+// 未指定地区时使用上下文中的 metadata 区域
+func phone(ctx context.Context, fl validator.FieldLevel) bool {
+	s, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	region := fl.Param()
+	if region == "" && ctx != nil {
+		region = metadata.GetRegionFromCtx(ctx)
+	}
+
+	return IsValidPhone(s, region)
+}
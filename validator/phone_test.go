@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/metadata"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		{"138 0013 8000", "CN", "+8613800138000"},
+		{"+86 138-0013-8000", "", "+8613800138000"},
+		{"0086 13800138000", "US", "+8613800138000"},
+		{"(202) 555-0123", "US", "+12025550123"},
+		{"1 202 555 0123", "US", "+12025550123"},
+		{"0812 3456 7890", "ID", "+6281234567890"},
+		{"9123 4567", "SG", "+6591234567"},
+		{"090-1234-5678", "JP", "+819012345678"},
+	}
+
+	for _, c := range cases {
+		got, err := NormalizePhone(c.raw, c.region)
+		if err != nil || got != c.want {
+			t.Errorf("NormalizePhone(%q, %q) = %q, %v; want %q", c.raw, c.region, got, err, c.want)
+		}
+	}
+
+	if _, err := NormalizePhone("12345", "CN"); err == nil {
+		t.Error("expected error for short number")
+	}
+}
+
+func TestPhoneTag(t *testing.T) {
+	Init()
+
+	type req struct {
+		Phone string `json:"phone" validate:"phone"`
+	}
+
+	ctx := metadata.WithMetadata(context.Background(), metadata.CtxRegion, "th")
+	if err := ValidateWithLangCtx(ctx, &req{Phone: "081 234 5678"}, LangEN); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateWithLangCtx(ctx, &req{Phone: "13800138000"}, LangZH); err == nil {
+		t.Error("expected error for CN number in TH region")
+	} else {
+		t.Log(err)
+	}
+}
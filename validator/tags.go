@@ -24,6 +24,11 @@ func registerTags() {
 	_ = validate.RegisterValidation("password", validatePassword)
 	_ = validate.RegisterValidation("iso639_1", validateLanguageCode)
 	_ = validate.RegisterValidation("valid_timestamp", validTimestamp)
+	_ = validate.RegisterValidationCtx("phone", phone)
+	_ = validate.RegisterValidation("amount_matches_currency", amountMatchesCurrency)
+	_ = validate.RegisterValidation("no_duplicates", noDuplicates)
+	_ = validate.RegisterValidation("unique_by", uniqueBy)
+	_ = validate.RegisterValidation("enum", enum)
 }
 
 // 英文字母加数字
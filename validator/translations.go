@@ -123,6 +123,46 @@ func registerEnglishTranslations(trans ut.Translator) {
 		t, _ := ut.T("valid_timestamp", fe.Field())
 		return t
 	})
+
+	// 手机号验证
+	_ = validate.RegisterTranslation("phone", trans, func(ut ut.Translator) error {
+		return ut.Add("phone", "{0} must be a valid phone number", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("phone", fe.Field())
+		return t
+	})
+
+	// 金额精度与币种匹配
+	_ = validate.RegisterTranslation("amount_matches_currency", trans, func(ut ut.Translator) error {
+		return ut.Add("amount_matches_currency", "{0} has more decimal places than the currency allows", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("amount_matches_currency", fe.Field())
+		return t
+	})
+
+	// 切片元素不重复
+	_ = validate.RegisterTranslation("no_duplicates", trans, func(ut ut.Translator) error {
+		return ut.Add("no_duplicates", "{0} must not contain duplicate elements", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("no_duplicates", fe.Field())
+		return t
+	})
+
+	// 切片元素的字段不重复
+	_ = validate.RegisterTranslation("unique_by", trans, func(ut ut.Translator) error {
+		return ut.Add("unique_by", "{0} must not contain duplicate {1}", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("unique_by", fe.Field(), fe.Param())
+		return t
+	})
+
+	// 枚举取值
+	_ = validate.RegisterTranslation("enum", trans, func(ut ut.Translator) error {
+		return ut.Add("enum", "{0} must be one of [{1}]", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("enum", fe.Field(), enumValuesText(fe.Param()))
+		return t
+	})
 }
 
 // 注册中文自定义错误消息
@@ -230,4 +270,44 @@ func registerChineseTranslations(trans ut.Translator) {
 		t, _ := ut.T("valid_timestamp", fe.Field())
 		return t
 	})
+
+	// 手机号验证
+	_ = validate.RegisterTranslation("phone", trans, func(ut ut.Translator) error {
+		return ut.Add("phone", "{0}必须是有效的手机号码", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("phone", fe.Field())
+		return t
+	})
+
+	// 金额精度与币种匹配
+	_ = validate.RegisterTranslation("amount_matches_currency", trans, func(ut ut.Translator) error {
+		return ut.Add("amount_matches_currency", "{0}的小数位数超出了币种允许的精度", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("amount_matches_currency", fe.Field())
+		return t
+	})
+
+	// 切片元素不重复
+	_ = validate.RegisterTranslation("no_duplicates", trans, func(ut ut.Translator) error {
+		return ut.Add("no_duplicates", "{0}不能包含重复的元素", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("no_duplicates", fe.Field())
+		return t
+	})
+
+	// 切片元素的字段不重复
+	_ = validate.RegisterTranslation("unique_by", trans, func(ut ut.Translator) error {
+		return ut.Add("unique_by", "{0}中的{1}不能重复", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("unique_by", fe.Field(), fe.Param())
+		return t
+	})
+
+	// 枚举取值
+	_ = validate.RegisterTranslation("enum", trans, func(ut ut.Translator) error {
+		return ut.Add("enum", "{0}必须是[{1}]中的一个", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("enum", fe.Field(), enumValuesText(fe.Param()))
+		return t
+	})
 }
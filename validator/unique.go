@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// noDuplicates 切片或数组中没有重复元素
+// 用法: IDs []int64 `validate:"no_duplicates"`，元素为结构体时整体比较，指针按指向的值比较
+func noDuplicates(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Array {
+		return false
+	}
+
+	values := make([]reflect.Value, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		values = append(values, reflect.Indirect(field.Index(i)))
+	}
+	return !hasDuplicate(values)
+}
+
+// uniqueBy 切片中结构体元素的指定字段不重复
+// 用法: Items []Item `validate:"unique_by=LineID"`，参数为Go字段名，支持 A.B 形式的嵌套字段；nil 元素忽略
+func uniqueBy(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Array {
+		return false
+	}
+	path := strings.Split(fl.Param(), ".")
+
+	values := make([]reflect.Value, 0, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		v, ok := fieldByPath(field.Index(i), path)
+		if !ok {
+			if field.Index(i).Kind() == reflect.Ptr && field.Index(i).IsNil() {
+				continue
+			}
+			return false
+		}
+		values = append(values, v)
+	}
+	return !hasDuplicate(values)
+}
+
+// fieldByPath 按字段路径取值，遇到 nil 指针时返回 false
+func fieldByPath(v reflect.Value, path []string) (reflect.Value, bool) {
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		if v = v.FieldByName(name); !v.IsValid() {
+			return reflect.Value{}, false
+		}
+	}
+	return reflect.Indirect(v), true
+}
+
+// hasDuplicate 是否存在重复值，可比较类型使用map，其他类型逐一深度比较
+func hasDuplicate(values []reflect.Value) bool {
+	if len(values) < 2 {
+		return false
+	}
+
+	if allComparable(values) {
+		seen := make(map[any]struct{}, len(values))
+		for _, v := range values {
+			key := v.Interface()
+			if _, ok := seen[key]; ok {
+				return true
+			}
+			seen[key] = struct{}{}
+		}
+		return false
+	}
+
+	for i := range values {
+		for j := i + 1; j < len(values); j++ {
+			if values[i].IsValid() && values[j].IsValid() &&
+				values[i].CanInterface() && values[j].CanInterface() &&
+				reflect.DeepEqual(values[i].Interface(), values[j].Interface()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allComparable 所有值都可作为map键（包括接口中的动态值）
+func allComparable(values []reflect.Value) bool {
+	for _, v := range values {
+		if !v.IsValid() || !v.CanInterface() || !v.Comparable() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestUniqueValidators(t *testing.T) {
+	Init()
+
+	type line struct {
+		LineID int64  `json:"line_id"`
+		SKU    string `json:"sku"`
+	}
+	type batchOrder struct {
+		Lines   []*line  `json:"lines" validate:"unique_by=LineID"`
+		Coupons []string `json:"coupons" validate:"no_duplicates"`
+		Items   []line   `json:"items" validate:"no_duplicates"`
+	}
+
+	cases := []struct {
+		req batchOrder
+		ok  bool
+	}{
+		{batchOrder{}, true},
+		{batchOrder{Lines: []*line{{LineID: 1}, {LineID: 2}, nil}, Coupons: []string{"a", "b"}}, true},
+		{batchOrder{Lines: []*line{{LineID: 1, SKU: "x"}, {LineID: 1, SKU: "y"}}}, false},
+		{batchOrder{Coupons: []string{"a", "a"}}, false},
+		{batchOrder{Items: []line{{LineID: 1, SKU: "x"}, {LineID: 1, SKU: "y"}}}, true},
+		{batchOrder{Items: []line{{LineID: 1, SKU: "x"}, {LineID: 1, SKU: "x"}}}, false},
+	}
+
+	for _, c := range cases {
+		err := ValidateZH(&c.req)
+		if (err == nil) != c.ok {
+			t.Errorf("Validate(%+v) = %v, want ok=%v", c.req, err, c.ok)
+		}
+		t.Log(err)
+	}
+}
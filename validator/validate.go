@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"context"
 	"errors"
+	"github.com/QuantumShiftX/golib/metadata"
 	"github.com/QuantumShiftX/golib/xerr"
 	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
@@ -98,15 +100,34 @@ func ValidateZH(req interface{}) error {
 	return ValidateWithLang(req, LangZH)
 }
 
+// ValidateCtx 使用上下文中的语言验证（见 middleware.LocaleMiddleware），如 zh-CN 使用中文
+func ValidateCtx(ctx context.Context, req interface{}) error {
+	return ValidateWithLangCtx(ctx, req, LangFromCtx(ctx))
+}
+
+// LangFromCtx 将上下文中的语言映射为支持的校验语言，不支持时返回英语
+func LangFromCtx(ctx context.Context) string {
+	lang := strings.ToLower(metadata.GetLanguageFromCtx(ctx))
+	if base, _, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); base == LangZH {
+		return LangZH
+	}
+	return LangEN
+}
+
 // ValidateWithLang 使用指定语言验证
 func ValidateWithLang(req interface{}, lang string) error {
+	return ValidateWithLangCtx(context.Background(), req, lang)
+}
+
+// ValidateWithLangCtx 使用指定语言验证，上下文会传递给依赖上下文的验证规则（如 phone）
+func ValidateWithLangCtx(ctx context.Context, req interface{}, lang string) error {
 	// 检查语言是否支持，不支持则使用默认语言(英语)
 	translator, ok := translators[lang]
 	if !ok {
 		translator = translators[LangEN]
 	}
 
-	if err := validate.Struct(req); err != nil {
+	if err := validate.StructCtx(ctx, req); err != nil {
 		// 将验证错误转换为翻译后的错误信息
 		var errs validator.ValidationErrors
 		if errors.As(err, &errs) {
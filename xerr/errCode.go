@@ -12,6 +12,7 @@ const (
 	DbError                ErrCode = 600 // 数据库错误
 	CaptchaError           ErrCode = 700 // 验证码错误
 	GoogleAuthCodeRequired ErrCode = 701 // 需要google验证码
+	QuotaExceededError     ErrCode = 800 // 超出配额
 )
 
 // 定义预设错误为 *XErr 类型
@@ -24,6 +25,7 @@ var (
 	ErrDB                     = &XErr{Code: DbError, Msg: "db error"}
 	ErrCaptcha                = &XErr{Code: CaptchaError, Msg: "captcha error"}
 	ErrGoogleAuthCodeRequired = &XErr{Code: GoogleAuthCodeRequired, Msg: "google auth code required"}
+	ErrQuotaExceeded          = &XErr{Code: QuotaExceededError, Msg: "quota exceeded"}
 )
 
 func (e ErrCode) Int() int {
@@ -0,0 +1,84 @@
+package xerr
+
+import (
+	serr "errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// 各语言的错误码消息，仅用于替换预设错误的默认消息
+	messages = map[string]map[ErrCode]string{
+		"zh": {
+			ParamError:             "参数错误",
+			UnauthorizedError:      "未授权",
+			ForbiddenError:         "无权限",
+			ServerError:            "网络服务繁忙，请稍后再试",
+			ServerInternalError:    "服务器错误",
+			DbError:                "数据库错误",
+			CaptchaError:           "验证码错误",
+			GoogleAuthCodeRequired: "需要谷歌验证码",
+			QuotaExceededError:     "超出配额",
+		},
+	}
+	messagesMu sync.RWMutex
+
+	// 预设错误的默认消息
+	defaultMessages = map[ErrCode]string{
+		ParamError:             ErrParam.Msg,
+		UnauthorizedError:      ErrUnauthorized.Msg,
+		ForbiddenError:         ErrorForbidden.Msg,
+		ServerError:            ErrorServer.Msg,
+		ServerInternalError:    ErrorInternalServer.Msg,
+		DbError:                ErrDB.Msg,
+		CaptchaError:           ErrCaptcha.Msg,
+		GoogleAuthCodeRequired: ErrGoogleAuthCodeRequired.Msg,
+		QuotaExceededError:     ErrQuotaExceeded.Msg,
+	}
+)
+
+// RegisterMessages 注册语言的错误码消息，如 RegisterMessages("zh", map[ErrCode]string{MyCode: "..."})
+// lang 使用主语言，如 zh、en；非预设错误码的消息总是按语言替换
+func RegisterMessages(lang string, msgs map[ErrCode]string) {
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	lang = baseLang(lang)
+	if messages[lang] == nil {
+		messages[lang] = make(map[ErrCode]string, len(msgs))
+	}
+	for code, msg := range msgs {
+		messages[lang][code] = msg
+	}
+}
+
+// LocalizeMessage 按语言返回错误消息：预设错误仍为默认消息时替换为对应语言的消息，
+// 业务自定义的消息原样返回，不包含原始错误；lang 通常来自 metadata.GetLanguageFromCtx
+func LocalizeMessage(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+
+	var xe *XErr
+	if !serr.As(err, &xe) {
+		return err.Error()
+	}
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	if def, ok := defaultMessages[xe.Code]; ok && xe.Msg != def {
+		return xe.Msg
+	}
+	if msg, ok := messages[baseLang(lang)][xe.Code]; ok {
+		return msg
+	}
+	return xe.Msg
+}
+
+// baseLang 取主语言，如 zh-CN → zh
+func baseLang(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	base, _, _ := strings.Cut(lang, "-")
+	return base
+}
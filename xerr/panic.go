@@ -0,0 +1,85 @@
+package xerr
+
+import (
+	serr "errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError panic 转换成的错误，保留 panic 值和调用栈
+type PanicError struct {
+	// panic 的值
+	Value any
+	// panic 时的调用栈
+	Stack []byte
+}
+
+// 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap panic 的值为 error 时返回该错误
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// Recover 在 defer 中将 panic 转换为错误码为 code 的 XErr 并写入 errp，code 为0时使用 ServerInternalError
+// 必须直接 defer 调用：
+//
+//	func (w *Worker) Handle(ctx context.Context) (err error) {
+//		defer xerr.Recover(&err, xerr.ServerInternalError)
+//		...
+//	}
+func Recover(errp *error, code ErrCode) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if errp != nil {
+		*errp = FromPanic(r, code)
+	}
+}
+
+// FromPanic 将 recover() 的返回值转换为 XErr，并记录当前调用栈
+// 返回给客户端的消息为错误码的默认消息，panic 的值只保留在原始错误中
+func FromPanic(r any, code ErrCode) *XErr {
+	if code == 0 {
+		code = ServerInternalError
+	}
+	pe := &PanicError{Value: r, Stack: debug.Stack()}
+	return &XErr{
+		Code: code,
+		Msg:  panicMessage(code),
+		err:  pe,
+	}
+}
+
+// panicMessage 错误码的默认消息，非预设错误码使用服务器错误的消息
+func panicMessage(code ErrCode) string {
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	if msg, ok := defaultMessages[code]; ok {
+		return msg
+	}
+	return ErrorInternalServer.Msg
+}
+
+// IsPanic 错误是否由 panic 转换而来
+func IsPanic(err error) bool {
+	var pe *PanicError
+	return serr.As(err, &pe)
+}
+
+// PanicStack 获取 panic 转换而来的错误的调用栈
+func PanicStack(err error) ([]byte, bool) {
+	var pe *PanicError
+	if !serr.As(err, &pe) {
+		return nil, false
+	}
+	return pe.Stack, true
+}
@@ -0,0 +1,72 @@
+package xerr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// panicking 触发 panic 并通过 Recover 转换为错误
+func panicking(v any, code ErrCode) (err error) {
+	defer Recover(&err, code)
+	panic(v)
+}
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		code     ErrCode
+		wantCode ErrCode
+		wantMsg  string
+		cause    error
+	}{
+		{"string", "boom", 0, ServerInternalError, ErrorInternalServer.Msg, nil},
+		{"custom code", "boom", DbError, DbError, ErrDB.Msg, nil},
+		{"error value", io.ErrUnexpectedEOF, 0, ServerInternalError, ErrorInternalServer.Msg, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := panicking(tt.value, tt.code)
+			var xe *XErr
+			if !errors.As(err, &xe) {
+				t.Fatalf("err = %T, want *XErr", err)
+			}
+			if xe.Code != tt.wantCode || xe.Msg != tt.wantMsg {
+				t.Fatalf("XErr = %d %q, want %d %q", xe.Code, xe.Msg, tt.wantCode, tt.wantMsg)
+			}
+			var pe *PanicError
+			if !errors.As(err, &pe) || pe.Value != tt.value {
+				t.Fatalf("panic value not kept in %v", err)
+			}
+			if !IsPanic(err) || !IsPanic(fmt.Errorf("handle: %w", err)) {
+				t.Fatal("IsPanic = false")
+			}
+			if tt.cause != nil && !errors.Is(err, tt.cause) {
+				t.Fatalf("err does not unwrap to %v", tt.cause)
+			}
+			stack, ok := PanicStack(err)
+			if !ok || !strings.Contains(string(stack), "panicking") {
+				t.Fatalf("stack missing caller:\n%s", stack)
+			}
+		})
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	err := func() (err error) {
+		defer Recover(&err, 0)
+		return io.EOF
+	}()
+	if err != io.EOF {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+	if IsPanic(err) || IsPanic(nil) {
+		t.Fatal("IsPanic = true for a normal error")
+	}
+	if _, ok := PanicStack(err); ok {
+		t.Fatal("PanicStack found a stack for a normal error")
+	}
+}
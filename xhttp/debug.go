@@ -0,0 +1,291 @@
+package xhttp
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/google/uuid"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+const (
+	// HeaderDebugID 调试ID响应头
+	HeaderDebugID = "X-Debug-Id"
+	// HeaderAdminToken 调试管理端点令牌请求头
+	HeaderAdminToken = "X-Admin-Token"
+	// 默认保留的记录条数
+	defaultDebugCapacity = 100
+	// 默认记录的最大body字节数
+	defaultDebugMaxBodySize = 64 * 1024
+)
+
+// 脱敏的请求头
+var redactedDebugHeaders = map[string]bool{
+	"Authorization":  true,
+	"Cookie":         true,
+	"Set-Cookie":     true,
+	HeaderAdminToken: true,
+}
+
+// DebugConfig 请求/响应转储调试配置
+type DebugConfig struct {
+	// 是否启用调试模式
+	Enable bool `json:",optional"`
+	// 访问管理端点的令牌，为空时管理端点不可用
+	AdminToken string `json:",optional"`
+	// 环形缓冲区容量
+	Capacity int `json:",optional"`
+	// 请求体/响应体最大记录字节数
+	MaxBodySize int `json:",optional"`
+}
+
+// DebugRecord 一次请求/响应记录
+type DebugRecord struct {
+	ID              string              `json:"id"`
+	Time            time.Time           `json:"time"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RemoteAddr      string              `json:"remote_addr"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	RequestBody     string              `json:"request_body,omitempty"`
+	Status          int                 `json:"status"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ResponseBody    string              `json:"response_body,omitempty"`
+	DurationMs      int64               `json:"duration_ms"`
+}
+
+// DebugRecorder 保存最近N条请求/响应的环形缓冲区
+type DebugRecorder struct {
+	cfg     DebugConfig
+	mu      sync.RWMutex
+	records []DebugRecord
+	next    int
+	full    bool
+}
+
+// NewDebugRecorder 创建调试记录器
+func NewDebugRecorder(cfg DebugConfig) *DebugRecorder {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = defaultDebugCapacity
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultDebugMaxBodySize
+	}
+
+	return &DebugRecorder{
+		cfg:     cfg,
+		records: make([]DebugRecord, cfg.Capacity),
+	}
+}
+
+// Middleware 记录请求/响应并在响应头中返回调试ID
+func (d *DebugRecorder) Middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !d.cfg.Enable {
+			next(w, r)
+			return
+		}
+
+		start := time.Now()
+		id := uuid.NewString()
+		w.Header().Set(HeaderDebugID, id)
+
+		// 读取请求体（最多MaxBodySize），并还原给后续处理器
+		var reqBody []byte
+		if r.Body != nil {
+			reqBody, _ = io.ReadAll(io.LimitReader(r.Body, int64(d.cfg.MaxBodySize)))
+			r.Body = readCloser{io.MultiReader(bytes.NewReader(reqBody), r.Body), r.Body}
+		}
+
+		dw := &debugResponseWriter{ResponseWriter: w, status: http.StatusOK, limit: d.cfg.MaxBodySize}
+		next(dw, r)
+
+		d.add(DebugRecord{
+			ID:              id,
+			Time:            start,
+			Method:          r.Method,
+			URL:             r.URL.String(),
+			RemoteAddr:      r.RemoteAddr,
+			RequestHeaders:  redactHeaders(r.Header),
+			RequestBody:     scrubBody(r.Header.Get("Content-Type"), reqBody),
+			Status:          dw.status,
+			ResponseHeaders: redactHeaders(w.Header()),
+			ResponseBody:    scrubBody(w.Header().Get("Content-Type"), dw.body.Bytes()),
+			DurationMs:      time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// Handler 管理端点，需携带管理令牌
+// 带 id 参数时返回单条记录，否则返回全部记录（新记录在前）
+func (d *DebugRecorder) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !d.cfg.Enable {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !d.authorized(r) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if id := r.URL.Query().Get("id"); id != "" {
+			record, ok := d.Get(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			httpx.OkJsonCtx(r.Context(), w, record)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, d.Records())
+	}
+}
+
+// Records 返回所有记录，新记录在前
+func (d *DebugRecorder) Records() []DebugRecord {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	count := d.next
+	if d.full {
+		count = len(d.records)
+	}
+
+	result := make([]DebugRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (d.next - i + len(d.records)) % len(d.records)
+		result = append(result, d.records[idx])
+	}
+	return result
+}
+
+// Get 根据调试ID查询记录
+func (d *DebugRecorder) Get(id string) (DebugRecord, bool) {
+	for _, record := range d.Records() {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return DebugRecord{}, false
+}
+
+// add 写入环形缓冲区
+func (d *DebugRecorder) add(record DebugRecord) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.records[d.next] = record
+	d.next = (d.next + 1) % len(d.records)
+	if d.next == 0 {
+		d.full = true
+	}
+}
+
+// authorized 校验管理令牌，支持 X-Admin-Token 和 Bearer 两种方式
+func (d *DebugRecorder) authorized(r *http.Request) bool {
+	if d.cfg.AdminToken == "" {
+		return false
+	}
+
+	token := r.Header.Get(HeaderAdminToken)
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(d.cfg.AdminToken)) == 1
+}
+
+// redactHeaders 复制请求头并脱敏敏感字段
+func redactHeaders(h http.Header) map[string][]string {
+	result := make(map[string][]string, len(h))
+	for k, v := range h {
+		if redactedDebugHeaders[http.CanonicalHeaderKey(k)] {
+			result[k] = []string{"[REDACTED]"}
+			continue
+		}
+		result[k] = append([]string(nil), v...)
+	}
+	return result
+}
+
+// scrubBody 按日志脱敏策略处理body：JSON和表单按字段名脱敏，其他内容按字符串截断
+func scrubBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			form := make(map[string]any, len(values))
+			for k, v := range values {
+				items := make([]any, len(v))
+				for i, item := range v {
+					items[i] = item
+				}
+				form[k] = items
+			}
+			return metadata.ScrubString(form)
+		}
+	}
+
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err == nil && !dec.More() {
+		return metadata.ScrubString(decoded)
+	}
+	// 非JSON或超出记录长度被截断的body，无字段名可匹配，只截断
+	return fmt.Sprint(metadata.Scrub("", string(body)))
+}
+
+// debugResponseWriter 记录响应状态码和响应体
+type debugResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	limit  int
+}
+
+// WriteHeader 记录状态码
+func (w *debugResponseWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 记录响应体（超出限制部分不记录）
+func (w *debugResponseWriter) Write(b []byte) (int, error) {
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		if len(b) > remain {
+			w.body.Write(b[:remain])
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (w *debugResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// readCloser 组合 Reader 和原始 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
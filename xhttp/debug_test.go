@@ -0,0 +1,136 @@
+package xhttp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoHandler 返回请求体
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Set-Cookie", "session=secret")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func TestDebugRecorderMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      DebugConfig
+		body     string
+		wantBody string
+		record   bool
+	}{
+		{"disabled", DebugConfig{}, "hello", "", false},
+		{"enabled", DebugConfig{Enable: true}, "hello", "hello", true},
+		{"body truncated", DebugConfig{Enable: true, MaxBodySize: 3}, "hello", "hel", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDebugRecorder(tt.cfg)
+			req := httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			d.Middleware(echoHandler)(w, req)
+
+			// 处理器仍能读到完整请求体
+			if w.Body.String() != tt.body || w.Code != http.StatusCreated {
+				t.Fatalf("response = %d %q", w.Code, w.Body.String())
+			}
+			id := w.Header().Get(HeaderDebugID)
+			if !tt.record {
+				if id != "" || len(d.Records()) != 0 {
+					t.Fatalf("recorded while disabled: %q", id)
+				}
+				return
+			}
+
+			record, ok := d.Get(id)
+			if !ok {
+				t.Fatalf("record %q not found", id)
+			}
+			if record.Method != http.MethodPost || record.URL != "/orders?id=1" || record.Status != http.StatusCreated {
+				t.Fatalf("record = %+v", record)
+			}
+			if record.RequestBody != tt.wantBody || record.ResponseBody != tt.wantBody {
+				t.Fatalf("bodies = %q, %q, want %q", record.RequestBody, record.ResponseBody, tt.wantBody)
+			}
+			if record.RequestHeaders["Authorization"][0] != "[REDACTED]" || record.ResponseHeaders["Set-Cookie"][0] != "[REDACTED]" {
+				t.Fatalf("headers not redacted: %v %v", record.RequestHeaders, record.ResponseHeaders)
+			}
+		})
+	}
+}
+
+func TestDebugRecorderRing(t *testing.T) {
+	d := NewDebugRecorder(DebugConfig{Enable: true, Capacity: 2})
+	var ids []string
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		d.Middleware(echoHandler)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		ids = append(ids, w.Header().Get(HeaderDebugID))
+	}
+
+	records := d.Records()
+	if len(records) != 2 || records[0].ID != ids[2] || records[1].ID != ids[1] {
+		t.Fatalf("records = %+v, want newest two of %v", records, ids)
+	}
+	if _, ok := d.Get(ids[0]); ok {
+		t.Fatal("oldest record not evicted")
+	}
+}
+
+func TestDebugRecorderHandler(t *testing.T) {
+	d := NewDebugRecorder(DebugConfig{Enable: true, AdminToken: "admin"})
+	w := httptest.NewRecorder()
+	d.Middleware(echoHandler)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	id := w.Header().Get(HeaderDebugID)
+
+	tests := []struct {
+		name   string
+		d      *DebugRecorder
+		target string
+		header map[string]string
+		want   int
+	}{
+		{"disabled", NewDebugRecorder(DebugConfig{AdminToken: "admin"}), "/", map[string]string{HeaderAdminToken: "admin"}, http.StatusNotFound},
+		{"no token configured", NewDebugRecorder(DebugConfig{Enable: true}), "/", map[string]string{HeaderAdminToken: ""}, http.StatusForbidden},
+		{"missing token", d, "/", nil, http.StatusForbidden},
+		{"wrong token", d, "/", map[string]string{HeaderAdminToken: "guest"}, http.StatusForbidden},
+		{"admin header", d, "/", map[string]string{HeaderAdminToken: "admin"}, http.StatusOK},
+		{"bearer", d, "/", map[string]string{"Authorization": "Bearer admin"}, http.StatusOK},
+		{"by id", d, "/?id=" + id, map[string]string{HeaderAdminToken: "admin"}, http.StatusOK},
+		{"unknown id", d, "/?id=missing", map[string]string{HeaderAdminToken: "admin"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			tt.d.Handler()(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if strings.Contains(tt.target, "id=") {
+				var record DebugRecord
+				if err := json.Unmarshal(w.Body.Bytes(), &record); err != nil || record.ID != id {
+					t.Fatalf("record = %+v, %v", record, err)
+				}
+				return
+			}
+			var records []DebugRecord
+			if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil || len(records) != 1 {
+				t.Fatalf("records = %+v, %v", records, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StatusClientClosedRequest 客户端在响应前断开连接（nginx 499）
+const StatusClientClosedRequest = 499
+
+// 客户端断开计数器
+var clientClosedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "http",
+		Subsystem: "server",
+		Name:      "client_closed_total",
+		Help:      "响应前客户端已断开的请求数",
+	},
+	[]string{"method"},
+)
+
+// ClientClosedCollector 返回客户端断开指标收集器，由业务方注册到自己的 Prometheus registry
+func ClientClosedCollector() prometheus.Collector {
+	return clientClosedTotal
+}
+
+// ErrServerShutdown 服务关闭时取消请求上下文的原因，见 context.WithCancelCause
+var ErrServerShutdown = errors.New("server shutting down")
+
+// IsClientClosed 请求上下文是否因客户端断开而取消
+// 超时（DeadlineExceeded）和服务端带原因的取消（如 ErrServerShutdown）不视为客户端断开
+func IsClientClosed(ctx context.Context) bool {
+	if ctx == nil || !errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+	// net/http 在连接断开时以 context.Canceled 取消请求上下文，服务端主动取消时原因不同
+	return context.Cause(ctx) == context.Canceled
+}
+
+// IsClientClosedErr 错误是否由客户端断开引起，包括下游RPC返回的 Canceled 状态
+func IsClientClosedErr(ctx context.Context, err error) bool {
+	if err == nil || !IsClientClosed(ctx) {
+		return false
+	}
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
+
+// WriteClientClosed 记录客户端断开并写入499状态码，不再序列化响应体
+func WriteClientClosed(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	method := ""
+	if r != nil {
+		method = r.Method
+		logx.WithContext(ctx).Infof("client closed request: %s %s", r.Method, r.URL.Path)
+	} else {
+		logx.WithContext(ctx).Info("client closed request")
+	}
+
+	clientClosedTotal.WithLabelValues(method).Inc()
+	// 连接已断开，写入状态码仅用于访问日志和指标
+	w.WriteHeader(StatusClientClosedRequest)
+}
+
+// ClientClosedMiddleware 客户端断开处理中间件，可通过 server.Use 注册
+// handler 未写入响应且客户端已断开时，以499结束请求，避免被记录为200或500
+func ClientClosedMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cw := &closeAwareWriter{ResponseWriter: w}
+		next(cw, r)
+
+		if !cw.wroteHeader && IsClientClosed(r.Context()) {
+			WriteClientClosed(r.Context(), w, r)
+		}
+	}
+}
+
+// closeAwareWriter 记录是否已写入响应头
+type closeAwareWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader 写入状态码
+func (w *closeAwareWriter) WriteHeader(code int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 写入响应体
+func (w *closeAwareWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 实现 http.Flusher，支持流式响应
+func (w *closeAwareWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 获取原始 ResponseWriter
+func (w *closeAwareWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
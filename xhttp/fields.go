@@ -0,0 +1,184 @@
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/QuantumShiftX/golib/xerr"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// FieldsParam 部分响应的查询参数，如 ?fields=id,name,user.avatar
+	FieldsParam = "fields"
+	// 最多请求的字段数
+	maxFields = 100
+)
+
+// fieldsCtxKey 上下文中部分响应字段的键
+type fieldsCtxKey struct{}
+
+// FieldSet 部分响应的字段树，路径相对于响应的 data，数组对每个元素生效
+type FieldSet struct {
+	children map[string]*FieldSet
+}
+
+// ParseFields 解析 ?fields= 参数，多个字段以逗号分隔，嵌套字段以点分隔
+// allowed 为允许请求的字段，允许 user 时也允许 user.name，为空时不限制；未传参数时返回 nil
+func ParseFields(r *http.Request, allowed ...string) (*FieldSet, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(FieldsParam))
+	if raw == "" {
+		return nil, nil
+	}
+
+	paths := strings.Split(raw, ",")
+	if len(paths) > maxFields {
+		return nil, xerr.NewParamErr("too many fields")
+	}
+
+	fs := &FieldSet{}
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if !fieldAllowed(path, allowed) {
+			return nil, xerr.NewParamErr("field not allowed: " + path)
+		}
+		fs.add(strings.Split(path, "."))
+	}
+	if len(fs.children) == 0 {
+		return nil, nil
+	}
+	return fs, nil
+}
+
+// WithFields 将部分响应字段写入上下文，JsonBaseResponseCtx 按字段过滤 data
+func WithFields(ctx context.Context, fs *FieldSet) context.Context {
+	if fs == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldsCtxKey{}, fs)
+}
+
+// FieldsFromContext 获取上下文中的部分响应字段
+func FieldsFromContext(ctx context.Context) *FieldSet {
+	if ctx == nil {
+		return nil
+	}
+	fs, _ := ctx.Value(fieldsCtxKey{}).(*FieldSet)
+	return fs
+}
+
+// PartialResponseMiddleware 部分响应中间件，handler 返回完整结构体，响应按 ?fields= 过滤，减少列表页等场景的响应大小
+// allowed 为允许请求的字段，请求了不允许的字段时返回参数错误
+func PartialResponseMiddleware(allowed ...string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fs, err := ParseFields(r, allowed...)
+			if err != nil {
+				JsonBaseResponseCtx(r.Context(), w, err)
+				return
+			}
+			if fs != nil {
+				r = r.WithContext(WithFields(r.Context(), fs))
+			}
+			next(w, r)
+		}
+	}
+}
+
+// Filter 按字段过滤数据，数据先序列化为JSON，不存在的字段忽略
+func (fs *FieldSet) Filter(v any) (any, error) {
+	if fs == nil || v == nil {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// 保留数字精度，避免大整数ID被转为浮点数
+	dec.UseNumber()
+	var doc any
+	if err = dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return fs.apply(doc), nil
+}
+
+// apply 过滤对象和数组，标量原样返回
+func (fs *FieldSet) apply(doc any) any {
+	if fs == nil || len(fs.children) == 0 {
+		return doc
+	}
+
+	switch val := doc.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(fs.children))
+		for name, child := range fs.children {
+			if fv, ok := val[name]; ok {
+				out[name] = child.apply(fv)
+			}
+		}
+		return out
+	case []any:
+		for i := range val {
+			val[i] = fs.apply(val[i])
+		}
+		return val
+	default:
+		return doc
+	}
+}
+
+// add 添加字段路径，已请求父字段时保留整个父字段
+func (fs *FieldSet) add(path []string) {
+	if fs.children == nil {
+		fs.children = make(map[string]*FieldSet)
+	}
+	name := path[0]
+	child, exists := fs.children[name]
+	if exists && child == nil {
+		return
+	}
+	if len(path) == 1 {
+		fs.children[name] = nil
+		return
+	}
+	if child == nil {
+		child = &FieldSet{}
+		fs.children[name] = child
+	}
+	child.add(path[1:])
+}
+
+// fieldAllowed 字段是否在允许列表中
+func fieldAllowed(path string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if path == a || strings.HasPrefix(path, a+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// filterData 按上下文中的字段过滤响应数据，失败时返回完整数据
+func filterData(ctx context.Context, v any) any {
+	fs := FieldsFromContext(ctx)
+	if fs == nil {
+		return v
+	}
+	filtered, err := fs.Filter(v)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("filter response fields error: %v", err)
+		return v
+	}
+	return filtered
+}
@@ -0,0 +1,110 @@
+package xhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+type fieldsUser struct {
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+}
+
+type fieldsItem struct {
+	ID    int64      `json:"id"`
+	Title string     `json:"title"`
+	Price float64    `json:"price"`
+	User  fieldsUser `json:"user"`
+}
+
+func TestPartialResponseMiddleware(t *testing.T) {
+	items := []fieldsItem{
+		{ID: 1<<62 + 1, Title: "a", Price: 1.5, User: fieldsUser{Name: "u1", Avatar: "x.png"}},
+		{ID: 2, Title: "b", Price: 2, User: fieldsUser{Name: "u2", Avatar: "y.png"}},
+	}
+	data := map[string]any{"list": items, "total": 2}
+
+	tests := []struct {
+		name     string
+		fields   string
+		allowed  []string
+		want     string
+		wantCode int
+	}{
+		{"no fields", "", nil, `{"list":[{"id":4611686018427387905,"title":"a","price":1.5,"user":{"name":"u1","avatar":"x.png"}},{"id":2,"title":"b","price":2,"user":{"name":"u2","avatar":"y.png"}}],"total":2}`, BusinessCodeOK},
+		{"top level", "total", nil, `{"total":2}`, BusinessCodeOK},
+		{"array elements", "list.id,list.user.name", nil, `{"list":[{"id":4611686018427387905,"user":{"name":"u1"}},{"id":2,"user":{"name":"u2"}}]}`, BusinessCodeOK},
+		{"parent wins over child", "list.user.name,list.user", nil, `{"list":[{"user":{"avatar":"x.png","name":"u1"}},{"user":{"avatar":"y.png","name":"u2"}}]}`, BusinessCodeOK},
+		{"unknown ignored", "total,missing,list.missing", nil, `{"list":[{},{}],"total":2}`, BusinessCodeOK},
+		{"blank entries", " , total ,", nil, `{"total":2}`, BusinessCodeOK},
+		{"allowed child", "list.user.name", []string{"list.user", "total"}, `{"list":[{"user":{"name":"u1"}},{"user":{"name":"u2"}}]}`, BusinessCodeOK},
+		{"not allowed", "list.title", []string{"list.user", "total"}, "", int(xerr.ErrParam.Code)},
+		{"prefix is not parent", "totals", []string{"total"}, "", int(xerr.ErrParam.Code)},
+		{"too many fields", strings.Repeat("a,", maxFields) + "a", nil, "", int(xerr.ErrParam.Code)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := PartialResponseMiddleware(tt.allowed...)(func(w http.ResponseWriter, r *http.Request) {
+				JsonBaseResponseCtx(r.Context(), w, data)
+			})
+			w := httptest.NewRecorder()
+			target := "/items"
+			if tt.fields != "" {
+				target += "?" + FieldsParam + "=" + url.QueryEscape(tt.fields)
+			}
+			handler(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+			var resp BaseResponse[json.RawMessage]
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", resp.Code, tt.wantCode, w.Body)
+			}
+			if tt.want != "" && string(resp.Data) != tt.want {
+				t.Fatalf("data = %s, want %s", resp.Data, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldSetFilter(t *testing.T) {
+	fs, err := ParseFields(httptest.NewRequest(http.MethodGet, "/?fields=id", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		fs   *FieldSet
+		v    any
+		want string
+	}{
+		{"nil field set", nil, fieldsItem{ID: 1}, `{"id":1,"title":"","price":0,"user":{"name":"","avatar":""}}`},
+		{"nil value", fs, nil, `null`},
+		{"scalar", fs, 3, `3`},
+		{"pointer", fs, &fieldsItem{ID: 1, Title: "a"}, `{"id":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fs.Filter(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := json.Marshal(got)
+			if string(b) != tt.want {
+				t.Fatalf("Filter = %s, want %s", b, tt.want)
+			}
+		})
+	}
+
+	if fs, err := ParseFields(httptest.NewRequest(http.MethodGet, "/?fields=,", nil)); err != nil || fs != nil {
+		t.Fatalf("ParseFields(empty) = %v, %v", fs, err)
+	}
+}
@@ -0,0 +1,137 @@
+package xhttp
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+const (
+	// 默认每页条数
+	defaultPageSize = 20
+	// 默认每页最大条数
+	defaultMaxPageSize = 100
+)
+
+// PageConfig 分页参数配置
+type PageConfig struct {
+	// 未指定时的每页条数，默认20
+	DefaultSize int64 `json:",optional"`
+	// 每页最大条数，超出时按最大值处理，默认100
+	MaxSize int64 `json:",optional"`
+	// 最大页码，防止深分页拖垮数据库，为0时不限制
+	MaxPage int64 `json:",optional"`
+}
+
+var pageConfig atomic.Pointer[PageConfig]
+
+// SetPageConfig 设置分页参数的默认值和上限
+func SetPageConfig(cfg PageConfig) {
+	if cfg.DefaultSize <= 0 {
+		cfg.DefaultSize = defaultPageSize
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxPageSize
+	}
+	cfg.DefaultSize = min(cfg.DefaultSize, cfg.MaxSize)
+	pageConfig.Store(&cfg)
+}
+
+// currentPageConfig 当前分页配置
+func currentPageConfig() PageConfig {
+	if cfg := pageConfig.Load(); cfg != nil {
+		return *cfg
+	}
+	return PageConfig{DefaultSize: defaultPageSize, MaxSize: defaultMaxPageSize}
+}
+
+// PageRequest 分页请求，支持 page/size 页码分页和 cursor/limit 游标分页
+// 查询时可分别转换为 paginate.Pagination{Page: Page, PageSize: Size} 和 paginate.Cursor(field, Cursor, Size, desc)
+type PageRequest struct {
+	// 页码，从1开始，游标分页时为0
+	Page int64 `json:"page"`
+	// 每页条数
+	Size int64 `json:"size"`
+	// 游标，游标分页时为上一页最后一条记录的排序字段值，首页为空
+	Cursor string `json:"cursor,omitempty"`
+	// 是否为游标分页
+	IsCursor bool `json:"is_cursor"`
+}
+
+// ParsePageRequest 从查询参数解析分页请求
+// 页码分页: ?page=2&size=20（size 也可写作 page_size）；游标分页: ?cursor=xxx&limit=20
+// 未指定时使用默认值，超出上限时按上限处理，非数字或页码超出 MaxPage 时返回参数错误
+func ParsePageRequest(r *http.Request) (*PageRequest, error) {
+	cfg := currentPageConfig()
+	query := r.URL.Query()
+
+	req := &PageRequest{Size: cfg.DefaultSize}
+	if query.Has("cursor") || query.Has("limit") {
+		req.IsCursor = true
+		req.Cursor = query.Get("cursor")
+		size, err := parsePageInt(query.Get("limit"), "limit")
+		if err != nil {
+			return nil, err
+		}
+		req.Size = clampPageSize(size, cfg)
+		return req, nil
+	}
+
+	page, err := parsePageInt(query.Get("page"), "page")
+	if err != nil {
+		return nil, err
+	}
+	sizeParam := query.Get("size")
+	if sizeParam == "" {
+		sizeParam = query.Get("page_size")
+	}
+	size, err := parsePageInt(sizeParam, "size")
+	if err != nil {
+		return nil, err
+	}
+
+	req.Page = max(page, 1)
+	req.Size = clampPageSize(size, cfg)
+	if cfg.MaxPage > 0 && req.Page > cfg.MaxPage {
+		return nil, xerr.NewParamErr("page exceeds max page " + strconv.FormatInt(cfg.MaxPage, 10))
+	}
+	// 未限制最大页码时，偏移量超出 int64 的页码同样视为参数错误
+	if req.Page-1 > math.MaxInt64/req.Size {
+		return nil, xerr.NewParamErr("page out of range: " + strconv.FormatInt(req.Page, 10))
+	}
+	return req, nil
+}
+
+// parsePageInt 解析分页数字参数，为空时返回0
+func parsePageInt(value, name string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, xerr.NewParamErr("invalid " + name + ": " + value)
+	}
+	return n, nil
+}
+
+// clampPageSize 将每页条数限制在 [1, MaxSize]，未指定时使用默认值
+func clampPageSize(size int64, cfg PageConfig) int64 {
+	if size <= 0 {
+		return cfg.DefaultSize
+	}
+	return min(size, cfg.MaxSize)
+}
+
+// Offset 页码分页的偏移量，超出 int64 时为 math.MaxInt64
+func (p *PageRequest) Offset() int64 {
+	if p.IsCursor || p.Page <= 0 || p.Size <= 0 {
+		return 0
+	}
+	if p.Page-1 > math.MaxInt64/p.Size {
+		return math.MaxInt64
+	}
+	return (p.Page - 1) * p.Size
+}
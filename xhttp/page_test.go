@@ -0,0 +1,66 @@
+package xhttp
+
+import (
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+func TestParsePageRequest(t *testing.T) {
+	maxPage := "/?page=" + strconv.FormatInt(math.MaxInt64, 10) + "&size=100"
+
+	tests := []struct {
+		name    string
+		cfg     PageConfig
+		target  string
+		want    PageRequest
+		wantErr bool
+	}{
+		{"defaults", PageConfig{}, "/", PageRequest{Page: 1, Size: 20}, false},
+		{"page size", PageConfig{}, "/?page=3&size=10", PageRequest{Page: 3, Size: 10}, false},
+		{"page_size alias", PageConfig{}, "/?page=2&page_size=5", PageRequest{Page: 2, Size: 5}, false},
+		{"size clamped", PageConfig{MaxSize: 50}, "/?size=500", PageRequest{Page: 1, Size: 50}, false},
+		{"cursor", PageConfig{}, "/?cursor=abc&limit=5", PageRequest{Size: 5, Cursor: "abc", IsCursor: true}, false},
+		{"first cursor page", PageConfig{}, "/?limit=0", PageRequest{Size: 20, IsCursor: true}, false},
+		{"invalid page", PageConfig{}, "/?page=x", PageRequest{}, true},
+		{"exceeds max page", PageConfig{MaxPage: 10}, "/?page=11", PageRequest{}, true},
+		{"offset overflow", PageConfig{}, maxPage, PageRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPageConfig(tt.cfg)
+			defer pageConfig.Store(nil)
+
+			got, err := ParsePageRequest(httptest.NewRequest("GET", tt.target, nil))
+			if tt.wantErr {
+				if !xerr.IsErrorCode(err, xerr.ParamError) {
+					t.Fatalf("err = %v, want param error", err)
+				}
+				return
+			}
+			if err != nil || *got != tt.want {
+				t.Fatalf("ParsePageRequest = %+v, %v, want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageRequestOffset(t *testing.T) {
+	tests := []struct {
+		req  PageRequest
+		want int64
+	}{
+		{PageRequest{Page: 1, Size: 20}, 0},
+		{PageRequest{Page: 3, Size: 20}, 40},
+		{PageRequest{Size: 20, IsCursor: true}, 0},
+		{PageRequest{Page: math.MaxInt64, Size: 100}, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		if got := tt.req.Offset(); got != tt.want {
+			t.Fatalf("%+v Offset() = %d, want %d", tt.req, got, tt.want)
+		}
+	}
+}
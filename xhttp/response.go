@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/QuantumShiftX/golib/gerr"
+	"github.com/QuantumShiftX/golib/metadata"
 	"github.com/QuantumShiftX/golib/xerr"
 	"github.com/zeromicro/go-zero/core/trace"
 	"github.com/zeromicro/go-zero/rest/httpx"
@@ -33,7 +34,13 @@ type BaseResponse[T any] struct {
 }
 
 // JsonBaseResponseCtx writes v into w with appropriate http status code.
+// 客户端已断开时跳过序列化，以499结束请求
 func JsonBaseResponseCtx(ctx context.Context, w http.ResponseWriter, v any) {
+	if IsClientClosed(ctx) {
+		WriteClientClosed(ctx, w, nil)
+		return
+	}
+
 	var (
 		traceId = trace.TraceIDFromContext(ctx)
 		spanID  = trace.SpanIDFromContext(ctx)
@@ -103,7 +110,7 @@ func wrapBaseResponse(v any, ctx context.Context) BaseResponse[any] {
 	switch data := v.(type) {
 	case *xerr.XErr:
 		resp.Code = int(data.Code)
-		resp.Message = data.Msg
+		resp.Message = localizeMessage(ctx, data)
 	case *gerr.GError:
 		resp.Code = int(data.Code)
 		resp.Message = data.Msg
@@ -122,7 +129,15 @@ func wrapBaseResponse(v any, ctx context.Context) BaseResponse[any] {
 	default:
 		resp.Code = BusinessCodeOK
 		resp.Message = BusinessMsgOk
-		resp.Data = v
+		resp.Data = filterData(ctx, v)
 	}
 	return resp
 }
+
+// localizeMessage 按上下文中的语言（见 middleware.LocaleMiddleware）本地化错误消息
+func localizeMessage(ctx context.Context, err *xerr.XErr) string {
+	if ctx == nil {
+		return err.Msg
+	}
+	return xerr.LocalizeMessage(err, metadata.GetLanguageFromCtx(ctx))
+}
@@ -0,0 +1,47 @@
+package xhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/QuantumShiftX/golib/metadata"
+	"github.com/QuantumShiftX/golib/xerr"
+)
+
+func TestJsonBaseResponseLocalized(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		err  *xerr.XErr
+		want string
+	}{
+		{"preset zh", "zh-CN", xerr.ErrParam, "参数错误"},
+		{"preset without language", "", xerr.ErrParam, xerr.ErrParam.Msg},
+		{"preset unknown language", "fr", xerr.ErrParam, xerr.ErrParam.Msg},
+		{"custom message", "zh", xerr.NewParamErr("invalid page: x"), "invalid page: x"},
+		{"wrapped", "zh", xerr.Wrap(xerr.DbError, errors.New("duplicate key"), "save user"), "save user: duplicate key"},
+		{"registered language", "en-US", xerr.New(9001, "余额不足"), "insufficient balance"},
+	}
+	xerr.RegisterMessages("en", map[xerr.ErrCode]string{9001: "insufficient balance"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.lang != "" {
+				ctx = metadata.WithLanguage(ctx, tt.lang)
+			}
+			w := httptest.NewRecorder()
+			JsonBaseResponseCtx(ctx, w, tt.err)
+
+			var resp BaseResponse[any]
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != int(tt.err.Code) || resp.Message != tt.want {
+				t.Fatalf("response = %+v, want message %q", resp, tt.want)
+			}
+		})
+	}
+}